@@ -13,6 +13,7 @@ import (
 
 	"path"
 
+	"github.com/drausin/libri/libri/acceptance/faults"
 	lauthor "github.com/drausin/libri/libri/author"
 	"github.com/drausin/libri/libri/author/io/publish"
 	"github.com/drausin/libri/libri/author/keychain"
@@ -84,6 +85,12 @@ type params struct {
 	nUploads       int
 	getTimeout     time.Duration
 	putTimeout     time.Duration
+
+	// network injects faults between librarians when set
+	network *faults.Network
+
+	// subscribeMaxErrRate overrides the default max subscription error rate when positive
+	subscribeMaxErrRate float32
 }
 
 // testClient has enough info to make requests to other peers
@@ -109,6 +116,8 @@ func setUp(params *params) *state { // nolint: deadcode
 		params.nPeers,
 		maxBucketPeers,
 		params.logLevel,
+		params.network,
+		params.subscribeMaxErrRate,
 	)
 	authorConfigs := newAuthorConfigs(dataDir, peerAddrs, params)
 	seeds := make([]*server.Librarian, params.nSeeds)
@@ -321,14 +330,16 @@ func averageSubsamples(
 
 // nolint: megacheck
 func newLibrarianConfigs(dataDir string, nSeeds, nPeers int, maxBucketPeers uint,
-	logLevel zapcore.Level) ([]*server.Config, []*server.Config, []*net.TCPAddr) {
+	logLevel zapcore.Level, network *faults.Network, subscribeMaxErrRate float32,
+) ([]*server.Config, []*server.Config, []*net.TCPAddr) {
 	seedStartPort, peerStartPort := 12000, 13000
 
 	seedConfigs := make([]*server.Config, nSeeds)
 	bootstrapAddrs := make([]*net.TCPAddr, nSeeds)
 	for c := 0; c < nSeeds; c++ {
 		localPort := seedStartPort + c
-		seedConfigs[c] = newConfig(dataDir, localPort, maxBucketPeers, logLevel)
+		seedConfigs[c] = newConfig(dataDir, localPort, maxBucketPeers, logLevel, network,
+			subscribeMaxErrRate)
 		bootstrapAddrs[c] = seedConfigs[c].PublicAddr
 	}
 	for c := 0; c < nSeeds; c++ {
@@ -339,7 +350,8 @@ func newLibrarianConfigs(dataDir string, nSeeds, nPeers int, maxBucketPeers uint
 	peerAddrs := make([]*net.TCPAddr, nPeers)
 	for c := 0; c < nPeers; c++ {
 		localPort := peerStartPort + c
-		peerConfigs[c] = newConfig(dataDir, localPort, maxBucketPeers, logLevel).
+		peerConfigs[c] = newConfig(dataDir, localPort, maxBucketPeers, logLevel, network,
+			subscribeMaxErrRate).
 			WithBootstrapAddrs(bootstrapAddrs)
 		peerAddrs[c] = peerConfigs[c].PublicAddr
 	}
@@ -372,7 +384,12 @@ func newAuthorConfigs(dataDir string, librarianAddrs []*net.TCPAddr, params *par
 
 // nolint: megacheck
 func newConfig(
-	dataDir string, port int, maxBucketPeers uint, logLevel zapcore.Level,
+	dataDir string,
+	port int,
+	maxBucketPeers uint,
+	logLevel zapcore.Level,
+	network *faults.Network,
+	subscribeMaxErrRate float32,
 ) *server.Config {
 
 	rtParams := routing.NewDefaultParameters()
@@ -385,12 +402,15 @@ func newConfig(
 
 	subscribeToParams := subscribe.NewDefaultToParameters()
 	subscribeToParams.FPRate = 0.9
+	if subscribeMaxErrRate > 0 {
+		subscribeToParams.MaxErrRate = subscribeMaxErrRate
+	}
 
 	localAddr, err := parse.Addr("localhost", port)
 	errors.MaybePanic(err) // should never happen
 	peerDataDir := filepath.Join(dataDir, server.NameFromAddr(localAddr))
 
-	return server.NewDefaultConfig().
+	config := server.NewDefaultConfig().
 		WithLocalPort(port).
 		WithReportMetrics(false).
 		WithDefaultPublicAddr().
@@ -402,6 +422,12 @@ func newConfig(
 		WithIntroduce(introParams).
 		WithSearch(searchParams).
 		WithSubscribeTo(subscribeToParams)
+	if network != nil {
+		addr := config.PublicAddr.String()
+		config.WithDialOptions(network.DialOptions(addr)...).
+			WithWrapListener(network.WrapListener(addr))
+	}
+	return config
 }
 
 // nolint: megacheck
@@ -0,0 +1,291 @@
+// Package faults injects network faults between librarians so tests can exercise how a cluster
+// behaves when peers are slow, flaky, partitioned, or unreachable.
+//
+// Faults are applied on the client side via gRPC interceptors (see DialOptions), so they affect
+// the RPCs a librarian makes to other peers, and on the server side via a listener wrapper (see
+// WrapListener), so isolating a peer also cuts off authors and other clients. Peers are
+// identified by their public address string (e.g., "127.0.0.1:20100").
+package faults
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrPartitioned is returned by RPCs between peers in different partitions.
+	ErrPartitioned = status.Error(codes.Unavailable, "injected fault: peers are partitioned")
+
+	// ErrIsolated is returned by RPCs to or from an isolated peer.
+	ErrIsolated = status.Error(codes.Unavailable, "injected fault: peer is isolated")
+
+	// ErrFailed is returned by RPCs that are randomly failed.
+	ErrFailed = status.Error(codes.Unavailable, "injected fault: RPC failed")
+)
+
+// Network holds the faults currently injected between peers. It is safe for concurrent use, and
+// faults may be changed while RPCs are in flight.
+type Network struct {
+	peers      map[string]*peerFaults
+	partitions map[string]int
+	listeners  map[string]*listener
+	rng        *rand.Rand
+	mu         sync.Mutex
+}
+
+type peerFaults struct {
+	latency  time.Duration
+	failRate float32
+	dropRate float32
+	isolated bool
+}
+
+// NewNetwork returns a new Network without any faults, using the given RNG to decide which RPCs
+// fail or are dropped.
+func NewNetwork(rng *rand.Rand) *Network {
+	return &Network{
+		peers:      make(map[string]*peerFaults),
+		partitions: make(map[string]int),
+		listeners:  make(map[string]*listener),
+		rng:        rng,
+	}
+}
+
+// SetLatency adds the given latency to every RPC to the peer.
+func (n *Network) SetLatency(addr string, latency time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.peer(addr).latency = latency
+}
+
+// SetFailRate sets the fraction of RPCs to the peer that immediately fail as unavailable.
+func (n *Network) SetFailRate(addr string, rate float32) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.peer(addr).failRate = rate
+}
+
+// SetDropRate sets the fraction of RPCs to the peer that are dropped and never answered, so they
+// only end when the caller's context is done.
+func (n *Network) SetDropRate(addr string, rate float32) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.peer(addr).dropRate = rate
+}
+
+// Partition splits the given peers into groups that cannot reach each other. Peers not in any
+// group can still reach (and be reached by) everyone. It replaces any existing partition.
+func (n *Network) Partition(groups ...[]string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.partitions = make(map[string]int)
+	for i, group := range groups {
+		for _, addr := range group {
+			n.partitions[addr] = i
+		}
+	}
+}
+
+// Isolate cuts the peer off from the network, as if its host went down: existing connections to
+// it are severed, new connections are refused, and RPCs it makes fail.
+func (n *Network) Isolate(addr string) {
+	n.mu.Lock()
+	n.peer(addr).isolated = true
+	lis, in := n.listeners[addr]
+	n.mu.Unlock()
+	if in {
+		lis.closeConns()
+	}
+}
+
+// Rejoin reconnects a previously isolated peer to the network.
+func (n *Network) Rejoin(addr string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.peer(addr).isolated = false
+}
+
+// Heal removes all partitions and isolations but leaves latencies, fail rates, and drop rates.
+func (n *Network) Heal() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.partitions = make(map[string]int)
+	for _, pf := range n.peers {
+		pf.isolated = false
+	}
+}
+
+// Reset removes all faults.
+func (n *Network) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.partitions = make(map[string]int)
+	n.peers = make(map[string]*peerFaults)
+}
+
+// DialOptions returns the options a peer with the given address should use when connecting to
+// other peers so that the network's faults apply to its RPCs.
+func (n *Network) DialOptions(src string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithUnaryInterceptor(n.unaryInterceptor(src)),
+		grpc.WithStreamInterceptor(n.streamInterceptor(src)),
+	}
+}
+
+// WrapListener returns a function wrapping the listener of the peer with the given address so
+// that isolating the peer severs its connections.
+func (n *Network) WrapListener(addr string) func(net.Listener) net.Listener {
+	return func(inner net.Listener) net.Listener {
+		lis := &listener{
+			Listener: inner,
+			isolated: func() bool { return n.isolated(addr) },
+			conns:    make(map[net.Conn]struct{}),
+		}
+		n.mu.Lock()
+		n.listeners[addr] = lis
+		n.mu.Unlock()
+		return lis
+	}
+}
+
+func (n *Network) unaryInterceptor(src string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := n.apply(ctx, src, cc.Target()); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (n *Network) streamInterceptor(src string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream,
+		error) {
+		if err := n.apply(ctx, src, cc.Target()); err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// apply blocks for any latency between src and dest and returns an error if the RPC should fail.
+func (n *Network) apply(ctx context.Context, src, dest string) error {
+	latency, drop, err := n.sample(src, dest)
+	if err != nil {
+		return err
+	}
+	if drop {
+		<-ctx.Done()
+		return contextErr(ctx)
+	}
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-ctx.Done():
+			return contextErr(ctx)
+		}
+	}
+	return nil
+}
+
+func contextErr(ctx context.Context) error {
+	if ctx.Err() == context.Canceled {
+		return status.Error(codes.Canceled, ctx.Err().Error())
+	}
+	return status.Error(codes.DeadlineExceeded, ctx.Err().Error())
+}
+
+func (n *Network) sample(src, dest string) (time.Duration, bool, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.peer(src).isolated || n.peer(dest).isolated {
+		return 0, false, ErrIsolated
+	}
+	srcGroup, srcIn := n.partitions[src]
+	destGroup, destIn := n.partitions[dest]
+	if srcIn && destIn && srcGroup != destGroup {
+		return 0, false, ErrPartitioned
+	}
+	pf := n.peer(dest)
+	if pf.failRate > 0 && n.rng.Float32() < pf.failRate {
+		return 0, false, ErrFailed
+	}
+	drop := pf.dropRate > 0 && n.rng.Float32() < pf.dropRate
+	return pf.latency, drop, nil
+}
+
+func (n *Network) isolated(addr string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.peer(addr).isolated
+}
+
+// peer returns the faults for the given address, creating them if needed; n.mu must be held.
+func (n *Network) peer(addr string) *peerFaults {
+	pf, in := n.peers[addr]
+	if !in {
+		pf = &peerFaults{}
+		n.peers[addr] = pf
+	}
+	return pf
+}
+
+// listener refuses connections while its peer is isolated and tracks accepted connections so
+// they can be severed when the peer becomes isolated.
+type listener struct {
+	net.Listener
+	isolated func() bool
+	conns    map[net.Conn]struct{}
+	mu       sync.Mutex
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.isolated() {
+			// refuse connection, but keep listening for when the peer rejoins
+			_ = conn.Close()
+			continue
+		}
+		c := &trackedConn{Conn: conn, lis: l}
+		l.mu.Lock()
+		l.conns[c] = struct{}{}
+		l.mu.Unlock()
+		return c, nil
+	}
+}
+
+func (l *listener) closeConns() {
+	l.mu.Lock()
+	conns := make([]net.Conn, 0, len(l.conns))
+	for conn := range l.conns {
+		conns = append(conns, conn)
+	}
+	l.mu.Unlock()
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+}
+
+type trackedConn struct {
+	net.Conn
+	lis *listener
+}
+
+func (c *trackedConn) Close() error {
+	c.lis.mu.Lock()
+	delete(c.lis.conns, c)
+	c.lis.mu.Unlock()
+	return c.Conn.Close()
+}
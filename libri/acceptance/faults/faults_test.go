@@ -0,0 +1,189 @@
+package faults
+
+import (
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	addr1 = "127.0.0.1:1"
+	addr2 = "127.0.0.1:2"
+	addr3 = "127.0.0.1:3"
+)
+
+func TestNetwork_apply_ok(t *testing.T) {
+	n := NewNetwork(rand.New(rand.NewSource(0)))
+	ctx := context.Background()
+
+	// no faults
+	assert.Nil(t, n.apply(ctx, addr1, addr2))
+
+	// latency
+	latency := 50 * time.Millisecond
+	n.SetLatency(addr2, latency)
+	start := time.Now()
+	assert.Nil(t, n.apply(ctx, addr1, addr2))
+	assert.True(t, time.Since(start) >= latency)
+
+	// latency only applies to RPCs to addr2
+	start = time.Now()
+	assert.Nil(t, n.apply(ctx, addr2, addr1))
+	assert.True(t, time.Since(start) < latency)
+
+	// peers outside a partition can reach everyone
+	n.Partition([]string{addr1}, []string{addr2})
+	assert.Nil(t, n.apply(ctx, addr3, addr1))
+	assert.Nil(t, n.apply(ctx, addr1, addr3))
+}
+
+func TestNetwork_apply_err(t *testing.T) {
+	n := NewNetwork(rand.New(rand.NewSource(0)))
+	ctx := context.Background()
+
+	// partition
+	n.Partition([]string{addr1}, []string{addr2, addr3})
+	assert.Equal(t, ErrPartitioned, n.apply(ctx, addr1, addr2))
+	assert.Equal(t, ErrPartitioned, n.apply(ctx, addr3, addr1))
+	assert.Nil(t, n.apply(ctx, addr2, addr3))
+	n.Heal()
+	assert.Nil(t, n.apply(ctx, addr1, addr2))
+
+	// isolation
+	n.Isolate(addr1)
+	assert.Equal(t, ErrIsolated, n.apply(ctx, addr1, addr2))
+	assert.Equal(t, ErrIsolated, n.apply(ctx, addr2, addr1))
+	n.Rejoin(addr1)
+	assert.Nil(t, n.apply(ctx, addr1, addr2))
+
+	// failures
+	n.SetFailRate(addr2, 1.0)
+	assert.Equal(t, ErrFailed, n.apply(ctx, addr1, addr2))
+
+	// drops
+	n.Reset()
+	n.SetDropRate(addr2, 1.0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := n.apply(ctx, addr1, addr2)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	// latency longer than timeout
+	n.Reset()
+	n.SetLatency(addr2, time.Second)
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = n.apply(ctx, addr1, addr2)
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestNetwork_SetFailRate(t *testing.T) {
+	n := NewNetwork(rand.New(rand.NewSource(0)))
+	n.SetFailRate(addr2, 0.5)
+	nFailed, nTrials := 0, 1000
+	for c := 0; c < nTrials; c++ {
+		if err := n.apply(context.Background(), addr1, addr2); err != nil {
+			nFailed++
+		}
+	}
+	assert.InDelta(t, nTrials/2, nFailed, float64(nTrials)/10)
+}
+
+func TestNetwork_interceptors(t *testing.T) {
+	n := NewNetwork(rand.New(rand.NewSource(0)))
+	cc, err := grpc.Dial(addr2, grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, cc.Close()) }()
+	ctx := context.Background()
+
+	nUnaryCalls := 0
+	invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn,
+		...grpc.CallOption) error {
+		nUnaryCalls++
+		return nil
+	}
+	nStreamCalls := 0
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string,
+		...grpc.CallOption) (grpc.ClientStream, error) {
+		nStreamCalls++
+		return nil, nil
+	}
+	unary, stream := n.unaryInterceptor(addr1), n.streamInterceptor(addr1)
+
+	err = unary(ctx, "method", nil, nil, cc, invoker)
+	assert.Nil(t, err)
+	_, err = stream(ctx, &grpc.StreamDesc{}, cc, "method", streamer)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, nUnaryCalls)
+	assert.Equal(t, 1, nStreamCalls)
+
+	// faulted RPCs don't call through
+	n.Partition([]string{addr1}, []string{addr2})
+	err = unary(ctx, "method", nil, nil, cc, invoker)
+	assert.Equal(t, ErrPartitioned, err)
+	_, err = stream(ctx, &grpc.StreamDesc{}, cc, "method", streamer)
+	assert.Equal(t, ErrPartitioned, err)
+	assert.Equal(t, 1, nUnaryCalls)
+	assert.Equal(t, 1, nStreamCalls)
+
+	assert.Len(t, n.DialOptions(addr1), 2)
+}
+
+func TestNetwork_WrapListener(t *testing.T) {
+	n := NewNetwork(rand.New(rand.NewSource(0)))
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := inner.Addr().String()
+	lis := n.WrapListener(addr)(inner)
+	defer func() { assert.Nil(t, lis.Close()) }()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err2 := lis.Accept()
+			if err2 != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	// connections are accepted normally
+	client1, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	server1 := <-accepted
+
+	// isolating severs existing connections ...
+	n.Isolate(addr)
+	_, err = server1.Read(make([]byte, 1))
+	assert.NotNil(t, err)
+	assert.Nil(t, client1.SetReadDeadline(time.Now().Add(time.Second)))
+	_, err = client1.Read(make([]byte, 1))
+	assert.NotNil(t, err)
+
+	// ... and refuses new ones
+	client2, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	assert.Nil(t, client2.SetReadDeadline(time.Now().Add(time.Second)))
+	_, err = client2.Read(make([]byte, 1))
+	assert.NotNil(t, err)
+	assert.Len(t, accepted, 0)
+
+	// rejoining accepts connections again
+	n.Rejoin(addr)
+	client3, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	server3 := <-accepted
+	assert.NotNil(t, server3)
+
+	for _, conn := range []net.Conn{client1, client2, client3, server3} {
+		_ = conn.Close()
+	}
+}
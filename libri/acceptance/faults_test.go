@@ -0,0 +1,191 @@
+// +build acceptance
+
+package acceptance
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/acceptance/faults"
+	"github.com/drausin/libri/libri/librarian/server"
+	"github.com/drausin/libri/libri/librarian/server/store"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// librarians only start replicating a minute after they've bootstrapped
+	replicatorStartWait = 60 * time.Second
+
+	// max time to wait for the replicators to restore all docs to full replication
+	rereplicateTimeout = 120 * time.Second
+)
+
+func TestLibrarianClusterFaults(t *testing.T) {
+	network := faults.NewNetwork(rand.New(rand.NewSource(0)))
+	params := &params{
+		nSeeds:         3,
+		nPeers:         24,
+		nAuthors:       1,
+		logLevel:       zapcore.InfoLevel,
+		nIntroductions: 24,
+		nPuts:          32,
+		nUploads:       4,
+		getTimeout:     20 * time.Second,
+		putTimeout:     20 * time.Second,
+		network:        network,
+
+		// subscriptions treat failures from peers as errors, and a librarian shuts itself down
+		// when too many pile up; the faults below would otherwise take most of the cluster
+		// down with them, so tolerate more subscription errors and focus on replication
+		subscribeMaxErrRate: 1.0,
+	}
+	start := time.Now()
+	state := setUp(params)
+
+	// populate test client routing table, data to replicate, and docs to download
+	testIntroduce(t, params, state)
+	testPut(t, params, state)
+	testUpload(t, params, state)
+
+	// make sure replicators are running before we start breaking things
+	if wait := replicatorStartWait - time.Since(start); wait > 0 {
+		state.logger.Info("waiting for replicators to start",
+			zap.Float64("n_seconds", wait.Seconds()),
+		)
+		time.Sleep(wait)
+	}
+
+	// slow and flaky peers shouldn't prevent downloads
+	testDownloadWithFlakyPeers(t, params, state, network)
+
+	// partitions, crashes, and restarts mid-replication should eventually be repaired
+	testReplicateWithFaults(t, params, state, network)
+
+	// all uploaded docs should still be available once things have settled
+	testDownload(t, params, state)
+
+	tearDown(state)
+}
+
+func testDownloadWithFlakyPeers(
+	t *testing.T, params *params, state *state, network *faults.Network,
+) {
+	for i, p := range state.peerConfigs[:12] {
+		addr := p.PublicAddr.String()
+		switch i % 3 {
+		case 0:
+			network.SetLatency(addr, 250*time.Millisecond)
+		case 1:
+			network.SetFailRate(addr, 0.25)
+		case 2:
+			network.SetDropRate(addr, 0.1)
+		}
+	}
+
+	// isolate a couple peers entirely, so some of the author's librarians are unreachable
+	for _, p := range state.peerConfigs[12:14] {
+		network.Isolate(p.PublicAddr.String())
+	}
+
+	testDownload(t, params, state)
+	network.Reset()
+}
+
+func testReplicateWithFaults(
+	t *testing.T, params *params, state *state, network *faults.Network,
+) {
+	nPeers := len(state.peers)
+
+	// split the network in two for a while, so each side sees docs as under-replicated
+	half1, half2 := make([]string, 0, nPeers/2), make([]string, 0, nPeers/2)
+	for i, p := range state.peerConfigs {
+		if i < nPeers/2 {
+			half1 = append(half1, p.PublicAddr.String())
+		} else {
+			half2 = append(half2, p.PublicAddr.String())
+		}
+	}
+	network.Partition(half1, half2)
+	state.logger.Info("partitioned network", zap.Int("n_half1", len(half1)),
+		zap.Int("n_half2", len(half2)))
+	time.Sleep(10 * time.Second)
+	network.Heal()
+
+	// crash a few peers mid-replication, and restart them a little later
+	toRestart := []int{0, 1, 2, 3}
+	for _, i := range toRestart {
+		crashPeer(t, state, network, i)
+	}
+
+	// remove some other peers for good; fewer than the number of replicas, so no doc can lose
+	// all of its copies at once
+	toRemove := []int{4, 5}
+	for _, i := range toRemove {
+		crashPeer(t, state, network, i)
+	}
+
+	time.Sleep(10 * time.Second)
+	for _, i := range toRestart {
+		restartPeer(t, state, network, i)
+	}
+
+	// some slowness and flakiness while replication catches up
+	for _, i := range []int{6, 7, 8} {
+		network.SetLatency(state.peerConfigs[i].PublicAddr.String(), 100*time.Millisecond)
+		network.SetFailRate(state.peerConfigs[i].PublicAddr.String(), 0.1)
+	}
+
+	// wait for the replicators to restore every doc to the full number of replicas
+	nUnderReplicated := -1
+	deadline := time.Now().Add(rereplicateTimeout)
+	for time.Now().Before(deadline) {
+		nReplicas := countDocReplicas(t, state)
+		nUnderReplicated = len(state.putDocs) - len(nReplicas) // failed verifications
+		for _, keyNReplicas := range nReplicas {
+			if keyNReplicas < int(store.DefaultNReplicas) {
+				nUnderReplicated++
+			}
+		}
+		state.logger.Info("finished replica audit",
+			zap.Int("n_under_replicated", nUnderReplicated),
+		)
+		if nUnderReplicated == 0 {
+			break
+		}
+		time.Sleep(5 * time.Second)
+	}
+	info := fmt.Sprintf("nUnderReplicated: %d", nUnderReplicated)
+	assert.Equal(t, 0, nUnderReplicated, info)
+	network.Reset()
+}
+
+// crashPeer abruptly cuts the ith peer off from the network and then shuts it down.
+func crashPeer(t *testing.T, state *state, network *faults.Network, i int) {
+	addr := state.peerConfigs[i].PublicAddr.String()
+	state.logger.Info("crashing peer", zap.String("peer_address", addr))
+	network.Isolate(addr)
+	assert.Nil(t, state.peers[i].Close())
+}
+
+// restartPeer starts the ith peer back up with the same config (and so same data dir & peer ID).
+func restartPeer(t *testing.T, state *state, network *faults.Network, i int) {
+	addr := state.peerConfigs[i].PublicAddr.String()
+	state.logger.Info("restarting peer", zap.String("peer_address", addr))
+	network.Rejoin(addr)
+	up := make(chan *server.Librarian, 1)
+	errs := make(chan error, 1)
+	go func() {
+		if err := server.Start(state.logger, state.peerConfigs[i], up); err != nil {
+			errs <- err
+		}
+	}()
+	select {
+	case err := <-errs:
+		assert.Nil(t, err)
+	case state.peers[i] = <-up:
+	}
+}
@@ -264,7 +264,7 @@ func testUpload(t *testing.T, params *params, state *state) {
 		}
 
 		// upload the contents
-		_, envKeys[i], err = state.authors[0].Upload(bytes.NewReader(contents[i]), mediaType)
+		_, envKeys[i], err = state.authors[0].Upload(bytes.NewReader(contents[i]), mediaType, nil)
 		assert.Nil(t, err)
 	}
 	state.uploadedDocContents = contents
@@ -2,6 +2,7 @@ package author
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"io"
 	"math/rand"
 	"time"
@@ -24,6 +25,11 @@ import (
 )
 
 var (
+	// ErrStopDownload can be returned by the content writer passed to Download to stop the
+	// download early without an error, e.g., once it has all the content it needs. The rest of
+	// the document's pages then aren't downloaded.
+	ErrStopDownload = errors.New("stop download")
+
 	healthcheckTimeout = 2 * time.Second
 )
 
@@ -78,6 +84,13 @@ type Author struct {
 
 	receiver ship.Receiver
 
+	// publishes and acquires single documents (e.g., pointers) and the librarian clients they
+	// use
+	publisher publish.Publisher
+	acquirer  publish.Acquirer
+	putters   client.PutterBalancer
+	getters   client.GetterBalancer
+
 	// stores Pages in chan to local storage
 	pageSL page.StorerLoader
 
@@ -141,7 +154,11 @@ func NewAuthor(
 	if err != nil {
 		return nil, err
 	}
-	peerSigner := client.NewECDSASigner(clientID.Key())
+	peerSigner, err := newClientSigner(clientID, config.CertificateFile)
+	if err != nil {
+		logger.Error("unable to load client certificate", zap.Error(err))
+		return nil, err
+	}
 	orgSigner := client.NewEmptySigner()
 	if config.OrgID != nil {
 		orgSigner = client.NewECDSASigner(config.OrgID.Key())
@@ -155,7 +172,7 @@ func NewAuthor(
 	ssAcquirer := publish.NewSingleStoreAcquirer(acquirer, documentSL)
 	mlPublisher := publish.NewMultiLoadPublisher(slPublisher, config.Publish)
 	msAcquirer := publish.NewMultiStoreAcquirer(ssAcquirer, config.Publish)
-	shipper := ship.NewShipper(putters, publisher, mlPublisher)
+	shipper := ship.NewShipper(putters, publisher, mlPublisher, documentSL)
 	receiver := ship.NewReceiver(getters, allKeys, acquirer, msAcquirer, documentSL)
 
 	mdEncDec := enc.NewMetadataEncrypterDecrypter()
@@ -179,6 +196,10 @@ func NewAuthor(
 		entryUnpacker:    entryUnpacker,
 		shipper:          shipper,
 		receiver:         receiver,
+		publisher:        publisher,
+		acquirer:         acquirer,
+		putters:          putters,
+		getters:          getters,
 		pageSL:           page.NewStorerLoader(documentSL),
 		signer:           peerSigner,
 		logger:           clientLogger,
@@ -225,9 +246,47 @@ func (a *Author) Healthcheck() (bool, map[string]healthpb.HealthCheckResponse_Se
 	return allHealthy, healthStatus
 }
 
+// UploadOptions are the optional fields of an uploaded document's entry metadata.
+type UploadOptions struct {
+	// Properties are domain-specific key-value pairs, e.g., a pipeline run ID.
+	Properties map[string][]byte
+
+	// Filepath is the relative filepath of the content.
+	Filepath string
+
+	// Schema is the schema of the content.
+	Schema *api.SchemaArtifact
+
+	// DataDictionary is the data dictionary of the content.
+	DataDictionary *api.SchemaArtifact
+}
+
+// metadata returns entry metadata with just the optional fields set, or nil if the options are
+// nil.
+func (o *UploadOptions) metadata() *api.EntryMetadata {
+	if o == nil {
+		return nil
+	}
+	return &api.EntryMetadata{
+		Properties:     o.Properties,
+		Filepath:       o.Filepath,
+		Schema:         o.Schema,
+		DataDictionary: o.DataDictionary,
+	}
+}
+
 // Upload compresses, encrypts, and splits the content into pages and then stores them in the
-// libri network. It returns the uploaded envelope for self-storage and its key.
-func (a *Author) Upload(content io.Reader, mediaType string) (*api.Document, id.ID, error) {
+// libri network. The options, which may be nil, are included in the encrypted entry metadata. It
+// returns the uploaded envelope for self-storage and its key.
+func (a *Author) Upload(content io.Reader, mediaType string, opts *UploadOptions) (
+	*api.Document, id.ID, error) {
+	return a.upload(content, mediaType, opts.metadata())
+}
+
+// upload uploads the content like Upload, including the optional fields of the optional metadata
+// in the entry metadata.
+func (a *Author) upload(content io.Reader, mediaType string, optional *api.EntryMetadata) (
+	*api.Document, id.ID, error) {
 	startTime := time.Now()
 	a.logger.Debug("uploading document")
 
@@ -237,7 +296,7 @@ func (a *Author) Upload(content io.Reader, mediaType string) (*api.Document, id.
 	}
 
 	a.logger.Debug("packing content", packingContentFields(authorPub)...)
-	entry, metadata, err := a.entryPacker.Pack(content, mediaType, eek, authorPub)
+	entry, metadata, err := a.entryPacker.Pack(content, mediaType, optional, eek, authorPub)
 	if err != nil {
 		return nil, nil, a.logAndReturnErr("error packing content", err)
 	}
@@ -254,24 +313,15 @@ func (a *Author) Upload(content io.Reader, mediaType string) (*api.Document, id.
 }
 
 // Download downloads, join, decrypts, and decompressed the content, writing it to a unified output
-// content writer.
+// content writer. Pages are downloaded as they're written, so a writer returning ErrStopDownload
+// stops the download.
 func (a *Author) Download(content io.Writer, envKey id.ID) error {
 	startTime := time.Now()
 	a.logger.Debug("downloading document", downloadingDocFields(envKey)...)
 
-	entry, keys, err := a.receiver.ReceiveEntry(envKey)
-	if err != nil {
-		return a.logAndReturnErr("error receiving entry", err)
-	}
-	entryKey, nPages, err := getEntryInfo(entry)
-	if err != nil {
-		return a.logAndReturnErr("error getting entry info", err)
-	}
-
-	a.logger.Debug("unpacking content", unpackingContentFields(entryKey, nPages)...)
-	metadata, err := a.entryUnpacker.Unpack(content, entry, keys)
+	entryKey, metadata, err := a.receiveAndUnpack(content, envKey)
 	if err != nil {
-		return a.logAndReturnErr("error unpacking content", err)
+		return err
 	}
 
 	elapsedTime := time.Since(startTime)
@@ -281,6 +331,61 @@ func (a *Author) Download(content io.Writer, envKey id.ID) error {
 	return nil
 }
 
+// Info returns the metadata and entry key of the document with the given envelope key. Unlike
+// Download, it receives just the envelope and entry, so none of the pages are downloaded.
+func (a *Author) Info(envKey id.ID) (*api.EntryMetadata, id.ID, error) {
+	a.logger.Debug("getting document info", downloadingDocFields(envKey)...)
+	_, entryKey, metadata, err := a.receiveMetadata(envKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	a.logger.Info("got document info", infoFields(envKey, entryKey, metadata)...)
+	return metadata, entryKey, nil
+}
+
+// receiveMetadata receives just the entry of the given envelope and decrypts its metadata,
+// returning the entry, its key, and the metadata.
+func (a *Author) receiveMetadata(envKey id.ID) (*api.Document, id.ID, *api.EntryMetadata, error) {
+	entry, keys, err := a.receiver.ReceiveEntryOnly(envKey)
+	if err != nil {
+		return nil, nil, nil, a.logAndReturnErr("error receiving entry", err)
+	}
+	entryKey, err := api.GetKey(entry)
+	if err != nil {
+		return nil, nil, nil, a.logAndReturnErr("error getting entry key", err)
+	}
+	metadata, err := a.entryUnpacker.UnpackMetadata(entry, keys)
+	if err != nil {
+		return nil, nil, nil, a.logAndReturnErr("error unpacking metadata", err)
+	}
+	return entry, entryKey, metadata, nil
+}
+
+// receiveAndUnpack receives the entry of the given envelope and unpacks its content, returning
+// the entry key and metadata.
+func (a *Author) receiveAndUnpack(content io.Writer, envKey id.ID) (
+	id.ID, *api.EntryMetadata, error) {
+	entry, keys, err := a.receiver.ReceiveEntryOnly(envKey)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error receiving entry", err)
+	}
+	entryKey, nPages, err := getEntryInfo(entry)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error getting entry info", err)
+	}
+	pageL, err := a.receiver.PageLoader(entry, a.pageSL)
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error receiving pages", err)
+	}
+
+	a.logger.Debug("unpacking content", unpackingContentFields(entryKey, nPages)...)
+	metadata, err := a.entryUnpacker.UnpackFrom(content, entry, keys, pageL)
+	if err != nil && err != ErrStopDownload {
+		return nil, nil, a.logAndReturnErr("error unpacking content", err)
+	}
+	return entryKey, metadata, nil
+}
+
 // Share creates and uploads a new envelope with the given reader public key. The new envelope
 // has the same entry and entry encryption key as that of envelopeKey.
 func (a *Author) Share(envKey id.ID, readerPub *ecdsa.PublicKey) (*api.Document, id.ID, error) {
@@ -321,6 +426,78 @@ func (a *Author) ShareEnvelope(env *api.Envelope, readerPub *ecdsa.PublicKey) (
 	return sharedEnv, sharedEnvKey, nil
 }
 
+// ShareWithGroup shares the document of the envelope with each member of the group it hasn't
+// already been shared with by previous calls, shipping the new envelopes concurrently. The
+// returned report has the result for each member; sharing with one member failing doesn't
+// prevent sharing with the others.
+func (a *Author) ShareWithGroup(envKey id.ID, group *Group) (*GroupShareReport, error) {
+	a.logger.Debug("sharing document with group", sharingGroupFields(envKey, group)...)
+	shared, err := loadSharedReaders(a.clientSL, envKey)
+	if err != nil {
+		return nil, a.logAndReturnErr("error loading shared readers", err)
+	}
+	report := &GroupShareReport{
+		Group:   group.Name,
+		Results: make([]*GroupShareResult, len(group.ReaderPubs)),
+	}
+	toShare := make([]int, 0, len(group.ReaderPubs))
+	for i, readerPub := range group.ReaderPubs {
+		report.Results[i] = &GroupShareResult{ReaderPub: readerPub}
+		if sharedEnvKey, in := shared[EncodeReaderKey(readerPub)]; in {
+			report.Results[i].EnvelopeKey, report.Results[i].Err = id.FromString(sharedEnvKey)
+			report.Results[i].AlreadyShared = true
+			continue
+		}
+		toShare = append(toShare, i)
+	}
+	if len(toShare) == 0 {
+		a.logger.Info("shared document with group", sharedGroupFields(envKey, report)...)
+		return report, nil
+	}
+
+	env, err := a.receiver.ReceiveEnvelope(envKey)
+	if err != nil {
+		return nil, a.logAndReturnErr("error receiving envelope", err)
+	}
+	eek, err := a.receiver.GetEEK(env)
+	if err != nil {
+		return nil, a.logAndReturnErr("error getting EEK", err)
+	}
+	authorKey, err := a.authorKeys.Sample()
+	if err != nil {
+		return nil, a.logAndReturnErr("error sampling author keys", err)
+	}
+	toShip := make([]int, 0, len(toShare))
+	readerPubs := make([][]byte, 0, len(toShare))
+	keks := make([]*enc.KEK, 0, len(toShare))
+	for _, i := range toShare {
+		kek, err := enc.NewKEK(authorKey.Key(), group.ReaderPubs[i])
+		if err != nil {
+			report.Results[i].Err = err
+			continue
+		}
+		toShip = append(toShip, i)
+		readerPubs = append(readerPubs, ecid.ToPublicKeyBytes(group.ReaderPubs[i]))
+		keks = append(keks, kek)
+	}
+
+	entryKey := id.FromBytes(env.EntryKey)
+	_, sharedEnvKeys, errs := a.shipper.ShipEnvelopes(entryKey, authorKey.PublicKeyBytes(),
+		readerPubs, keks, eek)
+	for j, i := range toShip {
+		report.Results[i].EnvelopeKey, report.Results[i].Err = sharedEnvKeys[j], errs[j]
+		if errs[j] == nil {
+			shared[EncodeReaderKey(group.ReaderPubs[i])] = sharedEnvKeys[j].String()
+		}
+	}
+	if err := saveSharedReaders(a.clientSL, envKey, shared); err != nil {
+		return nil, a.logAndReturnErr("error saving shared readers", err)
+	}
+
+	a.logger.Info("shared document with group", sharedGroupFields(envKey, report)...)
+	return report, nil
+}
+
 func (a *Author) logAndReturnErr(msg string, err error) error {
 	a.logger.Error(msg, zap.Error(err))
 	return err
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"encoding/hex"
 	"errors"
 	"io"
 	"io/ioutil"
@@ -19,10 +20,12 @@ import (
 	"github.com/drausin/libri/libri/author/io/publish"
 	"github.com/drausin/libri/libri/author/io/ship"
 	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/db"
 	"github.com/drausin/libri/libri/common/ecid"
 	cerrors "github.com/drausin/libri/libri/common/errors"
 	"github.com/drausin/libri/libri/common/id"
 	clogging "github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/common/storage"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zapcore"
@@ -137,7 +140,7 @@ func TestAuthor_Upload_ok(t *testing.T) {
 	}
 
 	// since everything is mocked, inputs don't really matter
-	actualEnvelope, actualEnvelopeKey, err := a.Upload(nil, "")
+	actualEnvelope, actualEnvelopeKey, err := a.Upload(nil, "", nil)
 	assert.Nil(t, err)
 	assert.NotNil(t, actualEnvelope)
 	assert.Equal(t, expectedEnvKey, actualEnvelopeKey)
@@ -152,7 +155,7 @@ func TestAuthor_Upload_err(t *testing.T) {
 	a.shipper = &fixedShipper{}
 
 	// check pack error bubbles up
-	actualEnvelope, actualEnvelopeKey, err := a.Upload(nil, "")
+	actualEnvelope, actualEnvelopeKey, err := a.Upload(nil, "", nil)
 	assert.NotNil(t, err)
 	assert.Nil(t, actualEnvelope)
 	assert.Nil(t, actualEnvelopeKey)
@@ -161,7 +164,7 @@ func TestAuthor_Upload_err(t *testing.T) {
 	a.shipper = &fixedShipper{err: errors.New("some Ship error")}
 
 	// check pack error bubbles up
-	actualEnvelope, actualEnvelopeKey, err = a.Upload(nil, "")
+	actualEnvelope, actualEnvelopeKey, err = a.Upload(nil, "", nil)
 	assert.NotNil(t, err)
 	assert.Nil(t, actualEnvelope)
 	assert.Nil(t, actualEnvelopeKey)
@@ -210,6 +213,112 @@ func TestAuthor_Download_err(t *testing.T) {
 	}
 	err = a2.Download(nil, docKey)
 	assert.NotNil(t, err)
+
+	// check PageLoader error bubbles up
+	a3 := &Author{
+		logger:        clogging.NewDevInfoLogger(),
+		receiver:      &fixedReceiver{entry: doc, pageLoaderErr: errors.New("some error")},
+		entryUnpacker: &fixedUnpacker{},
+	}
+	err = a3.Download(nil, docKey)
+	assert.NotNil(t, err)
+
+	// check stopping the download isn't an error
+	a4 := &Author{
+		logger:        clogging.NewDevInfoLogger(),
+		receiver:      &fixedReceiver{entry: doc},
+		entryUnpacker: &fixedUnpacker{metadata: &api.EntryMetadata{}, err: ErrStopDownload},
+	}
+	err = a4.Download(nil, docKey)
+	assert.Nil(t, err)
+}
+
+func TestAuthor_Info_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newMemNetworkTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	page.MinSize = 64 // just for testing
+	a.config.Print.PageSize = 128
+
+	content := common.NewCompressableBytes(rng, 1024).Bytes()
+	env, envKey, err := a.Upload(bytes.NewReader(content), "application/x-pdf", nil)
+	assert.Nil(t, err)
+
+	metadata, entryKey, err := a.Info(envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, id.FromBytes(env.GetEnvelope().EntryKey), entryKey)
+	assert.Equal(t, "application/x-pdf", metadata.MediaType)
+	assert.Equal(t, uint64(len(content)), metadata.UncompressedSize)
+}
+
+func TestAuthor_Upload_options(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newMemNetworkTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	page.MinSize = 64 // just for testing
+	a.config.Print.PageSize = 128
+
+	opts := &UploadOptions{
+		Properties: map[string][]byte{"run_id": []byte("1234")},
+		Filepath:   "some/file.csv",
+		Schema: &api.SchemaArtifact{
+			Group:   "drausin",
+			Project: "libri",
+			Path:    "libri/librarian/api/documents.proto",
+			Name:    "EntryMetadata",
+			Version: "0.1.0",
+		},
+		DataDictionary: &api.SchemaArtifact{
+			Group:   "drausin",
+			Project: "libri",
+			Path:    "README.md",
+			Version: "0.1.0",
+		},
+	}
+	content := common.NewCompressableBytes(rng, 1024).Bytes()
+	_, envKey, err := a.Upload(bytes.NewReader(content), "text/csv", opts)
+	assert.Nil(t, err)
+
+	metadata, _, err := a.Info(envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, opts.Properties, metadata.Properties)
+	assert.Equal(t, opts.Filepath, metadata.Filepath)
+	assert.Equal(t, opts.Schema, metadata.Schema)
+	assert.Equal(t, opts.DataDictionary, metadata.DataDictionary)
+
+	// check invalid options error
+	opts.Schema = &api.SchemaArtifact{Group: "drausin"}
+	env, envKey, err := a.Upload(bytes.NewReader(content), "text/csv", opts)
+	assert.Equal(t, api.ErrIncompleteSchemaArtifact, err)
+	assert.Nil(t, env)
+	assert.Nil(t, envKey)
+}
+
+func TestAuthor_Info_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	doc, docKey := api.NewTestDocument(rng)
+
+	// check Receive error bubbles up
+	a1 := &Author{
+		logger:        clogging.NewDevInfoLogger(),
+		receiver:      &fixedReceiver{receiveEntryErr: errors.New("some Receive error")},
+		entryUnpacker: &fixedUnpacker{},
+	}
+	metadata, entryKey, err := a1.Info(docKey)
+	assert.NotNil(t, err)
+	assert.Nil(t, metadata)
+	assert.Nil(t, entryKey)
+
+	// check UnpackMetadata error bubbles up
+	a2 := &Author{
+		logger:        clogging.NewDevInfoLogger(),
+		receiver:      &fixedReceiver{entry: doc},
+		entryUnpacker: &fixedUnpacker{err: errors.New("some UnpackMetadata error")},
+	}
+	metadata, entryKey, err = a2.Info(docKey)
+	assert.NotNil(t, err)
+	assert.Nil(t, metadata)
+	assert.Nil(t, entryKey)
 }
 
 func TestAuthor_UploadDownload(t *testing.T) {
@@ -226,7 +335,7 @@ func TestAuthor_UploadDownload(t *testing.T) {
 	ssAcquirer := publish.NewSingleStoreAcquirer(pubAcq, a.documentSLD)
 	mlPublisher := publish.NewMultiLoadPublisher(slPublisher, a.config.Publish)
 	msAcquirer := publish.NewMultiStoreAcquirer(ssAcquirer, a.config.Publish)
-	a.shipper = ship.NewShipper(&fixedPutterBalancer{}, pubAcq, mlPublisher)
+	a.shipper = ship.NewShipper(&fixedPutterBalancer{}, pubAcq, mlPublisher, a.documentSLD)
 	a.receiver = ship.NewReceiver(&fixedGetterBalancer{}, a.selfReaderKeys, pubAcq,
 		msAcquirer, a.documentSLD)
 
@@ -242,7 +351,7 @@ func TestAuthor_UploadDownload(t *testing.T) {
 		content1 := common.NewCompressableBytes(rng, c.uncompressedSize)
 		content1Bytes := content1.Bytes()
 
-		envelope, envelopeKey, err := a.Upload(content1, c.mediaType)
+		envelope, envelopeKey, err := a.Upload(content1, c.mediaType, nil)
 		assert.Nil(t, err)
 		assert.NotNil(t, envelope)
 		assert.NotNil(t, envelopeKey)
@@ -359,6 +468,143 @@ func TestAuthor_Share_err(t *testing.T) {
 	assert.Nil(t, envID)
 }
 
+func TestAuthor_ShareWithGroup_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	envKey := id.NewPseudoRandom(rng)
+	group := newTestGroup(rng, "team", 8)
+	failing := ecid.ToPublicKeyBytes(group.ReaderPubs[2])
+	shipper := &fixedShipper{
+		errReaders: map[string]struct{}{hex.EncodeToString(failing): {}},
+	}
+	a := &Author{
+		clientSL: storage.NewClientSL(db.NewMemoryDB()),
+		receiver: &fixedReceiver{
+			envelope: api.NewTestEnvelope(rng),
+			eek:      enc.NewPseudoRandomEEK(rng),
+		},
+		authorKeys: keychain.New(1),
+		shipper:    shipper,
+		logger:     clogging.NewDevLogger(zapcore.DebugLevel),
+	}
+
+	report, err := a.ShareWithGroup(envKey, group)
+	assert.Nil(t, err)
+	assert.Equal(t, "team", report.Group)
+	assert.Equal(t, len(group.ReaderPubs), len(report.Results))
+	assert.Equal(t, len(group.ReaderPubs)-1, report.NShared())
+	assert.Equal(t, 0, report.NAlreadyShared())
+	assert.Equal(t, 1, report.NFailed())
+	assert.Equal(t, len(group.ReaderPubs), len(shipper.shipped))
+	for i, result := range report.Results {
+		assert.Equal(t, group.ReaderPubs[i], result.ReaderPub)
+		assert.Equal(t, i == 2, result.Err != nil)
+		assert.Equal(t, i != 2, result.EnvelopeKey != nil)
+	}
+
+	// re-sharing after adding new members only ships to new & previously failing members
+	shipper.shipped, shipper.errReaders = nil, nil
+	group.ReaderPubs = append(group.ReaderPubs, newTestGroup(rng, "team", 2).ReaderPubs...)
+	report, err = a.ShareWithGroup(envKey, group)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, report.NShared())
+	assert.Equal(t, 7, report.NAlreadyShared())
+	assert.Equal(t, 0, report.NFailed())
+	assert.Equal(t, 3, len(shipper.shipped))
+	assert.Equal(t, failing, shipper.shipped[0])
+	for _, result := range report.Results {
+		assert.NotNil(t, result.EnvelopeKey)
+	}
+
+	// re-sharing with no new members doesn't ship anything
+	shipper.shipped = nil
+	a.receiver = &fixedReceiver{receiveEnvelopeErr: errors.New("some ReceiveEnvelope error")}
+	report, err = a.ShareWithGroup(envKey, group)
+	assert.Nil(t, err)
+	assert.Equal(t, len(group.ReaderPubs), report.NAlreadyShared())
+	assert.Zero(t, len(shipper.shipped))
+
+	// other envelopes are shared separately
+	a.receiver = &fixedReceiver{envelope: api.NewTestEnvelope(rng)}
+	report, err = a.ShareWithGroup(id.NewPseudoRandom(rng), group)
+	assert.Nil(t, err)
+	assert.Equal(t, len(group.ReaderPubs), report.NShared())
+}
+
+func TestAuthor_ShareWithGroup_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	envKey := id.NewPseudoRandom(rng)
+	group := newTestGroup(rng, "team", 4)
+	badCurvePK, err := ecdsa.GenerateKey(elliptic.P256(), rng)
+	assert.Nil(t, err)
+	newAuthor := func() *Author {
+		return &Author{
+			clientSL: storage.NewClientSL(db.NewMemoryDB()),
+			receiver: &fixedReceiver{
+				envelope: api.NewTestEnvelope(rng),
+				eek:      enc.NewPseudoRandomEEK(rng),
+			},
+			authorKeys: keychain.New(1),
+			shipper:    &fixedShipper{},
+			logger:     clogging.NewDevLogger(zapcore.DebugLevel),
+		}
+	}
+
+	// check load shared readers error bubbles up
+	a1 := newAuthor()
+	a1.clientSL = &storage.TestSLD{LoadErr: errors.New("some Load error")}
+	report, err := a1.ShareWithGroup(envKey, group)
+	assert.NotNil(t, err)
+	assert.Nil(t, report)
+
+	// check ReceiveEnvelope error bubbles up
+	a2 := newAuthor()
+	a2.receiver = &fixedReceiver{receiveEnvelopeErr: errors.New("some ReceiveEnvelope error")}
+	report, err = a2.ShareWithGroup(envKey, group)
+	assert.NotNil(t, err)
+	assert.Nil(t, report)
+
+	// check GetEEK error bubbles up
+	a3 := newAuthor()
+	a3.receiver = &fixedReceiver{getErrkErr: errors.New("some GetEEK error")}
+	report, err = a3.ShareWithGroup(envKey, group)
+	assert.NotNil(t, err)
+	assert.Nil(t, report)
+
+	// check Sample error bubbles up
+	a4 := newAuthor()
+	a4.authorKeys = &fixedKeychain{sampleErr: errors.New("some Sample error")}
+	report, err = a4.ShareWithGroup(envKey, group)
+	assert.NotNil(t, err)
+	assert.Nil(t, report)
+
+	// check save shared readers error bubbles up
+	a5 := newAuthor()
+	a5.clientSL = &storage.TestSLD{StoreErr: errors.New("some Store error")}
+	report, err = a5.ShareWithGroup(envKey, group)
+	assert.NotNil(t, err)
+	assert.Nil(t, report)
+
+	// check NewKEK error only fails that member
+	a6 := newAuthor()
+	badGroup := &Group{
+		Name:       "bad",
+		ReaderPubs: append([]*ecdsa.PublicKey{&badCurvePK.PublicKey}, group.ReaderPubs...),
+	}
+	report, err = a6.ShareWithGroup(envKey, badGroup)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.NFailed())
+	assert.NotNil(t, report.Results[0].Err)
+	assert.Equal(t, len(group.ReaderPubs), report.NShared())
+}
+
+func newTestGroup(rng *rand.Rand, name string, nMembers int) *Group {
+	group := &Group{Name: name, ReaderPubs: make([]*ecdsa.PublicKey, nMembers)}
+	for i := range group.ReaderPubs {
+		group.ReaderPubs[i] = &ecid.NewPseudoRandom(rng).Key().PublicKey
+	}
+	return group
+}
+
 type fixedEntryPacker struct {
 	entry    *api.Document
 	metadata *api.EntryMetadata
@@ -366,7 +612,11 @@ type fixedEntryPacker struct {
 }
 
 func (f *fixedEntryPacker) Pack(
-	content io.Reader, mediaType string, keys *enc.EEK, authorPub []byte,
+	content io.Reader,
+	mediaType string,
+	optional *api.EntryMetadata,
+	keys *enc.EEK,
+	authorPub []byte,
 ) (*api.Document, *api.EntryMetadata, error) {
 	return f.entry, f.metadata, f.err
 }
@@ -375,6 +625,8 @@ type fixedShipper struct {
 	envelope    *api.Document
 	envelopeKey id.ID
 	err         error
+	errReaders  map[string]struct{}
+	shipped     [][]byte
 }
 
 func (f *fixedShipper) ShipEntry(
@@ -389,10 +641,29 @@ func (f *fixedShipper) ShipEnvelope(
 	return f.envelope, f.envelopeKey, f.err
 }
 
+func (f *fixedShipper) ShipEnvelopes(
+	entryKey id.ID, authorPub []byte, readerPubs [][]byte, keks []*enc.KEK, eek *enc.EEK,
+) ([]*api.Document, []id.ID, []error) {
+	envs := make([]*api.Document, len(readerPubs))
+	envKeys := make([]id.ID, len(readerPubs))
+	errs := make([]error, len(readerPubs))
+	for i, readerPub := range readerPubs {
+		f.shipped = append(f.shipped, readerPub)
+		if _, in := f.errReaders[hex.EncodeToString(readerPub)]; in {
+			errs[i] = errors.New("some ShipEnvelopes error")
+			continue
+		}
+		envs[i] = f.envelope
+		envKeys[i] = id.FromBytes(readerPub[1:])
+	}
+	return envs, envKeys, errs
+}
+
 type fixedReceiver struct {
 	entry              *api.Document
 	keys               *enc.EEK
 	receiveEntryErr    error
+	pageLoaderErr      error
 	envelope           *api.Envelope
 	receiveEnvelopeErr error
 	eek                *enc.EEK
@@ -403,6 +674,15 @@ func (f *fixedReceiver) ReceiveEntry(envelopeKey id.ID) (*api.Document, *enc.EEK
 	return f.entry, f.keys, f.receiveEntryErr
 }
 
+func (f *fixedReceiver) ReceiveEntryOnly(envelopeKey id.ID) (*api.Document, *enc.EEK, error) {
+	return f.entry, f.keys, f.receiveEntryErr
+}
+
+func (f *fixedReceiver) PageLoader(entryDoc *api.Document, inner page.Loader) (
+	page.Loader, error) {
+	return inner, f.pageLoaderErr
+}
+
 func (f *fixedReceiver) ReceiveEnvelope(envelopeKey id.ID) (*api.Envelope, error) {
 	return f.envelope, f.receiveEnvelopeErr
 }
@@ -421,6 +701,17 @@ func (f *fixedUnpacker) Unpack(content io.Writer, entry *api.Document, keys *enc
 	return f.metadata, f.err
 }
 
+func (f *fixedUnpacker) UnpackFrom(
+	content io.Writer, entry *api.Document, keys *enc.EEK, pageL page.Loader,
+) (*api.EntryMetadata, error) {
+	return f.metadata, f.err
+}
+
+func (f *fixedUnpacker) UnpackMetadata(entry *api.Document, keys *enc.EEK) (
+	*api.EntryMetadata, error) {
+	return f.metadata, f.err
+}
+
 type memPublisherAcquirer struct {
 	docs map[string]*api.Document
 	mu   sync.Mutex
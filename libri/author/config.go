@@ -24,6 +24,10 @@ const (
 	// DefaultLogLevel is the default log level to use.
 	DefaultLogLevel = zap.InfoLevel
 
+	// DefaultFileParallelism is the default number of files simultaneously uploaded or
+	// downloaded with a directory.
+	DefaultFileParallelism = 3
+
 	// DataSubdir is the name of the data directory.
 	DataSubdir = "author-data"
 
@@ -32,6 +36,16 @@ const (
 
 	// KeychainSubDir is the default DB subdirectory within the data dir.
 	KeychainSubDir = "keychain"
+
+	// ContactsFilename is the default name of the contact book file within the data dir.
+	ContactsFilename = "contacts.json"
+
+	// GroupsFilename is the default name of the reader groups file within the data dir.
+	GroupsFilename = "groups.json"
+
+	// DaemonSocketFilename is the default name of the author daemon socket file within the data
+	// dir.
+	DaemonSocketFilename = "author.sock"
 )
 
 // Config is used to configure an Author.
@@ -46,9 +60,25 @@ type Config struct {
 	// KeychainDir is the local directory where the author keys are stored.
 	KeychainDir string
 
+	// ContactsFile is the local file with the contact book of reader public keys the author
+	// shares documents with.
+	ContactsFile string
+
+	// GroupsFile is the local file with the named groups of reader public keys the author
+	// shares documents with.
+	GroupsFile string
+
+	// DaemonSocketFile is the local Unix socket file the author daemon listens on and author
+	// commands use the daemon through when it's running.
+	DaemonSocketFile string
+
 	// OrgID is the organization ID of the peer, if one exists.
 	OrgID ecid.ID
 
+	// CertificateFile is the file with the organization certificate for the client public key
+	// that the author presents with its requests. When empty, no certificate is presented.
+	CertificateFile string
+
 	// LibrarianAddrs is a list of public addresses of Librarian servers to issue request to.
 	LibrarianAddrs []*net.TCPAddr
 
@@ -58,6 +88,10 @@ type Config struct {
 	// Publish defines parameters for publishing pages to libri.
 	Publish *publish.Parameters
 
+	// FileParallelism is the number of files simultaneously uploaded or downloaded with a
+	// directory.
+	FileParallelism uint32
+
 	// LogLevel is the log level
 	LogLevel zapcore.Level
 }
@@ -71,9 +105,13 @@ func NewDefaultConfig() *Config {
 	config.WithDefaultDataDir()
 	config.WithDefaultDBDir()
 	config.WithDefaultKeychainDir()
+	config.WithDefaultContactsFile()
+	config.WithDefaultGroupsFile()
+	config.WithDefaultDaemonSocketFile()
 	config.WithDefaultLibrarianAddrs()
 	config.WithDefaultPrint()
 	config.WithDefaultPublish()
+	config.WithDefaultFileParallelism()
 	config.WithDefaultLogLevel()
 
 	return config
@@ -127,6 +165,54 @@ func (c *Config) WithDefaultKeychainDir() *Config {
 	return c
 }
 
+// WithContactsFile sets the contacts file to the given value or the default if the given value is
+// empty.
+func (c *Config) WithContactsFile(contactsFile string) *Config {
+	if contactsFile == "" {
+		return c.WithDefaultContactsFile()
+	}
+	c.ContactsFile = contactsFile
+	return c
+}
+
+// WithDefaultContactsFile sets the contacts file to a local name within the data dir.
+func (c *Config) WithDefaultContactsFile() *Config {
+	c.ContactsFile = filepath.Join(c.DataDir, ContactsFilename)
+	return c
+}
+
+// WithGroupsFile sets the groups file to the given value or the default if the given value is
+// empty.
+func (c *Config) WithGroupsFile(groupsFile string) *Config {
+	if groupsFile == "" {
+		return c.WithDefaultGroupsFile()
+	}
+	c.GroupsFile = groupsFile
+	return c
+}
+
+// WithDefaultGroupsFile sets the groups file to a local name within the data dir.
+func (c *Config) WithDefaultGroupsFile() *Config {
+	c.GroupsFile = filepath.Join(c.DataDir, GroupsFilename)
+	return c
+}
+
+// WithDaemonSocketFile sets the daemon socket file to the given value or the default if the given
+// value is empty.
+func (c *Config) WithDaemonSocketFile(daemonSocketFile string) *Config {
+	if daemonSocketFile == "" {
+		return c.WithDefaultDaemonSocketFile()
+	}
+	c.DaemonSocketFile = daemonSocketFile
+	return c
+}
+
+// WithDefaultDaemonSocketFile sets the daemon socket file to a local name within the data dir.
+func (c *Config) WithDefaultDaemonSocketFile() *Config {
+	c.DaemonSocketFile = filepath.Join(c.DataDir, DaemonSocketFilename)
+	return c
+}
+
 // WithLibrarianAddrs sets the librarian addresses to the given value or the default if the given
 // value is empty.
 func (c *Config) WithLibrarianAddrs(librarianAddrs []*net.TCPAddr) *Config {
@@ -178,6 +264,27 @@ func (c *Config) WithDefaultPublish() *Config {
 	return c
 }
 
+// WithFileParallelism sets the file parallelism to the given value or the default if it is zero.
+func (c *Config) WithFileParallelism(fileParallelism uint32) *Config {
+	if fileParallelism == 0 {
+		return c.WithDefaultFileParallelism()
+	}
+	c.FileParallelism = fileParallelism
+	return c
+}
+
+// WithDefaultFileParallelism sets the file parallelism to the default value.
+func (c *Config) WithDefaultFileParallelism() *Config {
+	c.FileParallelism = DefaultFileParallelism
+	return c
+}
+
+// WithCertificateFile sets the certificate file, which may be empty to present no certificate.
+func (c *Config) WithCertificateFile(certificateFile string) *Config {
+	c.CertificateFile = certificateFile
+	return c
+}
+
 // WithLogLevel sets the log level to the given value, though this doesn't have any direct effect
 // on the creation of the logger instance.
 func (c *Config) WithLogLevel(logLevel zapcore.Level) *Config {
@@ -16,9 +16,13 @@ func TestNewDefaultConfig(t *testing.T) {
 	assert.NotEmpty(t, c.DataDir)
 	assert.NotEmpty(t, c.DbDir)
 	assert.NotEmpty(t, c.KeychainDir)
+	assert.NotEmpty(t, c.ContactsFile)
+	assert.NotEmpty(t, c.GroupsFile)
+	assert.NotEmpty(t, c.DaemonSocketFile)
 	assert.NotEmpty(t, c.LibrarianAddrs)
 	assert.NotEmpty(t, c.Print)
 	assert.NotEmpty(t, c.Publish)
+	assert.NotZero(t, c.FileParallelism)
 }
 
 func TestConfig_WithDataDir(t *testing.T) {
@@ -42,6 +46,28 @@ func TestConfig_WithKeychainDir(t *testing.T) {
 	assert.NotEqual(t, c1.KeychainDir, c3.WithKeychainDir("/some/other/dir").KeychainDir)
 }
 
+func TestConfig_WithContactsFile(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultContactsFile()
+	assert.Equal(t, c1.ContactsFile, c2.WithContactsFile("").ContactsFile)
+	assert.NotEqual(t, c1.ContactsFile, c3.WithContactsFile("/some/contacts.json").ContactsFile)
+}
+
+func TestConfig_WithGroupsFile(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultGroupsFile()
+	assert.Equal(t, c1.GroupsFile, c2.WithGroupsFile("").GroupsFile)
+	assert.NotEqual(t, c1.GroupsFile, c3.WithGroupsFile("/some/groups.json").GroupsFile)
+}
+
+func TestConfig_WithDaemonSocketFile(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultDaemonSocketFile()
+	assert.Equal(t, c1.DaemonSocketFile, c2.WithDaemonSocketFile("").DaemonSocketFile)
+	assert.NotEqual(t, c1.DaemonSocketFile,
+		c3.WithDaemonSocketFile("/some/author.sock").DaemonSocketFile)
+}
+
 func TestConfig_WithBootstrapAddrs(t *testing.T) {
 	c1, c2, c3 := &Config{}, &Config{}, &Config{}
 	c1.WithDefaultLibrarianAddrs()
@@ -74,6 +100,13 @@ func TestConfig_WithPublish(t *testing.T) {
 	)
 }
 
+func TestConfig_WithFileParallelism(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultFileParallelism()
+	assert.Equal(t, c1.FileParallelism, c2.WithFileParallelism(0).FileParallelism)
+	assert.NotEqual(t, c1.FileParallelism, c3.WithFileParallelism(8).FileParallelism)
+}
+
 func TestConfig_WithLogLevel(t *testing.T) {
 	c1, c2, c3 := &Config{}, &Config{}, &Config{}
 	c1.WithDefaultLogLevel()
@@ -83,3 +116,9 @@ func TestConfig_WithLogLevel(t *testing.T) {
 		c3.WithLogLevel(zapcore.DebugLevel).LogLevel,
 	)
 }
+
+func TestConfig_WithCertificateFile(t *testing.T) {
+	c := &Config{}
+	assert.Equal(t, "", c.CertificateFile)
+	assert.Equal(t, "client.cert", c.WithCertificateFile("client.cert").CertificateFile)
+}
@@ -0,0 +1,139 @@
+package author
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/drausin/libri/libri/common/ecid"
+)
+
+var (
+	// ErrContactExists indicates when adding a contact whose name is already in the contact
+	// book.
+	ErrContactExists = errors.New("contact already exists")
+
+	// ErrContactNotFound indicates when a contact is not in the contact book.
+	ErrContactNotFound = errors.New("contact not found")
+
+	// ErrEmptyContactName indicates when a contact name is empty.
+	ErrEmptyContactName = errors.New("empty contact name")
+)
+
+// Contacts is a contact book of the reader public keys of the people an author shares documents
+// with.
+type Contacts struct {
+	// ReaderKeys are the encoded (see EncodeReaderKey) reader public keys of each contact, keyed
+	// by contact name.
+	ReaderKeys map[string]string `json:"reader_keys"`
+}
+
+// NewContacts returns an empty Contacts.
+func NewContacts() *Contacts {
+	return &Contacts{
+		ReaderKeys: map[string]string{},
+	}
+}
+
+// Add adds the contact with the given reader public key, returning ErrContactExists if there's
+// already a contact with that name.
+func (c *Contacts) Add(name string, readerPub *ecdsa.PublicKey) error {
+	if name == "" {
+		return ErrEmptyContactName
+	}
+	if _, in := c.ReaderKeys[name]; in {
+		return fmt.Errorf("%s: %s", ErrContactExists, name)
+	}
+	c.ReaderKeys[name] = EncodeReaderKey(readerPub)
+	return nil
+}
+
+// Get returns the reader public key of the contact.
+func (c *Contacts) Get(name string) (*ecdsa.PublicKey, error) {
+	encoded, in := c.ReaderKeys[name]
+	if !in {
+		return nil, fmt.Errorf("%s: %s", ErrContactNotFound, name)
+	}
+	return DecodeReaderKey(encoded)
+}
+
+// Remove removes the contact from the contact book, returning whether it was there.
+func (c *Contacts) Remove(name string) bool {
+	_, in := c.ReaderKeys[name]
+	delete(c.ReaderKeys, name)
+	return in
+}
+
+// Names returns the sorted names of the contacts.
+func (c *Contacts) Names() []string {
+	names := make([]string, 0, len(c.ReaderKeys))
+	for name := range c.ReaderKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate returns an error if any of the contact names are empty or reader keys are invalid.
+func (c *Contacts) Validate() error {
+	for name, encoded := range c.ReaderKeys {
+		if name == "" {
+			return ErrEmptyContactName
+		}
+		if _, err := DecodeReaderKey(encoded); err != nil {
+			return fmt.Errorf("invalid reader key for contact %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// ReadContacts reads Contacts from a JSON file.
+func ReadContacts(filepath string) (*Contacts, error) {
+	buf, err := ioutil.ReadFile(filepath) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	c := NewContacts()
+	if err := json.Unmarshal(buf, c); err != nil {
+		return nil, err
+	}
+	if c.ReaderKeys == nil {
+		c.ReaderKeys = map[string]string{}
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// WriteContacts writes Contacts to a JSON file.
+func WriteContacts(filepath string, c *Contacts) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	const filePerm = 0644
+	return ioutil.WriteFile(filepath, buf, filePerm)
+}
+
+// EncodeReaderKey encodes the reader public key as the hex of its compressed representation, a
+// portable form for handing to other authors.
+func EncodeReaderKey(readerPub *ecdsa.PublicKey) string {
+	return hex.EncodeToString(ecid.ToPublicKeyBytes(readerPub))
+}
+
+// DecodeReaderKey decodes a reader public key encoded by EncodeReaderKey.
+func DecodeReaderKey(encoded string) (*ecdsa.PublicKey, error) {
+	buf, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return ecid.FromPublicKeyBytes(buf)
+}
@@ -0,0 +1,112 @@
+package author
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContacts_AddGetRemove(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	alice, bob := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	c := NewContacts()
+
+	assert.Nil(t, c.Add("alice", &alice.Key().PublicKey))
+	assert.Nil(t, c.Add("bob", &bob.Key().PublicKey))
+	assert.Equal(t, []string{"alice", "bob"}, c.Names())
+
+	pub, err := c.Get("alice")
+	assert.Nil(t, err)
+	assert.Equal(t, alice.PublicKeyBytes(), ecid.ToPublicKeyBytes(pub))
+
+	// can't add existing or empty name
+	assert.NotNil(t, c.Add("alice", &bob.Key().PublicKey))
+	assert.Equal(t, ErrEmptyContactName, c.Add("", &bob.Key().PublicKey))
+
+	assert.True(t, c.Remove("alice"))
+	assert.False(t, c.Remove("alice"))
+	pub, err = c.Get("alice")
+	assert.NotNil(t, err)
+	assert.Nil(t, pub)
+	assert.Equal(t, []string{"bob"}, c.Names())
+}
+
+func TestContacts_Validate(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	c := NewContacts()
+	assert.Nil(t, c.Validate())
+	assert.Nil(t, c.Add("alice", &ecid.NewPseudoRandom(rng).Key().PublicKey))
+	assert.Nil(t, c.Validate())
+
+	cases := map[string]string{
+		"":          EncodeReaderKey(&ecid.NewPseudoRandom(rng).Key().PublicKey),
+		"not hex":   "not hex",
+		"too short": "02abcd",
+	}
+	for name, encoded := range cases {
+		c2 := NewContacts()
+		c2.ReaderKeys[name] = encoded
+		assert.NotNil(t, c2.Validate(), name)
+	}
+}
+
+func TestReadWriteContacts(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "test-contacts")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	fp := filepath.Join(dir, ContactsFilename)
+
+	c1 := NewContacts()
+	assert.Nil(t, c1.Add("alice", &ecid.NewPseudoRandom(rng).Key().PublicKey))
+	assert.Nil(t, WriteContacts(fp, c1))
+	c2, err := ReadContacts(fp)
+	assert.Nil(t, err)
+	assert.Equal(t, c1, c2)
+
+	// empty file contents still has contacts map
+	assert.Nil(t, ioutil.WriteFile(fp, []byte("{}"), 0600))
+	c3, err := ReadContacts(fp)
+	assert.Nil(t, err)
+	assert.NotNil(t, c3.ReaderKeys)
+
+	// bad JSON
+	assert.Nil(t, ioutil.WriteFile(fp, []byte("not json"), 0600))
+	c4, err := ReadContacts(fp)
+	assert.NotNil(t, err)
+	assert.Nil(t, c4)
+
+	// invalid key
+	assert.Nil(t, ioutil.WriteFile(fp, []byte(`{"reader_keys":{"alice":"02"}}`), 0600))
+	c5, err := ReadContacts(fp)
+	assert.NotNil(t, err)
+	assert.Nil(t, c5)
+	c1.ReaderKeys["bob"] = "02"
+	assert.NotNil(t, WriteContacts(fp, c1))
+
+	// missing file
+	c6, err := ReadContacts(filepath.Join(dir, "missing.json"))
+	assert.True(t, os.IsNotExist(err))
+	assert.Nil(t, c6)
+}
+
+func TestEncodeDecodeReaderKey(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for c := 0; c < 8; c++ {
+		readerID := ecid.NewPseudoRandom(rng)
+		encoded := EncodeReaderKey(&readerID.Key().PublicKey)
+		pub, err := DecodeReaderKey(encoded)
+		assert.Nil(t, err)
+		assert.Equal(t, readerID.Key().PublicKey.X, pub.X)
+		assert.Equal(t, readerID.Key().PublicKey.Y, pub.Y)
+	}
+
+	pub, err := DecodeReaderKey("not hex")
+	assert.NotNil(t, err)
+	assert.Nil(t, pub)
+}
@@ -0,0 +1,315 @@
+package daemon
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+var (
+	// ErrNotRunning indicates that no daemon is listening on the socket.
+	ErrNotRunning = errors.New("author daemon not running")
+
+	// ErrUnexpectedNResults indicates that a ShareWithGroup response didn't have a result for
+	// each group member.
+	ErrUnexpectedNResults = errors.New("unexpected number of group share results")
+)
+
+// Client performs Author operations via a running daemon.
+type Client struct {
+	conn *grpc.ClientConn
+	rpc  AuthorDaemonClient
+}
+
+// Dial connects to the daemon listening on the Unix socket at the given path, returning
+// ErrNotRunning if the socket doesn't exist or nothing accepts connections on it within the
+// timeout.
+func Dial(socketPath string, timeout time.Duration) (*Client, error) {
+	if _, err := os.Stat(socketPath); err != nil {
+		return nil, ErrNotRunning
+	}
+	dialer := func(addr string, timeout time.Duration) (net.Conn, error) {
+		return net.DialTimeout("unix", addr, timeout)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, socketPath, grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithDialer(dialer))
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	return &Client{conn: conn, rpc: NewAuthorDaemonClient(conn)}, nil
+}
+
+// Upload uploads the content via the daemon, returning the uploaded envelope key. The options may
+// be nil.
+func (c *Client) Upload(content io.Reader, mediaType string, opts *author.UploadOptions) (
+	id.ID, error) {
+	return c.UploadVersion(content, mediaType, nil, opts)
+}
+
+// UploadVersion uploads the content via the daemon like Upload, recording the given lineage in
+// the encrypted entry metadata. The lineage and options may be nil.
+func (c *Client) UploadVersion(
+	content io.Reader, mediaType string, lineage *author.Lineage, opts *author.UploadOptions,
+) (id.ID, error) {
+	stream, err := c.rpc.Upload(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	rq := &UploadRequest{Header: &UploadHeader{MediaType: mediaType}}
+	if opts != nil {
+		rq.Header.Properties = opts.Properties
+		rq.Header.Filepath = opts.Filepath
+		rq.Header.Schema = opts.Schema
+		rq.Header.DataDictionary = opts.DataDictionary
+	}
+	if lineage != nil {
+		if lineage.PreviousEnvelopeKey != nil {
+			rq.Header.PreviousEnvelopeKey = lineage.PreviousEnvelopeKey.Bytes()
+		}
+		for _, entryKey := range lineage.DerivedFromEntryKeys {
+			rq.Header.DerivedFromEntryKeys = append(rq.Header.DerivedFromEntryKeys,
+				entryKey.Bytes())
+		}
+	}
+	if err = sendContent(stream, rq, content); err != nil {
+		return nil, err
+	}
+	rp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	return id.FromBytes(rp.EnvelopeKey), nil
+}
+
+// sendContent sends the initial request and then the content in chunks. A daemon error ends the
+// stream early, so it's left for CloseAndRecv to return.
+func sendContent(stream AuthorDaemon_UploadClient, rq *UploadRequest, content io.Reader) error {
+	if err := stream.Send(rq); err != nil {
+		return sendErr(err)
+	}
+	buf := make([]byte, ChunkSize)
+	for {
+		n, err := content.Read(buf)
+		if n > 0 {
+			if err2 := stream.Send(&UploadRequest{Content: buf[:n]}); err2 != nil {
+				return sendErr(err2)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sendErr returns nil for the io.EOF a stream Send returns when the daemon has ended the stream.
+func sendErr(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// Download downloads the content of the document with the given envelope key via the daemon,
+// stopping early without an error if the content writer returns author.ErrStopDownload.
+func (c *Client) Download(content io.Writer, envKey id.ID) error {
+	return c.download(content, &DownloadRequest{EnvelopeKey: envKey.Bytes()})
+}
+
+// DownloadVersion downloads the given version number from the history of the document with the
+// given envelope key via the daemon.
+func (c *Client) DownloadVersion(content io.Writer, envKey id.ID, number int) error {
+	return c.download(content, &DownloadRequest{
+		EnvelopeKey: envKey.Bytes(),
+		Version:     uint32(number),
+	})
+}
+
+func (c *Client) download(content io.Writer, rq *DownloadRequest) error {
+	// canceling the context ends the stream if we stop receiving before its end
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := c.rpc.Download(ctx, rq)
+	if err != nil {
+		return err
+	}
+	for {
+		rp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := content.Write(rp.Content); err == author.ErrStopDownload {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// Share shares the document with the given envelope key with a reader via the daemon, returning
+// the key of the new envelope.
+func (c *Client) Share(envKey id.ID, readerPub *ecdsa.PublicKey) (id.ID, error) {
+	rp, err := c.rpc.Share(context.Background(), &ShareRequest{
+		EnvelopeKey:     envKey.Bytes(),
+		ReaderPublicKey: ecid.ToPublicKeyBytes(readerPub),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return id.FromBytes(rp.EnvelopeKey), nil
+}
+
+// Info returns the metadata and entry key of the document with the given envelope key via the
+// daemon.
+func (c *Client) Info(envKey id.ID) (*api.EntryMetadata, id.ID, error) {
+	rp, err := c.rpc.Info(context.Background(), &InfoRequest{EnvelopeKey: envKey.Bytes()})
+	if err != nil {
+		return nil, nil, err
+	}
+	return rp.Metadata, id.FromBytes(rp.EntryKey), nil
+}
+
+// UploadDir uploads each file in the local directory tree along with a manifest via the daemon,
+// returning the manifest envelope key. Since the daemon reads the files itself, it must be able
+// to read the directory.
+func (c *Client) UploadDir(dirpath string) (id.ID, error) {
+	absDirpath, err := filepath.Abs(dirpath)
+	if err != nil {
+		return nil, err
+	}
+	rp, err := c.rpc.UploadDir(context.Background(), &UploadDirRequest{Dirpath: absDirpath})
+	if err != nil {
+		return nil, err
+	}
+	return id.FromBytes(rp.EnvelopeKey), nil
+}
+
+// DownloadDir downloads the files of the manifest with the given envelope key into the local
+// directory via the daemon, returning the number of files downloaded. Since the daemon writes the
+// files itself, it must be able to write to the directory.
+func (c *Client) DownloadDir(dirpath string, envKey id.ID) (int, error) {
+	absDirpath, err := filepath.Abs(dirpath)
+	if err != nil {
+		return 0, err
+	}
+	rp, err := c.rpc.DownloadDir(context.Background(), &DownloadDirRequest{
+		EnvelopeKey: envKey.Bytes(),
+		Dirpath:     absDirpath,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(rp.NDownloaded), nil
+}
+
+// History returns the versions of the document with the given envelope key via the daemon,
+// ordered from the first version to the given one.
+func (c *Client) History(envKey id.ID) ([]*author.Version, error) {
+	rp, err := c.rpc.History(context.Background(), &HistoryRequest{EnvelopeKey: envKey.Bytes()})
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]*author.Version, len(rp.Versions))
+	for i, v := range rp.Versions {
+		versions[i] = &author.Version{
+			Number:      int(v.Number),
+			EnvelopeKey: id.FromBytes(v.EnvelopeKey),
+			EntryKey:    id.FromBytes(v.EntryKey),
+			CreatedTime: time.Unix(int64(v.CreatedTime), 0),
+			Metadata:    v.Metadata,
+		}
+	}
+	return versions, nil
+}
+
+// Publish points the named pointer at the document with the given envelope key via the daemon,
+// returning the pointer key.
+func (c *Client) Publish(name string, envKey id.ID) (id.ID, error) {
+	rp, err := c.rpc.Publish(context.Background(), &PublishRequest{
+		Name:        name,
+		EnvelopeKey: envKey.Bytes(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return id.FromBytes(rp.PointerKey), nil
+}
+
+// Resolve returns the latest version of the daemon author's pointer with the given name.
+func (c *Client) Resolve(name string) (*api.Pointer, error) {
+	return c.resolve(&ResolveRequest{Name: name})
+}
+
+// ResolveKey returns the latest version of the pointer with the given key via the daemon.
+func (c *Client) ResolveKey(pointerKey id.ID) (*api.Pointer, error) {
+	return c.resolve(&ResolveRequest{PointerKey: pointerKey.Bytes()})
+}
+
+func (c *Client) resolve(rq *ResolveRequest) (*api.Pointer, error) {
+	rp, err := c.rpc.Resolve(context.Background(), rq)
+	if err != nil {
+		return nil, err
+	}
+	return rp.Pointer, nil
+}
+
+// ShareWithGroup shares the document with the given envelope key with each member of the group
+// via the daemon, returning the result for each member.
+func (c *Client) ShareWithGroup(envKey id.ID, group *author.Group) (
+	*author.GroupShareReport, error) {
+	rq := &ShareWithGroupRequest{
+		EnvelopeKey:      envKey.Bytes(),
+		Group:            group.Name,
+		ReaderPublicKeys: make([][]byte, len(group.ReaderPubs)),
+	}
+	for i, readerPub := range group.ReaderPubs {
+		rq.ReaderPublicKeys[i] = ecid.ToPublicKeyBytes(readerPub)
+	}
+	rp, err := c.rpc.ShareWithGroup(context.Background(), rq)
+	if err != nil {
+		return nil, err
+	}
+	if len(rp.Results) != len(group.ReaderPubs) {
+		return nil, ErrUnexpectedNResults
+	}
+	report := &author.GroupShareReport{
+		Group:   group.Name,
+		Results: make([]*author.GroupShareResult, len(rp.Results)),
+	}
+	for i, result := range rp.Results {
+		report.Results[i] = &author.GroupShareResult{
+			ReaderPub:     group.ReaderPubs[i],
+			AlreadyShared: result.AlreadyShared,
+		}
+		if result.EnvelopeKey != nil {
+			report.Results[i].EnvelopeKey = id.FromBytes(result.EnvelopeKey)
+		}
+		if result.Error != "" {
+			report.Results[i].Err = errors.New(result.Error)
+		}
+	}
+	return report, nil
+}
+
+// Close closes the connection to the daemon.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,30 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/author"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDial_notRunning(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-daemon")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	socketPath := filepath.Join(dir, author.DaemonSocketFilename)
+
+	// missing socket
+	c, err := Dial(socketPath, 100*time.Millisecond)
+	assert.Equal(t, ErrNotRunning, err)
+	assert.Nil(t, c)
+
+	// nothing listening on socket
+	assert.Nil(t, ioutil.WriteFile(socketPath, nil, 0600))
+	c, err = Dial(socketPath, 100*time.Millisecond)
+	assert.Equal(t, ErrNotRunning, err)
+	assert.Nil(t, c)
+}
@@ -0,0 +1,388 @@
+package daemon
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+const (
+	// ChunkSize is the max number of content bytes in each Upload or Download stream message.
+	ChunkSize = 256 * 1024
+
+	// socketMode allows only the daemon's user to connect to its socket.
+	socketMode = 0600
+
+	// socketUmask is the umask that creates the socket with socketMode.
+	socketUmask = 0777 &^ socketMode
+
+	envelopeKeyName         = "envelope key"
+	previousEnvelopeKeyName = "previous envelope key"
+	derivedFromEntryKeyName = "derived from entry key"
+	pointerKeyName          = "pointer key"
+)
+
+var (
+	// ErrDaemonRunning indicates that another daemon is already listening on the socket.
+	ErrDaemonRunning = errors.New("author daemon already running")
+
+	// ErrMissingUploadHeader indicates that the first request of an Upload stream had no header.
+	ErrMissingUploadHeader = errors.New("missing upload header")
+
+	// ErrRelativeDirpath indicates that an UploadDir or DownloadDir request had a relative
+	// dirpath, which the daemon would resolve against its own working directory.
+	ErrRelativeDirpath = errors.New("dirpath must be absolute")
+
+	// ErrPointerNameAndKey indicates that a Resolve request had neither or both of a pointer
+	// name and key.
+	ErrPointerNameAndKey = errors.New("must give exactly one of a pointer name and key")
+)
+
+// Author is the subset of *author.Author operations served by the daemon.
+type Author interface {
+	Upload(content io.Reader, mediaType string, opts *author.UploadOptions) (
+		*api.Document, id.ID, error)
+	Download(content io.Writer, envKey id.ID) error
+	Share(envKey id.ID, readerPub *ecdsa.PublicKey) (*api.Document, id.ID, error)
+	Info(envKey id.ID) (*api.EntryMetadata, id.ID, error)
+	UploadVersion(content io.Reader, mediaType string, lineage *author.Lineage,
+		opts *author.UploadOptions) (*api.Document, id.ID, error)
+	DownloadVersion(content io.Writer, envKey id.ID, number int) (*author.Version, error)
+	UploadDir(dirpath string) (*api.Document, id.ID, *api.Manifest, error)
+	DownloadDir(dirpath string, envKey id.ID) (*api.Manifest, int, error)
+	History(envKey id.ID) ([]*author.Version, error)
+	Publish(name string, envKey id.ID) (*api.Document, id.ID, error)
+	Resolve(name string) (*api.Pointer, error)
+	ResolveKey(pointerKey id.ID) (*api.Pointer, error)
+	ShareWithGroup(envKey id.ID, group *author.Group) (*author.GroupShareReport, error)
+}
+
+// Server serves the operations of an Author with unlocked keychains to local clients.
+type Server interface {
+	AuthorDaemonServer
+
+	// Serve accepts connections on the listener until Stop is called.
+	Serve(lis net.Listener) error
+
+	// Stop gracefully stops serving, waiting for in-progress operations to finish.
+	Stop()
+}
+
+type server struct {
+	author Author
+	grpc   *grpc.Server
+}
+
+// NewServer returns a new daemon Server for the given author.
+func NewServer(author Author) Server {
+	s := &server{
+		author: author,
+		grpc:   grpc.NewServer(),
+	}
+	RegisterAuthorDaemonServer(s.grpc, s)
+	return s
+}
+
+func (s *server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+func (s *server) Stop() {
+	s.grpc.GracefulStop()
+}
+
+// Upload uploads the content streamed after the initial header.
+func (s *server) Upload(stream AuthorDaemon_UploadServer) error {
+	rq, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if rq.Header == nil {
+		return ErrMissingUploadHeader
+	}
+	content := &uploadReader{stream: stream, buf: rq.Content}
+	opts := &author.UploadOptions{
+		Properties:     rq.Header.Properties,
+		Filepath:       rq.Header.Filepath,
+		Schema:         rq.Header.Schema,
+		DataDictionary: rq.Header.DataDictionary,
+	}
+	lineage, err := getLineage(rq.Header)
+	if err != nil {
+		return err
+	}
+	var envKey id.ID
+	if lineage != nil {
+		_, envKey, err = s.author.UploadVersion(content, rq.Header.MediaType, lineage, opts)
+	} else {
+		_, envKey, err = s.author.Upload(content, rq.Header.MediaType, opts)
+	}
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&UploadResponse{EnvelopeKey: envKey.Bytes()})
+}
+
+// getLineage returns the lineage given in the upload header, or nil if it has none.
+func getLineage(header *UploadHeader) (*author.Lineage, error) {
+	if header.PreviousEnvelopeKey == nil && len(header.DerivedFromEntryKeys) == 0 {
+		return nil, nil
+	}
+	lineage := &author.Lineage{}
+	if header.PreviousEnvelopeKey != nil {
+		err := api.ValidateBytes(header.PreviousEnvelopeKey, id.Length, previousEnvelopeKeyName)
+		if err != nil {
+			return nil, err
+		}
+		lineage.PreviousEnvelopeKey = id.FromBytes(header.PreviousEnvelopeKey)
+	}
+	for _, entryKey := range header.DerivedFromEntryKeys {
+		if err := api.ValidateBytes(entryKey, id.Length, derivedFromEntryKeyName); err != nil {
+			return nil, err
+		}
+		lineage.DerivedFromEntryKeys = append(lineage.DerivedFromEntryKeys, id.FromBytes(entryKey))
+	}
+	return lineage, nil
+}
+
+// Download streams the content of a document, or of one of its versions, in chunks.
+func (s *server) Download(rq *DownloadRequest, stream AuthorDaemon_DownloadServer) error {
+	if err := api.ValidateBytes(rq.EnvelopeKey, id.Length, envelopeKeyName); err != nil {
+		return err
+	}
+	content := bufio.NewWriterSize(&downloadWriter{stream: stream}, ChunkSize)
+	var err error
+	if rq.Version != 0 {
+		_, err = s.author.DownloadVersion(content, id.FromBytes(rq.EnvelopeKey),
+			int(rq.Version))
+	} else {
+		err = s.author.Download(content, id.FromBytes(rq.EnvelopeKey))
+	}
+	if err != nil {
+		return err
+	}
+	return content.Flush()
+}
+
+// Share shares a document with a reader.
+func (s *server) Share(ctx context.Context, rq *ShareRequest) (*ShareResponse, error) {
+	if err := api.ValidateBytes(rq.EnvelopeKey, id.Length, envelopeKeyName); err != nil {
+		return nil, err
+	}
+	readerPub, err := ecid.FromPublicKeyBytes(rq.ReaderPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	_, envKey, err := s.author.Share(id.FromBytes(rq.EnvelopeKey), readerPub)
+	if err != nil {
+		return nil, err
+	}
+	return &ShareResponse{EnvelopeKey: envKey.Bytes()}, nil
+}
+
+// Info returns the metadata of a document.
+func (s *server) Info(ctx context.Context, rq *InfoRequest) (*InfoResponse, error) {
+	if err := api.ValidateBytes(rq.EnvelopeKey, id.Length, envelopeKeyName); err != nil {
+		return nil, err
+	}
+	metadata, entryKey, err := s.author.Info(id.FromBytes(rq.EnvelopeKey))
+	if err != nil {
+		return nil, err
+	}
+	return &InfoResponse{Metadata: metadata, EntryKey: entryKey.Bytes()}, nil
+}
+
+// UploadDir uploads each file in a local directory tree along with a manifest.
+func (s *server) UploadDir(ctx context.Context, rq *UploadDirRequest) (
+	*UploadDirResponse, error) {
+	if !filepath.IsAbs(rq.Dirpath) {
+		return nil, ErrRelativeDirpath
+	}
+	_, envKey, _, err := s.author.UploadDir(rq.Dirpath)
+	if err != nil {
+		return nil, err
+	}
+	return &UploadDirResponse{EnvelopeKey: envKey.Bytes()}, nil
+}
+
+// DownloadDir downloads the files of a manifest into a local directory.
+func (s *server) DownloadDir(ctx context.Context, rq *DownloadDirRequest) (
+	*DownloadDirResponse, error) {
+	if err := api.ValidateBytes(rq.EnvelopeKey, id.Length, envelopeKeyName); err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(rq.Dirpath) {
+		return nil, ErrRelativeDirpath
+	}
+	_, nDownloaded, err := s.author.DownloadDir(rq.Dirpath, id.FromBytes(rq.EnvelopeKey))
+	if err != nil {
+		return nil, err
+	}
+	return &DownloadDirResponse{NDownloaded: uint32(nDownloaded)}, nil
+}
+
+// History returns the versions of a document.
+func (s *server) History(ctx context.Context, rq *HistoryRequest) (*HistoryResponse, error) {
+	if err := api.ValidateBytes(rq.EnvelopeKey, id.Length, envelopeKeyName); err != nil {
+		return nil, err
+	}
+	versions, err := s.author.History(id.FromBytes(rq.EnvelopeKey))
+	if err != nil {
+		return nil, err
+	}
+	rp := &HistoryResponse{Versions: make([]*Version, len(versions))}
+	for i, v := range versions {
+		rp.Versions[i] = &Version{
+			Number:      uint32(v.Number),
+			EnvelopeKey: v.EnvelopeKey.Bytes(),
+			EntryKey:    v.EntryKey.Bytes(),
+			CreatedTime: uint32(v.CreatedTime.Unix()),
+			Metadata:    v.Metadata,
+		}
+	}
+	return rp, nil
+}
+
+// Publish points a named pointer at a document.
+func (s *server) Publish(ctx context.Context, rq *PublishRequest) (*PublishResponse, error) {
+	if err := api.ValidateBytes(rq.EnvelopeKey, id.Length, envelopeKeyName); err != nil {
+		return nil, err
+	}
+	_, pointerKey, err := s.author.Publish(rq.Name, id.FromBytes(rq.EnvelopeKey))
+	if err != nil {
+		return nil, err
+	}
+	return &PublishResponse{PointerKey: pointerKey.Bytes()}, nil
+}
+
+// Resolve returns the latest version of a pointer.
+func (s *server) Resolve(ctx context.Context, rq *ResolveRequest) (*ResolveResponse, error) {
+	if (rq.Name == "") == (rq.PointerKey == nil) {
+		return nil, ErrPointerNameAndKey
+	}
+	var pointer *api.Pointer
+	var err error
+	if rq.PointerKey != nil {
+		if err = api.ValidateBytes(rq.PointerKey, id.Length, pointerKeyName); err != nil {
+			return nil, err
+		}
+		pointer, err = s.author.ResolveKey(id.FromBytes(rq.PointerKey))
+	} else {
+		pointer, err = s.author.Resolve(rq.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ResolveResponse{Pointer: pointer}, nil
+}
+
+// ShareWithGroup shares a document with each member of a group.
+func (s *server) ShareWithGroup(ctx context.Context, rq *ShareWithGroupRequest) (
+	*ShareWithGroupResponse, error) {
+	if err := api.ValidateBytes(rq.EnvelopeKey, id.Length, envelopeKeyName); err != nil {
+		return nil, err
+	}
+	group := &author.Group{
+		Name:       rq.Group,
+		ReaderPubs: make([]*ecdsa.PublicKey, len(rq.ReaderPublicKeys)),
+	}
+	for i, readerPubBytes := range rq.ReaderPublicKeys {
+		readerPub, err := ecid.FromPublicKeyBytes(readerPubBytes)
+		if err != nil {
+			return nil, err
+		}
+		group.ReaderPubs[i] = readerPub
+	}
+	report, err := s.author.ShareWithGroup(id.FromBytes(rq.EnvelopeKey), group)
+	if err != nil {
+		return nil, err
+	}
+	rp := &ShareWithGroupResponse{Results: make([]*GroupShareResult, len(report.Results))}
+	for i, result := range report.Results {
+		rp.Results[i] = &GroupShareResult{
+			ReaderPublicKey: rq.ReaderPublicKeys[i],
+			AlreadyShared:   result.AlreadyShared,
+		}
+		if result.EnvelopeKey != nil {
+			rp.Results[i].EnvelopeKey = result.EnvelopeKey.Bytes()
+		}
+		if result.Err != nil {
+			rp.Results[i].Error = result.Err.Error()
+		}
+	}
+	return rp, nil
+}
+
+// Listen listens on a Unix socket at the given path that only the current user may connect to,
+// removing a stale socket left by a daemon that didn't stop cleanly. It returns ErrDaemonRunning
+// if another daemon is already listening on the socket.
+func Listen(socketPath string) (net.Listener, error) {
+	if _, err := os.Stat(socketPath); err == nil {
+		if conn, err := net.DialTimeout("unix", socketPath, time.Second); err == nil {
+			_ = conn.Close()
+			return nil, ErrDaemonRunning
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, err
+		}
+	}
+	// create the socket with its final permissions rather than changing them after listening,
+	// which would leave a window where other users could connect
+	oldUmask := syscall.Umask(socketUmask)
+	lis, err := net.Listen("unix", socketPath)
+	syscall.Umask(oldUmask)
+	if err != nil {
+		return nil, err
+	}
+	return lis, nil
+}
+
+// uploadReader reads the content chunks of an Upload stream.
+type uploadReader struct {
+	stream AuthorDaemon_UploadServer
+	buf    []byte
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		rq, err := r.stream.Recv()
+		if err != nil {
+			return 0, err // includes io.EOF at end of stream
+		}
+		r.buf = rq.Content
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// downloadWriter writes content to a Download stream in chunks.
+type downloadWriter struct {
+	stream AuthorDaemon_DownloadServer
+}
+
+func (w *downloadWriter) Write(p []byte) (int, error) {
+	for i := 0; i < len(p); i += ChunkSize {
+		j := i + ChunkSize
+		if j > len(p) {
+			j = len(p)
+		}
+		if err := w.stream.Send(&DownloadResponse{Content: p[i:j]}); err != nil {
+			return i, err
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,599 @@
+package daemon
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestServer_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := &fixedAuthor{
+		envKey:   id.NewPseudoRandom(rng),
+		entryKey: id.NewPseudoRandom(rng),
+		metadata: &api.EntryMetadata{MediaType: "text/plain", Filepath: "some/file.txt"},
+	}
+	c, stop := startTestServer(t, a)
+	defer stop()
+
+	// content spans multiple chunks
+	content := api.RandBytes(rng, 2*ChunkSize+1)
+	opts := &author.UploadOptions{
+		Properties: map[string][]byte{"key": []byte("value")},
+		Filepath:   "some/file.txt",
+		Schema: &api.SchemaArtifact{
+			Group: "group", Project: "project", Path: "path", Version: "1.0.0",
+		},
+	}
+	envKey, err := c.Upload(bytes.NewReader(content), "text/plain", opts)
+	assert.Nil(t, err)
+	assert.Equal(t, a.envKey, envKey)
+	assert.Equal(t, content, a.uploaded)
+	assert.Equal(t, "text/plain", a.mediaType)
+	assert.Equal(t, opts.Properties, a.opts.Properties)
+	assert.Equal(t, opts.Filepath, a.opts.Filepath)
+	assert.Equal(t, opts.Schema.String(), a.opts.Schema.String())
+	assert.Nil(t, a.opts.DataDictionary)
+
+	// no options
+	_, err = c.Upload(bytes.NewReader(content), "text/plain", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "", a.opts.Filepath)
+
+	downloaded := new(bytes.Buffer)
+	err = c.Download(downloaded, envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, content, downloaded.Bytes())
+	assert.Equal(t, envKey, a.requestedKey)
+
+	readerPub := &ecid.NewPseudoRandom(rng).Key().PublicKey
+	sharedEnvKey, err := c.Share(envKey, readerPub)
+	assert.Nil(t, err)
+	assert.Equal(t, a.envKey, sharedEnvKey)
+	assert.Equal(t, readerPub, a.readerPub)
+
+	metadata, entryKey, err := c.Info(envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, a.metadata.String(), metadata.String())
+	assert.Equal(t, a.entryKey, entryKey)
+}
+
+func TestServer_versions(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := &fixedAuthor{
+		envKey: id.NewPseudoRandom(rng),
+		versions: []*author.Version{
+			{
+				Number:      1,
+				EnvelopeKey: id.NewPseudoRandom(rng),
+				EntryKey:    id.NewPseudoRandom(rng),
+				CreatedTime: time.Unix(1, 0),
+				Metadata:    &api.EntryMetadata{MediaType: "text/plain"},
+			},
+			{
+				Number:      2,
+				EnvelopeKey: id.NewPseudoRandom(rng),
+				EntryKey:    id.NewPseudoRandom(rng),
+				CreatedTime: time.Unix(2, 0),
+				Metadata:    &api.EntryMetadata{MediaType: "text/csv"},
+			},
+		},
+	}
+	c, stop := startTestServer(t, a)
+	defer stop()
+
+	content := api.RandBytes(rng, 1024)
+	lineage := &author.Lineage{
+		PreviousEnvelopeKey:  id.NewPseudoRandom(rng),
+		DerivedFromEntryKeys: []id.ID{id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)},
+	}
+	envKey, err := c.UploadVersion(bytes.NewReader(content), "text/plain", lineage, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, a.envKey, envKey)
+	assert.Equal(t, content, a.uploaded)
+	assert.Equal(t, lineage, a.lineage)
+
+	// only derived from keys
+	lineage = &author.Lineage{DerivedFromEntryKeys: []id.ID{id.NewPseudoRandom(rng)}}
+	_, err = c.UploadVersion(bytes.NewReader(content), "text/plain", lineage, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, lineage, a.lineage)
+
+	// no lineage uses Upload
+	a.lineage = nil
+	_, err = c.UploadVersion(bytes.NewReader(content), "text/plain", nil, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, a.lineage)
+
+	downloaded := new(bytes.Buffer)
+	err = c.DownloadVersion(downloaded, envKey, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, content, downloaded.Bytes())
+	assert.Equal(t, envKey, a.requestedKey)
+	assert.Equal(t, 1, a.number)
+
+	versions, err := c.History(envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, envKey, a.requestedKey)
+	assert.Len(t, versions, len(a.versions))
+	for i, v := range versions {
+		assert.Equal(t, a.versions[i].Number, v.Number)
+		assert.Equal(t, a.versions[i].EnvelopeKey, v.EnvelopeKey)
+		assert.Equal(t, a.versions[i].EntryKey, v.EntryKey)
+		assert.Equal(t, a.versions[i].CreatedTime, v.CreatedTime)
+		assert.Equal(t, a.versions[i].Metadata.String(), v.Metadata.String())
+	}
+}
+
+func TestServer_dirs(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := &fixedAuthor{envKey: id.NewPseudoRandom(rng), nDownloaded: 3}
+	c, stop := startTestServer(t, a)
+	defer stop()
+
+	envKey, err := c.UploadDir("some/dir")
+	assert.Nil(t, err)
+	assert.Equal(t, a.envKey, envKey)
+	absDirpath, err := filepath.Abs("some/dir")
+	assert.Nil(t, err)
+	assert.Equal(t, absDirpath, a.dirpath)
+
+	nDownloaded, err := c.DownloadDir("/some/other/dir", envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, a.nDownloaded, nDownloaded)
+	assert.Equal(t, "/some/other/dir", a.dirpath)
+	assert.Equal(t, envKey, a.requestedKey)
+}
+
+func TestServer_pointers(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := &fixedAuthor{
+		envKey:  id.NewPseudoRandom(rng),
+		pointer: &api.Pointer{Name: "some-name", Sequence: 2},
+	}
+	c, stop := startTestServer(t, a)
+	defer stop()
+
+	envKey := id.NewPseudoRandom(rng)
+	pointerKey, err := c.Publish("some-name", envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, a.envKey, pointerKey)
+	assert.Equal(t, "some-name", a.name)
+	assert.Equal(t, envKey, a.requestedKey)
+
+	a.name = ""
+	pointer, err := c.Resolve("some-name")
+	assert.Nil(t, err)
+	assert.Equal(t, a.pointer.String(), pointer.String())
+	assert.Equal(t, "some-name", a.name)
+
+	pointer, err = c.ResolveKey(pointerKey)
+	assert.Nil(t, err)
+	assert.Equal(t, a.pointer.String(), pointer.String())
+	assert.Equal(t, pointerKey, a.requestedKey)
+}
+
+func TestServer_ShareWithGroup(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	group := &author.Group{Name: "some-group"}
+	for i := 0; i < 3; i++ {
+		group.ReaderPubs = append(group.ReaderPubs, &ecid.NewPseudoRandom(rng).Key().PublicKey)
+	}
+	a := &fixedAuthor{report: &author.GroupShareReport{
+		Group: group.Name,
+		Results: []*author.GroupShareResult{
+			{ReaderPub: group.ReaderPubs[0], EnvelopeKey: id.NewPseudoRandom(rng)},
+			{
+				ReaderPub:     group.ReaderPubs[1],
+				EnvelopeKey:   id.NewPseudoRandom(rng),
+				AlreadyShared: true,
+			},
+			{ReaderPub: group.ReaderPubs[2], Err: errors.New("some share error")},
+		},
+	}}
+	c, stop := startTestServer(t, a)
+	defer stop()
+
+	envKey := id.NewPseudoRandom(rng)
+	report, err := c.ShareWithGroup(envKey, group)
+	assert.Nil(t, err)
+	assert.Equal(t, envKey, a.requestedKey)
+	assert.Equal(t, group, a.group)
+	assert.Equal(t, a.report, report)
+	assert.Equal(t, 1, report.NFailed())
+}
+
+func TestServer_Upload_shortChunks(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := &fixedAuthor{envKey: id.NewPseudoRandom(rng)}
+	c, stop := startTestServer(t, a)
+	defer stop()
+	content := api.RandBytes(rng, 1024)
+
+	// content read by the client in chunks much smaller than ChunkSize
+	envKey, err := c.Upload(iotest.OneByteReader(bytes.NewReader(content)), "text/plain", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, a.envKey, envKey)
+	assert.Equal(t, content, a.uploaded)
+
+	// header content followed by empty and short chunks
+	a.uploaded = nil
+	stream := &fixedUploadServer{rqs: []*UploadRequest{
+		{Header: &UploadHeader{MediaType: "text/plain"}, Content: content[:10]},
+		{},
+		{Content: content[10:11]},
+		{Content: content[11:]},
+	}}
+	err = NewServer(a).Upload(stream)
+	assert.Nil(t, err)
+	assert.Equal(t, content, a.uploaded)
+	assert.Equal(t, a.envKey.Bytes(), stream.rp.EnvelopeKey)
+}
+
+func TestServer_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := &fixedAuthor{err: errors.New("some author error")}
+	c, stop := startTestServer(t, a)
+	defer stop()
+	envKey := id.NewPseudoRandom(rng)
+
+	content := api.RandBytes(rng, 2*ChunkSize+1)
+	_, err := c.Upload(bytes.NewReader(content), "text/plain", nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+
+	err = c.Download(new(bytes.Buffer), envKey)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+
+	_, err = c.Share(envKey, &ecid.NewPseudoRandom(rng).Key().PublicKey)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+
+	_, _, err = c.Info(envKey)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+
+	lineage := &author.Lineage{PreviousEnvelopeKey: envKey}
+	_, err = c.UploadVersion(bytes.NewReader(content), "text/plain", lineage, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+
+	err = c.DownloadVersion(new(bytes.Buffer), envKey, 1)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+
+	_, err = c.UploadDir("some/dir")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+
+	_, err = c.DownloadDir("some/dir", envKey)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+
+	_, err = c.History(envKey)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+
+	_, err = c.Publish("some-name", envKey)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+
+	_, err = c.Resolve("some-name")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+
+	_, err = c.ResolveKey(envKey)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+
+	_, err = c.ShareWithGroup(envKey, &author.Group{Name: "some-group"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "some author error")
+}
+
+func TestClient_Download_stop(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := &fixedAuthor{uploaded: api.RandBytes(rng, 4*ChunkSize)}
+	c, stop := startTestServer(t, a)
+	defer stop()
+
+	w := &stoppingWriter{max: ChunkSize}
+	err := c.Download(w, id.NewPseudoRandom(rng))
+	assert.Nil(t, err)
+	assert.Equal(t, a.uploaded[:ChunkSize], w.buf.Bytes())
+}
+
+func TestClient_ShareWithGroup_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	group := &author.Group{
+		Name:       "some-group",
+		ReaderPubs: []*ecdsa.PublicKey{&ecid.NewPseudoRandom(rng).Key().PublicKey},
+	}
+	a := &fixedAuthor{report: &author.GroupShareReport{Group: group.Name}}
+	c, stop := startTestServer(t, a)
+	defer stop()
+
+	// response without a result for each member
+	report, err := c.ShareWithGroup(id.NewPseudoRandom(rng), group)
+	assert.Equal(t, ErrUnexpectedNResults, err)
+	assert.Nil(t, report)
+}
+
+func TestServer_badRequests(t *testing.T) {
+	s := NewServer(&fixedAuthor{})
+	ctx := context.Background()
+
+	_, err := s.Share(ctx, &ShareRequest{EnvelopeKey: []byte{1, 2, 3}})
+	assert.NotNil(t, err)
+	_, err = s.Share(ctx, &ShareRequest{
+		EnvelopeKey:     id.NewPseudoRandom(rand.New(rand.NewSource(0))).Bytes(),
+		ReaderPublicKey: []byte{1, 2, 3},
+	})
+	assert.NotNil(t, err)
+	_, err = s.Info(ctx, &InfoRequest{EnvelopeKey: []byte{1, 2, 3}})
+	assert.NotNil(t, err)
+	err = s.Download(&DownloadRequest{EnvelopeKey: []byte{1, 2, 3}}, nil)
+	assert.NotNil(t, err)
+	err = s.Upload(&fixedUploadServer{rqs: []*UploadRequest{{Content: []byte{1, 2, 3}}}})
+	assert.Equal(t, ErrMissingUploadHeader, err)
+	err = s.Upload(&fixedUploadServer{})
+	assert.Equal(t, io.EOF, err)
+
+	badKeyHeaders := []*UploadHeader{
+		{PreviousEnvelopeKey: []byte{1, 2, 3}},
+		{DerivedFromEntryKeys: [][]byte{{1, 2, 3}}},
+	}
+	for _, header := range badKeyHeaders {
+		err = s.Upload(&fixedUploadServer{rqs: []*UploadRequest{{Header: header}}})
+		assert.NotNil(t, err)
+	}
+
+	envKey := id.NewPseudoRandom(rand.New(rand.NewSource(0))).Bytes()
+	_, err = s.UploadDir(ctx, &UploadDirRequest{Dirpath: "some/dir"})
+	assert.Equal(t, ErrRelativeDirpath, err)
+	_, err = s.DownloadDir(ctx, &DownloadDirRequest{EnvelopeKey: []byte{1, 2, 3}})
+	assert.NotNil(t, err)
+	_, err = s.DownloadDir(ctx, &DownloadDirRequest{EnvelopeKey: envKey, Dirpath: "some/dir"})
+	assert.Equal(t, ErrRelativeDirpath, err)
+	_, err = s.History(ctx, &HistoryRequest{EnvelopeKey: []byte{1, 2, 3}})
+	assert.NotNil(t, err)
+	_, err = s.Publish(ctx, &PublishRequest{Name: "some-name", EnvelopeKey: []byte{1, 2, 3}})
+	assert.NotNil(t, err)
+	_, err = s.Resolve(ctx, &ResolveRequest{})
+	assert.Equal(t, ErrPointerNameAndKey, err)
+	_, err = s.Resolve(ctx, &ResolveRequest{Name: "some-name", PointerKey: envKey})
+	assert.Equal(t, ErrPointerNameAndKey, err)
+	_, err = s.Resolve(ctx, &ResolveRequest{PointerKey: []byte{1, 2, 3}})
+	assert.NotNil(t, err)
+	_, err = s.ShareWithGroup(ctx, &ShareWithGroupRequest{EnvelopeKey: []byte{1, 2, 3}})
+	assert.NotNil(t, err)
+	_, err = s.ShareWithGroup(ctx, &ShareWithGroupRequest{
+		EnvelopeKey:      envKey,
+		ReaderPublicKeys: [][]byte{{1, 2, 3}},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestListen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-daemon")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	socketPath := filepath.Join(dir, author.DaemonSocketFilename)
+
+	umask := syscall.Umask(0)
+	syscall.Umask(umask)
+	lis, err := Listen(socketPath)
+	assert.Nil(t, err)
+	info, err := os.Stat(socketPath)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(socketMode), info.Mode().Perm())
+
+	// check the process umask is restored
+	assert.Equal(t, umask, syscall.Umask(umask))
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	// socket in use
+	lis2, err := Listen(socketPath)
+	assert.Equal(t, ErrDaemonRunning, err)
+	assert.Nil(t, lis2)
+	assert.Nil(t, lis.Close())
+
+	// stale socket left behind
+	stale, err := net.Listen("unix", socketPath)
+	assert.Nil(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	assert.Nil(t, stale.Close())
+	_, err = os.Stat(socketPath)
+	assert.Nil(t, err)
+	lis, err = Listen(socketPath)
+	assert.Nil(t, err)
+	assert.Nil(t, lis.Close())
+
+	// bad socket path
+	lis, err = Listen(filepath.Join(dir, "missing", author.DaemonSocketFilename))
+	assert.NotNil(t, err)
+	assert.Nil(t, lis)
+}
+
+func startTestServer(t *testing.T, a Author) (*Client, func()) {
+	dir, err := ioutil.TempDir("", "test-daemon")
+	assert.Nil(t, err)
+	socketPath := filepath.Join(dir, author.DaemonSocketFilename)
+	lis, err := Listen(socketPath)
+	assert.Nil(t, err)
+	s := NewServer(a)
+	go func() { assert.Nil(t, s.Serve(lis)) }()
+	c, err := Dial(socketPath, time.Second)
+	assert.Nil(t, err)
+	return c, func() {
+		assert.Nil(t, c.Close())
+		s.Stop()
+		assert.Nil(t, os.RemoveAll(dir))
+	}
+}
+
+type fixedAuthor struct {
+	envKey   id.ID
+	entryKey id.ID
+	metadata *api.EntryMetadata
+	err      error
+
+	versions    []*author.Version
+	nDownloaded int
+	pointer     *api.Pointer
+	report      *author.GroupShareReport
+
+	uploaded     []byte
+	mediaType    string
+	opts         *author.UploadOptions
+	lineage      *author.Lineage
+	number       int
+	dirpath      string
+	name         string
+	group        *author.Group
+	requestedKey id.ID
+	readerPub    *ecdsa.PublicKey
+}
+
+func (f *fixedAuthor) Upload(content io.Reader, mediaType string, opts *author.UploadOptions) (
+	*api.Document, id.ID, error) {
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	uploaded, err := ioutil.ReadAll(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	f.uploaded, f.mediaType, f.opts = uploaded, mediaType, opts
+	return nil, f.envKey, nil
+}
+
+func (f *fixedAuthor) Download(content io.Writer, envKey id.ID) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.requestedKey = envKey
+	_, err := io.Copy(content, bytes.NewReader(f.uploaded))
+	return err
+}
+
+func (f *fixedAuthor) Share(envKey id.ID, readerPub *ecdsa.PublicKey) (
+	*api.Document, id.ID, error) {
+	f.readerPub = readerPub
+	return nil, f.envKey, f.err
+}
+
+func (f *fixedAuthor) Info(envKey id.ID) (*api.EntryMetadata, id.ID, error) {
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	return f.metadata, f.entryKey, nil
+}
+
+func (f *fixedAuthor) UploadVersion(
+	content io.Reader, mediaType string, lineage *author.Lineage, opts *author.UploadOptions,
+) (*api.Document, id.ID, error) {
+	f.lineage = lineage
+	return f.Upload(content, mediaType, opts)
+}
+
+func (f *fixedAuthor) DownloadVersion(content io.Writer, envKey id.ID, number int) (
+	*author.Version, error) {
+	f.number = number
+	return nil, f.Download(content, envKey)
+}
+
+func (f *fixedAuthor) UploadDir(dirpath string) (*api.Document, id.ID, *api.Manifest, error) {
+	f.dirpath = dirpath
+	return nil, f.envKey, nil, f.err
+}
+
+func (f *fixedAuthor) DownloadDir(dirpath string, envKey id.ID) (*api.Manifest, int, error) {
+	f.dirpath, f.requestedKey = dirpath, envKey
+	return nil, f.nDownloaded, f.err
+}
+
+func (f *fixedAuthor) History(envKey id.ID) ([]*author.Version, error) {
+	f.requestedKey = envKey
+	return f.versions, f.err
+}
+
+func (f *fixedAuthor) Publish(name string, envKey id.ID) (*api.Document, id.ID, error) {
+	f.name, f.requestedKey = name, envKey
+	return nil, f.envKey, f.err
+}
+
+func (f *fixedAuthor) Resolve(name string) (*api.Pointer, error) {
+	f.name = name
+	return f.pointer, f.err
+}
+
+func (f *fixedAuthor) ResolveKey(pointerKey id.ID) (*api.Pointer, error) {
+	f.requestedKey = pointerKey
+	return f.pointer, f.err
+}
+
+func (f *fixedAuthor) ShareWithGroup(envKey id.ID, group *author.Group) (
+	*author.GroupShareReport, error) {
+	f.requestedKey, f.group = envKey, group
+	return f.report, f.err
+}
+
+// stoppingWriter stops the download once it has written max bytes.
+type stoppingWriter struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (w *stoppingWriter) Write(p []byte) (int, error) {
+	if w.buf.Len() >= w.max {
+		return 0, author.ErrStopDownload
+	}
+	return w.buf.Write(p)
+}
+
+type fixedUploadServer struct {
+	AuthorDaemon_UploadServer
+	rqs []*UploadRequest
+	rp  *UploadResponse
+}
+
+func (f *fixedUploadServer) SendAndClose(rp *UploadResponse) error {
+	f.rp = rp
+	return nil
+}
+
+func (f *fixedUploadServer) Recv() (*UploadRequest, error) {
+	if len(f.rqs) == 0 {
+		return nil, io.EOF
+	}
+	rq := f.rqs[0]
+	f.rqs = f.rqs[1:]
+	return rq, nil
+}
@@ -0,0 +1,248 @@
+package author
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/parallel"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	// ErrEmptyDirectory indicates when an uploaded directory has no files.
+	ErrEmptyDirectory = errors.New("directory has no files")
+
+	// ErrNotManifest indicates when a downloaded entry is not a manifest.
+	ErrNotManifest = errors.New("entry is not a manifest")
+
+	// ErrUnexpectedFileHash indicates when the SHA-256 hash of a downloaded file differs from
+	// that in its manifest.
+	ErrUnexpectedFileHash = errors.New("unexpected downloaded file hash")
+)
+
+// UploadDir uploads each regular file in the directory tree as its own entry and then uploads a
+// manifest entry listing their relative filepaths, keys, sizes, and modes. Files are uploaded in
+// parallel. It returns the manifest envelope for self-storage, its key, and the manifest.
+func (a *Author) UploadDir(dirpath string) (*api.Document, id.ID, *api.Manifest, error) {
+	startTime := time.Now()
+	a.logger.Debug("uploading directory", uploadingDirFields(dirpath)...)
+	relFPs, err := listDirFiles(dirpath)
+	if err != nil {
+		return nil, nil, nil, a.logAndReturnErr("error listing directory files", err)
+	}
+	if len(relFPs) == 0 {
+		return nil, nil, nil, a.logAndReturnErr("error uploading directory", ErrEmptyDirectory)
+	}
+
+	manifest := &api.Manifest{Files: make([]*api.ManifestFile, len(relFPs))}
+	errs := parallel.Do(len(relFPs), a.config.FileParallelism, func(i int) error {
+		var err2 error
+		manifest.Files[i], err2 = a.uploadFile(dirpath, relFPs[i])
+		return err2
+	})
+	for i, err := range errs {
+		if err != nil {
+			msg := fmt.Sprintf("error uploading file %s", relFPs[i])
+			return nil, nil, nil, a.logAndReturnErr(msg, err)
+		}
+	}
+
+	buf, err := proto.Marshal(manifest)
+	if err != nil {
+		return nil, nil, nil, a.logAndReturnErr("error marshaling manifest", err)
+	}
+	env, envKey, err := a.Upload(bytes.NewReader(buf), ManifestMediaType, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	elapsedTime := time.Since(startTime)
+	a.logger.Info("uploaded directory",
+		uploadedDirFields(dirpath, envKey, len(manifest.Files), elapsedTime)...)
+	return env, envKey, manifest, nil
+}
+
+// DownloadDir downloads the manifest entry with the given envelope key and recreates its
+// directory tree within the given directory. Files are downloaded in parallel, skipping those
+// whose local copies already have the same contents. It returns the manifest and the number of
+// files downloaded.
+func (a *Author) DownloadDir(dirpath string, envKey id.ID) (*api.Manifest, int, error) {
+	startTime := time.Now()
+	a.logger.Debug("downloading directory", downloadingDirFields(dirpath, envKey)...)
+	buf := new(bytes.Buffer)
+	_, metadata, err := a.receiveAndUnpack(buf, envKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	if metadata.MediaType != ManifestMediaType {
+		return nil, 0, a.logAndReturnErr("error downloading manifest", ErrNotManifest)
+	}
+	manifest := &api.Manifest{}
+	if err = proto.Unmarshal(buf.Bytes(), manifest); err != nil {
+		return nil, 0, a.logAndReturnErr("error unmarshaling manifest", err)
+	}
+	if err = api.ValidateManifest(manifest); err != nil {
+		return nil, 0, a.logAndReturnErr("invalid manifest", err)
+	}
+
+	downloaded := make([]bool, len(manifest.Files))
+	errs := parallel.Do(len(manifest.Files), a.config.FileParallelism, func(i int) error {
+		var err2 error
+		downloaded[i], err2 = a.downloadFile(dirpath, manifest.Files[i])
+		return err2
+	})
+	nDownloaded := 0
+	for i, err := range errs {
+		if err != nil {
+			msg := fmt.Sprintf("error downloading file %s", manifest.Files[i].Filepath)
+			return nil, 0, a.logAndReturnErr(msg, err)
+		}
+		if downloaded[i] {
+			nDownloaded++
+		}
+	}
+
+	elapsedTime := time.Since(startTime)
+	a.logger.Info("downloaded directory", downloadedDirFields(dirpath, envKey,
+		len(manifest.Files), nDownloaded, elapsedTime)...)
+	return manifest, nDownloaded, nil
+}
+
+// uploadFile uploads a single file within the directory, returning its manifest file. Empty
+// files have no entry to upload.
+func (a *Author) uploadFile(dirpath, relFP string) (*api.ManifestFile, error) {
+	fp := filepath.Join(dirpath, filepath.FromSlash(relFP))
+	info, err := os.Stat(fp)
+	if err != nil {
+		return nil, err
+	}
+	mf := &api.ManifestFile{
+		Filepath: relFP,
+		Size:     uint64(info.Size()),
+		Mode:     uint32(info.Mode().Perm()),
+	}
+	h := sha256.New()
+	if mf.Size == 0 {
+		mf.Sha256 = h.Sum(nil)
+		return mf, nil
+	}
+	mediaType, err := DetectMediaType(fp)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(fp) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	opts := &UploadOptions{Filepath: relFP}
+	env, envKey, err := a.Upload(io.TeeReader(file, h), mediaType, opts)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	mf.EnvelopeKey = envKey.Bytes()
+	mf.EntryKey = env.Contents.(*api.Document_Envelope).Envelope.EntryKey
+	mf.Sha256 = h.Sum(nil)
+	return mf, file.Close()
+}
+
+// downloadFile downloads a single manifest file within the directory unless the local file
+// already has the same contents, returning whether it was downloaded.
+func (a *Author) downloadFile(dirpath string, mf *api.ManifestFile) (bool, error) {
+	fp := filepath.Join(dirpath, filepath.FromSlash(mf.Filepath))
+	mode := os.FileMode(mf.Mode).Perm()
+	unchanged, err := sameFileContents(fp, mf)
+	if err != nil {
+		return false, err
+	}
+	if unchanged {
+		return false, os.Chmod(fp, mode)
+	}
+	if err = os.MkdirAll(filepath.Dir(fp), os.ModePerm); err != nil {
+		return false, err
+	}
+
+	// download to temp file in same dir and then move so partial downloads never clobber
+	tmp, err := ioutil.TempFile(filepath.Dir(fp), "."+filepath.Base(fp)+".")
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // no-op after successful rename
+	h := sha256.New()
+	if mf.Size > 0 {
+		if err = a.Download(io.MultiWriter(tmp, h), id.FromBytes(mf.EnvelopeKey)); err != nil {
+			_ = tmp.Close()
+			return false, err
+		}
+	}
+	if err = tmp.Close(); err != nil {
+		return false, err
+	}
+	if !bytes.Equal(mf.Sha256, h.Sum(nil)) {
+		return false, ErrUnexpectedFileHash
+	}
+	if err = os.Chmod(tmp.Name(), mode); err != nil {
+		return false, err
+	}
+	return true, os.Rename(tmp.Name(), fp)
+}
+
+// sameFileContents returns whether the local file exists with the same size and SHA-256 hash as
+// the manifest file.
+func sameFileContents(fp string, mf *api.ManifestFile) (bool, error) {
+	info, err := os.Stat(fp)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !info.Mode().IsRegular() || uint64(info.Size()) != mf.Size {
+		return false, nil
+	}
+	file, err := os.Open(fp) // nolint: gosec
+	if err != nil {
+		return false, err
+	}
+	h := sha256.New()
+	if _, err = io.Copy(h, file); err != nil {
+		_ = file.Close()
+		return false, err
+	}
+	return bytes.Equal(mf.Sha256, h.Sum(nil)), file.Close()
+}
+
+// listDirFiles returns the sorted, forward slash separated filepaths (relative to the directory)
+// of the regular files in the directory tree.
+func listDirFiles(dirpath string) ([]string, error) {
+	relFPs := make([]string, 0)
+	err := filepath.Walk(dirpath, func(fp string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relFP, err := filepath.Rel(dirpath, fp)
+		if err != nil {
+			return err
+		}
+		relFPs = append(relFPs, filepath.ToSlash(relFP))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(relFPs)
+	return relFPs, nil
+}
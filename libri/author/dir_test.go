@@ -0,0 +1,225 @@
+package author
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drausin/libri/libri/author/io/common"
+	"github.com/drausin/libri/libri/author/io/page"
+	"github.com/drausin/libri/libri/author/io/publish"
+	"github.com/drausin/libri/libri/author/io/ship"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthor_UploadDownloadDir(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newMemNetworkTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	page.MinSize = 64 // just for testing
+	a.config.Print.PageSize = 128
+
+	srcDir, err := ioutil.TempDir("", "test-upload-dir")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(srcDir)) }()
+	contents := map[string][]byte{
+		"a.txt":       common.NewCompressableBytes(rng, 256).Bytes(),
+		"b/c.bin":     common.NewCompressableBytes(rng, 512).Bytes(),
+		"b/d/e.txt":   common.NewCompressableBytes(rng, 128).Bytes(),
+		"b/d/f.txt":   common.NewCompressableBytes(rng, 384).Bytes(),
+		"empty.txt":   {},
+		"g/h/i/j.txt": common.NewCompressableBytes(rng, 192).Bytes(),
+	}
+	for relFP, content := range contents {
+		writeTestFile(t, srcDir, relFP, content, 0600)
+	}
+	assert.Nil(t, os.Chmod(filepath.Join(srcDir, "a.txt"), 0640))
+
+	env, envKey, manifest, err := a.UploadDir(srcDir)
+	assert.Nil(t, err)
+	assert.NotNil(t, env)
+	assert.NotNil(t, envKey)
+	assert.Nil(t, api.ValidateManifest(manifest))
+	assert.Len(t, manifest.Files, len(contents))
+	assert.Equal(t, uint32(0640), manifest.Files[0].Mode)
+	assert.Nil(t, manifest.Files[4].EnvelopeKey) // empty.txt
+
+	// download to new dir
+	dstDir, err := ioutil.TempDir("", "test-download-dir")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dstDir)) }()
+	manifest2, nDownloaded, err := a.DownloadDir(dstDir, envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, manifest, manifest2)
+	assert.Equal(t, len(contents), nDownloaded)
+	for relFP, content := range contents {
+		fp := filepath.Join(dstDir, filepath.FromSlash(relFP))
+		downloaded, err := ioutil.ReadFile(fp)
+		assert.Nil(t, err)
+		assert.Equal(t, content, downloaded, relFP)
+	}
+	info, err := os.Stat(filepath.Join(dstDir, "a.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	// re-downloading skips unchanged files
+	_, nDownloaded, err = a.DownloadDir(dstDir, envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, nDownloaded)
+
+	// only changed & missing files are re-downloaded
+	writeTestFile(t, dstDir, "b/c.bin", []byte("changed"), 0600)
+	assert.Nil(t, os.Remove(filepath.Join(dstDir, "b/d/e.txt")))
+	_, nDownloaded, err = a.DownloadDir(dstDir, envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, nDownloaded)
+	for _, relFP := range []string{"b/c.bin", "b/d/e.txt"} {
+		downloaded, err := ioutil.ReadFile(filepath.Join(dstDir, filepath.FromSlash(relFP)))
+		assert.Nil(t, err)
+		assert.Equal(t, contents[relFP], downloaded, relFP)
+	}
+}
+
+func TestAuthor_UploadDir_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newMemNetworkTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+
+	// missing dir
+	env, envKey, manifest, err := a.UploadDir(filepath.Join(os.TempDir(), "missing-dir"))
+	assert.NotNil(t, err)
+	assert.Nil(t, env)
+	assert.Nil(t, envKey)
+	assert.Nil(t, manifest)
+
+	// empty dir
+	dir, err := ioutil.TempDir("", "test-upload-dir")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "sub"), 0700))
+	env, envKey, manifest, err = a.UploadDir(dir)
+	assert.Equal(t, ErrEmptyDirectory, err)
+	assert.Nil(t, env)
+	assert.Nil(t, envKey)
+	assert.Nil(t, manifest)
+
+	// file upload error
+	writeTestFile(t, dir, "a.txt", common.NewCompressableBytes(rng, 128).Bytes(), 0600)
+	a.shipper = &fixedShipper{err: errors.New("some ShipEntry error")}
+	env, envKey, manifest, err = a.UploadDir(dir)
+	assert.NotNil(t, err)
+	assert.Nil(t, env)
+	assert.Nil(t, envKey)
+	assert.Nil(t, manifest)
+}
+
+func TestAuthor_DownloadDir_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newMemNetworkTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	page.MinSize = 64 // just for testing
+	a.config.Print.PageSize = 128
+	dir, err := ioutil.TempDir("", "test-download-dir")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+
+	uploadManifest := func(m *api.Manifest) id.ID {
+		buf, err2 := proto.Marshal(m)
+		assert.Nil(t, err2)
+		_, envKey, err2 := a.Upload(bytes.NewReader(buf), ManifestMediaType, nil)
+		assert.Nil(t, err2)
+		return envKey
+	}
+
+	// not a manifest
+	content := common.NewCompressableBytes(rng, 128).Bytes()
+	_, envKey, err := a.Upload(bytes.NewReader(content), "text/plain", nil)
+	assert.Nil(t, err)
+	manifest, nDownloaded, err := a.DownloadDir(dir, envKey)
+	assert.Equal(t, ErrNotManifest, err)
+	assert.Nil(t, manifest)
+	assert.Zero(t, nDownloaded)
+
+	// invalid manifest
+	invalidFile := &api.ManifestFile{Filepath: "../a.txt", Sha256: api.RandBytes(rng, 32)}
+	envKey = uploadManifest(&api.Manifest{Files: []*api.ManifestFile{invalidFile}})
+	manifest, nDownloaded, err = a.DownloadDir(dir, envKey)
+	assert.NotNil(t, err)
+	assert.Nil(t, manifest)
+	assert.Zero(t, nDownloaded)
+
+	// unexpected file hash
+	_, fileEnvKey, err := a.Upload(bytes.NewReader(content), "text/plain", nil)
+	assert.Nil(t, err)
+	badHashFile := &api.ManifestFile{
+		Filepath:    "a.txt",
+		EnvelopeKey: fileEnvKey.Bytes(),
+		EntryKey:    api.RandBytes(rng, id.Length),
+		Size:        uint64(len(content)),
+		Sha256:      api.RandBytes(rng, 32),
+	}
+	envKey = uploadManifest(&api.Manifest{Files: []*api.ManifestFile{badHashFile}})
+	manifest, nDownloaded, err = a.DownloadDir(dir, envKey)
+	assert.NotNil(t, err)
+	assert.Nil(t, manifest)
+	assert.Zero(t, nDownloaded)
+	_, err = os.Stat(filepath.Join(dir, "a.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// receive entry error
+	a.receiver = &fixedReceiver{receiveEntryErr: errors.New("some ReceiveEntry error")}
+	manifest, nDownloaded, err = a.DownloadDir(dir, id.NewPseudoRandom(rng))
+	assert.NotNil(t, err)
+	assert.Nil(t, manifest)
+	assert.Zero(t, nDownloaded)
+}
+
+func TestListDirFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-list-dir")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	for _, relFP := range []string{"z.txt", "a/b.txt", "a.txt", "a/c/d.txt"} {
+		writeTestFile(t, dir, relFP, []byte("content"), 0600)
+	}
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "empty"), 0700))
+
+	relFPs, err := listDirFiles(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a.txt", "a/b.txt", "a/c/d.txt", "z.txt"}, relFPs)
+}
+
+// newMemNetworkTestAuthor returns a test author whose shipper and receiver publish to and
+// acquire from an in-memory network.
+func newMemNetworkTestAuthor() *Author {
+	return newMemNetworkTestAuthorOn(&memPublisherAcquirer{
+		docs: make(map[string]*api.Document),
+	})
+}
+
+// newMemNetworkTestAuthorOn returns a test author publishing to and acquiring from the given
+// in-memory network, which other test authors may share.
+func newMemNetworkTestAuthorOn(pubAcq *memPublisherAcquirer) *Author {
+	a := newTestAuthor()
+	slPublisher := publish.NewSingleLoadPublisher(pubAcq, a.documentSLD)
+	ssAcquirer := publish.NewSingleStoreAcquirer(pubAcq, a.documentSLD)
+	mlPublisher := publish.NewMultiLoadPublisher(slPublisher, a.config.Publish)
+	msAcquirer := publish.NewMultiStoreAcquirer(ssAcquirer, a.config.Publish)
+	a.shipper = ship.NewShipper(&fixedPutterBalancer{}, pubAcq, mlPublisher, a.documentSLD)
+	a.receiver = ship.NewReceiver(&fixedGetterBalancer{}, a.selfReaderKeys, pubAcq,
+		msAcquirer, a.documentSLD)
+	return a
+}
+
+func writeTestFile(t *testing.T, dir, relFP string, content []byte, perm os.FileMode) {
+	fp := filepath.Join(dir, filepath.FromSlash(relFP))
+	assert.Nil(t, os.MkdirAll(filepath.Dir(fp), 0700))
+	assert.Nil(t, ioutil.WriteFile(fp, content, perm))
+}
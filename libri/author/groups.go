@@ -0,0 +1,274 @@
+package author
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/storage"
+)
+
+var (
+	// ErrGroupNotFound indicates when a group is not in the groups file.
+	ErrGroupNotFound = errors.New("group not found")
+
+	// ErrEmptyGroupName indicates when a group name is empty.
+	ErrEmptyGroupName = errors.New("empty group name")
+
+	sharedReadersKeyPrefix = []byte("SharedReaders")
+)
+
+// Group is a named group of reader public keys an author shares documents with.
+type Group struct {
+	// Name of the group
+	Name string
+
+	// ReaderPubs are the reader public keys of the group members.
+	ReaderPubs []*ecdsa.PublicKey
+}
+
+// Groups are the named groups of reader public keys an author shares documents with.
+type Groups struct {
+	// Members are the encoded (see EncodeReaderKey) reader public keys of each group's members,
+	// keyed by group name.
+	Members map[string][]string `json:"members"`
+}
+
+// NewGroups returns an empty Groups.
+func NewGroups() *Groups {
+	return &Groups{
+		Members: map[string][]string{},
+	}
+}
+
+// Add adds the reader public keys to the group, creating it if necessary. It returns the number
+// of readers that weren't already members.
+func (g *Groups) Add(name string, readerPubs ...*ecdsa.PublicKey) (int, error) {
+	if name == "" {
+		return 0, ErrEmptyGroupName
+	}
+	members := g.Members[name]
+	existing := make(map[string]struct{}, len(members))
+	for _, encoded := range members {
+		existing[encoded] = struct{}{}
+	}
+	nAdded := 0
+	for _, readerPub := range readerPubs {
+		encoded := EncodeReaderKey(readerPub)
+		if _, in := existing[encoded]; in {
+			continue
+		}
+		existing[encoded] = struct{}{}
+		members = append(members, encoded)
+		nAdded++
+	}
+	g.Members[name] = members
+	return nAdded, nil
+}
+
+// RemoveMembers removes the reader public keys from the group, returning the number of readers
+// that were members.
+func (g *Groups) RemoveMembers(name string, readerPubs ...*ecdsa.PublicKey) (int, error) {
+	members, in := g.Members[name]
+	if !in {
+		return 0, fmt.Errorf("%s: %s", ErrGroupNotFound, name)
+	}
+	toRemove := make(map[string]struct{}, len(readerPubs))
+	for _, readerPub := range readerPubs {
+		toRemove[EncodeReaderKey(readerPub)] = struct{}{}
+	}
+	kept := make([]string, 0, len(members))
+	for _, encoded := range members {
+		if _, in := toRemove[encoded]; !in {
+			kept = append(kept, encoded)
+		}
+	}
+	g.Members[name] = kept
+	return len(members) - len(kept), nil
+}
+
+// Delete removes the group, returning whether it was there.
+func (g *Groups) Delete(name string) bool {
+	_, in := g.Members[name]
+	delete(g.Members, name)
+	return in
+}
+
+// Get returns the group with the given name.
+func (g *Groups) Get(name string) (*Group, error) {
+	members, in := g.Members[name]
+	if !in {
+		return nil, fmt.Errorf("%s: %s", ErrGroupNotFound, name)
+	}
+	group := &Group{
+		Name:       name,
+		ReaderPubs: make([]*ecdsa.PublicKey, len(members)),
+	}
+	for i, encoded := range members {
+		readerPub, err := DecodeReaderKey(encoded)
+		if err != nil {
+			return nil, err
+		}
+		group.ReaderPubs[i] = readerPub
+	}
+	return group, nil
+}
+
+// Names returns the sorted names of the groups.
+func (g *Groups) Names() []string {
+	names := make([]string, 0, len(g.Members))
+	for name := range g.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate returns an error if any of the group names are empty or member reader keys are
+// invalid.
+func (g *Groups) Validate() error {
+	for name, members := range g.Members {
+		if name == "" {
+			return ErrEmptyGroupName
+		}
+		for _, encoded := range members {
+			if _, err := DecodeReaderKey(encoded); err != nil {
+				return fmt.Errorf("invalid reader key in group %s: %s", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ReadGroups reads Groups from a JSON file.
+func ReadGroups(filepath string) (*Groups, error) {
+	buf, err := ioutil.ReadFile(filepath) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	g := NewGroups()
+	if err := json.Unmarshal(buf, g); err != nil {
+		return nil, err
+	}
+	if g.Members == nil {
+		g.Members = map[string][]string{}
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// WriteGroups writes Groups to a JSON file.
+func WriteGroups(filepath string, g *Groups) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	const filePerm = 0644
+	return ioutil.WriteFile(filepath, buf, filePerm)
+}
+
+// GroupShareResult is the result of sharing a document with one member of a group.
+type GroupShareResult struct {
+	// ReaderPub is the reader public key of the group member.
+	ReaderPub *ecdsa.PublicKey
+
+	// EnvelopeKey is the key of the envelope shared with the reader, which is nil if sharing
+	// failed.
+	EnvelopeKey id.ID
+
+	// AlreadyShared indicates whether the document was shared with the reader by a previous
+	// ShareWithGroup call, in which case EnvelopeKey is the key of that envelope.
+	AlreadyShared bool
+
+	// Err is the error sharing the document with the reader, if any.
+	Err error
+}
+
+// GroupShareReport contains the results of sharing a document with each member of a group.
+type GroupShareReport struct {
+	// Group is the name of the group.
+	Group string
+
+	// Results are the results for each group member, in the same order as the group's reader
+	// public keys.
+	Results []*GroupShareResult
+}
+
+// NShared returns the number of members the document was newly shared with.
+func (r *GroupShareReport) NShared() int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Err == nil && !result.AlreadyShared {
+			n++
+		}
+	}
+	return n
+}
+
+// NAlreadyShared returns the number of members the document was previously shared with.
+func (r *GroupShareReport) NAlreadyShared() int {
+	n := 0
+	for _, result := range r.Results {
+		if result.AlreadyShared {
+			n++
+		}
+	}
+	return n
+}
+
+// NFailed returns the number of members sharing the document with failed for.
+func (r *GroupShareReport) NFailed() int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// sharedReaders maps the encoded reader public keys a document has been shared with to the
+// keys of their envelopes.
+type sharedReaders map[string]string
+
+// sharedReadersKey returns the client storage key for the readers the document of the given
+// envelope has been shared with. The key is hashed to fit within the max storage key length.
+func sharedReadersKey(envKey id.ID) []byte {
+	h := sha256.New()
+	_, _ = h.Write(sharedReadersKeyPrefix)
+	_, _ = h.Write(envKey.Bytes())
+	return h.Sum(nil)
+}
+
+func loadSharedReaders(l storage.Loader, envKey id.ID) (sharedReaders, error) {
+	buf, err := l.Load(sharedReadersKey(envKey))
+	if err != nil {
+		return nil, err
+	}
+	shared := sharedReaders{}
+	if buf == nil {
+		return shared, nil
+	}
+	if err := json.Unmarshal(buf, &shared); err != nil {
+		return nil, err
+	}
+	return shared, nil
+}
+
+func saveSharedReaders(s storage.Storer, envKey id.ID, shared sharedReaders) error {
+	buf, err := json.Marshal(shared)
+	if err != nil {
+		return err
+	}
+	return s.Store(sharedReadersKey(envKey), buf)
+}
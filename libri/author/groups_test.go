@@ -0,0 +1,170 @@
+package author
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/db"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroups_AddGetRemove(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	alice, bob := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	carol := ecid.NewPseudoRandom(rng)
+	g := NewGroups()
+
+	nAdded, err := g.Add("team", &alice.Key().PublicKey, &bob.Key().PublicKey)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, nAdded)
+
+	// existing members aren't added again
+	nAdded, err = g.Add("team", &bob.Key().PublicKey, &carol.Key().PublicKey)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, nAdded)
+	_, err = g.Add("", &bob.Key().PublicKey)
+	assert.Equal(t, ErrEmptyGroupName, err)
+
+	nAdded, err = g.Add("other", &carol.Key().PublicKey)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, nAdded)
+	assert.Equal(t, []string{"other", "team"}, g.Names())
+
+	group, err := g.Get("team")
+	assert.Nil(t, err)
+	assert.Equal(t, "team", group.Name)
+	assert.Equal(t, 3, len(group.ReaderPubs))
+	for i, member := range []ecid.ID{alice, bob, carol} {
+		assert.Equal(t, member.PublicKeyBytes(), ecid.ToPublicKeyBytes(group.ReaderPubs[i]))
+	}
+
+	nRemoved, err := g.RemoveMembers("team", &alice.Key().PublicKey,
+		&ecid.NewPseudoRandom(rng).Key().PublicKey)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, nRemoved)
+	group, err = g.Get("team")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(group.ReaderPubs))
+
+	assert.True(t, g.Delete("team"))
+	assert.False(t, g.Delete("team"))
+	group, err = g.Get("team")
+	assert.NotNil(t, err)
+	assert.Nil(t, group)
+	_, err = g.RemoveMembers("team", &alice.Key().PublicKey)
+	assert.NotNil(t, err)
+}
+
+func TestGroups_Validate(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	g := NewGroups()
+	assert.Nil(t, g.Validate())
+	_, err := g.Add("team", &ecid.NewPseudoRandom(rng).Key().PublicKey)
+	assert.Nil(t, err)
+	assert.Nil(t, g.Validate())
+
+	cases := map[string][]string{
+		"":          {EncodeReaderKey(&ecid.NewPseudoRandom(rng).Key().PublicKey)},
+		"not hex":   {"not hex"},
+		"too short": {"02abcd"},
+	}
+	for name, members := range cases {
+		g2 := NewGroups()
+		g2.Members[name] = members
+		assert.NotNil(t, g2.Validate(), name)
+	}
+}
+
+func TestReadWriteGroups(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "test-groups")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	fp := filepath.Join(dir, GroupsFilename)
+
+	g1 := NewGroups()
+	_, err = g1.Add("team", &ecid.NewPseudoRandom(rng).Key().PublicKey)
+	assert.Nil(t, err)
+	assert.Nil(t, WriteGroups(fp, g1))
+	g2, err := ReadGroups(fp)
+	assert.Nil(t, err)
+	assert.Equal(t, g1, g2)
+
+	// empty file contents still has members map
+	assert.Nil(t, ioutil.WriteFile(fp, []byte("{}"), 0600))
+	g3, err := ReadGroups(fp)
+	assert.Nil(t, err)
+	assert.NotNil(t, g3.Members)
+
+	// bad JSON
+	assert.Nil(t, ioutil.WriteFile(fp, []byte("not json"), 0600))
+	g4, err := ReadGroups(fp)
+	assert.NotNil(t, err)
+	assert.Nil(t, g4)
+
+	// invalid key
+	assert.Nil(t, ioutil.WriteFile(fp, []byte(`{"members":{"team":["02"]}}`), 0600))
+	g5, err := ReadGroups(fp)
+	assert.NotNil(t, err)
+	assert.Nil(t, g5)
+	g1.Members["other"] = []string{"02"}
+	assert.NotNil(t, WriteGroups(fp, g1))
+
+	// missing file
+	g6, err := ReadGroups(filepath.Join(dir, "missing.json"))
+	assert.True(t, os.IsNotExist(err))
+	assert.Nil(t, g6)
+}
+
+func TestGroupShareReport_counts(t *testing.T) {
+	r := &GroupShareReport{
+		Results: []*GroupShareResult{
+			{},
+			{},
+			{AlreadyShared: true},
+			{Err: errors.New("some share error")},
+		},
+	}
+	assert.Equal(t, 2, r.NShared())
+	assert.Equal(t, 1, r.NAlreadyShared())
+	assert.Equal(t, 1, r.NFailed())
+}
+
+func TestLoadSaveSharedReaders(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	sl := storage.NewClientSL(db.NewMemoryDB())
+	envKey1, envKey2 := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+
+	// nothing shared yet
+	shared, err := loadSharedReaders(sl, envKey1)
+	assert.Nil(t, err)
+	assert.Empty(t, shared)
+
+	shared[EncodeReaderKey(&ecid.NewPseudoRandom(rng).Key().PublicKey)] =
+		id.NewPseudoRandom(rng).String()
+	assert.Nil(t, saveSharedReaders(sl, envKey1, shared))
+	loaded, err := loadSharedReaders(sl, envKey1)
+	assert.Nil(t, err)
+	assert.Equal(t, shared, loaded)
+
+	// other envelope is unaffected
+	loaded, err = loadSharedReaders(sl, envKey2)
+	assert.Nil(t, err)
+	assert.Empty(t, loaded)
+
+	// storage keys fit within max length
+	assert.Equal(t, storage.MaxKeyLength, len(sharedReadersKey(envKey1)))
+
+	// load errors
+	_, err = loadSharedReaders(&storage.TestSLD{LoadErr: errors.New("some Load error")}, envKey1)
+	assert.NotNil(t, err)
+	_, err = loadSharedReaders(&storage.TestSLD{Bytes: []byte("not json")}, envKey1)
+	assert.NotNil(t, err)
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/drausin/libri/libri/author/io/enc"
 	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/librarian/client"
 	"google.golang.org/grpc"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
@@ -57,3 +59,23 @@ var getLibrarianHealthClients = func(
 	}
 	return healthClients, nil
 }
+
+// newClientSigner returns the Signer for the author's requests, which presents the certificate in
+// the given file if it isn't empty.
+func newClientSigner(clientID ecid.ID, certFile string) (client.Signer, error) {
+	signer := client.NewECDSASigner(clientID.Key())
+	if certFile == "" {
+		return signer, nil
+	}
+	cert, err := client.ReadCertificate(certFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// fail fast rather than have librarians refuse all our requests
+	verifier := client.NewCertificateVerifier(client.DefaultClockSkew)
+	if err := verifier.Verify(cert, clientID.PublicKeyBytes()); err != nil {
+		return nil, err
+	}
+	return client.NewCertifiedSigner(signer, cert), nil
+}
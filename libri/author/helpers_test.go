@@ -2,12 +2,17 @@ package author
 
 import (
 	"errors"
+	"io/ioutil"
+	"math/rand"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/drausin/libri/libri/author/io/enc"
 	"github.com/drausin/libri/libri/author/keychain"
 	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -88,6 +93,10 @@ func (f *fixedKeychain) Sample() (ecid.ID, error) {
 	return f.sampleID, f.sampleErr
 }
 
+func (f *fixedKeychain) Primary() (ecid.ID, error) {
+	return f.sampleID, f.sampleErr
+}
+
 func (f *fixedKeychain) Get(publicKey []byte) (ecid.ID, bool) {
 	return nil, false
 }
@@ -95,3 +104,39 @@ func (f *fixedKeychain) Get(publicKey []byte) (ecid.ID, bool) {
 func (f *fixedKeychain) Len() int {
 	return 0
 }
+
+func TestNewClientSigner(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	clientID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	dir, err := ioutil.TempDir("", "test-client-signer")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+
+	// without certificate
+	signer, err := newClientSigner(clientID, "")
+	assert.Nil(t, err)
+	_, isCertified := signer.(client.CertifiedSigner)
+	assert.False(t, isCertified)
+
+	// with certificate
+	cert, err := client.NewCertificate(orgID, clientID.PublicKeyBytes(),
+		client.DefaultCertificateTTL)
+	assert.Nil(t, err)
+	certFile := filepath.Join(dir, "client.cert")
+	assert.Nil(t, client.WriteCertificate(certFile, cert))
+	signer, err = newClientSigner(clientID, certFile)
+	assert.Nil(t, err)
+	certified, isCertified := signer.(client.CertifiedSigner)
+	assert.True(t, isCertified)
+	assert.Equal(t, cert, certified.Certificate())
+
+	// missing certificate file
+	signer, err = newClientSigner(clientID, filepath.Join(dir, "other.cert"))
+	assert.NotNil(t, err)
+	assert.Nil(t, signer)
+
+	// certificate for another client
+	signer, err = newClientSigner(ecid.NewPseudoRandom(rng), certFile)
+	assert.Equal(t, client.ErrCertificatePubKeyMismatch, err)
+	assert.Nil(t, signer)
+}
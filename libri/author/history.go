@@ -0,0 +1,135 @@
+package author
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+)
+
+var (
+	// ErrUnexpectedPreviousEntry indicates when the entry of a previous version envelope differs
+	// from the previous entry key in the metadata of the next version.
+	ErrUnexpectedPreviousEntry = errors.New("previous envelope has unexpected entry key")
+
+	// ErrVersionNotFound indicates when a requested version is not in the history of a document.
+	ErrVersionNotFound = errors.New("version not found")
+
+	// ErrPreviousVersionNotReadable indicates when the envelope of a previous version isn't
+	// readable by this author, e.g., when walking the history of a document shared by its
+	// author, since the previous envelope keys in the metadata are the author's own envelopes.
+	ErrPreviousVersionNotReadable = errors.New("previous version envelope not readable by " +
+		"this author")
+)
+
+// Lineage identifies the previous version of an uploaded document and the entries it was
+// derived from.
+type Lineage struct {
+	// PreviousEnvelopeKey is the key of an envelope of the previous version, if there is one.
+	PreviousEnvelopeKey id.ID
+
+	// DerivedFromEntryKeys are the keys of the entries the document was derived from.
+	DerivedFromEntryKeys []id.ID
+}
+
+// Version is a single version in the history of a document.
+type Version struct {
+	// Number is the 1-based version number, with version 1 being the first version.
+	Number int
+
+	EnvelopeKey id.ID
+	EntryKey    id.ID
+	CreatedTime time.Time
+	Metadata    *api.EntryMetadata
+}
+
+// UploadVersion uploads the content like Upload, recording the given lineage in the encrypted
+// entry metadata. The previous version envelope must be readable by this author, since its entry
+// key is also recorded.
+func (a *Author) UploadVersion(
+	content io.Reader, mediaType string, lineage *Lineage, opts *UploadOptions,
+) (*api.Document, id.ID, error) {
+	optional := opts.metadata()
+	if optional == nil {
+		optional = &api.EntryMetadata{}
+	}
+	if lineage.PreviousEnvelopeKey != nil {
+		prevEnv, err := a.receiver.ReceiveEnvelope(lineage.PreviousEnvelopeKey)
+		if err != nil {
+			return nil, nil, a.logAndReturnErr("error receiving previous envelope", err)
+		}
+		optional.PreviousEntryKey = prevEnv.EntryKey
+		optional.PreviousEnvelopeKey = lineage.PreviousEnvelopeKey.Bytes()
+	}
+	for _, entryKey := range lineage.DerivedFromEntryKeys {
+		optional.DerivedFromEntryKeys = append(optional.DerivedFromEntryKeys, entryKey.Bytes())
+	}
+	return a.upload(content, mediaType, optional)
+}
+
+// History returns the versions of the document with the given envelope key, ordered from the
+// first version to the given one. It walks back through each previous version, receiving just its
+// envelope and entry and decrypting just its metadata, so none of the pages are downloaded.
+//
+// Since each version records the envelope of the previous version uploaded by the document's
+// author, only the author can walk the history. Readers of a shared envelope can get its metadata
+// via Info, but History returns ErrPreviousVersionNotReadable for them once it reaches a previous
+// version.
+func (a *Author) History(envKey id.ID) ([]*Version, error) {
+	a.logger.Debug("getting history", downloadingDocFields(envKey)...)
+	reversed := make([]*Version, 0)
+	var expectedEntryKey []byte
+	for envKey != nil {
+		entry, entryKey, metadata, err := a.receiveMetadata(envKey)
+		if err == keychain.ErrUnexpectedMissingKey && expectedEntryKey != nil {
+			return nil, ErrPreviousVersionNotReadable
+		}
+		if err != nil {
+			return nil, err
+		}
+		if expectedEntryKey != nil && !bytes.Equal(expectedEntryKey, entryKey.Bytes()) {
+			return nil, a.logAndReturnErr("error walking history", ErrUnexpectedPreviousEntry)
+		}
+		createdTime := entry.Contents.(*api.Document_Entry).Entry.CreatedTime
+		reversed = append(reversed, &Version{
+			EnvelopeKey: envKey,
+			EntryKey:    entryKey,
+			CreatedTime: time.Unix(int64(createdTime), 0),
+			Metadata:    metadata,
+		})
+		envKey, expectedEntryKey = nil, metadata.PreviousEntryKey
+		if metadata.PreviousEnvelopeKey != nil {
+			envKey = id.FromBytes(metadata.PreviousEnvelopeKey)
+		}
+	}
+
+	versions := make([]*Version, len(reversed))
+	for i, version := range reversed {
+		version.Number = len(reversed) - i
+		versions[version.Number-1] = version
+	}
+	a.logger.Info("got history", historyFields(versions)...)
+	return versions, nil
+}
+
+// DownloadVersion downloads the given version number from the history of the document with the
+// given envelope key, writing it to the content writer. It returns ErrVersionNotFound if the
+// history has no such version.
+func (a *Author) DownloadVersion(content io.Writer, envKey id.ID, number int) (*Version, error) {
+	versions, err := a.History(envKey)
+	if err != nil {
+		return nil, err
+	}
+	if number < 1 || number > len(versions) {
+		return nil, a.logAndReturnErr("error getting version", ErrVersionNotFound)
+	}
+	version := versions[number-1]
+	if err := a.Download(content, version.EnvelopeKey); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
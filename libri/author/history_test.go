@@ -0,0 +1,178 @@
+package author
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/author/io/common"
+	"github.com/drausin/libri/libri/author/io/page"
+	"github.com/drausin/libri/libri/author/keychain"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthor_UploadVersion_History(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newMemNetworkTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	page.MinSize = 64 // just for testing
+	a.config.Print.PageSize = 128
+	mediaType := "text/plain"
+
+	contents := make([][]byte, 3)
+	envKeys := make([]id.ID, 3)
+	entryKeys := make([]id.ID, 3)
+	derivedFrom := []id.ID{id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)}
+	for i := range contents {
+		contents[i] = common.NewCompressableBytes(rng, 512).Bytes()
+		lineage := &Lineage{}
+		if i > 0 {
+			lineage.PreviousEnvelopeKey = envKeys[i-1]
+		}
+		var opts *UploadOptions
+		if i == 2 {
+			lineage.DerivedFromEntryKeys = derivedFrom
+			opts = &UploadOptions{Properties: map[string][]byte{"run_id": []byte("1234")}}
+		}
+		env, envKey, err := a.UploadVersion(bytes.NewReader(contents[i]), mediaType, lineage, opts)
+		assert.Nil(t, err)
+		envKeys[i] = envKey
+		entryKeys[i] = id.FromBytes(env.GetEnvelope().EntryKey)
+	}
+
+	// history of latest version has all versions
+	versions, err := a.History(envKeys[2])
+	assert.Nil(t, err)
+	assert.Len(t, versions, 3)
+	for i, version := range versions {
+		assert.Equal(t, i+1, version.Number)
+		assert.Equal(t, envKeys[i], version.EnvelopeKey)
+		assert.Equal(t, entryKeys[i], version.EntryKey)
+		assert.Equal(t, mediaType, version.Metadata.MediaType)
+		assert.False(t, version.CreatedTime.IsZero())
+		if i > 0 {
+			assert.Equal(t, envKeys[i-1].Bytes(), version.Metadata.PreviousEnvelopeKey)
+			assert.Equal(t, entryKeys[i-1].Bytes(), version.Metadata.PreviousEntryKey)
+		} else {
+			assert.Nil(t, version.Metadata.PreviousEnvelopeKey)
+			assert.Nil(t, version.Metadata.PreviousEntryKey)
+		}
+	}
+	assert.Equal(t, [][]byte{derivedFrom[0].Bytes(), derivedFrom[1].Bytes()},
+		versions[2].Metadata.DerivedFromEntryKeys)
+	assert.Equal(t, []byte("1234"), versions[2].Metadata.Properties["run_id"])
+
+	// history of earlier version stops at that version
+	versions, err = a.History(envKeys[1])
+	assert.Nil(t, err)
+	assert.Len(t, versions, 2)
+
+	// download each version
+	for i := range contents {
+		content := new(bytes.Buffer)
+		version, err := a.DownloadVersion(content, envKeys[2], i+1)
+		assert.Nil(t, err)
+		assert.Equal(t, i+1, version.Number)
+		assert.Equal(t, contents[i], content.Bytes())
+	}
+}
+
+func TestAuthor_History_sharedReader(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	network := &memPublisherAcquirer{docs: make(map[string]*api.Document)}
+	a, reader := newMemNetworkTestAuthorOn(network), newMemNetworkTestAuthorOn(network)
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	defer func() { cerrors.MaybePanic(reader.CloseAndRemove()) }()
+	page.MinSize = 64 // just for testing
+	a.config.Print.PageSize = 128
+
+	content := common.NewCompressableBytes(rng, 256).Bytes()
+	_, envKey1, err := a.Upload(bytes.NewReader(content), "text/plain", nil)
+	assert.Nil(t, err)
+	lineage := &Lineage{PreviousEnvelopeKey: envKey1}
+	_, envKey2, err := a.UploadVersion(bytes.NewReader(content), "text/plain", lineage, nil)
+	assert.Nil(t, err)
+
+	readerKey, err := reader.selfReaderKeys.(keychain.GetterSampler).Sample()
+	assert.Nil(t, err)
+	_, sharedEnvKey, err := a.Share(envKey2, &readerKey.Key().PublicKey)
+	assert.Nil(t, err)
+
+	// reader can get the shared version's metadata but not walk back to the previous version,
+	// which was only ever in the author's own envelope
+	metadata, _, err := reader.Info(sharedEnvKey)
+	assert.Nil(t, err)
+	assert.Equal(t, envKey1.Bytes(), metadata.PreviousEnvelopeKey)
+	versions, err := reader.History(sharedEnvKey)
+	assert.Equal(t, ErrPreviousVersionNotReadable, err)
+	assert.Nil(t, versions)
+
+	// author can
+	versions, err = a.History(envKey2)
+	assert.Nil(t, err)
+	assert.Len(t, versions, 2)
+}
+
+func TestAuthor_UploadVersion_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	a.receiver = &fixedReceiver{receiveEnvelopeErr: errors.New("some ReceiveEnvelope error")}
+
+	lineage := &Lineage{PreviousEnvelopeKey: id.NewPseudoRandom(rng)}
+	env, envKey, err := a.UploadVersion(bytes.NewReader([]byte("some content")), "text/plain",
+		lineage, nil)
+	assert.NotNil(t, err)
+	assert.Nil(t, env)
+	assert.Nil(t, envKey)
+}
+
+func TestAuthor_History_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newMemNetworkTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	page.MinSize = 64 // just for testing
+	a.config.Print.PageSize = 128
+
+	// previous entry key doesn't match previous envelope's entry
+	content := common.NewCompressableBytes(rng, 256).Bytes()
+	_, envKey1, err := a.Upload(bytes.NewReader(content), "text/plain", nil)
+	assert.Nil(t, err)
+	optional := &api.EntryMetadata{
+		PreviousEntryKey:    api.RandBytes(rng, api.DocumentKeyLength),
+		PreviousEnvelopeKey: envKey1.Bytes(),
+	}
+	_, envKey2, err := a.upload(bytes.NewReader(content), "text/plain", optional)
+	assert.Nil(t, err)
+	versions, err := a.History(envKey2)
+	assert.Equal(t, ErrUnexpectedPreviousEntry, err)
+	assert.Nil(t, versions)
+
+	// missing version
+	version, err := a.DownloadVersion(new(bytes.Buffer), envKey1, 2)
+	assert.Equal(t, ErrVersionNotFound, err)
+	assert.Nil(t, version)
+
+	// history error
+	version, err = a.DownloadVersion(new(bytes.Buffer), envKey2, 1)
+	assert.NotNil(t, err)
+	assert.Nil(t, version)
+
+	// receive entry error
+	a.receiver = &fixedReceiver{receiveEntryErr: errors.New("some ReceiveEntry error")}
+	versions, err = a.History(envKey1)
+	assert.NotNil(t, err)
+	assert.Nil(t, versions)
+
+	// unpack metadata error
+	entry, _ := api.NewTestDocument(rng)
+	a.receiver = &fixedReceiver{entry: entry}
+	a.entryUnpacker = &fixedUnpacker{err: errors.New("some UnpackMetadata error")}
+	versions, err = a.History(envKey1)
+	assert.NotNil(t, err)
+	assert.Nil(t, versions)
+}
@@ -155,8 +155,10 @@ func (c *compressor) Read(p []byte) (int, error) {
 	// write compressed contents into buffer until we have enough for p
 	for c.buf.Len() < len(p) {
 		more := make([]byte, int(c.uncompressedBufferSize))
-		nMore, err := c.uncompressed.Read(more)
-		if err != nil && err != io.EOF {
+		// fill the buffer even from readers (like network streams) that return short reads, since
+		// a short read means the end of the uncompressed data below
+		nMore, err := io.ReadFull(c.uncompressed, more)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 			return 0, err
 		}
 		if _, err = c.inner.Write(more[:nMore]); err != nil {
@@ -7,6 +7,7 @@ import (
 	"io"
 	"math/rand"
 	"testing"
+	"testing/iotest"
 
 	"github.com/drausin/libri/libri/author/io/common"
 	"github.com/drausin/libri/libri/author/io/enc"
@@ -148,6 +149,39 @@ func TestCompressor_Read_ok(t *testing.T) {
 	// TODO check uncompressedMAC
 }
 
+func TestCompressor_Read_shortReads(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	keys := enc.NewPseudoRandomEEK(rng)
+	uncompressed1Bytes := common.NewCompressableBytes(rng, 1024).Bytes()
+
+	// source returns half of each requested read
+	comp, err := NewCompressor(
+		iotest.HalfReader(bytes.NewReader(uncompressed1Bytes)),
+		api.CompressionCodec_GZIP,
+		keys,
+		MinBufferSize,
+	)
+	assert.Nil(t, err)
+
+	compressed := new(bytes.Buffer)
+	bufSize := int(MinBufferSize)
+	n1 := bufSize
+	for n1 == bufSize {
+		buf := make([]byte, bufSize)
+		n1, err = comp.Read(buf)
+		assert.True(t, err == nil || err == io.EOF)
+		compressed.Write(buf[:n1])
+	}
+
+	// check all the uncompressed bytes were compressed
+	reader, err := gzip.NewReader(compressed)
+	assert.Nil(t, err)
+	uncompressed2 := new(bytes.Buffer)
+	_, err = uncompressed2.ReadFrom(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, uncompressed1Bytes, uncompressed2.Bytes())
+}
+
 func TestCompressor_Read_err(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	keys := enc.NewPseudoRandomEEK(rng)
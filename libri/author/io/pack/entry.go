@@ -17,9 +17,10 @@ import (
 // EntryPacker creates entry documents from raw content.
 type EntryPacker interface {
 	// Pack prints pages from the content, encrypts their metadata, and binds them together
-	// into an entry *api.Document.
-	Pack(content io.Reader, mediaType string, keys *enc.EEK, authorPub []byte) (
-		*api.Document, *api.EntryMetadata, error)
+	// into an entry *api.Document. The optional properties, filepath, schema, and lineage
+	// fields of the optional metadata, which may be nil, are included in the entry metadata.
+	Pack(content io.Reader, mediaType string, optional *api.EntryMetadata, keys *enc.EEK,
+		authorPub []byte) (*api.Document, *api.EntryMetadata, error)
 }
 
 // NewEntryPacker creates a new Packer instance.
@@ -46,16 +47,28 @@ type entryPacker struct {
 	docL        storage.DocumentLoader
 }
 
-func (p *entryPacker) Pack(content io.Reader, mediaType string, keys *enc.EEK, authorPub []byte) (
-	*api.Document, *api.EntryMetadata, error) {
+func (p *entryPacker) Pack(
+	content io.Reader,
+	mediaType string,
+	optional *api.EntryMetadata,
+	keys *enc.EEK,
+	authorPub []byte,
+) (*api.Document, *api.EntryMetadata, error) {
 
+	// check the optional metadata before printing, so invalid metadata doesn't leave behind
+	// stored pages that no entry refers to
+	if optional != nil {
+		if err := api.ValidateOptionalEntryMetadata(optional); err != nil {
+			return nil, nil, err
+		}
+	}
 	pageKeys, metadata, err := p.printer.Print(content, mediaType, keys, authorPub)
 	if err != nil {
 		return nil, nil, err
 	}
-	// TODO (drausin) add additional metadata K/V here
-	// - relative filepath
-	// - file mode permissions
+	if optional != nil {
+		setOptionalMetadata(metadata, optional)
+	}
 	encMetadata, err := p.metadataEnc.Encrypt(metadata, keys)
 	if err != nil {
 		return nil, nil, err
@@ -69,12 +82,22 @@ type EntryUnpacker interface {
 	// Unpack extracts the individual pages from a document and stitches them together to write
 	// to the content io.Writer.
 	Unpack(content io.Writer, entryDoc *api.Document, keys *enc.EEK) (*api.EntryMetadata, error)
+
+	// UnpackFrom is like Unpack but loads the pages with the given page.Loader, e.g., one that
+	// receives them from libri as they're needed.
+	UnpackFrom(content io.Writer, entryDoc *api.Document, keys *enc.EEK, pageL page.Loader) (
+		*api.EntryMetadata, error)
+
+	// UnpackMetadata decrypts just the metadata of an entry document, without loading any of
+	// its pages.
+	UnpackMetadata(entryDoc *api.Document, keys *enc.EEK) (*api.EntryMetadata, error)
 }
 
 type entryUnpacker struct {
 	params      *print.Parameters
 	metadataDec enc.MetadataDecrypter
-	scanner     print.Scanner
+	pageL       page.Loader
+	newScanner  func(pageL page.Loader) print.Scanner
 }
 
 // NewEntryUnpacker creates a new EntryUnpacker with the given parameters, metadata decrypter, and
@@ -84,28 +107,29 @@ func NewEntryUnpacker(
 	metadataDec enc.MetadataDecrypter,
 	docSL storage.DocumentSLD,
 ) EntryUnpacker {
-	pageL := page.NewStorerLoader(docSL)
 	return &entryUnpacker{
 		params:      params,
 		metadataDec: metadataDec,
-		scanner:     print.NewScanner(params, pageL),
+		pageL:       page.NewStorerLoader(docSL),
+		newScanner: func(pageL page.Loader) print.Scanner {
+			return print.NewScanner(params, pageL)
+		},
 	}
 }
 
 func (u *entryUnpacker) Unpack(content io.Writer, entryDoc *api.Document, keys *enc.EEK) (
 	*api.EntryMetadata, error) {
-	entry := entryDoc.Contents.(*api.Document_Entry).Entry
-	encMetadata, err := enc.NewEncryptedMetadata(
-		entry.MetadataCiphertext,
-		entry.MetadataCiphertextMac,
-	)
-	if err != nil {
-		return nil, err
-	}
-	metadata, err := u.metadataDec.Decrypt(encMetadata, keys)
+	return u.UnpackFrom(content, entryDoc, keys, u.pageL)
+}
+
+func (u *entryUnpacker) UnpackFrom(
+	content io.Writer, entryDoc *api.Document, keys *enc.EEK, pageL page.Loader,
+) (*api.EntryMetadata, error) {
+	metadata, err := u.UnpackMetadata(entryDoc, keys)
 	if err != nil {
 		return nil, err
 	}
+	entry := entryDoc.Contents.(*api.Document_Entry).Entry
 
 	var pageKeys []id.ID
 	if entry.Page != nil {
@@ -120,7 +144,35 @@ func (u *entryUnpacker) Unpack(content io.Writer, entryDoc *api.Document, keys *
 	} else {
 		return nil, api.ErrUnexpectedDocumentType
 	}
-	return metadata, u.scanner.Scan(content, pageKeys, keys, metadata)
+	return metadata, u.newScanner(pageL).Scan(content, pageKeys, keys, metadata)
+}
+
+func (u *entryUnpacker) UnpackMetadata(entryDoc *api.Document, keys *enc.EEK) (
+	*api.EntryMetadata, error) {
+	entry, ok := entryDoc.Contents.(*api.Document_Entry)
+	if !ok {
+		return nil, api.ErrUnexpectedDocumentType
+	}
+	encMetadata, err := enc.NewEncryptedMetadata(
+		entry.Entry.MetadataCiphertext,
+		entry.Entry.MetadataCiphertextMac,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return u.metadataDec.Decrypt(encMetadata, keys)
+}
+
+// setOptionalMetadata sets the optional fields of the printed metadata from those of the optional
+// metadata.
+func setOptionalMetadata(metadata, optional *api.EntryMetadata) {
+	metadata.Properties = optional.Properties
+	metadata.Filepath = optional.Filepath
+	metadata.Schema = optional.Schema
+	metadata.DataDictionary = optional.DataDictionary
+	metadata.PreviousEntryKey = optional.PreviousEntryKey
+	metadata.PreviousEnvelopeKey = optional.PreviousEnvelopeKey
+	metadata.DerivedFromEntryKeys = optional.DerivedFromEntryKeys
 }
 
 func newEntryDoc(
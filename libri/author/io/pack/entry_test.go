@@ -32,7 +32,7 @@ func TestEntryPacker_Pack_ok(t *testing.T) {
 	// test works with single-page content
 	uncompressedSize1 := int(params.PageSize / 2)
 	content1 := common.NewCompressableBytes(rng, uncompressedSize1)
-	doc, metadata, err := p.Pack(content1, mediaType, keys, authorPub)
+	doc, metadata, err := p.Pack(content1, mediaType, nil, keys, authorPub)
 	assert.Nil(t, err)
 	assert.NotNil(t, doc)
 	assert.NotNil(t, metadata)
@@ -41,7 +41,7 @@ func TestEntryPacker_Pack_ok(t *testing.T) {
 	// test works with multi-page content
 	uncompressedSize2 := int(params.PageSize * 5)
 	content2 := common.NewCompressableBytes(rng, uncompressedSize2)
-	doc, metadata, err = p.Pack(content2, mediaType, keys, authorPub)
+	doc, metadata, err = p.Pack(content2, mediaType, nil, keys, authorPub)
 	assert.Nil(t, err)
 	assert.NotNil(t, doc)
 	assert.NotNil(t, metadata)
@@ -49,6 +49,26 @@ func TestEntryPacker_Pack_ok(t *testing.T) {
 	pageKeys, err := api.GetEntryPageKeys(doc)
 	assert.Nil(t, err)
 	assert.True(t, len(pageKeys) > 1)
+
+	// test optional metadata is included
+	optional := &api.EntryMetadata{
+		Filepath:             "some/file.pdf",
+		PreviousEntryKey:     api.RandBytes(rng, api.DocumentKeyLength),
+		PreviousEnvelopeKey:  api.RandBytes(rng, api.DocumentKeyLength),
+		DerivedFromEntryKeys: [][]byte{api.RandBytes(rng, api.DocumentKeyLength)},
+	}
+	content3 := common.NewCompressableBytes(rng, uncompressedSize1)
+	doc, metadata, err = p.Pack(content3, mediaType, optional, keys, authorPub)
+	assert.Nil(t, err)
+	assert.NotNil(t, doc)
+	assert.Equal(t, optional.Filepath, metadata.Filepath)
+	assert.Equal(t, optional.PreviousEntryKey, metadata.PreviousEntryKey)
+	assert.Equal(t, optional.PreviousEnvelopeKey, metadata.PreviousEnvelopeKey)
+	assert.Equal(t, optional.DerivedFromEntryKeys, metadata.DerivedFromEntryKeys)
+	u := NewEntryUnpacker(params, enc.NewMetadataEncrypterDecrypter(), docSL)
+	metadata2, err := u.UnpackMetadata(doc, keys)
+	assert.Nil(t, err)
+	assert.Equal(t, metadata, metadata2)
 }
 
 func TestEntryPacker_Pack_err(t *testing.T) {
@@ -64,13 +84,13 @@ func TestEntryPacker_Pack_err(t *testing.T) {
 	keys := enc.NewPseudoRandomEEK(rng)
 
 	// check error from bad mediaType bubbles up
-	doc, metadata, err := p.Pack(content, "application x-pdf", keys, authorPub)
+	doc, metadata, err := p.Pack(content, "application x-pdf", nil, keys, authorPub)
 	assert.NotNil(t, err)
 	assert.Nil(t, doc)
 	assert.Nil(t, metadata)
 
 	// check Encrypt error from bad author key bubbles up
-	doc, metadata, err = p.Pack(content, mediaType, keys, []byte{})
+	doc, metadata, err = p.Pack(content, mediaType, nil, keys, []byte{})
 	assert.NotNil(t, err)
 	assert.Nil(t, doc)
 	assert.Nil(t, metadata)
@@ -82,11 +102,30 @@ func TestEntryPacker_Pack_err(t *testing.T) {
 	p2 := NewEntryPacker(params, enc.NewMetadataEncrypterDecrypter(), errDocSL)
 
 	// check error from missing page bubbles up
-	doc, metadata, err = p2.Pack(content, mediaType, keys, []byte{})
+	doc, metadata, err = p2.Pack(content, mediaType, nil, keys, []byte{})
 	assert.NotNil(t, err)
 	assert.Nil(t, doc)
 	assert.Nil(t, metadata)
 
+	// check error from invalid optional metadata bubbles up before any pages are stored
+	docSL3 := storage.NewTestDocSLD()
+	p3 := NewEntryPacker(params, enc.NewMetadataEncrypterDecrypter(), docSL3)
+	optional := &api.EntryMetadata{
+		PreviousEntryKey: api.RandBytes(rng, api.DocumentKeyLength),
+	}
+	content = common.NewCompressableBytes(rng, int(params.PageSize/2))
+	doc, metadata, err = p3.Pack(content, mediaType, optional, keys, authorPub)
+	assert.Equal(t, api.ErrIncompletePrevious, err)
+	assert.Nil(t, doc)
+	assert.Nil(t, metadata)
+	assert.Len(t, docSL3.Stored, 0)
+
+	optional = &api.EntryMetadata{Filepath: "/some/file.txt"}
+	doc, metadata, err = p3.Pack(content, mediaType, optional, keys, authorPub)
+	assert.Equal(t, api.ErrAbsoluteFilepath, err)
+	assert.Nil(t, doc)
+	assert.Nil(t, metadata)
+	assert.Len(t, docSL3.Stored, 0)
 }
 
 func TestEntryUnpacker_Unpack_ok(t *testing.T) {
@@ -110,7 +149,7 @@ func TestEntryUnpacker_Unpack_ok(t *testing.T) {
 		metadata: metadata1,
 	}
 	u := NewEntryUnpacker(params, metadataDec, docSL)
-	u.(*entryUnpacker).scanner = &fixedScanner{}
+	u.(*entryUnpacker).newScanner = func(page.Loader) print.Scanner { return &fixedScanner{} }
 	metadata, err := u.Unpack(content, doc, keys)
 	assert.Nil(t, err)
 	assert.NotNil(t, metadata)
@@ -146,12 +185,19 @@ func TestEntryUnpacker_Unpack_err(t *testing.T) {
 
 	// check scanner error bubbles up
 	u3 := NewEntryUnpacker(params, &fixedMetadataDecrypter{}, docSL)
-	u3.(*entryUnpacker).scanner = &fixedScanner{
-		err: errors.New("some Scan error"),
+	u3.(*entryUnpacker).newScanner = func(page.Loader) print.Scanner {
+		return &fixedScanner{err: errors.New("some Scan error")}
 	}
 	metadata, err = u3.Unpack(content, doc, keys)
 	assert.NotNil(t, err)
 	assert.Nil(t, metadata)
+
+	// check non-entry doc triggers error
+	pageDoc, _ := api.NewTestDocument(rng)
+	pageDoc.Contents = &api.Document_Page{Page: api.NewTestPage(rng)}
+	metadata, err = u1.UnpackMetadata(pageDoc, keys)
+	assert.Equal(t, api.ErrUnexpectedDocumentType, err)
+	assert.Nil(t, metadata)
 }
 
 func TestEntryPackUnpack(t *testing.T) {
@@ -187,7 +233,7 @@ func TestEntryPackUnpack(t *testing.T) {
 		assert.Nil(t, err)
 		u := NewEntryUnpacker(unpackParams, metadataEncDec, docSL)
 
-		doc, metadata1, err := p.Pack(content1, c.mediaType, keys, authorPub)
+		doc, metadata1, err := p.Pack(content1, c.mediaType, nil, keys, authorPub)
 		assert.Nil(t, err)
 		assert.NotNil(t, doc)
 		assert.Equal(t, c.uncompressedSize, int(metadata1.UncompressedSize))
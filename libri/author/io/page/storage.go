@@ -79,8 +79,7 @@ func (s *storerLoader) Load(keys []id.ID, pages chan *api.Page, abort chan struc
 		select {
 		case <-abort:
 			return nil
-		default:
-			pages <- docPage.Page
+		case pages <- docPage.Page:
 			if err := s.inner.Delete(key); err != nil { // no need to keep page around
 				return err
 			}
@@ -246,6 +246,43 @@ func TestMultiLoadPublisher_Publish_err(t *testing.T) {
 	}
 }
 
+func TestMultiLoadPublisher_PublishEach(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	cb := &fixedPutterBalancer{}
+	for _, nDocs := range []int{1, 2, 4, 8, 16} {
+		docKeys := make([]id.ID, nDocs)
+		errKeys := make(map[string]struct{})
+		for i := 0; i < nDocs; i++ {
+			docKeys[i] = id.NewPseudoRandom(rng)
+			if i%3 == 1 {
+				errKeys[docKeys[i].String()] = struct{}{}
+			}
+		}
+		authorKey := ecid.NewPseudoRandom(rng).PublicKeyBytes()
+		for _, putParallelism := range []uint32{1, 2, 3} {
+			slPub := &fixedSingleLoadPublisher{
+				publishedKeys: make(map[string]bool),
+				errKeys:       errKeys,
+			}
+			params, err := NewParameters(DefaultPutTimeout, DefaultGetTimeout,
+				putParallelism, DefaultGetParallelism)
+			assert.Nil(t, err)
+			mlPub := NewMultiLoadPublisher(slPub, params)
+
+			errs := mlPub.PublishEach(docKeys, authorKey, cb, true)
+			assert.Equal(t, nDocs, len(errs))
+
+			// check failing keys have errors and all others have been "published"
+			for i, docKey := range docKeys {
+				_, isErr := errKeys[docKey.String()]
+				_, published := slPub.publishedKeys[docKey.String()]
+				assert.Equal(t, isErr, errs[i] != nil)
+				assert.Equal(t, !isErr, published)
+			}
+		}
+	}
+}
+
 func TestMultiAcquirePublish(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	getterBalancer := &fixedGetterBalancer{}
@@ -379,6 +416,7 @@ func (p *memPublisherAcquirer) Acquire(docKey id.ID, authorPub []byte, lc api.Ge
 type fixedSingleLoadPublisher struct {
 	mu            sync.Mutex
 	publishedKeys map[string]bool // key -> deleted value
+	errKeys       map[string]struct{}
 	err           error
 }
 
@@ -387,6 +425,9 @@ func (f *fixedSingleLoadPublisher) Publish(
 ) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	if _, in := f.errKeys[docKey.String()]; in {
+		return errors.New("some Publish error")
+	}
 	if f.err == nil {
 		f.publishedKeys[docKey.String()] = delete
 	}
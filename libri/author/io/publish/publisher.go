@@ -9,6 +9,7 @@ import (
 	"github.com/drausin/libri/libri/common/ecid"
 	cerrors "github.com/drausin/libri/libri/common/errors"
 	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/parallel"
 	"github.com/drausin/libri/libri/common/storage"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/client"
@@ -210,6 +211,11 @@ type MultiLoadPublisher interface {
 	// clients for its Put requests.
 	Publish(docKeys []id.ID, authorPub []byte, cb client.PutterBalancer, delete bool) error
 
+	// PublishEach in parallel loads and publishes the documents with the given keys like
+	// Publish, but it continues past failures and returns the error (or nil) from publishing
+	// each document, in the same order as the keys.
+	PublishEach(docKeys []id.ID, authorPub []byte, cb client.PutterBalancer, delete bool) []error
+
 	// GetRetryPutter returns a new retrying api.Putter.
 	GetRetryPutter(cb client.PutterBalancer) api.Putter
 }
@@ -259,6 +265,16 @@ func (p *multiLoadPublisher) Publish(
 	}
 }
 
+func (p *multiLoadPublisher) PublishEach(
+	docKeys []id.ID, authorPub []byte, cb client.PutterBalancer, delete bool,
+) []error {
+
+	rlc := p.GetRetryPutter(cb)
+	return parallel.Do(len(docKeys), p.params.PutParallelism, func(i int) error {
+		return p.inner.Publish(docKeys[i], authorPub, rlc, delete)
+	})
+}
+
 func (p *multiLoadPublisher) GetRetryPutter(cb client.PutterBalancer) api.Putter {
 	return lclient.NewRetryPutter(cb, p.params.PutTimeout)
 }
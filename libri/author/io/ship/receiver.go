@@ -2,6 +2,7 @@ package ship
 
 import (
 	"github.com/drausin/libri/libri/author/io/enc"
+	"github.com/drausin/libri/libri/author/io/page"
 	"github.com/drausin/libri/libri/author/io/publish"
 	"github.com/drausin/libri/libri/author/keychain"
 	"github.com/drausin/libri/libri/common/ecid"
@@ -19,11 +20,24 @@ type Receiver interface {
 	// keys.
 	ReceiveEntry(envelopeKey id.ID) (*api.Document, *enc.EEK, error)
 
+	// ReceiveEntryOnly gets (from libri) the envelope and entry implied by the envelope key but
+	// none of the entry's pages, returning the entry and encryption keys. It is useful when only
+	// the entry metadata is needed.
+	ReceiveEntryOnly(envelopeKey id.ID) (*api.Document, *enc.EEK, error)
+
+	// PageLoader returns a page.Loader for the pages of the entry (received via
+	// ReceiveEntryOnly) that gets them from libri a batch at a time as they're loaded from the
+	// inner page.Loader, so the pages after any where loading is aborted aren't downloaded.
+	PageLoader(entryDoc *api.Document, inner page.Loader) (page.Loader, error)
+
 	ReceiveEnvelope(envelopeKey id.ID) (*api.Envelope, error)
 
 	GetEEK(envelope *api.Envelope) (*enc.EEK, error)
 }
 
+// pageBatchSize is the number of pages a PageLoader gets from libri at a time.
+const pageBatchSize = 8
+
 type receiver struct {
 	librarians client.GetterBalancer
 	readerKeys keychain.Getter
@@ -51,25 +65,60 @@ func NewReceiver(
 }
 
 func (r *receiver) ReceiveEntry(envelopeKey id.ID) (*api.Document, *enc.EEK, error) {
-	envelope, err := r.ReceiveEnvelope(envelopeKey)
+	envelope, entryDoc, eek, err := r.receiveEntryOnly(envelopeKey)
 	if err != nil {
 		return nil, nil, err
 	}
-	eek, err := r.GetEEK(envelope)
+	if err := r.getPages(entryDoc, envelope.AuthorPublicKey); err != nil {
+		return nil, nil, err
+	}
+	return entryDoc, eek, nil
+}
+
+func (r *receiver) ReceiveEntryOnly(envelopeKey id.ID) (*api.Document, *enc.EEK, error) {
+	_, entryDoc, eek, err := r.receiveEntryOnly(envelopeKey)
 	if err != nil {
 		return nil, nil, err
 	}
-	// get the entry and pages
+	if _, ok := entryDoc.Contents.(*api.Document_Entry); !ok {
+		return nil, nil, api.ErrUnexpectedDocumentType
+	}
+	return entryDoc, eek, nil
+}
+
+func (r *receiver) PageLoader(entryDoc *api.Document, inner page.Loader) (page.Loader, error) {
+	entry, ok := entryDoc.Contents.(*api.Document_Entry)
+	if !ok {
+		return nil, api.ErrUnexpectedDocumentType
+	}
+	if entry.Entry.Page != nil {
+		// the single page is in the entry itself, so there's nothing to get
+		return inner, r.getPages(entryDoc, entry.Entry.AuthorPublicKey)
+	}
+	return &receivingLoader{
+		receiver:  r,
+		authorPub: entry.Entry.AuthorPublicKey,
+		inner:     inner,
+	}, nil
+}
+
+func (r *receiver) receiveEntryOnly(envelopeKey id.ID) (
+	*api.Envelope, *api.Document, *enc.EEK, error) {
+	envelope, err := r.ReceiveEnvelope(envelopeKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	eek, err := r.GetEEK(envelope)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	entryKey := id.FromBytes(envelope.EntryKey)
 	rlc := r.msAcquirer.GetRetryGetter(r.librarians)
 	entryDoc, err := r.acquirer.Acquire(entryKey, envelope.AuthorPublicKey, rlc)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	if err := r.getPages(entryDoc, envelope.AuthorPublicKey); err != nil {
-		return nil, nil, err
-	}
-	return entryDoc, eek, nil
+	return envelope, entryDoc, eek, nil
 }
 
 func (r *receiver) ReceiveEnvelope(envelopeKey id.ID) (*api.Envelope, error) {
@@ -121,3 +170,33 @@ func (r *receiver) getPages(entryDoc *api.Document, authorPubBytes []byte) error
 	// should never get here
 	return api.ErrUnknownDocumentType
 }
+
+// receivingLoader gets each batch of pages from libri right before loading them from the inner
+// page.Loader.
+type receivingLoader struct {
+	receiver  *receiver
+	authorPub []byte
+	inner     page.Loader
+}
+
+func (l *receivingLoader) Load(keys []id.ID, pages chan *api.Page, abort chan struct{}) error {
+	for i := 0; i < len(keys); i += pageBatchSize {
+		select {
+		case <-abort:
+			return nil
+		default:
+		}
+		batch := keys[i:]
+		if len(batch) > pageBatchSize {
+			batch = batch[:pageBatchSize]
+		}
+		err := l.receiver.msAcquirer.Acquire(batch, l.authorPub, l.receiver.librarians)
+		if err != nil {
+			return err
+		}
+		if err := l.inner.Load(batch, pages, abort); err != nil {
+			return err
+		}
+	}
+	return nil
+}
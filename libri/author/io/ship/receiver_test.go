@@ -70,6 +70,15 @@ func TestReceiver_ReceiveEntry_ok(t *testing.T) {
 		assert.Equal(t, entry1, entry2)
 		assert.Equal(t, eek1, eek2)
 
+		// check that receiving just the entry doesn't get any pages
+		msAcq2 := &fixedMultiStoreAcquirer{}
+		r2 := NewReceiver(cb, readerKeys, acq, msAcq2, storage.NewTestDocSLD())
+		entry3, eek3, err := r2.ReceiveEntryOnly(envelopeKey)
+		assert.Nil(t, err)
+		assert.Equal(t, entry1, entry3)
+		assert.Equal(t, eek1, eek3)
+		assert.Nil(t, msAcq2.docKeys)
+
 		// check that pages have been stored, if necessary
 		assert.Equal(t, pageKeys, msAcq.docKeys)
 		if entry1.Contents.(*api.Document_Entry).Entry.Page != nil {
@@ -168,6 +177,17 @@ func TestReceiver_ReceiveEntry_err(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, receivedDoc)
 	assert.Nil(t, receivedKeys)
+
+	// check ReceiveEntryOnly errors bubble up
+	receivedDoc, receivedKeys, err = r5.ReceiveEntryOnly(envelopeKey)
+	assert.NotNil(t, err)
+	assert.Nil(t, receivedDoc)
+	assert.Nil(t, receivedKeys)
+
+	receivedDoc, receivedKeys, err = r6.ReceiveEntryOnly(envelopeKey)
+	assert.Equal(t, api.ErrUnexpectedDocumentType, err)
+	assert.Nil(t, receivedDoc)
+	assert.Nil(t, receivedKeys)
 }
 
 func TestReceiver_GetEEK_err(t *testing.T) {
@@ -227,6 +247,74 @@ func TestReceiver_GetEEK_err(t *testing.T) {
 	assert.Nil(t, eek)
 }
 
+func TestReceiver_PageLoader(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	inner := &fixedLoader{}
+	docS := storage.NewTestDocSLD()
+
+	// single page entries have nothing to receive
+	msAcq := &fixedMultiStoreAcquirer{}
+	r := NewReceiver(&fixedGetterBalancer{}, nil, nil, msAcq, docS)
+	single := &api.Document{
+		Contents: &api.Document_Entry{Entry: api.NewTestSinglePageEntry(rng)},
+	}
+	pageL, err := r.PageLoader(single, inner)
+	assert.Nil(t, err)
+	assert.Equal(t, inner, pageL)
+	assert.Len(t, docS.Stored, 1)
+	assert.Nil(t, msAcq.docKeys)
+
+	// multi-page entries receive their pages a batch at a time
+	entry := api.NewTestMultiPageEntry(rng)
+	keys := make([]id.ID, 2*pageBatchSize+1)
+	for i := range keys {
+		keys[i] = id.NewPseudoRandom(rng)
+	}
+	multi := &api.Document{Contents: &api.Document_Entry{Entry: entry}}
+	pageL, err = r.PageLoader(multi, inner)
+	assert.Nil(t, err)
+	err = pageL.Load(keys, nil, make(chan struct{}))
+	assert.Nil(t, err)
+	assert.Equal(t, keys[2*pageBatchSize:], msAcq.docKeys)
+	assert.Equal(t, entry.AuthorPublicKey, msAcq.authorPub)
+	assert.Equal(t, [][]id.ID{keys[:pageBatchSize], keys[pageBatchSize : 2*pageBatchSize],
+		keys[2*pageBatchSize:]}, inner.loaded)
+
+	// no more batches are received once loading is aborted
+	inner = &fixedLoader{abortAfter: 1}
+	pageL, err = r.PageLoader(multi, inner)
+	assert.Nil(t, err)
+	err = pageL.Load(keys, nil, make(chan struct{}))
+	assert.Nil(t, err)
+	assert.Equal(t, keys[:pageBatchSize], msAcq.docKeys)
+	assert.Len(t, inner.loaded, 1)
+
+	// check Acquire error bubbles up
+	msAcq.err = errors.New("some Acquire error")
+	pageL, err = r.PageLoader(multi, inner)
+	assert.Nil(t, err)
+	assert.Equal(t, msAcq.err, pageL.Load(keys, nil, make(chan struct{})))
+
+	// non-entry documents have no pages
+	pageDoc := &api.Document{Contents: &api.Document_Page{Page: api.NewTestPage(rng)}}
+	pageL, err = r.PageLoader(pageDoc, inner)
+	assert.Equal(t, api.ErrUnexpectedDocumentType, err)
+	assert.Nil(t, pageL)
+}
+
+type fixedLoader struct {
+	loaded     [][]id.ID
+	abortAfter int
+}
+
+func (f *fixedLoader) Load(keys []id.ID, pages chan *api.Page, abort chan struct{}) error {
+	f.loaded = append(f.loaded, keys)
+	if len(f.loaded) == f.abortAfter {
+		close(abort)
+	}
+	return nil
+}
+
 type fixedAcquirer struct {
 	docs map[string]*api.Document
 	err  error
@@ -268,6 +356,10 @@ func (f *fixedKeychain) Sample() (ecid.ID, error) {
 	return nil, nil
 }
 
+func (f *fixedKeychain) Primary() (ecid.ID, error) {
+	return nil, nil
+}
+
 func (f *fixedKeychain) Get(publicKey []byte) (ecid.ID, bool) {
 	return f.getKey, f.in
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/drausin/libri/libri/author/io/pack"
 	"github.com/drausin/libri/libri/author/io/publish"
 	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/storage"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/client"
 )
@@ -18,26 +19,39 @@ type Shipper interface {
 		entry *api.Document, authorPub []byte, readerPub []byte, kek *enc.KEK, eek *enc.EEK,
 	) (*api.Document, id.ID, error)
 
+	// ShipEnvelope publishes (to libri) a new envelope document for an existing entry with
+	// the author and reader public keys. It returns the published envelope document and its key.
 	ShipEnvelope(entryKey id.ID, authorPub, readerPub []byte, kek *enc.KEK, eek *enc.EEK) (
 		*api.Document, id.ID, error)
+
+	// ShipEnvelopes concurrently publishes (to libri) a new envelope document for an existing
+	// entry for each of the reader public keys, whose KEKs are given in the same order. It
+	// returns the envelope documents, their keys, and the error (or nil) from shipping each,
+	// all in the same order as the reader public keys.
+	ShipEnvelopes(entryKey id.ID, authorPub []byte, readerPubs [][]byte, keks []*enc.KEK,
+		eek *enc.EEK) ([]*api.Document, []id.ID, []error)
 }
 
 type shipper struct {
 	librarians  client.PutterBalancer
 	publisher   publish.Publisher
 	mlPublisher publish.MultiLoadPublisher
+	docS        storage.DocumentStorer
 	deletePages bool
 }
 
-// NewShipper creates a new Shipper from a librarian api.Balancer and two publisher variants.
+// NewShipper creates a new Shipper from a librarian api.Balancer, two publisher variants, and
+// the storage.DocumentStorer the MultiLoadPublisher loads documents from.
 func NewShipper(
 	librarians client.PutterBalancer,
 	publisher publish.Publisher,
-	mlPublisher publish.MultiLoadPublisher) Shipper {
+	mlPublisher publish.MultiLoadPublisher,
+	docS storage.DocumentStorer) Shipper {
 	return &shipper{
 		librarians:  librarians,
 		publisher:   publisher,
 		mlPublisher: mlPublisher,
+		docS:        docS,
 		deletePages: true,
 	}
 }
@@ -82,3 +96,55 @@ func (s *shipper) ShipEnvelope(
 	}
 	return envelope, envelopeKey, nil
 }
+
+func (s *shipper) ShipEnvelopes(
+	entryKey id.ID, authorPub []byte, readerPubs [][]byte, keks []*enc.KEK, eek *enc.EEK,
+) ([]*api.Document, []id.ID, []error) {
+
+	envelopes := make([]*api.Document, len(readerPubs))
+	envelopeKeys := make([]id.ID, len(readerPubs))
+	errs := make([]error, len(readerPubs))
+
+	// store envelopes locally so the MultiLoadPublisher can load & publish them concurrently
+	toPublish := make([]id.ID, 0, len(readerPubs))
+	toPublishIdxs := make([]int, 0, len(readerPubs))
+	for i, readerPub := range readerPubs {
+		envelope, envelopeKey, err := s.storeEnvelope(entryKey, authorPub, readerPub, keks[i],
+			eek)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		envelopes[i], envelopeKeys[i] = envelope, envelopeKey
+		toPublish = append(toPublish, envelopeKey)
+		toPublishIdxs = append(toPublishIdxs, i)
+	}
+
+	publishErrs := s.mlPublisher.PublishEach(toPublish, authorPub, s.librarians, true)
+	for j, err := range publishErrs {
+		if err != nil {
+			i := toPublishIdxs[j]
+			envelopes[i], envelopeKeys[i], errs[i] = nil, nil, err
+		}
+	}
+	return envelopes, envelopeKeys, errs
+}
+
+func (s *shipper) storeEnvelope(
+	entryKey id.ID, authorPub, readerPub []byte, kek *enc.KEK, eek *enc.EEK,
+) (*api.Document, id.ID, error) {
+
+	eekCiphertext, eekCiphertextMAC, err := kek.Encrypt(eek)
+	if err != nil {
+		return nil, nil, err
+	}
+	envelope := pack.NewEnvelopeDoc(entryKey, authorPub, readerPub, eekCiphertext, eekCiphertextMAC)
+	envelopeKey, err := api.GetKey(envelope)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.docS.Store(envelopeKey, envelope); err != nil {
+		return nil, nil, err
+	}
+	return envelope, envelopeKey, nil
+}
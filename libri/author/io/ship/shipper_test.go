@@ -9,6 +9,7 @@ import (
 	"github.com/drausin/libri/libri/author/io/enc"
 	"github.com/drausin/libri/libri/author/io/publish"
 	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/ecid"
 	cerrors "github.com/drausin/libri/libri/common/errors"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/common/storage"
@@ -26,6 +27,7 @@ func TestShipper_Ship_ok(t *testing.T) {
 		&fixedPutterBalancer{},
 		&fixedPublisher{},
 		mlPub,
+		storage.NewTestDocSLD(),
 	)
 	entry := &api.Document{
 		Contents: &api.Document_Entry{
@@ -74,6 +76,7 @@ func TestShipper_Ship_err(t *testing.T) {
 		&fixedPutterBalancer{},
 		&fixedPublisher{},
 		&fixedMultiLoadPublisher{err: errors.New("some Publish error")},
+		storage.NewTestDocSLD(),
 	)
 
 	// check GetEntryPageKeys error bubbles up
@@ -98,6 +101,7 @@ func TestShipper_Ship_err(t *testing.T) {
 		&fixedPutterBalancer{},
 		&fixedPublisher{[]error{errors.New("some Publish error")}},
 		&fixedMultiLoadPublisher{},
+		storage.NewTestDocSLD(),
 	)
 	envelope, entryKey, err = s.ShipEntry(entry, authorPub, readerPub, kek, eek)
 	assert.NotNil(t, err)
@@ -109,6 +113,7 @@ func TestShipper_Ship_err(t *testing.T) {
 		&fixedPutterBalancer{},
 		&fixedPublisher{},
 		&fixedMultiLoadPublisher{},
+		storage.NewTestDocSLD(),
 	)
 	envelope, entryKey, err = s.ShipEntry(entry, authorPub, readerPub, &enc.KEK{}, eek)
 	assert.NotNil(t, err)
@@ -120,6 +125,7 @@ func TestShipper_Ship_err(t *testing.T) {
 		&fixedPutterBalancer{},
 		&fixedPublisher{[]error{nil, errors.New("some Publish error")}},
 		&fixedMultiLoadPublisher{},
+		storage.NewTestDocSLD(),
 	)
 	envelope, entryKey, err = s.ShipEntry(entry, authorPub, readerPub, kek, eek)
 	assert.NotNil(t, err)
@@ -127,6 +133,63 @@ func TestShipper_Ship_err(t *testing.T) {
 	assert.Nil(t, entryKey)
 }
 
+func TestShipper_ShipEnvelopes(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	nReaders := 4
+	authorKey := ecid.NewPseudoRandom(rng)
+	authorPub := authorKey.PublicKeyBytes()
+	readerPubs := make([][]byte, nReaders)
+	keks := make([]*enc.KEK, nReaders)
+	for i := range readerPubs {
+		readerKey := ecid.NewPseudoRandom(rng)
+		readerPubs[i] = readerKey.PublicKeyBytes()
+		var err error
+		keks[i], err = enc.NewKEK(authorKey.Key(), &readerKey.Key().PublicKey)
+		assert.Nil(t, err)
+	}
+	keks[1] = &enc.KEK{} // Encrypt error
+	eek := enc.NewPseudoRandomEEK(rng)
+	entryKey := id.NewPseudoRandom(rng)
+
+	// publish error on third reader's envelope (second published)
+	mlPub := &fixedMultiLoadPublisher{
+		errs: []error{nil, errors.New("some Publish error"), nil},
+	}
+	docSLD := storage.NewTestDocSLD()
+	s := NewShipper(&fixedPutterBalancer{}, &fixedPublisher{}, mlPub, docSLD)
+
+	envs, envKeys, errs := s.ShipEnvelopes(entryKey, authorPub, readerPubs, keks, eek)
+	assert.Equal(t, len(readerPubs), len(envs))
+	assert.Equal(t, len(readerPubs), len(envKeys))
+	assert.Equal(t, len(readerPubs), len(errs))
+	for i := range readerPubs {
+		if i == 1 || i == 2 {
+			assert.NotNil(t, errs[i], i)
+			assert.Nil(t, envs[i], i)
+			assert.Nil(t, envKeys[i], i)
+			continue
+		}
+		assert.Nil(t, errs[i], i)
+		env := envs[i].Contents.(*api.Document_Envelope).Envelope
+		assert.Equal(t, entryKey.Bytes(), env.EntryKey)
+		assert.Equal(t, readerPubs[i], env.ReaderPublicKey)
+		envKey, err := api.GetKey(envs[i])
+		assert.Nil(t, err)
+		assert.Equal(t, envKey, envKeys[i])
+		assert.Equal(t, envs[i], docSLD.Stored[envKey.String()])
+	}
+	assert.True(t, mlPub.deleted)
+
+	// check store error
+	s = NewShipper(&fixedPutterBalancer{}, &fixedPublisher{}, &fixedMultiLoadPublisher{},
+		&storage.TestDocSLD{
+			Stored:   make(map[string]*api.Document),
+			StoreErr: errors.New("some Store error"),
+		})
+	_, _, errs = s.ShipEnvelopes(entryKey, authorPub, readerPubs[:1], keks[:1], eek)
+	assert.NotNil(t, errs[0])
+}
+
 func TestShipReceive(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	getterBalancer := &fixedGetterBalancer{}
@@ -187,7 +250,7 @@ func TestShipReceive(t *testing.T) {
 			publish.NewSingleLoadPublisher(pubAcq, docSL1),
 			params,
 		)
-		s := NewShipper(putterBalancer, pubAcq, mlP).(*shipper)
+		s := NewShipper(putterBalancer, pubAcq, mlP, docSL1).(*shipper)
 		s.deletePages = false // so we can check them at the end
 		eek := enc.NewPseudoRandomEEK(rng)
 		envelopeKeys := make([]id.ID, nDocs)
@@ -227,6 +290,7 @@ func TestShipReceive(t *testing.T) {
 
 type fixedMultiLoadPublisher struct {
 	err     error
+	errs    []error
 	deleted bool
 	putter  api.Putter
 }
@@ -238,6 +302,16 @@ func (f *fixedMultiLoadPublisher) Publish(
 	return f.err
 }
 
+func (f *fixedMultiLoadPublisher) PublishEach(
+	docKeys []id.ID, authorPub []byte, cb client.PutterBalancer, delete bool,
+) []error {
+	f.deleted = delete
+	if f.errs != nil {
+		return f.errs
+	}
+	return make([]error, len(docKeys))
+}
+
 func (f *fixedMultiLoadPublisher) GetRetryPutter(cb client.PutterBalancer) api.Putter {
 	return f.putter
 }
@@ -48,6 +48,10 @@ type Getter interface {
 type Sampler interface {
 	// Sample randomly selects a key from the collection.
 	Sample() (ecid.ID, error)
+
+	// Primary returns the key with the lowest public key, which is the same key every time the
+	// collection is loaded.
+	Primary() (ecid.ID, error)
 }
 
 // GetterSampler and a collection of ECDSA keys that can be both looked up and sampled.
@@ -101,6 +105,14 @@ func (kc *keychain) Sample() (ecid.ID, error) {
 	return kc.privs[kc.pubs[i]], nil
 }
 
+// Primary returns the key with the lowest public key from the keychain.
+func (kc *keychain) Primary() (ecid.ID, error) {
+	if len(kc.pubs) == 0 {
+		return nil, ErrEmptyKeychain
+	}
+	return kc.privs[kc.pubs[0]], nil
+}
+
 func (kc *keychain) Get(publicKey []byte) (ecid.ID, bool) {
 	value, in := kc.privs[pubKeyString(publicKey)]
 	return value, in
@@ -25,6 +25,27 @@ func TestSampler_Sample_err(t *testing.T) {
 	assert.Nil(t, k1)
 }
 
+func TestSampler_Primary(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	ecids := []ecid.ID{
+		ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng),
+	}
+	k1, err := FromECIDs(ecids).Primary()
+	assert.Nil(t, err)
+	for _, other := range ecids {
+		assert.True(t, pubKeyString(k1.PublicKeyBytes()) <= pubKeyString(other.PublicKeyBytes()))
+	}
+
+	// same key regardless of order
+	k2, err := FromECIDs([]ecid.ID{ecids[2], ecids[0], ecids[1]}).Primary()
+	assert.Nil(t, err)
+	assert.Equal(t, k1, k2)
+
+	k3, err := New(0).Primary()
+	assert.Equal(t, ErrEmptyKeychain, err)
+	assert.Nil(t, k3)
+}
+
 func TestGetter_Get(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	kc := New(3)
@@ -21,6 +21,19 @@ const (
 	logNPages         = "n_pages"
 	logMetadata       = "metadata"
 	logSpeedMbps      = "speed_Mbps"
+	logGroup          = "group"
+	logNMembers       = "n_members"
+	logNShared        = "n_shared"
+	logNAlreadyShared = "n_already_shared"
+	logNFailed        = "n_failed"
+	logDirpath        = "dirpath"
+	logNFiles         = "n_files"
+	logNDownloaded    = "n_downloaded"
+	logElapsed        = "elapsed"
+	logPointerName    = "pointer_name"
+	logPointerKey     = "pointer_key"
+	logSequence       = "sequence"
+	logNVersions      = "n_versions"
 )
 
 func packingContentFields(authorPub []byte) []zapcore.Field {
@@ -69,6 +82,14 @@ func docFields(
 	}
 }
 
+func infoFields(envKey, entryKey fmt.Stringer, md *api.EntryMetadata) []zapcore.Field {
+	return []zapcore.Field{
+		zap.Stringer(logEnvelopeKey, envKey),
+		zap.Stringer(logEntryKey, entryKey),
+		zap.Object(logMetadata, md),
+	}
+}
+
 func unpackingContentFields(entryKey fmt.Stringer, nPages int) []zapcore.Field {
 	return []zapcore.Field{
 		zap.Stringer(logEntryKey, entryKey),
@@ -91,3 +112,89 @@ func sharedDocFields(envKey, entryKey fmt.Stringer, authorPub, readerPub []byte)
 		zap.String(logReaderPubShort, id.ShortHex(readerPub[1:9])),
 	}
 }
+
+func sharingGroupFields(envKey fmt.Stringer, group *Group) []zapcore.Field {
+	return []zapcore.Field{
+		zap.Stringer(logEnvelopeKey, envKey),
+		zap.String(logGroup, group.Name),
+		zap.Int(logNMembers, len(group.ReaderPubs)),
+	}
+}
+
+func sharedGroupFields(envKey fmt.Stringer, report *GroupShareReport) []zapcore.Field {
+	return []zapcore.Field{
+		zap.Stringer(logEnvelopeKey, envKey),
+		zap.String(logGroup, report.Group),
+		zap.Int(logNShared, report.NShared()),
+		zap.Int(logNAlreadyShared, report.NAlreadyShared()),
+		zap.Int(logNFailed, report.NFailed()),
+	}
+}
+
+func uploadingDirFields(dirpath string) []zapcore.Field {
+	return []zapcore.Field{
+		zap.String(logDirpath, dirpath),
+	}
+}
+
+func uploadedDirFields(
+	dirpath string, envKey fmt.Stringer, nFiles int, elapsed time.Duration,
+) []zapcore.Field {
+	return []zapcore.Field{
+		zap.String(logDirpath, dirpath),
+		zap.Stringer(logEnvelopeKey, envKey),
+		zap.Int(logNFiles, nFiles),
+		zap.Duration(logElapsed, elapsed),
+	}
+}
+
+func downloadingDirFields(dirpath string, envKey fmt.Stringer) []zapcore.Field {
+	return []zapcore.Field{
+		zap.String(logDirpath, dirpath),
+		zap.Stringer(logEnvelopeKey, envKey),
+	}
+}
+
+func downloadedDirFields(
+	dirpath string, envKey fmt.Stringer, nFiles, nDownloaded int, elapsed time.Duration,
+) []zapcore.Field {
+	return []zapcore.Field{
+		zap.String(logDirpath, dirpath),
+		zap.Stringer(logEnvelopeKey, envKey),
+		zap.Int(logNFiles, nFiles),
+		zap.Int(logNDownloaded, nDownloaded),
+		zap.Duration(logElapsed, elapsed),
+	}
+}
+
+func publishingPointerFields(name string, pointerKey, envKey fmt.Stringer) []zapcore.Field {
+	return []zapcore.Field{
+		zap.String(logPointerName, name),
+		zap.Stringer(logPointerKey, pointerKey),
+		zap.Stringer(logEnvelopeKey, envKey),
+	}
+}
+
+func resolvingPointerFields(pointerKey fmt.Stringer) []zapcore.Field {
+	return []zapcore.Field{
+		zap.Stringer(logPointerKey, pointerKey),
+	}
+}
+
+func pointerFields(pointerKey fmt.Stringer, pointer *api.Pointer) []zapcore.Field {
+	return []zapcore.Field{
+		zap.String(logPointerName, pointer.Name),
+		zap.Stringer(logPointerKey, pointerKey),
+		zap.Uint64(logSequence, pointer.Sequence),
+		zap.Stringer(logEnvelopeKey, id.FromBytes(pointer.TargetEnvelopeKey)),
+	}
+}
+
+func historyFields(versions []*Version) []zapcore.Field {
+	latest := versions[len(versions)-1]
+	return []zapcore.Field{
+		zap.Stringer(logEnvelopeKey, latest.EnvelopeKey),
+		zap.Stringer(logEntryKey, latest.EntryKey),
+		zap.Int(logNVersions, len(versions)),
+	}
+}
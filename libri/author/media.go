@@ -0,0 +1,48 @@
+package author
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// ManifestMediaType is the media type of manifest entries, which list the files of an
+	// uploaded directory.
+	ManifestMediaType = "application/x-libri-manifest"
+
+	octetMediaType = "application/octet-stream"
+	sniffLen       = 512
+)
+
+// DetectMediaType returns the media type of a local file, first by sniffing its head, then by its
+// extension, and falling back to a generic binary media type.
+func DetectMediaType(fp string) (string, error) {
+	file, err := os.Open(fp) // nolint: gosec
+	if err != nil {
+		return "", err
+	}
+	head := make([]byte, sniffLen)
+	_, err = file.Read(head)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if err = file.Close(); err != nil {
+		return "", err
+	}
+	mediaType := http.DetectContentType(head)
+	if mediaType != octetMediaType {
+		// sniffing head of file worked
+		return mediaType, nil
+	}
+	mediaType = mime.TypeByExtension(filepath.Ext(fp))
+	if mediaType != "" {
+		// get by extension worked
+		return mediaType, nil
+	}
+
+	// fallback
+	return octetMediaType, nil
+}
@@ -0,0 +1,37 @@
+package author
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectMediaType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-media-type")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+
+	cases := []struct {
+		filename string
+		content  []byte
+		expected string
+	}{
+		{"some-file", []byte("%PDF-1.4 some PDF content"), "application/pdf"},
+		{"empty.pdf", []byte{}, "application/pdf"},
+		{"empty", []byte{}, octetMediaType},
+	}
+	for _, c := range cases {
+		fp := filepath.Join(dir, c.filename)
+		assert.Nil(t, ioutil.WriteFile(fp, c.content, 0600))
+		mediaType, err := DetectMediaType(fp)
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, mediaType, c.filename)
+	}
+
+	mediaType, err := DetectMediaType(filepath.Join(dir, "missing"))
+	assert.NotNil(t, err)
+	assert.Empty(t, mediaType)
+}
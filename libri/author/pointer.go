@@ -0,0 +1,171 @@
+package author
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/storage"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/proto"
+)
+
+var (
+	// ErrPointerNotFound indicates when a pointer could not be found locally or in the libri
+	// network.
+	ErrPointerNotFound = errors.New("pointer not found")
+
+	pointerKeyPrefix = []byte("Pointer")
+)
+
+// Publish points the pointer with the given name at the given envelope, incrementing its sequence
+// number so librarians replace any previous version. Pointers are signed by the primary key of the
+// author keychain (see keychain.Sampler), so their keys (see api.GetPointerKey) are the same from
+// any data directory with the same keychains. It returns the pointer document and its key.
+func (a *Author) Publish(name string, envKey id.ID) (*api.Document, id.ID, error) {
+	authorKey, err := a.authorKeys.Primary()
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error getting author key", err)
+	}
+	authorPub := authorKey.PublicKeyBytes()
+	pointerKey := api.GetPointerKey(authorPub, name)
+	a.logger.Debug("publishing pointer", publishingPointerFields(name, pointerKey, envKey)...)
+
+	// the pointer may also have been published from another data directory, or this one may
+	// have been lost, so the next sequence follows the latest local or network version
+	latest, err := a.latestPointer(pointerKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	pointer := &api.Pointer{
+		AuthorPublicKey:   authorPub,
+		Name:              name,
+		Sequence:          1,
+		TargetEnvelopeKey: envKey.Bytes(),
+	}
+	if latest != nil {
+		pointer.Sequence = latest.Sequence + 1
+	}
+	if err = api.SignPointer(pointer, authorKey.Key()); err != nil {
+		return nil, nil, a.logAndReturnErr("error signing pointer", err)
+	}
+	doc := &api.Document{Contents: &api.Document_Pointer{Pointer: pointer}}
+	if err = api.ValidateDocument(doc); err != nil {
+		return nil, nil, a.logAndReturnErr("invalid pointer", err)
+	}
+
+	lc, err := a.putters.Next()
+	if err != nil {
+		return nil, nil, a.logAndReturnErr("error getting librarian client", err)
+	}
+	if _, err = a.publisher.Publish(doc, authorPub, lc); err != nil {
+		return nil, nil, a.logAndReturnErr("error publishing pointer", err)
+	}
+	if err = savePointer(a.clientSL, pointerKey, pointer); err != nil {
+		return nil, nil, a.logAndReturnErr("error saving pointer", err)
+	}
+
+	a.logger.Info("published pointer", pointerFields(pointerKey, pointer)...)
+	return doc, pointerKey, nil
+}
+
+// Resolve returns the latest version of the pointer with the given name published by this
+// author.
+func (a *Author) Resolve(name string) (*api.Pointer, error) {
+	authorKey, err := a.authorKeys.Primary()
+	if err != nil {
+		return nil, a.logAndReturnErr("error getting author key", err)
+	}
+	return a.ResolveKey(api.GetPointerKey(authorKey.PublicKeyBytes(), name))
+}
+
+// ResolveKey returns the latest version of the pointer with the given key, which may have been
+// published by another author. The librarians resolve the pointer to the newest version held by
+// the peers closest to its key.
+func (a *Author) ResolveKey(pointerKey id.ID) (*api.Pointer, error) {
+	a.logger.Debug("resolving pointer", resolvingPointerFields(pointerKey)...)
+	pointer, err := a.latestPointer(pointerKey)
+	if err != nil {
+		return nil, err
+	}
+	if pointer == nil {
+		return nil, a.logAndReturnErr("error acquiring pointer", ErrPointerNotFound)
+	}
+	a.logger.Info("resolved pointer", pointerFields(pointerKey, pointer)...)
+	return pointer, nil
+}
+
+// latestPointer returns the newer of the pointer version saved locally when this author last
+// published it and the version acquired from the network, or nil if neither exists.
+func (a *Author) latestPointer(pointerKey id.ID) (*api.Pointer, error) {
+	local, err := loadPointer(a.clientSL, pointerKey)
+	if err != nil {
+		return nil, a.logAndReturnErr("error loading pointer", err)
+	}
+	acquired, err := a.acquirePointer(pointerKey)
+	if err != nil {
+		return nil, err
+	}
+	if acquired != nil && (local == nil || acquired.Sequence > local.Sequence) {
+		return acquired, nil
+	}
+	return local, nil
+}
+
+// acquirePointer returns the pointer with the given key from the network, or nil if it doesn't
+// exist.
+func (a *Author) acquirePointer(pointerKey id.ID) (*api.Pointer, error) {
+	lc, err := a.getters.Next()
+	if err != nil {
+		return nil, a.logAndReturnErr("error getting librarian client", err)
+	}
+	doc, err := a.acquirer.Acquire(pointerKey, nil, lc)
+	if err == api.ErrMissingDocument || (err == nil && doc == nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, a.logAndReturnErr("error acquiring pointer", err)
+	}
+	pointer := doc.GetPointer()
+	if pointer == nil {
+		return nil, a.logAndReturnErr("error acquiring pointer", api.ErrUnexpectedDocumentType)
+	}
+	docKey, err := api.GetKey(doc)
+	if err != nil {
+		return nil, a.logAndReturnErr("error getting pointer key", err)
+	}
+	if !bytes.Equal(pointerKey.Bytes(), docKey.Bytes()) {
+		return nil, a.logAndReturnErr("error acquiring pointer", api.ErrUnexpectedKey)
+	}
+	return pointer, nil
+}
+
+// localPointerKey returns the client storage key for the latest version of the pointer with the
+// given key published by this author.
+func localPointerKey(pointerKey id.ID) []byte {
+	h := sha256.New()
+	_, _ = h.Write(pointerKeyPrefix)
+	_, _ = h.Write(pointerKey.Bytes())
+	return h.Sum(nil)
+}
+
+func loadPointer(l storage.Loader, pointerKey id.ID) (*api.Pointer, error) {
+	buf, err := l.Load(localPointerKey(pointerKey))
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	pointer := &api.Pointer{}
+	if err := proto.Unmarshal(buf, pointer); err != nil {
+		return nil, err
+	}
+	return pointer, nil
+}
+
+func savePointer(s storage.Storer, pointerKey id.ID, pointer *api.Pointer) error {
+	buf, err := proto.Marshal(pointer)
+	if err != nil {
+		return err
+	}
+	return s.Store(localPointerKey(pointerKey), buf)
+}
@@ -0,0 +1,186 @@
+package author
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthor_PublishResolve(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	pubAcq := &memPublisherAcquirer{
+		docs: make(map[string]*api.Document),
+	}
+	a1, a2 := newTestAuthor(), newTestAuthor()
+	defer func() { cerrors.MaybePanic(a1.CloseAndRemove()) }()
+	defer func() { cerrors.MaybePanic(a2.CloseAndRemove()) }()
+	for _, a := range []*Author{a1, a2} {
+		a.publisher, a.acquirer = pubAcq, pubAcq
+		a.putters, a.getters = &fixedPutterBalancer{}, &fixedGetterBalancer{}
+	}
+	name := "some/dataset"
+
+	envKey1 := id.NewPseudoRandom(rng)
+	doc1, pointerKey1, err := a1.Publish(name, envKey1)
+	assert.Nil(t, err)
+	authorKey, err := a1.authorKeys.Primary()
+	assert.Nil(t, err)
+	assert.Equal(t, api.GetPointerKey(authorKey.PublicKeyBytes(), name), pointerKey1)
+	assert.Equal(t, uint64(1), doc1.GetPointer().Sequence)
+	assert.Equal(t, envKey1.Bytes(), doc1.GetPointer().TargetEnvelopeKey)
+
+	// re-publishing increments sequence but keeps the same key
+	envKey2 := id.NewPseudoRandom(rng)
+	doc2, pointerKey2, err := a1.Publish(name, envKey2)
+	assert.Nil(t, err)
+	assert.Equal(t, pointerKey1, pointerKey2)
+	assert.Equal(t, uint64(2), doc2.GetPointer().Sequence)
+
+	pointer, err := a1.Resolve(name)
+	assert.Nil(t, err)
+	assert.Equal(t, doc2.GetPointer(), pointer)
+
+	// other author resolves from the network
+	pointer, err = a2.ResolveKey(pointerKey1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), pointer.Sequence)
+	assert.Equal(t, envKey2.Bytes(), pointer.TargetEnvelopeKey)
+
+	// but doesn't have a pointer with the same name
+	pointer, err = a2.Resolve(name)
+	assert.Equal(t, ErrPointerNotFound, err)
+	assert.Nil(t, pointer)
+
+	// author with the same keychains but a new data dir continues from the network version
+	a3 := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a3.CloseAndRemove()) }()
+	a3.authorKeys = a1.authorKeys
+	a3.publisher, a3.acquirer = pubAcq, pubAcq
+	a3.putters, a3.getters = &fixedPutterBalancer{}, &fixedGetterBalancer{}
+	envKey3 := id.NewPseudoRandom(rng)
+	doc3, pointerKey3, err := a3.Publish(name, envKey3)
+	assert.Nil(t, err)
+	assert.Equal(t, pointerKey1, pointerKey3)
+	assert.Equal(t, uint64(3), doc3.GetPointer().Sequence)
+
+	// and the original author resolves the newer network version over its local one
+	pointer, err = a1.Resolve(name)
+	assert.Nil(t, err)
+	assert.Equal(t, doc3.GetPointer(), pointer)
+}
+
+func TestAuthor_Publish_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	pubAcq := &memPublisherAcquirer{
+		docs: make(map[string]*api.Document),
+	}
+	a.publisher, a.acquirer = pubAcq, pubAcq
+	a.putters, a.getters = &fixedPutterBalancer{}, &fixedGetterBalancer{}
+	envKey := id.NewPseudoRandom(rng)
+
+	// invalid name
+	doc, pointerKey, err := a.Publish("", envKey)
+	assert.Equal(t, api.ErrEmptyPointerName, err)
+	assert.Nil(t, doc)
+	assert.Nil(t, pointerKey)
+
+	// author key error
+	authorKeys := a.authorKeys
+	a.authorKeys = &fixedKeychain{sampleErr: errors.New("some Primary error")}
+	doc, pointerKey, err = a.Publish("some/dataset", envKey)
+	assert.NotNil(t, err)
+	assert.Nil(t, doc)
+	assert.Nil(t, pointerKey)
+	a.authorKeys = authorKeys
+
+	// acquire error
+	a.acquirer = &errPublisherAcquirer{err: errors.New("some Acquire error")}
+	doc, pointerKey, err = a.Publish("some/dataset", envKey)
+	assert.NotNil(t, err)
+	assert.Nil(t, doc)
+	assert.Nil(t, pointerKey)
+	a.acquirer = pubAcq
+
+	// putter balancer error
+	a.putters = &fixedPutterBalancer{err: errors.New("some Next error")}
+	doc, pointerKey, err = a.Publish("some/dataset", envKey)
+	assert.NotNil(t, err)
+	assert.Nil(t, doc)
+	assert.Nil(t, pointerKey)
+
+	// publish error
+	a.putters = &fixedPutterBalancer{}
+	a.publisher = &errPublisherAcquirer{err: errors.New("some Publish error")}
+	doc, pointerKey, err = a.Publish("some/dataset", envKey)
+	assert.NotNil(t, err)
+	assert.Nil(t, doc)
+	assert.Nil(t, pointerKey)
+
+	// pointer not saved, so next successful publish is still first
+	a.publisher = pubAcq
+	doc, _, err = a.Publish("some/dataset", envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), doc.GetPointer().Sequence)
+}
+
+func TestAuthor_ResolveKey_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newTestAuthor()
+	defer func() { cerrors.MaybePanic(a.CloseAndRemove()) }()
+	a.getters = &fixedGetterBalancer{}
+
+	// getter balancer error
+	a.getters = &fixedGetterBalancer{err: errors.New("some Next error")}
+	pointer, err := a.ResolveKey(id.NewPseudoRandom(rng))
+	assert.NotNil(t, err)
+	assert.Nil(t, pointer)
+	a.getters = &fixedGetterBalancer{}
+
+	// acquire error
+	a.acquirer = &errPublisherAcquirer{err: errors.New("some Acquire error")}
+	pointer, err = a.ResolveKey(id.NewPseudoRandom(rng))
+	assert.NotNil(t, err)
+	assert.Nil(t, pointer)
+
+	// not a pointer
+	pubAcq := &memPublisherAcquirer{
+		docs: make(map[string]*api.Document),
+	}
+	a.acquirer = pubAcq
+	doc, docKey := api.NewTestDocument(rng)
+	pubAcq.docs[docKey.String()] = doc
+	pointer, err = a.ResolveKey(docKey)
+	assert.Equal(t, api.ErrUnexpectedDocumentType, err)
+	assert.Nil(t, pointer)
+
+	// pointer under wrong key
+	otherPointer, _ := api.NewTestPointer(rng)
+	wrongKey := id.NewPseudoRandom(rng)
+	pubAcq.docs[wrongKey.String()] = &api.Document{
+		Contents: &api.Document_Pointer{Pointer: otherPointer},
+	}
+	pointer, err = a.ResolveKey(wrongKey)
+	assert.Equal(t, api.ErrUnexpectedKey, err)
+	assert.Nil(t, pointer)
+}
+
+type errPublisherAcquirer struct {
+	err error
+}
+
+func (p *errPublisherAcquirer) Publish(doc *api.Document, authorPub []byte, lc api.Putter) (
+	id.ID, error) {
+	return nil, p.err
+}
+
+func (p *errPublisherAcquirer) Acquire(docKey id.ID, authorPub []byte, lc api.Getter) (
+	*api.Document, error) {
+	return nil, p.err
+}
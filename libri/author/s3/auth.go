@@ -0,0 +1,286 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm        = "AWS4-HMAC-SHA256"
+	sigV4ChunkAlgorithm   = "AWS4-HMAC-SHA256-PAYLOAD"
+	sigV4Terminator       = "aws4_request"
+	sigV4Service          = "s3"
+	amzDateHeader         = "X-Amz-Date"
+	amzDateFormat         = "20060102T150405Z"
+	unsignedPayload       = "UNSIGNED-PAYLOAD"
+	signedStreamPayload   = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	unsignedStreamPayload = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+
+	// MaxRequestTimeSkew is the max difference between a signed request's time and the gateway's
+	// clock.
+	MaxRequestTimeSkew = 15 * time.Minute
+)
+
+var (
+	errAccessDenied = &s3Error{http.StatusForbidden, "AccessDenied", "Access Denied"}
+	errMissingAuth  = &s3Error{http.StatusForbidden, "AccessDenied",
+		"Requests must be signed with AWS Signature Version 4 in the Authorization header."}
+	errInvalidAccessKeyID = &s3Error{http.StatusForbidden, "InvalidAccessKeyId",
+		"The AWS access key ID you provided does not exist in our records."}
+	errSignatureMismatch = &s3Error{http.StatusForbidden, "SignatureDoesNotMatch",
+		"The request signature we calculated does not match the signature you provided."}
+	errRequestTimeSkewed = &s3Error{http.StatusForbidden, "RequestTimeTooSkewed",
+		"The difference between the request time and the server's time is too large."}
+	errAuthHeaderMalformed = &s3Error{http.StatusBadRequest, "AuthorizationHeaderMalformed",
+		"The authorization header is malformed."}
+	errInvalidContentSHA256 = &s3Error{http.StatusBadRequest, "InvalidArgument",
+		"The 'x-amz-content-sha256' header is missing or has an unsupported value."}
+	errContentSHA256Mismatch = &s3Error{http.StatusBadRequest, "XAmzContentSHA256Mismatch",
+		"The provided 'x-amz-content-sha256' header does not match what was computed."}
+
+	emptySHA256 = hex.EncodeToString(sha256.New().Sum(nil))
+)
+
+// Credentials are the access key ID and secret access key requests to the gateway must be signed
+// with.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// authenticator checks that requests are signed with the credentials via AWS Signature Version 4
+// in the Authorization header, or only that they're addressed to a loopback host if there are no
+// credentials.
+type authenticator struct {
+	creds *Credentials
+	now   func() time.Time
+}
+
+// signature is a verified request signature, which signs the chunks of a streaming upload.
+type signature struct {
+	key       []byte
+	date      string
+	scope     string
+	signature string
+}
+
+// authenticate returns an error if the request isn't authentic. It returns the verified
+// signature, if the gateway has credentials, to check any signed payload against.
+func (a *authenticator) authenticate(r *http.Request) (*signature, error) {
+	if a.creds == nil {
+		// without credentials, only local clients should be able to reach the gateway, but a
+		// browser on this host may still send requests for other (rebound) host names
+		if !IsLoopbackHost(r.Host) {
+			return nil, errAccessDenied
+		}
+		return nil, nil
+	}
+	return a.verify(r)
+}
+
+// verify checks the request signature in the Authorization header.
+func (a *authenticator) verify(r *http.Request) (*signature, error) {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return nil, errMissingAuth
+	}
+	if !strings.HasPrefix(auth, sigV4Algorithm+" ") {
+		return nil, errAuthHeaderMalformed
+	}
+	fields := make(map[string]string)
+	for _, field := range strings.Split(auth[len(sigV4Algorithm)+1:], ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			return nil, errAuthHeaderMalformed
+		}
+		fields[kv[0]] = kv[1]
+	}
+	credential := strings.Split(fields["Credential"], "/")
+	signedHeaders := strings.Split(fields["SignedHeaders"], ";")
+	if len(credential) != 5 || credential[3] != sigV4Service ||
+		credential[4] != sigV4Terminator || fields["Signature"] == "" ||
+		!containsString(signedHeaders, "host") {
+		return nil, errAuthHeaderMalformed
+	}
+	if credential[0] != a.creds.AccessKeyID {
+		return nil, errInvalidAccessKeyID
+	}
+	date := r.Header.Get(amzDateHeader)
+	reqTime, err := time.Parse(amzDateFormat, date)
+	if err != nil || !strings.HasPrefix(date, credential[1]) {
+		return nil, errAuthHeaderMalformed
+	}
+	if skew := a.now().Sub(reqTime); skew > MaxRequestTimeSkew || skew < -MaxRequestTimeSkew {
+		return nil, errRequestTimeSkewed
+	}
+	payloadHash := r.Header.Get(contentSHA256Header)
+	if !isPayloadHash(payloadHash) && payloadHash != unsignedPayload &&
+		payloadHash != signedStreamPayload && payloadHash != unsignedStreamPayload {
+		return nil, errInvalidContentSHA256
+	}
+
+	scope := strings.Join(credential[1:], "/")
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		uriEncode(r.URL.Path, false),
+		canonicalQuery(r.URL.Query()),
+		canonicalHeaders(r, signedHeaders),
+		fields["SignedHeaders"],
+		payloadHash,
+	}, "\n")
+	key := signingKey(a.creds.SecretAccessKey, credential[1], credential[2])
+	sig := &signature{key: key, date: date, scope: scope}
+	sig.signature = sig.sign(sigV4Algorithm, sha256Hex([]byte(canonicalRequest)))
+	if !signaturesEqual(sig.signature, fields["Signature"]) {
+		return nil, errSignatureMismatch
+	}
+	return sig, nil
+}
+
+// sign returns the signature of the string to sign with the given algorithm and the date, scope,
+// and given lines (e.g., the hash of the canonical request) after it.
+func (s *signature) sign(algorithm string, lines ...string) string {
+	stringToSign := strings.Join(append([]string{algorithm, s.date, s.scope}, lines...), "\n")
+	return hex.EncodeToString(hmacSHA256(s.key, []byte(stringToSign)))
+}
+
+// nextChunk returns the signature of the next chunk of a streaming upload, which is chained from
+// the signature of the previous one (or the seed request signature).
+func (s *signature) nextChunk(chunkHash []byte) string {
+	s.signature = s.sign(sigV4ChunkAlgorithm, s.signature, emptySHA256,
+		hex.EncodeToString(chunkHash))
+	return s.signature
+}
+
+func signingKey(secret, date, region string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), []byte(date))
+	key = hmacSHA256(key, []byte(region))
+	key = hmacSHA256(key, []byte(sigV4Service))
+	return hmacSHA256(key, []byte(sigV4Terminator))
+}
+
+// canonicalQuery returns the encoded query parameters sorted by name and then value.
+func canonicalQuery(q url.Values) string {
+	params := make([][2]string, 0, len(q))
+	for name, values := range q {
+		for _, value := range values {
+			params = append(params, [2]string{uriEncode(name, true), uriEncode(value, true)})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i][0] != params[j][0] {
+			return params[i][0] < params[j][0]
+		}
+		return params[i][1] < params[j][1]
+	})
+	encoded := make([]string, len(params))
+	for i, param := range params {
+		encoded[i] = param[0] + "=" + param[1]
+	}
+	return strings.Join(encoded, "&")
+}
+
+func canonicalHeaders(r *http.Request, signedHeaders []string) string {
+	var b strings.Builder
+	for _, name := range signedHeaders {
+		values := r.Header[http.CanonicalHeaderKey(name)]
+		if name == "host" {
+			values = []string{r.Host}
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		b.WriteString(name + ":" + strings.Join(trimmed, ",") + "\n")
+	}
+	return b.String()
+}
+
+// uriEncode encodes every byte but the unreserved characters, and also slashes if encodeSlash.
+func uriEncode(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&15])
+	}
+	return b.String()
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write(data)
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func signaturesEqual(expected, actual string) bool {
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) == 1
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLoopbackHost returns whether the host (with optional port) is localhost or a loopback IP
+// address. Other host names are treated as non-loopback since they may resolve to any address.
+func IsLoopbackHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isPayloadHash returns whether the value is a hex SHA-256 hash of the payload.
+func isPayloadHash(value string) bool {
+	decoded, err := hex.DecodeString(value)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// hashReader returns errContentSHA256Mismatch at the end of the content if its SHA-256 hash isn't
+// the expected hex hash.
+type hashReader struct {
+	r        io.Reader
+	h        hash.Hash
+	expected string
+}
+
+func (hr *hashReader) Read(p []byte) (int, error) {
+	n, err := hr.r.Read(p)
+	_, _ = hr.h.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(hr.h.Sum(nil)) != strings.ToLower(hr.expected) {
+		return n, errContentSHA256Mismatch
+	}
+	return n, err
+}
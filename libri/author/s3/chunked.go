@@ -0,0 +1,135 @@
+package s3
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	contentSHA256Header    = "X-Amz-Content-Sha256"
+	streamingPayloadPrefix = "STREAMING-"
+	awsChunkedEncoding     = "aws-chunked"
+	chunkSignaturePrefix   = "chunk-signature="
+)
+
+var errMalformedChunk = errors.New("malformed aws-chunked body chunk")
+
+// isChunked returns whether the request body has the aws-chunked encoding AWS SDKs use for
+// streaming uploads.
+func isChunked(r *http.Request) bool {
+	if strings.HasPrefix(r.Header.Get(contentSHA256Header), streamingPayloadPrefix) {
+		return true
+	}
+	for _, encoding := range strings.Split(r.Header.Get("Content-Encoding"), ",") {
+		if strings.TrimSpace(encoding) == awsChunkedEncoding {
+			return true
+		}
+	}
+	return false
+}
+
+// chunkedReader decodes an aws-chunked body, where each chunk is a hex size (with optional
+// ";chunk-signature=..." extension) line followed by that many bytes and a CRLF, ending with a
+// zero-size chunk. If it has the request signature, it checks the signature of each chunk once
+// it has been read, otherwise chunk signatures are ignored. Any trailers after the last chunk
+// are ignored.
+type chunkedReader struct {
+	r         *bufio.Reader
+	sig       *signature
+	chunkSig  string
+	chunkHash hash.Hash
+	remaining int64
+	done      bool
+}
+
+func newChunkedReader(r io.Reader, sig *signature) io.Reader {
+	return &chunkedReader{r: bufio.NewReader(r), sig: sig, chunkHash: sha256.New()}
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if c.done {
+		return 0, io.EOF
+	}
+	if c.remaining == 0 {
+		if err := c.readChunkHeader(); err != nil {
+			return 0, err
+		}
+		if c.remaining == 0 {
+			c.done = true
+			return 0, c.verifyChunk()
+		}
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	_, _ = c.chunkHash.Write(p[:n])
+	c.remaining -= int64(n)
+	if c.remaining == 0 && err == nil {
+		if err = c.readCRLF(); err == nil {
+			err = c.verifyChunk()
+		}
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (c *chunkedReader) readChunkHeader() error {
+	line, err := c.r.ReadString('\n')
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	c.chunkSig = ""
+	if i := strings.IndexByte(line, ';'); i >= 0 {
+		ext := line[i+1:]
+		if strings.HasPrefix(ext, chunkSignaturePrefix) {
+			c.chunkSig = ext[len(chunkSignaturePrefix):]
+		}
+		line = line[:i]
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(line), 16, 64)
+	if err != nil || size < 0 {
+		return errMalformedChunk
+	}
+	c.remaining = size
+	return nil
+}
+
+func (c *chunkedReader) readCRLF() error {
+	crlf := make([]byte, 2)
+	if _, err := io.ReadFull(c.r, crlf); err != nil {
+		return err
+	}
+	if string(crlf) != "\r\n" {
+		return errMalformedChunk
+	}
+	return nil
+}
+
+// verifyChunk checks the signature of the chunk just read if the reader has the request
+// signature, returning io.EOF after the last chunk if it's valid.
+func (c *chunkedReader) verifyChunk() error {
+	if c.sig != nil {
+		expected := c.sig.nextChunk(c.chunkHash.Sum(nil))
+		if !signaturesEqual(expected, c.chunkSig) {
+			return errSignatureMismatch
+		}
+	}
+	c.chunkHash.Reset()
+	if c.done {
+		return io.EOF
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package s3
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsChunked(t *testing.T) {
+	cases := []struct {
+		header   http.Header
+		expected bool
+	}{
+		{http.Header{}, false},
+		{http.Header{contentSHA256Header: {"UNSIGNED-PAYLOAD"}}, false},
+		{http.Header{contentSHA256Header: {"STREAMING-AWS4-HMAC-SHA256-PAYLOAD"}}, true},
+		{http.Header{contentSHA256Header: {"STREAMING-UNSIGNED-PAYLOAD-TRAILER"}}, true},
+		{http.Header{"Content-Encoding": {"gzip"}}, false},
+		{http.Header{"Content-Encoding": {"aws-chunked"}}, true},
+		{http.Header{"Content-Encoding": {"aws-chunked, gzip"}}, true},
+	}
+	for i, c := range cases {
+		assert.Equal(t, c.expected, isChunked(&http.Request{Header: c.header}), i)
+	}
+}
+
+func TestChunkedReader_ok(t *testing.T) {
+	cases := map[string]string{
+		"signed": "5;chunk-signature=abcd\r\nhello\r\n" +
+			"7;chunk-signature=abcd\r\n, world\r\n" +
+			"0;chunk-signature=abcd\r\n\r\n",
+		"trailer":  "5\r\nhello\r\n7\r\n, world\r\n0\r\nx-amz-checksum-crc32:abcd\r\n\r\n",
+		"hex size": "c\r\nhello, world\r\n0\r\n\r\n",
+	}
+	for name, body := range cases {
+		decoded, err := ioutil.ReadAll(newChunkedReader(strings.NewReader(body), nil))
+		assert.Nil(t, err, name)
+		assert.Equal(t, "hello, world", string(decoded), name)
+	}
+
+	// small reads
+	cr := newChunkedReader(strings.NewReader(cases["signed"]), nil)
+	decoded, err := ioutil.ReadAll(io.LimitReader(cr, 3))
+	assert.Nil(t, err)
+	assert.Equal(t, "hel", string(decoded))
+	decoded, err = ioutil.ReadAll(cr)
+	assert.Nil(t, err)
+	assert.Equal(t, "lo, world", string(decoded))
+	n, err := cr.Read(make([]byte, 1))
+	assert.Equal(t, 0, n)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestChunkedReader_err(t *testing.T) {
+	cases := map[string]struct {
+		body     string
+		expected error
+	}{
+		"empty":              {"", io.ErrUnexpectedEOF},
+		"bad size":           {"zz\r\nhello\r\n0\r\n\r\n", errMalformedChunk},
+		"negative size":      {"-5\r\nhello\r\n0\r\n\r\n", errMalformedChunk},
+		"short chunk":        {"a\r\nhello", io.ErrUnexpectedEOF},
+		"missing CRLF":       {"5\r\nhello", io.ErrUnexpectedEOF},
+		"bad CRLF":           {"5\r\nhelloXX0\r\n\r\n", errMalformedChunk},
+		"missing last chunk": {"5\r\nhello\r\n", io.ErrUnexpectedEOF},
+	}
+	for name, c := range cases {
+		_, err := ioutil.ReadAll(newChunkedReader(strings.NewReader(c.body), nil))
+		assert.Equal(t, c.expected, err, name)
+	}
+}
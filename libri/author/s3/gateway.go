@@ -0,0 +1,654 @@
+package s3
+
+import (
+	"bufio"
+	"crypto/md5" // nolint: gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"go.uber.org/zap"
+)
+
+const (
+	// PropertyBucket is the entry metadata property with the bucket of an uploaded object.
+	PropertyBucket = "s3-bucket"
+
+	// PropertyKey is the entry metadata property with the key of an uploaded object.
+	PropertyKey = "s3-key"
+
+	// PropertyMetadataPrefix is the prefix of the entry metadata properties with the user-defined
+	// metadata of an uploaded object.
+	PropertyMetadataPrefix = "s3-meta-"
+
+	// MaxListKeys is the max number of keys and common prefixes a ListObjectsV2 response has.
+	MaxListKeys = 1000
+
+	defaultContentType   = "application/octet-stream"
+	metadataHeaderPrefix = "X-Amz-Meta-"
+	xmlNamespace         = "http://s3.amazonaws.com/doc/2006-03-01/"
+	listTimeFormat       = "2006-01-02T15:04:05.000Z"
+	standardStorageClass = "STANDARD"
+)
+
+var (
+	errNoSuchKey = &s3Error{http.StatusNotFound, "NoSuchKey",
+		"The specified key does not exist."}
+	errInvalidBucketName = &s3Error{http.StatusBadRequest, "InvalidBucketName",
+		"The specified bucket is not valid."}
+	errInvalidArgument = &s3Error{http.StatusBadRequest, "InvalidArgument",
+		"Invalid argument."}
+	errIncompleteBody = &s3Error{http.StatusBadRequest, "IncompleteBody",
+		"The request body could not be read."}
+	errInvalidRange = &s3Error{http.StatusRequestedRangeNotSatisfiable, "InvalidRange",
+		"The requested range is not satisfiable."}
+	errNotImplemented = &s3Error{http.StatusNotImplemented, "NotImplemented",
+		"The operation is not implemented."}
+	errInternal = &s3Error{http.StatusInternalServerError, "InternalError",
+		"We encountered an internal error. Please try again."}
+
+	bucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+	// unsupportedSubresources are object URL query parameters of operations the gateway doesn't
+	// implement, like multipart uploads and ACLs.
+	unsupportedSubresources = []string{
+		"acl", "legal-hold", "partNumber", "retention", "tagging", "torrent", "uploadId",
+		"uploads", "versionId",
+	}
+)
+
+// Author is the subset of author.Author operations the gateway uses.
+type Author interface {
+	// Upload uploads the content, returning the document and its envelope key.
+	Upload(content io.Reader, mediaType string, opts *author.UploadOptions) (
+		*api.Document, id.ID, error)
+
+	// Download writes the content of the document with the given envelope key, stopping early
+	// without an error if the content writer returns author.ErrStopDownload.
+	Download(content io.Writer, envKey id.ID) error
+}
+
+// s3Error is an S3 error with its HTTP status.
+type s3Error struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *s3Error) Error() string {
+	return e.code + ": " + e.message
+}
+
+// errorResponse is the XML body of an error response.
+type errorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+// listBucketResult is the XML body of a ListObjectsV2 response.
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"ListBucketResult"`
+	Xmlns                 string         `xml:"xmlns,attr"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter,omitempty"`
+	StartAfter            string         `xml:"StartAfter,omitempty"`
+	ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	EncodingType          string         `xml:"EncodingType,omitempty"`
+	KeyCount              int            `xml:"KeyCount"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	Contents              []listObject   `xml:"Contents"`
+	CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+}
+
+type listObject struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// NewGateway returns an http.Handler implementing a subset of the S3 API on top of the author, so
+// tools that speak S3 can store objects in libri unchanged.
+//
+// Requests use path-style URLs, e.g., /{bucket}/{key}. The gateway supports
+//
+//   - PutObject: uploads the object content via the author and indexes its envelope key under the
+//     bucket and key, replacing any existing object
+//   - GetObject: downloads the object content, or just the bytes of a single Range
+//   - HeadObject: returns the object headers from the index
+//   - DeleteObject: removes the object from the index, leaving its documents in libri
+//   - ListObjectsV2: lists the indexed objects in the bucket
+//   - HeadBucket: succeeds for any valid bucket name
+//
+// Buckets exist implicitly once they have objects. Other operations get a NotImplemented error.
+//
+// If the credentials aren't nil, requests must be signed with them via AWS Signature Version 4 in
+// the Authorization header, as the AWS SDKs and CLI do by default. Otherwise, requests aren't
+// authenticated, so the gateway should only listen on localhost, and it rejects requests for
+// non-loopback hosts, which a browser may send to it via a rebound DNS name.
+func NewGateway(
+	author Author, index *Index, creds *Credentials, logger *zap.Logger,
+) http.Handler {
+	return &gateway{
+		author: author,
+		index:  index,
+		auth:   &authenticator{creds: creds, now: time.Now},
+		logger: logger,
+	}
+}
+
+type gateway struct {
+	author Author
+	index  *Index
+	auth   *authenticator
+	logger *zap.Logger
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sig, err := g.auth.authenticate(r)
+	if err != nil {
+		g.writeErr(w, r, err)
+		return
+	}
+	bucket, key := splitPath(r.URL.Path)
+	if bucket == "" {
+		g.writeErr(w, r, errNotImplemented)
+		return
+	}
+	if !bucketNamePattern.MatchString(bucket) {
+		g.writeErr(w, r, errInvalidBucketName)
+		return
+	}
+	if key == "" {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+			g.listObjects(w, r, bucket)
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusOK)
+		default:
+			g.writeErr(w, r, errNotImplemented)
+		}
+		return
+	}
+	if hasUnsupportedSubresource(r.URL.Query()) {
+		g.writeErr(w, r, errNotImplemented)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		if r.Header.Get("X-Amz-Copy-Source") != "" {
+			g.writeErr(w, r, errNotImplemented)
+			return
+		}
+		g.putObject(w, r, sig, bucket, key)
+	case http.MethodGet:
+		g.getObject(w, r, bucket, key, true)
+	case http.MethodHead:
+		g.getObject(w, r, bucket, key, false)
+	case http.MethodDelete:
+		g.deleteObject(w, r, bucket, key)
+	default:
+		g.writeErr(w, r, errNotImplemented)
+	}
+}
+
+func (g *gateway) putObject(
+	w http.ResponseWriter, r *http.Request, sig *signature, bucket, key string,
+) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+	if _, _, err := mime.ParseMediaType(contentType); err != nil {
+		g.writeErr(w, r, errInvalidArgument)
+		return
+	}
+	obj := &Object{
+		ContentType:  contentType,
+		LastModified: time.Now().UTC(),
+		Metadata:     userMetadata(r.Header),
+	}
+	h := md5.New() // nolint: gosec
+	content := &bodyReader{r: io.TeeReader(requestBody(r, sig), h)}
+	buffered := bufio.NewReader(content)
+
+	// empty objects have no content to upload, so they're only indexed
+	if _, err := buffered.Peek(1); err != io.EOF {
+		if err != nil {
+			g.writeErr(w, r, bodyErr(err))
+			return
+		}
+		_, envKey, err := g.author.Upload(buffered, contentType, uploadOptions(bucket, key, obj))
+		if content.err != nil {
+			g.writeErr(w, r, bodyErr(content.err))
+			return
+		}
+		if err != nil {
+			g.logger.Error("error uploading object", zap.String("bucket", bucket),
+				zap.String("key", key), zap.Error(err))
+			g.writeErr(w, r, errInternal)
+			return
+		}
+		obj.EnvelopeKey = envKey.String()
+	}
+	obj.Size = content.n
+	obj.ETag = hex.EncodeToString(h.Sum(nil))
+	if err := g.index.Put(bucket, key, obj); err != nil {
+		g.logger.Error("error indexing object", zap.String("bucket", bucket),
+			zap.String("key", key), zap.Error(err))
+		g.writeErr(w, r, errInternal)
+		return
+	}
+	w.Header().Set("ETag", quote(obj.ETag))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (g *gateway) getObject(
+	w http.ResponseWriter, r *http.Request, bucket, key string, withBody bool,
+) {
+	obj, in := g.index.Get(bucket, key)
+	if !in {
+		g.writeErr(w, r, errNoSuchKey)
+		return
+	}
+	start, end, partial, err := parseRange(r.Header.Get("Range"), obj.Size)
+	if err != nil {
+		g.writeErr(w, r, err)
+		return
+	}
+	status, length := http.StatusOK, obj.Size
+	if partial {
+		status, length = http.StatusPartialContent, end-start
+	}
+	writeHeader := func() {
+		setObjectHeaders(w.Header(), obj)
+		w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+		if partial {
+			w.Header().Set("Content-Range",
+				fmt.Sprintf("bytes %d-%d/%d", start, end-1, obj.Size))
+		}
+		w.WriteHeader(status)
+	}
+	if !withBody || length == 0 {
+		writeHeader()
+		return
+	}
+	envKey, err := id.FromString(obj.EnvelopeKey)
+	if err != nil {
+		g.logger.Error("invalid indexed envelope key", zap.String("bucket", bucket),
+			zap.String("key", key), zap.Error(err))
+		g.writeErr(w, r, errInternal)
+		return
+	}
+	rw := &rangeWriter{w: w, writeHeader: writeHeader, start: start, end: end}
+	if err := g.author.Download(rw, envKey); err != nil {
+		g.logger.Error("error downloading object", zap.String("bucket", bucket),
+			zap.String("key", key), zap.Error(err))
+		if !rw.wroteHeader {
+			g.writeErr(w, r, errInternal)
+		}
+		// otherwise the client sees a body shorter than the Content-Length
+		return
+	}
+	if !rw.wroteHeader {
+		writeHeader()
+	}
+}
+
+func (g *gateway) deleteObject(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	if err := g.index.Delete(bucket, key); err != nil {
+		g.logger.Error("error deleting object from index", zap.String("bucket", bucket),
+			zap.String("key", key), zap.Error(err))
+		g.writeErr(w, r, errInternal)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (g *gateway) listObjects(w http.ResponseWriter, r *http.Request, bucket string) {
+	q := r.URL.Query()
+	result := &listBucketResult{
+		Xmlns:             xmlNamespace,
+		Name:              bucket,
+		Prefix:            q.Get("prefix"),
+		Delimiter:         q.Get("delimiter"),
+		StartAfter:        q.Get("start-after"),
+		ContinuationToken: q.Get("continuation-token"),
+		EncodingType:      q.Get("encoding-type"),
+		MaxKeys:           MaxListKeys,
+	}
+	if value := q.Get("max-keys"); value != "" {
+		maxKeys, err := strconv.Atoi(value)
+		if err != nil || maxKeys < 0 {
+			g.writeErr(w, r, errInvalidArgument)
+			return
+		}
+		if maxKeys < MaxListKeys {
+			result.MaxKeys = maxKeys
+		}
+	}
+	after := result.StartAfter
+	if result.ContinuationToken != "" {
+		lastKey, err := base64.URLEncoding.DecodeString(result.ContinuationToken)
+		if err != nil {
+			g.writeErr(w, r, errInvalidArgument)
+			return
+		}
+		after = string(lastKey)
+	}
+	encode := func(s string) string { return s }
+	switch result.EncodingType {
+	case "":
+	case "url":
+		encode = url.QueryEscape
+	default:
+		g.writeErr(w, r, errInvalidArgument)
+		return
+	}
+
+	// keys are sorted, so the keys under each common prefix are contiguous
+	var lastKey, lastPrefix string
+	for _, key := range g.index.Keys(bucket) {
+		if key <= after || !strings.HasPrefix(key, result.Prefix) {
+			continue
+		}
+		prefix := commonPrefixOf(key, result.Prefix, result.Delimiter)
+		if prefix != "" && prefix == lastPrefix {
+			lastKey = key
+			continue
+		}
+		if result.KeyCount == result.MaxKeys {
+			result.IsTruncated = true
+			break
+		}
+		if prefix != "" {
+			result.CommonPrefixes = append(result.CommonPrefixes,
+				commonPrefix{Prefix: encode(prefix)})
+			lastPrefix = prefix
+		} else {
+			obj, in := g.index.Get(bucket, key)
+			if !in {
+				// deleted since getting the keys
+				continue
+			}
+			result.Contents = append(result.Contents, listObject{
+				Key:          encode(key),
+				LastModified: obj.LastModified.UTC().Format(listTimeFormat),
+				ETag:         quote(obj.ETag),
+				Size:         obj.Size,
+				StorageClass: standardStorageClass,
+			})
+		}
+		result.KeyCount++
+		lastKey = key
+	}
+	if result.IsTruncated && lastKey != "" {
+		result.NextContinuationToken = base64.URLEncoding.EncodeToString([]byte(lastKey))
+	}
+	result.Prefix = encode(result.Prefix)
+	result.Delimiter = encode(result.Delimiter)
+	result.StartAfter = encode(result.StartAfter)
+	g.writeXML(w, http.StatusOK, result)
+}
+
+func (g *gateway) writeErr(w http.ResponseWriter, r *http.Request, err error) {
+	s3Err, ok := err.(*s3Error)
+	if !ok {
+		s3Err = errInternal
+	}
+	if r.Method == http.MethodHead {
+		// HEAD responses have no body
+		w.WriteHeader(s3Err.status)
+		return
+	}
+	g.writeXML(w, s3Err.status, &errorResponse{
+		Code:     s3Err.code,
+		Message:  s3Err.message,
+		Resource: r.URL.Path,
+	})
+}
+
+func (g *gateway) writeXML(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		g.logger.Info("error writing s3 gateway response", zap.Error(err))
+		return
+	}
+	if err := xml.NewEncoder(w).Encode(body); err != nil {
+		g.logger.Info("error writing s3 gateway response", zap.Error(err))
+	}
+}
+
+// splitPath splits a path-style URL path into its bucket and key.
+func splitPath(path string) (string, string) {
+	path = strings.TrimPrefix(path, "/")
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return path, ""
+}
+
+func hasUnsupportedSubresource(q url.Values) bool {
+	for _, param := range unsupportedSubresources {
+		if _, in := q[param]; in {
+			return true
+		}
+	}
+	return false
+}
+
+// commonPrefixOf returns the prefix of the key through the first delimiter after the list prefix,
+// or an empty string if there's no delimiter or the key doesn't have it after the prefix.
+func commonPrefixOf(key, prefix, delimiter string) string {
+	if delimiter == "" {
+		return ""
+	}
+	i := strings.Index(key[len(prefix):], delimiter)
+	if i < 0 {
+		return ""
+	}
+	return key[:len(prefix)+i+len(delimiter)]
+}
+
+// userMetadata returns the user-defined (x-amz-meta-*) headers keyed by lower-case name without
+// the prefix.
+func userMetadata(header http.Header) map[string]string {
+	var metadata map[string]string
+	for name, values := range header {
+		if !strings.HasPrefix(name, metadataHeaderPrefix) || len(values) == 0 {
+			continue
+		}
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata[strings.ToLower(name[len(metadataHeaderPrefix):])] = values[0]
+	}
+	return metadata
+}
+
+// uploadOptions returns the upload options with the object bucket, key, and user-defined metadata
+// as entry metadata properties.
+func uploadOptions(bucket, key string, obj *Object) *author.UploadOptions {
+	props := map[string][]byte{
+		PropertyBucket: []byte(bucket),
+		PropertyKey:    []byte(key),
+	}
+	for name, value := range obj.Metadata {
+		props[PropertyMetadataPrefix+name] = []byte(value)
+	}
+	return &author.UploadOptions{Properties: props}
+}
+
+func setObjectHeaders(header http.Header, obj *Object) {
+	header.Set("Content-Type", obj.ContentType)
+	header.Set("ETag", quote(obj.ETag))
+	header.Set("Last-Modified", obj.LastModified.UTC().Format(http.TimeFormat))
+	header.Set("Accept-Ranges", "bytes")
+	for name, value := range obj.Metadata {
+		header.Set(metadataHeaderPrefix+name, value)
+	}
+}
+
+func quote(etag string) string {
+	return `"` + etag + `"`
+}
+
+// parseRange returns the start (inclusive) and end (exclusive) of the single byte range in the
+// Range header value for an object of the given size. It returns partial false (meaning the whole
+// object) if the value is empty, malformed, or has multiple ranges, and errInvalidRange if the
+// range isn't satisfiable.
+func parseRange(value string, size int64) (start, end int64, partial bool, err error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(value, prefix) || strings.Contains(value, ",") {
+		return 0, size, false, nil
+	}
+	spec := strings.TrimSpace(value[len(prefix):])
+	i := strings.IndexByte(spec, '-')
+	if i < 0 {
+		return 0, size, false, nil
+	}
+	first, last := strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+	if first == "" {
+		// suffix range of the last n bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, size, false, nil
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, errInvalidRange
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size, true, nil
+	}
+	start, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, size, false, nil
+	}
+	end = size
+	if last != "" {
+		lastByte, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || lastByte < start {
+			return 0, size, false, nil
+		}
+		if lastByte < size {
+			end = lastByte + 1
+		}
+	}
+	if start >= size {
+		return 0, 0, false, errInvalidRange
+	}
+	return start, end, true, nil
+}
+
+// requestBody returns the decoded request body, which also checks the payload against the
+// request signature, if the request has one.
+func requestBody(r *http.Request, sig *signature) io.Reader {
+	body := io.Reader(r.Body)
+	payloadHash := r.Header.Get(contentSHA256Header)
+	if sig != nil && isPayloadHash(payloadHash) {
+		body = &hashReader{r: body, h: sha256.New(), expected: payloadHash}
+	}
+	if isChunked(r) {
+		if payloadHash != signedStreamPayload {
+			// chunk signatures are only checked if the request signature covers them
+			sig = nil
+		}
+		body = newChunkedReader(body, sig)
+	}
+	return body
+}
+
+// bodyErr returns the S3 error for an error reading the request body.
+func bodyErr(err error) error {
+	if s3Err, ok := err.(*s3Error); ok {
+		return s3Err
+	}
+	return errIncompleteBody
+}
+
+// bodyReader counts the bytes read from the request body and records any read error, so it can
+// be distinguished from upload errors.
+type bodyReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+// rangeWriter writes only the bytes in [start, end) of the content written to it to the response,
+// writing the response header before the first of them. It discards the bytes before start and
+// returns author.ErrStopDownload once it has written the byte before end, so the rest of the
+// content isn't downloaded.
+type rangeWriter struct {
+	w           http.ResponseWriter
+	writeHeader func()
+	start       int64
+	end         int64
+	offset      int64
+	wroteHeader bool
+}
+
+func (rw *rangeWriter) Write(p []byte) (int, error) {
+	n := int64(len(p))
+	from, to := clamp(rw.start-rw.offset, n), clamp(rw.end-rw.offset, n)
+	rw.offset += n
+	if from >= to {
+		return len(p), nil
+	}
+	if !rw.wroteHeader {
+		rw.writeHeader()
+		rw.wroteHeader = true
+	}
+	if _, err := rw.w.Write(p[from:to]); err != nil {
+		return 0, err
+	}
+	if rw.offset >= rw.end {
+		return len(p), author.ErrStopDownload
+	}
+	return len(p), nil
+}
+
+// clamp returns x bounded to [0, max].
+func clamp(x, max int64) int64 {
+	if x < 0 {
+		return 0
+	}
+	if x > max {
+		return max
+	}
+	return x
+}
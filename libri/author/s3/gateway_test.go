@@ -0,0 +1,538 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/md5" // nolint: gosec
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/cluster"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/server"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestGateway_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newMemAuthor(rng)
+	s, idx, cleanup := newTestGateway(t, a)
+	defer cleanup()
+
+	// put & get object
+	content := api.RandBytes(rng, 1024)
+	rq := newRequest(t, http.MethodPut, s.URL+"/bucket/some/key.txt", bytes.NewReader(content))
+	rq.Header.Set("Content-Type", "text/plain")
+	rq.Header.Set("X-Amz-Meta-Some-Name", "some value")
+	rp := do(t, rq)
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+	assert.Equal(t, quote(md5Hex(content)), rp.Header.Get("ETag"))
+	assert.Len(t, a.uploads, 1)
+	for _, opts := range a.opts {
+		assert.Equal(t, "bucket", string(opts.Properties[PropertyBucket]))
+		assert.Equal(t, "some/key.txt", string(opts.Properties[PropertyKey]))
+		assert.Equal(t, "some value",
+			string(opts.Properties[PropertyMetadataPrefix+"some-name"]))
+	}
+	obj, in := idx.Get("bucket", "some/key.txt")
+	assert.True(t, in)
+	assert.Equal(t, int64(len(content)), obj.Size)
+	assert.Equal(t, "text/plain", obj.ContentType)
+
+	rp, body := get(t, s.URL+"/bucket/some/key.txt", "")
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+	assert.Equal(t, content, body)
+	assert.Equal(t, "text/plain", rp.Header.Get("Content-Type"))
+	assert.Equal(t, "some value", rp.Header.Get("X-Amz-Meta-Some-Name"))
+	assert.Equal(t, quote(md5Hex(content)), rp.Header.Get("ETag"))
+	assert.Equal(t, "1024", rp.Header.Get("Content-Length"))
+
+	// get ranges, with the memAuthor writing content in small pieces
+	a.writeSize = 100
+	ranges := map[string][2]int{
+		"bytes=0-0":       {0, 1},
+		"bytes=10-19":     {10, 20},
+		"bytes=150-449":   {150, 450},
+		"bytes=1000-":     {1000, 1024},
+		"bytes=1000-5000": {1000, 1024},
+		"bytes=-24":       {1000, 1024},
+		"bytes=-5000":     {0, 1024},
+	}
+	for value, r := range ranges {
+		rp, body = get(t, s.URL+"/bucket/some/key.txt", value)
+		assert.Equal(t, http.StatusPartialContent, rp.StatusCode, value)
+		assert.Equal(t, content[r[0]:r[1]], body, value)
+		assert.Equal(t, fmt.Sprintf("bytes %d-%d/1024", r[0], r[1]-1),
+			rp.Header.Get("Content-Range"), value)
+
+		// the download stops after the piece with the end of the range
+		downloaded := (r[1] + a.writeSize - 1) / a.writeSize * a.writeSize
+		if downloaded > len(content) {
+			downloaded = len(content)
+		}
+		assert.Equal(t, downloaded, a.downloaded, value)
+	}
+
+	// multiple ranges get the whole object
+	rp, body = get(t, s.URL+"/bucket/some/key.txt", "bytes=0-1,5-6")
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+	assert.Equal(t, content, body)
+
+	// head object
+	rp = do(t, newRequest(t, http.MethodHead, s.URL+"/bucket/some/key.txt", nil))
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+	assert.Equal(t, "1024", rp.Header.Get("Content-Length"))
+	assert.Equal(t, "some value", rp.Header.Get("X-Amz-Meta-Some-Name"))
+	assert.NotEmpty(t, rp.Header.Get("Last-Modified"))
+
+	// put & get empty object
+	rp = do(t, newRequest(t, http.MethodPut, s.URL+"/bucket/empty", nil))
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+	assert.Len(t, a.uploads, 1)
+	rp, body = get(t, s.URL+"/bucket/empty", "")
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+	assert.Empty(t, body)
+	assert.Equal(t, defaultContentType, rp.Header.Get("Content-Type"))
+	assert.Equal(t, quote(md5Hex(nil)), rp.Header.Get("ETag"))
+
+	// put aws-chunked object, replacing existing one
+	rq = newRequest(t, http.MethodPut, s.URL+"/bucket/some/key.txt",
+		strings.NewReader("5;chunk-signature=abcd\r\nhello\r\n0;chunk-signature=abcd\r\n\r\n"))
+	rq.Header.Set(contentSHA256Header, "STREAMING-AWS4-HMAC-SHA256-PAYLOAD")
+	rp = do(t, rq)
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+	rp, body = get(t, s.URL+"/bucket/some/key.txt", "")
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+	assert.Equal(t, "hello", string(body))
+	assert.Empty(t, rp.Header.Get("X-Amz-Meta-Some-Name"))
+
+	// head bucket
+	rp = do(t, newRequest(t, http.MethodHead, s.URL+"/bucket", nil))
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+
+	// delete object
+	rp = do(t, newRequest(t, http.MethodDelete, s.URL+"/bucket/some/key.txt", nil))
+	assert.Equal(t, http.StatusNoContent, rp.StatusCode)
+	rp, _ = get(t, s.URL+"/bucket/some/key.txt", "")
+	assert.Equal(t, http.StatusNotFound, rp.StatusCode)
+	rp = do(t, newRequest(t, http.MethodDelete, s.URL+"/bucket/some/key.txt", nil))
+	assert.Equal(t, http.StatusNoContent, rp.StatusCode)
+}
+
+func TestGateway_listObjects(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	s, _, cleanup := newTestGateway(t, newMemAuthor(rng))
+	defer cleanup()
+	keys := []string{"a/1", "a/2", "b/1", "b/c/1", "c", "d e"}
+	for _, key := range keys {
+		rq := newRequest(t, http.MethodPut, s.URL+"/bucket/"+key, strings.NewReader(key))
+		assert.Equal(t, http.StatusOK, do(t, rq).StatusCode)
+	}
+
+	// all keys
+	result := list(t, s.URL+"/bucket?list-type=2")
+	assert.Equal(t, "bucket", result.Name)
+	assert.Equal(t, keys, listedKeys(result))
+	assert.Equal(t, len(keys), result.KeyCount)
+	assert.Equal(t, MaxListKeys, result.MaxKeys)
+	assert.False(t, result.IsTruncated)
+	assert.Empty(t, result.NextContinuationToken)
+	assert.Equal(t, quote(md5Hex([]byte("a/1"))), result.Contents[0].ETag)
+	assert.Equal(t, int64(3), result.Contents[0].Size)
+
+	// prefix & delimiter
+	result = list(t, s.URL+"/bucket?list-type=2&delimiter=/")
+	assert.Equal(t, []string{"c", "d e"}, listedKeys(result))
+	assert.Equal(t, []string{"a/", "b/"}, listedPrefixes(result))
+	assert.Equal(t, 4, result.KeyCount)
+	result = list(t, s.URL+"/bucket?list-type=2&delimiter=/&prefix=b/")
+	assert.Equal(t, []string{"b/1"}, listedKeys(result))
+	assert.Equal(t, []string{"b/c/"}, listedPrefixes(result))
+	assert.Equal(t, "b/", result.Prefix)
+
+	// start after
+	result = list(t, s.URL+"/bucket?list-type=2&start-after=b/1")
+	assert.Equal(t, []string{"b/c/1", "c", "d e"}, listedKeys(result))
+
+	// url encoding
+	result = list(t, s.URL+"/bucket?list-type=2&prefix=d&encoding-type=url")
+	assert.Equal(t, []string{"d+e"}, listedKeys(result))
+
+	// paging
+	for _, delimiter := range []string{"", "/"} {
+		var listed []string
+		token := ""
+		for {
+			result = list(t, s.URL+"/bucket?list-type=2&max-keys=1&delimiter="+delimiter+
+				"&continuation-token="+token)
+			assert.Equal(t, 1, result.KeyCount)
+			listed = append(append(listed, listedKeys(result)...), listedPrefixes(result)...)
+			if !result.IsTruncated {
+				break
+			}
+			token = result.NextContinuationToken
+		}
+		if delimiter == "" {
+			assert.Equal(t, keys, listed)
+		} else {
+			assert.Equal(t, []string{"a/", "b/", "c", "d e"}, listed)
+		}
+	}
+
+	// empty bucket
+	result = list(t, s.URL+"/other-bucket?list-type=2")
+	assert.Empty(t, result.Contents)
+	assert.Equal(t, 0, result.KeyCount)
+}
+
+func TestGateway_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	a := newMemAuthor(rng)
+	s, idx, cleanup := newTestGateway(t, a)
+	defer cleanup()
+	content := api.RandBytes(rng, 1024)
+	rq := newRequest(t, http.MethodPut, s.URL+"/bucket/key", bytes.NewReader(content))
+	assert.Equal(t, http.StatusOK, do(t, rq).StatusCode)
+
+	cases := []struct {
+		method   string
+		path     string
+		header   http.Header
+		body     io.Reader
+		status   int
+		expected *s3Error
+	}{
+		// list buckets
+		{method: http.MethodGet, path: "/", expected: errNotImplemented},
+
+		// bad bucket names
+		{method: http.MethodGet, path: "/b/key", expected: errInvalidBucketName},
+		{method: http.MethodGet, path: "/Bucket/key", expected: errInvalidBucketName},
+		{method: http.MethodGet, path: "/-bucket?list-type=2", expected: errInvalidBucketName},
+
+		// unsupported bucket operations
+		{method: http.MethodGet, path: "/bucket", expected: errNotImplemented},
+		{method: http.MethodPut, path: "/bucket", expected: errNotImplemented},
+
+		// unsupported object operations
+		{method: http.MethodPost, path: "/bucket/key?uploads", expected: errNotImplemented},
+		{method: http.MethodPut, path: "/bucket/key?partNumber=1&uploadId=abc",
+			expected: errNotImplemented},
+		{method: http.MethodGet, path: "/bucket/key?acl", expected: errNotImplemented},
+		{method: http.MethodPut, path: "/bucket/key2",
+			header:   http.Header{"X-Amz-Copy-Source": {"/bucket/key"}},
+			expected: errNotImplemented},
+		{method: http.MethodPost, path: "/bucket/key", expected: errNotImplemented},
+
+		// missing key
+		{method: http.MethodGet, path: "/bucket/missing", expected: errNoSuchKey},
+
+		// bad range
+		{method: http.MethodGet, path: "/bucket/key", header: http.Header{"Range": {"bytes=1024-"}},
+			expected: errInvalidRange},
+
+		// bad content type
+		{method: http.MethodPut, path: "/bucket/key2", body: strings.NewReader("content"),
+			header: http.Header{"Content-Type": {"not a/media type"}}, expected: errInvalidArgument},
+
+		// malformed aws-chunked bodies
+		{method: http.MethodPut, path: "/bucket/key2", body: strings.NewReader("zz\r\n"),
+			header:   http.Header{"Content-Encoding": {"aws-chunked"}},
+			expected: errIncompleteBody},
+		{method: http.MethodPut, path: "/bucket/key2", body: strings.NewReader("5\r\nhelloXX"),
+			header:   http.Header{"Content-Encoding": {"aws-chunked"}},
+			expected: errIncompleteBody},
+
+		// bad list params
+		{method: http.MethodGet, path: "/bucket?list-type=2&max-keys=-1",
+			expected: errInvalidArgument},
+		{method: http.MethodGet, path: "/bucket?list-type=2&max-keys=many",
+			expected: errInvalidArgument},
+		{method: http.MethodGet, path: "/bucket?list-type=2&continuation-token=!",
+			expected: errInvalidArgument},
+		{method: http.MethodGet, path: "/bucket?list-type=2&encoding-type=other",
+			expected: errInvalidArgument},
+	}
+	for i, c := range cases {
+		rq := newRequest(t, c.method, s.URL+c.path, c.body)
+		for name, values := range c.header {
+			rq.Header[name] = values
+		}
+		rp := do(t, rq)
+		assertS3Error(t, c.expected, rp, i)
+	}
+
+	// head errors have no body
+	rp := do(t, newRequest(t, http.MethodHead, s.URL+"/bucket/missing", nil))
+	assert.Equal(t, http.StatusNotFound, rp.StatusCode)
+
+	// author errors
+	a.err = errors.New("some author error")
+	rp = do(t, newRequest(t, http.MethodPut, s.URL+"/bucket/key2", strings.NewReader("content")))
+	assertS3Error(t, errInternal, rp, "upload")
+	_, in := idx.Get("bucket", "key2")
+	assert.False(t, in)
+	rp = do(t, newRequest(t, http.MethodGet, s.URL+"/bucket/key", nil))
+	assertS3Error(t, errInternal, rp, "download")
+
+	// download error after writing some content
+	a.err = nil
+	a.writeSize, a.errAfter = 100, 1
+	rp, body := get(t, s.URL+"/bucket/key", "")
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+	assert.True(t, len(body) < len(content))
+
+	// bad indexed envelope key
+	assert.Nil(t, idx.Put("bucket", "bad-key", &Object{EnvelopeKey: "not hex", Size: 1}))
+	rp = do(t, newRequest(t, http.MethodGet, s.URL+"/bucket/bad-key", nil))
+	assertS3Error(t, errInternal, rp, "bad envelope key")
+
+	// index write errors
+	idx.filepath = filepath.Join(idx.filepath, "missing", IndexFilename)
+	rp = do(t, newRequest(t, http.MethodPut, s.URL+"/bucket/key2", strings.NewReader("content")))
+	assertS3Error(t, errInternal, rp, "put index")
+	rp = do(t, newRequest(t, http.MethodDelete, s.URL+"/bucket/key", nil))
+	assertS3Error(t, errInternal, rp, "delete index")
+}
+
+func TestGateway_cluster(t *testing.T) {
+	template := server.NewDefaultConfig().
+		WithLocalPort(21400).
+		WithLocalMetricsPort(21500).
+		WithLocalProfilerPort(21600)
+	// with only a few librarians, stores may run out of peers to replicate to
+	params := cluster.NewDefaultParameters()
+	c, err := cluster.Start(template, params, zap.NewNop())
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, c.Close()) }()
+	s, idx, cleanup := newTestGateway(t, c.Author)
+	defer cleanup()
+	rng := rand.New(rand.NewSource(0))
+
+	content := api.RandBytes(rng, 512*1024)
+	rq := newRequest(t, http.MethodPut, s.URL+"/bucket/backup/data.bin", bytes.NewReader(content))
+	assert.Equal(t, http.StatusOK, do(t, rq).StatusCode)
+	obj, in := idx.Get("bucket", "backup/data.bin")
+	assert.True(t, in)
+	envKey, err := id.FromString(obj.EnvelopeKey)
+	assert.Nil(t, err)
+
+	// object stored in libri
+	downloaded := new(bytes.Buffer)
+	assert.Nil(t, c.Author.Download(downloaded, envKey))
+	assert.Equal(t, content, downloaded.Bytes())
+
+	rp, body := get(t, s.URL+"/bucket/backup/data.bin", "")
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+	assert.Equal(t, content, body)
+	rp, body = get(t, s.URL+"/bucket/backup/data.bin", "bytes=1000-200999")
+	assert.Equal(t, http.StatusPartialContent, rp.StatusCode)
+	assert.Equal(t, content[1000:201000], body)
+
+	result := list(t, s.URL+"/bucket?list-type=2&prefix=backup/")
+	assert.Equal(t, []string{"backup/data.bin"}, listedKeys(result))
+
+	rq = newRequest(t, http.MethodDelete, s.URL+"/bucket/backup/data.bin", nil)
+	assert.Equal(t, http.StatusNoContent, do(t, rq).StatusCode)
+	rp, _ = get(t, s.URL+"/bucket/backup/data.bin", "")
+	assert.Equal(t, http.StatusNotFound, rp.StatusCode)
+}
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		value      string
+		size       int64
+		start, end int64
+		partial    bool
+		err        error
+	}{
+		{"", 10, 0, 10, false, nil},
+		{"bytes=2-5", 10, 2, 6, true, nil},
+		{"bytes=2-", 10, 2, 10, true, nil},
+		{"bytes=2-20", 10, 2, 10, true, nil},
+		{"bytes=-3", 10, 7, 10, true, nil},
+		{"bytes=-20", 10, 0, 10, true, nil},
+		{" bytes=2-5", 10, 0, 10, false, nil},
+		{"items=2-5", 10, 0, 10, false, nil},
+		{"bytes=2", 10, 0, 10, false, nil},
+		{"bytes=5-2", 10, 0, 10, false, nil},
+		{"bytes=a-5", 10, 0, 10, false, nil},
+		{"bytes=2-b", 10, 0, 10, false, nil},
+		{"bytes=-c", 10, 0, 10, false, nil},
+		{"bytes=0-1,3-4", 10, 0, 10, false, nil},
+		{"bytes=10-", 10, 0, 0, false, errInvalidRange},
+		{"bytes=-0", 10, 0, 0, false, errInvalidRange},
+		{"bytes=0-", 0, 0, 0, false, errInvalidRange},
+		{"bytes=-5", 0, 0, 0, false, errInvalidRange},
+	}
+	for _, c := range cases {
+		start, end, partial, err := parseRange(c.value, c.size)
+		assert.Equal(t, c.err, err, c.value)
+		assert.Equal(t, c.start, start, c.value)
+		assert.Equal(t, c.end, end, c.value)
+		assert.Equal(t, c.partial, partial, c.value)
+	}
+}
+
+func TestCommonPrefixOf(t *testing.T) {
+	assert.Equal(t, "", commonPrefixOf("a/b/c", "", ""))
+	assert.Equal(t, "a/", commonPrefixOf("a/b/c", "", "/"))
+	assert.Equal(t, "a/b/", commonPrefixOf("a/b/c", "a/", "/"))
+	assert.Equal(t, "", commonPrefixOf("a/b/c", "a/b/", "/"))
+	assert.Equal(t, "a--", commonPrefixOf("a--b", "", "--"))
+}
+
+func newTestGateway(t *testing.T, a Author) (*httptest.Server, *Index, func()) {
+	return newTestGatewayWithCreds(t, a, nil)
+}
+
+func newTestGatewayWithCreds(t *testing.T, a Author, creds *Credentials) (
+	*httptest.Server, *Index, func()) {
+	dir, err := ioutil.TempDir("", "test-s3-gateway")
+	assert.Nil(t, err)
+	idx, err := NewIndex(filepath.Join(dir, IndexFilename))
+	assert.Nil(t, err)
+	s := httptest.NewServer(NewGateway(a, idx, creds, zap.NewNop()))
+	return s, idx, func() {
+		s.Close()
+		assert.Nil(t, os.RemoveAll(dir))
+	}
+}
+
+func newRequest(t *testing.T, method, url string, body io.Reader) *http.Request {
+	rq, err := http.NewRequest(method, url, body)
+	assert.Nil(t, err)
+	return rq
+}
+
+func do(t *testing.T, rq *http.Request) *http.Response {
+	rp, err := http.DefaultClient.Do(rq)
+	assert.Nil(t, err)
+	return rp
+}
+
+func get(t *testing.T, url, rangeValue string) (*http.Response, []byte) {
+	rq := newRequest(t, http.MethodGet, url, nil)
+	if rangeValue != "" {
+		rq.Header.Set("Range", rangeValue)
+	}
+	rp := do(t, rq)
+	// short bodies from failed downloads give unexpected EOF errors
+	body, _ := ioutil.ReadAll(rp.Body)
+	assert.Nil(t, rp.Body.Close())
+	return rp, body
+}
+
+func list(t *testing.T, url string) *listBucketResult {
+	rp, body := get(t, url, "")
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+	result := &listBucketResult{}
+	assert.Nil(t, xml.Unmarshal(body, result))
+	return result
+}
+
+func listedKeys(result *listBucketResult) []string {
+	var keys []string
+	for _, obj := range result.Contents {
+		keys = append(keys, obj.Key)
+	}
+	return keys
+}
+
+func listedPrefixes(result *listBucketResult) []string {
+	var prefixes []string
+	for _, prefix := range result.CommonPrefixes {
+		prefixes = append(prefixes, prefix.Prefix)
+	}
+	return prefixes
+}
+
+func assertS3Error(t *testing.T, expected *s3Error, rp *http.Response, info interface{}) {
+	assert.Equal(t, expected.status, rp.StatusCode, info)
+	body, err := ioutil.ReadAll(rp.Body)
+	assert.Nil(t, err, info)
+	assert.Nil(t, rp.Body.Close(), info)
+	errRp := &errorResponse{}
+	assert.Nil(t, xml.Unmarshal(body, errRp), info)
+	assert.Equal(t, expected.code, errRp.Code, info)
+}
+
+func md5Hex(content []byte) string {
+	h := md5.Sum(content) // nolint: gosec
+	return hex.EncodeToString(h[:])
+}
+
+// memAuthor stores uploaded content in memory.
+type memAuthor struct {
+	rng        *rand.Rand
+	uploads    map[string][]byte
+	opts       map[string]*author.UploadOptions
+	writeSize  int
+	errAfter   int
+	err        error
+	downloaded int
+}
+
+func newMemAuthor(rng *rand.Rand) *memAuthor {
+	return &memAuthor{
+		rng:     rng,
+		uploads: make(map[string][]byte),
+		opts:    make(map[string]*author.UploadOptions),
+	}
+}
+
+func (a *memAuthor) Upload(content io.Reader, mediaType string, opts *author.UploadOptions) (
+	*api.Document, id.ID, error) {
+	if a.err != nil {
+		return nil, nil, a.err
+	}
+	uploaded, err := ioutil.ReadAll(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	envKey := id.NewPseudoRandom(a.rng)
+	a.uploads[envKey.String()] = uploaded
+	a.opts[envKey.String()] = opts
+	return nil, envKey, nil
+}
+
+func (a *memAuthor) Download(content io.Writer, envKey id.ID) error {
+	if a.err != nil {
+		return a.err
+	}
+	uploaded := a.uploads[envKey.String()]
+	writeSize := a.writeSize
+	if writeSize == 0 {
+		writeSize = len(uploaded)
+	}
+	a.downloaded = 0
+	for i := 0; i < len(uploaded); i += writeSize {
+		if a.errAfter > 0 && i >= a.errAfter*writeSize {
+			return errors.New("some download error")
+		}
+		end := i + writeSize
+		if end > len(uploaded) {
+			end = len(uploaded)
+		}
+		a.downloaded = end
+		if _, err := content.Write(uploaded[i:end]); err == author.ErrStopDownload {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package s3
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// IndexFilename is the default name of the object index file within the author data dir.
+const IndexFilename = "s3-index.json"
+
+// Object is the index entry for an object stored in libri.
+type Object struct {
+	// EnvelopeKey is the hex key of the object's envelope, which is empty for empty objects
+	// since they have no content to upload.
+	EnvelopeKey string `json:"envelope_key,omitempty"`
+
+	// Size is the number of bytes in the object.
+	Size int64 `json:"size"`
+
+	// ETag is the hex MD5 hash of the object.
+	ETag string `json:"etag"`
+
+	// ContentType is the media type of the object.
+	ContentType string `json:"content_type"`
+
+	// LastModified is when the object was put.
+	LastModified time.Time `json:"last_modified"`
+
+	// Metadata are the user-defined (x-amz-meta-*) headers of the object, keyed by lower-case
+	// name without the prefix.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Index maps the bucket and key names of objects to their entries. Changes are persisted to a
+// local JSON file. It is safe for concurrent use.
+type Index struct {
+	filepath string
+	buckets  map[string]map[string]*Object
+	mu       sync.RWMutex
+}
+
+// NewIndex returns an Index persisted to the given file, reading its existing entries if the file
+// exists.
+func NewIndex(filepath string) (*Index, error) {
+	idx := &Index{
+		filepath: filepath,
+		buckets:  make(map[string]map[string]*Object),
+	}
+	buf, err := ioutil.ReadFile(filepath) // nolint: gosec
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, &idx.buckets); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Get returns the object with the given bucket and key, if it exists.
+func (idx *Index) Get(bucket, key string) (*Object, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	obj, in := idx.buckets[bucket][key]
+	return obj, in
+}
+
+// Put adds or replaces the object with the given bucket and key.
+func (idx *Index) Put(bucket, key string, obj *Object) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	objs, in := idx.buckets[bucket]
+	if !in {
+		objs = make(map[string]*Object)
+		idx.buckets[bucket] = objs
+	}
+	objs[key] = obj
+	return idx.write()
+}
+
+// Delete removes the object with the given bucket and key, if it exists.
+func (idx *Index) Delete(bucket, key string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	objs, in := idx.buckets[bucket]
+	if !in {
+		return nil
+	}
+	if _, in := objs[key]; !in {
+		return nil
+	}
+	delete(objs, key)
+	if len(objs) == 0 {
+		delete(idx.buckets, bucket)
+	}
+	return idx.write()
+}
+
+// Keys returns the sorted keys of the objects in the bucket.
+func (idx *Index) Keys(bucket string) []string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	keys := make([]string, 0, len(idx.buckets[bucket]))
+	for key := range idx.buckets[bucket] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// write writes the index to a temp file, which only the current user can read, and then moves it
+// so a failed write never clobbers the existing index file.
+func (idx *Index) write() error {
+	buf, err := json.Marshal(idx.buckets)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(idx.filepath), "."+filepath.Base(idx.filepath)+".")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // no-op after successful rename
+	if _, err = tmp.Write(buf); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), idx.filepath)
+}
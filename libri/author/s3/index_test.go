@@ -0,0 +1,110 @@
+package s3
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex_ok(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-s3-index")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	indexFile := filepath.Join(dir, IndexFilename)
+
+	idx, err := NewIndex(indexFile)
+	assert.Nil(t, err)
+	_, in := idx.Get("bucket", "key")
+	assert.False(t, in)
+	assert.Empty(t, idx.Keys("bucket"))
+
+	obj1 := &Object{
+		EnvelopeKey:  "abcd",
+		Size:         3,
+		ETag:         "some etag",
+		ContentType:  "text/plain",
+		LastModified: time.Unix(1500000000, 0).UTC(),
+		Metadata:     map[string]string{"name": "value"},
+	}
+	obj2 := &Object{Size: 0, ETag: "other etag", ContentType: "text/plain"}
+	assert.Nil(t, idx.Put("bucket", "b/key", obj1))
+	assert.Nil(t, idx.Put("bucket", "a/key", obj2))
+	assert.Nil(t, idx.Put("other-bucket", "key", obj2))
+
+	obj, in := idx.Get("bucket", "b/key")
+	assert.True(t, in)
+	assert.Equal(t, obj1, obj)
+	assert.Equal(t, []string{"a/key", "b/key"}, idx.Keys("bucket"))
+
+	// index persists across instances
+	idx2, err := NewIndex(indexFile)
+	assert.Nil(t, err)
+	obj, in = idx2.Get("bucket", "b/key")
+	assert.True(t, in)
+	assert.Equal(t, obj1, obj)
+	assert.Equal(t, []string{"a/key", "b/key"}, idx2.Keys("bucket"))
+	assert.Equal(t, []string{"key"}, idx2.Keys("other-bucket"))
+
+	// index file only readable by current user
+	info, err := os.Stat(indexFile)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	assert.Nil(t, idx.Delete("bucket", "a/key"))
+	assert.Nil(t, idx.Delete("bucket", "missing"))
+	assert.Nil(t, idx.Delete("missing-bucket", "key"))
+	assert.Nil(t, idx.Delete("other-bucket", "key"))
+	assert.Equal(t, []string{"b/key"}, idx.Keys("bucket"))
+	assert.Empty(t, idx.Keys("other-bucket"))
+
+	idx3, err := NewIndex(indexFile)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b/key"}, idx3.Keys("bucket"))
+	_, in = idx3.Get("bucket", "a/key")
+	assert.False(t, in)
+	assert.Empty(t, idx3.buckets["other-bucket"])
+
+	// no temp files left behind
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestNewIndex_err(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-s3-index")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+
+	// bad JSON
+	indexFile := filepath.Join(dir, IndexFilename)
+	assert.Nil(t, ioutil.WriteFile(indexFile, []byte("not JSON"), 0600))
+	idx, err := NewIndex(indexFile)
+	assert.NotNil(t, err)
+	assert.Nil(t, idx)
+
+	// index file is a dir
+	idx, err = NewIndex(dir)
+	assert.NotNil(t, err)
+	assert.Nil(t, idx)
+}
+
+func TestIndex_write_err(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-s3-index")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+
+	// index dir doesn't exist
+	idx, err := NewIndex(filepath.Join(dir, "missing", IndexFilename))
+	assert.Nil(t, err)
+	assert.NotNil(t, idx.Put("bucket", "key", &Object{}))
+
+	// index file is a non-empty dir
+	indexFile := filepath.Join(dir, IndexFilename)
+	assert.Nil(t, os.MkdirAll(filepath.Join(indexFile, "some-file"), 0700))
+	idx = &Index{filepath: indexFile, buckets: make(map[string]map[string]*Object)}
+	assert.NotNil(t, idx.Put("bucket", "key", &Object{}))
+}
@@ -1,6 +1,7 @@
 package author
 
 import (
+	"encoding/hex"
 	"os"
 	"path"
 
@@ -31,6 +32,9 @@ const (
 	// LoggerClientID is a client ID.
 	LoggerClientID = "clientId"
 
+	// LoggerClientPubKey is a client public key.
+	LoggerClientPubKey = "clientPubKey"
+
 	// LoggerKeychainFilepath is a keychain filepath.
 	LoggerKeychainFilepath = "keychainFilepath"
 
@@ -72,13 +76,15 @@ func loadOrCreateClientID(logger *zap.Logger, nsl storage.StorerLoader) (ecid.ID
 			return nil, err
 		}
 		logger.Info("loaded exsting client ID", zap.String(LoggerClientID,
-			clientID.String()))
+			clientID.String()), zap.String(LoggerClientPubKey,
+			hex.EncodeToString(clientID.PublicKeyBytes())))
 		return clientID, nil
 	}
 
 	// return new client ID
 	clientID := ecid.NewRandom()
-	logger.Info("created new client ID", zap.String(LoggerClientID, clientID.String()))
+	logger.Info("created new client ID", zap.String(LoggerClientID, clientID.String()),
+		zap.String(LoggerClientPubKey, hex.EncodeToString(clientID.PublicKeyBytes())))
 
 	return clientID, saveClientID(nsl, clientID)
 }
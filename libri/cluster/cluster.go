@@ -0,0 +1,295 @@
+// Package cluster runs small, in-process clusters of librarians on localhost, along with an
+// author connected to them. It is meant for local development and integration tests, where
+// standing up separate processes (or containers) for each librarian is more hassle than it's
+// worth.
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/librarian/server"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultNLibrarians is the default number of librarians in a cluster.
+	DefaultNLibrarians = uint(8)
+
+	// DefaultNSeeds is the default (max) number of librarians every other librarian bootstraps
+	// from.
+	DefaultNSeeds = uint(3)
+
+	// DefaultHealthTimeout is the default max time to wait for all librarians to become healthy.
+	DefaultHealthTimeout = 30 * time.Second
+
+	// AuthorSubdir is the subdirectory of the cluster data dir containing the author's data.
+	AuthorSubdir = "author"
+
+	healthcheckInterval = 250 * time.Millisecond
+	authorNKeys         = 3
+	tmpDataDirPrefix    = "libri-cluster"
+)
+
+var (
+	// ErrNoLibrarians indicates when a cluster is requested without any librarians.
+	ErrNoLibrarians = errors.New("cluster must have at least one librarian")
+
+	// ErrUnhealthy indicates when the librarians did not all become healthy before the health
+	// timeout.
+	ErrUnhealthy = errors.New("librarians failed to become healthy in time")
+)
+
+// Parameters define how a cluster is laid out.
+type Parameters struct {
+	// NLibrarians is the number of librarians in the cluster.
+	NLibrarians uint
+
+	// NSeeds is the (max) number of librarians that all librarians bootstrap from.
+	NSeeds uint
+
+	// DataDir is the directory holding the data dirs for each librarian and the author. If
+	// empty, a temporary directory is created and then removed when the cluster is closed.
+	DataDir string
+
+	// HealthTimeout is the max time to wait for all librarians to become healthy.
+	HealthTimeout time.Duration
+}
+
+// NewDefaultParameters returns a default Parameters instance.
+func NewDefaultParameters() *Parameters {
+	return &Parameters{
+		NLibrarians:   DefaultNLibrarians,
+		NSeeds:        DefaultNSeeds,
+		HealthTimeout: DefaultHealthTimeout,
+	}
+}
+
+// Cluster is a running set of in-process librarians and an author connected to them.
+type Cluster struct {
+	// Librarians are the running librarian servers.
+	Librarians []*server.Librarian
+
+	// Configs are the configurations of each librarian.
+	Configs []*server.Config
+
+	// Author is connected to all librarians in the cluster.
+	Author *lauthor.Author
+
+	params     *Parameters
+	dataDir    string
+	removeData bool
+	stopped    []chan error
+	logger     *zap.Logger
+}
+
+// Start creates and starts a cluster of librarians from the given template config. Librarian i
+// listens on the template's local (metrics, profiler) port plus i and stores its data in a
+// subdirectory of the cluster data dir. Start returns once the librarians and an author
+// connected to them are up and all librarians report as healthy.
+//
+// The template's parameters (routing, introduce, etc.) are shared by all the librarians and
+// should not be modified after starting. Metrics reporting is disabled since in-process
+// librarians would otherwise register duplicate Prometheus collectors.
+func Start(template *server.Config, params *Parameters, logger *zap.Logger) (*Cluster, error) {
+	if params.NLibrarians == 0 {
+		return nil, ErrNoLibrarians
+	}
+	dataDir, removeData := params.DataDir, false
+	if dataDir == "" {
+		var err error
+		if dataDir, err = ioutil.TempDir("", tmpDataDirPrefix); err != nil {
+			return nil, err
+		}
+		removeData = true
+	}
+	c := &Cluster{
+		Librarians: make([]*server.Librarian, 0, params.NLibrarians),
+		Configs:    newConfigs(template, params, dataDir),
+		params:     params,
+		dataDir:    dataDir,
+		removeData: removeData,
+		stopped:    make([]chan error, 0, params.NLibrarians),
+		logger:     logger,
+	}
+
+	// start librarians one at a time, so seeds are up before others try to bootstrap off them
+	up := make(chan *server.Librarian, 1)
+	for _, config := range c.Configs {
+		logger.Info("starting librarian",
+			zap.String("librarian_name", config.PublicName),
+			zap.Stringer("librarian_address", config.PublicAddr),
+		)
+		stopped := make(chan error, 1)
+		go func(config *server.Config) {
+			stopped <- server.Start(logger, config, up)
+		}(config)
+		select {
+		case l := <-up:
+			c.Librarians = append(c.Librarians, l)
+			c.stopped = append(c.stopped, stopped)
+		case err := <-stopped:
+			return nil, c.abort("failed to start librarian", err)
+		}
+	}
+
+	if err := c.startAuthor(template); err != nil {
+		return nil, c.abort("failed to create author", err)
+	}
+	if err := c.waitUntilHealthy(); err != nil {
+		return nil, c.abort("cluster failed to become healthy", err)
+	}
+	logger.Info("cluster is healthy",
+		zap.String("librarian_addresses", fmt.Sprintf("%v", c.Addrs())),
+	)
+	return c, nil
+}
+
+// Addrs returns the public addresses of the librarians in the cluster.
+func (c *Cluster) Addrs() []*net.TCPAddr {
+	addrs := make([]*net.TCPAddr, len(c.Configs))
+	for i, config := range c.Configs {
+		addrs[i] = config.PublicAddr
+	}
+	return addrs
+}
+
+// DataDir returns the directory containing the librarian and author data dirs.
+func (c *Cluster) DataDir() string {
+	return c.dataDir
+}
+
+// Wait blocks until all librarians have stopped serving, e.g., after they receive a SIGTERM.
+func (c *Cluster) Wait() {
+	for _, stopped := range c.stopped {
+		<-stopped
+	}
+	c.stopped = nil
+}
+
+// Close disconnects the author, stops all librarians, and removes the cluster data dir if it
+// was created by Start.
+func (c *Cluster) Close() error {
+	if c.Author != nil {
+		if err := c.Author.Close(); err != nil {
+			return err
+		}
+		c.Author = nil
+	}
+	if err := c.closeLibrarians(); err != nil {
+		return err
+	}
+	if c.removeData {
+		return os.RemoveAll(c.dataDir)
+	}
+	return nil
+}
+
+// abort closes whatever part of the cluster has already started and returns the original error.
+func (c *Cluster) abort(msg string, err error) error {
+	c.logger.Error(msg, zap.Error(err))
+	if err2 := c.Close(); err2 != nil {
+		c.logger.Error("error closing partially started cluster", zap.Error(err2))
+	}
+	return err
+}
+
+func (c *Cluster) closeLibrarians() error {
+	errs := make(chan error, len(c.Librarians))
+	var wg sync.WaitGroup
+	for _, l := range c.Librarians {
+		wg.Add(1)
+		go func(l *server.Librarian) {
+			defer wg.Done()
+			errs <- l.Close()
+		}(l)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	c.Wait()
+	return nil
+}
+
+func (c *Cluster) startAuthor(template *server.Config) error {
+	config := lauthor.NewDefaultConfig().
+		WithLibrarianAddrs(c.Addrs()).
+		WithDataDir(filepath.Join(c.dataDir, AuthorSubdir)).
+		WithDefaultDBDir().
+		WithDefaultKeychainDir().
+		WithLogLevel(template.LogLevel)
+
+	// the author's keys are only used for as long as the cluster is up, so no need to save them
+	authorKeys, selfReaderKeys := keychain.New(authorNKeys), keychain.New(authorNKeys)
+	a, err := lauthor.NewAuthor(config, authorKeys, selfReaderKeys, c.logger)
+	if err != nil {
+		return err
+	}
+	c.Author = a
+	return nil
+}
+
+func (c *Cluster) waitUntilHealthy() error {
+	timeout := time.After(c.params.HealthTimeout)
+	for {
+		if healthy, _ := c.Author.Healthcheck(); healthy {
+			return nil
+		}
+		select {
+		case <-timeout:
+			return ErrUnhealthy
+		case <-time.After(healthcheckInterval):
+		}
+	}
+}
+
+func newConfigs(template *server.Config, params *Parameters, dataDir string) []*server.Config {
+	nSeeds := params.NSeeds
+	if nSeeds == 0 || nSeeds > params.NLibrarians {
+		nSeeds = params.NLibrarians
+	}
+	configs := make([]*server.Config, params.NLibrarians)
+	seedAddrs := make([]*net.TCPAddr, 0, nSeeds)
+	for i := range configs {
+		config := *template
+
+		// copy the parameters modified below so the template is left untouched
+		introParams, replicateParams := *template.Introduce, *template.Replicate
+		config.Introduce, config.Replicate = &introParams, &replicateParams
+
+		configs[i] = config.
+			WithLocalPort(template.LocalPort + i).
+			WithLocalMetricsPort(template.LocalMetricsPort + i).
+			WithLocalProfilerPort(template.LocalProfilerPort + i).
+			WithDefaultPublicAddr().
+			WithDefaultPublicName().
+			WithReportMetrics(false)
+		configs[i].
+			WithDataDir(filepath.Join(dataDir, configs[i].PublicName)).
+			WithDefaultDBDir()
+
+		// since librarians are started in order, seeds only bootstrap from themselves and the
+		// seeds before them, which are all already up; later seeds then introduce themselves
+		// to the earlier ones
+		if uint(len(seedAddrs)) < nSeeds {
+			seedAddrs = append(seedAddrs, configs[i].PublicAddr)
+		}
+		configs[i].WithBootstrapAddrs(seedAddrs[:len(seedAddrs):len(seedAddrs)])
+		if introParams.MinNumIntroductions > uint(len(seedAddrs)) {
+			introParams.MinNumIntroductions = uint(len(seedAddrs))
+		}
+	}
+	return configs
+}
@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drausin/libri/libri/librarian/server"
+	"github.com/drausin/libri/libri/librarian/server/introduce"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestStart_ok(t *testing.T) {
+	template := server.NewDefaultConfig().
+		WithLocalPort(21100).
+		WithLocalMetricsPort(21200).
+		WithLocalProfilerPort(21300)
+	params := NewDefaultParameters()
+	params.NLibrarians = 3
+
+	c, err := Start(template, params, zap.NewNop())
+	assert.Nil(t, err)
+	assert.Len(t, c.Librarians, 3)
+	assert.Len(t, c.Addrs(), 3)
+	assert.NotNil(t, c.Author)
+	healthy, _ := c.Author.Healthcheck()
+	assert.True(t, healthy)
+
+	dataDir := c.DataDir()
+	_, err = os.Stat(dataDir)
+	assert.Nil(t, err)
+
+	err = c.Close()
+	assert.Nil(t, err)
+
+	// temporary data dir should have been removed
+	_, err = os.Stat(dataDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestStart_err(t *testing.T) {
+	params := NewDefaultParameters()
+	params.NLibrarians = 0
+	c, err := Start(server.NewDefaultConfig(), params, zap.NewNop())
+	assert.Equal(t, ErrNoLibrarians, err)
+	assert.Nil(t, c)
+}
+
+func TestNewConfigs(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "test-cluster-data-dir")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dataDir)) }()
+	template := server.NewDefaultConfig()
+
+	params := &Parameters{NLibrarians: 5, NSeeds: 3}
+	configs := newConfigs(template, params, dataDir)
+	assert.Len(t, configs, 5)
+	for i, config := range configs {
+		assert.Equal(t, template.LocalPort+i, config.LocalPort)
+		assert.Equal(t, template.LocalMetricsPort+i, config.LocalMetricsPort)
+		assert.Equal(t, template.LocalProfilerPort+i, config.LocalProfilerPort)
+		assert.Equal(t, config.LocalPort, config.PublicAddr.Port)
+		assert.Equal(t, filepath.Join(dataDir, config.PublicName), config.DataDir)
+		assert.False(t, config.ReportMetrics)
+		assert.Equal(t, configs[0].PublicAddr, config.BootstrapAddrs[0])
+	}
+
+	// seeds bootstrap off themselves and the seeds started before them
+	expectedNBootstraps := []int{1, 2, 3, 3, 3}
+	expectedMinIntros := []uint{1, 2, 3, 3, 3}
+	for i, config := range configs {
+		assert.Len(t, config.BootstrapAddrs, expectedNBootstraps[i], i)
+		assert.Equal(t, expectedMinIntros[i], config.Introduce.MinNumIntroductions, i)
+	}
+
+	// zero seeds means every librarian is a seed
+	params.NSeeds = 0
+	configs = newConfigs(template, params, dataDir)
+	assert.Len(t, configs[4].BootstrapAddrs, 5)
+
+	// template should be unchanged
+	assert.True(t, template.ReportMetrics)
+	assert.True(t, template.Replicate.ReportMetrics)
+	assert.Equal(t, introduce.DefaultMinNumIntroductions, template.Introduce.MinNumIntroductions)
+}
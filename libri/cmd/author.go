@@ -25,6 +25,9 @@ const (
 	passphraseVar        = "passphrase"
 	authorLibrariansFlag = "authorLibrarians"
 	timeoutFlag          = "timeout"
+	contactsFileFlag     = "contactsFile"
+	groupsFileFlag       = "groupsFile"
+	daemonSocketFlag     = "daemonSocket"
 )
 
 // authorCmd represents the author command
@@ -38,9 +41,15 @@ func init() {
 
 	authorCmd.PersistentFlags().StringP(keychainDirFlag, "k", "", "local keychains directory")
 	authorCmd.PersistentFlags().StringSliceP(authorLibrariansFlag, "a", nil,
-		"comma-separated addresses (IPv4:Port) of librarian(s)")
+		"comma-separated addresses (host:port) of librarian(s)")
 	authorCmd.PersistentFlags().Int(timeoutFlag, 5,
 		"timeout (seconds) for requests to librarians")
+	authorCmd.PersistentFlags().String(contactsFileFlag, "",
+		"contact book file of reader public keys (default contacts.json in the data dir)")
+	authorCmd.PersistentFlags().String(groupsFileFlag, "",
+		"file of named reader public key groups (default groups.json in the data dir)")
+	authorCmd.PersistentFlags().String(daemonSocketFlag, "",
+		"socket file of the author daemon (default author.sock in the data dir)")
 
 	// bind viper flags
 	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
@@ -85,6 +94,10 @@ func (*authorConfigGetterImpl) get(librariansFlag string) (*author.Config, *zap.
 	config := author.NewDefaultConfig().
 		WithDataDir(viper.GetString(dataDirFlag)).
 		WithDefaultDBDir(). // depends on DataDir
+		WithContactsFile(viper.GetString(contactsFileFlag)).
+		WithGroupsFile(viper.GetString(groupsFileFlag)).
+		WithFileParallelism(viper.GetUint32(parallelismFlag)).
+		WithCertificateFile(viper.GetString(certificateFileFlag)).
 		WithLogLevel(getLogLevel())
 	timeout := time.Duration(viper.GetInt(timeoutFlag) * 1e9)
 	config.Publish.PutTimeout = timeout
@@ -102,6 +115,7 @@ func (*authorConfigGetterImpl) get(librariansFlag string) (*author.Config, *zap.
 	logger.Info("author configuration",
 		zap.String(librariansFlag, fmt.Sprintf("%v", config.LibrarianAddrs)),
 		zap.String(dataDirFlag, config.DataDir),
+		zap.String(certificateFileFlag, config.CertificateFile),
 		zap.Stringer(logLevelFlag, config.LogLevel),
 		zap.Int(timeoutFlag, int(timeout.Seconds())),
 	)
@@ -122,14 +136,16 @@ func (*terminalPassphraseGetter) get() (string, error) {
 // authorUploader just wraps an *author.Author Upload call that is hard to mock b/c *author.Author
 // is a struct rather than an interface
 type authorUploader interface {
-	upload(author *lauthor.Author, content io.Reader, mediaType string) (id.ID, error)
+	upload(author *lauthor.Author, content io.Reader, mediaType string,
+		opts *lauthor.UploadOptions) (id.ID, error)
 }
 
 type authorUploaderImpl struct{}
 
-func (*authorUploaderImpl) upload(author *lauthor.Author, content io.Reader, mediaType string) (
-	id.ID, error) {
-	_, envelopeKey, err := author.Upload(content, mediaType)
+func (*authorUploaderImpl) upload(
+	author *lauthor.Author, content io.Reader, mediaType string, opts *lauthor.UploadOptions,
+) (id.ID, error) {
+	_, envelopeKey, err := author.Upload(content, mediaType, opts)
 	return envelopeKey, err
 }
 
@@ -145,3 +161,66 @@ func (*authorDownloaderImpl) download(
 ) error {
 	return author.Download(content, envelopeKey)
 }
+
+// authorVersionUploader just wraps an *author.Author UploadVersion call for the same reason as
+// authorUploader
+type authorVersionUploader interface {
+	uploadVersion(author *lauthor.Author, content io.Reader, mediaType string,
+		lineage *lauthor.Lineage, opts *lauthor.UploadOptions) (id.ID, error)
+}
+
+type authorVersionUploaderImpl struct{}
+
+func (*authorVersionUploaderImpl) uploadVersion(
+	author *lauthor.Author,
+	content io.Reader,
+	mediaType string,
+	lineage *lauthor.Lineage,
+	opts *lauthor.UploadOptions,
+) (id.ID, error) {
+	_, envelopeKey, err := author.UploadVersion(content, mediaType, lineage, opts)
+	return envelopeKey, err
+}
+
+// authorVersionDownloader just wraps an *author.Author DownloadVersion call for the same reason
+// as authorUploader
+type authorVersionDownloader interface {
+	downloadVersion(author *lauthor.Author, content io.Writer, envelopeKey id.ID, number int) error
+}
+
+type authorVersionDownloaderImpl struct{}
+
+func (*authorVersionDownloaderImpl) downloadVersion(
+	author *lauthor.Author, content io.Writer, envelopeKey id.ID, number int,
+) error {
+	_, err := author.DownloadVersion(content, envelopeKey, number)
+	return err
+}
+
+// authorDirUploader just wraps an *author.Author UploadDir call for the same reason as
+// authorUploader
+type authorDirUploader interface {
+	uploadDir(author *lauthor.Author, dirpath string) (id.ID, error)
+}
+
+type authorDirUploaderImpl struct{}
+
+func (*authorDirUploaderImpl) uploadDir(author *lauthor.Author, dirpath string) (id.ID, error) {
+	_, envelopeKey, _, err := author.UploadDir(dirpath)
+	return envelopeKey, err
+}
+
+// authorDirDownloader just wraps an *author.Author DownloadDir call for the same reason as
+// authorUploader
+type authorDirDownloader interface {
+	downloadDir(author *lauthor.Author, dirpath string, envelopeKey id.ID) error
+}
+
+type authorDirDownloaderImpl struct{}
+
+func (*authorDirDownloaderImpl) downloadDir(
+	author *lauthor.Author, dirpath string, envelopeKey id.ID,
+) error {
+	_, _, err := author.DownloadDir(dirpath, envelopeKey)
+	return err
+}
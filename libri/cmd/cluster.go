@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/drausin/libri/libri/cluster"
+	"github.com/drausin/libri/libri/common/errors"
+	clogging "github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/librarian/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	nLibrariansFlag        = "nLibrarians"
+	nSeedsFlag             = "nSeeds"
+	clusterPortFlag        = "clusterPort"
+	clusterMetricsPortFlag = "clusterMetricsPort"
+	healthTimeoutFlag      = "healthTimeout"
+)
+
+// clusterCmd represents the dev cluster command
+var clusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "run a local cluster of librarians in a single process",
+	Long: `run a local cluster of librarians in a single process
+
+Librarians listen on consecutive localhost ports starting at --clusterPort. Once they are all
+healthy, their addresses are printed so other libri commands (e.g., libri test io) can point at
+them. The cluster runs until it receives a SIGINT or SIGTERM. If no --dataDir is given, all
+cluster data is kept in a temporary directory and removed on exit.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		template, params, logger := getClusterConfig()
+		c, err := cluster.Start(template, params, logger)
+		if err != nil {
+			return err
+		}
+		if err := writeClusterAddrs(os.Stdout, c); err != nil {
+			return err
+		}
+
+		// each librarian stops itself on SIGINT/SIGTERM
+		c.Wait()
+		return c.Close()
+	},
+}
+
+func init() {
+	devCmd.AddCommand(clusterCmd)
+
+	clusterCmd.Flags().UintP(nLibrariansFlag, "n", cluster.DefaultNLibrarians,
+		"number of librarians in the cluster")
+	clusterCmd.Flags().Uint(nSeedsFlag, cluster.DefaultNSeeds,
+		"number of librarians all others bootstrap from")
+	clusterCmd.Flags().IntP(clusterPortFlag, "p", server.DefaultPort,
+		"local port of the first librarian")
+	clusterCmd.Flags().Int(clusterMetricsPortFlag, server.DefaultMetricsPort,
+		"local metrics port of the first librarian")
+	clusterCmd.Flags().Duration(healthTimeoutFlag, cluster.DefaultHealthTimeout,
+		"max time to wait for the librarians to become healthy")
+
+	// bind viper flags
+	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
+	viper.AutomaticEnv()             // read in environment variables that match
+	errors.MaybePanic(viper.BindPFlags(clusterCmd.Flags()))
+}
+
+func getClusterConfig() (*server.Config, *cluster.Parameters, *zap.Logger) {
+	logLevel := getLogLevel()
+	template := server.NewDefaultConfig().
+		WithLocalPort(viper.GetInt(clusterPortFlag)).
+		WithLocalMetricsPort(viper.GetInt(clusterMetricsPortFlag)).
+		WithLogLevel(logLevel)
+
+	params := cluster.NewDefaultParameters()
+	params.NLibrarians = uint(viper.GetInt(nLibrariansFlag))
+	params.NSeeds = uint(viper.GetInt(nSeedsFlag))
+	params.DataDir = viper.GetString(dataDirFlag)
+	params.HealthTimeout = viper.GetDuration(healthTimeoutFlag)
+
+	logger := clogging.NewDevLogger(logLevel)
+	logger.Info("cluster configuration",
+		zap.Uint(nLibrariansFlag, params.NLibrarians),
+		zap.Uint(nSeedsFlag, params.NSeeds),
+		zap.Int(clusterPortFlag, template.LocalPort),
+		zap.String(dataDirFlag, params.DataDir),
+		zap.Stringer(logLevelFlag, logLevel),
+	)
+	return template, params, logger
+}
+
+func writeClusterAddrs(w io.Writer, c *cluster.Cluster) error {
+	addrStrs := make([]string, len(c.Configs))
+	for i, addr := range c.Addrs() {
+		addrStrs[i] = addr.String()
+	}
+	_, err := fmt.Fprintf(w, "librarian addresses: %s\n", strings.Join(addrStrs, ","))
+	return err
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/cluster"
+	"github.com/drausin/libri/libri/librarian/server"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetClusterConfig(t *testing.T) {
+	viper.Set(nLibrariansFlag, 5)
+	viper.Set(nSeedsFlag, 2)
+	viper.Set(clusterPortFlag, 1234)
+	viper.Set(clusterMetricsPortFlag, 1235)
+	viper.Set(healthTimeoutFlag, "10s")
+	viper.Set(dataDirFlag, "some/data/dir")
+	viper.Set(logLevelFlag, "debug")
+
+	template, params, logger := getClusterConfig()
+	assert.NotNil(t, logger)
+	assert.Equal(t, 1234, template.LocalPort)
+	assert.Equal(t, 1235, template.LocalMetricsPort)
+	assert.Equal(t, "debug", template.LogLevel.String())
+	assert.Equal(t, uint(5), params.NLibrarians)
+	assert.Equal(t, uint(2), params.NSeeds)
+	assert.Equal(t, "some/data/dir", params.DataDir)
+	assert.Equal(t, 10*time.Second, params.HealthTimeout)
+}
+
+func TestWriteClusterAddrs(t *testing.T) {
+	c := &cluster.Cluster{
+		Configs: []*server.Config{
+			server.NewDefaultConfig().WithLocalPort(1234).WithDefaultPublicAddr(),
+			server.NewDefaultConfig().WithLocalPort(1235).WithDefaultPublicAddr(),
+		},
+	}
+	w := new(bytes.Buffer)
+	err := writeClusterAddrs(w, c)
+	assert.Nil(t, err)
+	assert.Equal(t, "librarian addresses: 127.0.0.1:1234,127.0.0.1:1235\n", w.String())
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	errMissingContactNames = errors.New("missing one or more contact names")
+	errAddContactArgs      = errors.New("expected contact name and reader key")
+)
+
+// contactsCmd represents the author contacts command
+var contactsCmd = &cobra.Command{
+	Use:   "contacts",
+	Short: "manage the contact book of reader keys documents are shared with",
+	Long: `Manage the contact book mapping contact names to the reader public keys documents are
+shared with. Reader keys are exchanged in an encoded form: give yours (from "contacts
+export-mine") to the people who will share documents with you, and add theirs to share documents
+with them.`,
+}
+
+// contactsListCmd represents the author contacts list command
+var contactsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "print the contact names and reader keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newContactsEditor().list(os.Stdout)
+	},
+}
+
+// contactsAddCmd represents the author contacts add command
+var contactsAddCmd = &cobra.Command{
+	Use:   "add NAME READER_KEY",
+	Short: "add a contact to the contact book, creating it if necessary",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newContactsEditor().add(args)
+	},
+}
+
+// contactsRemoveCmd represents the author contacts remove command
+var contactsRemoveCmd = &cobra.Command{
+	Use:   "remove NAME...",
+	Short: "remove contacts from the contact book",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newContactsEditor().remove(args)
+	},
+}
+
+// contactsExportMineCmd represents the author contacts export-mine command
+var contactsExportMineCmd = &cobra.Command{
+	Use:   "export-mine",
+	Short: "print one of your own reader keys for others to share documents with you",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newReaderKeyExporter().export(os.Stdout)
+	},
+}
+
+func init() {
+	authorCmd.AddCommand(contactsCmd)
+	contactsCmd.AddCommand(contactsListCmd)
+	contactsCmd.AddCommand(contactsAddCmd)
+	contactsCmd.AddCommand(contactsRemoveCmd)
+	contactsCmd.AddCommand(contactsExportMineCmd)
+}
+
+type contactsEditor struct {
+	filepath string
+}
+
+func newContactsEditor() *contactsEditor {
+	contactsFile := lauthor.NewDefaultConfig().
+		WithDataDir(viper.GetString(dataDirFlag)).
+		WithContactsFile(viper.GetString(contactsFileFlag)). // depends on DataDir
+		ContactsFile
+	return &contactsEditor{filepath: contactsFile}
+}
+
+func (e *contactsEditor) list(w io.Writer) error {
+	c, err := e.read(true)
+	if err != nil {
+		return err
+	}
+	for _, name := range c.Names() {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", name, c.ReaderKeys[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *contactsEditor) add(args []string) error {
+	if len(args) != 2 {
+		return errAddContactArgs
+	}
+	readerPub, err := lauthor.DecodeReaderKey(args[1])
+	if err != nil {
+		return err
+	}
+	c, err := e.read(true)
+	if err != nil {
+		return err
+	}
+	if err := c.Add(args[0], readerPub); err != nil {
+		return err
+	}
+	return lauthor.WriteContacts(e.filepath, c)
+}
+
+func (e *contactsEditor) remove(names []string) error {
+	if len(names) == 0 {
+		return errMissingContactNames
+	}
+	c, err := e.read(false)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if !c.Remove(name) {
+			return fmt.Errorf("%s: %s", lauthor.ErrContactNotFound, name)
+		}
+	}
+	return lauthor.WriteContacts(e.filepath, c)
+}
+
+// read reads the contact book, returning an empty one if the file doesn't exist and
+// emptyIfMissing is true.
+func (e *contactsEditor) read(emptyIfMissing bool) (*lauthor.Contacts, error) {
+	c, err := lauthor.ReadContacts(e.filepath)
+	if os.IsNotExist(err) && emptyIfMissing {
+		return lauthor.NewContacts(), nil
+	}
+	return c, err
+}
+
+type readerKeyExporter struct {
+	kc keychainsGetter
+}
+
+func newReaderKeyExporter() *readerKeyExporter {
+	return &readerKeyExporter{
+		kc: &keychainsGetterImpl{
+			pg: &terminalPassphraseGetter{},
+		},
+	}
+}
+
+// export prints the encoded public key of a key from the self reader keychain, which the author
+// can decrypt envelopes shared with.
+func (x *readerKeyExporter) export(w io.Writer) error {
+	_, selfReaderKeys, err := x.kc.get()
+	if err != nil {
+		return err
+	}
+	readerKey, err := selfReaderKeys.Sample()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, lauthor.EncodeReaderKey(&readerKey.Key().PublicKey))
+	return err
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContactsCmds_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "contacts-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	viper.Set(dataDirFlag, dir)
+	defer viper.Set(dataDirFlag, "")
+
+	alice, bob := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	aliceKey := lauthor.EncodeReaderKey(&alice.Key().PublicKey)
+	bobKey := lauthor.EncodeReaderKey(&bob.Key().PublicKey)
+
+	// listing missing contact book prints nothing
+	buf := new(bytes.Buffer)
+	assert.Nil(t, newContactsEditor().list(buf))
+	assert.Empty(t, buf.String())
+
+	err = contactsAddCmd.RunE(contactsAddCmd, []string{"alice", aliceKey})
+	assert.Nil(t, err)
+	err = contactsAddCmd.RunE(contactsAddCmd, []string{"bob", bobKey})
+	assert.Nil(t, err)
+	err = contactsRemoveCmd.RunE(contactsRemoveCmd, []string{"alice"})
+	assert.Nil(t, err)
+
+	// contact book defaults to file in data dir
+	c, err := lauthor.ReadContacts(filepath.Join(dir, lauthor.ContactsFilename))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bob"}, c.Names())
+
+	buf = new(bytes.Buffer)
+	assert.Nil(t, newContactsEditor().list(buf))
+	assert.Equal(t, "bob\t"+bobKey+"\n", buf.String())
+
+	// contacts file flag overrides default
+	fp := filepath.Join(dir, "other-contacts.json")
+	viper.Set(contactsFileFlag, fp)
+	defer viper.Set(contactsFileFlag, "")
+	err = contactsAddCmd.RunE(contactsAddCmd, []string{"alice", aliceKey})
+	assert.Nil(t, err)
+	c, err = lauthor.ReadContacts(fp)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"alice"}, c.Names())
+}
+
+func TestContactsCmds_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "contacts-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	viper.Set(dataDirFlag, dir)
+	defer viper.Set(dataDirFlag, "")
+	aliceKey := lauthor.EncodeReaderKey(&ecid.NewPseudoRandom(rng).Key().PublicKey)
+
+	// wrong number of args
+	err = contactsAddCmd.RunE(contactsAddCmd, []string{"alice"})
+	assert.Equal(t, errAddContactArgs, err)
+	err = contactsRemoveCmd.RunE(contactsRemoveCmd, []string{})
+	assert.Equal(t, errMissingContactNames, err)
+
+	// bad reader key
+	err = contactsAddCmd.RunE(contactsAddCmd, []string{"alice", "not a key"})
+	assert.NotNil(t, err)
+
+	// can't remove from missing contact book
+	err = contactsRemoveCmd.RunE(contactsRemoveCmd, []string{"alice"})
+	assert.True(t, os.IsNotExist(err))
+
+	// can't add existing or remove missing contact
+	err = contactsAddCmd.RunE(contactsAddCmd, []string{"alice", aliceKey})
+	assert.Nil(t, err)
+	err = contactsAddCmd.RunE(contactsAddCmd, []string{"alice", aliceKey})
+	assert.NotNil(t, err)
+	err = contactsRemoveCmd.RunE(contactsRemoveCmd, []string{"bob"})
+	assert.NotNil(t, err)
+
+	// bad contact book file
+	fp := filepath.Join(dir, lauthor.ContactsFilename)
+	assert.Nil(t, ioutil.WriteFile(fp, []byte("not json"), 0600))
+	assert.NotNil(t, newContactsEditor().list(new(bytes.Buffer)))
+	err = contactsAddCmd.RunE(contactsAddCmd, []string{"bob", aliceKey})
+	assert.NotNil(t, err)
+}
+
+func TestReaderKeyExporter_export(t *testing.T) {
+	selfReaderKeys := keychain.New(1)
+	x := &readerKeyExporter{
+		kc: &fixedKeychainsGetter{selfReaderKeys: selfReaderKeys},
+	}
+	buf := new(bytes.Buffer)
+	assert.Nil(t, x.export(buf))
+	readerPub, err := lauthor.DecodeReaderKey(strings.TrimSpace(buf.String()))
+	assert.Nil(t, err)
+	_, in := selfReaderKeys.Get(ecid.ToPublicKeyBytes(readerPub))
+	assert.True(t, in)
+
+	// keychains get error
+	x = &readerKeyExporter{
+		kc: &fixedKeychainsGetter{err: errors.New("some get error")},
+	}
+	assert.NotNil(t, x.export(new(bytes.Buffer)))
+
+	// sample error
+	x = &readerKeyExporter{
+		kc: &fixedKeychainsGetter{selfReaderKeys: keychain.New(0)},
+	}
+	assert.NotNil(t, x.export(new(bytes.Buffer)))
+}
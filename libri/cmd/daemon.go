@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/daemon"
+	"github.com/drausin/libri/libri/author/keychain"
+	"github.com/drausin/libri/libri/common/id"
+	clogging "github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	daemonDialTimeout = 1 * time.Second
+	logDaemonSocket   = "daemon_socket"
+)
+
+// daemonCmd represents the author daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "run an author daemon that keeps the keychains unlocked",
+	Long: `Run an author daemon that unlocks the keychains and opens the local DB once and then
+serves author operations over a local Unix socket (author.sock in the data dir by default) that
+only the user running the daemon can connect to.
+
+While the daemon is running, the other author commands that use the keychains (upload, download,
+share, info, history, pointer, and s3gateway) use it instead of asking for the keychains
+passphrase, so they use the daemon's librarians and other settings. The daemon reads and writes
+the local files of directory uploads and downloads itself, so it must be able to access them.
+Stop the daemon with SIGINT or SIGTERM.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newAuthorDaemonRunner().run()
+	},
+}
+
+func init() {
+	authorCmd.AddCommand(daemonCmd)
+}
+
+type authorDaemonRunner interface {
+	run() error
+}
+
+func newAuthorDaemonRunner() authorDaemonRunner {
+	return &authorDaemonRunnerImpl{
+		ag: newAuthorGetter(),
+		kc: &keychainsGetterImpl{
+			pg: &terminalPassphraseGetter{},
+		},
+	}
+}
+
+type authorDaemonRunnerImpl struct {
+	ag authorGetter
+	kc keychainsGetter
+}
+
+func (r *authorDaemonRunnerImpl) run() error {
+	// fail before asking for the passphrase, since the running daemon holds the lock on the DB
+	if dc := getDaemonClient(); dc != nil {
+		_ = dc.Close()
+		return daemon.ErrDaemonRunning
+	}
+	authorKeys, selfReaderKeys, err := r.kc.get()
+	if err != nil {
+		return err
+	}
+	author, logger, err := r.ag.get(authorKeys, selfReaderKeys)
+	if err != nil {
+		return err
+	}
+	stop := make(chan os.Signal, 3)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+	if err = serveDaemon(author, getDaemonSocketFile(), logger, stop); err != nil {
+		_ = author.Close()
+		return err
+	}
+	return author.Close()
+}
+
+// serveDaemon serves the author's operations on the daemon socket until receiving a stop signal.
+func serveDaemon(
+	author daemon.Author, socketFile string, logger *zap.Logger, stop chan os.Signal,
+) error {
+	lis, err := daemon.Listen(socketFile)
+	if err != nil {
+		logger.Error("failed to listen on daemon socket", zap.String(logDaemonSocket, socketFile),
+			zap.Error(err))
+		return err
+	}
+	s := daemon.NewServer(author)
+	go func() {
+		<-stop
+		logger.Info("gracefully stopping author daemon")
+		s.Stop()
+	}()
+	logger.Info("serving author daemon", zap.String(logDaemonSocket, socketFile))
+	return s.Serve(lis)
+}
+
+// getDaemonSocketFile returns the daemon socket file given by the flag or the default one in the
+// data dir.
+func getDaemonSocketFile() string {
+	return lauthor.NewDefaultConfig().
+		WithDataDir(viper.GetString(dataDirFlag)).
+		WithDaemonSocketFile(viper.GetString(daemonSocketFlag)). // depends on DataDir
+		DaemonSocketFile
+}
+
+// getDaemonClient returns a client of the author daemon listening on the daemon socket or nil if
+// no daemon is running.
+func getDaemonClient() *daemon.Client {
+	c, err := daemon.Dial(getDaemonSocketFile(), daemonDialTimeout)
+	if err != nil {
+		return nil
+	}
+	return c
+}
+
+// closeDaemonClient closes the daemon client after the single request each command makes with it,
+// returning the request error if there was one.
+func closeDaemonClient(client *daemon.Client, err error) error {
+	if closeErr := client.Close(); err == nil {
+		return closeErr
+	}
+	return err
+}
+
+// daemonKeychainsGetter skips unlocking the keychains since the daemon already has.
+type daemonKeychainsGetter struct{}
+
+func (*daemonKeychainsGetter) get() (keychain.GetterSampler, keychain.GetterSampler, error) {
+	return nil, nil, nil
+}
+
+// daemonAuthorGetter returns a nil author, which the daemon wrappers below ignore, and a logger.
+type daemonAuthorGetter struct{}
+
+func (*daemonAuthorGetter) get(authorKeys, selfReaderKeys keychain.GetterSampler) (
+	*lauthor.Author, *zap.Logger, error) {
+	logger := clogging.NewDevLogger(getLogLevel())
+	logger.Info("using author daemon", zap.String(logDaemonSocket, getDaemonSocketFile()))
+	return nil, logger, nil
+}
+
+// daemonUploader uploads via the daemon instead of the given author.
+type daemonUploader struct {
+	client *daemon.Client
+}
+
+func (u *daemonUploader) upload(
+	_ *lauthor.Author, content io.Reader, mediaType string, opts *lauthor.UploadOptions,
+) (id.ID, error) {
+	envKey, err := u.client.Upload(content, mediaType, opts)
+	if err = closeDaemonClient(u.client, err); err != nil {
+		return nil, err
+	}
+	return envKey, nil
+}
+
+// daemonDownloader downloads via the daemon instead of the given author.
+type daemonDownloader struct {
+	client *daemon.Client
+}
+
+func (d *daemonDownloader) download(_ *lauthor.Author, content io.Writer, envelopeKey id.ID) error {
+	return closeDaemonClient(d.client, d.client.Download(content, envelopeKey))
+}
+
+// daemonSharer shares via the daemon instead of the given author.
+type daemonSharer struct {
+	client *daemon.Client
+}
+
+func (s *daemonSharer) share(
+	_ *lauthor.Author, envelopeKey id.ID, readerPub *ecdsa.PublicKey,
+) (id.ID, error) {
+	sharedEnvKey, err := s.client.Share(envelopeKey, readerPub)
+	if err = closeDaemonClient(s.client, err); err != nil {
+		return nil, err
+	}
+	return sharedEnvKey, nil
+}
+
+// daemonInfoGetter gets document info via the daemon instead of the given author.
+type daemonInfoGetter struct {
+	client *daemon.Client
+}
+
+func (g *daemonInfoGetter) info(_ *lauthor.Author, envelopeKey id.ID) (
+	*api.EntryMetadata, error) {
+	metadata, _, err := g.client.Info(envelopeKey)
+	if err = closeDaemonClient(g.client, err); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// daemonVersionUploader uploads versions via the daemon instead of the given author.
+type daemonVersionUploader struct {
+	client *daemon.Client
+}
+
+func (u *daemonVersionUploader) uploadVersion(
+	_ *lauthor.Author,
+	content io.Reader,
+	mediaType string,
+	lineage *lauthor.Lineage,
+	opts *lauthor.UploadOptions,
+) (id.ID, error) {
+	envKey, err := u.client.UploadVersion(content, mediaType, lineage, opts)
+	if err = closeDaemonClient(u.client, err); err != nil {
+		return nil, err
+	}
+	return envKey, nil
+}
+
+// daemonVersionDownloader downloads versions via the daemon instead of the given author.
+type daemonVersionDownloader struct {
+	client *daemon.Client
+}
+
+func (d *daemonVersionDownloader) downloadVersion(
+	_ *lauthor.Author, content io.Writer, envelopeKey id.ID, number int,
+) error {
+	return closeDaemonClient(d.client, d.client.DownloadVersion(content, envelopeKey, number))
+}
+
+// daemonDirUploader uploads directories via the daemon instead of the given author.
+type daemonDirUploader struct {
+	client *daemon.Client
+}
+
+func (u *daemonDirUploader) uploadDir(_ *lauthor.Author, dirpath string) (id.ID, error) {
+	envKey, err := u.client.UploadDir(dirpath)
+	if err = closeDaemonClient(u.client, err); err != nil {
+		return nil, err
+	}
+	return envKey, nil
+}
+
+// daemonDirDownloader downloads directories via the daemon instead of the given author.
+type daemonDirDownloader struct {
+	client *daemon.Client
+}
+
+func (d *daemonDirDownloader) downloadDir(
+	_ *lauthor.Author, dirpath string, envelopeKey id.ID,
+) error {
+	_, err := d.client.DownloadDir(dirpath, envelopeKey)
+	return closeDaemonClient(d.client, err)
+}
+
+// daemonHistorian gets document histories via the daemon instead of the given author.
+type daemonHistorian struct {
+	client *daemon.Client
+}
+
+func (h *daemonHistorian) history(_ *lauthor.Author, envelopeKey id.ID) (
+	[]*lauthor.Version, error) {
+	versions, err := h.client.History(envelopeKey)
+	if err = closeDaemonClient(h.client, err); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// daemonPointer publishes and resolves pointers via the daemon instead of the given author.
+type daemonPointer struct {
+	client *daemon.Client
+}
+
+func (p *daemonPointer) publish(_ *lauthor.Author, name string, envelopeKey id.ID) (
+	id.ID, error) {
+	pointerKey, err := p.client.Publish(name, envelopeKey)
+	if err = closeDaemonClient(p.client, err); err != nil {
+		return nil, err
+	}
+	return pointerKey, nil
+}
+
+func (p *daemonPointer) resolve(_ *lauthor.Author, name string) (*api.Pointer, error) {
+	pointer, err := p.client.Resolve(name)
+	if err = closeDaemonClient(p.client, err); err != nil {
+		return nil, err
+	}
+	return pointer, nil
+}
+
+func (p *daemonPointer) resolveKey(_ *lauthor.Author, pointerKey id.ID) (*api.Pointer, error) {
+	pointer, err := p.client.ResolveKey(pointerKey)
+	if err = closeDaemonClient(p.client, err); err != nil {
+		return nil, err
+	}
+	return pointer, nil
+}
+
+// daemonGroupSharer shares with groups via the daemon instead of the given author.
+type daemonGroupSharer struct {
+	client *daemon.Client
+}
+
+func (s *daemonGroupSharer) shareWithGroup(
+	_ *lauthor.Author, envelopeKey id.ID, group *lauthor.Group,
+) (*lauthor.GroupShareReport, error) {
+	report, err := s.client.ShareWithGroup(envelopeKey, group)
+	if err = closeDaemonClient(s.client, err); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// daemonS3Author uploads and downloads the S3 gateway's objects via the daemon, which unlike the
+// wrappers above keeps its client open for all of the gateway's requests.
+type daemonS3Author struct {
+	client *daemon.Client
+}
+
+func (a *daemonS3Author) Upload(content io.Reader, mediaType string, opts *lauthor.UploadOptions) (
+	*api.Document, id.ID, error) {
+	envKey, err := a.client.Upload(content, mediaType, opts)
+	return nil, envKey, err
+}
+
+func (a *daemonS3Author) Download(content io.Writer, envKey id.ID) error {
+	return a.client.Download(content, envKey)
+}
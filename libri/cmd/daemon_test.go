@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/daemon"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorDaemonRunner_run_err(t *testing.T) {
+	cases := []*authorDaemonRunnerImpl{
+		// keychains get error
+		{kc: &fixedKeychainsGetter{err: errors.New("some get error")}},
+
+		// author get error
+		{
+			ag: &fixedAuthorGetter{err: errors.New("some get error")},
+			kc: &fixedKeychainsGetter{},
+		},
+	}
+	for i, c := range cases {
+		assert.NotNil(t, c.run(), i)
+	}
+}
+
+func TestServeDaemon(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dataDir, err := ioutil.TempDir("", "test-author-data-dir")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dataDir)) }()
+	viper.Set(dataDirFlag, dataDir)
+	defer viper.Set(dataDirFlag, "")
+
+	// no daemon running yet
+	assert.Nil(t, getDaemonClient())
+
+	a := &fixedDaemonAuthor{
+		envKey:   id.NewPseudoRandom(rng),
+		metadata: &api.EntryMetadata{MediaType: "text/plain"},
+	}
+	stop := make(chan os.Signal, 1)
+	served := make(chan error, 1)
+	logger := logging.NewDevInfoLogger()
+	socketFile := getDaemonSocketFile()
+	go func() { served <- serveDaemon(a, socketFile, logger, stop) }()
+
+	var dc *daemon.Client
+	for dc == nil {
+		dc = getDaemonClient()
+	}
+	assert.Nil(t, dc.Close())
+
+	// daemon already running
+	assert.Equal(t, daemon.ErrDaemonRunning, serveDaemon(a, socketFile, logger, nil))
+
+	// each daemon wrapper closes its client after its request
+	content := api.RandBytes(rng, 1024)
+	u := &daemonUploader{client: getDaemonClient()}
+	envKey, err := u.upload(nil, bytes.NewReader(content), "text/plain", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, a.envKey, envKey)
+	_, err = u.upload(nil, bytes.NewReader(content), "text/plain", nil)
+	assert.NotNil(t, err)
+
+	downloaded := new(bytes.Buffer)
+	err = (&daemonDownloader{client: getDaemonClient()}).download(nil, downloaded, envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, content, downloaded.Bytes())
+
+	readerPub := &ecid.NewPseudoRandom(rng).Key().PublicKey
+	sharedEnvKey, err := (&daemonSharer{client: getDaemonClient()}).share(nil, envKey, readerPub)
+	assert.Nil(t, err)
+	assert.Equal(t, a.envKey, sharedEnvKey)
+
+	metadata, err := (&daemonInfoGetter{client: getDaemonClient()}).info(nil, envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, "text/plain", metadata.MediaType)
+
+	versionEnvKey, err := (&daemonVersionUploader{client: getDaemonClient()}).uploadVersion(nil,
+		bytes.NewReader(content), "text/plain", &lauthor.Lineage{PreviousEnvelopeKey: envKey}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, a.envKey, versionEnvKey)
+
+	downloaded = new(bytes.Buffer)
+	err = (&daemonVersionDownloader{client: getDaemonClient()}).downloadVersion(nil, downloaded,
+		envKey, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, content, downloaded.Bytes())
+
+	dirEnvKey, err := (&daemonDirUploader{client: getDaemonClient()}).uploadDir(nil, "some/dir")
+	assert.Nil(t, err)
+	assert.Equal(t, a.envKey, dirEnvKey)
+
+	err = (&daemonDirDownloader{client: getDaemonClient()}).downloadDir(nil, "some/dir", envKey)
+	assert.Nil(t, err)
+
+	versions, err := (&daemonHistorian{client: getDaemonClient()}).history(nil, envKey)
+	assert.Nil(t, err)
+	assert.Len(t, versions, 1)
+
+	pointerKey, err := (&daemonPointer{client: getDaemonClient()}).publish(nil, "some-name",
+		envKey)
+	assert.Nil(t, err)
+	assert.Equal(t, a.envKey, pointerKey)
+	pointer, err := (&daemonPointer{client: getDaemonClient()}).resolve(nil, "some-name")
+	assert.Nil(t, err)
+	assert.Equal(t, envKey.Bytes(), pointer.TargetEnvelopeKey)
+	pointer, err = (&daemonPointer{client: getDaemonClient()}).resolveKey(nil, pointerKey)
+	assert.Nil(t, err)
+	assert.Equal(t, envKey.Bytes(), pointer.TargetEnvelopeKey)
+
+	group := &lauthor.Group{Name: "some-group", ReaderPubs: []*ecdsa.PublicKey{readerPub}}
+	report, err := (&daemonGroupSharer{client: getDaemonClient()}).shareWithGroup(nil, envKey,
+		group)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.NShared())
+
+	s3Author := &daemonS3Author{client: getDaemonClient()}
+	_, s3EnvKey, err := s3Author.Upload(bytes.NewReader(content), "text/plain", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, a.envKey, s3EnvKey)
+	downloaded = new(bytes.Buffer)
+	assert.Nil(t, s3Author.Download(downloaded, envKey))
+	assert.Equal(t, content, downloaded.Bytes())
+	assert.Nil(t, s3Author.client.Close())
+
+	// all commands use the daemon
+	uploader := newFileUploader().(*fileUploaderImpl)
+	assert.IsType(t, &daemonUploader{}, uploader.au)
+	assert.IsType(t, &daemonVersionUploader{}, uploader.avu)
+	assert.IsType(t, &daemonDirUploader{}, uploader.adu)
+	assert.Nil(t, uploader.au.(*daemonUploader).client.Close())
+	downloader := newFileDownloader().(*fileDownloaderImpl)
+	assert.IsType(t, &daemonDownloader{}, downloader.ad)
+	assert.IsType(t, &daemonVersionDownloader{}, downloader.avd)
+	assert.IsType(t, &daemonDirDownloader{}, downloader.add)
+	assert.Nil(t, downloader.ad.(*daemonDownloader).client.Close())
+	sharer := newDocSharer().(*docSharerImpl)
+	assert.IsType(t, &daemonSharer{}, sharer.as)
+	assert.IsType(t, &daemonGroupSharer{}, sharer.ags)
+	assert.Nil(t, sharer.as.(*daemonSharer).client.Close())
+	infoPrinter := newInfoPrinter().(*infoPrinterImpl)
+	assert.IsType(t, &daemonInfoGetter{}, infoPrinter.ai)
+	assert.Nil(t, infoPrinter.ai.(*daemonInfoGetter).client.Close())
+	historyPrinter := newHistoryPrinter().(*historyPrinterImpl)
+	assert.IsType(t, &daemonHistorian{}, historyPrinter.ah)
+	assert.Nil(t, historyPrinter.ah.(*daemonHistorian).client.Close())
+	publisher := newPointerPublisher().(*pointerPublisherImpl)
+	assert.IsType(t, &daemonPointer{}, publisher.ap)
+	assert.Nil(t, publisher.ap.(*daemonPointer).client.Close())
+	resolver := newPointerResolver().(*pointerResolverImpl)
+	assert.IsType(t, &daemonPointer{}, resolver.ap)
+	assert.Nil(t, resolver.ap.(*daemonPointer).client.Close())
+	s3Runner := newS3GatewayRunner().(*s3GatewayRunnerImpl)
+	assert.NotNil(t, s3Runner.dc)
+	assert.Nil(t, s3Runner.dc.Close())
+
+	// another daemon fails before asking for the passphrase
+	assert.Equal(t, daemon.ErrDaemonRunning, newAuthorDaemonRunner().run())
+
+	stop <- syscall.SIGTERM
+	assert.Nil(t, <-served)
+}
+
+func TestGetDaemonSocketFile(t *testing.T) {
+	viper.Set(dataDirFlag, "/some/data/dir")
+	defer viper.Set(dataDirFlag, "")
+	assert.Equal(t, filepath.Join("/some/data/dir", lauthor.DaemonSocketFilename),
+		getDaemonSocketFile())
+
+	viper.Set(daemonSocketFlag, "/some/author.sock")
+	defer viper.Set(daemonSocketFlag, "")
+	assert.Equal(t, "/some/author.sock", getDaemonSocketFile())
+}
+
+func TestDaemonGetters(t *testing.T) {
+	authorKeys, selfReaderKeys, err := (&daemonKeychainsGetter{}).get()
+	assert.Nil(t, err)
+	assert.Nil(t, authorKeys)
+	assert.Nil(t, selfReaderKeys)
+
+	author, logger, err := (&daemonAuthorGetter{}).get(authorKeys, selfReaderKeys)
+	assert.Nil(t, err)
+	assert.Nil(t, author)
+	assert.NotNil(t, logger)
+}
+
+type fixedDaemonAuthor struct {
+	envKey        id.ID
+	metadata      *api.EntryMetadata
+	content       []byte
+	pointerTarget id.ID
+}
+
+func (f *fixedDaemonAuthor) Upload(
+	content io.Reader, mediaType string, opts *lauthor.UploadOptions,
+) (*api.Document, id.ID, error) {
+	var err error
+	f.content, err = ioutil.ReadAll(content)
+	return nil, f.envKey, err
+}
+
+func (f *fixedDaemonAuthor) Download(content io.Writer, envKey id.ID) error {
+	_, err := content.Write(f.content)
+	return err
+}
+
+func (f *fixedDaemonAuthor) Share(envKey id.ID, readerPub *ecdsa.PublicKey) (
+	*api.Document, id.ID, error) {
+	return nil, f.envKey, nil
+}
+
+func (f *fixedDaemonAuthor) Info(envKey id.ID) (*api.EntryMetadata, id.ID, error) {
+	return f.metadata, f.envKey, nil
+}
+
+func (f *fixedDaemonAuthor) UploadVersion(
+	content io.Reader, mediaType string, lineage *lauthor.Lineage, opts *lauthor.UploadOptions,
+) (*api.Document, id.ID, error) {
+	return f.Upload(content, mediaType, opts)
+}
+
+func (f *fixedDaemonAuthor) DownloadVersion(content io.Writer, envKey id.ID, number int) (
+	*lauthor.Version, error) {
+	return nil, f.Download(content, envKey)
+}
+
+func (f *fixedDaemonAuthor) UploadDir(dirpath string) (
+	*api.Document, id.ID, *api.Manifest, error) {
+	return nil, f.envKey, nil, nil
+}
+
+func (f *fixedDaemonAuthor) DownloadDir(dirpath string, envKey id.ID) (
+	*api.Manifest, int, error) {
+	return nil, 0, nil
+}
+
+func (f *fixedDaemonAuthor) History(envKey id.ID) ([]*lauthor.Version, error) {
+	return []*lauthor.Version{{
+		Number:      1,
+		EnvelopeKey: envKey,
+		EntryKey:    f.envKey,
+		Metadata:    f.metadata,
+	}}, nil
+}
+
+func (f *fixedDaemonAuthor) Publish(name string, envKey id.ID) (*api.Document, id.ID, error) {
+	f.pointerTarget = envKey
+	return nil, f.envKey, nil
+}
+
+func (f *fixedDaemonAuthor) Resolve(name string) (*api.Pointer, error) {
+	return &api.Pointer{Name: name, TargetEnvelopeKey: f.pointerTarget.Bytes()}, nil
+}
+
+func (f *fixedDaemonAuthor) ResolveKey(pointerKey id.ID) (*api.Pointer, error) {
+	return &api.Pointer{TargetEnvelopeKey: f.pointerTarget.Bytes()}, nil
+}
+
+func (f *fixedDaemonAuthor) ShareWithGroup(envKey id.ID, group *lauthor.Group) (
+	*lauthor.GroupShareReport, error) {
+	report := &lauthor.GroupShareReport{Group: group.Name}
+	for _, readerPub := range group.ReaderPubs {
+		report.Results = append(report.Results,
+			&lauthor.GroupShareResult{ReaderPub: readerPub, EnvelopeKey: f.envKey})
+	}
+	return report, nil
+}
@@ -14,16 +14,25 @@ import (
 const (
 	envelopeKeyFlag  = "envelopeKey"
 	downFilepathFlag = "downFilepath"
+	downDirpathFlag  = "downDirpath"
+	downVersionFlag  = "downVersion"
 )
 
 var (
 	errMissingEnvelopeKey = errors.New("missing envelope key")
+	errDirpathVersion     = errors.New("cannot give a version with a dirpath")
 )
 
 // downloadCmd represents the download command
 var downloadCmd = &cobra.Command{
 	Use:   "download",
 	Short: "download a file from a Libri network using an envelope ID",
+	Long: `Download a file from a Libri network using an envelope ID. Alternatively, download the
+directory tree listed by a manifest uploaded with "upload --upDirpath", skipping local files that
+already have the same contents.
+
+Earlier versions of a file uploaded with "upload --upPrevious" can be downloaded by giving the
+version number (see "author history"), where version 1 is the first version.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return newFileDownloader().download()
 	},
@@ -36,8 +45,12 @@ func init() {
 		"number of parallel processes")
 	downloadCmd.Flags().StringP(downFilepathFlag, "f", "",
 		"path of local file to write downloaded contents to")
+	downloadCmd.Flags().String(downDirpathFlag, "",
+		"path of local directory to write downloaded manifest files to")
 	downloadCmd.Flags().StringP(envelopeKeyFlag, "e", "",
 		"key of envelope to download")
+	downloadCmd.Flags().Uint(downVersionFlag, 0,
+		"version number in the history of the envelope to download instead (1 is the first)")
 
 	// bind viper flags
 	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
@@ -50,9 +63,20 @@ type fileDownloader interface {
 }
 
 func newFileDownloader() fileDownloader {
+	if dc := getDaemonClient(); dc != nil {
+		return &fileDownloaderImpl{
+			ag:  &daemonAuthorGetter{},
+			ad:  &daemonDownloader{client: dc},
+			avd: &daemonVersionDownloader{client: dc},
+			add: &daemonDirDownloader{client: dc},
+			kc:  &daemonKeychainsGetter{},
+		}
+	}
 	return &fileDownloaderImpl{
-		ag: newAuthorGetter(),
-		ad: &authorDownloaderImpl{},
+		ag:  newAuthorGetter(),
+		ad:  &authorDownloaderImpl{},
+		avd: &authorVersionDownloaderImpl{},
+		add: &authorDirDownloaderImpl{},
 		kc: &keychainsGetterImpl{
 			pg: &terminalPassphraseGetter{},
 		},
@@ -60,9 +84,11 @@ func newFileDownloader() fileDownloader {
 }
 
 type fileDownloaderImpl struct {
-	ag authorGetter
-	ad authorDownloader
-	kc keychainsGetter
+	ag  authorGetter
+	ad  authorDownloader
+	avd authorVersionDownloader
+	add authorDirDownloader
+	kc  keychainsGetter
 }
 
 func (d *fileDownloaderImpl) download() error {
@@ -75,6 +101,17 @@ func (d *fileDownloaderImpl) download() error {
 		return err
 	}
 	downFilepath := viper.GetString(downFilepathFlag)
+	downDirpath := viper.GetString(downDirpathFlag)
+	if downFilepath != "" && downDirpath != "" {
+		return errFilepathAndDirpath
+	}
+	version := viper.GetInt(downVersionFlag)
+	if downDirpath != "" {
+		if version != 0 {
+			return errDirpathVersion
+		}
+		return d.downloadDir(envelopeKey, downDirpath)
+	}
 	if downFilepath == "" {
 		return errMissingFilepath
 	}
@@ -93,10 +130,34 @@ func (d *fileDownloaderImpl) download() error {
 	logger.Info("downloading document",
 		zap.Stringer("envelope_key", envelopeKey),
 		zap.String("filepath", downFilepath),
+		zap.Int("version", version),
 	)
-	err = d.ad.download(author, file, envelopeKey)
+	if version != 0 {
+		err = d.avd.downloadVersion(author, file, envelopeKey, version)
+	} else {
+		err = d.ad.download(author, file, envelopeKey)
+	}
 	if err != nil {
 		return err
 	}
 	return file.Close()
 }
+
+func (d *fileDownloaderImpl) downloadDir(envelopeKey id.ID, downDirpath string) error {
+	authorKeys, selfReaderKeys, err := d.kc.get()
+	if err != nil {
+		return err
+	}
+	author, logger, err := d.ag.get(authorKeys, selfReaderKeys)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(downDirpath, os.ModePerm); err != nil {
+		return err
+	}
+	logger.Info("downloading directory",
+		zap.Stringer("envelope_key", envelopeKey),
+		zap.String("dirpath", downDirpath),
+	)
+	return d.add.downloadDir(author, downDirpath, envelopeKey)
+}
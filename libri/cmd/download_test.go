@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	lauthor "github.com/drausin/libri/libri/author"
@@ -109,6 +110,109 @@ func TestFileDownloader_download_err(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestFileDownloader_downloadVersion(t *testing.T) {
+	toDownloadFile, err := ioutil.TempFile("", "to-download")
+	assert.Nil(t, err)
+	assert.Nil(t, toDownloadFile.Close())
+	defer func() { assert.Nil(t, os.Remove(toDownloadFile.Name())) }()
+	viper.Set(envelopeKeyFlag, id.LowerBound.String())
+	viper.Set(downFilepathFlag, toDownloadFile.Name())
+	viper.Set(downVersionFlag, 2)
+	defer viper.Set(downVersionFlag, 0)
+
+	// ok
+	avd := &fixedAuthorVersionDownloader{}
+	d := &fileDownloaderImpl{
+		ag: &fixedAuthorGetter{
+			author: nil, // ok since we're passing it into a mocked method anyway
+			logger: logging.NewDevInfoLogger(),
+		},
+		avd: avd,
+		kc:  &fixedKeychainsGetter{}, // ok that KCs are null since passing to mock
+	}
+	err = d.download()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, avd.number)
+
+	// download version error
+	d.avd = &fixedAuthorVersionDownloader{err: errors.New("some download error")}
+	assert.NotNil(t, d.download())
+
+	// version with dirpath
+	viper.Set(downFilepathFlag, "")
+	viper.Set(downDirpathFlag, os.TempDir())
+	defer viper.Set(downDirpathFlag, "")
+	assert.Equal(t, errDirpathVersion, d.download())
+}
+
+func TestFileDownloader_downloadDir_ok(t *testing.T) {
+	d := &fileDownloaderImpl{
+		ag: &fixedAuthorGetter{
+			author: nil, // ok since we're passing it into a mocked method anyway
+			logger: logging.NewDevInfoLogger(),
+		},
+		add: &fixedAuthorDirDownloader{},
+		kc:  &fixedKeychainsGetter{}, // ok that KCs are null since passing to mock
+	}
+	toDownloadDir, err := ioutil.TempDir("", "to-download")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(toDownloadDir)) }()
+	downDirpath := filepath.Join(toDownloadDir, "sub") // created if missing
+	viper.Set(downFilepathFlag, "")
+	viper.Set(downDirpathFlag, downDirpath)
+	defer viper.Set(downDirpathFlag, "")
+	viper.Set(envelopeKeyFlag, id.LowerBound.String())
+
+	err = d.download()
+	assert.Nil(t, err)
+	info, err := os.Stat(downDirpath)
+	assert.Nil(t, err)
+	assert.True(t, info.IsDir())
+}
+
+func TestFileDownloader_downloadDir_err(t *testing.T) {
+	toDownloadDir, err := ioutil.TempDir("", "to-download")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(toDownloadDir)) }()
+	viper.Set(envelopeKeyFlag, id.LowerBound.String())
+	defer viper.Set(downDirpathFlag, "")
+
+	// should error on both filepath and dirpath
+	d1 := &fileDownloaderImpl{}
+	viper.Set(downFilepathFlag, "some/download/filepath")
+	viper.Set(downDirpathFlag, toDownloadDir)
+	err = d1.download()
+	assert.Equal(t, errFilepathAndDirpath, err)
+	viper.Set(downFilepathFlag, "")
+
+	// error getting author keys should bubble up
+	d2 := &fileDownloaderImpl{
+		kc: &fixedKeychainsGetter{err: errors.New("some get error")},
+	}
+	err = d2.download()
+	assert.NotNil(t, err)
+
+	// error getting author should bubble up
+	d3 := &fileDownloaderImpl{
+		ag: &fixedAuthorGetter{err: errors.New("some get error")},
+		kc: &fixedKeychainsGetter{}, // ok that KCs are null since passing to mock
+	}
+	err = d3.download()
+	assert.NotNil(t, err)
+
+	// download error should bubble up
+	d4 := &fileDownloaderImpl{
+		ag: &fixedAuthorGetter{
+			author: nil, // ok since we're passing it into a mocked method anyway
+			logger: logging.NewDevInfoLogger(),
+		},
+		add: &fixedAuthorDirDownloader{err: errors.New("some download error")},
+		kc:  &fixedKeychainsGetter{}, // ok that KCs are null since passing to mock
+	}
+	err = d4.download()
+	assert.NotNil(t, err)
+}
+
 type fixedAuthorDownloader struct {
 	err error
 }
@@ -118,3 +222,26 @@ func (f *fixedAuthorDownloader) download(
 ) error {
 	return f.err
 }
+
+type fixedAuthorVersionDownloader struct {
+	err error
+
+	number int
+}
+
+func (f *fixedAuthorVersionDownloader) downloadVersion(
+	author *lauthor.Author, content io.Writer, envelopeKey id.ID, number int,
+) error {
+	f.number = number
+	return f.err
+}
+
+type fixedAuthorDirDownloader struct {
+	err error
+}
+
+func (f *fixedAuthorDirDownloader) downloadDir(
+	author *lauthor.Author, dirpath string, envelopeKey id.ID,
+) error {
+	return f.err
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	errMissingGroupName    = errors.New("missing group name")
+	errMissingGroupMembers = errors.New("missing one or more group members")
+)
+
+// groupsCmd represents the author groups command
+var groupsCmd = &cobra.Command{
+	Use:   "groups",
+	Short: "manage named groups of reader keys documents are shared with",
+	Long: `Manage named groups of the reader public keys documents are shared with (see "share
+--group"). Group members are given either as contact names from the contact book (see "author
+contacts") or as encoded reader keys.`,
+}
+
+// groupsListCmd represents the author groups list command
+var groupsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "print the group names and member reader keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newGroupsEditor().list(os.Stdout)
+	},
+}
+
+// groupsAddCmd represents the author groups add command
+var groupsAddCmd = &cobra.Command{
+	Use:   "add GROUP MEMBER...",
+	Short: "add members to a group, creating it if necessary",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newGroupsEditor().add(args)
+	},
+}
+
+// groupsRemoveCmd represents the author groups remove command
+var groupsRemoveCmd = &cobra.Command{
+	Use:   "remove GROUP [MEMBER...]",
+	Short: "remove members from a group, or the whole group if no members are given",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newGroupsEditor().remove(args)
+	},
+}
+
+func init() {
+	authorCmd.AddCommand(groupsCmd)
+	groupsCmd.AddCommand(groupsListCmd)
+	groupsCmd.AddCommand(groupsAddCmd)
+	groupsCmd.AddCommand(groupsRemoveCmd)
+}
+
+type groupsEditor struct {
+	filepath string
+	contacts *contactsEditor
+}
+
+func newGroupsEditor() *groupsEditor {
+	groupsFile := lauthor.NewDefaultConfig().
+		WithDataDir(viper.GetString(dataDirFlag)).
+		WithGroupsFile(viper.GetString(groupsFileFlag)). // depends on DataDir
+		GroupsFile
+	return &groupsEditor{
+		filepath: groupsFile,
+		contacts: newContactsEditor(),
+	}
+}
+
+func (e *groupsEditor) list(w io.Writer) error {
+	g, err := e.read(true)
+	if err != nil {
+		return err
+	}
+	for _, name := range g.Names() {
+		for _, readerKey := range g.Members[name] {
+			if _, err := fmt.Fprintf(w, "%s\t%s\n", name, readerKey); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *groupsEditor) add(args []string) error {
+	if len(args) == 0 {
+		return errMissingGroupName
+	}
+	if len(args) == 1 {
+		return errMissingGroupMembers
+	}
+	readerPubs, err := e.resolveMembers(args[1:])
+	if err != nil {
+		return err
+	}
+	g, err := e.read(true)
+	if err != nil {
+		return err
+	}
+	if _, err := g.Add(args[0], readerPubs...); err != nil {
+		return err
+	}
+	return lauthor.WriteGroups(e.filepath, g)
+}
+
+func (e *groupsEditor) remove(args []string) error {
+	if len(args) == 0 {
+		return errMissingGroupName
+	}
+	g, err := e.read(false)
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		if !g.Delete(args[0]) {
+			return fmt.Errorf("%s: %s", lauthor.ErrGroupNotFound, args[0])
+		}
+		return lauthor.WriteGroups(e.filepath, g)
+	}
+	readerPubs, err := e.resolveMembers(args[1:])
+	if err != nil {
+		return err
+	}
+	if _, err := g.RemoveMembers(args[0], readerPubs...); err != nil {
+		return err
+	}
+	return lauthor.WriteGroups(e.filepath, g)
+}
+
+// resolveMembers resolves each member to a reader public key, either from the contact book if
+// it's a contact name or by decoding it as a reader key otherwise.
+func (e *groupsEditor) resolveMembers(members []string) ([]*ecdsa.PublicKey, error) {
+	contacts, err := e.contacts.read(true)
+	if err != nil {
+		return nil, err
+	}
+	readerPubs := make([]*ecdsa.PublicKey, len(members))
+	for i, member := range members {
+		if _, in := contacts.ReaderKeys[member]; in {
+			readerPubs[i], err = contacts.Get(member)
+		} else {
+			readerPubs[i], err = lauthor.DecodeReaderKey(member)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid group member %s: %s", member, err)
+		}
+	}
+	return readerPubs, nil
+}
+
+// read reads the groups, returning empty ones if the file doesn't exist and emptyIfMissing is
+// true.
+func (e *groupsEditor) read(emptyIfMissing bool) (*lauthor.Groups, error) {
+	g, err := lauthor.ReadGroups(e.filepath)
+	if os.IsNotExist(err) && emptyIfMissing {
+		return lauthor.NewGroups(), nil
+	}
+	return g, err
+}
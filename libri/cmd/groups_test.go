@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupsCmds_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "groups-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	viper.Set(dataDirFlag, dir)
+	defer viper.Set(dataDirFlag, "")
+
+	alice, bob := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	aliceKey := lauthor.EncodeReaderKey(&alice.Key().PublicKey)
+	bobKey := lauthor.EncodeReaderKey(&bob.Key().PublicKey)
+	err = contactsAddCmd.RunE(contactsAddCmd, []string{"alice", aliceKey})
+	assert.Nil(t, err)
+
+	// listing missing groups prints nothing
+	buf := new(bytes.Buffer)
+	assert.Nil(t, newGroupsEditor().list(buf))
+	assert.Empty(t, buf.String())
+
+	// members can be contact names or reader keys
+	err = groupsAddCmd.RunE(groupsAddCmd, []string{"team", "alice", bobKey})
+	assert.Nil(t, err)
+	err = groupsAddCmd.RunE(groupsAddCmd, []string{"other", bobKey})
+	assert.Nil(t, err)
+
+	// groups default to file in data dir
+	g, err := lauthor.ReadGroups(filepath.Join(dir, lauthor.GroupsFilename))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"other", "team"}, g.Names())
+	assert.Equal(t, []string{aliceKey, bobKey}, g.Members["team"])
+
+	buf = new(bytes.Buffer)
+	assert.Nil(t, newGroupsEditor().list(buf))
+	expected := "other\t" + bobKey + "\nteam\t" + aliceKey + "\nteam\t" + bobKey + "\n"
+	assert.Equal(t, expected, buf.String())
+
+	// remove member, then whole group
+	err = groupsRemoveCmd.RunE(groupsRemoveCmd, []string{"team", "alice"})
+	assert.Nil(t, err)
+	err = groupsRemoveCmd.RunE(groupsRemoveCmd, []string{"other"})
+	assert.Nil(t, err)
+	g, err = newGroupsEditor().read(false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"team"}, g.Names())
+	assert.Equal(t, []string{bobKey}, g.Members["team"])
+
+	// groups file flag overrides default
+	fp := filepath.Join(dir, "other-groups.json")
+	viper.Set(groupsFileFlag, fp)
+	defer viper.Set(groupsFileFlag, "")
+	err = groupsAddCmd.RunE(groupsAddCmd, []string{"team", "alice"})
+	assert.Nil(t, err)
+	g, err = lauthor.ReadGroups(fp)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{aliceKey}, g.Members["team"])
+}
+
+func TestGroupsCmds_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "groups-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	viper.Set(dataDirFlag, dir)
+	defer viper.Set(dataDirFlag, "")
+	aliceKey := lauthor.EncodeReaderKey(&ecid.NewPseudoRandom(rng).Key().PublicKey)
+
+	// wrong number of args
+	err = groupsAddCmd.RunE(groupsAddCmd, []string{})
+	assert.Equal(t, errMissingGroupName, err)
+	err = groupsAddCmd.RunE(groupsAddCmd, []string{"team"})
+	assert.Equal(t, errMissingGroupMembers, err)
+	err = groupsRemoveCmd.RunE(groupsRemoveCmd, []string{})
+	assert.Equal(t, errMissingGroupName, err)
+
+	// unknown contact & bad reader key
+	err = groupsAddCmd.RunE(groupsAddCmd, []string{"team", "bob"})
+	assert.NotNil(t, err)
+
+	// can't remove from missing groups file
+	err = groupsRemoveCmd.RunE(groupsRemoveCmd, []string{"team"})
+	assert.True(t, os.IsNotExist(err))
+
+	// can't remove missing group or from missing group
+	err = groupsAddCmd.RunE(groupsAddCmd, []string{"team", aliceKey})
+	assert.Nil(t, err)
+	err = groupsRemoveCmd.RunE(groupsRemoveCmd, []string{"other"})
+	assert.NotNil(t, err)
+	err = groupsRemoveCmd.RunE(groupsRemoveCmd, []string{"other", aliceKey})
+	assert.NotNil(t, err)
+	err = groupsRemoveCmd.RunE(groupsRemoveCmd, []string{"team", "bob"})
+	assert.NotNil(t, err)
+
+	// bad contact book file
+	contactsFP := filepath.Join(dir, lauthor.ContactsFilename)
+	assert.Nil(t, ioutil.WriteFile(contactsFP, []byte("not json"), 0600))
+	err = groupsAddCmd.RunE(groupsAddCmd, []string{"team", aliceKey})
+	assert.NotNil(t, err)
+
+	// bad groups file
+	fp := filepath.Join(dir, lauthor.GroupsFilename)
+	assert.Nil(t, ioutil.WriteFile(fp, []byte("not json"), 0600))
+	assert.NotNil(t, newGroupsEditor().list(new(bytes.Buffer)))
+	assert.Nil(t, os.Remove(contactsFP))
+	err = groupsAddCmd.RunE(groupsAddCmd, []string{"team", aliceKey})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	historyEnvelopeKeyFlag = "historyEnvelopeKey"
+)
+
+// historyCmd represents the author history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "print the version history of a document on the libri network",
+	Long: `Print the version history of a document uploaded with "upload --upPrevious", from the
+first version to the given one. Only the entry metadata of each version is downloaded. Each
+version's number, envelope key, entry key, creation time, media type, and uncompressed size are
+printed. Any version can be downloaded with "download --downVersion".
+
+Only the document's author can print its history, since each version records the author's own
+envelope of the previous version. Readers of a shared envelope can still print its metadata with
+"author info".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newHistoryPrinter().print(os.Stdout)
+	},
+}
+
+func init() {
+	authorCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringP(historyEnvelopeKeyFlag, "e", "",
+		"key of envelope of the latest version of the document")
+
+	// bind viper flags
+	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
+	viper.AutomaticEnv()             // read in environment variables that match
+	cerrors.MaybePanic(viper.BindPFlags(historyCmd.Flags()))
+}
+
+type historyPrinter interface {
+	print(w io.Writer) error
+}
+
+func newHistoryPrinter() historyPrinter {
+	if dc := getDaemonClient(); dc != nil {
+		return &historyPrinterImpl{
+			ag: &daemonAuthorGetter{},
+			ah: &daemonHistorian{client: dc},
+			kc: &daemonKeychainsGetter{},
+		}
+	}
+	return &historyPrinterImpl{
+		ag: newAuthorGetter(),
+		ah: &authorHistorianImpl{},
+		kc: &keychainsGetterImpl{
+			pg: &terminalPassphraseGetter{},
+		},
+	}
+}
+
+type historyPrinterImpl struct {
+	ag authorGetter
+	ah authorHistorian
+	kc keychainsGetter
+}
+
+func (p *historyPrinterImpl) print(w io.Writer) error {
+	envelopeKeyStr := viper.GetString(historyEnvelopeKeyFlag)
+	if envelopeKeyStr == "" {
+		return errMissingEnvelopeKey
+	}
+	envelopeKey, err := id.FromString(envelopeKeyStr)
+	if err != nil {
+		return err
+	}
+	authorKeys, selfReaderKeys, err := p.kc.get()
+	if err != nil {
+		return err
+	}
+	author, logger, err := p.ag.get(authorKeys, selfReaderKeys)
+	if err != nil {
+		return err
+	}
+	logger.Info("getting history", zap.Stringer("envelope_key", envelopeKey))
+	versions, err := p.ah.history(author, envelopeKey)
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		_, err = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\n", v.Number, v.EnvelopeKey,
+			v.EntryKey, v.CreatedTime.UTC().Format(time.RFC3339), v.Metadata.MediaType,
+			v.Metadata.UncompressedSize)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorHistorian just wraps an *author.Author History call for the same reason as
+// authorUploader
+type authorHistorian interface {
+	history(author *lauthor.Author, envelopeKey id.ID) ([]*lauthor.Version, error)
+}
+
+type authorHistorianImpl struct{}
+
+func (*authorHistorianImpl) history(author *lauthor.Author, envelopeKey id.ID) (
+	[]*lauthor.Version, error) {
+	return author.History(envelopeKey)
+}
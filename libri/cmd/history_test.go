@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryCmd_err(t *testing.T) {
+	viper.Set(historyEnvelopeKeyFlag, "")
+	err := historyCmd.RunE(historyCmd, []string{})
+	assert.Equal(t, errMissingEnvelopeKey, err)
+}
+
+func TestHistoryPrinter_print_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	envelopeKey := id.NewPseudoRandom(rng)
+	viper.Set(historyEnvelopeKeyFlag, envelopeKey.String())
+	defer viper.Set(historyEnvelopeKeyFlag, "")
+	versions := []*lauthor.Version{
+		{
+			Number:      1,
+			EnvelopeKey: id.NewPseudoRandom(rng),
+			EntryKey:    id.NewPseudoRandom(rng),
+			CreatedTime: time.Unix(0, 0),
+			Metadata:    &api.EntryMetadata{MediaType: "text/csv", UncompressedSize: 128},
+		},
+		{
+			Number:      2,
+			EnvelopeKey: envelopeKey,
+			EntryKey:    id.NewPseudoRandom(rng),
+			CreatedTime: time.Unix(60, 0),
+			Metadata:    &api.EntryMetadata{MediaType: "text/csv", UncompressedSize: 256},
+		},
+	}
+	ah := &fixedAuthorHistorian{versions: versions}
+	p := &historyPrinterImpl{
+		ag: &fixedAuthorGetter{
+			author: nil, // ok since we're passing it into a mocked method anyway
+			logger: logging.NewDevInfoLogger(),
+		},
+		ah: ah,
+		kc: &fixedKeychainsGetter{}, // ok that KCs are null for same reason
+	}
+	buf := new(bytes.Buffer)
+	err := p.print(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, envelopeKey, ah.envelopeKey)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.Equal(t, strings.Join([]string{"1", versions[0].EnvelopeKey.String(),
+		versions[0].EntryKey.String(), "1970-01-01T00:00:00Z", "text/csv", "128"}, "\t"),
+		lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "2\t"+envelopeKey.String()))
+}
+
+func TestHistoryPrinter_print_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	envelopeKey := id.NewPseudoRandom(rng).String()
+	defer viper.Set(historyEnvelopeKeyFlag, "")
+
+	cases := []struct {
+		envelopeKey string
+		p           *historyPrinterImpl
+		expected    error
+	}{
+		// missing envelope key
+		{envelopeKey: "", p: &historyPrinterImpl{}, expected: errMissingEnvelopeKey},
+
+		// bad envelope key
+		{envelopeKey: "not an ID", p: &historyPrinterImpl{}},
+
+		// keychains get error
+		{envelopeKey: envelopeKey, p: &historyPrinterImpl{
+			kc: &fixedKeychainsGetter{err: errors.New("some get error")},
+		}},
+
+		// author get error
+		{envelopeKey: envelopeKey, p: &historyPrinterImpl{
+			ag: &fixedAuthorGetter{err: errors.New("some get error")},
+			kc: &fixedKeychainsGetter{},
+		}},
+
+		// history error
+		{envelopeKey: envelopeKey, p: &historyPrinterImpl{
+			ag: &fixedAuthorGetter{logger: logging.NewDevInfoLogger()},
+			ah: &fixedAuthorHistorian{err: errors.New("some history error")},
+			kc: &fixedKeychainsGetter{},
+		}},
+	}
+	for i, c := range cases {
+		viper.Set(historyEnvelopeKeyFlag, c.envelopeKey)
+		err := c.p.print(new(bytes.Buffer))
+		assert.NotNil(t, err, i)
+		if c.expected != nil {
+			assert.Equal(t, c.expected, err, i)
+		}
+	}
+}
+
+type fixedAuthorHistorian struct {
+	versions []*lauthor.Version
+	err      error
+
+	envelopeKey id.ID
+}
+
+func (f *fixedAuthorHistorian) history(author *lauthor.Author, envelopeKey id.ID) (
+	[]*lauthor.Version, error) {
+	f.envelopeKey = envelopeKey
+	return f.versions, f.err
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	infoEnvelopeKeyFlag = "infoEnvelopeKey"
+)
+
+// infoCmd represents the author info command
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "print the metadata of a document on the libri network as JSON",
+	Long: `Print the decrypted metadata of a document on the libri network as JSON, including its
+media type, sizes, properties, schema, filepath, and lineage. Only the envelope and entry are
+downloaded, not the document's pages.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newInfoPrinter().print(os.Stdout)
+	},
+}
+
+func init() {
+	authorCmd.AddCommand(infoCmd)
+
+	infoCmd.Flags().StringP(infoEnvelopeKeyFlag, "e", "",
+		"key of envelope of document to print the metadata of")
+
+	// bind viper flags
+	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
+	viper.AutomaticEnv()             // read in environment variables that match
+	cerrors.MaybePanic(viper.BindPFlags(infoCmd.Flags()))
+}
+
+type infoPrinter interface {
+	print(w io.Writer) error
+}
+
+func newInfoPrinter() infoPrinter {
+	if dc := getDaemonClient(); dc != nil {
+		return &infoPrinterImpl{
+			ag: &daemonAuthorGetter{},
+			ai: &daemonInfoGetter{client: dc},
+			kc: &daemonKeychainsGetter{},
+		}
+	}
+	return &infoPrinterImpl{
+		ag: newAuthorGetter(),
+		ai: &authorInfoGetterImpl{},
+		kc: &keychainsGetterImpl{
+			pg: &terminalPassphraseGetter{},
+		},
+	}
+}
+
+type infoPrinterImpl struct {
+	ag authorGetter
+	ai authorInfoGetter
+	kc keychainsGetter
+}
+
+func (p *infoPrinterImpl) print(w io.Writer) error {
+	envelopeKeyStr := viper.GetString(infoEnvelopeKeyFlag)
+	if envelopeKeyStr == "" {
+		return errMissingEnvelopeKey
+	}
+	envelopeKey, err := id.FromString(envelopeKeyStr)
+	if err != nil {
+		return err
+	}
+	authorKeys, selfReaderKeys, err := p.kc.get()
+	if err != nil {
+		return err
+	}
+	author, logger, err := p.ag.get(authorKeys, selfReaderKeys)
+	if err != nil {
+		return err
+	}
+	logger.Info("getting document info", zap.Stringer("envelope_key", envelopeKey))
+	metadata, err := p.ai.info(author, envelopeKey)
+	if err != nil {
+		return err
+	}
+	m := &jsonpb.Marshaler{OrigName: true, Indent: "  "}
+	js, err := m.MarshalToString(metadata)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, js)
+	return err
+}
+
+// authorInfoGetter just wraps an *author.Author Info call for the same reason as authorUploader
+type authorInfoGetter interface {
+	info(author *lauthor.Author, envelopeKey id.ID) (*api.EntryMetadata, error)
+}
+
+type authorInfoGetterImpl struct{}
+
+func (*authorInfoGetterImpl) info(author *lauthor.Author, envelopeKey id.ID) (
+	*api.EntryMetadata, error) {
+	metadata, _, err := author.Info(envelopeKey)
+	return metadata, err
+}
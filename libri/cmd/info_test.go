@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfoCmd_err(t *testing.T) {
+	viper.Set(infoEnvelopeKeyFlag, "")
+	err := infoCmd.RunE(infoCmd, []string{})
+	assert.Equal(t, errMissingEnvelopeKey, err)
+}
+
+func TestInfoPrinter_print_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	envelopeKey := id.NewPseudoRandom(rng)
+	viper.Set(infoEnvelopeKeyFlag, envelopeKey.String())
+	defer viper.Set(infoEnvelopeKeyFlag, "")
+	metadata := &api.EntryMetadata{
+		MediaType:        "text/csv",
+		CompressionCodec: api.CompressionCodec_GZIP,
+		CiphertextSize:   256,
+		CiphertextMac:    api.RandBytes(rng, api.HMAC256Length),
+		UncompressedSize: 512,
+		UncompressedMac:  api.RandBytes(rng, api.HMAC256Length),
+		Filepath:         "some/file.csv",
+	}
+	ai := &fixedAuthorInfoGetter{metadata: metadata}
+	p := &infoPrinterImpl{
+		ag: &fixedAuthorGetter{
+			author: nil, // ok since we're passing it into a mocked method anyway
+			logger: logging.NewDevInfoLogger(),
+		},
+		ai: ai,
+		kc: &fixedKeychainsGetter{}, // ok that KCs are null for same reason
+	}
+	buf := new(bytes.Buffer)
+	err := p.print(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, envelopeKey, ai.envelopeKey)
+
+	printed := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &printed))
+	assert.Equal(t, "text/csv", printed["media_type"])
+	assert.Equal(t, "GZIP", printed["compression_codec"])
+	assert.Equal(t, "512", printed["uncompressed_size"])
+	assert.Equal(t, "some/file.csv", printed["filepath"])
+}
+
+func TestInfoPrinter_print_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	envelopeKey := id.NewPseudoRandom(rng).String()
+	defer viper.Set(infoEnvelopeKeyFlag, "")
+
+	cases := []struct {
+		envelopeKey string
+		p           *infoPrinterImpl
+		expected    error
+	}{
+		// missing envelope key
+		{envelopeKey: "", p: &infoPrinterImpl{}, expected: errMissingEnvelopeKey},
+
+		// bad envelope key
+		{envelopeKey: "not an ID", p: &infoPrinterImpl{}},
+
+		// keychains get error
+		{envelopeKey: envelopeKey, p: &infoPrinterImpl{
+			kc: &fixedKeychainsGetter{err: errors.New("some get error")},
+		}},
+
+		// author get error
+		{envelopeKey: envelopeKey, p: &infoPrinterImpl{
+			ag: &fixedAuthorGetter{err: errors.New("some get error")},
+			kc: &fixedKeychainsGetter{},
+		}},
+
+		// info error
+		{envelopeKey: envelopeKey, p: &infoPrinterImpl{
+			ag: &fixedAuthorGetter{logger: logging.NewDevInfoLogger()},
+			ai: &fixedAuthorInfoGetter{err: errors.New("some info error")},
+			kc: &fixedKeychainsGetter{},
+		}},
+	}
+	for i, c := range cases {
+		viper.Set(infoEnvelopeKeyFlag, c.envelopeKey)
+		err := c.p.print(new(bytes.Buffer))
+		assert.NotNil(t, err, i)
+		if c.expected != nil {
+			assert.Equal(t, c.expected, err, i)
+		}
+	}
+}
+
+type fixedAuthorInfoGetter struct {
+	metadata *api.EntryMetadata
+	err      error
+
+	envelopeKey id.ID
+}
+
+func (f *fixedAuthorInfoGetter) info(author *lauthor.Author, envelopeKey id.ID) (
+	*api.EntryMetadata, error) {
+	f.envelopeKey = envelopeKey
+	return f.metadata, f.err
+}
@@ -79,7 +79,7 @@ func (t *ioTesterImpl) test(author *author.Author, logger *zap.Logger) error {
 		}
 
 		uploadedBuf := bytes.NewReader(contents)
-		envelopeKey, err := t.au.upload(author, uploadedBuf, mediaType)
+		envelopeKey, err := t.au.upload(author, uploadedBuf, mediaType, nil)
 		if err != nil {
 			return err
 		}
@@ -101,7 +101,7 @@ type fixedAuthorUploaderDownloader struct {
 }
 
 func (f *fixedAuthorUploaderDownloader) upload(
-	author *lauthor.Author, content io.Reader, mediaType string,
+	author *lauthor.Author, content io.Reader, mediaType string, opts *lauthor.UploadOptions,
 ) (id.ID, error) {
 	if f.uploadErr != nil {
 		return nil, f.uploadErr
@@ -0,0 +1,510 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/io/common"
+	"github.com/drausin/libri/libri/common/ecid"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	loadConcurrencyFlag = "loadConcurrency"
+	loadDurationFlag    = "loadDuration"
+	loadMinSizeFlag     = "loadMinSize"
+	loadMaxSizeFlag     = "loadMaxSize"
+	loadSizeDistFlag    = "loadSizeDist"
+	loadReadFracFlag    = "loadReadFraction"
+	loadShareFracFlag   = "loadShareFraction"
+	loadFormatFlag      = "loadFormat"
+	loadOutputFlag      = "loadOutput"
+)
+
+const (
+	uniformSizeDist     = "uniform"
+	exponentialSizeDist = "exponential"
+
+	jsonFormat = "json"
+	csvFormat  = "csv"
+
+	uploadOp   = "upload"
+	downloadOp = "download"
+	shareOp    = "share"
+	allOps     = "all"
+)
+
+var (
+	errInvalidConcurrency = errors.New("concurrency must be positive")
+	errInvalidDuration    = errors.New("duration must be positive")
+	errInvalidSizes       = errors.New("min content size must be positive and <= max size")
+	errInvalidSizeDist    = errors.New("size distribution must be uniform or exponential")
+	errInvalidFraction    = errors.New("read and share fractions must be in [0, 1]")
+	errInvalidFormat      = errors.New("output format must be json or csv")
+)
+
+// loadCmd represents the load command
+var loadCmd = &cobra.Command{
+	Use:   "load",
+	Short: "measure latency and throughput of librarians under a configurable workload",
+	Long: `measure latency and throughput of librarians under a configurable workload
+
+Concurrent workers upload, download, and share random entries for the given duration. Each
+worker picks a download with probability --loadReadFraction (once there is something to
+download) and otherwise uploads a new entry, which is then shared with a random reader with
+probability --loadShareFraction. Content sizes are drawn from a uniform or exponential
+distribution between --loadMinSize and --loadMaxSize bytes.
+
+The report includes the number of operations, errors (by gRPC code), throughput, and the
+p50/p95/p99 latencies of each operation type and is written as JSON or CSV to stdout or
+--loadOutput, so runs against different cluster sizes can be compared.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		params, err := getLoadParameters()
+		if err != nil {
+			return err
+		}
+		author, logger, err := newTestAuthorGetter().get()
+		if err != nil {
+			return err
+		}
+		report := newLoadTester(params).test(author, logger)
+		return writeLoadReport(report, viper.GetString(loadFormatFlag),
+			viper.GetString(loadOutputFlag))
+	},
+}
+
+func init() {
+	testCmd.AddCommand(loadCmd)
+
+	loadCmd.Flags().IntP(loadConcurrencyFlag, "c", 4, "number of concurrent workers")
+	loadCmd.Flags().Int(loadDurationFlag, 60, "duration (seconds) of the load test")
+	loadCmd.Flags().Int(loadMinSizeFlag, minContentSize, "min content size (bytes)")
+	loadCmd.Flags().Int(loadMaxSizeFlag, maxContentSize, "max content size (bytes)")
+	loadCmd.Flags().String(loadSizeDistFlag, uniformSizeDist,
+		"content size distribution (uniform|exponential)")
+	loadCmd.Flags().Float64(loadReadFracFlag, 0.5, "fraction of operations that are downloads")
+	loadCmd.Flags().Float64(loadShareFracFlag, 0.0, "fraction of uploads that are also shared")
+	loadCmd.Flags().String(loadFormatFlag, jsonFormat, "report format (json|csv)")
+	loadCmd.Flags().StringP(loadOutputFlag, "o", "", "report file path (default stdout)")
+
+	// bind viper flags
+	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
+	viper.AutomaticEnv()             // read in environment variables that match
+	cerrors.MaybePanic(viper.BindPFlags(loadCmd.Flags()))
+}
+
+// loadParameters define the workload of a load test.
+type loadParameters struct {
+	concurrency   int
+	duration      time.Duration
+	minSize       int
+	maxSize       int
+	sizeDist      string
+	readFraction  float64
+	shareFraction float64
+}
+
+func getLoadParameters() (*loadParameters, error) {
+	params := &loadParameters{
+		concurrency:   viper.GetInt(loadConcurrencyFlag),
+		duration:      time.Duration(viper.GetInt(loadDurationFlag)) * time.Second,
+		minSize:       viper.GetInt(loadMinSizeFlag),
+		maxSize:       viper.GetInt(loadMaxSizeFlag),
+		sizeDist:      viper.GetString(loadSizeDistFlag),
+		readFraction:  viper.GetFloat64(loadReadFracFlag),
+		shareFraction: viper.GetFloat64(loadShareFracFlag),
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	if format := viper.GetString(loadFormatFlag); format != jsonFormat && format != csvFormat {
+		return nil, errInvalidFormat
+	}
+	return params, nil
+}
+
+func (p *loadParameters) validate() error {
+	if p.concurrency <= 0 {
+		return errInvalidConcurrency
+	}
+	if p.duration <= 0 {
+		return errInvalidDuration
+	}
+	if p.minSize <= 0 || p.minSize > p.maxSize {
+		return errInvalidSizes
+	}
+	if p.sizeDist != uniformSizeDist && p.sizeDist != exponentialSizeDist {
+		return errInvalidSizeDist
+	}
+	if p.readFraction < 0 || p.readFraction > 1 || p.shareFraction < 0 || p.shareFraction > 1 {
+		return errInvalidFraction
+	}
+	return nil
+}
+
+// sampleSize returns a random content size according to the size distribution.
+func (p *loadParameters) sampleSize(rng *rand.Rand) int {
+	span := p.maxSize - p.minSize
+	if span == 0 {
+		return p.minSize
+	}
+	if p.sizeDist == exponentialSizeDist {
+		// mean at 1/8 of the range, so most entries are small but a few are large
+		offset := int(rng.ExpFloat64() * float64(span) / 8)
+		if offset > span {
+			offset = span
+		}
+		return p.minSize + offset
+	}
+	return p.minSize + rng.Intn(span+1)
+}
+
+// authorSharer just wraps an *author.Author Share call for the same reason as authorUploader
+type authorSharer interface {
+	share(author *author.Author, envelopeKey id.ID, readerPub *ecdsa.PublicKey) (id.ID, error)
+}
+
+type authorSharerImpl struct{}
+
+func (*authorSharerImpl) share(
+	author *author.Author, envelopeKey id.ID, readerPub *ecdsa.PublicKey,
+) (id.ID, error) {
+	_, sharedEnvKey, err := author.Share(envelopeKey, readerPub)
+	return sharedEnvKey, err
+}
+
+type loadTester interface {
+	test(author *author.Author, logger *zap.Logger) *loadReport
+}
+
+func newLoadTester(params *loadParameters) loadTester {
+	return &loadTesterImpl{
+		params: params,
+		au:     &authorUploaderImpl{},
+		ad:     &authorDownloaderImpl{},
+		as:     &authorSharerImpl{},
+	}
+}
+
+type loadTesterImpl struct {
+	params *loadParameters
+	au     authorUploader
+	ad     authorDownloader
+	as     authorSharer
+}
+
+func (t *loadTesterImpl) test(author *author.Author, logger *zap.Logger) *loadReport {
+	rec := newLoadRecorder()
+	keys := &uploadedKeys{}
+	start := time.Now()
+	deadline := start.Add(t.params.duration)
+	logger.Info("starting load test",
+		zap.Int("concurrency", t.params.concurrency),
+		zap.Duration("duration", t.params.duration),
+	)
+
+	var wg sync.WaitGroup
+	for c := 0; c < t.params.concurrency; c++ {
+		wg.Add(1)
+		go func(rng *rand.Rand) {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				t.doOp(author, rng, keys, rec)
+			}
+		}(rand.New(rand.NewSource(int64(c))))
+	}
+	wg.Wait()
+
+	report := rec.report(time.Since(start))
+	for _, s := range report.Ops {
+		logger.Info("finished load test operations",
+			zap.String("op", s.Op),
+			zap.Int("n_ops", s.NOps),
+			zap.Int("n_errors", s.NErrors),
+			zap.Float64("throughput", s.Throughput),
+			zap.Float64("p99_latency_ms", s.P99LatencyMs),
+		)
+	}
+	return report
+}
+
+// doOp performs a single random download or upload (and maybe share) and records the result.
+func (t *loadTesterImpl) doOp(
+	author *author.Author, rng *rand.Rand, keys *uploadedKeys, rec *loadRecorder,
+) {
+	if rng.Float64() < t.params.readFraction {
+		if envKey := keys.sample(rng); envKey != nil {
+			start := time.Now()
+			n := &countingWriter{}
+			err := t.ad.download(author, n, envKey)
+			rec.record(downloadOp, time.Since(start), n.n, err)
+			return
+		}
+	}
+
+	size := t.params.sampleSize(rng)
+	contents := common.NewCompressableBytes(rng, size)
+	start := time.Now()
+	envKey, err := t.au.upload(author, contents, "application/octet-stream", nil)
+	rec.record(uploadOp, time.Since(start), int64(size), err)
+	if err != nil {
+		return
+	}
+	keys.add(envKey)
+
+	if rng.Float64() < t.params.shareFraction {
+		readerPub := &ecid.NewPseudoRandom(rng).Key().PublicKey
+		start = time.Now()
+		_, err = t.as.share(author, envKey, readerPub)
+		rec.record(shareOp, time.Since(start), 0, err)
+	}
+}
+
+// uploadedKeys are the envelope keys of successfully uploaded entries, which downloads sample
+// from.
+type uploadedKeys struct {
+	keys []id.ID
+	mu   sync.Mutex
+}
+
+func (k *uploadedKeys) add(envKey id.ID) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = append(k.keys, envKey)
+}
+
+func (k *uploadedKeys) sample(rng *rand.Rand) id.ID {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.keys) == 0 {
+		return nil
+	}
+	return k.keys[rng.Intn(len(k.keys))]
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// loadRecorder collects the latencies, sizes, and errors of each operation.
+type loadRecorder struct {
+	latencies map[string][]time.Duration
+	nBytes    map[string]int64
+	errors    map[string]map[string]int
+	mu        sync.Mutex
+}
+
+func newLoadRecorder() *loadRecorder {
+	return &loadRecorder{
+		latencies: make(map[string][]time.Duration),
+		nBytes:    make(map[string]int64),
+		errors:    make(map[string]map[string]int),
+	}
+}
+
+func (r *loadRecorder) record(op string, latency time.Duration, nBytes int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		if _, in := r.errors[op]; !in {
+			r.errors[op] = make(map[string]int)
+		}
+		r.errors[op][errorClass(err)]++
+		return
+	}
+	r.latencies[op] = append(r.latencies[op], latency)
+	r.nBytes[op] += nBytes
+}
+
+// errorClass returns the gRPC code of the error (Unknown for non-gRPC errors).
+func errorClass(err error) string {
+	return status.Code(err).String()
+}
+
+// loadReport summarizes the results of a load test.
+type loadReport struct {
+	DurationSecs float64         `json:"duration_secs"`
+	Ops          []*loadOpReport `json:"ops"`
+}
+
+// loadOpReport summarizes the results of one operation type. Latencies only include successful
+// operations.
+type loadOpReport struct {
+	Op            string         `json:"op"`
+	NOps          int            `json:"n_ops"`
+	NErrors       int            `json:"n_errors"`
+	Throughput    float64        `json:"throughput_ops_per_sec"`
+	BytesPerSec   float64        `json:"throughput_bytes_per_sec"`
+	P50LatencyMs  float64        `json:"p50_latency_ms"`
+	P95LatencyMs  float64        `json:"p95_latency_ms"`
+	P99LatencyMs  float64        `json:"p99_latency_ms"`
+	MaxLatencyMs  float64        `json:"max_latency_ms"`
+	ErrorsByClass map[string]int `json:"errors_by_class,omitempty"`
+}
+
+func (r *loadRecorder) report(elapsed time.Duration) *loadReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	secs := elapsed.Seconds()
+	report := &loadReport{DurationSecs: secs, Ops: make([]*loadOpReport, 0, 4)}
+	all := &loadOpReport{Op: allOps, ErrorsByClass: make(map[string]int)}
+	allLatencies := make([]time.Duration, 0)
+	var allBytes int64
+	for _, op := range []string{uploadOp, downloadOp, shareOp} {
+		s := newLoadOpReport(op, r.latencies[op], r.nBytes[op], r.errors[op], secs)
+		if s.NOps == 0 && s.NErrors == 0 {
+			continue
+		}
+		report.Ops = append(report.Ops, s)
+		allLatencies = append(allLatencies, r.latencies[op]...)
+		allBytes += r.nBytes[op]
+		for class, n := range r.errors[op] {
+			all.ErrorsByClass[class] += n
+		}
+	}
+	all = newLoadOpReport(allOps, allLatencies, allBytes, all.ErrorsByClass, secs)
+	report.Ops = append(report.Ops, all)
+	return report
+}
+
+func newLoadOpReport(
+	op string, latencies []time.Duration, nBytes int64, errs map[string]int, secs float64,
+) *loadOpReport {
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	s := &loadOpReport{
+		Op:            op,
+		NOps:          len(sorted),
+		P50LatencyMs:  percentileMs(sorted, 0.50),
+		P95LatencyMs:  percentileMs(sorted, 0.95),
+		P99LatencyMs:  percentileMs(sorted, 0.99),
+		MaxLatencyMs:  percentileMs(sorted, 1.0),
+		ErrorsByClass: errs,
+	}
+	for _, n := range errs {
+		s.NErrors += n
+	}
+	if secs > 0 {
+		s.Throughput = float64(s.NOps) / secs
+		s.BytesPerSec = float64(nBytes) / secs
+	}
+	return s
+}
+
+// percentileMs returns the q-quantile (nearest rank) of the sorted latencies in milliseconds.
+func percentileMs(sorted []time.Duration, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return float64(sorted[rank]) / float64(time.Millisecond)
+}
+
+func writeLoadReport(report *loadReport, format, outFilepath string) error {
+	if outFilepath == "" {
+		return writeLoadReportTo(os.Stdout, report, format)
+	}
+	file, err := os.Create(outFilepath)
+	if err != nil {
+		return err
+	}
+	if err := writeLoadReportTo(file, report, format); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func writeLoadReportTo(w io.Writer, report *loadReport, format string) error {
+	switch format {
+	case jsonFormat:
+		return writeLoadReportJSON(w, report)
+	case csvFormat:
+		return writeLoadReportCSV(w, report)
+	default:
+		return errInvalidFormat
+	}
+}
+
+func writeLoadReportJSON(w io.Writer, report *loadReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+var loadReportCSVHeader = []string{
+	"op", "n_ops", "n_errors", "throughput_ops_per_sec", "throughput_bytes_per_sec",
+	"p50_latency_ms", "p95_latency_ms", "p99_latency_ms", "max_latency_ms", "errors_by_class",
+}
+
+func writeLoadReportCSV(w io.Writer, report *loadReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(loadReportCSVHeader); err != nil {
+		return err
+	}
+	for _, s := range report.Ops {
+		record := []string{
+			s.Op,
+			strconv.Itoa(s.NOps),
+			strconv.Itoa(s.NErrors),
+			formatFloat(s.Throughput),
+			formatFloat(s.BytesPerSec),
+			formatFloat(s.P50LatencyMs),
+			formatFloat(s.P95LatencyMs),
+			formatFloat(s.P99LatencyMs),
+			formatFloat(s.MaxLatencyMs),
+			formatErrorClasses(s.ErrorsByClass),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(x, 'f', 3, 64)
+}
+
+// formatErrorClasses formats error counts as "class1=n1;class2=n2" in class order.
+func formatErrorClasses(errs map[string]int) string {
+	classes := make([]string, 0, len(errs))
+	for class := range errs {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	formatted := ""
+	for i, class := range classes {
+		if i > 0 {
+			formatted += ";"
+		}
+		formatted += fmt.Sprintf("%s=%d", class, errs[class])
+	}
+	return formatted
+}
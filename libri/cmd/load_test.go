@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/logging"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoadCmd_err(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "test-author-data-dir")
+	defer func() { err = os.RemoveAll(dataDir) }()
+	viper.Set(dataDirFlag, dataDir)
+	setDefaultLoadFlags()
+
+	// check getLoadParameters() error bubbles up
+	viper.Set(loadConcurrencyFlag, 0)
+	err = loadCmd.RunE(loadCmd, []string{})
+	assert.Equal(t, errInvalidConcurrency, err)
+
+	// check newTestAuthorGetter() error bubbles up
+	viper.Set(loadConcurrencyFlag, 1)
+	viper.Set(testLibrariansFlag, "bad librarians address")
+	err = loadCmd.RunE(loadCmd, []string{})
+	assert.NotNil(t, err)
+}
+
+func TestLoadCmd_execute(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "test-author-data-dir")
+	defer func() { err = os.RemoveAll(dataDir) }()
+	viper.Set(dataDirFlag, dataDir)
+	setDefaultLoadFlags()
+
+	// check command executes through the root command, with its flags merged with the root's
+	// persistent flags
+	viper.Set(loadConcurrencyFlag, 0)
+	RootCmd.SetArgs([]string{"test", "load", "--" + loadDurationFlag, "1"})
+	RootCmd.SetOutput(ioutil.Discard)
+	defer RootCmd.SetArgs(nil)
+	defer RootCmd.SetOutput(nil)
+	err = RootCmd.Execute()
+	assert.Equal(t, errInvalidConcurrency, err)
+}
+
+func TestGetLoadParameters_ok(t *testing.T) {
+	setDefaultLoadFlags()
+	params, err := getLoadParameters()
+	assert.Nil(t, err)
+	assert.Equal(t, &loadParameters{
+		concurrency:   2,
+		duration:      time.Second,
+		minSize:       32,
+		maxSize:       1024,
+		sizeDist:      uniformSizeDist,
+		readFraction:  0.5,
+		shareFraction: 0.25,
+	}, params)
+}
+
+func TestGetLoadParameters_err(t *testing.T) {
+	cases := []struct {
+		flag     string
+		value    interface{}
+		expected error
+	}{
+		{loadConcurrencyFlag, 0, errInvalidConcurrency},
+		{loadDurationFlag, 0, errInvalidDuration},
+		{loadMinSizeFlag, 0, errInvalidSizes},
+		{loadMinSizeFlag, 2048, errInvalidSizes},
+		{loadSizeDistFlag, "bimodal", errInvalidSizeDist},
+		{loadReadFracFlag, 1.5, errInvalidFraction},
+		{loadShareFracFlag, -0.1, errInvalidFraction},
+		{loadFormatFlag, "xml", errInvalidFormat},
+	}
+	for _, c := range cases {
+		setDefaultLoadFlags()
+		viper.Set(c.flag, c.value)
+		params, err := getLoadParameters()
+		assert.Equal(t, c.expected, err, c.flag)
+		assert.Nil(t, params, c.flag)
+	}
+}
+
+func TestLoadParameters_sampleSize(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, dist := range []string{uniformSizeDist, exponentialSizeDist} {
+		p := &loadParameters{minSize: 32, maxSize: 1024, sizeDist: dist}
+		for c := 0; c < 1000; c++ {
+			size := p.sampleSize(rng)
+			assert.True(t, size >= p.minSize, dist)
+			assert.True(t, size <= p.maxSize, dist)
+		}
+	}
+
+	p := &loadParameters{minSize: 32, maxSize: 32, sizeDist: uniformSizeDist}
+	assert.Equal(t, 32, p.sampleSize(rng))
+}
+
+func TestLoadTester_test_ok(t *testing.T) {
+	params := &loadParameters{
+		concurrency:   3,
+		duration:      100 * time.Millisecond,
+		minSize:       32,
+		maxSize:       1024,
+		sizeDist:      exponentialSizeDist,
+		readFraction:  0.5,
+		shareFraction: 0.5,
+	}
+	aud := newConcurrentAuthorUploaderDownloader()
+	lt := &loadTesterImpl{params: params, au: aud, ad: aud, as: aud}
+
+	report := lt.test(nil, logging.NewDevInfoLogger())
+	assert.True(t, report.DurationSecs >= params.duration.Seconds())
+	ops := reportOps(report)
+	assert.Len(t, ops, 4)
+	for _, op := range []string{uploadOp, downloadOp, shareOp, allOps} {
+		s := ops[op]
+		assert.True(t, s.NOps > 0, op)
+		assert.Zero(t, s.NErrors, op)
+		assert.True(t, s.Throughput > 0, op)
+		assert.True(t, s.P50LatencyMs <= s.P95LatencyMs, op)
+		assert.True(t, s.P95LatencyMs <= s.P99LatencyMs, op)
+		assert.True(t, s.P99LatencyMs <= s.MaxLatencyMs, op)
+	}
+	assert.Equal(t, ops[uploadOp].NOps+ops[downloadOp].NOps+ops[shareOp].NOps,
+		ops[allOps].NOps)
+	assert.True(t, ops[uploadOp].BytesPerSec > 0)
+	assert.True(t, ops[downloadOp].BytesPerSec > 0)
+}
+
+func TestLoadTester_test_err(t *testing.T) {
+	params := &loadParameters{
+		concurrency:  2,
+		duration:     50 * time.Millisecond,
+		minSize:      32,
+		maxSize:      32,
+		sizeDist:     uniformSizeDist,
+		readFraction: 0.5,
+	}
+	aud := newConcurrentAuthorUploaderDownloader()
+	aud.uploadErr = status.Error(codes.Unavailable, "some upload err")
+	lt := &loadTesterImpl{params: params, au: aud, ad: aud, as: aud}
+
+	report := lt.test(nil, logging.NewDevInfoLogger())
+	ops := reportOps(report)
+
+	// no uploads succeed, so nothing is ever downloaded
+	assert.Len(t, ops, 2)
+	assert.Zero(t, ops[uploadOp].NOps)
+	assert.True(t, ops[uploadOp].NErrors > 0)
+	assert.Equal(t, ops[uploadOp].NErrors, ops[uploadOp].ErrorsByClass["Unavailable"])
+	assert.Equal(t, ops[uploadOp].NErrors, ops[allOps].NErrors)
+}
+
+func TestLoadRecorder_report(t *testing.T) {
+	rec := newLoadRecorder()
+	for i := 1; i <= 100; i++ {
+		rec.record(uploadOp, time.Duration(i)*time.Millisecond, 10, nil)
+	}
+	rec.record(uploadOp, 0, 0, errors.New("some non-grpc err"))
+	rec.record(downloadOp, time.Millisecond, 10, nil)
+	rec.record(downloadOp, 0, 0, status.Error(codes.NotFound, "some grpc err"))
+
+	report := rec.report(10 * time.Second)
+	ops := reportOps(report)
+	assert.Len(t, ops, 3)
+
+	upload := ops[uploadOp]
+	assert.Equal(t, 100, upload.NOps)
+	assert.Equal(t, 1, upload.NErrors)
+	assert.Equal(t, map[string]int{"Unknown": 1}, upload.ErrorsByClass)
+	assert.Equal(t, 10.0, upload.Throughput)
+	assert.Equal(t, 100.0, upload.BytesPerSec)
+	assert.Equal(t, 50.0, upload.P50LatencyMs)
+	assert.Equal(t, 95.0, upload.P95LatencyMs)
+	assert.Equal(t, 99.0, upload.P99LatencyMs)
+	assert.Equal(t, 100.0, upload.MaxLatencyMs)
+
+	all := ops[allOps]
+	assert.Equal(t, 101, all.NOps)
+	assert.Equal(t, 2, all.NErrors)
+	assert.Equal(t, map[string]int{"Unknown": 1, "NotFound": 1}, all.ErrorsByClass)
+}
+
+func TestPercentileMs(t *testing.T) {
+	assert.Zero(t, percentileMs(nil, 0.5))
+	sorted := []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}
+	assert.Equal(t, 1.0, percentileMs(sorted, 0))
+	assert.Equal(t, 2.0, percentileMs(sorted, 0.5))
+	assert.Equal(t, 3.0, percentileMs(sorted, 0.99))
+}
+
+func TestWriteLoadReport_ok(t *testing.T) {
+	report := &loadReport{
+		DurationSecs: 10,
+		Ops: []*loadOpReport{
+			{Op: uploadOp, NOps: 4, NErrors: 3, Throughput: 0.4,
+				ErrorsByClass: map[string]int{"Unavailable": 2, "DeadlineExceeded": 1}},
+			{Op: allOps, NOps: 4, NErrors: 3, Throughput: 0.4},
+		},
+	}
+	outDir, err := ioutil.TempDir("", "test-load-report")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(outDir)) }()
+
+	// json
+	jsonFilepath := filepath.Join(outDir, "report.json")
+	err = writeLoadReport(report, jsonFormat, jsonFilepath)
+	assert.Nil(t, err)
+	jsonBytes, err := ioutil.ReadFile(jsonFilepath)
+	assert.Nil(t, err)
+	read := &loadReport{}
+	assert.Nil(t, json.Unmarshal(jsonBytes, read))
+	assert.Equal(t, report, read)
+
+	// csv
+	csvFilepath := filepath.Join(outDir, "report.csv")
+	err = writeLoadReport(report, csvFormat, csvFilepath)
+	assert.Nil(t, err)
+	csvBytes, err := ioutil.ReadFile(csvFilepath)
+	assert.Nil(t, err)
+	records, err := csv.NewReader(bytes.NewReader(csvBytes)).ReadAll()
+	assert.Nil(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, loadReportCSVHeader, records[0])
+	assert.Equal(t, uploadOp, records[1][0])
+	assert.Equal(t, "0.400", records[1][3])
+	assert.Equal(t, "DeadlineExceeded=1;Unavailable=2", records[1][9])
+	assert.Equal(t, "", records[2][9])
+}
+
+func TestWriteLoadReport_err(t *testing.T) {
+	report := &loadReport{}
+
+	// bad format
+	err := writeLoadReportTo(ioutil.Discard, report, "xml")
+	assert.Equal(t, errInvalidFormat, err)
+
+	// bad output filepath
+	err = writeLoadReport(report, jsonFormat, "/nonexistent/dir/report.json")
+	assert.NotNil(t, err)
+}
+
+func setDefaultLoadFlags() {
+	viper.Set(loadConcurrencyFlag, 2)
+	viper.Set(loadDurationFlag, 1)
+	viper.Set(loadMinSizeFlag, 32)
+	viper.Set(loadMaxSizeFlag, 1024)
+	viper.Set(loadSizeDistFlag, uniformSizeDist)
+	viper.Set(loadReadFracFlag, 0.5)
+	viper.Set(loadShareFracFlag, 0.25)
+	viper.Set(loadFormatFlag, jsonFormat)
+	viper.Set(loadOutputFlag, "")
+}
+
+func reportOps(report *loadReport) map[string]*loadOpReport {
+	ops := make(map[string]*loadOpReport)
+	for _, s := range report.Ops {
+		ops[s.Op] = s
+	}
+	return ops
+}
+
+// concurrentAuthorUploaderDownloader is like fixedAuthorUploaderDownloader but safe for
+// concurrent use and also shares.
+type concurrentAuthorUploaderDownloader struct {
+	rng       *rand.Rand
+	uploaded  map[string][]byte
+	uploadErr error
+	mu        sync.Mutex
+}
+
+func newConcurrentAuthorUploaderDownloader() *concurrentAuthorUploaderDownloader {
+	return &concurrentAuthorUploaderDownloader{
+		rng:      rand.New(rand.NewSource(0)),
+		uploaded: make(map[string][]byte),
+	}
+}
+
+func (f *concurrentAuthorUploaderDownloader) upload(
+	author *lauthor.Author, content io.Reader, mediaType string, opts *lauthor.UploadOptions,
+) (id.ID, error) {
+	if f.uploadErr != nil {
+		return nil, f.uploadErr
+	}
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(content); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := id.NewPseudoRandom(f.rng)
+	f.uploaded[key.String()] = buf.Bytes()
+	return key, nil
+}
+
+func (f *concurrentAuthorUploaderDownloader) download(
+	author *lauthor.Author, content io.Writer, envelopeKey id.ID,
+) error {
+	f.mu.Lock()
+	doc, in := f.uploaded[envelopeKey.String()]
+	f.mu.Unlock()
+	if !in {
+		return status.Error(codes.NotFound, "document not found")
+	}
+	_, err := io.Copy(content, bytes.NewReader(doc))
+	return err
+}
+
+func (f *concurrentAuthorUploaderDownloader) share(
+	author *lauthor.Author, envelopeKey id.ID, readerPub *ecdsa.PublicKey,
+) (id.ID, error) {
+	if readerPub == nil {
+		return nil, errors.New("missing reader public key")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return id.NewPseudoRandom(f.rng), nil
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	clogging "github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/comm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	issuerOrgIDFlag = "issuerOrganizationID"
+	certPubKeyFlag  = "certPubKey"
+	certTTLFlag     = "certTTL"
+	certOutFileFlag = "certOutFile"
+)
+
+var (
+	errMissingIssuerOrgID = errors.New("missing issuer organization ID private key")
+	errMissingCertPubKey  = errors.New("missing public key to certify")
+	errMissingCertOutFile = errors.New("missing certificate output file")
+)
+
+// orgCmd represents the org command
+var orgCmd = &cobra.Command{
+	Use:   "org",
+	Short: "manage the certificates an organization issues to its peers and authors",
+	Long: `Manage the certificates binding peer and author public keys to an organization. Librarians
+started with --requireCertificates refuse requests without a valid certificate from an organization
+in their trust list.`,
+}
+
+// orgIssueCmd represents the org issue command
+var orgIssueCmd = &cobra.Command{
+	Use:   "issue",
+	Short: "issue a certificate for a peer or author public key",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newCertIssuer().issue(os.Stdout)
+	},
+}
+
+// orgRevokeCmd represents the org revoke command
+var orgRevokeCmd = &cobra.Command{
+	Use:   "revoke SERIAL...",
+	Short: "revoke certificates by adding their serial numbers to the trust list",
+	Long: `Revoke certificates by adding their serial numbers to the trust list file, creating it if
+necessary. A running librarian reloads its trust list when it receives a SIGHUP.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newTrustListEditor().edit(args, func(tl *comm.TrustList, serial id.ID) error {
+			tl.Revoke(serial)
+			return nil
+		})
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(orgCmd)
+	orgCmd.AddCommand(orgIssueCmd)
+	orgCmd.AddCommand(orgRevokeCmd)
+
+	orgIssueCmd.Flags().String(issuerOrgIDFlag, "",
+		"[sensitive] hex value of issuing organization ID private key")
+	orgIssueCmd.Flags().String(certPubKeyFlag, "",
+		"hex value of the peer or author public key to certify")
+	orgIssueCmd.Flags().Duration(certTTLFlag, client.DefaultCertificateTTL,
+		"time after which the certificate expires")
+	orgIssueCmd.Flags().String(certOutFileFlag, "",
+		"file to write the certificate to")
+
+	// bind viper flags
+	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
+	viper.AutomaticEnv()             // read in environment variables that match
+	cerrors.MaybePanic(viper.BindPFlags(orgIssueCmd.Flags()))
+}
+
+type certIssuer struct {
+	orgIDPrivHex string
+	pubKeyHex    string
+	ttl          time.Duration
+	outFile      string
+}
+
+func newCertIssuer() *certIssuer {
+	return &certIssuer{
+		orgIDPrivHex: viper.GetString(issuerOrgIDFlag),
+		pubKeyHex:    viper.GetString(certPubKeyFlag),
+		ttl:          viper.GetDuration(certTTLFlag),
+		outFile:      viper.GetString(certOutFileFlag),
+	}
+}
+
+func (i *certIssuer) issue(w io.Writer) error {
+	if i.orgIDPrivHex == "" {
+		return errMissingIssuerOrgID
+	}
+	if i.pubKeyHex == "" {
+		return errMissingCertPubKey
+	}
+	if i.outFile == "" {
+		return errMissingCertOutFile
+	}
+	logger := clogging.NewDevLogger(getLogLevel())
+	orgID, err := parseOrgID(logger, i.orgIDPrivHex)
+	if err != nil {
+		return err
+	}
+	pubKey, err := hex.DecodeString(strings.TrimSpace(i.pubKeyHex))
+	if err != nil {
+		return err
+	}
+	cert, err := client.NewCertificate(orgID, pubKey, i.ttl)
+	if err != nil {
+		return err
+	}
+	if err := client.WriteCertificate(i.outFile, cert); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "organization ID: %s\nserial: %s\nexpires: %s\n",
+		orgID.ID().String(), id.FromBytes(cert.Serial).String(),
+		time.Unix(cert.ExpiresAt, 0).UTC().Format(time.RFC3339))
+	return err
+}
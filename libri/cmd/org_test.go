@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/comm"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertIssuer_issue_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	orgID, peerID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	dir, err := ioutil.TempDir("", "org-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+
+	i := &certIssuer{
+		orgIDPrivHex: hex.EncodeToString(orgID.Key().D.Bytes()),
+		pubKeyHex:    hex.EncodeToString(peerID.PublicKeyBytes()),
+		ttl:          client.DefaultCertificateTTL,
+		outFile:      filepath.Join(dir, "peer.cert"),
+	}
+	buf := new(bytes.Buffer)
+	err = i.issue(buf)
+	assert.Nil(t, err)
+
+	cert, err := client.ReadCertificate(i.outFile)
+	assert.Nil(t, err)
+	err = client.NewCertificateVerifier(client.DefaultClockSkew).Verify(cert,
+		peerID.PublicKeyBytes())
+	assert.Nil(t, err)
+	assert.Equal(t, orgID.PublicKeyBytes(), cert.OrgPubKey)
+	assert.Contains(t, buf.String(), orgID.ID().String())
+	assert.Contains(t, buf.String(), id.FromBytes(cert.Serial).String())
+}
+
+func TestCertIssuer_issue_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	orgID, peerID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	dir, err := ioutil.TempDir("", "org-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	orgIDPrivHex := hex.EncodeToString(orgID.Key().D.Bytes())
+	pubKeyHex := hex.EncodeToString(peerID.PublicKeyBytes())
+	outFile := filepath.Join(dir, "peer.cert")
+	ttl := client.DefaultCertificateTTL
+
+	cases := map[string]*certIssuer{
+		"missing org ID":   {pubKeyHex: pubKeyHex, ttl: ttl, outFile: outFile},
+		"missing pub key":  {orgIDPrivHex: orgIDPrivHex, ttl: ttl, outFile: outFile},
+		"missing out file": {orgIDPrivHex: orgIDPrivHex, pubKeyHex: pubKeyHex, ttl: ttl},
+		"bad org ID": {orgIDPrivHex: "not hex", pubKeyHex: pubKeyHex, ttl: ttl,
+			outFile: outFile},
+		"bad pub key": {orgIDPrivHex: orgIDPrivHex, pubKeyHex: "not hex", ttl: ttl,
+			outFile: outFile},
+		"bad TTL": {orgIDPrivHex: orgIDPrivHex, pubKeyHex: pubKeyHex, outFile: outFile},
+		"bad out file": {orgIDPrivHex: orgIDPrivHex, pubKeyHex: pubKeyHex, ttl: ttl,
+			outFile: filepath.Join(dir, "missing", "peer.cert")},
+	}
+	for desc, i := range cases {
+		err := i.issue(new(bytes.Buffer))
+		assert.NotNil(t, err, desc)
+	}
+	_, err = os.Stat(outFile)
+	assert.True(t, os.IsNotExist(err))
+
+	// missing flags
+	viper.Set(issuerOrgIDFlag, "")
+	err = orgIssueCmd.RunE(orgIssueCmd, []string{})
+	assert.Equal(t, errMissingIssuerOrgID, err)
+}
+
+func TestOrgRevokeCmd(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "org-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	fp := filepath.Join(dir, "trust.json")
+	serial1, serial2 := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+
+	viper.Set(trustListFileFlag, "")
+	err = orgRevokeCmd.RunE(orgRevokeCmd, []string{serial1.String()})
+	assert.Equal(t, errMissingTrustListFile, err)
+
+	viper.Set(trustListFileFlag, fp)
+	defer viper.Set(trustListFileFlag, "")
+	err = orgRevokeCmd.RunE(orgRevokeCmd, []string{serial1.String(), serial2.String()})
+	assert.Nil(t, err)
+	err = orgRevokeCmd.RunE(orgRevokeCmd, []string{serial1.String()})
+	assert.Nil(t, err)
+
+	tl, err := comm.ReadTrustList(fp)
+	assert.Nil(t, err)
+	assert.Len(t, tl.RevokedCerts, 2)
+	assert.Contains(t, tl.RevokedCerts, serial1.String())
+	assert.Contains(t, tl.RevokedCerts, serial2.String())
+
+	err = orgRevokeCmd.RunE(orgRevokeCmd, []string{"not a serial"})
+	assert.NotNil(t, err)
+}
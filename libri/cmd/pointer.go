@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	pointerEnvelopeKeyFlag = "pointerEnvelopeKey"
+	pointerKeyFlag         = "pointerKey"
+)
+
+var (
+	errMissingPointerName      = errors.New("missing pointer name")
+	errPointerNameAndKey       = errors.New("cannot resolve both a pointer name and key")
+	errMissingPointerNameOrKey = errors.New("missing pointer name or key")
+)
+
+// pointerCmd represents the author pointer command
+var pointerCmd = &cobra.Command{
+	Use:   "pointer",
+	Short: "publish and resolve named pointers to documents",
+	Long: `Publish and resolve named pointers to documents on the libri network. A pointer is
+signed by the author and keyed by the author's client public key and the pointer name, so its key
+stays the same each time it is re-published to point at a newer document.`,
+}
+
+// pointerPublishCmd represents the author pointer publish command
+var pointerPublishCmd = &cobra.Command{
+	Use:   "publish NAME",
+	Short: "point the named pointer at a document, printing the pointer key",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newPointerPublisher().publish(os.Stdout, args)
+	},
+}
+
+// pointerResolveCmd represents the author pointer resolve command
+var pointerResolveCmd = &cobra.Command{
+	Use:   "resolve [NAME]",
+	Short: "print the envelope key and sequence number of the latest version of a pointer",
+	Long: `Print the envelope key and sequence number of the latest version of a pointer, given
+either the name of a pointer published by this author or the key of a pointer published by any
+author.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newPointerResolver().resolve(os.Stdout, args)
+	},
+}
+
+func init() {
+	authorCmd.AddCommand(pointerCmd)
+	pointerCmd.AddCommand(pointerPublishCmd)
+	pointerCmd.AddCommand(pointerResolveCmd)
+
+	pointerPublishCmd.Flags().StringP(pointerEnvelopeKeyFlag, "e", "",
+		"key of envelope of document to point to")
+	pointerResolveCmd.Flags().String(pointerKeyFlag, "",
+		"key of pointer to resolve")
+
+	// bind viper flags
+	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
+	viper.AutomaticEnv()             // read in environment variables that match
+	cerrors.MaybePanic(viper.BindPFlags(pointerPublishCmd.Flags()))
+	cerrors.MaybePanic(viper.BindPFlags(pointerResolveCmd.Flags()))
+}
+
+type pointerPublisher interface {
+	publish(w io.Writer, args []string) error
+}
+
+func newPointerPublisher() pointerPublisher {
+	if dc := getDaemonClient(); dc != nil {
+		return &pointerPublisherImpl{
+			ag: &daemonAuthorGetter{},
+			ap: &daemonPointer{client: dc},
+			kc: &daemonKeychainsGetter{},
+		}
+	}
+	return &pointerPublisherImpl{
+		ag: newAuthorGetter(),
+		ap: &authorPointerImpl{},
+		kc: &keychainsGetterImpl{
+			pg: &terminalPassphraseGetter{},
+		},
+	}
+}
+
+type pointerPublisherImpl struct {
+	ag authorGetter
+	ap authorPointer
+	kc keychainsGetter
+}
+
+func (p *pointerPublisherImpl) publish(w io.Writer, args []string) error {
+	if len(args) != 1 || args[0] == "" {
+		return errMissingPointerName
+	}
+	name := args[0]
+	envelopeKeyStr := viper.GetString(pointerEnvelopeKeyFlag)
+	if envelopeKeyStr == "" {
+		return errMissingEnvelopeKey
+	}
+	envelopeKey, err := id.FromString(envelopeKeyStr)
+	if err != nil {
+		return err
+	}
+	authorKeys, selfReaderKeys, err := p.kc.get()
+	if err != nil {
+		return err
+	}
+	author, logger, err := p.ag.get(authorKeys, selfReaderKeys)
+	if err != nil {
+		return err
+	}
+	logger.Info("publishing pointer",
+		zap.String("pointer_name", name),
+		zap.Stringer("envelope_key", envelopeKey),
+	)
+	pointerKey, err := p.ap.publish(author, name, envelopeKey)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, pointerKey)
+	return err
+}
+
+type pointerResolver interface {
+	resolve(w io.Writer, args []string) error
+}
+
+func newPointerResolver() pointerResolver {
+	if dc := getDaemonClient(); dc != nil {
+		return &pointerResolverImpl{
+			ag: &daemonAuthorGetter{},
+			ap: &daemonPointer{client: dc},
+			kc: &daemonKeychainsGetter{},
+		}
+	}
+	return &pointerResolverImpl{
+		ag: newAuthorGetter(),
+		ap: &authorPointerImpl{},
+		kc: &keychainsGetterImpl{
+			pg: &terminalPassphraseGetter{},
+		},
+	}
+}
+
+type pointerResolverImpl struct {
+	ag authorGetter
+	ap authorPointer
+	kc keychainsGetter
+}
+
+func (r *pointerResolverImpl) resolve(w io.Writer, args []string) error {
+	pointerKeyStr := viper.GetString(pointerKeyFlag)
+	if len(args) > 0 && pointerKeyStr != "" {
+		return errPointerNameAndKey
+	}
+	if (len(args) == 0 || args[0] == "") && pointerKeyStr == "" {
+		return errMissingPointerNameOrKey
+	}
+	var pointerKey id.ID
+	if pointerKeyStr != "" {
+		var err error
+		if pointerKey, err = id.FromString(pointerKeyStr); err != nil {
+			return err
+		}
+	}
+	authorKeys, selfReaderKeys, err := r.kc.get()
+	if err != nil {
+		return err
+	}
+	author, logger, err := r.ag.get(authorKeys, selfReaderKeys)
+	if err != nil {
+		return err
+	}
+	var pointer *api.Pointer
+	if pointerKey != nil {
+		logger.Info("resolving pointer", zap.Stringer("pointer_key", pointerKey))
+		pointer, err = r.ap.resolveKey(author, pointerKey)
+	} else {
+		logger.Info("resolving pointer", zap.String("pointer_name", args[0]))
+		pointer, err = r.ap.resolve(author, args[0])
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\t%d\n", id.FromBytes(pointer.TargetEnvelopeKey),
+		pointer.Sequence)
+	return err
+}
+
+// authorPointer just wraps *author.Author Publish, Resolve, and ResolveKey calls for the same
+// reason as authorUploader
+type authorPointer interface {
+	publish(author *lauthor.Author, name string, envelopeKey id.ID) (id.ID, error)
+	resolve(author *lauthor.Author, name string) (*api.Pointer, error)
+	resolveKey(author *lauthor.Author, pointerKey id.ID) (*api.Pointer, error)
+}
+
+type authorPointerImpl struct{}
+
+func (*authorPointerImpl) publish(author *lauthor.Author, name string, envelopeKey id.ID) (
+	id.ID, error) {
+	_, pointerKey, err := author.Publish(name, envelopeKey)
+	return pointerKey, err
+}
+
+func (*authorPointerImpl) resolve(author *lauthor.Author, name string) (*api.Pointer, error) {
+	return author.Resolve(name)
+}
+
+func (*authorPointerImpl) resolveKey(author *lauthor.Author, pointerKey id.ID) (
+	*api.Pointer, error) {
+	return author.ResolveKey(pointerKey)
+}
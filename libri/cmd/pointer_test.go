@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPointerPublishCmd_err(t *testing.T) {
+	err := pointerPublishCmd.RunE(pointerPublishCmd, []string{})
+	assert.Equal(t, errMissingPointerName, err)
+}
+
+func TestPointerPublisher_publish_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	envelopeKey, pointerKey := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	viper.Set(pointerEnvelopeKeyFlag, envelopeKey.String())
+	defer viper.Set(pointerEnvelopeKeyFlag, "")
+
+	ap := &fixedAuthorPointer{pointerKey: pointerKey}
+	p := &pointerPublisherImpl{
+		ag: &fixedAuthorGetter{
+			author: nil, // ok since we're passing it into a mocked method anyway
+			logger: logging.NewDevInfoLogger(),
+		},
+		ap: ap,
+		kc: &fixedKeychainsGetter{}, // ok that KCs are null for same reason
+	}
+	buf := new(bytes.Buffer)
+	err := p.publish(buf, []string{"some/dataset"})
+	assert.Nil(t, err)
+	assert.Equal(t, pointerKey.String(), strings.TrimSpace(buf.String()))
+	assert.Equal(t, "some/dataset", ap.name)
+	assert.Equal(t, envelopeKey, ap.envelopeKey)
+}
+
+func TestPointerPublisher_publish_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	envelopeKey := id.NewPseudoRandom(rng).String()
+	defer viper.Set(pointerEnvelopeKeyFlag, "")
+	okAG := &fixedAuthorGetter{logger: logging.NewDevInfoLogger()}
+
+	cases := []struct {
+		args        []string
+		envelopeKey string
+		p           *pointerPublisherImpl
+		expected    error
+	}{
+		// missing name
+		{args: []string{}, envelopeKey: envelopeKey, p: &pointerPublisherImpl{},
+			expected: errMissingPointerName},
+
+		// missing envelope key
+		{args: []string{"some/dataset"}, envelopeKey: "", p: &pointerPublisherImpl{},
+			expected: errMissingEnvelopeKey},
+
+		// bad envelope key
+		{args: []string{"some/dataset"}, envelopeKey: "not an ID", p: &pointerPublisherImpl{}},
+
+		// keychains get error
+		{args: []string{"some/dataset"}, envelopeKey: envelopeKey, p: &pointerPublisherImpl{
+			kc: &fixedKeychainsGetter{err: errors.New("some get error")},
+		}},
+
+		// author get error
+		{args: []string{"some/dataset"}, envelopeKey: envelopeKey, p: &pointerPublisherImpl{
+			ag: &fixedAuthorGetter{err: errors.New("some get error")},
+			kc: &fixedKeychainsGetter{},
+		}},
+
+		// publish error
+		{args: []string{"some/dataset"}, envelopeKey: envelopeKey, p: &pointerPublisherImpl{
+			ag: okAG,
+			ap: &fixedAuthorPointer{err: errors.New("some publish error")},
+			kc: &fixedKeychainsGetter{},
+		}},
+	}
+	for i, c := range cases {
+		viper.Set(pointerEnvelopeKeyFlag, c.envelopeKey)
+		err := c.p.publish(new(bytes.Buffer), c.args)
+		assert.NotNil(t, err, i)
+		if c.expected != nil {
+			assert.Equal(t, c.expected, err, i)
+		}
+	}
+}
+
+func TestPointerResolver_resolve_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	pointer, _ := api.NewTestPointer(rng)
+	pointerKey := id.NewPseudoRandom(rng)
+	defer viper.Set(pointerKeyFlag, "")
+	expected := fmt.Sprintf("%s\t%d", id.FromBytes(pointer.TargetEnvelopeKey), pointer.Sequence)
+
+	ap := &fixedAuthorPointer{pointer: pointer}
+	r := &pointerResolverImpl{
+		ag: &fixedAuthorGetter{logger: logging.NewDevInfoLogger()},
+		ap: ap,
+		kc: &fixedKeychainsGetter{},
+	}
+
+	// by name
+	viper.Set(pointerKeyFlag, "")
+	buf := new(bytes.Buffer)
+	err := r.resolve(buf, []string{"some/dataset"})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, strings.TrimSpace(buf.String()))
+	assert.Equal(t, "some/dataset", ap.name)
+	assert.Nil(t, ap.pointerKey)
+
+	// by key
+	ap.name = ""
+	viper.Set(pointerKeyFlag, pointerKey.String())
+	buf = new(bytes.Buffer)
+	err = r.resolve(buf, []string{})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, strings.TrimSpace(buf.String()))
+	assert.Equal(t, "", ap.name)
+	assert.Equal(t, pointerKey, ap.pointerKey)
+}
+
+func TestPointerResolver_resolve_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	pointerKey := id.NewPseudoRandom(rng).String()
+	defer viper.Set(pointerKeyFlag, "")
+	okAG := &fixedAuthorGetter{logger: logging.NewDevInfoLogger()}
+
+	cases := []struct {
+		args       []string
+		pointerKey string
+		r          *pointerResolverImpl
+		expected   error
+	}{
+		// missing name and key
+		{args: []string{}, pointerKey: "", r: &pointerResolverImpl{},
+			expected: errMissingPointerNameOrKey},
+
+		// both name and key
+		{args: []string{"some/dataset"}, pointerKey: pointerKey, r: &pointerResolverImpl{},
+			expected: errPointerNameAndKey},
+
+		// bad pointer key
+		{args: []string{}, pointerKey: "not an ID", r: &pointerResolverImpl{}},
+
+		// keychains get error
+		{args: []string{"some/dataset"}, r: &pointerResolverImpl{
+			kc: &fixedKeychainsGetter{err: errors.New("some get error")},
+		}},
+
+		// author get error
+		{args: []string{"some/dataset"}, r: &pointerResolverImpl{
+			ag: &fixedAuthorGetter{err: errors.New("some get error")},
+			kc: &fixedKeychainsGetter{},
+		}},
+
+		// resolve error
+		{args: []string{"some/dataset"}, r: &pointerResolverImpl{
+			ag: okAG,
+			ap: &fixedAuthorPointer{err: errors.New("some resolve error")},
+			kc: &fixedKeychainsGetter{},
+		}},
+
+		// resolve key error
+		{args: []string{}, pointerKey: pointerKey, r: &pointerResolverImpl{
+			ag: okAG,
+			ap: &fixedAuthorPointer{err: errors.New("some resolve error")},
+			kc: &fixedKeychainsGetter{},
+		}},
+	}
+	for i, c := range cases {
+		viper.Set(pointerKeyFlag, c.pointerKey)
+		err := c.r.resolve(new(bytes.Buffer), c.args)
+		assert.NotNil(t, err, i)
+		if c.expected != nil {
+			assert.Equal(t, c.expected, err, i)
+		}
+	}
+}
+
+type fixedAuthorPointer struct {
+	pointerKey id.ID
+	pointer    *api.Pointer
+	err        error
+
+	name        string
+	envelopeKey id.ID
+}
+
+func (f *fixedAuthorPointer) publish(author *lauthor.Author, name string, envelopeKey id.ID) (
+	id.ID, error) {
+	f.name, f.envelopeKey = name, envelopeKey
+	return f.pointerKey, f.err
+}
+
+func (f *fixedAuthorPointer) resolve(author *lauthor.Author, name string) (*api.Pointer, error) {
+	f.name = name
+	return f.pointer, f.err
+}
+
+func (f *fixedAuthorPointer) resolveKey(author *lauthor.Author, pointerKey id.ID) (
+	*api.Pointer, error) {
+	f.pointerKey = pointerKey
+	return f.pointer, f.err
+}
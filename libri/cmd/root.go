@@ -12,9 +12,11 @@ import (
 )
 
 const (
-	dataDirFlag  = "dataDir"
-	logLevelFlag = "logLevel"
-	envVarPrefix = "LIBRI"
+	dataDirFlag         = "dataDir"
+	logLevelFlag        = "logLevel"
+	trustListFileFlag   = "trustListFile"
+	certificateFileFlag = "certificateFile"
+	envVarPrefix        = "LIBRI"
 )
 
 // RootCmd represents the base command when called without any subcommands
@@ -36,6 +38,11 @@ func init() {
 		"local data directory")
 	RootCmd.PersistentFlags().StringP(logLevelFlag, "l", zap.InfoLevel.String(),
 		"log level")
+	RootCmd.PersistentFlags().String(trustListFileFlag, "",
+		"JSON file of organization and peer IDs treated as known and of revoked certificates "+
+			"(all peers known if empty)")
+	RootCmd.PersistentFlags().String(certificateFileFlag, "",
+		"organization certificate file presented with requests (none if empty)")
 
 	// bind viper flags
 	viper.SetEnvPrefix("LIBRI") // look for env vars with "LIBRI_" prefix
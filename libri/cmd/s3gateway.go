@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/daemon"
+	"github.com/drausin/libri/libri/author/s3"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+)
+
+const (
+	s3HostFlag      = "s3Host"
+	s3PortFlag      = "s3Port"
+	s3IndexFileFlag = "s3IndexFile"
+	s3AccessKeyFlag = "s3AccessKey"
+	s3SecretKeyVar  = "s3SecretKey"
+	logS3Addr       = "s3_addr"
+	logS3IndexFile  = "s3_index_file"
+
+	// s3ReadHeaderTimeout is the max time to read the headers of a gateway request. There are no
+	// read or write timeouts for whole requests since objects may be arbitrarily large.
+	s3ReadHeaderTimeout = 10 * time.Second
+
+	// s3IdleTimeout is the max time to keep an idle gateway connection open between requests.
+	s3IdleTimeout = 2 * time.Minute
+)
+
+var (
+	// errS3HostNotLoopback indicates that the gateway was asked to listen on a non-loopback host
+	// without credentials to authenticate requests with.
+	errS3HostNotLoopback = errors.New("listening on a non-loopback host requires s3 gateway " +
+		"credentials to authenticate requests with")
+
+	// errMissingS3Credential indicates when only one of the access key and secret key is given.
+	errMissingS3Credential = errors.New("s3 gateway access key and secret key must be given " +
+		"together")
+)
+
+// s3GatewayCmd represents the author s3gateway command
+var s3GatewayCmd = &cobra.Command{
+	Use:   "s3gateway",
+	Short: "run an S3-compatible object gateway backed by the author",
+	Long: `Run an HTTP server implementing a subset of the S3 API (PutObject, GetObject with Range,
+HeadObject, ListObjectsV2, and DeleteObject) so tools that speak S3 can store encrypted objects in
+libri unchanged. Clients must use path-style URLs (e.g., http://localhost:9000/bucket/key).
+
+Objects are uploaded as documents via the author, and their envelope keys are indexed under their
+bucket and key names in a local index file (s3-index.json in the data dir by default). Deleting an
+object only removes it from the index.
+
+If --s3AccessKey and the LIBRI_S3SECRETKEY env var are given, requests must be signed with them via
+AWS Signature Version 4, as the AWS SDKs and CLI do by default. Otherwise, requests aren't
+authenticated, so the gateway listens on localhost by default, refuses to listen on a non-loopback
+host, and rejects requests addressed to other hosts. Stop the gateway with SIGINT or SIGTERM.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newS3GatewayRunner().run()
+	},
+}
+
+func init() {
+	authorCmd.AddCommand(s3GatewayCmd)
+
+	s3GatewayCmd.Flags().String(s3HostFlag, "localhost", "host for the gateway to listen on")
+	s3GatewayCmd.Flags().Int(s3PortFlag, 9000, "port for the gateway to listen on")
+	s3GatewayCmd.Flags().String(s3IndexFileFlag, "",
+		"object index file (default s3-index.json in the data dir)")
+	s3GatewayCmd.Flags().String(s3AccessKeyFlag, "",
+		"access key ID requests must be signed with, along with the LIBRI_S3SECRETKEY secret key")
+
+	// bind viper flags
+	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
+	viper.AutomaticEnv()             // read in environment variables that match
+	cerrors.MaybePanic(viper.BindPFlags(s3GatewayCmd.Flags()))
+}
+
+type s3GatewayRunner interface {
+	run() error
+}
+
+func newS3GatewayRunner() s3GatewayRunner {
+	if dc := getDaemonClient(); dc != nil {
+		return &s3GatewayRunnerImpl{
+			ag: &daemonAuthorGetter{},
+			kc: &daemonKeychainsGetter{},
+			dc: dc,
+		}
+	}
+	return &s3GatewayRunnerImpl{
+		ag: newAuthorGetter(),
+		kc: &keychainsGetterImpl{
+			pg: &terminalPassphraseGetter{},
+		},
+	}
+}
+
+type s3GatewayRunnerImpl struct {
+	ag authorGetter
+	kc keychainsGetter
+
+	// dc is the client of the author daemon the gateway uses instead of a local author, if
+	// one is running
+	dc *daemon.Client
+}
+
+func (r *s3GatewayRunnerImpl) run() error {
+	host := viper.GetString(s3HostFlag)
+	creds, err := getS3Credentials()
+	if err != nil {
+		return err
+	}
+	if creds == nil && !s3.IsLoopbackHost(host) {
+		return errS3HostNotLoopback
+	}
+	authorKeys, selfReaderKeys, err := r.kc.get()
+	if err != nil {
+		return err
+	}
+	author, logger, err := r.ag.get(authorKeys, selfReaderKeys)
+	if err != nil {
+		return err
+	}
+	var s3Author s3.Author = author
+	closeAuthor := author.Close
+	if r.dc != nil {
+		s3Author, closeAuthor = &daemonS3Author{client: r.dc}, r.dc.Close
+	}
+	stop := make(chan os.Signal, 3)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+	addr := net.JoinHostPort(host, strconv.Itoa(viper.GetInt(s3PortFlag)))
+	err = serveS3Gateway(s3Author, getS3IndexFile(), creds, addr, logger, stop)
+	if err != nil {
+		_ = closeAuthor()
+		return err
+	}
+	return closeAuthor()
+}
+
+// serveS3Gateway serves the S3 gateway on the address until receiving a stop signal.
+func serveS3Gateway(
+	author s3.Author,
+	indexFile string,
+	creds *s3.Credentials,
+	addr string,
+	logger *zap.Logger,
+	stop chan os.Signal,
+) error {
+	index, err := s3.NewIndex(indexFile)
+	if err != nil {
+		logger.Error("failed to read s3 index", zap.String(logS3IndexFile, indexFile),
+			zap.Error(err))
+		return err
+	}
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Error("failed to listen on s3 gateway address", zap.String(logS3Addr, addr),
+			zap.Error(err))
+		return err
+	}
+	server := &http.Server{
+		Handler:           s3.NewGateway(author, index, creds, logger),
+		ReadHeaderTimeout: s3ReadHeaderTimeout,
+		IdleTimeout:       s3IdleTimeout,
+	}
+	go func() {
+		<-stop
+		logger.Info("gracefully stopping s3 gateway")
+		_ = server.Shutdown(context.Background())
+	}()
+	logger.Info("serving s3 gateway", zap.String(logS3Addr, addr),
+		zap.String(logS3IndexFile, indexFile))
+	if err = server.Serve(lis); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// getS3Credentials returns the gateway credentials given by the access key flag and secret key env
+// var, or nil if neither is given.
+func getS3Credentials() (*s3.Credentials, error) {
+	accessKey := viper.GetString(s3AccessKeyFlag)
+	secretKey := viper.GetString(s3SecretKeyVar) // intentionally not bound to flag
+	if accessKey == "" && secretKey == "" {
+		return nil, nil
+	}
+	if accessKey == "" || secretKey == "" {
+		return nil, errMissingS3Credential
+	}
+	return &s3.Credentials{AccessKeyID: accessKey, SecretAccessKey: secretKey}, nil
+}
+
+// getS3IndexFile returns the s3 index file given by the flag or the default one in the data dir.
+func getS3IndexFile() string {
+	if indexFile := viper.GetString(s3IndexFileFlag); indexFile != "" {
+		return indexFile
+	}
+	dataDir := lauthor.NewDefaultConfig().WithDataDir(viper.GetString(dataDirFlag)).DataDir
+	return filepath.Join(dataDir, s3.IndexFilename)
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/author/s3"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestS3GatewayRunner_run_err(t *testing.T) {
+	cases := []*s3GatewayRunnerImpl{
+		// keychains get error
+		{kc: &fixedKeychainsGetter{err: errors.New("some get error")}},
+
+		// author get error
+		{
+			ag: &fixedAuthorGetter{err: errors.New("some get error")},
+			kc: &fixedKeychainsGetter{},
+		},
+	}
+	for i, c := range cases {
+		assert.NotNil(t, c.run(), i)
+	}
+
+	// non-loopback host not allowed without credentials
+	viper.Set(s3HostFlag, "0.0.0.0")
+	defer viper.Set(s3HostFlag, "localhost")
+	r := &s3GatewayRunnerImpl{kc: &fixedKeychainsGetter{err: errors.New("some get error")}}
+	assert.Equal(t, errS3HostNotLoopback, r.run())
+
+	// missing secret key
+	viper.Set(s3AccessKeyFlag, "some access key")
+	defer viper.Set(s3AccessKeyFlag, "")
+	assert.Equal(t, errMissingS3Credential, r.run())
+
+	// allowed with credentials, so fails on keychains get
+	viper.Set(s3SecretKeyVar, "some secret key")
+	defer viper.Set(s3SecretKeyVar, "")
+	err := r.run()
+	assert.NotNil(t, err)
+	assert.NotEqual(t, errS3HostNotLoopback, err)
+}
+
+func TestGetS3Credentials(t *testing.T) {
+	creds, err := getS3Credentials()
+	assert.Nil(t, err)
+	assert.Nil(t, creds)
+
+	viper.Set(s3SecretKeyVar, "some secret key")
+	defer viper.Set(s3SecretKeyVar, "")
+	creds, err = getS3Credentials()
+	assert.Equal(t, errMissingS3Credential, err)
+	assert.Nil(t, creds)
+
+	viper.Set(s3AccessKeyFlag, "some access key")
+	defer viper.Set(s3AccessKeyFlag, "")
+	creds, err = getS3Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, &s3.Credentials{AccessKeyID: "some access key",
+		SecretAccessKey: "some secret key"}, creds)
+}
+
+func TestServeS3Gateway(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dataDir, err := ioutil.TempDir("", "test-author-data-dir")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dataDir)) }()
+	indexFile := filepath.Join(dataDir, s3.IndexFilename)
+	a := &fixedDaemonAuthor{envKey: id.NewPseudoRandom(rng)}
+	stop := make(chan os.Signal, 1)
+	served := make(chan error, 1)
+	logger := logging.NewDevInfoLogger()
+	addr := "localhost:20600"
+	go func() { served <- serveS3Gateway(a, indexFile, nil, addr, logger, stop) }()
+
+	content := api.RandBytes(rng, 1024)
+	var rp *http.Response
+	for rp == nil {
+		rq, err := http.NewRequest(http.MethodPut, "http://"+addr+"/bucket/key",
+			bytes.NewReader(content))
+		assert.Nil(t, err)
+		rp, _ = http.DefaultClient.Do(rq)
+	}
+	assert.Equal(t, http.StatusOK, rp.StatusCode)
+	assert.Equal(t, content, a.content)
+
+	rp, err = http.Get("http://" + addr + "/bucket/key")
+	assert.Nil(t, err)
+	body, err := ioutil.ReadAll(rp.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, rp.Body.Close())
+	assert.Equal(t, content, body)
+
+	// address in use
+	assert.NotNil(t, serveS3Gateway(a, indexFile, nil, addr, logger, nil))
+
+	stop <- syscall.SIGTERM
+	assert.Nil(t, <-served)
+
+	// object indexed
+	idx, err := s3.NewIndex(indexFile)
+	assert.Nil(t, err)
+	obj, in := idx.Get("bucket", "key")
+	assert.True(t, in)
+	assert.Equal(t, a.envKey.String(), obj.EnvelopeKey)
+
+	// bad index file
+	assert.NotNil(t, serveS3Gateway(a, dataDir, nil, addr, logger, nil))
+}
+
+func TestGetS3IndexFile(t *testing.T) {
+	viper.Set(dataDirFlag, "/some/data/dir")
+	defer viper.Set(dataDirFlag, "")
+	assert.Equal(t, filepath.Join("/some/data/dir", s3.IndexFilename), getS3IndexFile())
+
+	viper.Set(dataDirFlag, "")
+	assert.Equal(t, filepath.Join(lauthor.NewDefaultConfig().DataDir, s3.IndexFilename),
+		getS3IndexFile())
+
+	viper.Set(s3IndexFileFlag, "/some/index.json")
+	defer viper.Set(s3IndexFileFlag, "")
+	assert.Equal(t, "/some/index.json", getS3IndexFile())
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	shareEnvelopeKeyFlag = "shareEnvelopeKey"
+	shareToFlag          = "to"
+	shareGroupFlag       = "group"
+)
+
+var (
+	errMissingShareContact  = errors.New("missing contact or group to share with")
+	errShareContactAndGroup = errors.New("cannot share with both a contact and a group")
+	errGroupShareFailed     = errors.New("sharing with one or more group members failed")
+)
+
+// shareCmd represents the share command
+var shareCmd = &cobra.Command{
+	Use:   "share",
+	Short: "share a document on the libri network with a contact",
+	Long: `Share a document with a contact from the contact book (see "author contacts") by
+uploading a new envelope for the contact's reader public key. The new envelope's key, which the
+contact uses to download the document, is printed on success.
+
+Alternatively, share a document with each member of a group (see "author groups"), uploading all
+the new envelopes concurrently. Members the document was already shared with by a previous group
+share are skipped, so re-sharing after adding members only shares with the new ones. Each
+member's reader key, envelope key, and result are printed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newDocSharer().share(os.Stdout)
+	},
+}
+
+func init() {
+	authorCmd.AddCommand(shareCmd)
+
+	shareCmd.Flags().StringP(shareEnvelopeKeyFlag, "e", "",
+		"key of envelope of document to share")
+	shareCmd.Flags().String(shareToFlag, "",
+		"name of contact to share document with")
+	shareCmd.Flags().String(shareGroupFlag, "",
+		"name of group to share document with")
+
+	// bind viper flags
+	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
+	viper.AutomaticEnv()             // read in environment variables that match
+	cerrors.MaybePanic(viper.BindPFlags(shareCmd.Flags()))
+}
+
+type docSharer interface {
+	share(w io.Writer) error
+}
+
+func newDocSharer() docSharer {
+	if dc := getDaemonClient(); dc != nil {
+		return &docSharerImpl{
+			ag:  &daemonAuthorGetter{},
+			as:  &daemonSharer{client: dc},
+			ags: &daemonGroupSharer{client: dc},
+			kc:  &daemonKeychainsGetter{},
+		}
+	}
+	return &docSharerImpl{
+		ag:  newAuthorGetter(),
+		as:  &authorSharerImpl{},
+		ags: &authorGroupSharerImpl{},
+		kc: &keychainsGetterImpl{
+			pg: &terminalPassphraseGetter{},
+		},
+	}
+}
+
+type docSharerImpl struct {
+	ag  authorGetter
+	as  authorSharer
+	ags authorGroupSharer
+	kc  keychainsGetter
+}
+
+func (s *docSharerImpl) share(w io.Writer) error {
+	envelopeKeyStr := viper.GetString(shareEnvelopeKeyFlag)
+	if envelopeKeyStr == "" {
+		return errMissingEnvelopeKey
+	}
+	envelopeKey, err := id.FromString(envelopeKeyStr)
+	if err != nil {
+		return err
+	}
+	contact, group := viper.GetString(shareToFlag), viper.GetString(shareGroupFlag)
+	if contact != "" && group != "" {
+		return errShareContactAndGroup
+	}
+	if group != "" {
+		return s.shareWithGroup(w, envelopeKey, group)
+	}
+	if contact == "" {
+		return errMissingShareContact
+	}
+	contacts, err := newContactsEditor().read(false)
+	if err != nil {
+		return err
+	}
+	readerPub, err := contacts.Get(contact)
+	if err != nil {
+		return err
+	}
+	authorKeys, selfReaderKeys, err := s.kc.get()
+	if err != nil {
+		return err
+	}
+	author, logger, err := s.ag.get(authorKeys, selfReaderKeys)
+	if err != nil {
+		return err
+	}
+	logger.Info("sharing document",
+		zap.Stringer("envelope_key", envelopeKey),
+		zap.String("contact", contact),
+		zap.String("reader_key", lauthor.EncodeReaderKey(readerPub)),
+	)
+	sharedEnvelopeKey, err := s.as.share(author, envelopeKey, readerPub)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, sharedEnvelopeKey)
+	return err
+}
+
+func (s *docSharerImpl) shareWithGroup(w io.Writer, envelopeKey id.ID, groupName string) error {
+	groups, err := newGroupsEditor().read(false)
+	if err != nil {
+		return err
+	}
+	group, err := groups.Get(groupName)
+	if err != nil {
+		return err
+	}
+	authorKeys, selfReaderKeys, err := s.kc.get()
+	if err != nil {
+		return err
+	}
+	author, logger, err := s.ag.get(authorKeys, selfReaderKeys)
+	if err != nil {
+		return err
+	}
+	logger.Info("sharing document with group",
+		zap.Stringer("envelope_key", envelopeKey),
+		zap.String("group", groupName),
+		zap.Int("n_members", len(group.ReaderPubs)),
+	)
+	report, err := s.ags.shareWithGroup(author, envelopeKey, group)
+	if err != nil {
+		return err
+	}
+	for _, result := range report.Results {
+		sharedEnvelopeKey, status := "-", "shared"
+		if result.EnvelopeKey != nil {
+			sharedEnvelopeKey = result.EnvelopeKey.String()
+		}
+		if result.AlreadyShared {
+			status = "already shared"
+		}
+		if result.Err != nil {
+			status = result.Err.Error()
+		}
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\n", lauthor.EncodeReaderKey(result.ReaderPub),
+			sharedEnvelopeKey, status)
+		if err != nil {
+			return err
+		}
+	}
+	if report.NFailed() > 0 {
+		return errGroupShareFailed
+	}
+	return nil
+}
+
+// authorGroupSharer just wraps an *author.Author ShareWithGroup call for the same reason as
+// authorUploader
+type authorGroupSharer interface {
+	shareWithGroup(author *lauthor.Author, envelopeKey id.ID, group *lauthor.Group) (
+		*lauthor.GroupShareReport, error)
+}
+
+type authorGroupSharerImpl struct{}
+
+func (*authorGroupSharerImpl) shareWithGroup(
+	author *lauthor.Author, envelopeKey id.ID, group *lauthor.Group,
+) (*lauthor.GroupShareReport, error) {
+	return author.ShareWithGroup(envelopeKey, group)
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lauthor "github.com/drausin/libri/libri/author"
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/logging"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShareCmd_err(t *testing.T) {
+	viper.Set(shareEnvelopeKeyFlag, "")
+	err := shareCmd.RunE(shareCmd, []string{})
+	assert.Equal(t, errMissingEnvelopeKey, err)
+}
+
+func TestDocSharer_share_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	readerPub := &ecid.NewPseudoRandom(rng).Key().PublicKey
+	fp := writeTestContacts(t, map[string]*ecdsa.PublicKey{"alice": readerPub})
+	defer func() { assert.Nil(t, os.RemoveAll(filepath.Dir(fp))) }()
+	envelopeKey, sharedEnvelopeKey := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	viper.Set(shareEnvelopeKeyFlag, envelopeKey.String())
+	viper.Set(shareToFlag, "alice")
+	viper.Set(contactsFileFlag, fp)
+	defer viper.Set(contactsFileFlag, "")
+
+	as := &fixedAuthorSharer{sharedEnvelopeKey: sharedEnvelopeKey}
+	s := &docSharerImpl{
+		ag: &fixedAuthorGetter{
+			author: nil, // ok since we're passing it into a mocked method anyway
+			logger: logging.NewDevInfoLogger(),
+		},
+		as: as,
+		kc: &fixedKeychainsGetter{}, // ok that KCs are null for same reason
+	}
+	buf := new(bytes.Buffer)
+	err := s.share(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, sharedEnvelopeKey.String(), strings.TrimSpace(buf.String()))
+	assert.Equal(t, envelopeKey, as.envelopeKey)
+	assert.Equal(t, ecid.ToPublicKeyBytes(readerPub), ecid.ToPublicKeyBytes(as.readerPub))
+}
+
+func TestDocSharer_share_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	readerPub := &ecid.NewPseudoRandom(rng).Key().PublicKey
+	fp := writeTestContacts(t, map[string]*ecdsa.PublicKey{"alice": readerPub})
+	defer func() { assert.Nil(t, os.RemoveAll(filepath.Dir(fp))) }()
+	envelopeKey := id.NewPseudoRandom(rng).String()
+	viper.Set(contactsFileFlag, fp)
+	defer viper.Set(contactsFileFlag, "")
+	okAG := &fixedAuthorGetter{logger: logging.NewDevInfoLogger()}
+
+	cases := []struct {
+		envelopeKey string
+		to          string
+		contactsFP  string
+		s           *docSharerImpl
+		expected    error
+	}{
+		// missing envelope key
+		{envelopeKey: "", to: "alice", contactsFP: fp, s: &docSharerImpl{},
+			expected: errMissingEnvelopeKey},
+
+		// bad envelope key
+		{envelopeKey: "not an ID", to: "alice", contactsFP: fp, s: &docSharerImpl{}},
+
+		// missing contact
+		{envelopeKey: envelopeKey, to: "", contactsFP: fp, s: &docSharerImpl{},
+			expected: errMissingShareContact},
+
+		// missing contact book
+		{envelopeKey: envelopeKey, to: "alice",
+			contactsFP: filepath.Join(filepath.Dir(fp), "missing.json"), s: &docSharerImpl{}},
+
+		// unknown contact
+		{envelopeKey: envelopeKey, to: "bob", contactsFP: fp, s: &docSharerImpl{}},
+
+		// keychains get error
+		{envelopeKey: envelopeKey, to: "alice", contactsFP: fp, s: &docSharerImpl{
+			kc: &fixedKeychainsGetter{err: errors.New("some get error")},
+		}},
+
+		// author get error
+		{envelopeKey: envelopeKey, to: "alice", contactsFP: fp, s: &docSharerImpl{
+			ag: &fixedAuthorGetter{err: errors.New("some get error")},
+			kc: &fixedKeychainsGetter{},
+		}},
+
+		// share error
+		{envelopeKey: envelopeKey, to: "alice", contactsFP: fp, s: &docSharerImpl{
+			ag: okAG,
+			as: &fixedAuthorSharer{err: errors.New("some share error")},
+			kc: &fixedKeychainsGetter{},
+		}},
+	}
+	for i, c := range cases {
+		viper.Set(shareEnvelopeKeyFlag, c.envelopeKey)
+		viper.Set(shareToFlag, c.to)
+		viper.Set(contactsFileFlag, c.contactsFP)
+		err := c.s.share(new(bytes.Buffer))
+		assert.NotNil(t, err, i)
+		if c.expected != nil {
+			assert.Equal(t, c.expected, err, i)
+		}
+	}
+}
+
+func TestDocSharer_shareWithGroup(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "share-test")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	alice, bob := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	g := lauthor.NewGroups()
+	_, err = g.Add("team", &alice.Key().PublicKey, &bob.Key().PublicKey)
+	assert.Nil(t, err)
+	fp := filepath.Join(dir, lauthor.GroupsFilename)
+	assert.Nil(t, lauthor.WriteGroups(fp, g))
+	viper.Set(groupsFileFlag, fp)
+	defer viper.Set(groupsFileFlag, "")
+	viper.Set(shareEnvelopeKeyFlag, id.NewPseudoRandom(rng).String())
+	defer viper.Set(shareGroupFlag, "")
+	defer viper.Set(shareToFlag, "")
+
+	sharedEnvKey := id.NewPseudoRandom(rng)
+	okAG := &fixedAuthorGetter{logger: logging.NewDevInfoLogger()}
+	ags := &fixedAuthorGroupSharer{
+		report: &lauthor.GroupShareReport{
+			Group: "team",
+			Results: []*lauthor.GroupShareResult{
+				{ReaderPub: &alice.Key().PublicKey, EnvelopeKey: sharedEnvKey},
+				{ReaderPub: &bob.Key().PublicKey, EnvelopeKey: sharedEnvKey,
+					AlreadyShared: true},
+			},
+		},
+	}
+	s := &docSharerImpl{ag: okAG, ags: ags, kc: &fixedKeychainsGetter{}}
+
+	// ok
+	viper.Set(shareToFlag, "")
+	viper.Set(shareGroupFlag, "team")
+	buf := new(bytes.Buffer)
+	err = s.share(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "team", ags.group.Name)
+	assert.Equal(t, 2, len(ags.group.ReaderPubs))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.Equal(t, lauthor.EncodeReaderKey(&alice.Key().PublicKey)+"\t"+
+		sharedEnvKey.String()+"\tshared", lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], "\talready shared"))
+
+	// some members failing
+	ags.report.Results[1] = &lauthor.GroupShareResult{
+		ReaderPub: &bob.Key().PublicKey,
+		Err:       errors.New("some share error"),
+	}
+	buf = new(bytes.Buffer)
+	err = s.share(buf)
+	assert.Equal(t, errGroupShareFailed, err)
+	assert.True(t, strings.Contains(buf.String(), "-\tsome share error"))
+
+	// both contact and group
+	viper.Set(shareToFlag, "alice")
+	assert.Equal(t, errShareContactAndGroup, s.share(new(bytes.Buffer)))
+	viper.Set(shareToFlag, "")
+
+	// unknown group
+	viper.Set(shareGroupFlag, "other")
+	assert.NotNil(t, s.share(new(bytes.Buffer)))
+	viper.Set(shareGroupFlag, "team")
+
+	// missing groups file
+	viper.Set(groupsFileFlag, filepath.Join(dir, "missing.json"))
+	assert.NotNil(t, s.share(new(bytes.Buffer)))
+	viper.Set(groupsFileFlag, fp)
+
+	// keychains, author, and share errors
+	errSharers := []*docSharerImpl{
+		{kc: &fixedKeychainsGetter{err: errors.New("some get error")}},
+		{ag: &fixedAuthorGetter{err: errors.New("some get error")}, kc: &fixedKeychainsGetter{}},
+		{ag: okAG, ags: &fixedAuthorGroupSharer{err: errors.New("some share error")},
+			kc: &fixedKeychainsGetter{}},
+	}
+	for i, s := range errSharers {
+		assert.NotNil(t, s.share(new(bytes.Buffer)), i)
+	}
+}
+
+func writeTestContacts(t *testing.T, readerPubs map[string]*ecdsa.PublicKey) string {
+	dir, err := ioutil.TempDir("", "share-test")
+	assert.Nil(t, err)
+	c := lauthor.NewContacts()
+	for name, readerPub := range readerPubs {
+		assert.Nil(t, c.Add(name, readerPub))
+	}
+	fp := filepath.Join(dir, lauthor.ContactsFilename)
+	assert.Nil(t, lauthor.WriteContacts(fp, c))
+	return fp
+}
+
+type fixedAuthorSharer struct {
+	sharedEnvelopeKey id.ID
+	err               error
+
+	envelopeKey id.ID
+	readerPub   *ecdsa.PublicKey
+}
+
+func (f *fixedAuthorSharer) share(
+	author *lauthor.Author, envelopeKey id.ID, readerPub *ecdsa.PublicKey,
+) (id.ID, error) {
+	f.envelopeKey, f.readerPub = envelopeKey, readerPub
+	return f.sharedEnvelopeKey, f.err
+}
+
+type fixedAuthorGroupSharer struct {
+	report *lauthor.GroupShareReport
+	err    error
+
+	group *lauthor.Group
+}
+
+func (f *fixedAuthorGroupSharer) shareWithGroup(
+	author *lauthor.Author, envelopeKey id.ID, group *lauthor.Group,
+) (*lauthor.GroupShareReport, error) {
+	f.group = group
+	return f.report, f.err
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/hex"
 	"fmt"
+	"net"
 	"os"
 	"strings"
 
@@ -11,9 +12,12 @@ import (
 	clogging "github.com/drausin/libri/libri/common/logging"
 	"github.com/drausin/libri/libri/common/parse"
 	"github.com/drausin/libri/libri/common/subscribe"
+	"github.com/drausin/libri/libri/librarian/relay"
 	"github.com/drausin/libri/libri/librarian/server"
+	"github.com/drausin/libri/libri/librarian/server/refresh"
 	"github.com/drausin/libri/libri/librarian/server/replicate"
 	"github.com/drausin/libri/libri/librarian/server/routing"
+	"github.com/drausin/libri/libri/librarian/server/search"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -30,14 +34,31 @@ const (
 	publicPortFlag        = "publicPort"
 	nSubscriptionsFlag    = "nSubscriptions"
 	fpRateFlag            = "fpRate"
+	subSelectionFlag      = "subscriptionSelection"
+	subRotationFlag       = "subscriptionRotation"
 	profileFlag           = "profile"
 	maxBucketPeersFlag    = "maxRoutingBucketPeers"
+	minIDWorkFlag         = "minIDWork"
+	maxBucketOrgFlag      = "maxRoutingBucketOrgPeers"
+	maxBucketSubnetFlag   = "maxRoutingBucketSubnetPeers"
+	disjointPathsFlag     = "searchDisjointPaths"
 	verifyIntervalFlag    = "verifyInterval"
+	refreshIntervalFlag   = "refreshInterval"
+	refreshPingsFlag      = "refreshPings"
 	organizationIDFlag    = "organizationID"
+	clockSkewFlag         = "clockSkew"
+	replayCacheMemFlag    = "replayCacheMemory"
+	requireCertsFlag      = "requireCertificates"
+	limitsFileFlag        = "limitsFile"
+	relayFlag             = "relay"
+	localRelayPortFlag    = "localRelayPort"
+	localGatewayPortFlag  = "localGatewayPort"
+	advertisedAddrsFlag   = "advertisedAddrs"
 
 	logLocalPort        = "localPort"
 	logLocalMetricsPort = "localMetricsPort"
 	logPublicAddr       = "publicAddr"
+	logSubConsistency   = "subscriptionEstimatedConsistency"
 )
 
 // startLibrarianCmd represents the librarian start command
@@ -64,25 +85,65 @@ func init() {
 	startLibrarianCmd.Flags().Int(localProfilerPortFlag, server.DefaultProfilerPort,
 		"local profiler port (when --profile is set)")
 	startLibrarianCmd.Flags().StringP(publicHostFlag, "i", server.DefaultIP,
-		"public host (IPv4 or URL)")
+		"public host (IPv4 or IPv6 address or DNS name)")
 	startLibrarianCmd.Flags().IntP(publicPortFlag, "p", server.DefaultPort,
 		"public port")
 	startLibrarianCmd.Flags().StringSliceP(bootstrapsFlag, "b", nil,
-		"comma-separated addresses (IPv4:Port) of bootstrap peers")
+		"comma-separated addresses (host:port) of bootstrap peers")
+	startLibrarianCmd.Flags().StringSlice(advertisedAddrsFlag, nil,
+		"comma-separated addresses (host:port) peers may also reach this librarian at, in "+
+			"addition to the public address, e.g., IPv6 addresses or DNS names")
 	startLibrarianCmd.Flags().StringP(publicNameFlag, "n", "",
 		"public peer name")
 	startLibrarianCmd.Flags().IntP(nSubscriptionsFlag, "s", subscribe.DefaultNSubscriptionsTo,
 		"number of active subscriptions to other peers to maintain")
 	startLibrarianCmd.Flags().Float32P(fpRateFlag, "f", subscribe.DefaultFPRate,
 		"false positive rate for subscriptions to other peers")
+	startLibrarianCmd.Flags().String(subSelectionFlag, subscribe.DefaultSelection.String(),
+		"selection of peers to subscribe to (random, spread, or healthy)")
+	startLibrarianCmd.Flags().Duration(subRotationFlag, subscribe.DefaultRotationPeriod,
+		"approximate period after which subscriptions rotate to other peers (0 to disable)")
 	startLibrarianCmd.Flags().Bool(profileFlag, false,
 		"enable /debug/pprof profiler endpoint")
 	startLibrarianCmd.Flags().Uint(maxBucketPeersFlag, routing.DefaultMaxActivePeers,
 		"max number of peers allowed in a routing table bucket")
+	startLibrarianCmd.Flags().Uint(minIDWorkFlag, routing.DefaultMinIDWork,
+		"min leading zero bits in the SHA-256 hash of a peer ID for the peer to join the "+
+			"routing table")
+	startLibrarianCmd.Flags().Uint(maxBucketOrgFlag, routing.DefaultMaxBucketOrgPeers,
+		"max number of peers from the same organization in a routing table bucket (0 for no "+
+			"limit)")
+	startLibrarianCmd.Flags().Uint(maxBucketSubnetFlag, routing.DefaultMaxBucketSubnetPeers,
+		"max number of peers from the same /24 subnet in a routing table bucket (0 for no "+
+			"limit)")
+	startLibrarianCmd.Flags().Uint(disjointPathsFlag, search.DefaultNDisjointPaths,
+		"number of disjoint paths followed by Get, Put, and Verify lookups")
 	startLibrarianCmd.Flags().Duration(verifyIntervalFlag, replicate.DefaultVerifyInterval,
 		"verify interval duration")
+	startLibrarianCmd.Flags().Duration(refreshIntervalFlag, refresh.DefaultInterval,
+		"routing table refresh interval duration, after which buckets without new peers are "+
+			"refreshed")
+	startLibrarianCmd.Flags().Uint(refreshPingsFlag, refresh.DefaultNPings,
+		"number of least-recently-seen peers pinged each routing table refresh")
 	startLibrarianCmd.Flags().String(organizationIDFlag, "",
 		"[sensitive] hex value of organization ID private key")
+	startLibrarianCmd.Flags().Duration(clockSkewFlag, server.DefaultClockSkew,
+		"max clock difference tolerated when checking request signature times")
+	startLibrarianCmd.Flags().Uint(replayCacheMemFlag, server.DefaultReplayCacheMemory,
+		"max memory (in bytes) used to remember recent request IDs to reject replays")
+	startLibrarianCmd.Flags().Bool(requireCertsFlag, false,
+		"refuse requests without a valid certificate from an organization in the trust list")
+	startLibrarianCmd.Flags().String(limitsFileFlag, "",
+		"JSON file with endpoint authorizations and rate limits for known and unknown peers, "+
+			"reloaded on SIGHUP")
+	startLibrarianCmd.Flags().String(relayFlag, "",
+		"address (host:port) of the relay through which peers reach this librarian when it "+
+			"isn't directly reachable, e.g., when behind a NAT, optionally prefixed by the "+
+			"relay's peer ID (peerID@host:port) to only register with that relay")
+	startLibrarianCmd.Flags().Int(localRelayPortFlag, 0,
+		"local port to relay connections from for unreachable peers (0 to not relay)")
+	startLibrarianCmd.Flags().Int(localGatewayPortFlag, 0,
+		"local port to serve the HTTP/JSON API gateway from (0 to not serve it)")
 
 	// bind viper flags
 	viper.SetEnvPrefix("LIBRI") // look for env vars with "LIBRI_" prefix
@@ -108,6 +169,9 @@ func getLibrarianConfig() (*server.Config, *zap.Logger, error) {
 	}
 	replicateParams := replicate.NewDefaultParameters()
 	replicateParams.VerifyInterval = viper.GetDuration(verifyIntervalFlag)
+	refreshParams := refresh.NewDefaultParameters()
+	refreshParams.Interval = viper.GetDuration(refreshIntervalFlag)
+	refreshParams.NPings = uint(viper.GetInt(refreshPingsFlag))
 	orgID, err := getOrgID(logger)
 	if err != nil {
 		return nil, nil, err
@@ -122,12 +186,31 @@ func getLibrarianConfig() (*server.Config, *zap.Logger, error) {
 		WithPublicName(viper.GetString(publicNameFlag)).
 		WithOrgID(orgID).
 		WithReplicate(replicateParams).
+		WithRefresh(refreshParams).
 		WithDataDir(viper.GetString(dataDirFlag)).
 		WithDefaultDBDir(). // depends on DataDir
-		WithLogLevel(logLevel)
+		WithLogLevel(logLevel).
+		WithClockSkew(viper.GetDuration(clockSkewFlag)).
+		WithReplayCacheMemory(uint(viper.GetInt(replayCacheMemFlag))).
+		WithTrustListFile(viper.GetString(trustListFileFlag)).
+		WithCertificateFile(viper.GetString(certificateFileFlag)).
+		WithRequireCertificates(viper.GetBool(requireCertsFlag)).
+		WithLimitsFile(viper.GetString(limitsFileFlag)).
+		WithLocalRelayPort(viper.GetInt(localRelayPortFlag)).
+		WithLocalGatewayPort(viper.GetInt(localGatewayPortFlag))
 	config.SubscribeTo.NSubscriptions = uint32(viper.GetInt(nSubscriptionsFlag))
 	config.SubscribeTo.FPRate = float32(viper.GetFloat64(fpRateFlag))
+	config.SubscribeTo.RotationPeriod = viper.GetDuration(subRotationFlag)
+	config.SubscribeTo.Selection, err = subscribe.ParseSelection(viper.GetString(subSelectionFlag))
+	if err != nil {
+		logger.Error("unable to parse subscription selection", zap.Error(err))
+		return nil, nil, err
+	}
 	config.Routing.MaxBucketPeers = uint(viper.GetInt(maxBucketPeersFlag))
+	config.Routing.MinIDWork = uint(viper.GetInt(minIDWorkFlag))
+	config.Routing.MaxBucketOrgPeers = uint(viper.GetInt(maxBucketOrgFlag))
+	config.Routing.MaxBucketSubnetPeers = uint(viper.GetInt(maxBucketSubnetFlag))
+	config.Search.NDisjointPaths = uint(viper.GetInt(disjointPathsFlag))
 
 	bootstrapNetAddrs, err := parse.Addrs(viper.GetStringSlice(bootstrapsFlag))
 	if err != nil {
@@ -137,18 +220,55 @@ func getLibrarianConfig() (*server.Config, *zap.Logger, error) {
 	}
 	config.WithBootstrapAddrs(bootstrapNetAddrs)
 
+	advertisedAddrs := viper.GetStringSlice(advertisedAddrsFlag)
+	if err := parse.HostPorts(advertisedAddrs); err != nil {
+		logger.Error("unable to parse advertised address", zap.Error(err))
+		return nil, nil, err
+	}
+	config.WithAdvertisedAddrs(advertisedAddrs)
+
+	if relayAddr := viper.GetString(relayFlag); relayAddr != "" {
+		if relayID, addr, hasID := relay.ParseTarget(relayAddr); hasID {
+			config.WithRelayID(relayID)
+			relayAddr = addr
+		}
+		relayNetAddr, err := net.ResolveTCPAddr("tcp", relayAddr)
+		if err != nil {
+			logger.Error("unable to parse relay address", zap.Error(err))
+			return nil, nil, err
+		}
+		config.WithRelayAddr(relayNetAddr)
+	}
+
 	WriteLibrarianBanner(os.Stdout)
 	logger.Info("librarian configuration",
 		zap.Int(logLocalPort, config.LocalPort),
 		zap.Int(logLocalMetricsPort, config.LocalMetricsPort),
 		zap.Stringer(logPublicAddr, config.PublicAddr),
+		zap.Strings(advertisedAddrsFlag, config.AdvertisedAddrs),
 		zap.String(bootstrapsFlag, fmt.Sprintf("%v", config.BootstrapAddrs)),
 		zap.String(publicNameFlag, config.PublicName),
 		zap.String(dataDirFlag, config.DataDir),
 		zap.Stringer(logLevelFlag, config.LogLevel),
 		zap.Uint32(nSubscriptionsFlag, config.SubscribeTo.NSubscriptions),
 		zap.Float32(fpRateFlag, config.SubscribeTo.FPRate),
+		zap.Stringer(subSelectionFlag, config.SubscribeTo.Selection),
+		zap.Duration(subRotationFlag, config.SubscribeTo.RotationPeriod),
+		zap.Float64(logSubConsistency, config.SubscribeTo.EstimatedConsistency()),
 		zap.Uint(maxBucketPeersFlag, config.Routing.MaxBucketPeers),
+		zap.Uint(minIDWorkFlag, config.Routing.MinIDWork),
+		zap.Uint(maxBucketOrgFlag, config.Routing.MaxBucketOrgPeers),
+		zap.Uint(maxBucketSubnetFlag, config.Routing.MaxBucketSubnetPeers),
+		zap.Uint(disjointPathsFlag, config.Search.NDisjointPaths),
+		zap.Duration(clockSkewFlag, config.ClockSkew),
+		zap.Uint(replayCacheMemFlag, config.ReplayCacheMemory),
+		zap.String(trustListFileFlag, config.TrustListFile),
+		zap.String(certificateFileFlag, config.CertificateFile),
+		zap.Bool(requireCertsFlag, config.RequireCertificates),
+		zap.String(limitsFileFlag, config.LimitsFile),
+		zap.Stringer(relayFlag, config.RelayAddr),
+		zap.Int(localRelayPortFlag, config.LocalRelayPort),
+		zap.Int(localGatewayPortFlag, config.LocalGatewayPort),
 	)
 	return config, logger, nil
 }
@@ -159,6 +279,10 @@ func getOrgID(logger *zap.Logger) (ecid.ID, error) {
 		// ok if org ID isn't set
 		return nil, nil
 	}
+	return parseOrgID(logger, orgIDPrivHex)
+}
+
+func parseOrgID(logger *zap.Logger, orgIDPrivHex string) (ecid.ID, error) {
 	orgIDPrivBytes, err := hex.DecodeString(strings.TrimSpace(orgIDPrivHex))
 	if err != nil {
 		logger.Error("fatal error parsing organization ID private key hex")
@@ -170,7 +294,8 @@ func getOrgID(logger *zap.Logger) (ecid.ID, error) {
 			zap.Int("expected_length", expectedByteLen),
 			zap.Int("actual_length", len(orgIDPrivBytes)),
 		)
-		return nil, err
+		return nil, fmt.Errorf("organization ID private key has %d bytes, expected %d",
+			len(orgIDPrivBytes), expectedByteLen)
 	}
 	priv, err := crypto.ToECDSA(orgIDPrivBytes)
 	if err != nil {
@@ -14,6 +14,8 @@ import (
 	"strings"
 
 	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/subscribe"
 	"github.com/drausin/libri/libri/librarian/server"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -42,9 +44,15 @@ func TestGetLibrarianConfig_ok(t *testing.T) {
 	dataDir := "some/data/dir"
 	logLevel := "debug"
 	nSubscriptions, fpRate := 5, 0.5
+	subSelection, subRotation := "spread", 10*time.Minute
 	bootstraps := "1.2.3.5:1000 1.2.3.6:1000"
 	nBucketPeers := uint(8)
+	minIDWork, nBucketOrgPeers, nBucketSubnetPeers := uint(4), uint(3), uint(2)
+	nDisjointPaths := uint(2)
 	verifyInterval := 5 * time.Second
+	refreshInterval, refreshPings := 10*time.Minute, uint(4)
+	clockSkew := 5 * time.Second
+	replayCacheMemory := uint(1024 * 1024)
 	orgID := ecid.NewPseudoRandom(rng)
 	orgIDHex := hex.EncodeToString(orgID.Key().D.Bytes())
 
@@ -59,10 +67,28 @@ func TestGetLibrarianConfig_ok(t *testing.T) {
 	viper.Set(dataDirFlag, dataDir)
 	viper.Set(nSubscriptionsFlag, nSubscriptions)
 	viper.Set(fpRateFlag, fpRate)
+	viper.Set(subSelectionFlag, subSelection)
+	viper.Set(subRotationFlag, subRotation)
 	viper.Set(bootstrapsFlag, bootstraps)
 	viper.Set(maxBucketPeersFlag, nBucketPeers)
+	viper.Set(minIDWorkFlag, minIDWork)
+	viper.Set(maxBucketOrgFlag, nBucketOrgPeers)
+	viper.Set(maxBucketSubnetFlag, nBucketSubnetPeers)
+	viper.Set(disjointPathsFlag, nDisjointPaths)
 	viper.Set(verifyIntervalFlag, verifyInterval)
+	viper.Set(refreshIntervalFlag, refreshInterval)
+	viper.Set(refreshPingsFlag, refreshPings)
 	viper.Set(organizationIDFlag, orgIDHex)
+	viper.Set(clockSkewFlag, clockSkew)
+	viper.Set(replayCacheMemFlag, replayCacheMemory)
+	viper.Set(trustListFileFlag, "trust.json")
+	viper.Set(certificateFileFlag, "peer.cert")
+	viper.Set(requireCertsFlag, true)
+	viper.Set(limitsFileFlag, "limits.json")
+	viper.Set(relayFlag, "1.2.3.7:20400")
+	viper.Set(advertisedAddrsFlag, "[2001:db8::1]:6789 librarian.example.com:6789")
+	viper.Set(localRelayPortFlag, 20400)
+	viper.Set(localGatewayPortFlag, 20500)
 
 	config, logger, err := getLibrarianConfig()
 	assert.Nil(t, err)
@@ -78,10 +104,38 @@ func TestGetLibrarianConfig_ok(t *testing.T) {
 	assert.Equal(t, logLevel, config.LogLevel.String())
 	assert.Equal(t, uint32(nSubscriptions), config.SubscribeTo.NSubscriptions)
 	assert.Equal(t, float32(fpRate), config.SubscribeTo.FPRate)
+	assert.Equal(t, subscribe.SpreadSelection, config.SubscribeTo.Selection)
+	assert.Equal(t, subRotation, config.SubscribeTo.RotationPeriod)
 	assert.Equal(t, 2, len(config.BootstrapAddrs))
 	assert.Equal(t, nBucketPeers, config.Routing.MaxBucketPeers)
+	assert.Equal(t, minIDWork, config.Routing.MinIDWork)
+	assert.Equal(t, nBucketOrgPeers, config.Routing.MaxBucketOrgPeers)
+	assert.Equal(t, nBucketSubnetPeers, config.Routing.MaxBucketSubnetPeers)
+	assert.Equal(t, nDisjointPaths, config.Search.NDisjointPaths)
 	assert.Equal(t, verifyInterval, config.Replicate.VerifyInterval)
+	assert.Equal(t, refreshInterval, config.Refresh.Interval)
+	assert.Equal(t, refreshPings, config.Refresh.NPings)
 	assert.Equal(t, orgID.Key(), config.OrgID.Key())
+	assert.Equal(t, clockSkew, config.ClockSkew)
+	assert.Equal(t, replayCacheMemory, config.ReplayCacheMemory)
+	assert.Equal(t, "trust.json", config.TrustListFile)
+	assert.Equal(t, "peer.cert", config.CertificateFile)
+	assert.True(t, config.RequireCertificates)
+	assert.Equal(t, "limits.json", config.LimitsFile)
+	assert.Equal(t, "1.2.3.7:20400", config.RelayAddr.String())
+	assert.Nil(t, config.RelayID)
+	assert.Equal(t, 20400, config.LocalRelayPort)
+	assert.Equal(t, 20500, config.LocalGatewayPort)
+	assert.Equal(t, []string{"[2001:db8::1]:6789", "librarian.example.com:6789"},
+		config.AdvertisedAddrs)
+	viper.Set(trustListFileFlag, "")
+	viper.Set(certificateFileFlag, "")
+	viper.Set(requireCertsFlag, false)
+	viper.Set(limitsFileFlag, "")
+	viper.Set(relayFlag, "")
+	viper.Set(localRelayPortFlag, 0)
+	viper.Set(localGatewayPortFlag, 0)
+	viper.Set(advertisedAddrsFlag, "")
 
 	assert.Nil(t, os.RemoveAll(config.DataDir))
 }
@@ -101,6 +155,39 @@ func TestGetLibrarianConfig_err(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, config)
 	assert.Nil(t, logger)
+
+	// reset to ok value
+	viper.Set(bootstrapsFlag, "1.2.3.5:1000")
+
+	viper.Set(subSelectionFlag, "bad selection")
+	config, logger, err = getLibrarianConfig()
+	assert.Equal(t, subscribe.ErrUnknownSelection, err)
+	assert.Nil(t, config)
+	assert.Nil(t, logger)
+	viper.Set(subSelectionFlag, subscribe.DefaultSelection.String())
+
+	viper.Set(relayFlag, "bad relay")
+	config, logger, err = getLibrarianConfig()
+	assert.NotNil(t, err)
+	assert.Nil(t, config)
+	assert.Nil(t, logger)
+	viper.Set(relayFlag, "")
+
+	relayID := id.FromInt64(1)
+	viper.Set(relayFlag, relayID.String()+"@1.2.3.7:20400")
+	config, logger, err = getLibrarianConfig()
+	assert.Nil(t, err)
+	assert.Equal(t, relayID, config.RelayID)
+	assert.Equal(t, "1.2.3.7:20400", config.RelayAddr.String())
+	assert.Nil(t, os.RemoveAll(config.DataDir))
+	viper.Set(relayFlag, "")
+
+	viper.Set(advertisedAddrsFlag, "librarian.example.com")
+	config, logger, err = getLibrarianConfig()
+	assert.NotNil(t, err)
+	assert.Nil(t, config)
+	assert.Nil(t, logger)
+	viper.Set(advertisedAddrsFlag, "")
 }
 
 func TestGetOrgID_ok(t *testing.T) {
@@ -23,7 +23,7 @@ func init() {
 	RootCmd.AddCommand(testCmd)
 
 	testCmd.PersistentFlags().StringSliceP(testLibrariansFlag, "a", nil,
-		"comma-separated addresses (IPv4:Port) of librarian(s)")
+		"comma-separated addresses (host:port) of librarian(s)")
 	testCmd.PersistentFlags().Int(timeoutFlag, 10,
 		"timeout (seconds) for requests to librarians")
 
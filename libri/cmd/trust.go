@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/server/comm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	errMissingTrustListFile = errors.New("missing trust list file")
+	errMissingTrustIDs      = errors.New("missing one or more IDs")
+	errTrustIDNotFound      = errors.New("ID not found in trust list")
+)
+
+// trustCmd represents the librarian trust command
+var trustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "manage the organizations and peers a librarian treats as known",
+	Long: `Manage the trust list file of organization and peer IDs a librarian treats as known. A
+running librarian reloads its trust list when it receives a SIGHUP.`,
+}
+
+// trustListCmd represents the librarian trust list command
+var trustListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "print the trust list",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newTrustListEditor().list(os.Stdout)
+	},
+}
+
+// trustAddOrgCmd represents the librarian trust add-org command
+var trustAddOrgCmd = &cobra.Command{
+	Use:   "add-org ORG_ID...",
+	Short: "add organization IDs to the trust list, creating it if necessary",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newTrustListEditor().edit(args, func(tl *comm.TrustList, i id.ID) error {
+			tl.AddOrg(i)
+			return nil
+		})
+	},
+}
+
+// trustAddPeerCmd represents the librarian trust add-peer command
+var trustAddPeerCmd = &cobra.Command{
+	Use:   "add-peer PEER_ID...",
+	Short: "add peer IDs to the trust list, creating it if necessary",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newTrustListEditor().edit(args, func(tl *comm.TrustList, i id.ID) error {
+			tl.AddPeer(i)
+			return nil
+		})
+	},
+}
+
+// trustRemoveCmd represents the librarian trust remove command
+var trustRemoveCmd = &cobra.Command{
+	Use:   "remove ID...",
+	Short: "remove organization or peer IDs from the trust list",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return newTrustListEditor().edit(args, func(tl *comm.TrustList, i id.ID) error {
+			if !tl.Remove(i) {
+				return fmt.Errorf("%s: %s", errTrustIDNotFound, i)
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	librarianCmd.AddCommand(trustCmd)
+	trustCmd.AddCommand(trustListCmd)
+	trustCmd.AddCommand(trustAddOrgCmd)
+	trustCmd.AddCommand(trustAddPeerCmd)
+	trustCmd.AddCommand(trustRemoveCmd)
+}
+
+type trustListEditor struct {
+	filepath string
+}
+
+func newTrustListEditor() *trustListEditor {
+	return &trustListEditor{filepath: viper.GetString(trustListFileFlag)}
+}
+
+func (e *trustListEditor) list(w io.Writer) error {
+	tl, err := e.read(false)
+	if err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(tl, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(buf))
+	return err
+}
+
+func (e *trustListEditor) edit(hexIDs []string, apply func(*comm.TrustList, id.ID) error) error {
+	if len(hexIDs) == 0 {
+		return errMissingTrustIDs
+	}
+	ids := make([]id.ID, len(hexIDs))
+	for i, hexID := range hexIDs {
+		var err error
+		if ids[i], err = id.FromString(hexID); err != nil {
+			return err
+		}
+	}
+	tl, err := e.read(true)
+	if err != nil {
+		return err
+	}
+	for _, i := range ids {
+		if err := apply(tl, i); err != nil {
+			return err
+		}
+	}
+	return comm.WriteTrustList(e.filepath, tl)
+}
+
+// read reads the trust list, returning an empty one if the file doesn't exist and emptyIfMissing
+// is true.
+func (e *trustListEditor) read(emptyIfMissing bool) (*comm.TrustList, error) {
+	if e.filepath == "" {
+		return nil, errMissingTrustListFile
+	}
+	tl, err := comm.ReadTrustList(e.filepath)
+	if os.IsNotExist(err) && emptyIfMissing {
+		return comm.NewTrustList(), nil
+	}
+	return tl, err
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/server/comm"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustCmds_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "trust-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	fp := filepath.Join(dir, "trust.json")
+	viper.Set(trustListFileFlag, fp)
+	defer viper.Set(trustListFileFlag, "")
+
+	orgID1, orgID2, peerID := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng),
+		id.NewPseudoRandom(rng)
+	err = trustAddOrgCmd.RunE(trustAddOrgCmd, []string{orgID1.String(), orgID2.String()})
+	assert.Nil(t, err)
+	err = trustAddPeerCmd.RunE(trustAddPeerCmd, []string{peerID.String()})
+	assert.Nil(t, err)
+	err = trustRemoveCmd.RunE(trustRemoveCmd, []string{orgID1.String()})
+	assert.Nil(t, err)
+
+	tl, err := comm.ReadTrustList(fp)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{orgID2.String()}, tl.OrgIDs)
+	assert.Equal(t, []string{peerID.String()}, tl.PeerIDs)
+
+	buf := new(bytes.Buffer)
+	assert.Nil(t, newTrustListEditor().list(buf))
+	assert.Contains(t, buf.String(), orgID2.String())
+	assert.Contains(t, buf.String(), peerID.String())
+	assert.Nil(t, trustListCmd.RunE(trustListCmd, []string{}))
+}
+
+func TestTrustCmds_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "trust-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	fp := filepath.Join(dir, "trust.json")
+	orgID := id.NewPseudoRandom(rng)
+
+	// missing trust list file flag
+	viper.Set(trustListFileFlag, "")
+	err = trustListCmd.RunE(trustListCmd, []string{})
+	assert.Equal(t, errMissingTrustListFile, err)
+	err = trustAddOrgCmd.RunE(trustAddOrgCmd, []string{orgID.String()})
+	assert.Equal(t, errMissingTrustListFile, err)
+
+	viper.Set(trustListFileFlag, fp)
+	defer viper.Set(trustListFileFlag, "")
+
+	// missing file
+	err = trustListCmd.RunE(trustListCmd, []string{})
+	assert.NotNil(t, err)
+
+	// missing or bad IDs
+	err = trustAddPeerCmd.RunE(trustAddPeerCmd, []string{})
+	assert.Equal(t, errMissingTrustIDs, err)
+	err = trustAddPeerCmd.RunE(trustAddPeerCmd, []string{"not an ID"})
+	assert.NotNil(t, err)
+
+	// removing ID not in list
+	err = trustRemoveCmd.RunE(trustRemoveCmd, []string{orgID.String()})
+	assert.NotNil(t, err)
+	_, err = os.Stat(fp)
+	assert.True(t, os.IsNotExist(err))
+}
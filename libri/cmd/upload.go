@@ -2,15 +2,15 @@ package cmd
 
 import (
 	"fmt"
-	"io"
-	"mime"
-	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	lauthor "github.com/drausin/libri/libri/author"
 	"github.com/drausin/libri/libri/author/keychain"
 	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -18,19 +18,48 @@ import (
 )
 
 const (
-	upFilepathFlag = "upFilepath"
-	octetMediaType = "application/octet-stream"
+	upFilepathFlag    = "upFilepath"
+	upDirpathFlag     = "upDirpath"
+	upPreviousFlag    = "upPrevious"
+	upDerivedFromFlag = "upDerivedFrom"
+
+	upEntryFilepathFlag  = "upEntryFilepath"
+	upPropertyFlag       = "upProperty"
+	upSchemaFlag         = "upSchema"
+	upDataDictionaryFlag = "upDataDictionary"
 )
 
 var (
-	errKeychainsNotExist = errors.New("no keychains exist in the keychain directory")
-	errMissingFilepath   = errors.New("missing filepath")
+	errKeychainsNotExist  = errors.New("no keychains exist in the keychain directory")
+	errMissingFilepath    = errors.New("missing filepath")
+	errFilepathAndDirpath = errors.New("cannot give both a filepath and a dirpath")
+	errDirpathLineage     = errors.New("cannot give previous or derived-from keys with a dirpath")
+	errDirpathOptions     = errors.New("cannot give an entry filepath, properties, schema, or " +
+		"data dictionary with a dirpath")
+	errInvalidProperty       = errors.New("property must have the form key=value")
+	errInvalidSchemaArtifact = errors.New("schema artifact must have the form " +
+		"group/project/path/name@version")
 )
 
 // uploadCmd represents the upload command
 var uploadCmd = &cobra.Command{
 	Use:   "upload",
-	Short: "upload a local file to the libri network",
+	Short: "upload a local file or directory to the libri network",
+	Long: `Upload a local file to the libri network as an entry. Alternatively, upload each file
+in a local directory tree as its own entry along with a manifest entry listing their relative
+paths, keys, sizes, and modes. Downloading the manifest's envelope key with a dirpath recreates
+the directory tree.
+
+A file uploaded as a new version of an earlier upload records the earlier upload's envelope key
+(see "upPrevious") in its encrypted metadata, along with the keys of any entries it was derived
+from (see "upDerivedFrom"). The versions can then be listed with "author history" and downloaded
+with "download --downVersion".
+
+Properties (e.g., a pipeline run ID) and references to the file's schema and data dictionary can
+also be recorded in the file's encrypted metadata (see "upProperty", "upSchema", and
+"upDataDictionary"), which "author info" prints. Schema artifacts have the form
+group/project/path/name@version, where the path may contain slashes and the name may be empty,
+e.g., drausin/libri/libri/librarian/api/documents.proto/Entry@0.1.0.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return newFileUploader().upload()
 	},
@@ -43,6 +72,20 @@ func init() {
 		"number of parallel processes")
 	uploadCmd.Flags().StringP(upFilepathFlag, "f", "",
 		"path of local file to upload")
+	uploadCmd.Flags().String(upDirpathFlag, "",
+		"path of local directory to upload")
+	uploadCmd.Flags().String(upPreviousFlag, "",
+		"envelope key of the previous version of the file")
+	uploadCmd.Flags().StringSlice(upDerivedFromFlag, nil,
+		"comma-separated entry keys of the entries the file was derived from")
+	uploadCmd.Flags().String(upEntryFilepathFlag, "",
+		"relative filepath recorded in the entry metadata (default the file's name)")
+	uploadCmd.Flags().StringArray(upPropertyFlag, nil,
+		"key=value property recorded in the entry metadata, which may be given multiple times")
+	uploadCmd.Flags().String(upSchemaFlag, "",
+		"schema of the file, as group/project/path/name@version")
+	uploadCmd.Flags().String(upDataDictionaryFlag, "",
+		"data dictionary of the file, as group/project/path/name@version")
 
 	// bind viper flags
 	viper.SetEnvPrefix(envVarPrefix) // look for env vars with "LIBRI_" prefix
@@ -57,14 +100,28 @@ type fileUploader interface {
 type fileUploaderImpl struct {
 	ag  authorGetter
 	au  authorUploader
+	avu authorVersionUploader
+	adu authorDirUploader
 	mtg mediaTypeGetter
 	kc  keychainsGetter
 }
 
 func newFileUploader() fileUploader {
+	if dc := getDaemonClient(); dc != nil {
+		return &fileUploaderImpl{
+			ag:  &daemonAuthorGetter{},
+			au:  &daemonUploader{client: dc},
+			avu: &daemonVersionUploader{client: dc},
+			adu: &daemonDirUploader{client: dc},
+			mtg: &mediaTypeGetterImpl{},
+			kc:  &daemonKeychainsGetter{},
+		}
+	}
 	return &fileUploaderImpl{
 		ag:  newAuthorGetter(),
 		au:  &authorUploaderImpl{},
+		avu: &authorVersionUploaderImpl{},
+		adu: &authorDirUploaderImpl{},
 		mtg: &mediaTypeGetterImpl{},
 		kc: &keychainsGetterImpl{
 			pg: &terminalPassphraseGetter{},
@@ -73,10 +130,36 @@ func newFileUploader() fileUploader {
 }
 
 func (u *fileUploaderImpl) upload() error {
-	upFilepath := viper.GetString(upFilepathFlag)
+	upFilepath, upDirpath := viper.GetString(upFilepathFlag), viper.GetString(upDirpathFlag)
+	if upFilepath != "" && upDirpath != "" {
+		return errFilepathAndDirpath
+	}
+	lineage, err := getLineage()
+	if err != nil {
+		return err
+	}
+	opts, err := getUploadOptions()
+	if err != nil {
+		return err
+	}
+	if upDirpath != "" {
+		if lineage != nil {
+			return errDirpathLineage
+		}
+		if opts != nil {
+			return errDirpathOptions
+		}
+		return u.uploadDir(upDirpath)
+	}
 	if upFilepath == "" {
 		return errMissingFilepath
 	}
+	if opts == nil {
+		opts = &lauthor.UploadOptions{}
+	}
+	if opts.Filepath == "" {
+		opts.Filepath = filepath.Base(upFilepath)
+	}
 	mediaType, err := u.mtg.get(upFilepath)
 	if err != nil {
 		return err
@@ -101,44 +184,145 @@ func (u *fileUploaderImpl) upload() error {
 		zap.String("filepath", upFilepath),
 		zap.String("media_type", mediaType),
 	)
-	if _, err = u.au.upload(author, file, mediaType); err != nil {
+	if lineage != nil {
+		_, err = u.avu.uploadVersion(author, file, mediaType, lineage, opts)
+	} else {
+		_, err = u.au.upload(author, file, mediaType, opts)
+	}
+	if err != nil {
 		return err
 	}
 	return file.Close()
 }
 
-type mediaTypeGetter interface {
-	get(upFilepath string) (string, error)
+// getLineage returns the lineage given by the previous and derived-from flags, or nil if neither
+// is given.
+func getLineage() (*lauthor.Lineage, error) {
+	previousStr := viper.GetString(upPreviousFlag)
+	derivedFromStrs := viper.GetStringSlice(upDerivedFromFlag)
+	if previousStr == "" && len(derivedFromStrs) == 0 {
+		return nil, nil
+	}
+	lineage := &lauthor.Lineage{}
+	if previousStr != "" {
+		previous, err := id.FromString(previousStr)
+		if err != nil {
+			return nil, err
+		}
+		lineage.PreviousEnvelopeKey = previous
+	}
+	for _, derivedFromStr := range derivedFromStrs {
+		derivedFrom, err := id.FromString(derivedFromStr)
+		if err != nil {
+			return nil, err
+		}
+		lineage.DerivedFromEntryKeys = append(lineage.DerivedFromEntryKeys, derivedFrom)
+	}
+	return lineage, nil
 }
 
-type mediaTypeGetterImpl struct{}
+// getPropertyStrs returns the key=value property values. Since viper only parses string slice
+// flags, values not set directly in viper are read from the string array flag itself, which
+// unlike a string slice flag doesn't split values on commas.
+func getPropertyStrs() []string {
+	if propertyStrs, ok := viper.Get(upPropertyFlag).([]string); ok {
+		return propertyStrs
+	}
+	propertyStrs, err := uploadCmd.Flags().GetStringArray(upPropertyFlag)
+	cerrors.MaybePanic(err) // should never happen
+	return propertyStrs
+}
 
-func (*mediaTypeGetterImpl) get(upFilepath string) (string, error) {
-	file, err := os.Open(upFilepath) // nolint: gosec
-	if err != nil {
-		return "", err
+// getUploadOptions returns the upload options given by the entry filepath, property, schema, and
+// data dictionary flags, or nil if none are given.
+func getUploadOptions() (*lauthor.UploadOptions, error) {
+	entryFilepath := viper.GetString(upEntryFilepathFlag)
+	propertyStrs := getPropertyStrs()
+	schemaStr := viper.GetString(upSchemaFlag)
+	dataDictionaryStr := viper.GetString(upDataDictionaryFlag)
+	if entryFilepath == "" && len(propertyStrs) == 0 && schemaStr == "" &&
+		dataDictionaryStr == "" {
+		return nil, nil
 	}
-	head := make([]byte, 512)
-	_, err = file.Read(head)
-	if err != nil && err != io.EOF {
-		return "", err
+	opts := &lauthor.UploadOptions{Filepath: entryFilepath}
+	if len(propertyStrs) > 0 {
+		opts.Properties = make(map[string][]byte)
 	}
-	if err = file.Close(); err != nil {
-		return "", err
+	for _, propertyStr := range propertyStrs {
+		kv := strings.SplitN(propertyStr, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, errInvalidProperty
+		}
+		opts.Properties[kv[0]] = []byte(kv[1])
 	}
-	mediaType := http.DetectContentType(head)
-	if mediaType != octetMediaType {
-		// sniffing head of file worked
-		return mediaType, nil
+	var err error
+	if schemaStr != "" {
+		if opts.Schema, err = parseSchemaArtifact(schemaStr); err != nil {
+			return nil, err
+		}
 	}
-	mediaType = mime.TypeByExtension(filepath.Ext(upFilepath))
-	if mediaType != "" {
-		// get by extension worked
-		return mediaType, nil
+	if dataDictionaryStr != "" {
+		if opts.DataDictionary, err = parseSchemaArtifact(dataDictionaryStr); err != nil {
+			return nil, err
+		}
 	}
+	return opts, nil
+}
 
-	// fallback
-	return octetMediaType, nil
+// parseSchemaArtifact parses a schema artifact of the form group/project/path/name@version, where
+// the path may contain slashes and the name may be empty.
+func parseSchemaArtifact(s string) (*api.SchemaArtifact, error) {
+	at := strings.LastIndex(s, "@")
+	if at == -1 {
+		return nil, errInvalidSchemaArtifact
+	}
+	parts := strings.Split(s[:at], "/")
+	if len(parts) < 4 {
+		return nil, errInvalidSchemaArtifact
+	}
+	sa := &api.SchemaArtifact{
+		Group:   parts[0],
+		Project: parts[1],
+		Path:    strings.Join(parts[2:len(parts)-1], "/"),
+		Name:    parts[len(parts)-1],
+		Version: s[at+1:],
+	}
+	if err := api.ValidateSchemaArtifact(sa); err != nil {
+		return nil, err
+	}
+	return sa, nil
+}
+
+func (u *fileUploaderImpl) uploadDir(upDirpath string) error {
+	info, err := os.Stat(upDirpath)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", upDirpath)
+	}
+	authorKeys, selfReaderKeys, err := u.kc.get()
+	if err != nil {
+		return err
+	}
+	author, logger, err := u.ag.get(authorKeys, selfReaderKeys)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("uploading directory", zap.String("dirpath", upDirpath))
+	_, err = u.adu.uploadDir(author, upDirpath)
+	return err
+}
+
+type mediaTypeGetter interface {
+	get(upFilepath string) (string, error)
+}
+
+type mediaTypeGetterImpl struct{}
+
+func (*mediaTypeGetterImpl) get(upFilepath string) (string, error) {
+	return lauthor.DetectMediaType(upFilepath)
 }
 
 type keychainsGetter interface {
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"path"
 	"testing"
@@ -16,6 +17,8 @@ import (
 	cerrors "github.com/drausin/libri/libri/common/errors"
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/common/logging"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -124,6 +127,284 @@ func TestFileUploader_upload_err(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestFileUploader_uploadVersion(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	toUploadFile, err := ioutil.TempFile("", "to-upload")
+	defer func() { cerrors.MaybePanic(os.Remove(toUploadFile.Name())) }()
+	assert.Nil(t, err)
+	assert.Nil(t, toUploadFile.Close())
+	viper.Set(upFilepathFlag, toUploadFile.Name())
+	defer viper.Set(upPreviousFlag, "")
+	defer viper.Set(upDerivedFromFlag, nil)
+	previous := id.NewPseudoRandom(rng)
+	derivedFrom := []id.ID{id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)}
+
+	// ok
+	avu := &fixedAuthorVersionUploader{}
+	u := &fileUploaderImpl{
+		ag: &fixedAuthorGetter{
+			author: nil, // ok since we're passing it into a mocked method anyway
+			logger: logging.NewDevInfoLogger(),
+		},
+		avu: avu,
+		mtg: &fixedMediaTypeGetter{}, // ok that mediaType is nil since passing to mock
+		kc:  &fixedKeychainsGetter{}, // ok that KCs are null for same reason
+	}
+	viper.Set(upPreviousFlag, previous.String())
+	viper.Set(upDerivedFromFlag, []string{derivedFrom[0].String(), derivedFrom[1].String()})
+	err = u.upload()
+	assert.Nil(t, err)
+	assert.Equal(t, &lauthor.Lineage{
+		PreviousEnvelopeKey:  previous,
+		DerivedFromEntryKeys: derivedFrom,
+	}, avu.lineage)
+
+	// bad previous key
+	viper.Set(upPreviousFlag, "not an ID")
+	assert.NotNil(t, u.upload())
+	viper.Set(upPreviousFlag, previous.String())
+
+	// bad derived-from key
+	viper.Set(upDerivedFromFlag, []string{"not an ID"})
+	assert.NotNil(t, u.upload())
+	viper.Set(upDerivedFromFlag, nil)
+
+	// upload version error
+	u.avu = &fixedAuthorVersionUploader{err: errors.New("some upload error")}
+	assert.NotNil(t, u.upload())
+
+	// lineage with dirpath
+	viper.Set(upFilepathFlag, "")
+	viper.Set(upDirpathFlag, os.TempDir())
+	defer viper.Set(upDirpathFlag, "")
+	assert.Equal(t, errDirpathLineage, u.upload())
+}
+
+func TestFileUploader_uploadOptions(t *testing.T) {
+	toUploadFile, err := ioutil.TempFile("", "to-upload")
+	defer func() { cerrors.MaybePanic(os.Remove(toUploadFile.Name())) }()
+	assert.Nil(t, err)
+	assert.Nil(t, toUploadFile.Close())
+	viper.Set(upFilepathFlag, toUploadFile.Name())
+	defer viper.Set(upPropertyFlag, nil)
+	defer viper.Set(upEntryFilepathFlag, "")
+
+	// default entry filepath is the file's name
+	au := &fixedAuthorUploader{}
+	u := &fileUploaderImpl{
+		ag: &fixedAuthorGetter{
+			author: nil, // ok since we're passing it into a mocked method anyway
+			logger: logging.NewDevInfoLogger(),
+		},
+		au:  au,
+		mtg: &fixedMediaTypeGetter{}, // ok that mediaType is nil since passing to mock
+		kc:  &fixedKeychainsGetter{}, // ok that KCs are null for same reason
+	}
+	err = u.upload()
+	assert.Nil(t, err)
+	assert.Equal(t, &lauthor.UploadOptions{Filepath: path.Base(toUploadFile.Name())}, au.opts)
+
+	// given entry filepath and properties
+	viper.Set(upEntryFilepathFlag, "some/file.csv")
+	viper.Set(upPropertyFlag, []string{"run_id=1234", "stage=clean"})
+	err = u.upload()
+	assert.Nil(t, err)
+	assert.Equal(t, &lauthor.UploadOptions{
+		Filepath: "some/file.csv",
+		Properties: map[string][]byte{
+			"run_id": []byte("1234"),
+			"stage":  []byte("clean"),
+		},
+	}, au.opts)
+
+	// bad property
+	viper.Set(upPropertyFlag, []string{"run_id"})
+	assert.Equal(t, errInvalidProperty, u.upload())
+
+	// options with dirpath
+	viper.Set(upFilepathFlag, "")
+	viper.Set(upDirpathFlag, os.TempDir())
+	defer viper.Set(upDirpathFlag, "")
+	viper.Set(upPropertyFlag, []string{"run_id=1234"})
+	assert.Equal(t, errDirpathOptions, u.upload())
+}
+
+func TestGetUploadOptions_ok(t *testing.T) {
+	defer viper.Set(upPropertyFlag, nil)
+	defer viper.Set(upSchemaFlag, "")
+	defer viper.Set(upDataDictionaryFlag, "")
+
+	// no options given
+	opts, err := getUploadOptions()
+	assert.Nil(t, err)
+	assert.Nil(t, opts)
+
+	viper.Set(upPropertyFlag, []string{"run_id=1234", "empty="})
+	viper.Set(upSchemaFlag, "drausin/libri/libri/librarian/api/documents.proto/Entry@0.1.0")
+	viper.Set(upDataDictionaryFlag, "drausin/libri/README.md/@0.1.0")
+	opts, err = getUploadOptions()
+	assert.Nil(t, err)
+	assert.Equal(t, &lauthor.UploadOptions{
+		Properties: map[string][]byte{
+			"run_id": []byte("1234"),
+			"empty":  []byte(""),
+		},
+		Schema: &api.SchemaArtifact{
+			Group:   "drausin",
+			Project: "libri",
+			Path:    "libri/librarian/api/documents.proto",
+			Name:    "Entry",
+			Version: "0.1.0",
+		},
+		DataDictionary: &api.SchemaArtifact{
+			Group:   "drausin",
+			Project: "libri",
+			Path:    "README.md",
+			Version: "0.1.0",
+		},
+	}, opts)
+}
+
+func TestGetUploadOptions_propertyFlag(t *testing.T) {
+	// swap in a fresh flag value so the values set here don't leak into other tests
+	flag, fresh := uploadCmd.Flags().Lookup(upPropertyFlag), &cobra.Command{}
+	fresh.Flags().StringArray(upPropertyFlag, nil, "")
+	value := flag.Value
+	flag.Value = fresh.Flags().Lookup(upPropertyFlag).Value
+	defer func() { flag.Value, flag.Changed = value, false }()
+
+	assert.Nil(t, uploadCmd.Flags().Set(upPropertyFlag, "run_id=1234"))
+	assert.Nil(t, uploadCmd.Flags().Set(upPropertyFlag, "stages=clean,train"))
+
+	// check values with commas aren't split
+	opts, err := getUploadOptions()
+	assert.Nil(t, err)
+	assert.Equal(t, &lauthor.UploadOptions{
+		Properties: map[string][]byte{
+			"run_id": []byte("1234"),
+			"stages": []byte("clean,train"),
+		},
+	}, opts)
+}
+
+func TestGetUploadOptions_err(t *testing.T) {
+	defer viper.Set(upPropertyFlag, nil)
+	defer viper.Set(upSchemaFlag, "")
+	defer viper.Set(upDataDictionaryFlag, "")
+
+	viper.Set(upPropertyFlag, []string{"=1234"})
+	opts, err := getUploadOptions()
+	assert.Equal(t, errInvalidProperty, err)
+	assert.Nil(t, opts)
+
+	viper.Set(upPropertyFlag, nil)
+	viper.Set(upSchemaFlag, "drausin/libri/documents.proto")
+	opts, err = getUploadOptions()
+	assert.NotNil(t, err)
+	assert.Nil(t, opts)
+
+	viper.Set(upSchemaFlag, "")
+	viper.Set(upDataDictionaryFlag, "drausin/libri/README.md@0.1.0")
+	opts, err = getUploadOptions()
+	assert.NotNil(t, err)
+	assert.Nil(t, opts)
+}
+
+func TestParseSchemaArtifact_err(t *testing.T) {
+	cases := []string{
+		"",
+		"drausin/libri/README.md/Entry",                 // no version
+		"drausin/libri/README.md@0.1.0",                 // too few parts
+		"drausin/libri/README.md/Entry@",                // empty version
+		"drausin//README.md/Entry@0.1.0",                // empty project
+		"drausin/libri//Entry@0.1.0",                    // empty path
+		"/libri/libri/librarian/api/documents.proto/@1", // empty group
+	}
+	for i, c := range cases {
+		sa, err := parseSchemaArtifact(c)
+		assert.NotNil(t, err, i)
+		assert.Nil(t, sa, i)
+	}
+}
+
+func TestFileUploader_uploadDir_ok(t *testing.T) {
+	u := &fileUploaderImpl{
+		ag: &fixedAuthorGetter{
+			author: nil, // ok since we're passing it into a mocked method anyway
+			logger: logging.NewDevInfoLogger(),
+		},
+		adu: &fixedAuthorDirUploader{},
+		kc:  &fixedKeychainsGetter{}, // ok that KCs are null for same reason
+	}
+	toUploadDir, err := ioutil.TempDir("", "to-upload")
+	defer func() { cerrors.MaybePanic(os.RemoveAll(toUploadDir)) }()
+	assert.Nil(t, err)
+	viper.Set(upFilepathFlag, "")
+	viper.Set(upDirpathFlag, toUploadDir)
+	defer viper.Set(upDirpathFlag, "")
+
+	err = u.upload()
+	assert.Nil(t, err)
+}
+
+func TestFileUploader_uploadDir_err(t *testing.T) {
+	toUploadDir, err := ioutil.TempDir("", "to-upload")
+	defer func() { cerrors.MaybePanic(os.RemoveAll(toUploadDir)) }()
+	assert.Nil(t, err)
+	defer viper.Set(upDirpathFlag, "")
+
+	// should error on both filepath and dirpath
+	u1 := &fileUploaderImpl{}
+	viper.Set(upFilepathFlag, "some/upload/filepath")
+	viper.Set(upDirpathFlag, toUploadDir)
+	err = u1.upload()
+	assert.Equal(t, errFilepathAndDirpath, err)
+	viper.Set(upFilepathFlag, "")
+
+	// non-existent dir should throw error
+	u2 := &fileUploaderImpl{}
+	viper.Set(upDirpathFlag, "some/upload/dirpath")
+	err = u2.upload()
+	assert.NotNil(t, err)
+
+	// file instead of dir should throw error
+	toUploadFile, err := ioutil.TempFile(toUploadDir, "to-upload")
+	assert.Nil(t, err)
+	assert.Nil(t, toUploadFile.Close())
+	u3 := &fileUploaderImpl{}
+	viper.Set(upDirpathFlag, toUploadFile.Name())
+	err = u3.upload()
+	assert.NotNil(t, err)
+
+	// error getting author keys should bubble up
+	viper.Set(upDirpathFlag, toUploadDir)
+	u4 := &fileUploaderImpl{
+		kc: &fixedKeychainsGetter{err: errors.New("some get error")},
+	}
+	err = u4.upload()
+	assert.NotNil(t, err)
+
+	// error getting author should bubble up
+	u5 := &fileUploaderImpl{
+		ag: &fixedAuthorGetter{err: errors.New("some get error")},
+		kc: &fixedKeychainsGetter{}, // ok that KCs are null since passing to mock
+	}
+	err = u5.upload()
+	assert.NotNil(t, err)
+
+	// upload error should bubble up
+	u6 := &fileUploaderImpl{
+		ag: &fixedAuthorGetter{
+			author: nil, // ok since we're passing it into a mocked method anyway
+			logger: logging.NewDevInfoLogger(),
+		},
+		adu: &fixedAuthorDirUploader{err: errors.New("some upload error")},
+		kc:  &fixedKeychainsGetter{}, // ok that KCs are null since passing to mock
+	}
+	err = u6.upload()
+	assert.NotNil(t, err)
+}
+
 func TestMediaTypeGetter_get_ok(t *testing.T) {
 	uncompressed := bytes.Repeat([]byte("these bytes are uncompressed"), 25)
 	compressed := new(bytes.Buffer)
@@ -168,7 +449,7 @@ func TestMediaTypeGetter_get_ok(t *testing.T) {
 
 	mediaType, err = mtg.get(emptyFileNoExt)
 	assert.Nil(t, err)
-	assert.Equal(t, octetMediaType, mediaType)
+	assert.Equal(t, "application/octet-stream", mediaType)
 }
 
 func TestMediaTypeGetter_get_err(t *testing.T) {
@@ -258,10 +539,43 @@ func TestKeychainsGetter_get_err(t *testing.T) {
 type fixedAuthorUploader struct {
 	envelopeKey id.ID
 	err         error
+
+	opts *lauthor.UploadOptions
+}
+
+func (f *fixedAuthorUploader) upload(
+	author *lauthor.Author, content io.Reader, mediaType string, opts *lauthor.UploadOptions,
+) (id.ID, error) {
+	f.opts = opts
+	return f.envelopeKey, f.err
+}
+
+type fixedAuthorVersionUploader struct {
+	envelopeKey id.ID
+	err         error
+
+	lineage *lauthor.Lineage
+	opts    *lauthor.UploadOptions
+}
+
+func (f *fixedAuthorVersionUploader) uploadVersion(
+	author *lauthor.Author,
+	content io.Reader,
+	mediaType string,
+	lineage *lauthor.Lineage,
+	opts *lauthor.UploadOptions,
+) (id.ID, error) {
+	f.lineage = lineage
+	f.opts = opts
+	return f.envelopeKey, f.err
+}
+
+type fixedAuthorDirUploader struct {
+	envelopeKey id.ID
+	err         error
 }
 
-func (f *fixedAuthorUploader) upload(author *lauthor.Author, content io.Reader, mediaType string) (
-	id.ID, error) {
+func (f *fixedAuthorDirUploader) uploadDir(author *lauthor.Author, dirpath string) (id.ID, error) {
 	return f.envelopeKey, f.err
 }
 
@@ -3,6 +3,7 @@ package ecid
 import (
 	"crypto/ecdsa"
 	crand "crypto/rand"
+	"encoding/asn1"
 	"errors"
 	"fmt"
 	"io"
@@ -81,6 +82,27 @@ func NewPseudoRandom(rng *mrand.Rand) ID {
 	return newRandom(rng)
 }
 
+// NewRandomWithWork creates a new ID instance whose ID has at least the given amount of work (see
+// id.Work), trying new random keys until it finds one. Each extra bit of work doubles the
+// expected number of tries.
+func NewRandomWithWork(minWork uint) ID {
+	return newRandomWithWork(crand.Reader, minWork)
+}
+
+// NewPseudoRandomWithWork creates a new ID instance with at least the given amount of work using
+// a math.Rand source of entropy.
+func NewPseudoRandomWithWork(rng *mrand.Rand, minWork uint) ID {
+	return newRandomWithWork(rng, minWork)
+}
+
+func newRandomWithWork(reader io.Reader, minWork uint) ID {
+	for {
+		if i := newRandom(reader); id.Work(i.ID()) >= minWork {
+			return i
+		}
+	}
+}
+
 func newRandom(reader io.Reader) ID {
 	key, err := ecdsa.GenerateKey(Curve, reader)
 	cerrors.MaybePanic(err) // should never happen
@@ -156,6 +178,30 @@ func FromPublicKeyBytes(buf []byte) (*ecdsa.PublicKey, error) {
 	return unmarshalCompressed(buf)
 }
 
+// Sign returns the ASN.1 DER encoding of the ECDSA signature of the hash by the private key.
+func Sign(priv *ecdsa.PrivateKey, hash []byte) ([]byte, error) {
+	r, s, err := ecdsa.Sign(crand.Reader, priv, hash)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(ecdsaSignature{R: r, S: s})
+}
+
+// Verify returns whether the ASN.1 DER-encoded ECDSA signature of the hash is valid for the public
+// key.
+func Verify(pub *ecdsa.PublicKey, hash, sig []byte) bool {
+	var es ecdsaSignature
+	rest, err := asn1.Unmarshal(sig, &es)
+	if err != nil || len(rest) != 0 || es.R == nil || es.S == nil {
+		return false
+	}
+	return ecdsa.Verify(pub, hash, es.R, es.S)
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
 // marshalCompressed marshals a secp256k1 public key to a compressed binary format.
 // Credit:
 //  - https://github.com/kmackay/micro-ecc/blob/1fce01e69c3f3c179cb9b6238391307426c5e887/
@@ -48,6 +48,14 @@ func TestEcid_NewPsuedoRandom(t *testing.T) {
 	}
 }
 
+func TestEcid_NewPseudoRandomWithWork(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for minWork := uint(0); minWork <= 6; minWork += 3 {
+		val := NewPseudoRandomWithWork(rng, minWork)
+		assert.True(t, id.Work(val.ID()) >= minWork)
+	}
+}
+
 func TestEcid_String(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	for c := 0; c < 10; c++ {
@@ -101,6 +109,24 @@ func TestEcid_Key_Sign(t *testing.T) {
 	}
 }
 
+func TestSignVerify(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	hash := sha256.Sum256([]byte("some value to sign"))
+	val1, val2 := NewPseudoRandom(rng), NewPseudoRandom(rng)
+
+	sig, err := Sign(val1.Key(), hash[:])
+	assert.Nil(t, err)
+	assert.True(t, Verify(&val1.Key().PublicKey, hash[:], sig))
+
+	// check other key, other hash, and malformed signatures don't verify
+	otherHash := sha256.Sum256([]byte("some other value"))
+	assert.False(t, Verify(&val2.Key().PublicKey, hash[:], sig))
+	assert.False(t, Verify(&val1.Key().PublicKey, otherHash[:], sig))
+	assert.False(t, Verify(&val1.Key().PublicKey, hash[:], sig[:len(sig)-1]))
+	assert.False(t, Verify(&val1.Key().PublicKey, hash[:], append(sig, 0)))
+	assert.False(t, Verify(&val1.Key().PublicKey, hash[:], nil))
+}
+
 func TestEcid_ID(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	i := NewPseudoRandom(rng)
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	crand "crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"math/bits"
 	mrand "math/rand"
 
 	"github.com/drausin/libri/libri/common/errors"
@@ -125,6 +127,21 @@ func FromPublicKey(pubKey *ecdsa.PublicKey) ID {
 	return FromInt(pubKey.X)
 }
 
+// Work returns the number of leading zero bits in the SHA-256 hash of the ID, which takes about
+// 2^n random tries to find for n bits. Requiring a minimum amount of work makes it expensive for
+// a peer to choose an ID close to a particular key.
+func Work(x ID) uint {
+	hash := sha256.Sum256(x.Bytes())
+	n := uint(0)
+	for _, b := range hash {
+		n += uint(bits.LeadingZeros8(b))
+		if b != 0 {
+			break
+		}
+	}
+	return n
+}
+
 // Hex returns the 64-char hex value of a 32-byte values.
 func Hex(val []byte) string {
 	format := "%064x"
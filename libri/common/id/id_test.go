@@ -2,6 +2,7 @@ package id
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"math/big"
 	"math/rand"
 	"strings"
@@ -151,6 +152,23 @@ func TestID_Bytes(t *testing.T) {
 	}
 }
 
+func TestWork(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	maxWork := uint(0)
+	for c := 0; c < 256; c++ {
+		x := NewPseudoRandom(rng)
+		hash := sha256.Sum256(x.Bytes())
+		expected := uint(Length*8 - new(big.Int).SetBytes(hash[:]).BitLen())
+		assert.Equal(t, expected, Work(x))
+		if expected > maxWork {
+			maxWork = expected
+		}
+	}
+
+	// about 1 in 256 IDs has at least 8 bits of work, so we should see some with a few bits
+	assert.True(t, maxWork >= 4)
+}
+
 func TestHex(t *testing.T) {
 	cases := [][]byte{
 		{0},
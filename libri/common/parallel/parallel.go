@@ -0,0 +1,29 @@
+package parallel
+
+import "sync"
+
+// Do calls fn for each index in [0, n) using the given number of simultaneous workers,
+// returning the error (or nil) from each call, in index order.
+func Do(n int, parallelism uint32, fn func(i int) error) []error {
+	idxs := make(chan int, parallelism)
+	go func() {
+		for i := 0; i < n; i++ {
+			idxs <- i
+		}
+		close(idxs)
+	}()
+	wg := new(sync.WaitGroup)
+	errs := make([]error, n)
+	for c := uint32(0); c < parallelism; c++ {
+		wg.Add(1)
+		go func() {
+			for i := range idxs {
+				// each worker writes to distinct indices, so no need to lock
+				errs[i] = fn(i)
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	return errs
+}
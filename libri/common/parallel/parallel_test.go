@@ -0,0 +1,44 @@
+package parallel
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDo(t *testing.T) {
+	errOdd := errors.New("odd index")
+	for _, parallelism := range []uint32{1, 3, 16} {
+		for _, n := range []int{0, 1, 10} {
+			var nCalls, nRunning, maxRunning int32
+			errs := Do(n, parallelism, func(i int) error {
+				atomic.AddInt32(&nCalls, 1)
+				running := atomic.AddInt32(&nRunning, 1)
+				defer atomic.AddInt32(&nRunning, -1)
+				for {
+					prev := atomic.LoadInt32(&maxRunning)
+					if running <= prev ||
+						atomic.CompareAndSwapInt32(&maxRunning, prev, running) {
+						break
+					}
+				}
+				if i%2 == 1 {
+					return errOdd
+				}
+				return nil
+			})
+			assert.Equal(t, int32(n), nCalls)
+			assert.True(t, maxRunning <= int32(parallelism))
+			assert.Len(t, errs, n)
+			for i, err := range errs {
+				if i%2 == 1 {
+					assert.Equal(t, errOdd, err)
+				} else {
+					assert.Nil(t, err)
+				}
+			}
+		}
+	}
+}
@@ -1,21 +1,23 @@
 package parse
 
 import (
-	"fmt"
 	"net"
+	"strconv"
 )
 
-// Addr parses a net.TCPAddr from a host address and port.
+// Addr parses a net.TCPAddr from a host and port, where the host may be an IPv4 or IPv6 address
+// or a DNS name. DNS names resolve to an IPv4 address when they have one.
 func Addr(host string, port int) (*net.TCPAddr, error) {
-	return net.ResolveTCPAddr("tcp4", fmt.Sprintf("%s:%d", host, port))
+	return net.ResolveTCPAddr("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
 }
 
-// Addrs parses an array of net.TCPAddrs from an array of IPv4:Port address strings.
+// Addrs parses an array of net.TCPAddrs from an array of host:port address strings, where IPv6
+// hosts are in brackets, e.g., [::1]:20100.
 func Addrs(addrs []string) ([]*net.TCPAddr, error) {
 	netAddrs := make([]*net.TCPAddr, 0, len(addrs))
 	nErrs := 0
 	for _, a := range addrs {
-		netAddr, err := net.ResolveTCPAddr("tcp4", a)
+		netAddr, err := net.ResolveTCPAddr("tcp", a)
 		if err != nil {
 			nErrs++
 			if nErrs == len(addrs) {
@@ -28,3 +30,18 @@ func Addrs(addrs []string) ([]*net.TCPAddr, error) {
 	}
 	return netAddrs, nil
 }
+
+// HostPorts checks that each address is a host:port string with a numeric port, without
+// resolving the host, so DNS names may be resolved later when they are dialed.
+func HostPorts(addrs []string) error {
+	for _, a := range addrs {
+		_, port, err := net.SplitHostPort(a)
+		if err != nil {
+			return err
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return err
+		}
+	}
+	return nil
+}
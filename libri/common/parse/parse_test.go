@@ -16,6 +16,7 @@ func TestAddr(t *testing.T) {
 		{"192.168.1.1", 20100, &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 20100}},
 		{"192.168.1.1", 11001, &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 11001}},
 		{"localhost", 20100, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 20100}},
+		{"2001:db8::1", 20100, &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 20100}},
 	}
 	for _, c := range cases {
 		actual, err := Addr(c.ip, c.port)
@@ -29,11 +30,13 @@ func TestAddrs_ok(t *testing.T) {
 		"192.168.1.1:20100",
 		"192.168.1.1:11001",
 		"localhost:20100",
+		"[2001:db8::1]:20100",
 	}
 	expectedNetAddrs := []*net.TCPAddr{
 		{IP: net.ParseIP("192.168.1.1"), Port: 20100},
 		{IP: net.ParseIP("192.168.1.1"), Port: 11001},
 		{IP: net.ParseIP("127.0.0.1"), Port: 20100},
+		{IP: net.ParseIP("2001:db8::1"), Port: 20100},
 	}
 	actualNetAddrs, err := Addrs(addrs)
 
@@ -47,7 +50,7 @@ func TestParseAddrs_err(t *testing.T) {
 	addrs := []string{
 		"192.168.1.1",         // no port
 		"192.168.1.1:A",       // bad port
-		"192::168::1:1:11001", // IPv6 instead of IPv4
+		"192::168::1:1:11001", // IPv6 without brackets
 		"192.168.1.1.11001",   // bad port delimiter
 	}
 
@@ -70,3 +73,15 @@ func TestParseAddrs_multi_err(t *testing.T) {
 	assert.Nil(t, as2)
 	assert.NotNil(t, err)
 }
+
+func TestHostPorts(t *testing.T) {
+	ok := []string{"192.168.1.1:20100", "[2001:db8::1]:20100", "librarian.example.com:20100"}
+	assert.Nil(t, HostPorts(ok))
+	assert.Nil(t, HostPorts(nil))
+
+	bad := []string{"192.168.1.1", "librarian.example.com:A", "2001:db8::1:20100",
+		"librarian.example.com:100000"}
+	for _, a := range bad {
+		assert.NotNil(t, HostPorts([]string{a}), a)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/proto"
 )
 
 // Checker checks that a key or value is value.
@@ -105,3 +108,36 @@ func (hc *hashChecker) Check(key []byte, value []byte) error {
 	}
 	return nil
 }
+
+type documentChecker struct {
+	hc KeyValueChecker
+}
+
+// NewDocumentKeyValueChecker returns a new KeyValueChecker that checks that the key is the
+// api.GetKey key of the marshaled api.Document value, i.e., the SHA256 hash of the value for
+// immutable documents and the pointer key for pointers.
+func NewDocumentKeyValueChecker() KeyValueChecker {
+	return &documentChecker{
+		hc: NewHashKeyValueChecker(),
+	}
+}
+
+func (dc *documentChecker) Check(key []byte, value []byte) error {
+	hashErr := dc.hc.Check(key, value)
+	if hashErr == nil {
+		return nil
+	}
+	doc := &api.Document{}
+	if err := proto.Unmarshal(value, doc); err != nil {
+		return err
+	}
+	pointer, ok := doc.Contents.(*api.Document_Pointer)
+	if !ok || pointer.Pointer == nil {
+		return hashErr
+	}
+	pointerKey := api.GetPointerKey(pointer.Pointer.AuthorPublicKey, pointer.Pointer.Name)
+	if !bytes.Equal(key, pointerKey.Bytes()) {
+		return errors.New("key does not equal pointer key of value")
+	}
+	return nil
+}
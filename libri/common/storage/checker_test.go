@@ -3,8 +3,12 @@ package storage
 import (
 	"bytes"
 	"crypto/sha256"
+	"math/rand"
 	"testing"
 
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -107,3 +111,39 @@ func TestHashChecker_Check_err(t *testing.T) {
 	c := NewHashKeyValueChecker()
 	assert.NotNil(t, c.Check([]byte{0, 1, 2}, []byte{0, 1, 2}))
 }
+
+func TestDocumentChecker_Check_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	c := NewDocumentKeyValueChecker()
+
+	doc, key := api.NewTestDocument(rng)
+	value, err := proto.Marshal(doc)
+	assert.Nil(t, err)
+	assert.Nil(t, c.Check(key.Bytes(), value))
+
+	pointer, _ := api.NewTestPointer(rng)
+	value, err = proto.Marshal(&api.Document{Contents: &api.Document_Pointer{Pointer: pointer}})
+	assert.Nil(t, err)
+	pointerKey := api.GetPointerKey(pointer.AuthorPublicKey, pointer.Name)
+	assert.Nil(t, c.Check(pointerKey.Bytes(), value))
+}
+
+func TestDocumentChecker_Check_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	c := NewDocumentKeyValueChecker()
+
+	// not a document
+	assert.NotNil(t, c.Check([]byte{0, 1, 2}, []byte{0, 1, 2}))
+
+	// wrong key for immutable document
+	doc, _ := api.NewTestDocument(rng)
+	value, err := proto.Marshal(doc)
+	assert.Nil(t, err)
+	assert.NotNil(t, c.Check(id.NewPseudoRandom(rng).Bytes(), value))
+
+	// wrong key for pointer
+	pointer, _ := api.NewTestPointer(rng)
+	value, err = proto.Marshal(&api.Document{Contents: &api.Document_Pointer{Pointer: pointer}})
+	assert.Nil(t, err)
+	assert.NotNil(t, c.Check(id.NewPseudoRandom(rng).Bytes(), value))
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"crypto/hmac"
 	"crypto/sha256"
+	"sync"
 
 	"github.com/drausin/libri/libri/common/db"
 	"github.com/drausin/libri/libri/common/errors"
@@ -102,6 +103,9 @@ type DocumentSLD interface {
 type documentSLD struct {
 	sld StorerLoaderDeleter
 	c   KeyValueChecker
+
+	// serializes pointer overwrites
+	pointerMu sync.Mutex
 }
 
 // NewDocumentSLD creates a new NamespaceSL for the "entries" namespace
@@ -114,11 +118,12 @@ func NewDocumentSLD(kvdb db.KVDB) DocumentSLD {
 			NewExactLengthChecker(EntriesKeyLength),
 			NewMaxLengthChecker(MaxEntriesValueLength),
 		),
-		c: NewHashKeyValueChecker(),
+		c: NewDocumentKeyValueChecker(),
 	}
 }
 
-// Store checks that the key equals the SHA256 hash of the value before storing it.
+// Store checks that the key equals the SHA256 hash of the value (or pointer key for pointers)
+// before storing it. Pointers only overwrite stored pointers with lower sequence numbers.
 func (dsld *documentSLD) Store(key id.ID, value *api.Document) error {
 	if err := api.ValidateDocument(value); err != nil {
 		return err
@@ -129,6 +134,13 @@ func (dsld *documentSLD) Store(key id.ID, value *api.Document) error {
 	if err := dsld.c.Check(keyBytes, valueBytes); err != nil {
 		return err
 	}
+	if pointer, ok := value.Contents.(*api.Document_Pointer); ok {
+		dsld.pointerMu.Lock()
+		defer dsld.pointerMu.Unlock()
+		if err := dsld.checkPointerUpdate(key, pointer.Pointer); err != nil {
+			return err
+		}
+	}
 	if err := dsld.sld.Store(keyBytes, valueBytes); err != nil {
 		return err
 	}
@@ -173,6 +185,24 @@ func (dsld *documentSLD) Delete(key id.ID) error {
 	return dsld.sld.Delete(key.Bytes())
 }
 
+// checkPointerUpdate checks that the pointer's sequence number is greater than that of any stored
+// pointer it would overwrite, though re-storing the same pointer is fine.
+func (dsld *documentSLD) checkPointerUpdate(key id.ID, updated *api.Pointer) error {
+	stored, err := dsld.Load(key)
+	if err != nil || stored == nil {
+		return err
+	}
+	storedPointer := stored.GetPointer()
+	if storedPointer == nil {
+		// should never happen b/c pointer keys aren't hashes of their values
+		return api.ErrUnexpectedDocumentType
+	}
+	if updated.Sequence > storedPointer.Sequence || proto.Equal(storedPointer, updated) {
+		return nil
+	}
+	return api.ErrStalePointer
+}
+
 func (dsld *documentSLD) loadCheckBytes(key id.ID) ([]byte, error) {
 	keyBytes := key.Bytes()
 	valueBytes, err := dsld.sld.Load(keyBytes)
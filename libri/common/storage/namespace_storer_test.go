@@ -147,6 +147,45 @@ func TestDocumentSLD_Store_err(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestDocumentSLD_StorePointer(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dsld := NewDocumentSLD(db.NewMemoryDB())
+	p1, authorKey := api.NewTestPointer(rng)
+	key := api.GetPointerKey(p1.AuthorPublicKey, p1.Name)
+	newPointerDoc := func(sequence uint64) *api.Document {
+		p := &api.Pointer{
+			AuthorPublicKey:   p1.AuthorPublicKey,
+			Name:              p1.Name,
+			Sequence:          sequence,
+			TargetEnvelopeKey: api.RandBytes(rng, api.DocumentKeyLength),
+		}
+		assert.Nil(t, api.SignPointer(p, authorKey.Key()))
+		return &api.Document{Contents: &api.Document_Pointer{Pointer: p}}
+	}
+	doc1 := &api.Document{Contents: &api.Document_Pointer{Pointer: p1}}
+	assert.Nil(t, dsld.Store(key, doc1))
+
+	// re-storing same pointer is fine
+	assert.Nil(t, dsld.Store(key, doc1))
+
+	// higher sequence overwrites
+	doc3 := newPointerDoc(3)
+	assert.Nil(t, dsld.Store(key, doc3))
+	loaded, err := dsld.Load(key)
+	assert.Nil(t, err)
+	assert.Equal(t, doc3, loaded)
+
+	// lower or same sequence with different target doesn't
+	assert.Equal(t, api.ErrStalePointer, dsld.Store(key, newPointerDoc(2)))
+	assert.Equal(t, api.ErrStalePointer, dsld.Store(key, newPointerDoc(3)))
+	loaded, err = dsld.Load(key)
+	assert.Nil(t, err)
+	assert.Equal(t, doc3, loaded)
+
+	// pointer under wrong key
+	assert.NotNil(t, dsld.Store(id.NewPseudoRandom(rng), newPointerDoc(4)))
+}
+
 func TestDocumentSLD_Iterate(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 
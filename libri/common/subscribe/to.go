@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/rand"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/drausin/libri/libri/librarian/client"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/net/context"
 )
 
 const (
@@ -57,10 +60,59 @@ const (
 	// DefaultRecentCacheSize is the default recent publications LRU cache size.
 	DefaultRecentCacheSize = 1 << 12
 
+	// DefaultSelection is the default way of selecting the peers to subscribe to.
+	DefaultSelection = RandomSelection
+
+	// DefaultRotationPeriod is the default period after which a subscription is ended and
+	// replaced by one to another peer. Zero means subscriptions are never rotated.
+	DefaultRotationPeriod = time.Duration(0)
+
 	// errQueueSize is the size of the error queue used to calculate the running error rate.
 	errQueueSize = 100
 )
 
+// Selection determines how the peers to subscribe to are selected.
+type Selection int
+
+const (
+	// RandomSelection selects peers (approximately) uniformly at random from the ID space.
+	RandomSelection Selection = iota
+
+	// SpreadSelection selects peers spread as evenly as possible across the routing table
+	// buckets, which avoids clustering subscriptions in the few buckets of small clusters.
+	SpreadSelection
+
+	// HealthySelection selects the healthy peers with the best response history first.
+	HealthySelection
+)
+
+// ErrUnknownSelection indicates when a selection name is not recognized.
+var ErrUnknownSelection = errors.New("unknown subscription selection")
+
+var selectionNames = map[Selection]string{
+	RandomSelection:  "random",
+	SpreadSelection:  "spread",
+	HealthySelection: "healthy",
+}
+
+// String returns the name of the selection.
+func (s Selection) String() string {
+	if name, in := selectionNames[s]; in {
+		return name
+	}
+	return fmt.Sprintf("Selection(%d)", int(s))
+}
+
+// ParseSelection returns the Selection with the given (case-insensitive) name.
+func ParseSelection(name string) (Selection, error) {
+	for s, sName := range selectionNames {
+		if strings.ToLower(name) == sName {
+			return s, nil
+		}
+	}
+	return RandomSelection, ErrUnknownSelection
+}
+
 // ToParameters define how the collection of subscriptions to other peers will be managed.
 type ToParameters struct {
 	// NSubscriptions is the number of concurrent subscriptions maintained to other peers.
@@ -80,6 +132,14 @@ type ToParameters struct {
 	// RecentCacheSize is the size of the LRU cache used in deduplicating and grouping
 	// publications.
 	RecentCacheSize uint32
+
+	// Selection determines how the peers to subscribe to are selected.
+	Selection Selection
+
+	// RotationPeriod is the (approximate) period after which each subscription is ended and
+	// replaced by one to another peer. Each subscription's lifetime is jittered uniformly
+	// within +/- 50% of this period so they don't all rotate at once. Zero disables rotation.
+	RotationPeriod time.Duration
 }
 
 // NewDefaultToParameters returns a *ToParameters object with default values.
@@ -90,9 +150,27 @@ func NewDefaultToParameters() *ToParameters {
 		Timeout:         DefaultTimeout,
 		MaxErrRate:      DefaultMaxErrRate,
 		RecentCacheSize: DefaultRecentCacheSize,
+		Selection:       DefaultSelection,
+		RotationPeriod:  DefaultRotationPeriod,
 	}
 }
 
+// EstimatedConsistency returns the estimated fraction of publications seen by the collection of
+// subscriptions, i.e., the probability that at least one of the NSubscriptions receives a given
+// publication when each passes it with probability FPRate.
+func (p *ToParameters) EstimatedConsistency() float64 {
+	return 1.0 - math.Pow(1.0-float64(p.FPRate), float64(p.NSubscriptions))
+}
+
+// lifetime returns the jittered lifetime of a single subscription, or zero if subscriptions are
+// not rotated.
+func (p *ToParameters) lifetime(rng *rand.Rand) time.Duration {
+	if p.RotationPeriod <= 0 {
+		return 0
+	}
+	return p.RotationPeriod/2 + time.Duration(rng.Int63n(int64(p.RotationPeriod)))
+}
+
 // To maintains active subscriptions to a collection of peers, merging their publications into a
 // single, deduplicated stream.
 type To interface {
@@ -164,6 +242,14 @@ func (t *to) Begin() error {
 		t.dedup()
 	}(wg)
 
+	t.logger.Info("beginning subscriptions",
+		zap.Uint32("n_subscriptions", t.params.NSubscriptions),
+		zap.Float32("false_positive_rate", t.params.FPRate),
+		zap.Stringer("selection", t.params.Selection),
+		zap.Duration("rotation_period", t.params.RotationPeriod),
+		zap.Float64("estimated_consistency", t.params.EstimatedConsistency()),
+	)
+
 	// monitor non-fatal errors, sending fatal err if too many
 	go cerrors.MonitorRunningErrors(errs, fatal, errQueueSize, t.params.MaxErrRate, t.logger)
 
@@ -182,15 +268,17 @@ func (t *to) Begin() error {
 					fatal <- err
 					return
 				}
+				lifetime := t.params.lifetime(rng)
 				t.logger.Debug("beginning new subscription",
 					zap.Int("index", int(i)),
 					zap.Float64("false_positive_rate", fp),
 					zap.String("peer_address", address),
+					zap.Duration("lifetime", lifetime),
 				)
 				select {
 				case <-t.end:
 					return
-				case errs <- t.sb.begin(lc, sub, t.received, errs, t.end):
+				case errs <- t.sb.begin(lc, sub, t.received, errs, t.end, lifetime):
 				}
 				cerrors.MaybePanic(t.csb.Remove(address)) // should never happen
 			}
@@ -279,9 +367,10 @@ func getLoggerValues(pub *api.Publication) []zapcore.Field {
 }
 
 type subscriptionBeginner interface {
-	// begin begins a subscription and writes publications to received and errors to errs
+	// begin begins a subscription and writes publications to received and errors to errs. If
+	// lifetime is positive, the subscription ends gracefully after that duration.
 	begin(lc api.Subscriber, sub *api.Subscription, received chan *pubValueReceipt,
-		errs chan error, end chan struct{}) error
+		errs chan error, end chan struct{}, lifetime time.Duration) error
 }
 
 type subscriptionBeginnerImpl struct {
@@ -298,6 +387,7 @@ func (sb *subscriptionBeginnerImpl) begin(
 	received chan *pubValueReceipt,
 	errs chan error,
 	end chan struct{},
+	lifetime time.Duration,
 ) error {
 
 	rq := client.NewSubscribeRequest(sb.peerID, sb.orgID, sub)
@@ -305,6 +395,11 @@ func (sb *subscriptionBeginnerImpl) begin(
 	if err != nil {
 		return err
 	}
+	if lifetime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, lifetime)
+		defer cancel()
+	}
 	subscribeClient, err := lc.Subscribe(ctx, rq)
 	if err != nil {
 		return err
@@ -314,6 +409,10 @@ func (sb *subscriptionBeginnerImpl) begin(
 		if err == io.EOF {
 			return nil
 		}
+		if err != nil && ctx.Err() == context.DeadlineExceeded {
+			// subscription lifetime elapsed, so end gracefully to rotate to another peer
+			return nil
+		}
 		if err != nil {
 			return err
 		}
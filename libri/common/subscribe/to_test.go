@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/drausin/libri/libri/common/ecid"
 	cerrors "github.com/drausin/libri/libri/common/errors"
@@ -245,7 +246,7 @@ func TestSubscriptionBeginnerImpl_Begin_ok(t *testing.T) {
 	responseErrs <- nil
 
 	go func() {
-		beginErr := sb.begin(lc, sub, received, errs, end)
+		beginErr := sb.begin(lc, sub, received, errs, end, 0)
 		assert.Nil(t, beginErr)
 	}()
 
@@ -262,7 +263,7 @@ func TestSubscriptionBeginnerImpl_Begin_ok(t *testing.T) {
 
 	// start again
 	go func() {
-		beginErr := sb.begin(lc, sub, received, errs, end)
+		beginErr := sb.begin(lc, sub, received, errs, end, 0)
 		assert.Nil(t, beginErr)
 	}()
 
@@ -301,7 +302,7 @@ func TestSubscriptionBeginnerImpl_Begin_err(t *testing.T) {
 		params:     NewDefaultToParameters(),
 	}
 	lc1 := &fixedSubscriber{}
-	err = sb1.begin(lc1, sub, received, errs, end)
+	err = sb1.begin(lc1, sub, received, errs, end, 0)
 	assert.NotNil(t, err)
 
 	// check Subscribe error bubbles up
@@ -315,7 +316,7 @@ func TestSubscriptionBeginnerImpl_Begin_err(t *testing.T) {
 		client: nil,
 		err:    errors.New("some Subscribe error"),
 	}
-	err = sb2.begin(lc2, sub, received, errs, end)
+	err = sb2.begin(lc2, sub, received, errs, end, 0)
 	assert.NotNil(t, err)
 
 	// check Recv error bubbles up
@@ -335,7 +336,7 @@ func TestSubscriptionBeginnerImpl_Begin_err(t *testing.T) {
 	}
 	responses3 <- nil
 	responseErrs3 <- errors.New("some Recv error")
-	err = sb3.begin(lc3, sub, received, errs, end)
+	err = sb3.begin(lc3, sub, received, errs, end, 0)
 	assert.NotNil(t, err)
 
 	// check newPublicationValueReceipt error bubbles up
@@ -362,10 +363,82 @@ func TestSubscriptionBeginnerImpl_Begin_err(t *testing.T) {
 		Value: value,
 	}
 	responseErrs4 <- nil
-	err = sb4.begin(lc4, sub, received, errs, end)
+	err = sb4.begin(lc4, sub, received, errs, end, 0)
 	assert.NotNil(t, err)
 }
 
+func TestSubscriptionBeginnerImpl_Begin_rotate(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	sb := subscriptionBeginnerImpl{
+		peerID:     ecid.NewPseudoRandom(rng),
+		peerSigner: &fixedSigner{signature: "some.signature.jtw"},
+		orgSigner:  &fixedSigner{signature: "some.org-signature.jtw"},
+		params:     NewDefaultToParameters(),
+	}
+	lc := &fixedSubscriber{client: &deadlineLibrarianSubscribeClient{}}
+	sub, err := NewFPSubscription(DefaultFPRate, rng)
+	assert.Nil(t, err)
+	received := make(chan *pubValueReceipt, 1)
+	errs := make(chan error)
+	end := make(chan struct{})
+
+	// check subscription ends gracefully once its lifetime has elapsed
+	err = sb.begin(lc, sub, received, errs, end, 10*time.Millisecond)
+	assert.Nil(t, err)
+}
+
+func TestToParameters_EstimatedConsistency(t *testing.T) {
+	cases := []struct {
+		fpRate         float32
+		nSubscriptions uint32
+		expected       float64
+	}{
+		{fpRate: 1.0, nSubscriptions: 1, expected: 1.0},
+		{fpRate: 0.9, nSubscriptions: 5, expected: 0.99999},
+		{fpRate: 0.75, nSubscriptions: 10, expected: 0.999999},
+		{fpRate: 0.5, nSubscriptions: 10, expected: 0.999},
+		{fpRate: 0.3, nSubscriptions: 20, expected: 0.999},
+		{fpRate: 0.5, nSubscriptions: 0, expected: 0.0},
+	}
+	for i, c := range cases {
+		params := &ToParameters{FPRate: c.fpRate, NSubscriptions: c.nSubscriptions}
+		assert.InDelta(t, c.expected, params.EstimatedConsistency(), 1e-3, i)
+	}
+}
+
+func TestToParameters_lifetime(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := NewDefaultToParameters()
+	assert.Zero(t, params.lifetime(rng))
+
+	params.RotationPeriod = time.Hour
+	for c := 0; c < 16; c++ {
+		lifetime := params.lifetime(rng)
+		assert.True(t, lifetime >= 30*time.Minute)
+		assert.True(t, lifetime < 90*time.Minute)
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	for _, s := range []Selection{RandomSelection, SpreadSelection, HealthySelection} {
+		parsed, err := ParseSelection(s.String())
+		assert.Nil(t, err)
+		assert.Equal(t, s, parsed)
+	}
+	parsed, err := ParseSelection("Healthy")
+	assert.Nil(t, err)
+	assert.Equal(t, HealthySelection, parsed)
+
+	_, err = ParseSelection("some other selection")
+	assert.Equal(t, ErrUnknownSelection, err)
+}
+
+func TestSelection_String(t *testing.T) {
+	assert.Equal(t, "healthy", HealthySelection.String())
+	assert.Equal(t, "Selection(-1)", Selection(-1).String())
+	assert.Equal(t, "Selection(3)", Selection(3).String())
+}
+
 func TestDedup(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	value1 := api.NewTestPublication(rng)
@@ -424,7 +497,8 @@ type fixedSubscriptionBeginner struct {
 }
 
 func (f *fixedSubscriptionBeginner) begin(lc api.Subscriber, sub *api.Subscription,
-	received chan *pubValueReceipt, errs chan error, end chan struct{}) error {
+	received chan *pubValueReceipt, errs chan error, end chan struct{},
+	lifetime time.Duration) error {
 	if f.subscribeErr == nil {
 		prv := <-f.received
 		err := <-f.errs
@@ -453,9 +527,24 @@ type fixedSubscriber struct {
 
 func (f *fixedSubscriber) Subscribe(ctx context.Context, in *api.SubscribeRequest,
 	opts ...grpc.CallOption) (api.Librarian_SubscribeClient, error) {
+	if dc, ok := f.client.(*deadlineLibrarianSubscribeClient); ok {
+		dc.ctx = ctx
+	}
 	return f.client, f.err
 }
 
+// deadlineLibrarianSubscribeClient blocks on Recv() until its context is done, mimicking a
+// subscription without any new publications.
+type deadlineLibrarianSubscribeClient struct {
+	fixedLibrarianSubscribeClient
+	ctx context.Context
+}
+
+func (f *deadlineLibrarianSubscribeClient) Recv() (*api.SubscribeResponse, error) {
+	<-f.ctx.Done()
+	return nil, f.ctx.Err()
+}
+
 type fixedSigner struct {
 	signature string
 	err       error
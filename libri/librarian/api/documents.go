@@ -88,8 +88,14 @@ var (
 	ErrEmptyPageKeys = errors.New("empty page keys")
 )
 
-// GetKey calculates the key from the has of the proto.Message.
+// GetKey calculates the key from the hash of the proto.Message. The key of a Pointer document is
+// instead its GetPointerKey, since pointers are mutable.
 func GetKey(value proto.Message) (id.ID, error) {
+	if doc, ok := value.(*Document); ok && doc != nil {
+		if c, ok := doc.Contents.(*Document_Pointer); ok && c.Pointer != nil {
+			return GetPointerKey(c.Pointer.AuthorPublicKey, c.Pointer.Name), nil
+		}
+	}
 	valueBytes, err := proto.Marshal(value)
 	if err != nil {
 		return nil, err
@@ -107,6 +113,8 @@ func GetAuthorPub(d *Document) []byte {
 		return c.Page.AuthorPublicKey
 	case *Document_Envelope:
 		return c.Envelope.AuthorPublicKey
+	case *Document_Pointer:
+		return c.Pointer.AuthorPublicKey
 	}
 	panic(ErrUnknownDocumentType)
 }
@@ -158,6 +166,8 @@ func ValidateDocument(d *Document) error {
 		return ValidateEntry(c.Entry)
 	case *Document_Page:
 		return ValidatePage(c.Page)
+	case *Document_Pointer:
+		return ValidatePointer(c.Pointer)
 	}
 	return ErrUnknownDocumentType
 }
@@ -15,8 +15,12 @@ It has these top-level messages:
 	EntryMetadata
 	SchemaArtifact
 	Page
+	Manifest
+	ManifestFile
+	Pointer
 	RequestMetadata
 	ResponseMetadata
+	PeerCertificate
 	IntroduceRequest
 	IntroduceResponse
 	FindRequest
@@ -75,12 +79,13 @@ func (x CompressionCodec) String() string {
 }
 func (CompressionCodec) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
 
-// Document contains either an Envelope, Entry, or Page message.
+// Document contains either an Envelope, Entry, Page, or Pointer message.
 type Document struct {
 	// Types that are valid to be assigned to Contents:
 	//	*Document_Envelope
 	//	*Document_Entry
 	//	*Document_Page
+	//	*Document_Pointer
 	Contents isDocument_Contents `protobuf_oneof:"contents"`
 }
 
@@ -102,10 +107,14 @@ type Document_Entry struct {
 type Document_Page struct {
 	Page *Page `protobuf:"bytes,3,opt,name=page,oneof"`
 }
+type Document_Pointer struct {
+	Pointer *Pointer `protobuf:"bytes,4,opt,name=pointer,oneof"`
+}
 
 func (*Document_Envelope) isDocument_Contents() {}
 func (*Document_Entry) isDocument_Contents()    {}
 func (*Document_Page) isDocument_Contents()     {}
+func (*Document_Pointer) isDocument_Contents()  {}
 
 func (m *Document) GetContents() isDocument_Contents {
 	if m != nil {
@@ -135,12 +144,20 @@ func (m *Document) GetPage() *Page {
 	return nil
 }
 
+func (m *Document) GetPointer() *Pointer {
+	if x, ok := m.GetContents().(*Document_Pointer); ok {
+		return x.Pointer
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Document) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Document_OneofMarshaler, _Document_OneofUnmarshaler, _Document_OneofSizer, []interface{}{
 		(*Document_Envelope)(nil),
 		(*Document_Entry)(nil),
 		(*Document_Page)(nil),
+		(*Document_Pointer)(nil),
 	}
 }
 
@@ -163,6 +180,11 @@ func _Document_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Page); err != nil {
 			return err
 		}
+	case *Document_Pointer:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Pointer); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Document.Contents has unexpected type %T", x)
@@ -197,6 +219,14 @@ func _Document_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffe
 		err := b.DecodeMessage(msg)
 		m.Contents = &Document_Page{msg}
 		return true, err
+	case 4: // contents.pointer
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Pointer)
+		err := b.DecodeMessage(msg)
+		m.Contents = &Document_Pointer{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -221,6 +251,11 @@ func _Document_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(3<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Document_Pointer:
+		s := proto.Size(x.Pointer)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -380,6 +415,12 @@ type EntryMetadata struct {
 	Schema *SchemaArtifact `protobuf:"bytes,9,opt,name=schema" json:"schema,omitempty"`
 	// data dictionary of the entry plaintext
 	DataDictionary *SchemaArtifact `protobuf:"bytes,10,opt,name=dataDictionary" json:"dataDictionary,omitempty"`
+	// key of the entry of the previous version of this entry
+	PreviousEntryKey []byte `protobuf:"bytes,11,opt,name=previous_entry_key,json=previousEntryKey,proto3" json:"previous_entry_key,omitempty"`
+	// key of an envelope of the previous version of this entry, used to walk the version history
+	PreviousEnvelopeKey []byte `protobuf:"bytes,12,opt,name=previous_envelope_key,json=previousEnvelopeKey,proto3" json:"previous_envelope_key,omitempty"`
+	// keys of the entries this entry was derived from
+	DerivedFromEntryKeys [][]byte `protobuf:"bytes,13,rep,name=derived_from_entry_keys,json=derivedFromEntryKeys,proto3" json:"derived_from_entry_keys,omitempty"`
 }
 
 func (m *EntryMetadata) Reset()                    { *m = EntryMetadata{} }
@@ -457,6 +498,27 @@ func (m *EntryMetadata) GetDataDictionary() *SchemaArtifact {
 	return nil
 }
 
+func (m *EntryMetadata) GetPreviousEntryKey() []byte {
+	if m != nil {
+		return m.PreviousEntryKey
+	}
+	return nil
+}
+
+func (m *EntryMetadata) GetPreviousEnvelopeKey() []byte {
+	if m != nil {
+		return m.PreviousEnvelopeKey
+	}
+	return nil
+}
+
+func (m *EntryMetadata) GetDerivedFromEntryKeys() [][]byte {
+	if m != nil {
+		return m.DerivedFromEntryKeys
+	}
+	return nil
+}
+
 // SchemaArtifact denotes the schema artifact associated with the serialized plaintext of a
 // particular entry. Artifacts can mainly be two separate types:
 //
@@ -579,6 +641,146 @@ func (m *Page) GetCiphertextMac() []byte {
 	return nil
 }
 
+// Manifest lists the files of a directory uploaded as separate entries, allowing the directory tree
+// to be recreated from the manifest entry.
+type Manifest struct {
+	// files in the directory tree, sorted by filepath
+	Files []*ManifestFile `protobuf:"bytes,1,rep,name=files" json:"files,omitempty"`
+}
+
+func (m *Manifest) Reset()                    { *m = Manifest{} }
+func (m *Manifest) String() string            { return proto.CompactTextString(m) }
+func (*Manifest) ProtoMessage()               {}
+func (*Manifest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *Manifest) GetFiles() []*ManifestFile {
+	if m != nil {
+		return m.Files
+	}
+	return nil
+}
+
+// ManifestFile describes a single file in a Manifest.
+type ManifestFile struct {
+	// filepath relative to the manifest's directory, using forward slash separators
+	Filepath string `protobuf:"bytes,1,opt,name=filepath" json:"filepath,omitempty"`
+	// 32-byte key of the Envelope for the file's Entry
+	EnvelopeKey []byte `protobuf:"bytes,2,opt,name=envelope_key,json=envelopeKey,proto3" json:"envelope_key,omitempty"`
+	// 32-byte key of the file's Entry
+	EntryKey []byte `protobuf:"bytes,3,opt,name=entry_key,json=entryKey,proto3" json:"entry_key,omitempty"`
+	// size (in bytes) of the file contents
+	Size uint64 `protobuf:"varint,4,opt,name=size" json:"size,omitempty"`
+	// file mode permission bits
+	Mode uint32 `protobuf:"varint,5,opt,name=mode" json:"mode,omitempty"`
+	// 32-byte SHA-256 hash of the file contents
+	Sha256 []byte `protobuf:"bytes,6,opt,name=sha256,proto3" json:"sha256,omitempty"`
+}
+
+func (m *ManifestFile) Reset()                    { *m = ManifestFile{} }
+func (m *ManifestFile) String() string            { return proto.CompactTextString(m) }
+func (*ManifestFile) ProtoMessage()               {}
+func (*ManifestFile) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *ManifestFile) GetFilepath() string {
+	if m != nil {
+		return m.Filepath
+	}
+	return ""
+}
+
+func (m *ManifestFile) GetEnvelopeKey() []byte {
+	if m != nil {
+		return m.EnvelopeKey
+	}
+	return nil
+}
+
+func (m *ManifestFile) GetEntryKey() []byte {
+	if m != nil {
+		return m.EntryKey
+	}
+	return nil
+}
+
+func (m *ManifestFile) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *ManifestFile) GetMode() uint32 {
+	if m != nil {
+		return m.Mode
+	}
+	return 0
+}
+
+func (m *ManifestFile) GetSha256() []byte {
+	if m != nil {
+		return m.Sha256
+	}
+	return nil
+}
+
+// Pointer is a mutable, author-signed named reference to an Envelope. Unlike other documents,
+// whose keys are the hashes of their contents, a Pointer's key is the hash of its author public
+// key and name, so it gives a stable handle for the newest of a series of uploads. Librarians only
+// overwrite a stored Pointer with one with a higher sequence number.
+type Pointer struct {
+	// ECDSA public key of the pointer author
+	AuthorPublicKey []byte `protobuf:"bytes,1,opt,name=author_public_key,json=authorPublicKey,proto3" json:"author_public_key,omitempty"`
+	// name of the pointer, unique per author
+	Name string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	// sequence number, incremented each time the author updates the pointer
+	Sequence uint64 `protobuf:"varint,3,opt,name=sequence" json:"sequence,omitempty"`
+	// 32-byte key of the Envelope the pointer refers to
+	TargetEnvelopeKey []byte `protobuf:"bytes,4,opt,name=target_envelope_key,json=targetEnvelopeKey,proto3" json:"target_envelope_key,omitempty"`
+	// ASN.1 ECDSA signature by the author key of the SHA-256 hash of the marshalled pointer
+	// without this signature
+	Signature []byte `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *Pointer) Reset()                    { *m = Pointer{} }
+func (m *Pointer) String() string            { return proto.CompactTextString(m) }
+func (*Pointer) ProtoMessage()               {}
+func (*Pointer) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *Pointer) GetAuthorPublicKey() []byte {
+	if m != nil {
+		return m.AuthorPublicKey
+	}
+	return nil
+}
+
+func (m *Pointer) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Pointer) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *Pointer) GetTargetEnvelopeKey() []byte {
+	if m != nil {
+		return m.TargetEnvelopeKey
+	}
+	return nil
+}
+
+func (m *Pointer) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Document)(nil), "api.Document")
 	proto.RegisterType((*Envelope)(nil), "api.Envelope")
@@ -586,56 +788,74 @@ func init() {
 	proto.RegisterType((*EntryMetadata)(nil), "api.EntryMetadata")
 	proto.RegisterType((*SchemaArtifact)(nil), "api.SchemaArtifact")
 	proto.RegisterType((*Page)(nil), "api.Page")
+	proto.RegisterType((*Manifest)(nil), "api.Manifest")
+	proto.RegisterType((*ManifestFile)(nil), "api.ManifestFile")
+	proto.RegisterType((*Pointer)(nil), "api.Pointer")
 	proto.RegisterEnum("api.CompressionCodec", CompressionCodec_name, CompressionCodec_value)
 }
 
 func init() { proto.RegisterFile("librarian/api/documents.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 718 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0x4d, 0x4f, 0xdb, 0x4a,
-	0x14, 0xc5, 0xf9, 0x80, 0xf8, 0x42, 0x3e, 0x18, 0x40, 0xcf, 0xe2, 0x89, 0xf7, 0x78, 0x91, 0x5e,
-	0x4b, 0xa1, 0x0a, 0x12, 0x95, 0xaa, 0xaa, 0x55, 0x17, 0xe5, 0x43, 0xa5, 0x42, 0xd0, 0xc8, 0xb0,
-	0xea, 0x26, 0x1a, 0xc6, 0x17, 0x32, 0x25, 0xb6, 0x47, 0xe3, 0x09, 0xc2, 0x2c, 0xbb, 0xea, 0xae,
-	0x3f, 0xa2, 0x7f, 0xa9, 0xff, 0xa6, 0x9b, 0x6a, 0xae, 0x9d, 0xc4, 0x49, 0xa9, 0xd4, 0xae, 0x3c,
-	0x73, 0xee, 0x99, 0x33, 0xf7, 0x9e, 0xb9, 0xd7, 0xb0, 0x31, 0x90, 0x97, 0x9a, 0x6b, 0xc9, 0xa3,
-	0x5d, 0xae, 0xe4, 0x6e, 0x10, 0x8b, 0x61, 0x88, 0x91, 0x49, 0x3a, 0x4a, 0xc7, 0x26, 0x66, 0x65,
-	0xae, 0x64, 0xfb, 0xb3, 0x03, 0xb5, 0xc3, 0x3c, 0xc0, 0x76, 0xa0, 0x86, 0xd1, 0x2d, 0x0e, 0x62,
-	0x85, 0x9e, 0xb3, 0xe9, 0x6c, 0x2d, 0xee, 0xd5, 0x3b, 0x5c, 0xc9, 0xce, 0x51, 0x0e, 0x1e, 0xcf,
-	0xf9, 0x63, 0x02, 0x6b, 0x43, 0x15, 0x23, 0xa3, 0x53, 0xaf, 0x44, 0x4c, 0xc8, 0x99, 0x46, 0xa7,
-	0xc7, 0x73, 0x7e, 0x16, 0x62, 0xff, 0x42, 0x45, 0xf1, 0x6b, 0xf4, 0xca, 0x44, 0x71, 0x89, 0xd2,
-	0xe5, 0xd7, 0x56, 0x88, 0x02, 0xfb, 0x00, 0x35, 0x11, 0x47, 0xc6, 0x66, 0xd5, 0xfe, 0xe6, 0x40,
-	0x6d, 0x74, 0x13, 0xfb, 0x1b, 0x5c, 0x92, 0xe8, 0xdd, 0x60, 0x4a, 0xb9, 0x2c, 0xd9, 0xab, 0x8d,
-	0x4e, 0x4f, 0x30, 0x65, 0xdb, 0xb0, 0xcc, 0x87, 0xa6, 0x1f, 0xeb, 0x9e, 0x1a, 0x5e, 0x0e, 0xa4,
-	0x20, 0x52, 0x89, 0x48, 0xcd, 0x2c, 0xd0, 0x25, 0x3c, 0xe7, 0x6a, 0xe4, 0x01, 0x4e, 0x71, 0xcb,
-	0x19, 0x37, 0x0b, 0x4c, 0xb8, 0xff, 0x43, 0x03, 0xf1, 0xa6, 0x27, 0xa4, 0xea, 0xa3, 0x36, 0x78,
-	0x67, 0xbc, 0x0a, 0x11, 0xeb, 0x88, 0x37, 0x07, 0x63, 0x90, 0x3d, 0x05, 0x36, 0x4d, 0xeb, 0x85,
-	0x5c, 0x78, 0x55, 0xa2, 0xb6, 0xa6, 0xa8, 0xa7, 0x5c, 0xb4, 0xbf, 0x3b, 0x50, 0x25, 0x5b, 0x1e,
-	0x4e, 0xdb, 0x79, 0x38, 0xed, 0x8d, 0xdc, 0xb9, 0xd2, 0x8c, 0x73, 0x99, 0x6f, 0xd6, 0x1e, 0xfb,
-	0xb5, 0x0a, 0x89, 0x57, 0xde, 0x2c, 0x5b, 0x7b, 0x2c, 0x70, 0x82, 0x69, 0xc2, 0xfe, 0x83, 0x25,
-	0xa1, 0x91, 0x1b, 0x0c, 0x7a, 0x46, 0x86, 0x48, 0x45, 0xd4, 0xfd, 0xc5, 0x1c, 0xbb, 0x90, 0x21,
-	0xb2, 0x5d, 0x58, 0x09, 0xd1, 0xf0, 0x80, 0x1b, 0x5e, 0x2c, 0x37, 0xab, 0x81, 0x8d, 0x42, 0x85,
-	0x9a, 0x9f, 0xc3, 0x5f, 0x0f, 0x1c, 0xa0, 0xc2, 0xe7, 0xe9, 0xd0, 0xda, 0xcf, 0x87, 0x6c, 0xf5,
-	0x5f, 0x2b, 0x50, 0xa7, 0xea, 0x4f, 0xf3, 0x30, 0xdb, 0x00, 0x08, 0x31, 0x90, 0xbc, 0x67, 0xd2,
-	0xbc, 0xcd, 0x5c, 0xdf, 0x25, 0xe4, 0x22, 0x55, 0xc8, 0xf6, 0x61, 0x59, 0xc4, 0xa1, 0xd2, 0x98,
-	0x24, 0x32, 0x8e, 0x7a, 0x22, 0x0e, 0x50, 0x90, 0x0b, 0x8d, 0xbd, 0x35, 0x72, 0xe1, 0x60, 0x12,
-	0x3d, 0xb0, 0x41, 0xbf, 0x25, 0x66, 0x10, 0xf6, 0x18, 0x9a, 0x85, 0x1c, 0x13, 0x79, 0x9f, 0x75,
-	0x60, 0xc5, 0x6f, 0x4c, 0xe0, 0x73, 0x79, 0x8f, 0xf6, 0xc1, 0x67, 0x8a, 0xc9, 0x1f, 0x5c, 0x14,
-	0x8b, 0x60, 0x3b, 0xb0, 0x3c, 0x8c, 0x46, 0xb7, 0x60, 0x90, 0x29, 0x56, 0x49, 0xb1, 0x55, 0x0c,
-	0x90, 0xe6, 0x13, 0x98, 0xc2, 0x0a, 0x16, 0x35, 0x8b, 0xb8, 0xd5, 0xdd, 0x07, 0x50, 0x3a, 0x56,
-	0xa8, 0x8d, 0xc4, 0xc4, 0x5b, 0xd8, 0x2c, 0x6f, 0x2d, 0xee, 0xb5, 0x27, 0x73, 0x34, 0xb2, 0xac,
-	0xd3, 0x1d, 0x93, 0x08, 0xf7, 0x0b, 0xa7, 0xd8, 0x3a, 0xd4, 0xae, 0xe4, 0x00, 0x15, 0x37, 0x7d,
-	0xaf, 0x46, 0x66, 0x8e, 0xf7, 0x6c, 0x07, 0xe6, 0x13, 0xd1, 0xc7, 0x90, 0x7b, 0x2e, 0xb5, 0xd1,
-	0x0a, 0x69, 0x9f, 0x13, 0xf4, 0x46, 0x1b, 0x79, 0xc5, 0x85, 0xf1, 0x73, 0x0a, 0x7b, 0x05, 0x0d,
-	0x7b, 0xd9, 0xa1, 0x14, 0x46, 0xc6, 0x11, 0xd7, 0xa9, 0x07, 0xbf, 0x3e, 0x34, 0x43, 0x5d, 0x7f,
-	0x0d, 0xcd, 0x99, 0x24, 0x59, 0x0b, 0xca, 0xa3, 0xfe, 0x76, 0x7d, 0xbb, 0x64, 0xab, 0x50, 0xbd,
-	0xe5, 0x83, 0x21, 0xe6, 0xa3, 0x9a, 0x6d, 0x5e, 0x96, 0x5e, 0x38, 0xed, 0x4f, 0x0e, 0x34, 0xa6,
-	0x6f, 0xb0, 0xe4, 0x6b, 0x1d, 0x0f, 0x55, 0x2e, 0x90, 0x6d, 0x98, 0x07, 0x0b, 0x4a, 0xc7, 0x1f,
-	0x51, 0x18, 0x12, 0x71, 0xfd, 0xd1, 0x96, 0x31, 0x3b, 0x30, 0xa6, 0x4f, 0x0f, 0xed, 0xfa, 0xb4,
-	0xb6, 0x58, 0xc4, 0xf3, 0x01, 0x70, 0x7d, 0x5a, 0x5b, 0x85, 0x5b, 0xd4, 0xb6, 0x57, 0xe8, 0x05,
-	0x5d, 0x7f, 0xb4, 0x6d, 0x7f, 0x71, 0xa0, 0x62, 0x47, 0xec, 0x8f, 0xe6, 0x74, 0x15, 0xaa, 0x32,
-	0x0a, 0xf0, 0x8e, 0xd2, 0xa9, 0xfb, 0xd9, 0x86, 0xfd, 0x03, 0x50, 0x98, 0xaa, 0xec, 0x6f, 0x53,
-	0x40, 0x7e, 0xb3, 0xef, 0xb6, 0x1f, 0x41, 0x6b, 0xb6, 0xdb, 0x59, 0x0d, 0x2a, 0x67, 0xef, 0xcf,
-	0x8e, 0x5a, 0x73, 0x76, 0xf5, 0xf6, 0xc3, 0xbb, 0x6e, 0xcb, 0xb9, 0x9c, 0xa7, 0x1f, 0xfa, 0xb3,
-	0x1f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x99, 0x7c, 0xfb, 0xd4, 0xf1, 0x05, 0x00, 0x00,
+	// 947 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x56, 0xcd, 0x6e, 0x23, 0x45,
+	0x10, 0xce, 0xf8, 0x27, 0xf1, 0x94, 0x7f, 0x62, 0x77, 0x12, 0x76, 0x94, 0x25, 0x90, 0x1d, 0x09,
+	0x36, 0x6c, 0x90, 0x23, 0x79, 0xb5, 0x2b, 0x04, 0xe2, 0x40, 0xb2, 0x59, 0x82, 0x56, 0x59, 0xa2,
+	0xd9, 0x3d, 0x71, 0xb1, 0x3a, 0x33, 0x15, 0xbb, 0x89, 0xe7, 0x87, 0x9e, 0xb6, 0xb5, 0xde, 0x23,
+	0x2f, 0xc0, 0x7b, 0x70, 0x84, 0x2b, 0x8f, 0xc1, 0xdb, 0x70, 0x41, 0x5d, 0xdd, 0x63, 0x8f, 0x4d,
+	0x90, 0x36, 0x27, 0x77, 0x7f, 0xf5, 0x55, 0x75, 0x55, 0x75, 0x7d, 0x3d, 0x86, 0x83, 0x89, 0xb8,
+	0x96, 0x5c, 0x0a, 0x9e, 0x9c, 0xf0, 0x4c, 0x9c, 0x44, 0x69, 0x38, 0x8d, 0x31, 0x51, 0x79, 0x3f,
+	0x93, 0xa9, 0x4a, 0x59, 0x95, 0x67, 0xc2, 0xff, 0xc3, 0x81, 0xc6, 0x0b, 0x6b, 0x60, 0xc7, 0xd0,
+	0xc0, 0x64, 0x86, 0x93, 0x34, 0x43, 0xcf, 0x39, 0x74, 0x8e, 0x9a, 0x83, 0x76, 0x9f, 0x67, 0xa2,
+	0x7f, 0x6e, 0xc1, 0x8b, 0x8d, 0x60, 0x41, 0x60, 0x3e, 0xd4, 0x31, 0x51, 0x72, 0xee, 0x55, 0x88,
+	0x09, 0x96, 0xa9, 0xe4, 0xfc, 0x62, 0x23, 0x30, 0x26, 0xf6, 0x29, 0xd4, 0x32, 0x3e, 0x42, 0xaf,
+	0x4a, 0x14, 0x97, 0x28, 0x57, 0x7c, 0xa4, 0x03, 0x91, 0x81, 0x1d, 0xc1, 0x56, 0x96, 0x8a, 0x44,
+	0xa1, 0xf4, 0x6a, 0xc4, 0x69, 0x19, 0x8e, 0xc1, 0x2e, 0x36, 0x82, 0xc2, 0x7c, 0x0a, 0xd0, 0x08,
+	0xd3, 0x44, 0xe9, 0xfc, 0xfd, 0xbf, 0x1d, 0x68, 0x14, 0x39, 0xb1, 0x87, 0xe0, 0xd2, 0x61, 0xc3,
+	0x5b, 0x9c, 0x53, 0xd6, 0x2d, 0x9d, 0xa4, 0x92, 0xf3, 0x57, 0x38, 0x67, 0x4f, 0xa0, 0xc7, 0xa7,
+	0x6a, 0x9c, 0xca, 0x61, 0x36, 0xbd, 0x9e, 0x88, 0x90, 0x48, 0x15, 0x22, 0x6d, 0x1b, 0xc3, 0x15,
+	0xe1, 0x96, 0x2b, 0x91, 0x47, 0xb8, 0xc2, 0xad, 0x1a, 0xae, 0x31, 0x2c, 0xb9, 0x9f, 0x41, 0x07,
+	0xf1, 0x76, 0x18, 0x8a, 0x6c, 0x8c, 0x52, 0xe1, 0x3b, 0x45, 0xe9, 0xb7, 0x82, 0x36, 0xe2, 0xed,
+	0xd9, 0x02, 0x64, 0x5f, 0x02, 0x5b, 0xa5, 0x0d, 0x63, 0x1e, 0x7a, 0x75, 0xa2, 0x76, 0x57, 0xa8,
+	0x97, 0x3c, 0xf4, 0xff, 0x71, 0xa0, 0x4e, 0x0d, 0xbc, 0x3b, 0x6d, 0xe7, 0xee, 0xb4, 0x0f, 0x6c,
+	0x8f, 0x2b, 0x6b, 0x3d, 0xb6, 0x1d, 0x7e, 0x08, 0xae, 0xfe, 0xd5, 0x11, 0x72, 0xaf, 0x7a, 0x58,
+	0xd5, 0xed, 0xd1, 0xc0, 0x2b, 0x9c, 0xe7, 0xec, 0x11, 0xb4, 0x42, 0x89, 0x5c, 0x61, 0x34, 0x54,
+	0x22, 0x46, 0x2a, 0xa2, 0x1d, 0x34, 0x2d, 0xf6, 0x56, 0xc4, 0xc8, 0x4e, 0x60, 0x27, 0x46, 0xc5,
+	0x23, 0xae, 0x78, 0xb9, 0x5c, 0x53, 0x03, 0x2b, 0x4c, 0xa5, 0x9a, 0x9f, 0xc3, 0x83, 0x3b, 0x1c,
+	0xa8, 0xf0, 0x4d, 0x72, 0xda, 0xfb, 0xaf, 0x93, 0xae, 0xfe, 0xaf, 0x3a, 0xb4, 0xa9, 0xfa, 0x4b,
+	0x6b, 0x66, 0x07, 0x00, 0x31, 0x46, 0x82, 0x0f, 0xd5, 0xdc, 0x0e, 0xa4, 0x1b, 0xb8, 0x84, 0xbc,
+	0x9d, 0x67, 0xc8, 0x4e, 0xa1, 0x17, 0xa6, 0x71, 0x26, 0x31, 0xcf, 0x45, 0x9a, 0x0c, 0xc3, 0x34,
+	0xc2, 0x90, 0xba, 0xd0, 0x19, 0xec, 0x51, 0x17, 0xce, 0x96, 0xd6, 0x33, 0x6d, 0x0c, 0xba, 0xe1,
+	0x1a, 0xc2, 0x1e, 0xc3, 0x76, 0x29, 0xc7, 0x5c, 0xbc, 0x37, 0xb3, 0x5a, 0x0b, 0x3a, 0x4b, 0xf8,
+	0x8d, 0x78, 0x8f, 0xfa, 0xc2, 0xd7, 0x8a, 0xb1, 0x17, 0x1e, 0x96, 0x8b, 0x60, 0xc7, 0xd0, 0x9b,
+	0x26, 0xc5, 0x29, 0x18, 0x99, 0x88, 0x75, 0x8a, 0xd8, 0x2d, 0x1b, 0x28, 0xe6, 0x17, 0xb0, 0x82,
+	0x95, 0x5a, 0xb4, 0x5d, 0xc6, 0x75, 0xdc, 0x53, 0x80, 0x4c, 0xa6, 0x19, 0x4a, 0x25, 0x30, 0xf7,
+	0xb6, 0x0e, 0xab, 0x47, 0xcd, 0x81, 0xbf, 0x54, 0x5c, 0xd1, 0xb2, 0xfe, 0xd5, 0x82, 0x44, 0x78,
+	0x50, 0xf2, 0x62, 0xfb, 0xd0, 0xb8, 0x11, 0x13, 0xcc, 0xb8, 0x1a, 0x7b, 0x0d, 0x6a, 0xe6, 0x62,
+	0xcf, 0x8e, 0x61, 0x33, 0x0f, 0xc7, 0x18, 0x73, 0xcf, 0xa5, 0x31, 0xda, 0xa1, 0xd8, 0x6f, 0x08,
+	0xfa, 0x4e, 0x2a, 0x71, 0xc3, 0x43, 0x15, 0x58, 0x0a, 0xfb, 0x06, 0x3a, 0xfa, 0xb0, 0x17, 0x22,
+	0x54, 0x22, 0x4d, 0xb8, 0x9c, 0x7b, 0xf0, 0xff, 0x4e, 0x6b, 0x54, 0x2d, 0x89, 0x4c, 0xe2, 0x4c,
+	0xa4, 0xd3, 0x7c, 0xb8, 0xd4, 0x6d, 0xd3, 0x48, 0xa2, 0xb0, 0x9c, 0x17, 0xfa, 0x1d, 0xc0, 0x5e,
+	0x89, 0x6d, 0x14, 0x4f, 0x0e, 0x2d, 0x72, 0xd8, 0x59, 0x3a, 0x18, 0x9b, 0xf6, 0x79, 0x06, 0x0f,
+	0x22, 0x94, 0x62, 0x86, 0xd1, 0xf0, 0x46, 0xa6, 0xf1, 0xf2, 0x94, 0xdc, 0x6b, 0xd3, 0xfc, 0xef,
+	0x5a, 0xf3, 0x4b, 0x99, 0xc6, 0xc5, 0x49, 0xf9, 0xfe, 0xb7, 0xb0, 0xbd, 0xd6, 0x3d, 0xd6, 0x85,
+	0x6a, 0x21, 0x3c, 0x37, 0xd0, 0x4b, 0xb6, 0x0b, 0xf5, 0x19, 0x9f, 0x4c, 0xd1, 0xbe, 0x21, 0x66,
+	0xf3, 0x75, 0xe5, 0x2b, 0xc7, 0xff, 0xd5, 0x81, 0xce, 0x6a, 0xe9, 0x9a, 0x3c, 0x92, 0xe9, 0x34,
+	0xb3, 0x01, 0xcc, 0x86, 0x79, 0xb0, 0x95, 0xc9, 0xf4, 0x67, 0x0c, 0x15, 0x05, 0x71, 0x83, 0x62,
+	0xcb, 0x98, 0x56, 0xb2, 0x1a, 0xd3, 0x04, 0xba, 0x01, 0xad, 0x35, 0x96, 0x70, 0xab, 0x4c, 0x37,
+	0xa0, 0xb5, 0x8e, 0x30, 0x43, 0xa9, 0x87, 0x98, 0x46, 0xcb, 0x0d, 0x8a, 0xad, 0xff, 0x9b, 0x03,
+	0x35, 0xad, 0xfd, 0x7b, 0x3d, 0x20, 0xbb, 0x50, 0x17, 0x49, 0x84, 0xef, 0x28, 0x9d, 0x76, 0x60,
+	0x36, 0xec, 0x13, 0x80, 0x92, 0xdc, 0xcd, 0x33, 0x58, 0x42, 0x3e, 0x50, 0x10, 0xfe, 0x53, 0x68,
+	0x5c, 0xf2, 0x44, 0xdc, 0x60, 0xae, 0xd8, 0x63, 0xa8, 0xeb, 0x81, 0xcb, 0x3d, 0x87, 0xe6, 0xb7,
+	0x47, 0xe3, 0x52, 0x58, 0x5f, 0x8a, 0x09, 0x06, 0xc6, 0xee, 0xff, 0xee, 0x40, 0xab, 0x8c, 0xaf,
+	0x8c, 0xae, 0xb3, 0x36, 0xba, 0x8f, 0xa0, 0xb5, 0x32, 0x19, 0xe6, 0x66, 0x9a, 0x58, 0x9a, 0x88,
+	0x95, 0x4f, 0x44, 0x75, 0xed, 0x13, 0xc1, 0xa0, 0x46, 0x2a, 0xad, 0x91, 0x4a, 0x69, 0xad, 0xb1,
+	0x38, 0x8d, 0x8c, 0x72, 0xdb, 0x01, 0xad, 0xd9, 0x47, 0xb0, 0x99, 0x8f, 0xf9, 0xe0, 0xd9, 0x73,
+	0xab, 0x51, 0xbb, 0xf3, 0xff, 0x74, 0x60, 0xcb, 0x7e, 0xaf, 0xee, 0xd5, 0xf6, 0xe2, 0x66, 0x2b,
+	0xa5, 0x9b, 0xdd, 0x87, 0x46, 0x8e, 0xbf, 0x4c, 0x31, 0x09, 0x8b, 0x77, 0x68, 0xb1, 0x67, 0x7d,
+	0xd8, 0x51, 0x5c, 0x8e, 0x50, 0xad, 0x0a, 0xc1, 0x74, 0xbd, 0x67, 0x4c, 0x65, 0x19, 0x7c, 0x0c,
+	0x6e, 0x2e, 0x46, 0x09, 0x57, 0x53, 0x89, 0xf6, 0xb9, 0x5e, 0x02, 0x4f, 0x3e, 0x87, 0xee, 0xfa,
+	0xf3, 0xc8, 0x1a, 0x50, 0x7b, 0xfd, 0xe3, 0xeb, 0xf3, 0xee, 0x86, 0x5e, 0x7d, 0xff, 0xd3, 0x0f,
+	0x57, 0x5d, 0xe7, 0x7a, 0x93, 0xfe, 0x2b, 0x3c, 0xfd, 0x77, 0x00, 0x6c, 0xf1, 0xcd, 0x04, 0x4c,
+	0x08, 0x00, 0x00,
 }
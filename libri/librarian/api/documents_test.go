@@ -15,6 +15,23 @@ func TestGetKey(t *testing.T) {
 	key, err := GetKey(value)
 	assert.Nil(t, err)
 	assert.Nil(t, ValidateBytes(key.Bytes(), DocumentKeyLength, "key"))
+
+	// pointer key doesn't depend on sequence or target
+	p, authorKey := NewTestPointer(rng)
+	key1, err := GetKey(&Document{&Document_Pointer{Pointer: p}})
+	assert.Nil(t, err)
+	assert.Equal(t, GetPointerKey(p.AuthorPublicKey, p.Name), key1)
+	p.Sequence++
+	p.TargetEnvelopeKey = RandBytes(rng, DocumentKeyLength)
+	assert.Nil(t, SignPointer(p, authorKey.Key()))
+	key2, err := GetKey(&Document{&Document_Pointer{Pointer: p}})
+	assert.Nil(t, err)
+	assert.Equal(t, key1, key2)
+
+	// nil document errors rather than panics
+	key, err = GetKey((*Document)(nil))
+	assert.NotNil(t, err)
+	assert.Nil(t, key)
 }
 
 func TestGetAuthorPub(t *testing.T) {
@@ -36,6 +53,10 @@ func TestGetAuthorPub(t *testing.T) {
 	envelope := NewTestEnvelope(rng)
 	envelope.AuthorPublicKey = expected
 	assert.Equal(t, expected, GetAuthorPub(&Document{&Document_Envelope{Envelope: envelope}}))
+
+	pointer, _ := NewTestPointer(rng)
+	assert.Equal(t, pointer.AuthorPublicKey,
+		GetAuthorPub(&Document{&Document_Pointer{Pointer: pointer}}))
 }
 
 func TestGetEntryPageKeys_ok(t *testing.T) {
@@ -95,6 +116,10 @@ func TestValidateDocument_ok(t *testing.T) {
 
 	d3 := &Document{&Document_Page{NewTestPage(rng)}}
 	assert.Nil(t, ValidateDocument(d3))
+
+	p, _ := NewTestPointer(rng)
+	d4 := &Document{&Document_Pointer{p}}
+	assert.Nil(t, ValidateDocument(d4))
 }
 
 func TestValidateEnvelope_ok(t *testing.T) {
@@ -48,6 +48,8 @@ type RequestMetadata struct {
 	PubKey []byte `protobuf:"bytes,2,opt,name=pub_key,json=pubKey,proto3" json:"pub_key,omitempty"`
 	// (optional) organization ECDSA public key
 	OrgPubKey []byte `protobuf:"bytes,3,opt,name=org_pub_key,json=orgPubKey,proto3" json:"org_pub_key,omitempty"`
+	// (optional) organization-issued certificate for the peer ECDSA public key
+	Cert *PeerCertificate `protobuf:"bytes,4,opt,name=cert" json:"cert,omitempty"`
 }
 
 func (m *RequestMetadata) Reset()                    { *m = RequestMetadata{} }
@@ -76,6 +78,13 @@ func (m *RequestMetadata) GetOrgPubKey() []byte {
 	return nil
 }
 
+func (m *RequestMetadata) GetCert() *PeerCertificate {
+	if m != nil {
+		return m.Cert
+	}
+	return nil
+}
+
 type ResponseMetadata struct {
 	// 32-byte request ID that generated this response
 	RequestId []byte `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
@@ -111,6 +120,69 @@ func (m *ResponseMetadata) GetOrgPubKey() []byte {
 	return nil
 }
 
+// PeerCertificate binds a peer or author ECDSA public key to the organization that issued it.
+type PeerCertificate struct {
+	// peer or author ECDSA public key being certified
+	PubKey []byte `protobuf:"bytes,1,opt,name=pub_key,json=pubKey,proto3" json:"pub_key,omitempty"`
+	// organization ECDSA public key of the issuer
+	OrgPubKey []byte `protobuf:"bytes,2,opt,name=org_pub_key,json=orgPubKey,proto3" json:"org_pub_key,omitempty"`
+	// 32-byte random serial number, used to revoke the certificate
+	Serial []byte `protobuf:"bytes,3,opt,name=serial,proto3" json:"serial,omitempty"`
+	// epoch seconds when the certificate was issued
+	IssuedAt int64 `protobuf:"varint,4,opt,name=issued_at,json=issuedAt" json:"issued_at,omitempty"`
+	// epoch seconds after which the certificate is no longer valid
+	ExpiresAt int64 `protobuf:"varint,5,opt,name=expires_at,json=expiresAt" json:"expires_at,omitempty"`
+	// organization ECDSA signature (ASN.1 DER) of the certificate without its signature
+	Signature []byte `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *PeerCertificate) Reset()                    { *m = PeerCertificate{} }
+func (m *PeerCertificate) String() string            { return proto.CompactTextString(m) }
+func (*PeerCertificate) ProtoMessage()               {}
+func (*PeerCertificate) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+
+func (m *PeerCertificate) GetPubKey() []byte {
+	if m != nil {
+		return m.PubKey
+	}
+	return nil
+}
+
+func (m *PeerCertificate) GetOrgPubKey() []byte {
+	if m != nil {
+		return m.OrgPubKey
+	}
+	return nil
+}
+
+func (m *PeerCertificate) GetSerial() []byte {
+	if m != nil {
+		return m.Serial
+	}
+	return nil
+}
+
+func (m *PeerCertificate) GetIssuedAt() int64 {
+	if m != nil {
+		return m.IssuedAt
+	}
+	return 0
+}
+
+func (m *PeerCertificate) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *PeerCertificate) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
 type IntroduceRequest struct {
 	Metadata *RequestMetadata `protobuf:"bytes,1,opt,name=metadata" json:"metadata,omitempty"`
 	// info about the peer making the introduction
@@ -122,7 +194,7 @@ type IntroduceRequest struct {
 func (m *IntroduceRequest) Reset()                    { *m = IntroduceRequest{} }
 func (m *IntroduceRequest) String() string            { return proto.CompactTextString(m) }
 func (*IntroduceRequest) ProtoMessage()               {}
-func (*IntroduceRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+func (*IntroduceRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
 
 func (m *IntroduceRequest) GetMetadata() *RequestMetadata {
 	if m != nil {
@@ -156,7 +228,7 @@ type IntroduceResponse struct {
 func (m *IntroduceResponse) Reset()                    { *m = IntroduceResponse{} }
 func (m *IntroduceResponse) String() string            { return proto.CompactTextString(m) }
 func (*IntroduceResponse) ProtoMessage()               {}
-func (*IntroduceResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+func (*IntroduceResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
 
 func (m *IntroduceResponse) GetMetadata() *ResponseMetadata {
 	if m != nil {
@@ -190,7 +262,7 @@ type FindRequest struct {
 func (m *FindRequest) Reset()                    { *m = FindRequest{} }
 func (m *FindRequest) String() string            { return proto.CompactTextString(m) }
 func (*FindRequest) ProtoMessage()               {}
-func (*FindRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
+func (*FindRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
 
 func (m *FindRequest) GetMetadata() *RequestMetadata {
 	if m != nil {
@@ -224,7 +296,7 @@ type FindResponse struct {
 func (m *FindResponse) Reset()                    { *m = FindResponse{} }
 func (m *FindResponse) String() string            { return proto.CompactTextString(m) }
 func (*FindResponse) ProtoMessage()               {}
-func (*FindResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
+func (*FindResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
 
 func (m *FindResponse) GetMetadata() *ResponseMetadata {
 	if m != nil {
@@ -260,7 +332,7 @@ type VerifyRequest struct {
 func (m *VerifyRequest) Reset()                    { *m = VerifyRequest{} }
 func (m *VerifyRequest) String() string            { return proto.CompactTextString(m) }
 func (*VerifyRequest) ProtoMessage()               {}
-func (*VerifyRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
+func (*VerifyRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{7} }
 
 func (m *VerifyRequest) GetMetadata() *RequestMetadata {
 	if m != nil {
@@ -302,7 +374,7 @@ type VerifyResponse struct {
 func (m *VerifyResponse) Reset()                    { *m = VerifyResponse{} }
 func (m *VerifyResponse) String() string            { return proto.CompactTextString(m) }
 func (*VerifyResponse) ProtoMessage()               {}
-func (*VerifyResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{7} }
+func (*VerifyResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{8} }
 
 func (m *VerifyResponse) GetMetadata() *ResponseMetadata {
 	if m != nil {
@@ -334,12 +406,22 @@ type PeerAddress struct {
 	Ip string `protobuf:"bytes,3,opt,name=ip" json:"ip,omitempty"`
 	// public address TCP port
 	Port uint32 `protobuf:"varint,4,opt,name=port" json:"port,omitempty"`
+	// IP address of the relay the peer is reachable through when it is not directly reachable,
+	// or empty when it is
+	RelayIp string `protobuf:"bytes,5,opt,name=relay_ip,json=relayIp" json:"relay_ip,omitempty"`
+	// relay TCP port
+	RelayPort uint32 `protobuf:"varint,6,opt,name=relay_port,json=relayPort" json:"relay_port,omitempty"`
+	// additional addresses (host:port) the peer may be reached at, where the host may be an IPv4
+	// or IPv6 address or a DNS name resolved when dialing
+	Addresses []string `protobuf:"bytes,7,rep,name=addresses" json:"addresses,omitempty"`
+	// (optional) organization-issued certificate for the peer ECDSA public key
+	Cert *PeerCertificate `protobuf:"bytes,8,opt,name=cert" json:"cert,omitempty"`
 }
 
 func (m *PeerAddress) Reset()                    { *m = PeerAddress{} }
 func (m *PeerAddress) String() string            { return proto.CompactTextString(m) }
 func (*PeerAddress) ProtoMessage()               {}
-func (*PeerAddress) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{8} }
+func (*PeerAddress) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{9} }
 
 func (m *PeerAddress) GetPeerId() []byte {
 	if m != nil {
@@ -369,6 +451,34 @@ func (m *PeerAddress) GetPort() uint32 {
 	return 0
 }
 
+func (m *PeerAddress) GetRelayIp() string {
+	if m != nil {
+		return m.RelayIp
+	}
+	return ""
+}
+
+func (m *PeerAddress) GetRelayPort() uint32 {
+	if m != nil {
+		return m.RelayPort
+	}
+	return 0
+}
+
+func (m *PeerAddress) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func (m *PeerAddress) GetCert() *PeerCertificate {
+	if m != nil {
+		return m.Cert
+	}
+	return nil
+}
+
 type StoreRequest struct {
 	Metadata *RequestMetadata `protobuf:"bytes,1,opt,name=metadata" json:"metadata,omitempty"`
 	// key to store value under
@@ -380,7 +490,7 @@ type StoreRequest struct {
 func (m *StoreRequest) Reset()                    { *m = StoreRequest{} }
 func (m *StoreRequest) String() string            { return proto.CompactTextString(m) }
 func (*StoreRequest) ProtoMessage()               {}
-func (*StoreRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{9} }
+func (*StoreRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{10} }
 
 func (m *StoreRequest) GetMetadata() *RequestMetadata {
 	if m != nil {
@@ -410,7 +520,7 @@ type StoreResponse struct {
 func (m *StoreResponse) Reset()                    { *m = StoreResponse{} }
 func (m *StoreResponse) String() string            { return proto.CompactTextString(m) }
 func (*StoreResponse) ProtoMessage()               {}
-func (*StoreResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{10} }
+func (*StoreResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{11} }
 
 func (m *StoreResponse) GetMetadata() *ResponseMetadata {
 	if m != nil {
@@ -428,7 +538,7 @@ type GetRequest struct {
 func (m *GetRequest) Reset()                    { *m = GetRequest{} }
 func (m *GetRequest) String() string            { return proto.CompactTextString(m) }
 func (*GetRequest) ProtoMessage()               {}
-func (*GetRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{11} }
+func (*GetRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{12} }
 
 func (m *GetRequest) GetMetadata() *RequestMetadata {
 	if m != nil {
@@ -453,7 +563,7 @@ type GetResponse struct {
 func (m *GetResponse) Reset()                    { *m = GetResponse{} }
 func (m *GetResponse) String() string            { return proto.CompactTextString(m) }
 func (*GetResponse) ProtoMessage()               {}
-func (*GetResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{12} }
+func (*GetResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{13} }
 
 func (m *GetResponse) GetMetadata() *ResponseMetadata {
 	if m != nil {
@@ -480,7 +590,7 @@ type PutRequest struct {
 func (m *PutRequest) Reset()                    { *m = PutRequest{} }
 func (m *PutRequest) String() string            { return proto.CompactTextString(m) }
 func (*PutRequest) ProtoMessage()               {}
-func (*PutRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{13} }
+func (*PutRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{14} }
 
 func (m *PutRequest) GetMetadata() *RequestMetadata {
 	if m != nil {
@@ -514,7 +624,7 @@ type PutResponse struct {
 func (m *PutResponse) Reset()                    { *m = PutResponse{} }
 func (m *PutResponse) String() string            { return proto.CompactTextString(m) }
 func (*PutResponse) ProtoMessage()               {}
-func (*PutResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{14} }
+func (*PutResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{15} }
 
 func (m *PutResponse) GetMetadata() *ResponseMetadata {
 	if m != nil {
@@ -545,7 +655,7 @@ type SubscribeRequest struct {
 func (m *SubscribeRequest) Reset()                    { *m = SubscribeRequest{} }
 func (m *SubscribeRequest) String() string            { return proto.CompactTextString(m) }
 func (*SubscribeRequest) ProtoMessage()               {}
-func (*SubscribeRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{15} }
+func (*SubscribeRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{16} }
 
 func (m *SubscribeRequest) GetMetadata() *RequestMetadata {
 	if m != nil {
@@ -570,7 +680,7 @@ type SubscribeResponse struct {
 func (m *SubscribeResponse) Reset()                    { *m = SubscribeResponse{} }
 func (m *SubscribeResponse) String() string            { return proto.CompactTextString(m) }
 func (*SubscribeResponse) ProtoMessage()               {}
-func (*SubscribeResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{16} }
+func (*SubscribeResponse) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{17} }
 
 func (m *SubscribeResponse) GetMetadata() *ResponseMetadata {
 	if m != nil {
@@ -603,7 +713,7 @@ type Publication struct {
 func (m *Publication) Reset()                    { *m = Publication{} }
 func (m *Publication) String() string            { return proto.CompactTextString(m) }
 func (*Publication) ProtoMessage()               {}
-func (*Publication) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{17} }
+func (*Publication) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{18} }
 
 func (m *Publication) GetEnvelopeKey() []byte {
 	if m != nil {
@@ -641,7 +751,7 @@ type Subscription struct {
 func (m *Subscription) Reset()                    { *m = Subscription{} }
 func (m *Subscription) String() string            { return proto.CompactTextString(m) }
 func (*Subscription) ProtoMessage()               {}
-func (*Subscription) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{18} }
+func (*Subscription) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{19} }
 
 func (m *Subscription) GetAuthorPublicKeys() *BloomFilter {
 	if m != nil {
@@ -665,7 +775,7 @@ type BloomFilter struct {
 func (m *BloomFilter) Reset()                    { *m = BloomFilter{} }
 func (m *BloomFilter) String() string            { return proto.CompactTextString(m) }
 func (*BloomFilter) ProtoMessage()               {}
-func (*BloomFilter) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{19} }
+func (*BloomFilter) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{20} }
 
 func (m *BloomFilter) GetEncoded() []byte {
 	if m != nil {
@@ -677,6 +787,7 @@ func (m *BloomFilter) GetEncoded() []byte {
 func init() {
 	proto.RegisterType((*RequestMetadata)(nil), "api.RequestMetadata")
 	proto.RegisterType((*ResponseMetadata)(nil), "api.ResponseMetadata")
+	proto.RegisterType((*PeerCertificate)(nil), "api.PeerCertificate")
 	proto.RegisterType((*IntroduceRequest)(nil), "api.IntroduceRequest")
 	proto.RegisterType((*IntroduceResponse)(nil), "api.IntroduceResponse")
 	proto.RegisterType((*FindRequest)(nil), "api.FindRequest")
@@ -1015,60 +1126,69 @@ var _Librarian_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("librarian/api/librarian.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 870 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0xcf, 0x6f, 0xe3, 0x44,
-	0x14, 0xae, 0x93, 0x34, 0xad, 0x9f, 0x93, 0xd6, 0x19, 0x7e, 0x6c, 0x15, 0xb4, 0x08, 0x0c, 0x5a,
-	0x50, 0xa5, 0x6d, 0x4b, 0x56, 0xdc, 0xd0, 0x4a, 0xac, 0xb6, 0xad, 0xa2, 0x5d, 0x76, 0x23, 0xa7,
-	0x42, 0xdc, 0xa2, 0x89, 0xfd, 0xb6, 0x0c, 0xd8, 0x1e, 0x33, 0x1e, 0x2f, 0x8a, 0x10, 0x12, 0x37,
-	0xc4, 0x05, 0x71, 0xe0, 0x5f, 0xe0, 0x6f, 0xe4, 0x8a, 0x3c, 0x33, 0x76, 0x6c, 0x07, 0x55, 0x4b,
-	0xb6, 0xec, 0x2d, 0x7e, 0xef, 0x9b, 0xbc, 0xef, 0x7d, 0xf3, 0x7e, 0x0c, 0xdc, 0x8d, 0xd8, 0x52,
-	0x50, 0xc1, 0x68, 0x72, 0x4a, 0x53, 0x76, 0x5a, 0x7d, 0x9d, 0xa4, 0x82, 0x4b, 0x4e, 0xba, 0x34,
-	0x65, 0xe3, 0x16, 0x26, 0xe4, 0x41, 0x1e, 0x63, 0x22, 0x33, 0x8d, 0xf1, 0x18, 0x1c, 0xfa, 0xf8,
-	0x43, 0x8e, 0x99, 0xfc, 0x0a, 0x25, 0x0d, 0xa9, 0xa4, 0xe4, 0x2e, 0x80, 0xd0, 0xa6, 0x05, 0x0b,
-	0x8f, 0xac, 0x0f, 0xac, 0x4f, 0x07, 0xbe, 0x6d, 0x2c, 0xd3, 0x90, 0xdc, 0x81, 0xbd, 0x34, 0x5f,
-	0x2e, 0xbe, 0xc7, 0xd5, 0x51, 0x47, 0xf9, 0xfa, 0x69, 0xbe, 0x7c, 0x82, 0x2b, 0xf2, 0x3e, 0x38,
-	0x5c, 0x5c, 0x2f, 0x4a, 0x67, 0x57, 0x1f, 0xe4, 0xe2, 0x7a, 0xa6, 0xfc, 0xde, 0x77, 0xe0, 0xfa,
-	0x98, 0xa5, 0x3c, 0xc9, 0xf0, 0x7f, 0x8f, 0xf5, 0xab, 0x05, 0xee, 0x34, 0x91, 0x82, 0x87, 0x79,
-	0x80, 0x26, 0x41, 0x72, 0x06, 0xfb, 0xb1, 0x09, 0xac, 0x42, 0x39, 0x93, 0xb7, 0x4f, 0x68, 0xca,
-	0x4e, 0x5a, 0x02, 0xf8, 0x15, 0x8a, 0x7c, 0x0c, 0xbd, 0x0c, 0xa3, 0x17, 0x2a, 0xb8, 0x33, 0x71,
-	0x15, 0x7a, 0x86, 0x28, 0xbe, 0x0c, 0x43, 0x81, 0x59, 0xe6, 0x2b, 0x2f, 0x79, 0x0f, 0xec, 0x24,
-	0x8f, 0x17, 0x29, 0xa2, 0xc8, 0x14, 0x95, 0xa1, 0xbf, 0x9f, 0xe4, 0x71, 0x01, 0xcc, 0xbc, 0x3f,
-	0x2d, 0x18, 0xd5, 0x98, 0xe8, 0xfc, 0xc9, 0x67, 0x1b, 0x54, 0xde, 0x31, 0x54, 0x9a, 0x02, 0xfd,
-	0x67, 0x2e, 0xf7, 0x60, 0xb7, 0xe4, 0xd1, 0xfd, 0x57, 0x98, 0x76, 0x7b, 0x09, 0x38, 0x17, 0x2c,
-	0x09, 0xb7, 0x97, 0xc6, 0x85, 0xee, 0xfa, 0x5a, 0x8a, 0x9f, 0x37, 0xcb, 0xf0, 0xbb, 0x05, 0x03,
-	0x1d, 0x70, 0x7b, 0x05, 0xaa, 0xdc, 0x3a, 0x37, 0xe6, 0x46, 0x3e, 0x82, 0xdd, 0x97, 0x34, 0xca,
-	0x51, 0x91, 0x70, 0x26, 0x43, 0x85, 0x7b, 0x6c, 0x0a, 0xdf, 0xd7, 0x3e, 0xef, 0x37, 0x0b, 0x86,
-	0x5f, 0xa3, 0x60, 0x2f, 0x56, 0xb7, 0xa9, 0xc1, 0x1d, 0xd8, 0x8b, 0x69, 0x50, 0xab, 0xc9, 0x7e,
-	0x4c, 0x83, 0x27, 0x6d, 0x71, 0x7a, 0x2d, 0x71, 0x7e, 0x86, 0x83, 0x92, 0xca, 0xf6, 0xea, 0xb8,
-	0xd0, 0x8d, 0x69, 0x50, 0x92, 0x89, 0x69, 0xf0, 0xca, 0xb5, 0x70, 0x0d, 0x4e, 0xcd, 0xaa, 0x9a,
-	0x0e, 0x51, 0xac, 0x1b, 0xb2, 0x5f, 0x7c, 0x4e, 0xc3, 0x22, 0x07, 0xe5, 0x48, 0x68, 0x8c, 0x2a,
-	0x8e, 0xed, 0xef, 0x17, 0x86, 0x67, 0x34, 0x46, 0x72, 0x00, 0x1d, 0x96, 0xaa, 0xa4, 0x6d, 0xbf,
-	0xc3, 0x52, 0x42, 0xa0, 0x97, 0x72, 0x21, 0x4d, 0xae, 0xea, 0xb7, 0xf7, 0x23, 0x0c, 0xe6, 0x92,
-	0x0b, 0xbc, 0x4d, 0xc5, 0x5f, 0xe9, 0xb2, 0x1f, 0xc1, 0xd0, 0x04, 0xde, 0x5a, 0x5f, 0x6f, 0x06,
-	0x70, 0x89, 0xf2, 0x16, 0xa9, 0x7b, 0x08, 0x8e, 0xfa, 0xc7, 0xed, 0xef, 0xbc, 0x4a, 0xbe, 0x73,
-	0x43, 0xf2, 0x39, 0xc0, 0x2c, 0x97, 0x6f, 0x5c, 0xf3, 0x3f, 0x2c, 0x70, 0x54, 0xdc, 0xed, 0xd3,
-	0x3b, 0x05, 0x9b, 0xa7, 0x28, 0xa8, 0x64, 0x3c, 0x51, 0xf1, 0x0f, 0x26, 0x23, 0x5d, 0xc4, 0xb9,
-	0x7c, 0x5e, 0x3a, 0xfc, 0x35, 0xa6, 0x58, 0x27, 0xc9, 0x42, 0x60, 0x1a, 0xb1, 0x80, 0x96, 0x33,
-	0xc8, 0x4e, 0x7c, 0x63, 0xf0, 0x7e, 0x02, 0x77, 0x9e, 0x2f, 0xb3, 0x40, 0xb0, 0xe5, 0x6b, 0xd4,
-	0xe0, 0xe7, 0x30, 0xc8, 0xf4, 0xbf, 0xa4, 0x15, 0x31, 0xc7, 0x10, 0x9b, 0xd7, 0x1c, 0x7e, 0x03,
-	0xe6, 0xfd, 0x62, 0xc1, 0xa8, 0x16, 0xfd, 0xb5, 0x1a, 0xbd, 0x75, 0x1f, 0xf7, 0x9a, 0xf7, 0x61,
-	0x1a, 0x3d, 0x5f, 0x16, 0x59, 0x2b, 0x26, 0xe6, 0x4a, 0xfe, 0x52, 0x57, 0x52, 0x99, 0xc9, 0x87,
-	0x30, 0xc0, 0xe4, 0x25, 0x46, 0x3c, 0x45, 0x35, 0xb2, 0x74, 0xbb, 0x3b, 0xa5, 0xcd, 0xcc, 0x2d,
-	0x4c, 0xa4, 0x58, 0xd5, 0x76, 0xf0, 0xbe, 0x32, 0x14, 0xce, 0x63, 0x18, 0xd1, 0x5c, 0x7e, 0xcb,
-	0x45, 0xb1, 0x88, 0x23, 0x56, 0x9f, 0x7b, 0x87, 0xda, 0xa1, 0xa3, 0x19, 0xac, 0x40, 0x1a, 0x62,
-	0x03, 0xdb, 0xd3, 0x58, 0xed, 0xa8, 0xb0, 0x6a, 0x59, 0xd4, 0x95, 0x24, 0x0f, 0x81, 0x6c, 0x04,
-	0xca, 0x8c, 0x5e, 0x3a, 0xdb, 0x47, 0x11, 0xe7, 0xf1, 0x05, 0x8b, 0x24, 0x0a, 0xdf, 0x6d, 0xc5,
-	0xce, 0x8a, 0xf3, 0x1b, 0xc1, 0xb3, 0xc6, 0x26, 0x6d, 0x9c, 0x6f, 0xf1, 0xc9, 0xbc, 0x4f, 0xc0,
-	0xa9, 0x01, 0xc8, 0x11, 0xec, 0x61, 0x12, 0xf0, 0x10, 0xcb, 0x09, 0x59, 0x7e, 0x1e, 0xdf, 0x87,
-	0x41, 0xbd, 0x36, 0x09, 0x40, 0x7f, 0x7e, 0xf5, 0xdc, 0x3f, 0x7f, 0xec, 0xee, 0x90, 0x11, 0x0c,
-	0x9f, 0x9e, 0x5f, 0x5c, 0x2d, 0xce, 0xbf, 0x99, 0xce, 0xaf, 0xa6, 0xcf, 0x2e, 0x5d, 0x6b, 0xf2,
-	0x77, 0x07, 0xec, 0xa7, 0xe5, 0xfb, 0x8c, 0x7c, 0x01, 0x76, 0xf5, 0x52, 0x20, 0xba, 0x0c, 0xda,
-	0x6f, 0x98, 0xf1, 0xbb, 0x6d, 0xb3, 0x2e, 0x13, 0x6f, 0x87, 0xdc, 0x87, 0x5e, 0xb1, 0x60, 0x89,
-	0xce, 0xa7, 0xb6, 0xdc, 0xc7, 0xa3, 0x9a, 0xa5, 0x82, 0x3f, 0x80, 0xbe, 0xde, 0x39, 0x84, 0x28,
-	0x77, 0x63, 0x17, 0x8e, 0xdf, 0x6a, 0xd8, 0xaa, 0x43, 0x67, 0xb0, 0xab, 0xe6, 0x28, 0x31, 0xd5,
-	0x5e, 0x1b, 0xe6, 0x63, 0x52, 0x37, 0x55, 0x27, 0x8e, 0xa1, 0x7b, 0x89, 0x92, 0x1c, 0x2a, 0xe7,
-	0x7a, 0x7e, 0x8e, 0xdd, 0xb5, 0xa1, 0x8e, 0x9d, 0xe5, 0x25, 0x76, 0x3d, 0xb2, 0xc6, 0xee, 0xda,
-	0x50, 0x61, 0x1f, 0x82, 0x5d, 0x35, 0x93, 0xd1, 0xaa, 0xdd, 0xda, 0x46, 0xab, 0x8d, 0x9e, 0xf3,
-	0x76, 0xce, 0xac, 0x65, 0x5f, 0x3d, 0x7f, 0x1f, 0xfc, 0x13, 0x00, 0x00, 0xff, 0xff, 0x7e, 0x17,
-	0xea, 0x04, 0x43, 0x0b, 0x00, 0x00,
+	// 1015 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x57, 0xdf, 0x6e, 0xe3, 0xc4,
+	0x17, 0xae, 0x93, 0x34, 0x8d, 0x4f, 0x92, 0xd6, 0x99, 0xdf, 0x8f, 0x6e, 0x08, 0xbb, 0xa8, 0x18,
+	0xb4, 0x54, 0x95, 0xb6, 0x2d, 0x5d, 0x71, 0x87, 0x56, 0xea, 0xb2, 0x6d, 0x15, 0xed, 0xb2, 0x1b,
+	0xb9, 0x15, 0xe2, 0x2e, 0x9a, 0xd8, 0xa7, 0x65, 0xc0, 0xff, 0x98, 0x19, 0x2f, 0x54, 0x08, 0x89,
+	0x3b, 0xc4, 0x0d, 0x42, 0x82, 0x57, 0xe0, 0x2d, 0x78, 0x21, 0x9e, 0x80, 0x5b, 0xe4, 0x19, 0xdb,
+	0x99, 0xb8, 0x50, 0x96, 0xb4, 0x70, 0x17, 0x7f, 0xdf, 0x77, 0x7c, 0xfe, 0xce, 0xf1, 0x04, 0xee,
+	0x85, 0x6c, 0xc6, 0x29, 0x67, 0x34, 0xde, 0xa3, 0x29, 0xdb, 0xab, 0x9e, 0x76, 0x53, 0x9e, 0xc8,
+	0x84, 0x34, 0x69, 0xca, 0x46, 0x35, 0x4d, 0x90, 0xf8, 0x59, 0x84, 0xb1, 0x14, 0x5a, 0xe3, 0xfe,
+	0x64, 0xc1, 0x86, 0x87, 0x5f, 0x64, 0x28, 0xe4, 0x47, 0x28, 0x69, 0x40, 0x25, 0x25, 0xf7, 0x00,
+	0xb8, 0x86, 0xa6, 0x2c, 0x18, 0x5a, 0x5b, 0xd6, 0x76, 0xcf, 0xb3, 0x0b, 0x64, 0x1c, 0x90, 0x3b,
+	0xb0, 0x96, 0x66, 0xb3, 0xe9, 0xe7, 0x78, 0x39, 0x6c, 0x28, 0xae, 0x9d, 0x66, 0xb3, 0xa7, 0x78,
+	0x49, 0xde, 0x84, 0x6e, 0xc2, 0x2f, 0xa6, 0x25, 0xd9, 0xd4, 0x86, 0x09, 0xbf, 0x98, 0x68, 0x7e,
+	0x1b, 0x5a, 0x3e, 0x72, 0x39, 0x6c, 0x6d, 0x59, 0xdb, 0xdd, 0x83, 0xff, 0xef, 0xd2, 0x94, 0xed,
+	0x4e, 0x10, 0xf9, 0x87, 0xc8, 0x25, 0x3b, 0x67, 0x3e, 0x95, 0xe8, 0x29, 0x85, 0xfb, 0x19, 0x38,
+	0x1e, 0x8a, 0x34, 0x89, 0x05, 0xfe, 0xdb, 0x51, 0xb9, 0xbf, 0x5a, 0xb0, 0x51, 0x8b, 0xc2, 0x7c,
+	0x99, 0x75, 0xdd, 0xcb, 0x1a, 0xf5, 0x14, 0x37, 0xa1, 0x2d, 0x90, 0x33, 0x1a, 0x16, 0x7e, 0x8a,
+	0x27, 0xf2, 0x06, 0xd8, 0x4c, 0x88, 0x0c, 0x83, 0x29, 0xd5, 0xf9, 0x37, 0xbd, 0x8e, 0x06, 0x0e,
+	0x65, 0x9e, 0x19, 0x7e, 0x95, 0x32, 0x8e, 0x22, 0x67, 0x57, 0x15, 0x6b, 0x17, 0xc8, 0xa1, 0x24,
+	0x77, 0xc1, 0x16, 0xec, 0x22, 0xa6, 0x32, 0xe3, 0x38, 0x6c, 0x6b, 0x8f, 0x15, 0xe0, 0x7e, 0x67,
+	0x81, 0x33, 0x8e, 0x25, 0x4f, 0x82, 0xcc, 0xc7, 0xa2, 0x93, 0x64, 0x1f, 0x3a, 0x51, 0x51, 0xb7,
+	0xa1, 0x65, 0x54, 0xbb, 0xd6, 0x69, 0xaf, 0x52, 0x91, 0x77, 0xa0, 0x25, 0x30, 0x3c, 0x57, 0x19,
+	0x75, 0x0f, 0x9c, 0xaa, 0x37, 0x87, 0x41, 0xc0, 0x51, 0x08, 0x4f, 0xb1, 0x79, 0x1a, 0x71, 0x16,
+	0x4d, 0x53, 0x44, 0x2e, 0x54, 0x86, 0x7d, 0xaf, 0x13, 0x67, 0x51, 0x2e, 0x14, 0xee, 0xcf, 0x16,
+	0x0c, 0x8c, 0x48, 0x74, 0xfb, 0xc8, 0x7b, 0x57, 0x42, 0x79, 0xad, 0x08, 0x65, 0xb1, 0xbf, 0xff,
+	0x38, 0x96, 0xfb, 0xb0, 0x5a, 0xc6, 0xd1, 0xfc, 0x53, 0x99, 0xa6, 0xdd, 0x18, 0xba, 0xc7, 0x2c,
+	0x0e, 0x96, 0x2f, 0x8d, 0x03, 0xcd, 0x79, 0xaf, 0xf3, 0x9f, 0xd7, 0x97, 0xe1, 0x07, 0x0b, 0x7a,
+	0xda, 0xe1, 0xf2, 0x15, 0xa8, 0x72, 0x6b, 0x5c, 0x9b, 0x1b, 0x79, 0x1b, 0x56, 0x5f, 0xd2, 0x30,
+	0x43, 0x15, 0x44, 0xf7, 0xa0, 0xaf, 0x74, 0x4f, 0x8a, 0x23, 0xee, 0x69, 0xce, 0xfd, 0xde, 0x82,
+	0xfe, 0xc7, 0xc8, 0xd9, 0xf9, 0xe5, 0x6d, 0xd6, 0xe0, 0x0e, 0xac, 0x45, 0xd4, 0x37, 0x8e, 0x54,
+	0x3b, 0xa2, 0xfe, 0xd3, 0x7a, 0x71, 0x5a, 0xb5, 0xe2, 0x7c, 0x03, 0xeb, 0x65, 0x28, 0xcb, 0x57,
+	0xc7, 0x81, 0x66, 0x44, 0xfd, 0x32, 0x98, 0x88, 0xfa, 0xaf, 0x3c, 0x0b, 0xbf, 0x59, 0xd0, 0x35,
+	0x60, 0x75, 0xce, 0x11, 0xf9, 0x7c, 0xa1, 0xb4, 0xf3, 0xc7, 0x71, 0x90, 0x27, 0xa1, 0x88, 0x98,
+	0x46, 0xa8, 0x1c, 0xd9, 0x5e, 0x27, 0x07, 0x9e, 0xd3, 0x08, 0xc9, 0x3a, 0x34, 0x58, 0xaa, 0xb2,
+	0xb6, 0xbd, 0x06, 0x4b, 0x09, 0x81, 0x56, 0x9a, 0x14, 0x7b, 0xad, 0xef, 0xa9, 0xdf, 0xe4, 0x75,
+	0xe8, 0x70, 0x0c, 0xe9, 0xe5, 0x94, 0xa5, 0xea, 0x44, 0xdb, 0xde, 0x9a, 0x7a, 0x1e, 0xa7, 0x7a,
+	0x91, 0xe5, 0x94, 0x32, 0x6a, 0x2b, 0x23, 0x5b, 0x21, 0x93, 0xdc, 0xf2, 0x2e, 0xd8, 0x54, 0x87,
+	0x87, 0x62, 0xb8, 0xb6, 0xd5, 0xdc, 0xb6, 0xbd, 0x39, 0x50, 0xed, 0xd0, 0xce, 0xdf, 0xee, 0xd0,
+	0x2f, 0xa1, 0x77, 0x2a, 0x13, 0x8e, 0xb7, 0xd9, 0xf4, 0x57, 0x9a, 0xb7, 0xc7, 0xd0, 0x2f, 0x1c,
+	0x2f, 0xdd, 0x62, 0x77, 0x02, 0x70, 0x82, 0xf2, 0x16, 0x43, 0x77, 0x11, 0xba, 0xea, 0x8d, 0xcb,
+	0x8f, 0x5d, 0x95, 0x7c, 0xe3, 0x9a, 0xe4, 0x33, 0x80, 0x49, 0x26, 0xff, 0xf3, 0x9a, 0xff, 0x98,
+	0x0f, 0x76, 0x76, 0xa3, 0xf4, 0xf6, 0xc0, 0x4e, 0x52, 0xe4, 0x54, 0xb2, 0x24, 0x56, 0xfe, 0xd7,
+	0x0f, 0x06, 0x7a, 0xbc, 0x32, 0xf9, 0xa2, 0x24, 0xbc, 0xb9, 0x26, 0x9f, 0xe3, 0x78, 0xca, 0x31,
+	0x0d, 0x99, 0x4f, 0xcb, 0x35, 0x68, 0xc7, 0x5e, 0x01, 0xb8, 0x5f, 0x83, 0x73, 0x9a, 0xcd, 0x84,
+	0xcf, 0xd9, 0xec, 0x06, 0x33, 0xf8, 0x3e, 0xf4, 0x84, 0x7e, 0x4b, 0x5a, 0x05, 0xd6, 0x2d, 0x02,
+	0x3b, 0x35, 0x08, 0x6f, 0x41, 0xe6, 0x7e, 0x6b, 0xc1, 0xc0, 0xf0, 0x7e, 0xa3, 0x5d, 0x53, 0xeb,
+	0xc7, 0xfd, 0xc5, 0x7e, 0x14, 0xbb, 0x26, 0x9b, 0xe5, 0x59, 0xab, 0x48, 0x8a, 0x96, 0xfc, 0xa2,
+	0x5a, 0x52, 0xc1, 0xe4, 0x2d, 0xe8, 0x61, 0xfc, 0x12, 0xc3, 0x24, 0x45, 0xe3, 0x62, 0xd1, 0x2d,
+	0xb1, 0x62, 0x75, 0x62, 0x2c, 0xf9, 0xa5, 0x71, 0xb7, 0xe8, 0x28, 0x20, 0x27, 0x77, 0x60, 0x40,
+	0x33, 0xf9, 0x69, 0xc2, 0xf3, 0xdb, 0x47, 0xc8, 0xcc, 0xd5, 0xbb, 0xa1, 0x09, 0xed, 0xad, 0xd0,
+	0x72, 0xa4, 0x01, 0x2e, 0x68, 0x5b, 0x5a, 0xab, 0x89, 0x4a, 0xab, 0xbe, 0x57, 0x66, 0x25, 0xc9,
+	0x23, 0x20, 0x57, 0x1c, 0x89, 0xa1, 0x65, 0x64, 0xfb, 0x38, 0x4c, 0x92, 0xe8, 0x98, 0x85, 0x12,
+	0xb9, 0xe7, 0xd4, 0x7c, 0x8b, 0xdc, 0xfe, 0x8a, 0x73, 0x31, 0x6c, 0xfc, 0x95, 0x7d, 0x2d, 0x1e,
+	0xe1, 0xbe, 0x0b, 0x5d, 0x43, 0x40, 0x86, 0xb0, 0x86, 0xb1, 0x9f, 0x04, 0x58, 0xee, 0xe8, 0xf2,
+	0x71, 0xe7, 0x01, 0xf4, 0xcc, 0xd9, 0x24, 0x00, 0xed, 0xd3, 0xb3, 0x17, 0xde, 0xd1, 0x13, 0x67,
+	0x85, 0x0c, 0xa0, 0xff, 0xec, 0xe8, 0xf8, 0x6c, 0x7a, 0xf4, 0xc9, 0xf8, 0xf4, 0x6c, 0xfc, 0xfc,
+	0xc4, 0xb1, 0x0e, 0x7e, 0x6f, 0x80, 0xfd, 0xac, 0xbc, 0x0c, 0x93, 0x0f, 0xc0, 0xae, 0x2e, 0x2b,
+	0x44, 0x8f, 0x41, 0xfd, 0x1a, 0x35, 0xda, 0xac, 0xc3, 0x7a, 0x4c, 0xdc, 0x15, 0xf2, 0x00, 0x5a,
+	0xf9, 0x37, 0x9e, 0xe8, 0x7c, 0x8c, 0xfb, 0xc5, 0x68, 0x60, 0x20, 0x95, 0xfc, 0x21, 0xb4, 0xf5,
+	0x67, 0x8f, 0x10, 0x45, 0x2f, 0x7c, 0x8e, 0x47, 0xff, 0x5b, 0xc0, 0x2a, 0xa3, 0x7d, 0x58, 0x55,
+	0x7b, 0x94, 0x14, 0xd3, 0x6e, 0x2c, 0xf3, 0x11, 0x31, 0xa1, 0xca, 0x62, 0x07, 0x9a, 0x27, 0x28,
+	0xc9, 0x86, 0x22, 0xe7, 0xfb, 0x73, 0xe4, 0xcc, 0x01, 0x53, 0x3b, 0xc9, 0x4a, 0xed, 0x24, 0xab,
+	0x69, 0x8d, 0x5d, 0xe2, 0xae, 0x90, 0x47, 0x60, 0x57, 0x87, 0xa9, 0xa8, 0x55, 0xfd, 0x68, 0x8f,
+	0x36, 0xeb, 0x70, 0x69, 0xbd, 0x6f, 0xcd, 0xda, 0xea, 0xbf, 0xc6, 0xc3, 0x3f, 0x06, 0x00, 0xa3,
+	0xd6, 0x72, 0x14, 0xb0, 0x0c, 0x00, 0x00,
 }
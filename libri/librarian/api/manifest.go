@@ -0,0 +1,74 @@
+package api
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrMissingManifest indicates when a manifest is unexpectedly missing.
+	ErrMissingManifest = errors.New("missing manifest")
+
+	// ErrEmptyManifestFilepath indicates when a manifest file has an empty filepath.
+	ErrEmptyManifestFilepath = errors.New("empty manifest filepath")
+
+	// ErrUnexpectedEmptyFileKeys indicates when an empty manifest file unexpectedly has
+	// envelope or entry keys.
+	ErrUnexpectedEmptyFileKeys = errors.New("unexpected keys for empty manifest file")
+)
+
+// ValidateManifest checks that all manifest files have unique, sorted, relative filepaths within
+// the manifest directory and keys and hashes of the expected lengths. Empty files have no entry
+// and so no envelope or entry keys.
+func ValidateManifest(m *Manifest) error {
+	if m == nil {
+		return ErrMissingManifest
+	}
+	for i, f := range m.Files {
+		if err := ValidateManifestFilepath(f.Filepath); err != nil {
+			return err
+		}
+		if i > 0 && m.Files[i-1].Filepath >= f.Filepath {
+			return fmt.Errorf("manifest filepaths must be unique and sorted, found %s after %s",
+				f.Filepath, m.Files[i-1].Filepath)
+		}
+		if err := validateManifestFileKeys(f); err != nil {
+			return err
+		}
+		if err := ValidateBytes(f.Sha256, sha256.Size, "Sha256"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateManifestFileKeys checks that a non-empty file has envelope and entry keys, whereas an
+// empty file, which has no entry, doesn't.
+func validateManifestFileKeys(f *ManifestFile) error {
+	if f.Size == 0 {
+		if len(f.EnvelopeKey) != 0 || len(f.EntryKey) != 0 {
+			return ErrUnexpectedEmptyFileKeys
+		}
+		return nil
+	}
+	if err := ValidateBytes(f.EnvelopeKey, DocumentKeyLength, "EnvelopeKey"); err != nil {
+		return err
+	}
+	return ValidateBytes(f.EntryKey, DocumentKeyLength, "EntryKey")
+}
+
+// ValidateManifestFilepath checks that a manifest filepath is a clean, relative, forward slash
+// separated path that stays within the manifest directory.
+func ValidateManifestFilepath(fp string) error {
+	if fp == "" {
+		return ErrEmptyManifestFilepath
+	}
+	if path.IsAbs(fp) || path.Clean(fp) != fp || fp == "." || fp == ".." ||
+		strings.HasPrefix(fp, "../") || strings.Contains(fp, "\\") {
+		return fmt.Errorf("invalid manifest filepath %s", fp)
+	}
+	return nil
+}
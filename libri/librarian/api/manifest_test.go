@@ -0,0 +1,79 @@
+package api
+
+import (
+	"crypto/sha256"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateManifest_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	assert.Nil(t, ValidateManifest(&Manifest{}))
+	m := &Manifest{
+		Files: []*ManifestFile{
+			newTestManifestFile(rng, "a.txt"),
+			newTestManifestFile(rng, "b/c.txt"),
+			newTestManifestFile(rng, "b/d/..e.txt"),
+			{Filepath: "empty.txt", Sha256: RandBytes(rng, sha256.Size)},
+		},
+	}
+	assert.Nil(t, ValidateManifest(m))
+}
+
+func TestValidateManifest_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	assert.Equal(t, ErrMissingManifest, ValidateManifest(nil))
+
+	cases := map[string]*Manifest{
+		"bad filepath": {Files: []*ManifestFile{newTestManifestFile(rng, "/a.txt")}},
+		"unsorted": {Files: []*ManifestFile{
+			newTestManifestFile(rng, "b.txt"),
+			newTestManifestFile(rng, "a.txt"),
+		}},
+		"duplicate": {Files: []*ManifestFile{
+			newTestManifestFile(rng, "a.txt"),
+			newTestManifestFile(rng, "a.txt"),
+		}},
+	}
+	badEnvKey := newTestManifestFile(rng, "a.txt")
+	badEnvKey.EnvelopeKey = nil
+	cases["bad envelope key"] = &Manifest{Files: []*ManifestFile{badEnvKey}}
+	badEntryKey := newTestManifestFile(rng, "a.txt")
+	badEntryKey.EntryKey = []byte{1, 2, 3}
+	cases["bad entry key"] = &Manifest{Files: []*ManifestFile{badEntryKey}}
+	badSha256 := newTestManifestFile(rng, "a.txt")
+	badSha256.Sha256 = make([]byte, sha256.Size)
+	cases["bad sha256"] = &Manifest{Files: []*ManifestFile{badSha256}}
+
+	emptyWithKey := newTestManifestFile(rng, "a.txt")
+	emptyWithKey.Size = 0
+	cases["empty file with keys"] = &Manifest{Files: []*ManifestFile{emptyWithKey}}
+
+	for desc, m := range cases {
+		assert.NotNil(t, ValidateManifest(m), desc)
+	}
+}
+
+func TestValidateManifestFilepath(t *testing.T) {
+	oks := []string{"a", "a.txt", "a/b.txt", "a/b/c", "..a", "a/..b"}
+	for _, fp := range oks {
+		assert.Nil(t, ValidateManifestFilepath(fp), fp)
+	}
+	errs := []string{"", ".", "..", "../a", "/a", "a/", "a//b", "a/../b", "./a", "a\\b"}
+	for _, fp := range errs {
+		assert.NotNil(t, ValidateManifestFilepath(fp), fp)
+	}
+}
+
+func newTestManifestFile(rng *rand.Rand, fp string) *ManifestFile {
+	return &ManifestFile{
+		Filepath:    fp,
+		EnvelopeKey: RandBytes(rng, DocumentKeyLength),
+		EntryKey:    RandBytes(rng, DocumentKeyLength),
+		Size:        uint64(rng.Intn(1024)) + 1,
+		Mode:        0644,
+		Sha256:      RandBytes(rng, sha256.Size),
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"errors"
+	"path"
 
 	"github.com/dustin/go-humanize"
 	"go.uber.org/zap/zapcore"
@@ -26,6 +27,22 @@ var (
 
 	// ErrMissingUncompressedSize indicates when metadata has zero-valued UncompressedSize.
 	ErrMissingUncompressedSize = errors.New("missing UncompressedSize")
+
+	// ErrIncompletePrevious indicates when metadata has only one of PreviousEntryKey and
+	// PreviousEnvelopeKey.
+	ErrIncompletePrevious = errors.New("PreviousEntryKey and PreviousEnvelopeKey must both be " +
+		"set or both be empty")
+
+	// ErrEmptyPropertyKey indicates when metadata has a property with an empty key.
+	ErrEmptyPropertyKey = errors.New("empty property key")
+
+	// ErrAbsoluteFilepath indicates when metadata has an absolute rather than relative Filepath.
+	ErrAbsoluteFilepath = errors.New("Filepath must be relative")
+
+	// ErrIncompleteSchemaArtifact indicates when a SchemaArtifact is missing a group, project,
+	// path, or version.
+	ErrIncompleteSchemaArtifact = errors.New("SchemaArtifact must have a group, project, path, " +
+		"and version")
 )
 
 // ValidateEntryMetadata checks that the metadata has all the required non-zero values.
@@ -45,6 +62,66 @@ func ValidateEntryMetadata(m *EntryMetadata) error {
 	if err := ValidateHMAC256(m.UncompressedMac); err != nil {
 		return err
 	}
+	return ValidateOptionalEntryMetadata(m)
+}
+
+// ValidateOptionalEntryMetadata checks the optional fields of metadata given when packing an
+// entry, i.e., those not set from the printed content, so they can be checked before printing.
+func ValidateOptionalEntryMetadata(m *EntryMetadata) error {
+	if err := validateOptional(m); err != nil {
+		return err
+	}
+	return validateLineage(m)
+}
+
+func validateOptional(m *EntryMetadata) error {
+	for key := range m.Properties {
+		if key == "" {
+			return ErrEmptyPropertyKey
+		}
+	}
+	if path.IsAbs(m.Filepath) {
+		return ErrAbsoluteFilepath
+	}
+	if m.Schema != nil {
+		if err := ValidateSchemaArtifact(m.Schema); err != nil {
+			return err
+		}
+	}
+	if m.DataDictionary != nil {
+		return ValidateSchemaArtifact(m.DataDictionary)
+	}
+	return nil
+}
+
+// ValidateSchemaArtifact checks that the schema artifact has all the required non-zero values. Its
+// name is optional.
+func ValidateSchemaArtifact(s *SchemaArtifact) error {
+	if s.Group == "" || s.Project == "" || s.Path == "" || s.Version == "" {
+		return ErrIncompleteSchemaArtifact
+	}
+	return nil
+}
+
+func validateLineage(m *EntryMetadata) error {
+	if (m.PreviousEntryKey == nil) != (m.PreviousEnvelopeKey == nil) {
+		return ErrIncompletePrevious
+	}
+	if m.PreviousEntryKey != nil {
+		if err := ValidateBytes(m.PreviousEntryKey, DocumentKeyLength,
+			"PreviousEntryKey"); err != nil {
+			return err
+		}
+		if err := ValidateBytes(m.PreviousEnvelopeKey, DocumentKeyLength,
+			"PreviousEnvelopeKey"); err != nil {
+			return err
+		}
+	}
+	for _, entryKey := range m.DerivedFromEntryKeys {
+		if err := ValidateBytes(entryKey, DocumentKeyLength, "DerivedFromEntryKeys"); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -22,6 +22,35 @@ func TestValidateMetadata_ok(t *testing.T) {
 	}
 	err := ValidateEntryMetadata(m)
 	assert.Nil(t, err)
+
+	// with lineage
+	m.PreviousEntryKey = RandBytes(rng, DocumentKeyLength)
+	m.PreviousEnvelopeKey = RandBytes(rng, DocumentKeyLength)
+	m.DerivedFromEntryKeys = [][]byte{
+		RandBytes(rng, DocumentKeyLength),
+		RandBytes(rng, DocumentKeyLength),
+	}
+	err = ValidateEntryMetadata(m)
+	assert.Nil(t, err)
+
+	// with properties, filepath, schema, and data dictionary
+	m.Properties = map[string][]byte{"run_id": []byte("1234")}
+	m.Filepath = "some/file.csv"
+	m.Schema = &SchemaArtifact{
+		Group:   "drausin",
+		Project: "libri",
+		Path:    "libri/librarian/api/documents.proto",
+		Name:    "EntryMetadata",
+		Version: "0.1.0",
+	}
+	m.DataDictionary = &SchemaArtifact{
+		Group:   "drausin",
+		Project: "libri",
+		Path:    "README.md",
+		Version: "0.1.0",
+	}
+	err = ValidateEntryMetadata(m)
+	assert.Nil(t, err)
 }
 
 func TestValidateMetadata_err(t *testing.T) {
@@ -62,6 +91,64 @@ func TestValidateMetadata_err(t *testing.T) {
 			UncompressedSize: 2,
 			UncompressedMac:  nil,
 		},
+		{ // 6
+			MediaType:           "application/x-pdf",
+			CiphertextSize:      1,
+			CiphertextMac:       RandBytes(rng, 32),
+			UncompressedSize:    2,
+			UncompressedMac:     RandBytes(rng, 32),
+			PreviousEntryKey:    RandBytes(rng, DocumentKeyLength),
+			PreviousEnvelopeKey: nil,
+		},
+		{ // 7
+			MediaType:           "application/x-pdf",
+			CiphertextSize:      1,
+			CiphertextMac:       RandBytes(rng, 32),
+			UncompressedSize:    2,
+			UncompressedMac:     RandBytes(rng, 32),
+			PreviousEntryKey:    RandBytes(rng, DocumentKeyLength),
+			PreviousEnvelopeKey: RandBytes(rng, 16),
+		},
+		{ // 8
+			MediaType:            "application/x-pdf",
+			CiphertextSize:       1,
+			CiphertextMac:        RandBytes(rng, 32),
+			UncompressedSize:     2,
+			UncompressedMac:      RandBytes(rng, 32),
+			DerivedFromEntryKeys: [][]byte{RandBytes(rng, 16)},
+		},
+		{ // 9
+			MediaType:        "application/x-pdf",
+			CiphertextSize:   1,
+			CiphertextMac:    RandBytes(rng, 32),
+			UncompressedSize: 2,
+			UncompressedMac:  RandBytes(rng, 32),
+			Properties:       map[string][]byte{"": []byte("1234")},
+		},
+		{ // 10
+			MediaType:        "application/x-pdf",
+			CiphertextSize:   1,
+			CiphertextMac:    RandBytes(rng, 32),
+			UncompressedSize: 2,
+			UncompressedMac:  RandBytes(rng, 32),
+			Filepath:         "/some/file.csv",
+		},
+		{ // 11
+			MediaType:        "application/x-pdf",
+			CiphertextSize:   1,
+			CiphertextMac:    RandBytes(rng, 32),
+			UncompressedSize: 2,
+			UncompressedMac:  RandBytes(rng, 32),
+			Schema:           &SchemaArtifact{Group: "drausin", Project: "libri"},
+		},
+		{ // 12
+			MediaType:        "application/x-pdf",
+			CiphertextSize:   1,
+			CiphertextMac:    RandBytes(rng, 32),
+			UncompressedSize: 2,
+			UncompressedMac:  RandBytes(rng, 32),
+			DataDictionary:   &SchemaArtifact{Path: "README.md", Version: "0.1.0"},
+		},
 	}
 	for i, m := range ms {
 		err := ValidateEntryMetadata(m)
@@ -69,6 +156,44 @@ func TestValidateMetadata_err(t *testing.T) {
 	}
 }
 
+func TestValidateOptionalEntryMetadata(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+
+	// no printed fields needed
+	m := &EntryMetadata{
+		PreviousEntryKey:     RandBytes(rng, DocumentKeyLength),
+		PreviousEnvelopeKey:  RandBytes(rng, DocumentKeyLength),
+		DerivedFromEntryKeys: [][]byte{RandBytes(rng, DocumentKeyLength)},
+		Properties:           map[string][]byte{"run_id": []byte("1234")},
+		Filepath:             "some/file.csv",
+		Schema: &SchemaArtifact{
+			Group:   "drausin",
+			Project: "libri",
+			Path:    "README.md",
+			Version: "0.1.0",
+		},
+	}
+	assert.Nil(t, ValidateOptionalEntryMetadata(m))
+	assert.Nil(t, ValidateOptionalEntryMetadata(&EntryMetadata{}))
+
+	ms := []*EntryMetadata{
+		{PreviousEntryKey: RandBytes(rng, DocumentKeyLength)},
+		{PreviousEnvelopeKey: RandBytes(rng, DocumentKeyLength)},
+		{
+			PreviousEntryKey:    RandBytes(rng, DocumentKeyLength),
+			PreviousEnvelopeKey: RandBytes(rng, 16),
+		},
+		{DerivedFromEntryKeys: [][]byte{RandBytes(rng, 16)}},
+		{Properties: map[string][]byte{"": []byte("1234")}},
+		{Filepath: "/some/file.csv"},
+		{Schema: &SchemaArtifact{Group: "drausin", Project: "libri"}},
+		{DataDictionary: &SchemaArtifact{Path: "README.md", Version: "0.1.0"}},
+	}
+	for i, m := range ms {
+		assert.NotNil(t, ValidateOptionalEntryMetadata(m), fmt.Sprintf("case %d", i))
+	}
+}
+
 func TestMetadata_MarshalLogObject(t *testing.T) {
 	oe := zapcore.NewJSONEncoder(zap.NewDevelopmentEncoderConfig())
 	rng := rand.New(rand.NewSource(0))
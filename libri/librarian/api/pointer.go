@@ -0,0 +1,106 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/golang/protobuf/proto"
+)
+
+// MaxPointerNameLength is the max byte length of a Pointer name.
+const MaxPointerNameLength = 256
+
+var (
+	// ErrMissingPointer indicates when a pointer is unexpectedly missing.
+	ErrMissingPointer = errors.New("missing pointer")
+
+	// ErrEmptyPointerName indicates when a pointer's name is empty.
+	ErrEmptyPointerName = errors.New("empty pointer name")
+
+	// ErrPointerNameTooLong indicates when a pointer's name is longer than MaxPointerNameLength.
+	ErrPointerNameTooLong = fmt.Errorf("pointer name longer than %d bytes", MaxPointerNameLength)
+
+	// ErrZeroPointerSequence indicates when a pointer's sequence number is zero.
+	ErrZeroPointerSequence = errors.New("pointer sequence is zero")
+
+	// ErrStalePointer indicates when a pointer would overwrite a stored pointer with a sequence
+	// number at least as high.
+	ErrStalePointer = errors.New("pointer sequence not greater than that of stored pointer")
+
+	// ErrInvalidPointerSignature indicates when a pointer's signature does not verify against its
+	// author public key.
+	ErrInvalidPointerSignature = errors.New("invalid pointer signature")
+)
+
+// GetPointerKey returns the key of the pointer with the given author public key and name, which
+// is the SHA-256 hash of the two concatenated.
+func GetPointerKey(authorPub []byte, name string) id.ID {
+	h := sha256.New()
+	_, _ = h.Write(authorPub)
+	_, _ = h.Write([]byte(name))
+	return id.FromBytes(h.Sum(nil))
+}
+
+// SignPointer sets the signature of the pointer by the given author key, whose public key must
+// be the pointer's author public key.
+func SignPointer(p *Pointer, authorKey *ecdsa.PrivateKey) error {
+	hash, err := hashUnsignedPointer(p)
+	if err != nil {
+		return err
+	}
+	p.Signature, err = ecid.Sign(authorKey, hash[:])
+	return err
+}
+
+// ValidatePointer checks that all fields of a Pointer are populated and have the expected lengths
+// and that its signature verifies against its author public key.
+func ValidatePointer(p *Pointer) error {
+	if p == nil {
+		return ErrMissingPointer
+	}
+	if err := ValidatePublicKey(p.AuthorPublicKey); err != nil {
+		return err
+	}
+	if p.Name == "" {
+		return ErrEmptyPointerName
+	}
+	if len(p.Name) > MaxPointerNameLength {
+		return ErrPointerNameTooLong
+	}
+	if p.Sequence == 0 {
+		return ErrZeroPointerSequence
+	}
+	if err := ValidateBytes(p.TargetEnvelopeKey, DocumentKeyLength,
+		"TargetEnvelopeKey"); err != nil {
+		return err
+	}
+	if err := ValidateNotEmpty(p.Signature, "Signature"); err != nil {
+		return err
+	}
+	authorPub, err := ecid.FromPublicKeyBytes(p.AuthorPublicKey)
+	if err != nil {
+		return err
+	}
+	hash, err := hashUnsignedPointer(p)
+	if err != nil {
+		return err
+	}
+	if !ecid.Verify(authorPub, hash[:], p.Signature) {
+		return ErrInvalidPointerSignature
+	}
+	return nil
+}
+
+func hashUnsignedPointer(p *Pointer) ([sha256.Size]byte, error) {
+	unsigned := *p
+	unsigned.Signature = nil
+	buf, err := proto.Marshal(&unsigned)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(buf), nil
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPointerKey(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	authorPub1 := ecid.NewPseudoRandom(rng).PublicKeyBytes()
+	authorPub2 := ecid.NewPseudoRandom(rng).PublicKeyBytes()
+
+	key := GetPointerKey(authorPub1, "name1")
+	assert.Equal(t, key, GetPointerKey(authorPub1, "name1"))
+	assert.NotEqual(t, key, GetPointerKey(authorPub1, "name2"))
+	assert.NotEqual(t, key, GetPointerKey(authorPub2, "name1"))
+}
+
+func TestSignPointer(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	p, authorKey := NewTestPointer(rng)
+	assert.Nil(t, ValidatePointer(p))
+
+	// re-signing after change still valid
+	p.Sequence++
+	assert.NotNil(t, ValidatePointer(p))
+	assert.Nil(t, SignPointer(p, authorKey.Key()))
+	assert.Nil(t, ValidatePointer(p))
+
+	// signing with another key is invalid
+	assert.Nil(t, SignPointer(p, ecid.NewPseudoRandom(rng).Key()))
+	assert.Equal(t, ErrInvalidPointerSignature, ValidatePointer(p))
+}
+
+func TestValidatePointer_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	assert.Equal(t, ErrMissingPointer, ValidatePointer(nil))
+
+	cases := map[string]func(p *Pointer){
+		"missing author pub":   func(p *Pointer) { p.AuthorPublicKey = nil },
+		"short author pub":     func(p *Pointer) { p.AuthorPublicKey = p.AuthorPublicKey[:8] },
+		"empty name":           func(p *Pointer) { p.Name = "" },
+		"long name":            func(p *Pointer) { p.Name = strings.Repeat("a", MaxPointerNameLength+1) },
+		"zero sequence":        func(p *Pointer) { p.Sequence = 0 },
+		"missing target":       func(p *Pointer) { p.TargetEnvelopeKey = nil },
+		"short target":         func(p *Pointer) { p.TargetEnvelopeKey = RandBytes(rng, 8) },
+		"missing signature":    func(p *Pointer) { p.Signature = nil },
+		"bad signature":        func(p *Pointer) { p.Signature = RandBytes(rng, 64) },
+		"changed after signed": func(p *Pointer) { p.Name = "other/name" },
+	}
+	for desc, mutate := range cases {
+		p, _ := NewTestPointer(rng)
+		mutate(p)
+		assert.NotNil(t, ValidatePointer(p), desc)
+	}
+}
@@ -27,6 +27,20 @@ func NewTestEnvelope(rng *rand.Rand) *Envelope {
 	}
 }
 
+// NewTestPointer generates a dummy signed Pointer for use in testing, returning it and the
+// author key that signed it.
+func NewTestPointer(rng *rand.Rand) (*Pointer, ecid.ID) {
+	authorKey := ecid.NewPseudoRandom(rng)
+	p := &Pointer{
+		AuthorPublicKey:   authorKey.PublicKeyBytes(),
+		Name:              "some/pointer/name",
+		Sequence:          1,
+		TargetEnvelopeKey: RandBytes(rng, DocumentKeyLength),
+	}
+	errors.MaybePanic(SignPointer(p, authorKey.Key()))
+	return p, authorKey
+}
+
 // NewTestSinglePageEntry generates a dummy Entry document with a single Page for use in testing.
 func NewTestSinglePageEntry(rng *rand.Rand) *Entry {
 	page := NewTestPage(rng)
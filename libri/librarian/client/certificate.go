@@ -0,0 +1,189 @@
+package client
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"io/ioutil"
+	"time"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/proto"
+)
+
+// DefaultCertificateTTL is the default time after being issued that a certificate expires.
+const DefaultCertificateTTL = 30 * 24 * time.Hour
+
+var (
+	// ErrMissingCertificate indicates when a certificate is required but absent.
+	ErrMissingCertificate = errors.New("missing certificate")
+
+	// ErrCertificateExpired indicates when a certificate's expiration time has passed.
+	ErrCertificateExpired = errors.New("certificate has expired")
+
+	// ErrCertificateNotYetValid indicates when a certificate's issued time is in the future.
+	ErrCertificateNotYetValid = errors.New("certificate issued time is in the future")
+
+	// ErrCertificatePubKeyMismatch indicates when a certificate was issued for a different public
+	// key than the one presenting it.
+	ErrCertificatePubKeyMismatch = errors.New("certificate public key does not match")
+
+	// ErrInvalidCertificateSignature indicates when a certificate was not signed by its
+	// organization.
+	ErrInvalidCertificateSignature = errors.New("invalid certificate signature")
+
+	errNonPositiveCertificateTTL = errors.New("certificate TTL must be positive")
+	errInvalidCertificateSerial  = errors.New("invalid certificate serial number length")
+	errMissingCertificateTimes   = errors.New("certificate issued and expiration times must " +
+		"be positive and increasing")
+)
+
+// NewCertificate returns a certificate for the given public key issued by the organization and
+// expiring after the given TTL.
+func NewCertificate(orgID ecid.ID, pubKey []byte, ttl time.Duration) (*api.PeerCertificate,
+	error) {
+	return newCertificate(orgID.Key(), pubKey, id.NewRandom().Bytes(), time.Now(), ttl)
+}
+
+func newCertificate(
+	orgKey *ecdsa.PrivateKey, pubKey, serial []byte, issued time.Time, ttl time.Duration,
+) (*api.PeerCertificate, error) {
+	if ttl <= 0 {
+		return nil, errNonPositiveCertificateTTL
+	}
+	if _, err := ecid.FromPublicKeyBytes(pubKey); err != nil {
+		return nil, err
+	}
+	cert := &api.PeerCertificate{
+		PubKey:    pubKey,
+		OrgPubKey: ecid.ToPublicKeyBytes(&orgKey.PublicKey),
+		Serial:    serial,
+		IssuedAt:  issued.Unix(),
+		ExpiresAt: issued.Add(ttl).Unix(),
+	}
+	hash, err := hashMessage(cert)
+	if err != nil {
+		return nil, err
+	}
+	cert.Signature, err = ecid.Sign(orgKey, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// ReadCertificate reads a certificate from a file.
+func ReadCertificate(filepath string) (*api.PeerCertificate, error) {
+	buf, err := ioutil.ReadFile(filepath) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	cert := &api.PeerCertificate{}
+	if err := proto.Unmarshal(buf, cert); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// WriteCertificate writes a certificate to a file.
+func WriteCertificate(filepath string, cert *api.PeerCertificate) error {
+	buf, err := proto.Marshal(cert)
+	if err != nil {
+		return err
+	}
+	const filePerm = 0644
+	return ioutil.WriteFile(filepath, buf, filePerm)
+}
+
+// CertificateVerifier verifies certificates.
+type CertificateVerifier interface {
+	// Verify verifies that the certificate was issued for the public key by its organization and
+	// is currently valid.
+	Verify(cert *api.PeerCertificate, pubKey []byte) error
+}
+
+type certificateVerifier struct {
+	clockSkew time.Duration
+	now       func() time.Time
+}
+
+// NewCertificateVerifier creates a new CertificateVerifier instance tolerating the given max
+// difference between the issuer's and verifier's clocks.
+func NewCertificateVerifier(clockSkew time.Duration) CertificateVerifier {
+	return &certificateVerifier{
+		clockSkew: clockSkew,
+		now:       time.Now,
+	}
+}
+
+func (v *certificateVerifier) Verify(cert *api.PeerCertificate, pubKey []byte) error {
+	if cert == nil {
+		return ErrMissingCertificate
+	}
+	if !bytes.Equal(cert.PubKey, pubKey) {
+		return ErrCertificatePubKeyMismatch
+	}
+	if len(cert.Serial) != id.Length {
+		return errInvalidCertificateSerial
+	}
+	if err := v.verifyTimes(cert); err != nil {
+		return err
+	}
+	orgPubKey, err := ecid.FromPublicKeyBytes(cert.OrgPubKey)
+	if err != nil {
+		return err
+	}
+	unsigned := *cert
+	unsigned.Signature = nil
+	hash, err := hashMessage(&unsigned)
+	if err != nil {
+		return err
+	}
+	if !ecid.Verify(orgPubKey, hash[:], cert.Signature) {
+		return ErrInvalidCertificateSignature
+	}
+	return nil
+}
+
+func (v *certificateVerifier) verifyTimes(cert *api.PeerCertificate) error {
+	if cert.IssuedAt <= 0 || cert.ExpiresAt <= cert.IssuedAt {
+		return errMissingCertificateTimes
+	}
+	now := v.now()
+	if time.Unix(cert.IssuedAt, 0).After(now.Add(v.clockSkew)) {
+		return ErrCertificateNotYetValid
+	}
+	if !time.Unix(cert.ExpiresAt, 0).After(now.Add(-v.clockSkew)) {
+		return ErrCertificateExpired
+	}
+	return nil
+}
+
+// CertifiedSigner is a Signer that presents a certificate in the metadata of the requests it
+// signs.
+type CertifiedSigner interface {
+	Signer
+
+	// Certificate returns the certificate for the signer's public key.
+	Certificate() *api.PeerCertificate
+}
+
+type certifiedSigner struct {
+	Signer
+	cert *api.PeerCertificate
+}
+
+// NewCertifiedSigner returns a CertifiedSigner presenting the given certificate with the
+// signatures from the given Signer.
+func NewCertifiedSigner(signer Signer, cert *api.PeerCertificate) CertifiedSigner {
+	return &certifiedSigner{
+		Signer: signer,
+		cert:   cert,
+	}
+}
+
+func (s *certifiedSigner) Certificate() *api.PeerCertificate {
+	return s.cert
+}
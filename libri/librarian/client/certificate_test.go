@@ -0,0 +1,175 @@
+package client
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCertificate_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	orgID, peerID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	cert, err := NewCertificate(orgID, peerID.PublicKeyBytes(), DefaultCertificateTTL)
+	assert.Nil(t, err)
+	assert.Equal(t, peerID.PublicKeyBytes(), cert.PubKey)
+	assert.Equal(t, orgID.PublicKeyBytes(), cert.OrgPubKey)
+	assert.Len(t, cert.Serial, id.Length)
+	assert.Equal(t, int64(DefaultCertificateTTL.Seconds()), cert.ExpiresAt-cert.IssuedAt)
+	assert.NotEmpty(t, cert.Signature)
+
+	err = NewCertificateVerifier(DefaultClockSkew).Verify(cert, peerID.PublicKeyBytes())
+	assert.Nil(t, err)
+}
+
+func TestNewCertificate_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	orgID, peerID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+
+	// bad TTL
+	cert, err := NewCertificate(orgID, peerID.PublicKeyBytes(), 0)
+	assert.Equal(t, errNonPositiveCertificateTTL, err)
+	assert.Nil(t, cert)
+
+	// bad public key
+	cert, err = NewCertificate(orgID, []byte{1, 2, 3}, DefaultCertificateTTL)
+	assert.NotNil(t, err)
+	assert.Nil(t, cert)
+}
+
+func TestReadWriteCertificate(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	orgID, peerID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	dir, err := ioutil.TempDir("", "test-certificate")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	filepath := path.Join(dir, "peer.cert")
+
+	cert1, err := NewCertificate(orgID, peerID.PublicKeyBytes(), DefaultCertificateTTL)
+	assert.Nil(t, err)
+	err = WriteCertificate(filepath, cert1)
+	assert.Nil(t, err)
+	cert2, err := ReadCertificate(filepath)
+	assert.Nil(t, err)
+	assert.Equal(t, cert1, cert2)
+
+	// missing file
+	cert3, err := ReadCertificate(path.Join(dir, "other.cert"))
+	assert.NotNil(t, err)
+	assert.Nil(t, cert3)
+
+	// file isn't a certificate
+	err = ioutil.WriteFile(filepath, []byte("not a certificate"), 0644)
+	assert.Nil(t, err)
+	cert3, err = ReadCertificate(filepath)
+	assert.NotNil(t, err)
+	assert.Nil(t, cert3)
+}
+
+func TestCertificateVerifier_Verify_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	orgID, peerID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	now := time.Unix(1500000000, 0)
+	skew := DefaultClockSkew
+	v := &certificateVerifier{clockSkew: skew, now: func() time.Time { return now }}
+	newCert := func(issued time.Time, ttl time.Duration) *api.PeerCertificate {
+		cert, err := newCertificate(orgID.Key(), peerID.PublicKeyBytes(),
+			id.NewPseudoRandom(rng).Bytes(), issued, ttl)
+		assert.Nil(t, err)
+		return cert
+	}
+	ttl := DefaultCertificateTTL
+
+	// ok, including within clock skew
+	for _, issued := range []time.Time{now, now.Add(skew / 2), now.Add(-ttl - skew/2)} {
+		assert.Nil(t, v.Verify(newCert(issued, ttl), peerID.PublicKeyBytes()))
+	}
+
+	cases := map[string]struct {
+		cert   func() *api.PeerCertificate
+		pubKey []byte
+		err    error
+	}{
+		"missing": {
+			cert:   func() *api.PeerCertificate { return nil },
+			pubKey: peerID.PublicKeyBytes(),
+			err:    ErrMissingCertificate,
+		},
+		"other pub key": {
+			cert:   func() *api.PeerCertificate { return newCert(now, ttl) },
+			pubKey: orgID.PublicKeyBytes(),
+			err:    ErrCertificatePubKeyMismatch,
+		},
+		"bad serial": {
+			cert: func() *api.PeerCertificate {
+				cert := newCert(now, ttl)
+				cert.Serial = cert.Serial[:8]
+				return cert
+			},
+			pubKey: peerID.PublicKeyBytes(),
+			err:    errInvalidCertificateSerial,
+		},
+		"missing times": {
+			cert: func() *api.PeerCertificate {
+				cert := newCert(now, ttl)
+				cert.IssuedAt = 0
+				return cert
+			},
+			pubKey: peerID.PublicKeyBytes(),
+			err:    errMissingCertificateTimes,
+		},
+		"not yet valid": {
+			cert:   func() *api.PeerCertificate { return newCert(now.Add(2*skew), ttl) },
+			pubKey: peerID.PublicKeyBytes(),
+			err:    ErrCertificateNotYetValid,
+		},
+		"expired": {
+			cert:   func() *api.PeerCertificate { return newCert(now.Add(-ttl-2*skew), ttl) },
+			pubKey: peerID.PublicKeyBytes(),
+			err:    ErrCertificateExpired,
+		},
+		"extended expiration": {
+			cert: func() *api.PeerCertificate {
+				cert := newCert(now, ttl)
+				cert.ExpiresAt += 3600
+				return cert
+			},
+			pubKey: peerID.PublicKeyBytes(),
+			err:    ErrInvalidCertificateSignature,
+		},
+		"other org": {
+			cert: func() *api.PeerCertificate {
+				cert := newCert(now, ttl)
+				cert.OrgPubKey = ecid.NewPseudoRandom(rng).PublicKeyBytes()
+				return cert
+			},
+			pubKey: peerID.PublicKeyBytes(),
+			err:    ErrInvalidCertificateSignature,
+		},
+	}
+	for desc, c := range cases {
+		err := v.Verify(c.cert(), c.pubKey)
+		assert.Equal(t, c.err, err, desc)
+	}
+
+	// bad org public key
+	cert := newCert(now, ttl)
+	cert.OrgPubKey = []byte{1, 2, 3}
+	assert.NotNil(t, v.Verify(cert, peerID.PublicKeyBytes()))
+}
+
+func TestCertifiedSigner(t *testing.T) {
+	cert := &api.PeerCertificate{Serial: []byte{1, 2, 3}}
+	s := NewCertifiedSigner(&TestNoOpSigner{}, cert)
+	assert.Equal(t, cert, s.Certificate())
+	token, err := s.Sign(nil)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/metadata"
@@ -14,6 +15,11 @@ const (
 	orgSignatureKey = "orgsignature"
 )
 
+// metadataRequest is a request with metadata, which all the api requests are.
+type metadataRequest interface {
+	GetMetadata() *api.RequestMetadata
+}
+
 var (
 	errContextMissingMetadata  = errors.New("context unexpectedly missing metadata")
 	errContextMissingSignature = errors.New("metadata signature key unexpectedly does not " +
@@ -33,7 +39,8 @@ func NewSignatureContext(ctx context.Context, signedJWT, orgSignedJWT string) co
 }
 
 // NewIncomingSignatureContext creates a new context with the signed JSON web token (JWT) string
-// in the incoming metadata field. This function should only be used for testing.
+// in the incoming metadata field. This function should only be used for testing and for requests
+// received other than via gRPC, e.g., by an HTTP gateway.
 func NewIncomingSignatureContext(
 	ctx context.Context, signedJWT, orgSignedJWT string,
 ) context.Context {
@@ -66,6 +73,13 @@ func FromSignatureContext(ctx context.Context) (string, string, error) {
 func NewSignedContext(signer, orgSigner Signer, request proto.Message) (context.Context, error) {
 	ctx := context.Background()
 
+	// present the signer's certificate, if it has one, before signing so the signatures cover it
+	if cs, ok := signer.(CertifiedSigner); ok {
+		if rq, ok := request.(metadataRequest); ok && rq.GetMetadata() != nil {
+			rq.GetMetadata().Cert = cs.Certificate()
+		}
+	}
+
 	// sign the message
 	signedJWT, err := signer.Sign(request)
 	if err != nil {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/metadata"
 )
@@ -93,3 +94,27 @@ func TestNewSignedTimeoutContext_err(t *testing.T) {
 	assert.NotNil(t, cancel)
 	assert.NotNil(t, err)
 }
+
+func TestNewSignedContext_certificate(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	cert := &api.PeerCertificate{Serial: id.NewPseudoRandom(rng).Bytes()}
+	rq := NewFindRequest(
+		ecid.NewPseudoRandom(rng),
+		ecid.NewPseudoRandom(rng),
+		id.NewPseudoRandom(rng),
+		20,
+	)
+
+	// uncertified signer doesn't present a certificate
+	ctx, err := NewSignedContext(&TestNoOpSigner{}, &TestNoOpSigner{}, rq)
+	assert.Nil(t, err)
+	assert.NotNil(t, ctx)
+	assert.Nil(t, rq.Metadata.Cert)
+
+	// certified signer does
+	ctx, err = NewSignedContext(NewCertifiedSigner(&TestNoOpSigner{}, cert),
+		&TestNoOpSigner{}, rq)
+	assert.Nil(t, err)
+	assert.NotNil(t, ctx)
+	assert.Equal(t, cert, rq.Metadata.Cert)
+}
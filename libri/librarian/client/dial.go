@@ -0,0 +1,97 @@
+package client
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/drausin/libri/libri/librarian/relay"
+)
+
+const (
+	// FallbackDelay is how long Dial waits for a connection to one of a peer's addresses before
+	// also trying its next address.
+	FallbackDelay = 300 * time.Millisecond
+
+	// addressSep separates the addresses in a multi-address dial target.
+	addressSep = ","
+)
+
+var errNoAddresses = errors.New("no addresses to dial")
+
+// JoinAddresses returns the dial target for a peer reachable at any of the given host:port
+// addresses, which Dial tries in order.
+func JoinAddresses(addresses []string) string {
+	return strings.Join(addresses, addressSep)
+}
+
+// Dial connects to the target, which may be a single host:port address, multiple addresses (see
+// JoinAddresses), or a relayed target (see relay.Target). Hosts may be IPv4 or IPv6 addresses or
+// DNS names, which are resolved when dialing. Multiple addresses are tried in order, each given
+// FallbackDelay before the next is also tried, and the first connection made wins. Its
+// signature matches that expected by grpc.WithDialer.
+func Dial(target string, timeout time.Duration) (net.Conn, error) {
+	if _, _, relayed := relay.ParseTarget(target); relayed {
+		return relay.Dial(target, timeout)
+	}
+	return dialFirst(strings.Split(target, addressSep), timeout, FallbackDelay)
+}
+
+type dialResult struct {
+	conn net.Conn
+	err  error
+}
+
+func dialFirst(addresses []string, timeout, fallbackDelay time.Duration) (net.Conn, error) {
+	if len(addresses) == 0 || addresses[0] == "" {
+		return nil, errNoAddresses
+	}
+	if len(addresses) == 1 {
+		return net.DialTimeout("tcp", addresses[0], timeout)
+	}
+	results := make(chan dialResult, len(addresses))
+	next, nInFlight := 0, 0
+	dialNext := func() {
+		go func(address string) {
+			conn, err := net.DialTimeout("tcp", address, timeout)
+			results <- dialResult{conn: conn, err: err}
+		}(addresses[next])
+		next++
+		nInFlight++
+	}
+	var firstErr error
+	for next < len(addresses) || nInFlight > 0 {
+		if nInFlight == 0 {
+			// nothing in flight, so try next address right away
+			dialNext()
+		}
+		var fallback <-chan time.Time
+		if next < len(addresses) {
+			fallback = time.After(fallbackDelay)
+		}
+		select {
+		case result := <-results:
+			nInFlight--
+			if result.err == nil {
+				go closeLate(results, nInFlight)
+				return result.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = result.err
+			}
+		case <-fallback:
+			dialNext()
+		}
+	}
+	return nil, firstErr
+}
+
+// closeLate closes the connections of dials still in flight after another has won.
+func closeLate(results chan dialResult, nInFlight int) {
+	for c := 0; c < nInFlight; c++ {
+		if result := <-results; result.err == nil {
+			_ = result.conn.Close()
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/relay"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinAddresses(t *testing.T) {
+	assert.Equal(t, "1.2.3.4:20100", JoinAddresses([]string{"1.2.3.4:20100"}))
+	assert.Equal(t, "1.2.3.4:20100,[2001:db8::1]:20100,librarian.example.com:20100",
+		JoinAddresses([]string{
+			"1.2.3.4:20100", "[2001:db8::1]:20100", "librarian.example.com:20100",
+		}))
+}
+
+func TestDial_ok(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, lis.Close()) }()
+	closedAddr := newClosedAddress(t)
+
+	targets := []string{
+		lis.Addr().String(),
+		JoinAddresses([]string{lis.Addr().String(), closedAddr}),
+		JoinAddresses([]string{closedAddr, lis.Addr().String()}),
+		JoinAddresses([]string{closedAddr, closedAddr, lis.Addr().String()}),
+	}
+	for _, target := range targets {
+		conn, err := Dial(target, time.Second)
+		assert.Nil(t, err, target)
+		assert.Equal(t, lis.Addr().String(), conn.RemoteAddr().String())
+		assert.Nil(t, conn.Close())
+	}
+}
+
+func TestDial_err(t *testing.T) {
+	closedAddr := newClosedAddress(t)
+
+	targets := []string{
+		"",
+		closedAddr,
+		JoinAddresses([]string{closedAddr, closedAddr}),
+		relay.Target(id.FromInt64(1), closedAddr),
+	}
+	for _, target := range targets {
+		conn, err := Dial(target, time.Second)
+		assert.NotNil(t, err, target)
+		assert.Nil(t, conn)
+	}
+}
+
+func TestDialFirst_fallback(t *testing.T) {
+	lis1, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, lis1.Close()) }()
+	lis2, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, lis2.Close()) }()
+
+	// with no fallback delay, both get dialed and whichever connects first wins
+	addresses := []string{lis1.Addr().String(), lis2.Addr().String()}
+	conn, err := dialFirst(addresses, time.Second, 0)
+	assert.Nil(t, err)
+	assert.Contains(t, addresses, conn.RemoteAddr().String())
+	assert.Nil(t, conn.Close())
+
+	// with long fallback delay, first address wins
+	conn, err = dialFirst(addresses, time.Second, time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, lis1.Addr().String(), conn.RemoteAddr().String())
+	assert.Nil(t, conn.Close())
+}
+
+// newClosedAddress returns the address of a local port that nothing listens on.
+func newClosedAddress(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := lis.Addr().String()
+	assert.Nil(t, lis.Close())
+	return addr
+}
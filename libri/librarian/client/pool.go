@@ -28,14 +28,16 @@ type lruPool struct {
 	evictionErr chan error
 }
 
-// NewLRUPool creates a new LRU Pool with the given number of max connections.
-func NewLRUPool(maxConns int) (Pool, error) {
-	return newLRUPool(maxConns, insecureDialer{}, closerImpl{})
+// NewLRUPool creates a new LRU Pool with the given number of max connections. Any dial options
+// (e.g., interceptors) are used in addition to the defaults when creating new connections.
+// Addresses may be any target Dial accepts, e.g., multiple addresses of the same peer.
+func NewLRUPool(maxConns int, opts ...grpc.DialOption) (Pool, error) {
+	return newLRUPool(maxConns, insecureDialer{opts: opts}, closerImpl{})
 }
 
 // NewDefaultLRUPool creates a new LRU pool with the default number of max connections.
-func NewDefaultLRUPool() (Pool, error) {
-	return NewLRUPool(defaultMaxConns)
+func NewDefaultLRUPool(opts ...grpc.DialOption) (Pool, error) {
+	return NewLRUPool(defaultMaxConns, opts...)
 }
 
 func newLRUPool(maxConns int, dialer dialer, closer closer) (Pool, error) {
@@ -93,10 +95,16 @@ type dialer interface {
 	dial(address string) (*grpc.ClientConn, error)
 }
 
-type insecureDialer struct{}
+type insecureDialer struct {
+	opts []grpc.DialOption
+}
 
-func (insecureDialer) dial(address string) (*grpc.ClientConn, error) {
-	return grpc.Dial(address, grpc.WithInsecure())
+func (d insecureDialer) dial(address string) (*grpc.ClientConn, error) {
+	opts := append([]grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithDialer(Dial),
+	}, d.opts...)
+	return grpc.Dial(address, opts...)
 }
 
 // closer is a very thin wrapper around (*grpc.ClientConn).Close() to facilitate mocking during
@@ -14,6 +14,10 @@ func TestNewDefaultLRUPool(t *testing.T) {
 	p, err := NewDefaultLRUPool()
 	assert.Nil(t, err)
 	assert.NotNil(t, p)
+
+	p, err = NewDefaultLRUPool(grpc.WithBlock())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(p.(*lruPool).dialer.(insecureDialer).opts))
 }
 
 func TestLRUPool_Get_ok(t *testing.T) {
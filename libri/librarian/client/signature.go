@@ -3,46 +3,115 @@ package client
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"regexp"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
-	"github.com/drausin/libri/libri/common/errors"
+	cerrors "github.com/drausin/libri/libri/common/errors"
 	"github.com/golang/protobuf/proto"
 )
 
-// regex pattern for a base-64 url-encoded string for a 256-bit number
-var b64url256bit *regexp.Regexp
+const (
+	// DefaultSignatureTTL is the default time after being issued that a signature expires.
+	DefaultSignatureTTL = 1 * time.Minute
+
+	// MaxSignatureTTL is the max time between a signature being issued and expiring that a
+	// Verifier accepts. It bounds how long a verifier needs to remember requests it has seen to
+	// detect replays.
+	MaxSignatureTTL = 10 * time.Minute
+
+	// DefaultClockSkew is the default max difference between the signer's and verifier's
+	// clocks that a Verifier tolerates when checking a signature's issued and expiration times.
+	DefaultClockSkew = 30 * time.Second
+
+	// nonceLength is the number of random bytes in each signature's nonce.
+	nonceLength = 16
+)
+
+var (
+	// ErrSignatureExpired indicates when a signature's expiration time has passed.
+	ErrSignatureExpired = errors.New("signature has expired")
+
+	// ErrSignatureNotYetValid indicates when a signature's issued time is in the future.
+	ErrSignatureNotYetValid = errors.New("signature issued time is in the future")
+
+	// ErrSignatureTTLTooLong indicates when a signature is valid for longer than
+	// MaxSignatureTTL.
+	ErrSignatureTTLTooLong = errors.New("signature is valid for too long")
+
+	errMissingTimes = errors.New("signature issued and expiration times must be positive and " +
+		"increasing")
+)
+
+// regex patterns for base-64 url-encoded strings for 256- and 128-bit numbers
+var b64url256bit, b64url128bit *regexp.Regexp
 
 func init() {
 	// base-64 encoded 256-bit values have 43 chars followed by an =
 	var err error
 	b64url256bit, err = regexp.Compile(`^[A-Za-z0-9\-_]{43}=$`)
-	errors.MaybePanic(err)
+	cerrors.MaybePanic(err)
+
+	// base-64 encoded 128-bit values have 22 chars followed by ==
+	b64url128bit, err = regexp.Compile(`^[A-Za-z0-9\-_]{22}==$`)
+	cerrors.MaybePanic(err)
 }
 
 // Claims holds the claims associated with a message signature.
 type Claims struct {
 	// Hash is the base-64-url encoded string of the hash of the message being signed
 	Hash string `json:"hash"`
+
+	// IssuedAt is the epoch time (in seconds) the signature was created.
+	IssuedAt int64 `json:"iat"`
+
+	// ExpiresAt is the epoch time (in seconds) after which the signature is no longer valid.
+	ExpiresAt int64 `json:"exp"`
+
+	// Nonce is the base-64-url encoded string of random bytes unique to the signature.
+	Nonce string `json:"nonce"`
 }
 
-// Valid returns whether the claim is valid or invalid via an error.
+// Valid returns whether the claim is valid or invalid via an error. It does not check the issued
+// and expiration times against the current time, which the Verifier does.
 func (c *Claims) Valid() error {
 	// check that message hash looks like a base-64-url encoded string
 	if !b64url256bit.MatchString(c.Hash) {
 		return fmt.Errorf("%v does not look like a base-64-url encoded 32-byte number",
 			c.Hash)
 	}
+	if !b64url128bit.MatchString(c.Nonce) {
+		return fmt.Errorf("%v does not look like a base-64-url encoded 16-byte nonce",
+			c.Nonce)
+	}
+	if c.IssuedAt <= 0 || c.ExpiresAt <= c.IssuedAt {
+		return errMissingTimes
+	}
 	return nil
 }
 
-// NewSignatureClaims creates a new SignatureClaims instance with the given message hash.
+// NewSignatureClaims creates a new SignatureClaims instance with the given message hash, issued
+// now and expiring after DefaultSignatureTTL.
 func NewSignatureClaims(hash [sha256.Size]byte) *Claims {
+	nonce := make([]byte, nonceLength)
+	_, err := rand.Read(nonce)
+	cerrors.MaybePanic(err) // should never happen
+	return newSignatureClaims(hash, time.Now(), DefaultSignatureTTL, nonce)
+}
+
+func newSignatureClaims(
+	hash [sha256.Size]byte, issued time.Time, ttl time.Duration, nonce []byte,
+) *Claims {
 	return &Claims{
-		Hash: base64.URLEncoding.EncodeToString(hash[:]),
+		Hash:      base64.URLEncoding.EncodeToString(hash[:]),
+		IssuedAt:  issued.Unix(),
+		ExpiresAt: issued.Add(ttl).Unix(),
+		Nonce:     base64.URLEncoding.EncodeToString(nonce),
 	}
 }
 
@@ -78,29 +147,60 @@ func (s *ecdsaSigner) Sign(m proto.Message) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	return SignHash(s.key, hash)
+}
 
+// SignHash returns the signature (in the form of an encoded json web token) on the given hash,
+// issued now and expiring after DefaultSignatureTTL. Signing the hash of a protobuf-encoded
+// message is the same as signing the message.
+func SignHash(key *ecdsa.PrivateKey, hash [sha256.Size]byte) (string, error) {
 	// create token
 	token := jwt.NewWithClaims(jwt.SigningMethodES256, NewSignatureClaims(hash))
 
 	// sign with key, yield encoded token string like XXXXXX.YYYYYY.ZZZZZZ
-	return token.SignedString(s.key)
+	return token.SignedString(key)
 }
 
 // Verifier verifies the signature on a message.
 type Verifier interface {
 	// Verify verifies that the encoded token is well formed and has been signed by the peer.
 	Verify(encToken string, fromPubKey *ecdsa.PublicKey, m proto.Message) error
+
+	// VerifyHash verifies that the encoded token is well formed and has been signed by the peer
+	// over the given hash, e.g., of an HTTP request body rather than a protobuf message.
+	VerifyHash(encToken string, fromPubKey *ecdsa.PublicKey, hash [sha256.Size]byte) error
 }
 
-type ecsdaVerifier struct{}
+type ecsdaVerifier struct {
+	clockSkew time.Duration
+	now       func() time.Time
+}
 
-// NewVerifier creates a new Verifier instance.
+// NewVerifier creates a new Verifier instance tolerating DefaultClockSkew.
 func NewVerifier() Verifier {
-	return &ecsdaVerifier{}
+	return NewClockSkewVerifier(DefaultClockSkew)
+}
+
+// NewClockSkewVerifier creates a new Verifier instance tolerating the given max difference
+// between the signer's and verifier's clocks.
+func NewClockSkewVerifier(clockSkew time.Duration) Verifier {
+	return &ecsdaVerifier{
+		clockSkew: clockSkew,
+		now:       time.Now,
+	}
 }
 
 func (v *ecsdaVerifier) Verify(encToken string, fromPubKey *ecdsa.PublicKey,
 	m proto.Message) error {
+	hash, err := hashMessage(m)
+	if err != nil {
+		return err
+	}
+	return v.VerifyHash(encToken, fromPubKey, hash)
+}
+
+func (v *ecsdaVerifier) VerifyHash(encToken string, fromPubKey *ecdsa.PublicKey,
+	hash [sha256.Size]byte) error {
 	token, err := jwt.ParseWithClaims(encToken, &Claims{}, func(token *jwt.Token) (
 		interface{}, error) {
 		return fromPubKey, nil
@@ -114,15 +214,38 @@ func (v *ecsdaVerifier) Verify(encToken string, fromPubKey *ecdsa.PublicKey,
 	if !ok {
 		return fmt.Errorf("token claims %v are not expected SignatureClaims", token.Claims)
 	}
+	if err := v.verifyTimes(claims); err != nil {
+		return err
+	}
+	return verifyHash(hash, claims.Hash)
+}
 
-	return verifyMessageHash(m, claims.Hash)
+func (v *ecsdaVerifier) verifyTimes(claims *Claims) error {
+	now := v.now()
+	issued, expires := time.Unix(claims.IssuedAt, 0), time.Unix(claims.ExpiresAt, 0)
+	if expires.Sub(issued) > MaxSignatureTTL {
+		return ErrSignatureTTLTooLong
+	}
+	if issued.After(now.Add(v.clockSkew)) {
+		return ErrSignatureNotYetValid
+	}
+	if !expires.After(now.Add(-v.clockSkew)) {
+		return ErrSignatureExpired
+	}
+	return nil
 }
 
-func verifyMessageHash(m proto.Message, encClaimedHash string) error {
-	messageHash, err := hashMessage(m)
-	if err != nil {
-		return err
+// ParseClaims returns the claims of the encoded token without verifying its signature, so it
+// should only be used for tokens a Verifier has already verified.
+func ParseClaims(encToken string) (*Claims, error) {
+	claims := &Claims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(encToken, claims); err != nil {
+		return nil, err
 	}
+	return claims, nil
+}
+
+func verifyHash(messageHash [sha256.Size]byte, encClaimedHash string) error {
 	claimedHash, err := base64.URLEncoding.DecodeString(encClaimedHash)
 	if err != nil {
 		return err
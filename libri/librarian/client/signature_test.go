@@ -1,11 +1,13 @@
 package client
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"math/rand"
 	"testing"
+	"time"
 
-	"fmt"
-
+	"github.com/dgrijalva/jwt-go"
 	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/errors"
 	"github.com/drausin/libri/libri/common/id"
@@ -15,12 +17,18 @@ import (
 )
 
 func TestSignatureClaims_Valid_ok(t *testing.T) {
+	nonce, iat, exp := "47DEQpj8HBSa-_TImW-5JA==", int64(1500000000), int64(1500000060)
+
 	// all of these should be considered valid hashes
 	cases := []*Claims{
-		{"n4bQgYhMfWWaL-qgxVrQFaO_TxsrC4Is0V1sFbDwCgg="},
-		{"9nITsSKl1ELSuTvajMRcVkpw7F0qTg6Vu1hc8ZmGnJg="},
-		{"-MAqRWZ-E5DpcCh23U3GwAZuSbXNqm7ByD59iL6S4uI="},
-		{"47DEQpj8HBSa-_TImW-5JCeuQeRkm5NMpJWZG3hSuFU="},
+		{Hash: "n4bQgYhMfWWaL-qgxVrQFaO_TxsrC4Is0V1sFbDwCgg=", IssuedAt: iat, ExpiresAt: exp,
+			Nonce: nonce},
+		{Hash: "9nITsSKl1ELSuTvajMRcVkpw7F0qTg6Vu1hc8ZmGnJg=", IssuedAt: iat, ExpiresAt: exp,
+			Nonce: nonce},
+		{Hash: "-MAqRWZ-E5DpcCh23U3GwAZuSbXNqm7ByD59iL6S4uI=", IssuedAt: iat, ExpiresAt: exp,
+			Nonce: nonce},
+		{Hash: "47DEQpj8HBSa-_TImW-5JCeuQeRkm5NMpJWZG3hSuFU=", IssuedAt: iat, ExpiresAt: exp,
+			Nonce: nonce},
 	}
 	for _, c := range cases {
 		assert.Nil(t, c.Valid())
@@ -28,20 +36,40 @@ func TestSignatureClaims_Valid_ok(t *testing.T) {
 }
 
 func TestSignatureClaims_Valid_err(t *testing.T) {
+	hash, nonce := "n4bQgYhMfWWaL-qgxVrQFaO_TxsrC4Is0V1sFbDwCgg=", "47DEQpj8HBSa-_TImW-5JA=="
+	iat, exp := int64(1500000000), int64(1500000060)
+
 	// none of these is valid
 	cases := []*Claims{
-		{"n4bQgYhMfWWaL-qgxVrQFaO_TxsrC4Is0V1sFbDwCgga"},       // missing last =
-		{"n4bQgYhMfWWaL+qgxVrQFaO_TxsrC4Is0V1sFbDwCgga"},       // + part of non-url base-64
-		{"9nITsSKl1ELSuTvajMRcVkpw7F0qTg6Vu1hc8ZmGnJg"},        // too short
-		{"9nITsSKl1ELSuTvajMRcVkpw7F0qTg6Vu1hc8ZmGnJgggggggg"}, // too long
-		{""},            // too short
-		{"test *&*&*&"}, // invalid chars
+		{Hash: "n4bQgYhMfWWaL-qgxVrQFaO_TxsrC4Is0V1sFbDwCgga"},       // missing last =
+		{Hash: "n4bQgYhMfWWaL+qgxVrQFaO_TxsrC4Is0V1sFbDwCgga"},       // + part of non-url b64
+		{Hash: "9nITsSKl1ELSuTvajMRcVkpw7F0qTg6Vu1hc8ZmGnJg"},        // too short
+		{Hash: "9nITsSKl1ELSuTvajMRcVkpw7F0qTg6Vu1hc8ZmGnJgggggggg"}, // too long
+		{Hash: ""},            // too short
+		{Hash: "test *&*&*&"}, // invalid chars
+
+		{Hash: hash, IssuedAt: iat, ExpiresAt: exp},               // missing nonce
+		{Hash: hash, IssuedAt: iat, ExpiresAt: exp, Nonce: hash},  // nonce too long
+		{Hash: hash, Nonce: nonce},                                // missing times
+		{Hash: hash, IssuedAt: iat, Nonce: nonce},                 // missing exp
+		{Hash: hash, IssuedAt: exp, ExpiresAt: iat, Nonce: nonce}, // exp before iat
+		{Hash: hash, IssuedAt: -1, ExpiresAt: exp, Nonce: nonce},  // negative iat
+		{Hash: hash, IssuedAt: iat, ExpiresAt: iat, Nonce: nonce}, // exp same as iat
 	}
 	for _, c := range cases {
 		assert.NotNil(t, c.Valid())
 	}
 }
 
+func TestNewSignatureClaims(t *testing.T) {
+	var hash [sha256.Size]byte
+	c1, c2 := NewSignatureClaims(hash), NewSignatureClaims(hash)
+	assert.Nil(t, c1.Valid())
+	assert.Equal(t, c1.Hash, c2.Hash)
+	assert.NotEqual(t, c1.Nonce, c2.Nonce)
+	assert.Equal(t, int64(DefaultSignatureTTL/time.Second), c1.ExpiresAt-c1.IssuedAt)
+}
+
 func TestEcdsaSignerVerifer_SignVerify_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	peerID := ecid.NewPseudoRandom(rng)
@@ -112,6 +140,90 @@ func TestEcdsaVerifer_Verify_err(t *testing.T) {
 	})
 }
 
+func TestSignHash_VerifyHash(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := ecid.NewPseudoRandom(rng)
+	verifier := NewVerifier()
+	body := []byte(`{"metadata": {}, "key": "some key"}`)
+	hash := sha256.Sum256(body)
+
+	encToken, err := SignHash(peerID.Key(), hash)
+	assert.Nil(t, err)
+	assert.Nil(t, verifier.VerifyHash(encToken, &peerID.Key().PublicKey, hash))
+
+	// different body
+	otherHash := sha256.Sum256([]byte(`{"metadata": {}, "key": "other key"}`))
+	assert.NotNil(t, verifier.VerifyHash(encToken, &peerID.Key().PublicKey, otherHash))
+
+	// different peer
+	otherPubKey := &ecid.NewPseudoRandom(rng).Key().PublicKey
+	assert.NotNil(t, verifier.VerifyHash(encToken, otherPubKey, hash))
+
+	// signing the hash of a message is the same as signing the message
+	message := NewFindRequest(peerID, ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng), 20)
+	messageBytes, err := proto.Marshal(message)
+	assert.Nil(t, err)
+	encToken, err = SignHash(peerID.Key(), sha256.Sum256(messageBytes))
+	assert.Nil(t, err)
+	assert.Nil(t, verifier.Verify(encToken, &peerID.Key().PublicKey, message))
+}
+
+func TestParseClaims(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	hash := sha256.Sum256([]byte("some message"))
+	encToken, err := SignHash(ecid.NewPseudoRandom(rng).Key(), hash)
+	assert.Nil(t, err)
+
+	claims, err := ParseClaims(encToken)
+	assert.Nil(t, err)
+	assert.Nil(t, claims.Valid())
+	assert.Equal(t, int64(DefaultSignatureTTL.Seconds()), claims.ExpiresAt-claims.IssuedAt)
+
+	claims, err = ParseClaims("not a token")
+	assert.NotNil(t, err)
+	assert.Nil(t, claims)
+}
+
+func TestEcdsaVerifer_Verify_times(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := ecid.NewPseudoRandom(rng)
+	key := id.NewPseudoRandom(rng)
+	message := NewFindRequest(peerID, nil, key, 20)
+	hash, err := hashMessage(message)
+	assert.Nil(t, err)
+	nonce := make([]byte, nonceLength)
+	now := time.Unix(1500000000, 0)
+	verifier := &ecsdaVerifier{
+		clockSkew: DefaultClockSkew,
+		now:       func() time.Time { return now },
+	}
+
+	cases := map[string]struct {
+		issued   time.Time
+		ttl      time.Duration
+		expected error
+	}{
+		"now":             {now, DefaultSignatureTTL, nil},
+		"slightly future": {now.Add(DefaultClockSkew / 2), DefaultSignatureTTL, nil},
+		"slightly expired": {now.Add(-DefaultSignatureTTL - DefaultClockSkew/2),
+			DefaultSignatureTTL, nil},
+		"max TTL": {now, MaxSignatureTTL, nil},
+		"future": {now.Add(2 * DefaultClockSkew), DefaultSignatureTTL,
+			ErrSignatureNotYetValid},
+		"expired": {now.Add(-DefaultSignatureTTL - DefaultClockSkew), DefaultSignatureTTL,
+			ErrSignatureExpired},
+		"TTL too long": {now, MaxSignatureTTL + time.Second, ErrSignatureTTLTooLong},
+	}
+	for desc, c := range cases {
+		claims := newSignatureClaims(hash, c.issued, c.ttl, nonce)
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		encToken, err := token.SignedString(peerID.Key())
+		assert.Nil(t, err)
+		err = verifier.Verify(encToken, &peerID.Key().PublicKey, message)
+		assert.Equal(t, c.expected, err, desc)
+	}
+}
+
 func TestTestNoOpSigner_Sign(t *testing.T) {
 	s := &TestNoOpSigner{}
 	token, err := s.Sign(nil)
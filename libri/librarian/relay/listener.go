@@ -0,0 +1,194 @@
+package relay
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"go.uber.org/zap"
+)
+
+const logRelayAddr = "relay_addr"
+
+// ErrListenerClosed indicates when Accept is called on a closed listener.
+var ErrListenerClosed = errors.New("relay listener closed")
+
+type listener struct {
+	relayAddr *net.TCPAddr
+	relayID   id.ID
+	selfID    ecid.ID
+	params    *Parameters
+	logger    *zap.Logger
+	accepted  chan net.Conn
+	done      chan struct{}
+	idle      map[net.Conn]struct{}
+	closed    bool
+	mu        sync.Mutex
+}
+
+// NewListener returns a net.Listener that accepts connections clients make through the relay at
+// the given address. It keeps params.MaxIdleConns connections registered with the relay under
+// the given peer ID, so e.g. a gRPC server can serve relayed clients by also serving on the
+// listener. When relayID isn't nil, it only registers with a relay presenting that peer ID, which
+// keeps another relay at the address from forwarding the registrations to it.
+func NewListener(relayAddr *net.TCPAddr, relayID id.ID, selfID ecid.ID, params *Parameters,
+	logger *zap.Logger) net.Listener {
+	l := &listener{
+		relayAddr: relayAddr,
+		relayID:   relayID,
+		selfID:    selfID,
+		params:    params,
+		logger:    logger.With(zap.Stringer(logRelayAddr, relayAddr)),
+		accepted:  make(chan net.Conn),
+		done:      make(chan struct{}),
+		idle:      make(map[net.Conn]struct{}),
+	}
+	for c := uint(0); c < params.MaxIdleConns; c++ {
+		go l.maintain()
+	}
+	return l
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.accepted:
+		return conn, nil
+	case <-l.done:
+		return nil, ErrListenerClosed
+	}
+}
+
+func (l *listener) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	close(l.done)
+	for conn := range l.idle {
+		_ = conn.Close()
+	}
+	return nil
+}
+
+func (l *listener) Addr() net.Addr {
+	return l.relayAddr
+}
+
+// maintain keeps one connection registered with the relay, handing it to Accept once a client
+// connects through it and then registering another.
+func (l *listener) maintain() {
+	for {
+		conn, err := l.register()
+		if err != nil {
+			l.logger.Debug("failed to register relay connection", zap.Error(err))
+			select {
+			case <-time.After(l.params.RetryInterval):
+				continue
+			case <-l.done:
+				return
+			}
+		}
+		err = l.awaitActivation(conn)
+		l.untrack(conn)
+		if err != nil {
+			// relay closed the connection or it has idled for too long
+			_ = conn.Close()
+			if l.isClosed() {
+				return
+			}
+			continue
+		}
+		select {
+		case l.accepted <- conn:
+		case <-l.done:
+			_ = conn.Close()
+			return
+		}
+	}
+}
+
+func (l *listener) register() (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", l.relayAddr.String(), l.params.HandshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if !l.track(conn) {
+		return nil, closeWith(conn, ErrListenerClosed)
+	}
+	if err := conn.SetDeadline(time.Now().Add(l.params.HandshakeTimeout)); err != nil {
+		return nil, l.untrackWith(conn, err)
+	}
+	if _, err := conn.Write([]byte{registerMsg}); err != nil {
+		return nil, l.untrackWith(conn, err)
+	}
+	buf := make([]byte, nonceLength+peerIDLength)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return nil, l.untrackWith(conn, err)
+	}
+	nonce, relayID := buf[:nonceLength], id.FromBytes(buf[nonceLength:])
+	if l.relayID != nil && l.relayID.Cmp(relayID) != 0 {
+		return nil, l.untrackWith(conn, ErrUnexpectedRelay)
+	}
+	msg, err := signNonce(l.selfID, relayID, nonce)
+	if err != nil {
+		return nil, l.untrackWith(conn, err)
+	}
+	if _, err := conn.Write(msg); err != nil {
+		return nil, l.untrackWith(conn, err)
+	}
+	if err := readStatus(conn); err != nil {
+		return nil, l.untrackWith(conn, err)
+	}
+	return conn, nil
+}
+
+// awaitActivation waits for the relay to activate the idle connection and acknowledges it.
+func (l *listener) awaitActivation(conn net.Conn) error {
+	if err := conn.SetDeadline(time.Now().Add(l.params.IdleTimeout)); err != nil {
+		return err
+	}
+	msg := make([]byte, 1)
+	if _, err := io.ReadFull(conn, msg); err != nil {
+		return err
+	}
+	if msg[0] != activateMsg {
+		return errUnexpectedMsg
+	}
+	if _, err := conn.Write([]byte{activateMsg}); err != nil {
+		return err
+	}
+	return conn.SetDeadline(time.Time{})
+}
+
+func (l *listener) track(conn net.Conn) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return false
+	}
+	l.idle[conn] = struct{}{}
+	return true
+}
+
+func (l *listener) untrack(conn net.Conn) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.idle, conn)
+}
+
+func (l *listener) untrackWith(conn net.Conn, err error) error {
+	l.untrack(conn)
+	return closeWith(conn, err)
+}
+
+func (l *listener) isClosed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
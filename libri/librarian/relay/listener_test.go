@@ -0,0 +1,62 @@
+package relay
+
+import (
+	"math/rand"
+	"net"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestListener_Close(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := newTestParameters()
+	relayAddr, stop := startTestServer(t, params)
+	defer stop()
+
+	lis := NewListener(relayAddr, testRelayID, ecid.NewPseudoRandom(rng), params, zap.NewNop())
+	assert.Equal(t, relayAddr, lis.Addr())
+	assert.Nil(t, lis.Close())
+	assert.Nil(t, lis.Close())
+
+	conn, err := lis.Accept()
+	assert.Equal(t, ErrListenerClosed, err)
+	assert.Nil(t, conn)
+}
+
+func TestListener_register_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := newTestParameters()
+
+	// no relay listening
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	relayAddr := lis.Addr().(*net.TCPAddr)
+	assert.Nil(t, lis.Close())
+	l := &listener{relayAddr: relayAddr, selfID: ecid.NewPseudoRandom(rng), params: params,
+		idle: make(map[net.Conn]struct{})}
+	conn, err := l.register()
+	assert.NotNil(t, err)
+	assert.Nil(t, conn)
+
+	// listener already closed
+	relayAddr, stop := startTestServer(t, params)
+	defer stop()
+	l = &listener{relayAddr: relayAddr, selfID: ecid.NewPseudoRandom(rng), params: params,
+		idle: make(map[net.Conn]struct{}), closed: true}
+	conn, err = l.register()
+	assert.Equal(t, ErrListenerClosed, err)
+	assert.Nil(t, conn)
+	assert.Empty(t, l.idle)
+
+	// relay has different peer ID than expected
+	l = &listener{relayAddr: relayAddr, relayID: id.FromInt64(2),
+		selfID: ecid.NewPseudoRandom(rng), params: params, idle: make(map[net.Conn]struct{})}
+	conn, err = l.register()
+	assert.Equal(t, ErrUnexpectedRelay, err)
+	assert.Nil(t, conn)
+	assert.Empty(t, l.idle)
+}
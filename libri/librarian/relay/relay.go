@@ -0,0 +1,237 @@
+// Package relay lets librarians that are not directly reachable (e.g., behind a NAT) accept
+// connections through a relay librarian.
+//
+// An unreachable librarian keeps a few idle outbound connections registered with the relay,
+// proving its peer ID by signing a nonce from the relay along with the relay's peer ID, so the
+// signature can't be forwarded to register with another relay. When a client asks the relay to connect
+// to that peer ID, the relay activates one of the idle connections and splices it to the client's
+// connection, after which the client and the librarian speak normal gRPC over it.
+package relay
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+)
+
+const (
+	// DefaultMaxPeers is the default max number of peers a relay server accepts registrations
+	// from.
+	DefaultMaxPeers = 256
+
+	// DefaultMaxIdleConns is the default max number of idle connections a relay server holds
+	// per peer and the number a listener keeps registered.
+	DefaultMaxIdleConns = 4
+
+	// DefaultHandshakeTimeout is the default timeout for the registration and connection
+	// handshakes.
+	DefaultHandshakeTimeout = 5 * time.Second
+
+	// DefaultConnectTimeout is the default time a relay server waits for an idle connection to
+	// the target peer to become available.
+	DefaultConnectTimeout = 5 * time.Second
+
+	// DefaultIdleTimeout is the default time after which a listener replaces an idle connection,
+	// which keeps NAT mappings from expiring.
+	DefaultIdleTimeout = 4 * time.Minute
+
+	// DefaultRetryInterval is the default time a listener waits after failing to register a
+	// connection before trying again.
+	DefaultRetryInterval = 5 * time.Second
+
+	// targetSep separates the peer ID from the relay address in a relayed dial target.
+	targetSep = "@"
+
+	nonceLength      = 32
+	maxSigLength     = 72
+	pubKeyLength     = 33
+	peerIDLength     = id.Length
+	registerMsg      = byte(1)
+	connectMsg       = byte(2)
+	activateMsg      = byte(3)
+	statusOK         = byte(0)
+	statusUnknown    = byte(1)
+	statusUnverified = byte(2)
+	statusFull       = byte(3)
+	statusBadRequest = byte(4)
+)
+
+var (
+	// ErrUnknownPeer indicates when the relay has no connections registered for a peer.
+	ErrUnknownPeer = errors.New("relay has no connections to peer")
+
+	// ErrUnverified indicates when the relay could not verify a peer's registration signature.
+	ErrUnverified = errors.New("relay could not verify registration")
+
+	// ErrRelayFull indicates when the relay is not accepting any more registrations.
+	ErrRelayFull = errors.New("relay is full")
+
+	// ErrBadRequest indicates when the relay did not understand a request.
+	ErrBadRequest = errors.New("relay received bad request")
+
+	// ErrUnexpectedRelay indicates when the relay presents a different peer ID than expected.
+	ErrUnexpectedRelay = errors.New("relay has unexpected peer ID")
+
+	errUnexpectedStatus = errors.New("unexpected relay status")
+	errUnexpectedMsg    = errors.New("unexpected relay message")
+
+	statusErrs = map[byte]error{
+		statusUnknown:    ErrUnknownPeer,
+		statusUnverified: ErrUnverified,
+		statusFull:       ErrRelayFull,
+		statusBadRequest: ErrBadRequest,
+	}
+)
+
+// Parameters define the limits and timeouts of relay servers and listeners.
+type Parameters struct {
+	MaxPeers         uint
+	MaxIdleConns     uint
+	HandshakeTimeout time.Duration
+	ConnectTimeout   time.Duration
+	IdleTimeout      time.Duration
+	RetryInterval    time.Duration
+}
+
+// NewDefaultParameters returns a *Parameters object with default values.
+func NewDefaultParameters() *Parameters {
+	return &Parameters{
+		MaxPeers:         DefaultMaxPeers,
+		MaxIdleConns:     DefaultMaxIdleConns,
+		HandshakeTimeout: DefaultHandshakeTimeout,
+		ConnectTimeout:   DefaultConnectTimeout,
+		IdleTimeout:      DefaultIdleTimeout,
+		RetryInterval:    DefaultRetryInterval,
+	}
+}
+
+// Target returns the dial target for reaching the peer with the given ID through the relay at
+// the given address.
+func Target(peerID id.ID, relayAddress string) string {
+	return peerID.String() + targetSep + relayAddress
+}
+
+// ParseTarget returns the peer ID and relay address of a relayed dial target and whether the
+// target is relayed at all.
+func ParseTarget(target string) (id.ID, string, bool) {
+	i := strings.Index(target, targetSep)
+	if i < 0 {
+		return nil, "", false
+	}
+	peerID, err := id.FromString(target[:i])
+	if err != nil {
+		return nil, "", false
+	}
+	return peerID, target[i+len(targetSep):], true
+}
+
+// Dial connects to the target address, going through the relay for relayed targets (see
+// Target). Its signature matches that expected by grpc.WithDialer.
+func Dial(target string, timeout time.Duration) (net.Conn, error) {
+	peerID, relayAddress, relayed := ParseTarget(target)
+	if !relayed {
+		return net.DialTimeout("tcp", target, timeout)
+	}
+	conn, err := net.DialTimeout("tcp", relayAddress, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, closeWith(conn, err)
+		}
+	}
+	msg := append([]byte{connectMsg}, peerID.Bytes()...)
+	if _, err := conn.Write(msg); err != nil {
+		return nil, closeWith(conn, err)
+	}
+	if err := readStatus(conn); err != nil {
+		return nil, closeWith(conn, err)
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, closeWith(conn, err)
+	}
+	return conn, nil
+}
+
+// signNonce returns the compressed public key of the peer followed by its length-prefixed
+// signature of the relay ID and nonce.
+func signNonce(peerID ecid.ID, relayID id.ID, nonce []byte) ([]byte, error) {
+	hash := hashNonce(relayID, nonce)
+	sig, err := ecid.Sign(peerID.Key(), hash[:])
+	if err != nil {
+		return nil, err
+	}
+	msg := append(peerID.PublicKeyBytes(), byte(len(sig)))
+	return append(msg, sig...), nil
+}
+
+// readVerifiedPeerID reads the message written by signNonce and returns the ID of the peer that
+// signed the relay ID and nonce.
+func readVerifiedPeerID(r io.Reader, relayID id.ID, nonce []byte) (id.ID, error) {
+	buf := make([]byte, pubKeyLength+1)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	sigLen := int(buf[pubKeyLength])
+	if sigLen > maxSigLength {
+		return nil, ErrUnverified
+	}
+	sig := make([]byte, sigLen)
+	if _, err := io.ReadFull(r, sig); err != nil {
+		return nil, err
+	}
+	pubKey, err := ecid.FromPublicKeyBytes(buf[:pubKeyLength])
+	if err != nil {
+		return nil, ErrUnverified
+	}
+	hash := hashNonce(relayID, nonce)
+	if !ecid.Verify(pubKey, hash[:], sig) {
+		return nil, ErrUnverified
+	}
+	return id.FromPublicKey(pubKey), nil
+}
+
+func hashNonce(relayID id.ID, nonce []byte) [sha256.Size]byte {
+	return sha256.Sum256(append(relayID.Bytes(), nonce...))
+}
+
+func newNonce() ([]byte, error) {
+	nonce := make([]byte, nonceLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return nonce, nil
+}
+
+func readStatus(r io.Reader) error {
+	buf := make([]byte, 1)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return err
+	}
+	if buf[0] == statusOK {
+		return nil
+	}
+	if err, in := statusErrs[buf[0]]; in {
+		return err
+	}
+	return fmt.Errorf("%s: %d", errUnexpectedStatus, buf[0])
+}
+
+func writeStatus(w io.Writer, status byte) error {
+	_, err := w.Write([]byte{status})
+	return err
+}
+
+func closeWith(conn net.Conn, err error) error {
+	_ = conn.Close()
+	return err
+}
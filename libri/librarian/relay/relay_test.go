@@ -0,0 +1,120 @@
+package relay
+
+import (
+	"bytes"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTarget_ParseTarget(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := id.NewPseudoRandom(rng)
+	target := Target(peerID, "1.2.3.4:20100")
+
+	parsedID, relayAddress, relayed := ParseTarget(target)
+	assert.True(t, relayed)
+	assert.Equal(t, peerID, parsedID)
+	assert.Equal(t, "1.2.3.4:20100", relayAddress)
+
+	for _, target := range []string{"1.2.3.4:20100", "localhost:20100", "notHex@1.2.3.4:20100"} {
+		parsedID, relayAddress, relayed = ParseTarget(target)
+		assert.False(t, relayed)
+		assert.Nil(t, parsedID)
+		assert.Empty(t, relayAddress)
+	}
+}
+
+func TestDial_direct(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, lis.Close()) }()
+	go func() {
+		conn, err2 := lis.Accept()
+		assert.Nil(t, err2)
+		_, err2 = conn.Write([]byte("hello"))
+		assert.Nil(t, err2)
+		assert.Nil(t, conn.Close())
+	}()
+
+	conn, err := Dial(lis.Addr().String(), time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", readAll(t, conn))
+}
+
+func TestDial_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := id.NewPseudoRandom(rng)
+
+	// bad relay address
+	conn, err := Dial(Target(peerID, "bad address"), time.Second)
+	assert.NotNil(t, err)
+	assert.Nil(t, conn)
+
+	// relay responds with error status
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, lis.Close()) }()
+	go func() {
+		relayConn, err2 := lis.Accept()
+		assert.Nil(t, err2)
+		msg := make([]byte, 1+id.Length)
+		_, err2 = relayConn.Read(msg)
+		assert.Nil(t, err2)
+		assert.Nil(t, writeStatus(relayConn, statusUnknown))
+	}()
+	conn, err = Dial(Target(peerID, lis.Addr().String()), time.Second)
+	assert.Equal(t, ErrUnknownPeer, err)
+	assert.Nil(t, conn)
+}
+
+func TestSignNonce_readVerifiedPeerID(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := ecid.NewPseudoRandom(rng)
+	nonce, err := newNonce()
+	assert.Nil(t, err)
+
+	msg, err := signNonce(peerID, testRelayID, nonce)
+	assert.Nil(t, err)
+	verifiedID, err := readVerifiedPeerID(bytes.NewReader(msg), testRelayID, nonce)
+	assert.Nil(t, err)
+	assert.Equal(t, peerID.ID(), verifiedID)
+
+	// different nonce
+	otherNonce, err := newNonce()
+	assert.Nil(t, err)
+	verifiedID, err = readVerifiedPeerID(bytes.NewReader(msg), testRelayID, otherNonce)
+	assert.Equal(t, ErrUnverified, err)
+	assert.Nil(t, verifiedID)
+
+	// different relay
+	verifiedID, err = readVerifiedPeerID(bytes.NewReader(msg), id.FromInt64(2), nonce)
+	assert.Equal(t, ErrUnverified, err)
+	assert.Nil(t, verifiedID)
+
+	// truncated message
+	verifiedID, err = readVerifiedPeerID(bytes.NewReader(msg[:10]), testRelayID, nonce)
+	assert.NotNil(t, err)
+	assert.Nil(t, verifiedID)
+}
+
+func TestReadStatus(t *testing.T) {
+	assert.Nil(t, readStatus(bytes.NewReader([]byte{statusOK})))
+	for status, expected := range statusErrs {
+		assert.Equal(t, expected, readStatus(bytes.NewReader([]byte{status})))
+	}
+	assert.NotNil(t, readStatus(bytes.NewReader([]byte{255})))
+	assert.NotNil(t, readStatus(bytes.NewReader([]byte{})))
+}
+
+func readAll(t *testing.T, conn net.Conn) string {
+	var buf bytes.Buffer
+	_, err := buf.ReadFrom(conn)
+	assert.Nil(t, err)
+	return buf.String()
+}
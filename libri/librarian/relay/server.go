@@ -0,0 +1,259 @@
+package relay
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"go.uber.org/zap"
+)
+
+const (
+	logPeerID     = "peer_id"
+	logRemoteAddr = "remote_addr"
+)
+
+// Server relays connections from clients to peers that have registered idle connections with
+// it.
+type Server interface {
+	// Serve accepts and handles connections from the listener until it is closed.
+	Serve(lis net.Listener) error
+
+	// Stop closes the listener and all idle registered connections.
+	Stop()
+}
+
+type server struct {
+	relayID id.ID
+	params  *Parameters
+	logger  *zap.Logger
+	lis     net.Listener
+	idle    map[string]*idleConns
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+// idleConns are the idle connections registered by a peer.
+type idleConns struct {
+	conns chan net.Conn
+
+	// registered is when the peer last registered a connection
+	registered time.Time
+}
+
+// NewServer returns a new relay Server for the relay with the given peer ID, which peers sign
+// along with the nonce when registering.
+func NewServer(relayID id.ID, params *Parameters, logger *zap.Logger) Server {
+	return &server{
+		relayID: relayID,
+		params:  params,
+		logger:  logger,
+		idle:    make(map[string]*idleConns),
+		now:     time.Now,
+	}
+}
+
+func (s *server) Serve(lis net.Listener) error {
+	s.mu.Lock()
+	s.lis = lis
+	s.mu.Unlock()
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *server) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lis != nil {
+		_ = s.lis.Close()
+	}
+	for _, ic := range s.idle {
+		closeIdle(ic.conns, len(ic.conns))
+	}
+}
+
+func (s *server) handle(conn net.Conn) {
+	logger := s.logger.With(zap.Stringer(logRemoteAddr, conn.RemoteAddr()))
+	if err := conn.SetDeadline(time.Now().Add(s.params.HandshakeTimeout)); err != nil {
+		_ = conn.Close()
+		return
+	}
+	msgType := make([]byte, 1)
+	if _, err := io.ReadFull(conn, msgType); err != nil {
+		_ = conn.Close()
+		return
+	}
+	switch msgType[0] {
+	case registerMsg:
+		s.register(conn, logger)
+	case connectMsg:
+		s.connect(conn, logger)
+	default:
+		_ = writeStatus(conn, statusBadRequest)
+		_ = conn.Close()
+	}
+}
+
+func (s *server) register(conn net.Conn, logger *zap.Logger) {
+	nonce, err := newNonce()
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	if _, err = conn.Write(append(nonce, s.relayID.Bytes()...)); err != nil {
+		_ = conn.Close()
+		return
+	}
+	peerID, err := readVerifiedPeerID(conn, s.relayID, nonce)
+	if err != nil {
+		logger.Debug("rejected relay registration", zap.Error(err))
+		_ = writeStatus(conn, statusUnverified)
+		_ = conn.Close()
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ic, in := s.idle[peerID.String()]
+	if !in {
+		if uint(len(s.idle)) >= s.params.MaxPeers {
+			s.evictIdle()
+		}
+		if uint(len(s.idle)) >= s.params.MaxPeers {
+			_ = writeStatus(conn, statusFull)
+			_ = conn.Close()
+			return
+		}
+		ic = &idleConns{conns: make(chan net.Conn, s.params.MaxIdleConns)}
+		s.idle[peerID.String()] = ic
+	}
+	ic.registered = s.now()
+	conns := ic.conns
+	if len(conns) == cap(conns) {
+		// replace oldest idle connection, since the peer's newest ones are the most likely to
+		// still be open
+		closeIdle(conns, 1)
+	}
+	if err := writeStatus(conn, statusOK); err != nil {
+		_ = conn.Close()
+		return
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		_ = conn.Close()
+		return
+	}
+	conns <- conn
+	logger.Debug("registered relay connection", zap.Stringer(logPeerID, peerID))
+}
+
+func (s *server) connect(conn net.Conn, logger *zap.Logger) {
+	buf := make([]byte, peerIDLength)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		_ = conn.Close()
+		return
+	}
+	peerID := id.FromBytes(buf)
+	s.mu.Lock()
+	ic, in := s.idle[peerID.String()]
+	s.mu.Unlock()
+	if !in {
+		_ = writeStatus(conn, statusUnknown)
+		_ = conn.Close()
+		return
+	}
+	conns := ic.conns
+	timeout := time.After(s.params.ConnectTimeout)
+	for {
+		var peerConn net.Conn
+		select {
+		case peerConn = <-conns:
+		case <-timeout:
+			_ = writeStatus(conn, statusUnknown)
+			_ = conn.Close()
+			return
+		}
+		if err := s.activate(peerConn); err != nil {
+			// peer probably closed the idle connection, so try the next one
+			_ = peerConn.Close()
+			continue
+		}
+		if err := writeStatus(conn, statusOK); err != nil {
+			_ = peerConn.Close()
+			_ = conn.Close()
+			return
+		}
+		if err := conn.SetDeadline(time.Time{}); err != nil {
+			_ = peerConn.Close()
+			_ = conn.Close()
+			return
+		}
+		logger.Debug("relaying connection", zap.Stringer(logPeerID, peerID))
+		splice(conn, peerConn)
+		return
+	}
+}
+
+// evictIdle removes the peers without any idle connections left and those that haven't registered
+// a connection within the idle timeout, after which their listeners have replaced (and so
+// closed) all the connections the relay holds for them. The caller must hold the lock.
+func (s *server) evictIdle() {
+	stale := s.now().Add(-s.params.IdleTimeout)
+	for peerID, ic := range s.idle {
+		if len(ic.conns) == 0 || ic.registered.Before(stale) {
+			closeIdle(ic.conns, len(ic.conns))
+			delete(s.idle, peerID)
+		}
+	}
+}
+
+// activate tells the peer an idle connection is about to carry a client's traffic and waits for
+// it to acknowledge.
+func (s *server) activate(peerConn net.Conn) error {
+	if err := peerConn.SetDeadline(time.Now().Add(s.params.HandshakeTimeout)); err != nil {
+		return err
+	}
+	if _, err := peerConn.Write([]byte{activateMsg}); err != nil {
+		return err
+	}
+	ack := make([]byte, 1)
+	if _, err := io.ReadFull(peerConn, ack); err != nil {
+		return err
+	}
+	if ack[0] != activateMsg {
+		return errUnexpectedMsg
+	}
+	return peerConn.SetDeadline(time.Time{})
+}
+
+// splice copies traffic between the two connections until either closes.
+func splice(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	cp := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		done <- struct{}{}
+	}
+	go cp(a, b)
+	go cp(b, a)
+	<-done
+	_ = a.Close()
+	_ = b.Close()
+	<-done
+}
+
+// closeIdle closes up to n idle connections, stopping early if connect takes the rest.
+func closeIdle(conns chan net.Conn, n int) {
+	for c := 0; c < n; c++ {
+		select {
+		case conn := <-conns:
+			_ = conn.Close()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package relay
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+var testRelayID = id.FromInt64(1)
+
+func TestServer_relay(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := newTestParameters()
+	relayAddr, stop := startTestServer(t, params)
+	defer stop()
+
+	selfID := ecid.NewPseudoRandom(rng)
+	lis := NewListener(relayAddr, testRelayID, selfID, params, zap.NewNop())
+	defer func() { assert.Nil(t, lis.Close()) }()
+	go echo(lis)
+
+	target := Target(selfID.ID(), relayAddr.String())
+	for c := 0; c < 2*int(params.MaxIdleConns); c++ {
+		conn := dialEventually(t, target)
+		_, err := conn.Write([]byte("hello"))
+		assert.Nil(t, err)
+		buf := make([]byte, 5)
+		_, err = io.ReadFull(conn, buf)
+		assert.Nil(t, err)
+		assert.Equal(t, "hello", string(buf))
+		assert.Nil(t, conn.Close())
+	}
+}
+
+func TestServer_relay_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := newTestParameters()
+	relayAddr, stop := startTestServer(t, params)
+	defer stop()
+
+	// no peer registered
+	conn, err := Dial(Target(id.NewPseudoRandom(rng), relayAddr.String()), time.Second)
+	assert.Equal(t, ErrUnknownPeer, err)
+	assert.Nil(t, conn)
+
+	// bad request
+	conn, err = net.Dial("tcp", relayAddr.String())
+	assert.Nil(t, err)
+	_, err = conn.Write([]byte{255})
+	assert.Nil(t, err)
+	assert.Equal(t, ErrBadRequest, readStatus(conn))
+	assert.Nil(t, conn.Close())
+
+	// unverified registrations, signing a different nonce or for a different relay
+	signers := map[string]func(nonce []byte) ([]byte, error){
+		"other nonce": func(nonce []byte) ([]byte, error) {
+			return signNonce(ecid.NewPseudoRandom(rng), testRelayID, []byte("other nonce"))
+		},
+		"other relay": func(nonce []byte) ([]byte, error) {
+			return signNonce(ecid.NewPseudoRandom(rng), id.FromInt64(2), nonce)
+		},
+	}
+	for desc, sign := range signers {
+		conn, err = net.Dial("tcp", relayAddr.String())
+		assert.Nil(t, err, desc)
+		_, err = conn.Write([]byte{registerMsg})
+		assert.Nil(t, err, desc)
+		buf := make([]byte, nonceLength+peerIDLength)
+		_, err = io.ReadFull(conn, buf)
+		assert.Nil(t, err, desc)
+		assert.Equal(t, testRelayID, id.FromBytes(buf[nonceLength:]), desc)
+		msg, err := sign(buf[:nonceLength])
+		assert.Nil(t, err, desc)
+		_, err = conn.Write(msg)
+		assert.Nil(t, err, desc)
+		assert.Equal(t, ErrUnverified, readStatus(conn), desc)
+		assert.Nil(t, conn.Close(), desc)
+	}
+}
+
+func TestServer_register_full(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := newTestParameters()
+	params.MaxPeers = 1
+	relayAddr, stop := startTestServer(t, params)
+	defer stop()
+
+	l1 := &listener{relayAddr: relayAddr, selfID: ecid.NewPseudoRandom(rng), params: params,
+		idle: make(map[net.Conn]struct{})}
+	conn1, err := l1.register()
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, conn1.Close()) }()
+
+	l2 := &listener{relayAddr: relayAddr, selfID: ecid.NewPseudoRandom(rng), params: params,
+		idle: make(map[net.Conn]struct{})}
+	conn2, err := l2.register()
+	assert.Equal(t, ErrRelayFull, err)
+	assert.Nil(t, conn2)
+}
+
+func TestServer_register_evict(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := newTestParameters()
+	params.MaxPeers = 1
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	s := NewServer(testRelayID, params, zap.NewNop()).(*server)
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	relayAddr := lis.Addr().(*net.TCPAddr)
+	newListener := func() *listener {
+		return &listener{relayAddr: relayAddr, selfID: ecid.NewPseudoRandom(rng),
+			params: params, idle: make(map[net.Conn]struct{})}
+	}
+
+	// check peer whose connections have all been taken is evicted
+	l1 := newListener()
+	conn1, err := l1.register()
+	assert.Nil(t, err)
+	activated := make(chan error, 1)
+	go func() { activated <- l1.awaitActivation(conn1) }()
+	conn, err := Dial(Target(l1.selfID.ID(), relayAddr.String()), time.Second)
+	assert.Nil(t, err)
+	assert.Nil(t, <-activated)
+	assert.Nil(t, conn.Close())
+	assert.Nil(t, conn1.Close())
+	conn2, err := newListener().register()
+	assert.Nil(t, err)
+
+	// check peer that hasn't registered within the idle timeout is evicted, closing its
+	// remaining idle connections
+	s.mu.Lock()
+	s.now = func() time.Time { return time.Now().Add(2 * params.IdleTimeout) }
+	s.mu.Unlock()
+	conn3, err := newListener().register()
+	assert.Nil(t, err)
+	defer func() { assert.Nil(t, conn3.Close()) }()
+	_, err = conn2.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+	assert.Nil(t, conn2.Close())
+	s.mu.Lock()
+	assert.Equal(t, 1, len(s.idle))
+	s.mu.Unlock()
+}
+
+func newTestParameters() *Parameters {
+	params := NewDefaultParameters()
+	params.MaxIdleConns = 2
+	params.HandshakeTimeout = time.Second
+	params.ConnectTimeout = time.Second
+	params.RetryInterval = 10 * time.Millisecond
+	return params
+}
+
+func startTestServer(t *testing.T, params *Parameters) (*net.TCPAddr, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	s := NewServer(testRelayID, params, zap.NewNop())
+	go func() { _ = s.Serve(lis) }()
+	return lis.Addr().(*net.TCPAddr), s.Stop
+}
+
+// dialEventually dials the target until the listener has registered its connections.
+func dialEventually(t *testing.T, target string) net.Conn {
+	var conn net.Conn
+	var err error
+	for c := 0; c < 100; c++ {
+		if conn, err = Dial(target, time.Second); err == nil {
+			return conn
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Nil(t, err)
+	return nil
+}
+
+func echo(lis net.Listener) {
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			_, _ = io.Copy(conn, conn)
+			_ = conn.Close()
+		}()
+	}
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/comm"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/routing"
+)
+
+var (
+	errCertificatesWithoutTrustList = errors.New("requiring certificates also requires a trust " +
+		"list file with the allowed organizations")
+	errUntrustedCertificateOrg = errors.New("certificate issued by organization not in trust " +
+		"list")
+	errRevokedCertificate      = errors.New("certificate has been revoked")
+	errCertificatePeerMismatch = errors.New("certificate public key does not match peer ID")
+)
+
+// certChecker checks the organization certificates presented with requests.
+type certChecker struct {
+	verifier client.CertificateVerifier
+	trust    comm.TrustKnower
+	require  bool
+}
+
+// newCertChecker returns a certChecker that verifies any certificates presented with requests
+// and, if require is true, refuses requests without a valid certificate from an organization in
+// the trust list.
+func newCertChecker(
+	verifier client.CertificateVerifier, trust comm.TrustKnower, require bool,
+) (*certChecker, error) {
+	if require && trust == nil {
+		return nil, errCertificatesWithoutTrustList
+	}
+	return &certChecker{
+		verifier: verifier,
+		trust:    trust,
+		require:  require,
+	}, nil
+}
+
+// Check verifies the certificate in the request metadata, if there is one or it is required. It
+// returns the ID of the organization that issued the certificate or nil if there isn't one.
+func (c *certChecker) Check(meta *api.RequestMetadata) (id.ID, error) {
+	return c.check(meta.Cert, meta.PubKey)
+}
+
+// CheckPeer verifies the certificate a peer advertises, if there is one or it is required. It
+// returns the ID of the organization that issued the certificate or nil if there isn't one.
+func (c *certChecker) CheckPeer(p peer.Peer) (id.ID, error) {
+	cert := p.Certificate()
+	if cert != nil {
+		certPeerID, err := newIDFromPublicKeyBytes(cert.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		if certPeerID.Cmp(p.ID()) != 0 {
+			return nil, errCertificatePeerMismatch
+		}
+		return c.check(cert, cert.PubKey)
+	}
+	return c.check(nil, nil)
+}
+
+func (c *certChecker) check(cert *api.PeerCertificate, pubKey []byte) (id.ID, error) {
+	if cert == nil && !c.require {
+		return nil, nil
+	}
+	if err := c.verifier.Verify(cert, pubKey); err != nil {
+		return nil, err
+	}
+	orgID, err := newIDFromPublicKeyBytes(cert.OrgPubKey)
+	if err != nil {
+		return nil, err
+	}
+	if c.trust == nil {
+		return orgID, nil
+	}
+	if c.trust.Revoked(id.FromBytes(cert.Serial)) {
+		return nil, errRevokedCertificate
+	}
+	if c.require && !c.trust.KnowOrg(orgID) {
+		return nil, errUntrustedCertificateOrg
+	}
+	return orgID, nil
+}
+
+// certAdmitter is a routing.PromAdmitter that only admits peers to the routing table whose
+// advertised certificates pass the certChecker, however the peers were learned about.
+type certAdmitter struct {
+	routing.PromAdmitter
+	certs *certChecker
+	orgs  comm.PeerOrgs
+}
+
+// newCertAdmitter returns a routing.PromAdmitter that checks the certificates of new peers before
+// applying the rules of the given admitter, recording the organizations of certified peers in
+// orgs.
+func newCertAdmitter(
+	admitter routing.PromAdmitter, certs *certChecker, orgs comm.PeerOrgs,
+) routing.PromAdmitter {
+	return &certAdmitter{
+		PromAdmitter: admitter,
+		certs:        certs,
+		orgs:         orgs,
+	}
+}
+
+func (a *certAdmitter) AdmitBucket(new peer.Peer, bucketPeers []peer.Peer) error {
+	orgID, err := a.certs.CheckPeer(new)
+	if err != nil {
+		return err
+	}
+	if orgID != nil {
+		// peer has proven its organization via the verified certificate, which the bucket
+		// organization limit then applies to
+		a.orgs.SetOrg(new.ID(), orgID)
+	}
+	return a.PromAdmitter.AdmitBucket(new, bucketPeers)
+}
+
+// newPeerSigner returns the Signer for the peer's requests, which presents the certificate in the
+// given file if it isn't empty.
+func newPeerSigner(
+	peerID ecid.ID, certFile string, verifier client.CertificateVerifier,
+) (client.Signer, error) {
+	signer := client.NewECDSASigner(peerID.Key())
+	if certFile == "" {
+		return signer, nil
+	}
+	cert, err := client.ReadCertificate(certFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// fail fast rather than have other peers refuse all our requests
+	if err := verifier.Verify(cert, peerID.PublicKeyBytes()); err != nil {
+		return nil, err
+	}
+	return client.NewCertifiedSigner(signer, cert), nil
+}
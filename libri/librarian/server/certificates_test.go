@@ -0,0 +1,223 @@
+package server
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/comm"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/routing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCertChecker(t *testing.T) {
+	verifier := client.NewCertificateVerifier(client.DefaultClockSkew)
+	trust := &fixedTrustKnower{}
+
+	c, err := newCertChecker(verifier, trust, true)
+	assert.Nil(t, err)
+	assert.NotNil(t, c)
+
+	c, err = newCertChecker(verifier, nil, false)
+	assert.Nil(t, err)
+	assert.NotNil(t, c)
+
+	c, err = newCertChecker(verifier, nil, true)
+	assert.Equal(t, errCertificatesWithoutTrustList, err)
+	assert.Nil(t, c)
+}
+
+func TestCertChecker_Check(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := ecid.NewPseudoRandom(rng)
+	trustedOrgID, otherOrgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	verifier := client.NewCertificateVerifier(client.DefaultClockSkew)
+	newCert := func(orgID ecid.ID) *api.PeerCertificate {
+		cert, err := client.NewCertificate(orgID, peerID.PublicKeyBytes(),
+			client.DefaultCertificateTTL)
+		assert.Nil(t, err)
+		return cert
+	}
+	revokedCert := newCert(trustedOrgID)
+	trust := &fixedTrustKnower{
+		knownOrgs: map[string]struct{}{trustedOrgID.ID().String(): {}},
+		revoked:   map[string]struct{}{id.FromBytes(revokedCert.Serial).String(): {}},
+	}
+	required := &certChecker{verifier: verifier, trust: trust, require: true}
+	optional := &certChecker{verifier: verifier, trust: trust}
+	noTrust := &certChecker{verifier: verifier}
+
+	cases := map[string]struct {
+		c     *certChecker
+		cert  *api.PeerCertificate
+		orgID id.ID
+		err   error
+	}{
+		"required ok": {
+			c:     required,
+			cert:  newCert(trustedOrgID),
+			orgID: trustedOrgID.ID(),
+		},
+		"required missing": {
+			c:   required,
+			err: client.ErrMissingCertificate,
+		},
+		"required untrusted org": {
+			c:    required,
+			cert: newCert(otherOrgID),
+			err:  errUntrustedCertificateOrg,
+		},
+		"required revoked": {
+			c:    required,
+			cert: revokedCert,
+			err:  errRevokedCertificate,
+		},
+		"optional missing": {
+			c: optional,
+		},
+		"optional untrusted org": {
+			c:     optional,
+			cert:  newCert(otherOrgID),
+			orgID: otherOrgID.ID(),
+		},
+		"optional revoked": {
+			c:    optional,
+			cert: revokedCert,
+			err:  errRevokedCertificate,
+		},
+		"no trust list": {
+			c:     noTrust,
+			cert:  newCert(otherOrgID),
+			orgID: otherOrgID.ID(),
+		},
+	}
+	for desc, c := range cases {
+		meta := client.NewRequestMetadata(peerID, nil)
+		meta.Cert = c.cert
+		orgID, err := c.c.Check(meta)
+		assert.Equal(t, c.err, err, desc)
+		assert.Equal(t, c.orgID, orgID, desc)
+	}
+
+	// certificate for another peer
+	meta := client.NewRequestMetadata(ecid.NewPseudoRandom(rng), nil)
+	meta.Cert = newCert(trustedOrgID)
+	orgID, err := required.Check(meta)
+	assert.Equal(t, client.ErrCertificatePubKeyMismatch, err)
+	assert.Nil(t, orgID)
+}
+
+func TestCertChecker_CheckPeer(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	verifier := client.NewCertificateVerifier(client.DefaultClockSkew)
+	trust := &fixedTrustKnower{knownOrgs: map[string]struct{}{orgID.ID().String(): {}}}
+	required := &certChecker{verifier: verifier, trust: trust, require: true}
+	optional := &certChecker{verifier: verifier, trust: trust}
+	cert, err := client.NewCertificate(orgID, peerID.PublicKeyBytes(),
+		client.DefaultCertificateTTL)
+	assert.Nil(t, err)
+	addr := &net.TCPAddr{IP: net.ParseIP("1.2.3.4"), Port: 20100}
+
+	// certified peer
+	p := peer.NewCertified(peer.New(peerID.ID(), "peer", addr), cert)
+	certOrgID, err := required.CheckPeer(p)
+	assert.Nil(t, err)
+	assert.Equal(t, orgID.ID(), certOrgID)
+
+	// uncertified peer
+	p = peer.New(peerID.ID(), "peer", addr)
+	certOrgID, err = required.CheckPeer(p)
+	assert.Equal(t, client.ErrMissingCertificate, err)
+	assert.Nil(t, certOrgID)
+	certOrgID, err = optional.CheckPeer(p)
+	assert.Nil(t, err)
+	assert.Nil(t, certOrgID)
+
+	// peer advertising another peer's certificate
+	p = peer.NewCertified(peer.New(ecid.NewPseudoRandom(rng).ID(), "peer", addr), cert)
+	certOrgID, err = optional.CheckPeer(p)
+	assert.Equal(t, errCertificatePeerMismatch, err)
+	assert.Nil(t, certOrgID)
+
+	// invalid certificate
+	badCert := *cert
+	badCert.ExpiresAt++
+	p = peer.NewCertified(peer.New(peerID.ID(), "peer", addr), &badCert)
+	certOrgID, err = optional.CheckPeer(p)
+	assert.Equal(t, client.ErrInvalidCertificateSignature, err)
+	assert.Nil(t, certOrgID)
+}
+
+func TestCertAdmitter_AdmitBucket(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	verifier := client.NewCertificateVerifier(client.DefaultClockSkew)
+	trust := &fixedTrustKnower{knownOrgs: map[string]struct{}{orgID.ID().String(): {}}}
+	certs := &certChecker{verifier: verifier, trust: trust, require: true}
+	orgs, err := comm.NewPeerOrgs(comm.DefaultPeerOrgsSize)
+	assert.Nil(t, err)
+	a := newCertAdmitter(routing.NewAdmitter(routing.NewDefaultParameters(), orgs), certs, orgs)
+	cert, err := client.NewCertificate(orgID, peerID.PublicKeyBytes(),
+		client.DefaultCertificateTTL)
+	assert.Nil(t, err)
+	addr := &net.TCPAddr{IP: net.ParseIP("1.2.3.4"), Port: 20100}
+
+	// check peer without certificate is rejected
+	err = a.AdmitBucket(peer.New(peerID.ID(), "peer", addr), nil)
+	assert.Equal(t, client.ErrMissingCertificate, err)
+	_, in := orgs.GetOrg(peerID.ID())
+	assert.False(t, in)
+
+	// check certified peer is admitted and its organization recorded
+	err = a.AdmitBucket(peer.NewCertified(peer.New(peerID.ID(), "peer", addr), cert), nil)
+	assert.Nil(t, err)
+	certOrgID, in := orgs.GetOrg(peerID.ID())
+	assert.True(t, in)
+	assert.Equal(t, orgID.ID(), certOrgID)
+}
+
+func TestNewPeerSigner(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	verifier := client.NewCertificateVerifier(client.DefaultClockSkew)
+	dir, err := ioutil.TempDir("", "test-peer-signer")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+
+	// without certificate
+	signer, err := newPeerSigner(peerID, "", verifier)
+	assert.Nil(t, err)
+	_, isCertified := signer.(client.CertifiedSigner)
+	assert.False(t, isCertified)
+
+	// with certificate
+	cert, err := client.NewCertificate(orgID, peerID.PublicKeyBytes(),
+		client.DefaultCertificateTTL)
+	assert.Nil(t, err)
+	certFile := filepath.Join(dir, "peer.cert")
+	assert.Nil(t, client.WriteCertificate(certFile, cert))
+	signer, err = newPeerSigner(peerID, certFile, verifier)
+	assert.Nil(t, err)
+	certified, isCertified := signer.(client.CertifiedSigner)
+	assert.True(t, isCertified)
+	assert.Equal(t, cert, certified.Certificate())
+
+	// missing certificate file
+	signer, err = newPeerSigner(peerID, filepath.Join(dir, "other.cert"), verifier)
+	assert.NotNil(t, err)
+	assert.Nil(t, signer)
+
+	// certificate for another peer
+	signer, err = newPeerSigner(ecid.NewPseudoRandom(rng), certFile, verifier)
+	assert.Equal(t, client.ErrCertificatePubKeyMismatch, err)
+	assert.Nil(t, signer)
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 var (
@@ -46,7 +45,7 @@ var (
 	// Week defines a week time window for a Recorder.
 	Week = 7 * Day
 
-	defaultQueryWindowLimits = windowLimits{
+	defaultQueryWindowLimits = WindowLimits{
 		Second: Limits{
 			api.Put:       {false: 0, true: 16},
 			api.Get:       {false: 0, true: 16},
@@ -67,7 +66,7 @@ var (
 		},
 	}
 
-	defaultPeerWindowLimits = windowLimits{
+	defaultPeerWindowLimits = WindowLimits{
 		Second: Limits{
 			api.Put:       {false: 0, true: 64},
 			api.Get:       {false: 0, true: 64},
@@ -126,15 +125,23 @@ type allower struct {
 }
 
 func (a *allower) Allow(peerID id.ID, endpoint api.Endpoint) error {
+	if code, err := a.check(peerID, endpoint); err != nil {
+		return toStatusError(code, err)
+	}
+	return nil
+}
+
+// check returns the authorizer or limiter error for a request and the grpc status code for it.
+func (a *allower) check(peerID id.ID, endpoint api.Endpoint) (codes.Code, error) {
 	if err := a.auth.Authorized(peerID, endpoint); err != nil {
-		return status.Error(codes.PermissionDenied, err.Error())
+		return codes.PermissionDenied, err
 	}
 	for _, limiter := range a.limiters {
 		if err := limiter.WithinLimit(peerID, endpoint); err != nil {
-			return status.Error(codes.ResourceExhausted, err.Error())
+			return codes.ResourceExhausted, err
 		}
 	}
-	return nil
+	return codes.OK, nil
 }
 
 // Authorizer authorizes peers on endpoints.
@@ -197,7 +204,8 @@ type Limiter interface {
 // Limits defines a set of limits for endpoints and whether the peer is known or not.
 type Limits map[api.Endpoint]map[bool]uint64
 
-type windowLimits map[time.Duration]Limits
+// WindowLimits defines a set of Limits for each time window.
+type WindowLimits map[time.Duration]Limits
 
 // NewPeerLimiter returns a new Limiter on the number of peers allowed to make requests for certain
 // endpoints and known status.
@@ -1,6 +1,13 @@
 package comm
 
-import "github.com/drausin/libri/libri/common/id"
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"github.com/drausin/libri/libri/common/id"
+)
 
 // Knower defines which peers are known, and thus usually more trustworthy, versus unknown.
 type Knower interface {
@@ -19,3 +26,209 @@ type alwaysKnower struct{}
 func (k *alwaysKnower) Know(peerID id.ID) bool {
 	return true
 }
+
+// TrustList defines the organizations and individual peers that are known.
+type TrustList struct {
+	// OrgIDs are the hex IDs of the organizations whose peers are known.
+	OrgIDs []string `json:"org_ids"`
+
+	// PeerIDs are the hex IDs of individual known peers.
+	PeerIDs []string `json:"peer_ids"`
+
+	// RevokedCerts are the hex serial numbers of revoked organization certificates.
+	RevokedCerts []string `json:"revoked_certs"`
+}
+
+// NewTrustList returns an empty TrustList.
+func NewTrustList() *TrustList {
+	return &TrustList{
+		OrgIDs:       []string{},
+		PeerIDs:      []string{},
+		RevokedCerts: []string{},
+	}
+}
+
+// AddOrg adds the organization ID to the list if it isn't already there.
+func (tl *TrustList) AddOrg(orgID id.ID) {
+	tl.OrgIDs = addSorted(tl.OrgIDs, orgID.String())
+}
+
+// AddPeer adds the peer ID to the list if it isn't already there.
+func (tl *TrustList) AddPeer(peerID id.ID) {
+	tl.PeerIDs = addSorted(tl.PeerIDs, peerID.String())
+}
+
+// Remove removes the organization or peer ID from the list, returning whether it was there.
+func (tl *TrustList) Remove(orgOrPeerID id.ID) bool {
+	var removedOrg, removedPeer bool
+	tl.OrgIDs, removedOrg = remove(tl.OrgIDs, orgOrPeerID.String())
+	tl.PeerIDs, removedPeer = remove(tl.PeerIDs, orgOrPeerID.String())
+	return removedOrg || removedPeer
+}
+
+// Revoke adds the certificate serial number to the list of revoked certificates if it isn't
+// already there.
+func (tl *TrustList) Revoke(serial id.ID) {
+	tl.RevokedCerts = addSorted(tl.RevokedCerts, serial.String())
+}
+
+// Validate returns an error if any of the IDs or serial numbers are not valid hex IDs.
+func (tl *TrustList) Validate() error {
+	for _, hexIDs := range [][]string{tl.OrgIDs, tl.PeerIDs, tl.RevokedCerts} {
+		if _, err := toIDSet(hexIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadTrustList reads a TrustList from a JSON file.
+func ReadTrustList(filepath string) (*TrustList, error) {
+	buf, err := ioutil.ReadFile(filepath) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	tl := NewTrustList()
+	if err := json.Unmarshal(buf, tl); err != nil {
+		return nil, err
+	}
+	if err := tl.Validate(); err != nil {
+		return nil, err
+	}
+	return tl, nil
+}
+
+// WriteTrustList writes a TrustList to a JSON file.
+func WriteTrustList(filepath string, tl *TrustList) error {
+	if err := tl.Validate(); err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(tl, "", "  ")
+	if err != nil {
+		return err
+	}
+	const filePerm = 0644
+	return ioutil.WriteFile(filepath, buf, filePerm)
+}
+
+// TrustKnower is a Knower that treats a peer as known when it belongs to an organization in a
+// trust list or is itself in the trust list.
+type TrustKnower interface {
+	Knower
+	PeerOrgs
+
+	// KnowOrg returns whether an organization is in the trust list.
+	KnowOrg(orgID id.ID) bool
+
+	// Revoked returns whether the certificate with the given serial number has been revoked.
+	Revoked(serial id.ID) bool
+
+	// Reload replaces the trust list with the one currently in the trust list file.
+	Reload() error
+}
+
+type trustKnower struct {
+	filepath string
+	orgIDs   map[string]struct{}
+	peerIDs  map[string]struct{}
+	revoked  map[string]struct{}
+	PeerOrgs
+	mu sync.RWMutex
+}
+
+// NewTrustKnower returns a TrustKnower using the trust list in the given file and the
+// organizations of peers recorded in peerOrgs.
+func NewTrustKnower(trustListFilepath string, peerOrgs PeerOrgs) (TrustKnower, error) {
+	k := &trustKnower{
+		filepath: trustListFilepath,
+		PeerOrgs: peerOrgs,
+	}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (k *trustKnower) Know(peerID id.ID) bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if _, in := k.peerIDs[peerID.String()]; in {
+		return true
+	}
+	orgID, in := k.GetOrg(peerID)
+	if !in {
+		return false
+	}
+	_, in = k.orgIDs[orgID.String()]
+	return in
+}
+
+func (k *trustKnower) KnowOrg(orgID id.ID) bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	_, in := k.orgIDs[orgID.String()]
+	return in
+}
+
+func (k *trustKnower) Revoked(serial id.ID) bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	_, in := k.revoked[serial.String()]
+	return in
+}
+
+func (k *trustKnower) Reload() error {
+	tl, err := ReadTrustList(k.filepath)
+	if err != nil {
+		return err
+	}
+	orgIDs, err := toIDSet(tl.OrgIDs)
+	if err != nil {
+		return err
+	}
+	peerIDs, err := toIDSet(tl.PeerIDs)
+	if err != nil {
+		return err
+	}
+	revoked, err := toIDSet(tl.RevokedCerts)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.orgIDs, k.peerIDs, k.revoked = orgIDs, peerIDs, revoked
+	k.mu.Unlock()
+	return nil
+}
+
+// toIDSet returns the set of (normalized) hex IDs, or an error if any of them is invalid.
+func toIDSet(hexIDs []string) (map[string]struct{}, error) {
+	set := make(map[string]struct{}, len(hexIDs))
+	for _, hexID := range hexIDs {
+		i, err := id.FromString(hexID)
+		if err != nil {
+			return nil, err
+		}
+		set[i.String()] = struct{}{}
+	}
+	return set, nil
+}
+
+func addSorted(vals []string, val string) []string {
+	for _, v := range vals {
+		if v == val {
+			return vals
+		}
+	}
+	vals = append(vals, val)
+	sort.Strings(vals)
+	return vals
+}
+
+func remove(vals []string, val string) ([]string, bool) {
+	for i, v := range vals {
+		if v == val {
+			return append(vals[:i], vals[i+1:]...), true
+		}
+	}
+	return vals, false
+}
@@ -1,7 +1,10 @@
 package comm
 
 import (
+	"io/ioutil"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/drausin/libri/libri/common/id"
@@ -15,3 +18,165 @@ func TestAlwaysKnower_Know(t *testing.T) {
 
 	assert.True(t, k.Know(peerID))
 }
+
+func TestTrustList_AddRemove(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	orgID, peerID := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	tl := NewTrustList()
+
+	tl.AddOrg(orgID)
+	tl.AddOrg(orgID)
+	tl.AddPeer(peerID)
+	assert.Equal(t, []string{orgID.String()}, tl.OrgIDs)
+	assert.Equal(t, []string{peerID.String()}, tl.PeerIDs)
+
+	assert.True(t, tl.Remove(orgID))
+	assert.False(t, tl.Remove(orgID))
+	assert.Len(t, tl.OrgIDs, 0)
+	assert.True(t, tl.Remove(peerID))
+	assert.Len(t, tl.PeerIDs, 0)
+}
+
+func TestTrustList_Revoke(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	serial := id.NewPseudoRandom(rng)
+	tl := NewTrustList()
+
+	tl.Revoke(serial)
+	tl.Revoke(serial)
+	assert.Equal(t, []string{serial.String()}, tl.RevokedCerts)
+
+	// revocations aren't undone by removing trusted IDs
+	assert.False(t, tl.Remove(serial))
+	assert.Len(t, tl.RevokedCerts, 1)
+}
+
+func TestReadWriteTrustList_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "trust-list-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	fp := filepath.Join(dir, "trust.json")
+
+	tl1 := NewTrustList()
+	tl1.AddOrg(id.NewPseudoRandom(rng))
+	tl1.AddPeer(id.NewPseudoRandom(rng))
+	tl1.AddPeer(id.NewPseudoRandom(rng))
+	tl1.Revoke(id.NewPseudoRandom(rng))
+	assert.Nil(t, WriteTrustList(fp, tl1))
+
+	tl2, err := ReadTrustList(fp)
+	assert.Nil(t, err)
+	assert.Equal(t, tl1, tl2)
+}
+
+func TestReadWriteTrustList_err(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trust-list-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	fp := filepath.Join(dir, "trust.json")
+
+	// bad IDs
+	tl := &TrustList{OrgIDs: []string{"not an ID"}}
+	assert.NotNil(t, WriteTrustList(fp, tl))
+	tl = &TrustList{PeerIDs: []string{"abc"}}
+	assert.NotNil(t, WriteTrustList(fp, tl))
+	tl = &TrustList{RevokedCerts: []string{"abc"}}
+	assert.NotNil(t, WriteTrustList(fp, tl))
+
+	// missing file
+	tl, err = ReadTrustList(fp)
+	assert.NotNil(t, err)
+	assert.Nil(t, tl)
+
+	// bad JSON
+	assert.Nil(t, ioutil.WriteFile(fp, []byte("{bad json"), 0600))
+	tl, err = ReadTrustList(fp)
+	assert.NotNil(t, err)
+	assert.Nil(t, tl)
+
+	// bad ID in file
+	assert.Nil(t, ioutil.WriteFile(fp, []byte(`{"org_ids": ["abc"]}`), 0600))
+	tl, err = ReadTrustList(fp)
+	assert.NotNil(t, err)
+	assert.Nil(t, tl)
+}
+
+func TestTrustKnower_Know(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "trust-list-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	fp := filepath.Join(dir, "trust.json")
+
+	trustedOrgID, otherOrgID := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	trustedPeerID, orgPeerID, otherPeerID := id.NewPseudoRandom(rng),
+		id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	tl := NewTrustList()
+	tl.AddOrg(trustedOrgID)
+	tl.AddPeer(trustedPeerID)
+	assert.Nil(t, WriteTrustList(fp, tl))
+
+	peerOrgs, err := NewPeerOrgs(DefaultPeerOrgsSize)
+	assert.Nil(t, err)
+	k, err := NewTrustKnower(fp, peerOrgs)
+	assert.Nil(t, err)
+	assert.True(t, k.Know(trustedPeerID))
+	assert.False(t, k.Know(orgPeerID))
+	assert.False(t, k.Know(otherPeerID))
+
+	k.SetOrg(orgPeerID, trustedOrgID)
+	k.SetOrg(otherPeerID, otherOrgID)
+	assert.True(t, k.Know(orgPeerID))
+	assert.False(t, k.Know(otherPeerID))
+	assert.True(t, k.KnowOrg(trustedOrgID))
+	assert.False(t, k.KnowOrg(otherOrgID))
+
+	// trust other org and stop trusting individual peer
+	tl.AddOrg(otherOrgID)
+	tl.Remove(trustedPeerID)
+	assert.Nil(t, WriteTrustList(fp, tl))
+	assert.Nil(t, k.Reload())
+	assert.False(t, k.Know(trustedPeerID))
+	assert.True(t, k.Know(orgPeerID))
+	assert.True(t, k.Know(otherPeerID))
+	assert.True(t, k.KnowOrg(otherOrgID))
+
+	// bad reload keeps existing trust list
+	assert.Nil(t, ioutil.WriteFile(fp, []byte("{bad json"), 0600))
+	assert.NotNil(t, k.Reload())
+	assert.True(t, k.Know(otherPeerID))
+}
+
+func TestTrustKnower_Revoked(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	dir, err := ioutil.TempDir("", "trust-list-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	fp := filepath.Join(dir, "trust.json")
+
+	serial1, serial2 := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	tl := NewTrustList()
+	tl.Revoke(serial1)
+	assert.Nil(t, WriteTrustList(fp, tl))
+
+	peerOrgs, err := NewPeerOrgs(DefaultPeerOrgsSize)
+	assert.Nil(t, err)
+	k, err := NewTrustKnower(fp, peerOrgs)
+	assert.Nil(t, err)
+	assert.True(t, k.Revoked(serial1))
+	assert.False(t, k.Revoked(serial2))
+
+	tl.Revoke(serial2)
+	assert.Nil(t, WriteTrustList(fp, tl))
+	assert.Nil(t, k.Reload())
+	assert.True(t, k.Revoked(serial2))
+}
+
+func TestNewTrustKnower_err(t *testing.T) {
+	peerOrgs, err := NewPeerOrgs(DefaultPeerOrgsSize)
+	assert.Nil(t, err)
+	k, err := NewTrustKnower("/does/not/exist.json", peerOrgs)
+	assert.NotNil(t, err)
+	assert.Nil(t, k)
+}
@@ -0,0 +1,567 @@
+package comm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/pkg/errors"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	knownKey   = "known"
+	unknownKey = "unknown"
+
+	limitTypeLabel = "limit_type"
+	windowLabel    = "window"
+	knownLabel     = "known"
+	reasonLabel    = "reason"
+
+	queryLimitType = "query"
+	peerLimitType  = "peer"
+	rateLimitType  = "token_rate"
+	burstLimitType = "token_burst"
+
+	allowSubsystem     = "allow"
+	limitGaugeName     = "limit"
+	authorizedName     = "authorized"
+	rejectionCountName = "rejection_count"
+
+	otherRejection = "other"
+)
+
+var (
+	errNonPositiveLimitWindow = errors.New("limit window must be positive")
+
+	rejectionReasons = map[error]string{
+		ErrUnauthorized:           "unauthorized",
+		ErrKnownAboveQueryLimit:   "known_above_query_limit",
+		ErrUnknownAboveQueryLimit: "unknown_above_query_limit",
+		ErrKnownAbovePeerLimit:    "known_above_peer_limit",
+		ErrUnknownAbovePeerLimit:  "unknown_above_peer_limit",
+		ErrKnownAboveRateLimit:    "known_above_rate_limit",
+		ErrUnknownAboveRateLimit:  "unknown_above_rate_limit",
+	}
+)
+
+// AllowConfig defines the authorizations and rate limits an Allower enforces.
+type AllowConfig struct {
+	// Authorizations defines which endpoints known and unknown peers may make requests on.
+	Authorizations Authorizations
+
+	// QueryLimits defines the max number of requests a peer may make on an endpoint within
+	// each window.
+	QueryLimits WindowLimits
+
+	// PeerLimits defines the max number of peers that may make requests on an endpoint within
+	// each window.
+	PeerLimits WindowLimits
+
+	// TokenRates defines the steady rate and burst at which a peer may make requests on an
+	// endpoint. Endpoints without a rate aren't limited this way.
+	TokenRates TokenRates
+}
+
+// NewDefaultAllowConfig returns a copy of the default authorizations and limits.
+func NewDefaultAllowConfig() *AllowConfig {
+	c := &AllowConfig{
+		Authorizations: make(Authorizations),
+		QueryLimits:    make(WindowLimits),
+		PeerLimits:     make(WindowLimits),
+		TokenRates:     make(TokenRates),
+	}
+	for endpoint, auths := range defaultAuthorizations {
+		for known, auth := range auths {
+			c.setAuthorization(endpoint, known, auth)
+		}
+	}
+	copyWindowLimits(c.QueryLimits, defaultQueryWindowLimits)
+	copyWindowLimits(c.PeerLimits, defaultPeerWindowLimits)
+	return c
+}
+
+// Validate returns an error if any of the limit windows are not positive or token rates are
+// negative.
+func (c *AllowConfig) Validate() error {
+	for _, wls := range []WindowLimits{c.QueryLimits, c.PeerLimits} {
+		for window := range wls {
+			if window <= 0 {
+				return errNonPositiveLimitWindow
+			}
+		}
+	}
+	return c.TokenRates.Validate()
+}
+
+// allowConfigJSON is the JSON representation of an AllowConfig, with endpoints keyed by name,
+// windows by duration string, and known status by "known" or "unknown".
+type allowConfigJSON struct {
+	Authorizations map[string]map[string]bool              `json:"authorizations"`
+	QueryLimits    map[string]map[string]map[string]uint64 `json:"query_limits"`
+	PeerLimits     map[string]map[string]map[string]uint64 `json:"peer_limits"`
+	TokenRates     map[string]map[string]TokenRate         `json:"token_rates"`
+}
+
+// MarshalJSON marshals the AllowConfig to its JSON representation.
+func (c *AllowConfig) MarshalJSON() ([]byte, error) {
+	cj := &allowConfigJSON{
+		Authorizations: make(map[string]map[string]bool),
+		QueryLimits:    windowLimitsToJSON(c.QueryLimits),
+		PeerLimits:     windowLimitsToJSON(c.PeerLimits),
+		TokenRates:     make(map[string]map[string]TokenRate),
+	}
+	for endpoint, auths := range c.Authorizations {
+		cj.Authorizations[endpoint.String()] = make(map[string]bool)
+		for known, auth := range auths {
+			cj.Authorizations[endpoint.String()][knownKeys[known]] = auth
+		}
+	}
+	for endpoint, rates := range c.TokenRates {
+		cj.TokenRates[endpoint.String()] = make(map[string]TokenRate)
+		for known, rate := range rates {
+			cj.TokenRates[endpoint.String()][knownKeys[known]] = rate
+		}
+	}
+	return json.Marshal(cj)
+}
+
+// UnmarshalJSON overrides the AllowConfig's authorizations and limits with those in the JSON
+// representation, leaving the ones it doesn't mention unchanged.
+func (c *AllowConfig) UnmarshalJSON(buf []byte) error {
+	cj := &allowConfigJSON{}
+	if err := json.Unmarshal(buf, cj); err != nil {
+		return err
+	}
+	for endpointName, auths := range cj.Authorizations {
+		endpoint, err := parseEndpoint(endpointName)
+		if err != nil {
+			return err
+		}
+		for knownName, auth := range auths {
+			known, err := parseKnown(knownName)
+			if err != nil {
+				return err
+			}
+			c.setAuthorization(endpoint, known, auth)
+		}
+	}
+	if c.QueryLimits == nil {
+		c.QueryLimits = make(WindowLimits)
+	}
+	if err := windowLimitsFromJSON(c.QueryLimits, cj.QueryLimits); err != nil {
+		return err
+	}
+	if c.PeerLimits == nil {
+		c.PeerLimits = make(WindowLimits)
+	}
+	if err := windowLimitsFromJSON(c.PeerLimits, cj.PeerLimits); err != nil {
+		return err
+	}
+	if c.TokenRates == nil {
+		c.TokenRates = make(TokenRates)
+	}
+	for endpointName, rates := range cj.TokenRates {
+		endpoint, err := parseEndpoint(endpointName)
+		if err != nil {
+			return err
+		}
+		if _, in := c.TokenRates[endpoint]; !in {
+			c.TokenRates[endpoint] = make(map[bool]TokenRate)
+		}
+		for knownName, rate := range rates {
+			known, err := parseKnown(knownName)
+			if err != nil {
+				return err
+			}
+			c.TokenRates[endpoint][known] = rate
+		}
+	}
+	return nil
+}
+
+// maxTokenBuckets returns the number of token buckets needed to keep one for each peer the peer
+// limits of the longest windows allow on the endpoints with token rates, so new peers can't evict
+// the buckets of active ones to let them burst again, or DefaultMaxTokenBuckets if larger.
+func (c *AllowConfig) maxTokenBuckets() int {
+	n := 0
+	for endpoint, knownRates := range c.TokenRates {
+		for known := range knownRates {
+			n += int(c.longestPeerLimit(endpoint, known))
+		}
+	}
+	if n < DefaultMaxTokenBuckets {
+		return DefaultMaxTokenBuckets
+	}
+	return n
+}
+
+// longestPeerLimit returns the peer limit of the longest window with one for the endpoint and
+// known status or zero if there isn't one.
+func (c *AllowConfig) longestPeerLimit(endpoint api.Endpoint, known bool) uint64 {
+	var longest time.Duration
+	limit := uint64(0)
+	for window, limits := range c.PeerLimits {
+		if windowLimit, in := limits[endpoint][known]; in && window > longest {
+			longest, limit = window, windowLimit
+		}
+	}
+	return limit
+}
+
+func (c *AllowConfig) setAuthorization(endpoint api.Endpoint, known, auth bool) {
+	if c.Authorizations == nil {
+		c.Authorizations = make(Authorizations)
+	}
+	if _, in := c.Authorizations[endpoint]; !in {
+		c.Authorizations[endpoint] = make(map[bool]bool)
+	}
+	c.Authorizations[endpoint][known] = auth
+}
+
+// ReadAllowConfig reads an AllowConfig from a JSON file, using the default authorizations and
+// limits for any the file doesn't mention.
+func ReadAllowConfig(filepath string) (*AllowConfig, error) {
+	buf, err := ioutil.ReadFile(filepath) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	c := NewDefaultAllowConfig()
+	if err := json.Unmarshal(buf, c); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// WriteAllowConfig writes an AllowConfig to a JSON file.
+func WriteAllowConfig(filepath string, c *AllowConfig) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	const filePerm = 0644
+	return ioutil.WriteFile(filepath, buf, filePerm)
+}
+
+// NewConfiguredAllower returns a new Allower enforcing the given authorizations and limits. It
+// returns an error if there is no QueryGetter for one of the limit windows.
+func NewConfiguredAllower(c *AllowConfig, knower Knower, queryGetters WindowQueryGetters) (
+	Allower, error) {
+	buckets, err := newTokenBuckets(c.maxTokenBuckets())
+	if err != nil {
+		return nil, err
+	}
+	return newConfiguredAllower(c, knower, queryGetters, buckets)
+}
+
+// newConfiguredAllower returns a new Allower using the given authorizations and limits and
+// keeping the token buckets for any TokenRates in the given buckets.
+func newConfiguredAllower(
+	c *AllowConfig, knower Knower, queryGetters WindowQueryGetters, buckets *tokenBuckets,
+) (Allower, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	limits := make([]Limiter, 0, len(c.PeerLimits)+len(c.QueryLimits)+1)
+	for window, peerWindowLimit := range c.PeerLimits {
+		qGetter, in := queryGetters[window]
+		if !in {
+			return nil, fmt.Errorf("no query counts for peer limit window %s", window)
+		}
+		limits = append(limits, NewPeerLimiter(peerWindowLimit, knower, qGetter))
+	}
+	for window, queryWindowLimit := range c.QueryLimits {
+		qGetter, in := queryGetters[window]
+		if !in {
+			return nil, fmt.Errorf("no query counts for query limit window %s", window)
+		}
+		limits = append(limits, NewQueryLimiter(queryWindowLimit, knower, qGetter))
+	}
+	if len(c.TokenRates) > 0 {
+		// after the other limiters, so peers above the peer limits don't get token buckets
+		lim, err := newTokenBucketLimiter(c.TokenRates, knower, buckets)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, lim)
+	}
+	auth := NewConfiguredAuthorizer(c.Authorizations, knower)
+	return NewAllower(auth, limits...), nil
+}
+
+// ReloadableAllower is an Allower whose authorizations and limits come from a file that can be
+// reloaded while it is running, keeping the token buckets of peers across reloads. It also exposes
+// the active limits and the number of rejected requests via Prometheus metrics.
+type ReloadableAllower interface {
+	Allower
+
+	// Reload replaces the authorizations and limits with those currently in the file, keeping
+	// the previous ones if the file is invalid.
+	Reload() error
+
+	// Config returns the active authorizations and limits.
+	Config() *AllowConfig
+
+	// Register registers the Prometheus metrics with the default Prometheus registerer.
+	Register()
+
+	// Unregister unregisters the Prometheus metrics from the default Prometheus registerer.
+	Unregister()
+}
+
+type reloadableAllower struct {
+	filepath     string
+	knower       Knower
+	queryGetters WindowQueryGetters
+
+	config  *AllowConfig
+	inner   *allower
+	buckets *tokenBuckets
+	limits  *prom.GaugeVec
+	auths   *prom.GaugeVec
+	rejects *prom.CounterVec
+	mu      sync.RWMutex
+}
+
+// NewReloadableAllower returns a ReloadableAllower using the authorizations and limits in the
+// given JSON file or the defaults when the filepath is empty.
+func NewReloadableAllower(limitsFilepath string, knower Knower, queryGetters WindowQueryGetters) (
+	ReloadableAllower, error) {
+	a := &reloadableAllower{
+		filepath:     limitsFilepath,
+		knower:       knower,
+		queryGetters: queryGetters,
+		limits: prom.NewGaugeVec(
+			prom.GaugeOpts{
+				Namespace: counterNamespace,
+				Subsystem: allowSubsystem,
+				Name:      limitGaugeName,
+				Help:      "Active limit on requests to an endpoint within a window.",
+			},
+			[]string{limitTypeLabel, windowLabel, endpointLabel, knownLabel},
+		),
+		auths: prom.NewGaugeVec(
+			prom.GaugeOpts{
+				Namespace: counterNamespace,
+				Subsystem: allowSubsystem,
+				Name:      authorizedName,
+				Help:      "Whether peers are authorized to make requests to an endpoint.",
+			},
+			[]string{endpointLabel, knownLabel},
+		),
+		rejects: prom.NewCounterVec(
+			prom.CounterOpts{
+				Namespace: counterNamespace,
+				Subsystem: allowSubsystem,
+				Name:      rejectionCountName,
+				Help:      "Number of requests rejected as unauthorized or above a limit.",
+			},
+			[]string{endpointLabel, reasonLabel},
+		),
+	}
+	if err := a.Reload(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *reloadableAllower) Allow(peerID id.ID, endpoint api.Endpoint) error {
+	a.mu.RLock()
+	inner := a.inner
+	a.mu.RUnlock()
+	code, err := inner.check(peerID, endpoint)
+	if err == nil {
+		return nil
+	}
+	reason, in := rejectionReasons[errors.Cause(err)]
+	if !in {
+		reason = otherRejection
+	}
+	a.rejects.With(prom.Labels{
+		endpointLabel: endpoint.String(),
+		reasonLabel:   reason,
+	}).Inc()
+	return toStatusError(code, err)
+}
+
+func (a *reloadableAllower) Reload() error {
+	c := NewDefaultAllowConfig()
+	if a.filepath != "" {
+		var err error
+		if c, err = ReadAllowConfig(a.filepath); err != nil {
+			return err
+		}
+	}
+	a.mu.RLock()
+	buckets, err := a.buckets.withMaxSize(c.maxTokenBuckets())
+	a.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	inner, err := newConfiguredAllower(c, a.knower, a.queryGetters, buckets)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.config, a.inner, a.buckets = c, inner.(*allower), buckets
+	a.setGauges()
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *reloadableAllower) Config() *AllowConfig {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.config
+}
+
+func (a *reloadableAllower) Register() {
+	prom.MustRegister(a.limits, a.auths, a.rejects)
+}
+
+func (a *reloadableAllower) Unregister() {
+	_ = prom.Unregister(a.limits)
+	_ = prom.Unregister(a.auths)
+	_ = prom.Unregister(a.rejects)
+}
+
+// setGauges sets the limit and authorization gauges to the current config, so removed limits no
+// longer appear.
+func (a *reloadableAllower) setGauges() {
+	a.limits.Reset()
+	a.auths.Reset()
+	for limitType, wls := range map[string]WindowLimits{
+		queryLimitType: a.config.QueryLimits,
+		peerLimitType:  a.config.PeerLimits,
+	} {
+		for window, limits := range wls {
+			for endpoint, knownLimits := range limits {
+				for known, limit := range knownLimits {
+					a.limits.With(prom.Labels{
+						limitTypeLabel: limitType,
+						windowLabel:    window.String(),
+						endpointLabel:  endpoint.String(),
+						knownLabel:     knownKeys[known],
+					}).Set(float64(limit))
+				}
+			}
+		}
+	}
+	for endpoint, rates := range a.config.TokenRates {
+		for known, rate := range rates {
+			labels := prom.Labels{
+				windowLabel:   "",
+				endpointLabel: endpoint.String(),
+				knownLabel:    knownKeys[known],
+			}
+			labels[limitTypeLabel] = rateLimitType
+			a.limits.With(labels).Set(rate.Rate)
+			labels[limitTypeLabel] = burstLimitType
+			a.limits.With(labels).Set(float64(rate.Burst))
+		}
+	}
+	for endpoint, auths := range a.config.Authorizations {
+		for known, auth := range auths {
+			value := 0.0
+			if auth {
+				value = 1.0
+			}
+			a.auths.With(prom.Labels{
+				endpointLabel: endpoint.String(),
+				knownLabel:    knownKeys[known],
+			}).Set(value)
+		}
+	}
+}
+
+var knownKeys = map[bool]string{true: knownKey, false: unknownKey}
+
+func parseKnown(name string) (bool, error) {
+	switch name {
+	case knownKey:
+		return true, nil
+	case unknownKey:
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid known status %q, must be %q or %q", name, knownKey,
+			unknownKey)
+	}
+}
+
+func parseEndpoint(name string) (api.Endpoint, error) {
+	for _, endpoint := range api.Endpoints {
+		if endpoint.String() == name {
+			return endpoint, nil
+		}
+	}
+	return api.All, fmt.Errorf("unknown endpoint %q", name)
+}
+
+func copyWindowLimits(dest, src WindowLimits) {
+	for window, limits := range src {
+		if _, in := dest[window]; !in {
+			dest[window] = make(Limits)
+		}
+		for endpoint, knownLimits := range limits {
+			if _, in := dest[window][endpoint]; !in {
+				dest[window][endpoint] = make(map[bool]uint64)
+			}
+			for known, limit := range knownLimits {
+				dest[window][endpoint][known] = limit
+			}
+		}
+	}
+}
+
+func windowLimitsToJSON(wls WindowLimits) map[string]map[string]map[string]uint64 {
+	wlsJSON := make(map[string]map[string]map[string]uint64)
+	for window, limits := range wls {
+		wlsJSON[window.String()] = make(map[string]map[string]uint64)
+		for endpoint, knownLimits := range limits {
+			wlsJSON[window.String()][endpoint.String()] = make(map[string]uint64)
+			for known, limit := range knownLimits {
+				wlsJSON[window.String()][endpoint.String()][knownKeys[known]] = limit
+			}
+		}
+	}
+	return wlsJSON
+}
+
+func windowLimitsFromJSON(
+	dest WindowLimits, wlsJSON map[string]map[string]map[string]uint64,
+) error {
+	src := make(WindowLimits)
+	for windowName, limits := range wlsJSON {
+		window, err := time.ParseDuration(windowName)
+		if err != nil {
+			return err
+		}
+		src[window] = make(Limits)
+		for endpointName, knownLimits := range limits {
+			endpoint, err := parseEndpoint(endpointName)
+			if err != nil {
+				return err
+			}
+			src[window][endpoint] = make(map[bool]uint64)
+			for knownName, limit := range knownLimits {
+				known, err := parseKnown(knownName)
+				if err != nil {
+					return err
+				}
+				src[window][endpoint][known] = limit
+			}
+		}
+	}
+	copyWindowLimits(dest, src)
+	return nil
+}
@@ -0,0 +1,310 @@
+package comm
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	prom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewDefaultAllowConfig(t *testing.T) {
+	c := NewDefaultAllowConfig()
+	assert.Equal(t, defaultAuthorizations, c.Authorizations)
+	assert.Equal(t, defaultQueryWindowLimits, c.QueryLimits)
+	assert.Equal(t, defaultPeerWindowLimits, c.PeerLimits)
+
+	// changing copy doesn't change defaults
+	c.Authorizations[api.Put][false] = true
+	c.QueryLimits[Second][api.Put][true] = 1
+	assert.False(t, defaultAuthorizations[api.Put][false])
+	assert.Equal(t, uint64(16), defaultQueryWindowLimits[Second][api.Put][true])
+}
+
+func TestAllowConfig_Validate(t *testing.T) {
+	c := NewDefaultAllowConfig()
+	assert.Nil(t, c.Validate())
+
+	c.PeerLimits[-Second] = Limits{}
+	assert.Equal(t, errNonPositiveLimitWindow, c.Validate())
+
+	c = NewDefaultAllowConfig()
+	c.TokenRates[api.Put] = map[bool]TokenRate{true: {Rate: -1}}
+	assert.Equal(t, errNegativeTokenRate, c.Validate())
+}
+
+func TestAllowConfig_MarshalUnmarshalJSON(t *testing.T) {
+	c1 := NewDefaultAllowConfig()
+	c1.TokenRates[api.Find] = map[bool]TokenRate{true: {Rate: 0.5, Burst: 4}}
+	buf, err := json.Marshal(c1)
+	assert.Nil(t, err)
+
+	c2 := &AllowConfig{}
+	err = json.Unmarshal(buf, c2)
+	assert.Nil(t, err)
+	assert.Equal(t, c1, c2)
+}
+
+func TestAllowConfig_UnmarshalJSON_err(t *testing.T) {
+	cases := map[string]string{
+		"not JSON":            `not JSON`,
+		"bad auth endpoint":   `{"authorizations": {"Other": {"known": true}}}`,
+		"bad auth known":      `{"authorizations": {"Put": {"other": true}}}`,
+		"bad window":          `{"query_limits": {"1 fortnight": {"Put": {"known": 1}}}}`,
+		"bad limit endpoint":  `{"query_limits": {"1s": {"Other": {"known": 1}}}}`,
+		"bad limit known":     `{"peer_limits": {"1s": {"Put": {"other": 1}}}}`,
+		"bad limit value":     `{"peer_limits": {"1s": {"Put": {"known": -1}}}}`,
+		"bad peer limit type": `{"peer_limits": {"1s": ["Put"]}}`,
+		"bad rate endpoint":   `{"token_rates": {"Other": {"known": {"rate": 1}}}}`,
+		"bad rate known":      `{"token_rates": {"Put": {"other": {"rate": 1}}}}`,
+	}
+	for desc, in := range cases {
+		c := NewDefaultAllowConfig()
+		assert.NotNil(t, json.Unmarshal([]byte(in), c), desc)
+	}
+}
+
+func TestReadWriteAllowConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-allow-config")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	filepath := path.Join(dir, "limits.json")
+
+	c1 := NewDefaultAllowConfig()
+	c1.Authorizations[api.Find][false] = true
+	c1.QueryLimits[Week] = Limits{api.Find: {false: 4}}
+	err = WriteAllowConfig(filepath, c1)
+	assert.Nil(t, err)
+	c2, err := ReadAllowConfig(filepath)
+	assert.Nil(t, err)
+	assert.Equal(t, c1, c2)
+
+	// file only overrides what it mentions
+	in := `{
+		"authorizations": {"Find": {"unknown": true}},
+		"query_limits": {"1s": {"Find": {"unknown": 2}}},
+		"token_rates": {"Find": {"known": {"rate": 0.5, "burst": 4}}}
+	}`
+	err = ioutil.WriteFile(filepath, []byte(in), 0644)
+	assert.Nil(t, err)
+	c3, err := ReadAllowConfig(filepath)
+	assert.Nil(t, err)
+	assert.True(t, c3.Authorizations[api.Find][false])
+	assert.True(t, c3.Authorizations[api.Find][true])
+	assert.False(t, c3.Authorizations[api.Put][false])
+	assert.Equal(t, uint64(2), c3.QueryLimits[Second][api.Find][false])
+	assert.Equal(t, uint64(16), c3.QueryLimits[Second][api.Find][true])
+	assert.Equal(t, defaultPeerWindowLimits, c3.PeerLimits)
+	assert.Equal(t, TokenRate{Rate: 0.5, Burst: 4}, c3.TokenRates[api.Find][true])
+
+	// missing file
+	c4, err := ReadAllowConfig(path.Join(dir, "other.json"))
+	assert.NotNil(t, err)
+	assert.Nil(t, c4)
+
+	// invalid window
+	err = ioutil.WriteFile(filepath, []byte(`{"peer_limits": {"-1s": {}}}`), 0644)
+	assert.Nil(t, err)
+	c4, err = ReadAllowConfig(filepath)
+	assert.Equal(t, errNonPositiveLimitWindow, err)
+	assert.Nil(t, c4)
+
+	// won't write invalid config
+	c1.PeerLimits[-Second] = Limits{}
+	err = WriteAllowConfig(filepath, c1)
+	assert.Equal(t, errNonPositiveLimitWindow, err)
+}
+
+func TestNewConfiguredAllower(t *testing.T) {
+	k := NewAlwaysKnower()
+	_, getters := NewWindowQueryRecorderGetters(k, []time.Duration{Second, Day})
+	a, err := NewConfiguredAllower(NewDefaultAllowConfig(), k, getters)
+	assert.Nil(t, err)
+	assert.NotNil(t, a.(*allower).auth)
+	assert.Equal(t, 4, len(a.(*allower).limiters))
+
+	// with token rates
+	c := NewDefaultAllowConfig()
+	c.TokenRates[api.Find] = map[bool]TokenRate{true: {Rate: 1, Burst: 1}}
+	a, err = NewConfiguredAllower(c, k, getters)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(a.(*allower).limiters))
+
+	// no getter for window
+	c = NewDefaultAllowConfig()
+	c.QueryLimits[Week] = Limits{}
+	a, err = NewConfiguredAllower(c, k, getters)
+	assert.NotNil(t, err)
+	assert.Nil(t, a)
+
+	c = NewDefaultAllowConfig()
+	c.PeerLimits[Week] = Limits{}
+	a, err = NewConfiguredAllower(c, k, getters)
+	assert.NotNil(t, err)
+	assert.Nil(t, a)
+
+	// invalid config
+	c = NewDefaultAllowConfig()
+	c.PeerLimits[-Second] = Limits{}
+	a, err = NewConfiguredAllower(c, k, getters)
+	assert.Equal(t, errNonPositiveLimitWindow, err)
+	assert.Nil(t, a)
+}
+
+func TestReloadableAllower(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := id.NewPseudoRandom(rng)
+	dir, err := ioutil.TempDir("", "test-reloadable-allower")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	filepath := path.Join(dir, "limits.json")
+	k := &neverKnower{}
+	_, getters := NewWindowQueryRecorderGetters(k, []time.Duration{Second, Day})
+
+	// defaults without file
+	a, err := NewReloadableAllower("", k, getters)
+	assert.Nil(t, err)
+	assert.Equal(t, NewDefaultAllowConfig(), a.Config())
+	assert.Nil(t, a.Reload())
+
+	// unknown peers unauthorized on Find by default
+	err = a.Allow(peerID, api.Find)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// authorize unknown peers on Find from file
+	in := `{"authorizations": {"Find": {"unknown": true}}}`
+	assert.Nil(t, ioutil.WriteFile(filepath, []byte(in), 0644))
+	a, err = NewReloadableAllower(filepath, k, getters)
+	assert.Nil(t, err)
+	assert.Nil(t, a.Allow(peerID, api.Find))
+
+	// reload with unauthorized again
+	in = `{"authorizations": {"Find": {"unknown": false}}}`
+	assert.Nil(t, ioutil.WriteFile(filepath, []byte(in), 0644))
+	assert.Nil(t, a.Reload())
+	err = a.Allow(peerID, api.Find)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// invalid file keeps previous config
+	prev := a.Config()
+	assert.Nil(t, ioutil.WriteFile(filepath, []byte(`{"peer_limits": {"168h": {}}}`), 0644))
+	assert.NotNil(t, a.Reload())
+	assert.Equal(t, prev, a.Config())
+
+	// unsupported window in initial file
+	a, err = NewReloadableAllower(filepath, k, getters)
+	assert.NotNil(t, err)
+	assert.Nil(t, a)
+}
+
+func TestReloadableAllower_metrics(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := id.NewPseudoRandom(rng)
+	k := &neverKnower{}
+	_, getters := NewWindowQueryRecorderGetters(k, []time.Duration{Second, Day})
+	a, err := NewReloadableAllower("", k, getters)
+	assert.Nil(t, err)
+	ra := a.(*reloadableAllower)
+	a.Register()
+	defer a.Unregister()
+
+	// one gauge per (type, window, endpoint, known) limit and (endpoint, known) authorization
+	nLimits := 2 * 2 * len(api.Endpoints) * 2
+	assert.Equal(t, nLimits, countMetrics(ra.limits))
+	assert.Equal(t, len(api.Endpoints)*2, countMetrics(ra.auths))
+
+	g, err := ra.limits.GetMetricWith(prom.Labels{
+		limitTypeLabel: queryLimitType,
+		windowLabel:    Second.String(),
+		endpointLabel:  api.Put.String(),
+		knownLabel:     knownKey,
+	})
+	assert.Nil(t, err)
+	written := &dto.Metric{}
+	assert.Nil(t, g.Write(written))
+	assert.Equal(t, float64(16), *written.Gauge.Value)
+
+	for i := 0; i < 3; i++ {
+		assert.NotNil(t, a.Allow(peerID, api.Put))
+	}
+	c, err := ra.rejects.GetMetricWith(prom.Labels{
+		endpointLabel: api.Put.String(),
+		reasonLabel:   "unauthorized",
+	})
+	assert.Nil(t, err)
+	written = &dto.Metric{}
+	assert.Nil(t, c.Write(written))
+	assert.Equal(t, float64(3), *written.Counter.Value)
+}
+
+func TestReloadableAllower_tokenRates(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := id.NewPseudoRandom(rng)
+	dir, err := ioutil.TempDir("", "test-reloadable-allower")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	filepath := path.Join(dir, "limits.json")
+	in := `{"token_rates": {"Find": {"known": {"rate": 0.001, "burst": 1}}}}`
+	assert.Nil(t, ioutil.WriteFile(filepath, []byte(in), 0644))
+	k := NewAlwaysKnower()
+	_, getters := NewWindowQueryRecorderGetters(k, []time.Duration{Second, Day})
+	a, err := NewReloadableAllower(filepath, k, getters)
+	assert.Nil(t, err)
+	ra := a.(*reloadableAllower)
+
+	// rate and burst gauges in addition to window limits
+	nLimits := 2*2*len(api.Endpoints)*2 + 2
+	assert.Equal(t, nLimits, countMetrics(ra.limits))
+
+	// new bucket starts full
+	assert.Nil(t, a.Allow(peerID, api.Find))
+	err = a.Allow(peerID, api.Find)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	delay, ok := RetryDelay(err)
+	assert.True(t, ok)
+	assert.True(t, delay > 0)
+
+	// reloading keeps the buckets
+	assert.Nil(t, a.Reload())
+	err = a.Allow(peerID, api.Find)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	c, err := ra.rejects.GetMetricWith(prom.Labels{
+		endpointLabel: api.Find.String(),
+		reasonLabel:   "known_above_rate_limit",
+	})
+	assert.Nil(t, err)
+	written := &dto.Metric{}
+	assert.Nil(t, c.Write(written))
+	assert.Equal(t, float64(2), *written.Counter.Value)
+}
+
+func TestAllowConfig_maxTokenBuckets(t *testing.T) {
+	c := NewDefaultAllowConfig()
+	assert.Equal(t, DefaultMaxTokenBuckets, c.maxTokenBuckets())
+
+	// one bucket for each peer allowed by the longest window's peer limit
+	c.TokenRates[api.Find] = map[bool]TokenRate{true: {Rate: 1, Burst: 1}}
+	c.TokenRates[api.Get] = map[bool]TokenRate{true: {Rate: 1, Burst: 1}}
+	c.PeerLimits[Second][api.Find][true] = 2 * DefaultMaxTokenBuckets
+	c.PeerLimits[Day][api.Find][true] = DefaultMaxTokenBuckets
+	c.PeerLimits[Day][api.Get][true] = 8
+	assert.Equal(t, DefaultMaxTokenBuckets+8, c.maxTokenBuckets())
+}
+
+func countMetrics(c prom.Collector) int {
+	metrics := make(chan prom.Metric, 1024)
+	c.Collect(metrics)
+	close(metrics)
+	return len(metrics)
+}
@@ -0,0 +1,46 @@
+package comm
+
+import (
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/hashicorp/golang-lru"
+)
+
+// DefaultPeerOrgsSize is the default number of peers whose organizations a PeerOrgs remembers.
+const DefaultPeerOrgsSize = 4096
+
+// PeerOrgs records the organizations peers belong to.
+type PeerOrgs interface {
+	// SetOrg records that a peer belongs to the given organization. Callers should only do so
+	// after the peer has proven its membership, e.g., by a valid organization signature on its
+	// request.
+	SetOrg(peerID, orgID id.ID)
+
+	// GetOrg returns the organization a peer belongs to and whether it is known.
+	GetOrg(peerID id.ID) (id.ID, bool)
+}
+
+type peerOrgs struct {
+	orgs *lru.Cache
+}
+
+// NewPeerOrgs returns a new PeerOrgs remembering the organizations of up to size peers, evicting
+// the least recently used.
+func NewPeerOrgs(size int) (PeerOrgs, error) {
+	orgs, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &peerOrgs{orgs: orgs}, nil
+}
+
+func (po *peerOrgs) SetOrg(peerID, orgID id.ID) {
+	po.orgs.Add(peerID.String(), orgID)
+}
+
+func (po *peerOrgs) GetOrg(peerID id.ID) (id.ID, bool) {
+	orgID, in := po.orgs.Get(peerID.String())
+	if !in {
+		return nil, false
+	}
+	return orgID.(id.ID), true
+}
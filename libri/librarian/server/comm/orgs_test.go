@@ -0,0 +1,42 @@
+package comm
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerOrgs_SetGetOrg(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID1, peerID2, peerID3 := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng),
+		id.NewPseudoRandom(rng)
+	orgID1, orgID2 := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	po, err := NewPeerOrgs(2)
+	assert.Nil(t, err)
+
+	orgID, in := po.GetOrg(peerID1)
+	assert.False(t, in)
+	assert.Nil(t, orgID)
+
+	po.SetOrg(peerID1, orgID1)
+	po.SetOrg(peerID2, orgID2)
+	orgID, in = po.GetOrg(peerID1)
+	assert.True(t, in)
+	assert.Equal(t, orgID1, orgID)
+
+	// evicts least recently used peer 2
+	po.SetOrg(peerID3, orgID1)
+	_, in = po.GetOrg(peerID2)
+	assert.False(t, in)
+	orgID, in = po.GetOrg(peerID3)
+	assert.True(t, in)
+	assert.Equal(t, orgID1, orgID)
+}
+
+func TestNewPeerOrgs_err(t *testing.T) {
+	po, err := NewPeerOrgs(0)
+	assert.NotNil(t, err)
+	assert.Nil(t, po)
+}
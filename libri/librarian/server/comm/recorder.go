@@ -28,9 +28,10 @@ var (
 	// healthyErrStatusCodes defines the set of GRPC error codes that a health server can
 	// return. usually due to some client issue.
 	healthyErrStatusCodes = map[codes.Code]struct{}{
-		codes.InvalidArgument:   {},
-		codes.PermissionDenied:  {},
-		codes.ResourceExhausted: {},
+		codes.InvalidArgument:    {},
+		codes.PermissionDenied:   {},
+		codes.ResourceExhausted:  {},
+		codes.FailedPrecondition: {},
 	}
 )
 
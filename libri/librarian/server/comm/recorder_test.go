@@ -45,6 +45,12 @@ func TestMaybeRecordErr(t *testing.T) {
 			errRecorded:     false,
 			successRecorded: true,
 		},
+		"stale pointer err": {
+			peerID:          peerID,
+			err:             status.Error(codes.FailedPrecondition, "stale pointer"),
+			errRecorded:     false,
+			successRecorded: true,
+		},
 	}
 	for desc, c := range cases {
 		r := &fixedRecorder{}
@@ -0,0 +1,236 @@
+package comm
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMaxTokenBuckets is the default max number of (peer, endpoint) token buckets a token
+// bucket Limiter keeps before evicting the least recently used. Configured Allowers keep more if
+// their peer limits allow more peers.
+const DefaultMaxTokenBuckets = 16 * 1024
+
+var (
+	// ErrKnownAboveRateLimit indicates when a known peer is above a token bucket rate limit.
+	ErrKnownAboveRateLimit = errors.New("known peer above rate limit")
+
+	// ErrUnknownAboveRateLimit indicates when an unknown peer is above a token bucket rate limit.
+	ErrUnknownAboveRateLimit = errors.New("unknown peer above rate limit")
+
+	errNegativeTokenRate     = errors.New("token rate must be non-negative")
+	errNonPositiveTokenBurst = errors.New("token burst must be positive")
+)
+
+// TokenRate defines the steady rate at which a peer may make requests and the burst of requests
+// it may make above that rate.
+type TokenRate struct {
+	// Rate is the number of requests per second a peer may make in the long run, where zero
+	// refuses all requests.
+	Rate float64 `json:"rate"`
+
+	// Burst is the max number of requests a peer may make at once after being idle.
+	Burst uint64 `json:"burst"`
+}
+
+// TokenRates defines the TokenRate for endpoints and whether the peer is known or not.
+type TokenRates map[api.Endpoint]map[bool]TokenRate
+
+// Validate returns an error if any of the rates are negative or bursts are zero.
+func (trs TokenRates) Validate() error {
+	for _, knownRates := range trs {
+		for _, tr := range knownRates {
+			if tr.Rate < 0 || math.IsNaN(tr.Rate) {
+				return errNegativeTokenRate
+			}
+			if tr.Burst == 0 {
+				return errNonPositiveTokenBurst
+			}
+		}
+	}
+	return nil
+}
+
+// RateLimitError indicates that a request is above a rate limit and how long the peer should wait
+// before retrying it.
+type RateLimitError struct {
+	// Err is the underlying limit error.
+	Err error
+
+	// RetryAfter is how long until the request would be within the rate limit. It is zero if
+	// the request will never be within the limit.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return e.Err.Error()
+}
+
+// Cause returns the underlying limit error.
+func (e *RateLimitError) Cause() error {
+	return e.Err
+}
+
+// RetryDelay returns the delay before retrying a request that was rejected for being above a rate
+// limit, as given in the details of the gRPC status error, and whether there was one.
+func RetryDelay(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return 0, false
+	}
+	for _, detail := range st.Details() {
+		if ri, ok := detail.(*errdetails.RetryInfo); ok {
+			delay, err := ptypes.Duration(ri.RetryDelay)
+			if err != nil {
+				return 0, false
+			}
+			return delay, true
+		}
+	}
+	return 0, false
+}
+
+// toStatusError returns a gRPC status error with the given code for the error, including a retry
+// delay in the status details for a RateLimitError.
+func toStatusError(code codes.Code, err error) error {
+	st := status.New(code, err.Error())
+	if rlErr, ok := err.(*RateLimitError); ok && rlErr.RetryAfter > 0 {
+		retry := &errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(rlErr.RetryAfter)}
+		if withRetry, err := st.WithDetails(retry); err == nil {
+			st = withRetry
+		}
+	}
+	return st.Err()
+}
+
+type tokenBucketKey struct {
+	peerID   string
+	endpoint api.Endpoint
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// tokenBuckets holds up to a max number of token buckets, evicting the least recently used, so
+// they can outlive the token bucket Limiter using them, e.g., when its rates are reloaded.
+type tokenBuckets struct {
+	cache   *lru.Cache
+	maxSize int
+	mu      sync.Mutex
+}
+
+func newTokenBuckets(maxSize int) (*tokenBuckets, error) {
+	cache, err := lru.New(maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return &tokenBuckets{cache: cache, maxSize: maxSize}, nil
+}
+
+// withMaxSize returns these token buckets if they hold up to at least the given max size or
+// otherwise a copy of them holding up to that size, which are new token buckets if these are nil.
+func (tbs *tokenBuckets) withMaxSize(maxSize int) (*tokenBuckets, error) {
+	if tbs == nil {
+		return newTokenBuckets(maxSize)
+	}
+	if maxSize <= tbs.maxSize {
+		return tbs, nil
+	}
+	larger, err := newTokenBuckets(maxSize)
+	if err != nil {
+		return nil, err
+	}
+	tbs.mu.Lock()
+	defer tbs.mu.Unlock()
+	for _, key := range tbs.cache.Keys() { // oldest to newest
+		if value, in := tbs.cache.Peek(key); in {
+			b := *value.(*tokenBucket)
+			larger.cache.Add(key, &b)
+		}
+	}
+	return larger, nil
+}
+
+// NewTokenBucketLimiter returns a new Limiter allowing each peer to make requests on an endpoint
+// at a steady rate with some burst above it. It keeps a token bucket for up to maxBuckets
+// (peer, endpoint) pairs, evicting the least recently used. New buckets start full, so a peer's
+// first requests are allowed up to the burst.
+func NewTokenBucketLimiter(rates TokenRates, knower Knower, maxBuckets int) (Limiter, error) {
+	buckets, err := newTokenBuckets(maxBuckets)
+	if err != nil {
+		return nil, err
+	}
+	return newTokenBucketLimiter(rates, knower, buckets)
+}
+
+func newTokenBucketLimiter(rates TokenRates, knower Knower, buckets *tokenBuckets) (
+	Limiter, error) {
+	if err := rates.Validate(); err != nil {
+		return nil, err
+	}
+	return &tokenBucketLimiter{
+		rates:   rates,
+		knower:  knower,
+		buckets: buckets,
+		now:     time.Now,
+	}, nil
+}
+
+type tokenBucketLimiter struct {
+	rates   TokenRates
+	knower  Knower
+	buckets *tokenBuckets
+	now     func() time.Time
+}
+
+func (l *tokenBucketLimiter) WithinLimit(peerID id.ID, endpoint api.Endpoint) error {
+	epRates, hasEPRates := l.rates[endpoint]
+	if !hasEPRates {
+		return nil
+	}
+	known := l.knower.Know(peerID)
+	rate, hasRate := epRates[known]
+	if !hasRate {
+		return nil
+	}
+
+	rlErr := &RateLimitError{Err: ErrUnknownAboveRateLimit}
+	if known {
+		rlErr.Err = ErrKnownAboveRateLimit
+	}
+	if rate.Rate == 0 {
+		return rlErr
+	}
+
+	key := tokenBucketKey{peerID: peerID.String(), endpoint: endpoint}
+	now := l.now()
+	l.buckets.mu.Lock()
+	defer l.buckets.mu.Unlock()
+	var b *tokenBucket
+	if value, in := l.buckets.cache.Get(key); in {
+		b = value.(*tokenBucket)
+		elapsed := now.Sub(b.last).Seconds()
+		b.tokens = math.Min(float64(rate.Burst), b.tokens+math.Max(0, elapsed)*rate.Rate)
+	} else {
+		b = &tokenBucket{tokens: float64(rate.Burst)}
+		l.buckets.cache.Add(key, b)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return nil
+	}
+	rlErr.RetryAfter = time.Duration((1 - b.tokens) / rate.Rate * float64(time.Second))
+	return rlErr
+}
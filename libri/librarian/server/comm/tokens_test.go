@@ -0,0 +1,177 @@
+package comm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTokenRates_Validate(t *testing.T) {
+	trs := TokenRates{api.Find: {true: {Rate: 1, Burst: 2}, false: {Burst: 1}}}
+	assert.Nil(t, trs.Validate())
+
+	trs[api.Find][false] = TokenRate{Rate: -1, Burst: 1}
+	assert.Equal(t, errNegativeTokenRate, trs.Validate())
+
+	trs[api.Find][false] = TokenRate{Rate: math.NaN(), Burst: 1}
+	assert.Equal(t, errNegativeTokenRate, trs.Validate())
+
+	trs[api.Find][false] = TokenRate{Rate: 1}
+	assert.Equal(t, errNonPositiveTokenBurst, trs.Validate())
+}
+
+func TestNewTokenBucketLimiter_err(t *testing.T) {
+	k := NewAlwaysKnower()
+	l, err := NewTokenBucketLimiter(TokenRates{api.Find: {true: {Rate: -1}}}, k,
+		DefaultMaxTokenBuckets)
+	assert.Equal(t, errNegativeTokenRate, err)
+	assert.Nil(t, l)
+
+	l, err = NewTokenBucketLimiter(TokenRates{api.Find: {true: {Rate: 1}}}, k,
+		DefaultMaxTokenBuckets)
+	assert.Equal(t, errNonPositiveTokenBurst, err)
+	assert.Nil(t, l)
+
+	l, err = NewTokenBucketLimiter(TokenRates{}, k, 0)
+	assert.NotNil(t, err)
+	assert.Nil(t, l)
+}
+
+func TestTokenBucketLimiter_WithinLimit(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID1, peerID2 := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	rates := TokenRates{
+		api.Find: {true: {Rate: 2, Burst: 3}},
+		api.Get:  {true: {Rate: 0, Burst: 1}},
+	}
+	lim, err := NewTokenBucketLimiter(rates, NewAlwaysKnower(), DefaultMaxTokenBuckets)
+	assert.Nil(t, err)
+	now := time.Unix(1500000000, 0)
+	l := lim.(*tokenBucketLimiter)
+	l.now = func() time.Time { return now }
+
+	// new bucket starts full, so burst is allowed at once
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, l.WithinLimit(peerID1, api.Find))
+	}
+	err = l.WithinLimit(peerID1, api.Find)
+	rlErr, ok := err.(*RateLimitError)
+	assert.True(t, ok)
+	assert.Equal(t, ErrKnownAboveRateLimit, rlErr.Err)
+	assert.Equal(t, 500*time.Millisecond, rlErr.RetryAfter)
+
+	// other peers and endpoints have their own buckets or no limit
+	assert.Nil(t, l.WithinLimit(peerID2, api.Find))
+	assert.Nil(t, l.WithinLimit(peerID1, api.Store))
+
+	// refills at steady rate
+	now = now.Add(500 * time.Millisecond)
+	assert.Nil(t, l.WithinLimit(peerID1, api.Find))
+	assert.NotNil(t, l.WithinLimit(peerID1, api.Find))
+
+	// refills no more than burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, l.WithinLimit(peerID1, api.Find))
+	}
+	err = l.WithinLimit(peerID1, api.Find)
+	assert.Equal(t, 500*time.Millisecond, err.(*RateLimitError).RetryAfter)
+
+	// never allowed, so no retry
+	err = l.WithinLimit(peerID1, api.Get)
+	assert.Equal(t, &RateLimitError{Err: ErrKnownAboveRateLimit}, err)
+	now = now.Add(time.Hour)
+	err = l.WithinLimit(peerID1, api.Get)
+	assert.Equal(t, &RateLimitError{Err: ErrKnownAboveRateLimit}, err)
+}
+
+func TestTokenBucketLimiter_WithinLimit_unknown(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := id.NewPseudoRandom(rng)
+	rates := TokenRates{api.Find: {false: {Rate: 1, Burst: 1}}}
+	lim, err := NewTokenBucketLimiter(rates, &neverKnower{}, DefaultMaxTokenBuckets)
+	assert.Nil(t, err)
+	now := time.Unix(1500000000, 0)
+	l := lim.(*tokenBucketLimiter)
+	l.now = func() time.Time { return now }
+
+	assert.Nil(t, l.WithinLimit(peerID, api.Find))
+	err = l.WithinLimit(peerID, api.Find)
+	assert.Equal(t, ErrUnknownAboveRateLimit, err.(*RateLimitError).Err)
+	now = now.Add(time.Second)
+	assert.Nil(t, l.WithinLimit(peerID, api.Find))
+}
+
+func TestTokenBucketLimiter_WithinLimit_evicted(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID1, peerID2 := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	rates := TokenRates{api.Find: {true: {Rate: 1, Burst: 1}}}
+	lim, err := NewTokenBucketLimiter(rates, NewAlwaysKnower(), 1)
+	assert.Nil(t, err)
+	now := time.Unix(1500000000, 0)
+	l := lim.(*tokenBucketLimiter)
+	l.now = func() time.Time { return now }
+
+	assert.Nil(t, l.WithinLimit(peerID1, api.Find))
+	assert.NotNil(t, l.WithinLimit(peerID1, api.Find))
+
+	// evicts peer 1's bucket, which starts full again
+	assert.Nil(t, l.WithinLimit(peerID2, api.Find))
+	assert.Equal(t, 1, l.buckets.cache.Len())
+	assert.Nil(t, l.WithinLimit(peerID1, api.Find))
+}
+
+func TestTokenBuckets_withMaxSize(t *testing.T) {
+	var tbs *tokenBuckets
+	tbs, err := tbs.withMaxSize(2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, tbs.maxSize)
+	key1, key2 := tokenBucketKey{peerID: "1"}, tokenBucketKey{peerID: "2"}
+	tbs.cache.Add(key1, &tokenBucket{tokens: 1})
+	tbs.cache.Add(key2, &tokenBucket{tokens: 2})
+
+	// same buckets if large enough
+	same, err := tbs.withMaxSize(1)
+	assert.Nil(t, err)
+	assert.Equal(t, tbs, same)
+
+	// copy of buckets if not
+	larger, err := tbs.withMaxSize(3)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, larger.maxSize)
+	assert.Equal(t, tbs.cache.Keys(), larger.cache.Keys())
+	b, in := larger.cache.Peek(key2)
+	assert.True(t, in)
+	assert.Equal(t, float64(2), b.(*tokenBucket).tokens)
+}
+
+func TestAllower_Allow_retryDelay(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := id.NewPseudoRandom(rng)
+	rlErr := &RateLimitError{Err: ErrKnownAboveRateLimit, RetryAfter: 3 * time.Second}
+	a := NewAllower(&fixedAuthorizer{}, &fixedLimiter{err: rlErr})
+
+	err := a.Allow(peerID, api.Find)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	delay, ok := RetryDelay(err)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, delay)
+
+	// no retry delay for other limit errors
+	a = NewAllower(&fixedAuthorizer{}, &fixedLimiter{err: ErrKnownAboveQueryLimit})
+	err = a.Allow(peerID, api.Find)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	_, ok = RetryDelay(err)
+	assert.False(t, ok)
+
+	// not a status error
+	_, ok = RetryDelay(errTest)
+	assert.False(t, ok)
+}
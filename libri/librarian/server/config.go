@@ -6,18 +6,24 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/common/parse"
 	"github.com/drausin/libri/libri/common/subscribe"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/relay"
 	"github.com/drausin/libri/libri/librarian/server/introduce"
+	"github.com/drausin/libri/libri/librarian/server/refresh"
 	"github.com/drausin/libri/libri/librarian/server/replicate"
 	"github.com/drausin/libri/libri/librarian/server/routing"
 	"github.com/drausin/libri/libri/librarian/server/search"
 	"github.com/drausin/libri/libri/librarian/server/store"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -42,6 +48,14 @@ const (
 
 	// DBSubDir is the default DB subdirectory within the data dir.
 	DBSubDir = "db"
+
+	// DefaultClockSkew is the default max difference between a requester's and the server's
+	// clocks tolerated when checking request signatures.
+	DefaultClockSkew = client.DefaultClockSkew
+
+	// DefaultReplayCacheMemory is the default max memory in bytes used to remember recent request
+	// IDs to reject replayed requests.
+	DefaultReplayCacheMemory = 64 * 1024 * 1024
 )
 
 // Config is used to configure a Librarian server
@@ -61,6 +75,30 @@ type Config struct {
 	// PublicName is the public facing name of the peer.
 	PublicName string
 
+	// AdvertisedAddrs are other host:port addresses peers may reach this server at, e.g., IPv6
+	// addresses or DNS names, which peers resolve when connecting.
+	AdvertisedAddrs []string
+
+	// RelayAddr is the address of the relay server through which peers reach this server when
+	// it isn't directly reachable at its public address (e.g., when behind a NAT). When nil,
+	// peers connect to the public address directly.
+	RelayAddr *net.TCPAddr
+
+	// RelayID is the peer ID of the relay server at RelayAddr. When not nil, this server only
+	// registers with a relay presenting that peer ID.
+	RelayID id.ID
+
+	// LocalRelayPort is the local port the relay server listens to when this server relays
+	// connections for peers that aren't directly reachable. When zero, it doesn't relay.
+	LocalRelayPort int
+
+	// LocalGatewayPort is the local port the HTTP/JSON gateway listens to. When zero, the gateway
+	// isn't served.
+	LocalGatewayPort int
+
+	// Relay defines parameters for relaying connections, both to and from this server.
+	Relay *relay.Parameters
+
 	// OrgID is the organization ID of the peer, if one exists.
 	OrgID ecid.ID
 
@@ -89,6 +127,9 @@ type Config struct {
 	// Replicate defines parameters for replications the server performs.
 	Replicate *replicate.Parameters
 
+	// Refresh defines parameters for the server's routing table refreshes.
+	Refresh *refresh.Parameters
+
 	// SubscribeTo defines parameters for subscriptions to other peers.
 	SubscribeTo *subscribe.ToParameters
 
@@ -103,6 +144,39 @@ type Config struct {
 
 	// LogLevel is the log level
 	LogLevel zapcore.Level
+
+	// ClockSkew is the max difference between a requester's and the server's clocks tolerated
+	// when checking request signatures.
+	ClockSkew time.Duration
+
+	// ReplayCacheMemory is the max memory in bytes used to remember recent request IDs to reject
+	// replayed requests. When full, the request IDs expiring soonest are forgotten.
+	ReplayCacheMemory uint
+
+	// TrustListFile is the JSON file with the organizations and peers treated as known. When
+	// empty, all peers are treated as known.
+	TrustListFile string
+
+	// LimitsFile is the JSON file with the endpoint authorizations and rate limits for known and
+	// unknown peers. Authorizations and limits it doesn't mention keep their defaults. When
+	// empty, only the defaults are used.
+	LimitsFile string
+
+	// CertificateFile is the file with the organization certificate for this peer's public key
+	// that it presents with its requests. When empty, no certificate is presented.
+	CertificateFile string
+
+	// RequireCertificates determines whether the server refuses requests from peers without a
+	// valid certificate from an organization in the trust list.
+	RequireCertificates bool
+
+	// DialOptions are extra options used when connecting to other peers, e.g., client
+	// interceptors. Usually only set in tests.
+	DialOptions []grpc.DialOption
+
+	// WrapListener optionally wraps the listener accepting connections to this server. Usually
+	// only set in tests.
+	WrapListener func(net.Listener) net.Listener
 }
 
 // NewDefaultConfig returns a reasonable default server configuration.
@@ -126,9 +200,13 @@ func NewDefaultConfig() *Config {
 	config.WithDefaultSubscribeTo()
 	config.WithDefaultSubscribeFrom()
 	config.WithDefaultReplicate()
+	config.WithDefaultRefresh()
+	config.WithDefaultRelay()
 	config.WithDefaultReportMetrics()
 	config.WithDefaultProfile()
 	config.WithDefaultLogLevel()
+	config.WithDefaultClockSkew()
+	config.WithDefaultReplayCacheMemory()
 
 	return config
 }
@@ -210,6 +288,57 @@ func (c *Config) WithPublicName(publicName string) *Config {
 	return c
 }
 
+// WithAdvertisedAddrs sets the other addresses peers may reach this server at, which may be
+// empty.
+func (c *Config) WithAdvertisedAddrs(advertisedAddrs []string) *Config {
+	c.AdvertisedAddrs = advertisedAddrs
+	return c
+}
+
+// WithRelayAddr sets the address of the relay through which peers reach this server, which may
+// be nil when the server is directly reachable.
+func (c *Config) WithRelayAddr(relayAddr *net.TCPAddr) *Config {
+	c.RelayAddr = relayAddr
+	return c
+}
+
+// WithRelayID sets the peer ID of the relay through which peers reach this server, which may be
+// nil to register with whichever relay is at the relay address.
+func (c *Config) WithRelayID(relayID id.ID) *Config {
+	c.RelayID = relayID
+	return c
+}
+
+// WithLocalRelayPort sets the local port the relay server listens to, which may be zero to not
+// relay connections for other peers.
+func (c *Config) WithLocalRelayPort(localRelayPort int) *Config {
+	c.LocalRelayPort = localRelayPort
+	return c
+}
+
+// WithLocalGatewayPort sets the local port the HTTP/JSON gateway listens to, which may be zero to
+// not serve the gateway.
+func (c *Config) WithLocalGatewayPort(localGatewayPort int) *Config {
+	c.LocalGatewayPort = localGatewayPort
+	return c
+}
+
+// WithRelay sets the relay parameters to the given value or the default if it is nil.
+func (c *Config) WithRelay(params *relay.Parameters) *Config {
+	if params == nil {
+		return c.WithDefaultRelay()
+	}
+	c.Relay = params
+	return c
+}
+
+// WithDefaultRelay sets the relay parameters to their default values specified in the relay
+// package.
+func (c *Config) WithDefaultRelay() *Config {
+	c.Relay = relay.NewDefaultParameters()
+	return c
+}
+
 // WithOrgID sets the organization ID.
 func (c *Config) WithOrgID(orgID ecid.ID) *Config {
 	c.OrgID = orgID
@@ -387,10 +516,27 @@ func (c *Config) WithDefaultReplicate() *Config {
 	return c
 }
 
+// WithRefresh sets the routing table refresh parameters to the given value or the default if it
+// is nil.
+func (c *Config) WithRefresh(params *refresh.Parameters) *Config {
+	if params == nil {
+		return c.WithDefaultRefresh()
+	}
+	c.Refresh = params
+	return c
+}
+
+// WithDefaultRefresh sets the routing table refresh parameters to the default.
+func (c *Config) WithDefaultRefresh() *Config {
+	c.Refresh = refresh.NewDefaultParameters()
+	return c
+}
+
 // WithDefaultReportMetrics sets the default state for whether to report metrics.
 func (c *Config) WithDefaultReportMetrics() *Config {
 	c.ReportMetrics = true
 	c.Replicate.ReportMetrics = true
+	c.Refresh.ReportMetrics = true
 	return c
 }
 
@@ -398,6 +544,7 @@ func (c *Config) WithDefaultReportMetrics() *Config {
 func (c *Config) WithReportMetrics(reportMetrics bool) *Config {
 	c.ReportMetrics = reportMetrics
 	c.Replicate.ReportMetrics = reportMetrics
+	c.Refresh.ReportMetrics = reportMetrics
 	return c
 }
 
@@ -429,6 +576,77 @@ func (c *Config) WithDefaultLogLevel() *Config {
 	return c
 }
 
+// WithClockSkew sets the tolerated clock skew to the given value or to the default if the given
+// value is zero.
+func (c *Config) WithClockSkew(clockSkew time.Duration) *Config {
+	if clockSkew == 0 {
+		return c.WithDefaultClockSkew()
+	}
+	c.ClockSkew = clockSkew
+	return c
+}
+
+// WithDefaultClockSkew sets the tolerated clock skew to the default value.
+func (c *Config) WithDefaultClockSkew() *Config {
+	c.ClockSkew = DefaultClockSkew
+	return c
+}
+
+// WithReplayCacheMemory sets the max memory in bytes used to remember recent request IDs to the
+// given value or to the default if the given value is zero.
+func (c *Config) WithReplayCacheMemory(maxMemory uint) *Config {
+	if maxMemory == 0 {
+		return c.WithDefaultReplayCacheMemory()
+	}
+	c.ReplayCacheMemory = maxMemory
+	return c
+}
+
+// WithDefaultReplayCacheMemory sets the max memory in bytes used to remember recent request IDs
+// to the default value.
+func (c *Config) WithDefaultReplayCacheMemory() *Config {
+	c.ReplayCacheMemory = DefaultReplayCacheMemory
+	return c
+}
+
+// WithTrustListFile sets the trust list file, which may be empty to treat all peers as known.
+func (c *Config) WithTrustListFile(trustListFile string) *Config {
+	c.TrustListFile = trustListFile
+	return c
+}
+
+// WithLimitsFile sets the limits file, which may be empty to use the default authorizations and
+// limits.
+func (c *Config) WithLimitsFile(limitsFile string) *Config {
+	c.LimitsFile = limitsFile
+	return c
+}
+
+// WithCertificateFile sets the certificate file, which may be empty to present no certificate.
+func (c *Config) WithCertificateFile(certificateFile string) *Config {
+	c.CertificateFile = certificateFile
+	return c
+}
+
+// WithRequireCertificates sets whether to refuse requests from peers without a valid certificate
+// from a trusted organization.
+func (c *Config) WithRequireCertificates(requireCertificates bool) *Config {
+	c.RequireCertificates = requireCertificates
+	return c
+}
+
+// WithDialOptions sets the extra options used when connecting to other peers.
+func (c *Config) WithDialOptions(opts ...grpc.DialOption) *Config {
+	c.DialOptions = opts
+	return c
+}
+
+// WithWrapListener sets the function wrapping the server's listener.
+func (c *Config) WithWrapListener(wrap func(net.Listener) net.Listener) *Config {
+	c.WrapListener = wrap
+	return c
+}
+
 // NameFromAddr gives the local name (on the host) of the node using the NodeIndex
 func NameFromAddr(localAddr fmt.Stringer) string {
 	addrHash := sha256.Sum256([]byte(localAddr.String()))
@@ -3,16 +3,21 @@ package server
 import (
 	"net"
 	"testing"
+	"time"
 
+	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/common/parse"
 	"github.com/drausin/libri/libri/common/subscribe"
+	"github.com/drausin/libri/libri/librarian/relay"
 	"github.com/drausin/libri/libri/librarian/server/introduce"
+	"github.com/drausin/libri/libri/librarian/server/refresh"
 	"github.com/drausin/libri/libri/librarian/server/replicate"
 	"github.com/drausin/libri/libri/librarian/server/routing"
 	"github.com/drausin/libri/libri/librarian/server/search"
 	"github.com/drausin/libri/libri/librarian/server/store"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -31,6 +36,9 @@ func TestDefaultConfig(t *testing.T) {
 	assert.NotEmpty(t, c.SubscribeTo)
 	assert.NotEmpty(t, c.SubscribeFrom)
 	assert.NotEmpty(t, c.Replicate)
+	assert.NotEmpty(t, c.Refresh)
+	assert.NotEmpty(t, c.ClockSkew)
+	assert.NotEmpty(t, c.ReplayCacheMemory)
 }
 
 func TestConfig_WithLocalPort(t *testing.T) {
@@ -172,17 +180,30 @@ func TestConfig_WithReplicate(t *testing.T) {
 	)
 }
 
+func TestConfig_WithRefresh(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultRefresh()
+	assert.Equal(t, c1.Refresh, c2.WithRefresh(nil).Refresh)
+	assert.NotEqual(t,
+		c1.Refresh,
+		c3.WithRefresh(&refresh.Parameters{Interval: time.Minute}).Refresh,
+	)
+}
+
 func TestConfig_WithReportMetrics(t *testing.T) {
 	c1, c2, c3 := NewDefaultConfig(), NewDefaultConfig(), NewDefaultConfig()
 	c1.WithDefaultReportMetrics()
 	assert.True(t, c1.ReportMetrics)
 	assert.True(t, c1.Replicate.ReportMetrics)
+	assert.True(t, c1.Refresh.ReportMetrics)
 	c2.WithReportMetrics(true)
 	assert.True(t, c2.ReportMetrics)
 	assert.True(t, c2.Replicate.ReportMetrics)
+	assert.True(t, c2.Refresh.ReportMetrics)
 	c3.WithReportMetrics(false)
 	assert.False(t, c3.ReportMetrics)
 	assert.False(t, c3.Replicate.ReportMetrics)
+	assert.False(t, c3.Refresh.ReportMetrics)
 }
 
 func TestConfig_WithProfile(t *testing.T) {
@@ -204,3 +225,95 @@ func TestConfig_WithLogLevel(t *testing.T) {
 		c3.WithLogLevel(zapcore.DebugLevel).LogLevel,
 	)
 }
+
+func TestConfig_WithDialOptions(t *testing.T) {
+	c1, c2 := &Config{}, &Config{}
+	assert.Nil(t, c1.DialOptions)
+	c2.WithDialOptions(grpc.WithBlock(), grpc.WithInsecure())
+	assert.Len(t, c2.DialOptions, 2)
+}
+
+func TestConfig_WithWrapListener(t *testing.T) {
+	c1, c2 := &Config{}, &Config{}
+	assert.Nil(t, c1.WrapListener)
+	wrapped := &net.TCPListener{}
+	c2.WithWrapListener(func(net.Listener) net.Listener { return wrapped })
+	assert.Equal(t, wrapped, c2.WrapListener(nil))
+}
+
+func TestConfig_WithClockSkew(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultClockSkew()
+	assert.Equal(t, c1.ClockSkew, c2.WithClockSkew(0).ClockSkew)
+	assert.NotEqual(t, c1.ClockSkew, c3.WithClockSkew(5*time.Second).ClockSkew)
+}
+
+func TestConfig_WithReplayCacheMemory(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultReplayCacheMemory()
+	assert.Equal(t, c1.ReplayCacheMemory, c2.WithReplayCacheMemory(0).ReplayCacheMemory)
+	assert.NotEqual(t, c1.ReplayCacheMemory, c3.WithReplayCacheMemory(2).ReplayCacheMemory)
+}
+
+func TestConfig_WithTrustListFile(t *testing.T) {
+	c := &Config{}
+	assert.Equal(t, "", c.TrustListFile)
+	assert.Equal(t, "trust.json", c.WithTrustListFile("trust.json").TrustListFile)
+}
+
+func TestConfig_WithLimitsFile(t *testing.T) {
+	c := &Config{}
+	assert.Equal(t, "", c.LimitsFile)
+	assert.Equal(t, "limits.json", c.WithLimitsFile("limits.json").LimitsFile)
+}
+
+func TestConfig_WithCertificateFile(t *testing.T) {
+	c := &Config{}
+	assert.Equal(t, "", c.CertificateFile)
+	assert.Equal(t, "peer.cert", c.WithCertificateFile("peer.cert").CertificateFile)
+}
+
+func TestConfig_WithRequireCertificates(t *testing.T) {
+	c := &Config{}
+	assert.False(t, c.RequireCertificates)
+	assert.True(t, c.WithRequireCertificates(true).RequireCertificates)
+}
+
+func TestConfig_WithRelayAddr(t *testing.T) {
+	c := &Config{}
+	assert.Nil(t, c.RelayAddr)
+	relayAddr := &net.TCPAddr{IP: net.ParseIP("10.11.12.13"), Port: 20400}
+	assert.Equal(t, relayAddr, c.WithRelayAddr(relayAddr).RelayAddr)
+}
+
+func TestConfig_WithRelayID(t *testing.T) {
+	c := &Config{}
+	assert.Nil(t, c.RelayID)
+	assert.Equal(t, id.FromInt64(1), c.WithRelayID(id.FromInt64(1)).RelayID)
+}
+
+func TestConfig_WithLocalGatewayPort(t *testing.T) {
+	c := &Config{}
+	assert.Zero(t, c.LocalGatewayPort)
+	assert.Equal(t, 20500, c.WithLocalGatewayPort(20500).LocalGatewayPort)
+}
+
+func TestConfig_WithLocalRelayPort(t *testing.T) {
+	c := &Config{}
+	assert.Zero(t, c.LocalRelayPort)
+	assert.Equal(t, 20400, c.WithLocalRelayPort(20400).LocalRelayPort)
+}
+
+func TestConfig_WithRelay(t *testing.T) {
+	c1, c2, c3 := &Config{}, &Config{}, &Config{}
+	c1.WithDefaultRelay()
+	assert.Equal(t, c1.Relay, c2.WithRelay(nil).Relay)
+	assert.NotEqual(t, c1.Relay, c3.WithRelay(&relay.Parameters{MaxPeers: 1}).Relay)
+}
+
+func TestConfig_WithAdvertisedAddrs(t *testing.T) {
+	c := &Config{}
+	assert.Empty(t, c.AdvertisedAddrs)
+	addrs := []string{"[2001:db8::1]:20100", "librarian.example.com:20100"}
+	assert.Equal(t, addrs, c.WithAdvertisedAddrs(addrs).AdvertisedAddrs)
+}
@@ -0,0 +1,387 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/drausin/libri/libri/common/storage"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// GatewaySignatureHeader is the HTTP header with the requester's signature of a gateway
+	// request.
+	GatewaySignatureHeader = "Libri-Signature"
+
+	// GatewayOrgSignatureHeader is the HTTP header with the requester organization's signature of
+	// a gateway request, which is empty or absent when the request has no organization public
+	// key.
+	GatewayOrgSignatureHeader = "Libri-Org-Signature"
+
+	// GatewayGetPath is the gateway path of Get requests.
+	GatewayGetPath = "/v1/get"
+
+	// GatewayPutPath is the gateway path of Put requests.
+	GatewayPutPath = "/v1/put"
+
+	// GatewayFindPath is the gateway path of Find requests.
+	GatewayFindPath = "/v1/find"
+
+	// GatewayVerifyPath is the gateway path of Verify requests.
+	GatewayVerifyPath = "/v1/verify"
+
+	// GatewaySubscribePath is the gateway path of Subscribe requests.
+	GatewaySubscribePath = "/v1/subscribe"
+
+	// maxGatewayBodySize is the max size of a gateway request body, allowing for the base-64
+	// encoding of a max-size document value.
+	maxGatewayBodySize = 2 * storage.MaxValueLength
+
+	// gatewayReadHeaderTimeout is the max time to read the headers of a gateway request.
+	gatewayReadHeaderTimeout = 10 * time.Second
+
+	// gatewayReadTimeout is the max time to read an entire gateway request, including its body.
+	// There is no write timeout since Subscribe responses stream indefinitely.
+	gatewayReadTimeout = 30 * time.Second
+
+	// gatewayIdleTimeout is the max time to keep an idle gateway connection open between
+	// requests.
+	gatewayIdleTimeout = 2 * time.Minute
+
+	// maxGatewaySubscriptions is the max number of concurrent Subscribe streams from the
+	// gateway, like maxConcurrentStreams for gRPC streams.
+	maxGatewaySubscriptions = 32
+
+	jsonContentType        = "application/json"
+	eventStreamContentType = "text/event-stream"
+)
+
+var (
+	errMissingGatewayMetadata = errors.New("missing request metadata")
+	errGatewayStreaming       = errors.New("response writer does not support streaming")
+	errGatewaySubscriptions   = errors.New("too many concurrent gateway subscriptions")
+)
+
+// metadataRequest is a request with metadata, which all the api requests are.
+type metadataRequest interface {
+	proto.Message
+	GetMetadata() *api.RequestMetadata
+}
+
+// gatewayError is the JSON body of a gateway error response.
+type gatewayError struct {
+	// Code is the name of the gRPC status code of the error, e.g., "InvalidArgument".
+	Code string `json:"code"`
+
+	// Message describes the error.
+	Message string `json:"message"`
+}
+
+// NewGateway returns an http.Handler exposing the Get, Put, Find, Verify, and Subscribe endpoints
+// of the librarian server as REST+JSON, for clients that can't easily use gRPC.
+//
+// Get, Put, Find, and Verify requests are POSTed to their paths (e.g., /v1/get) with the JSON
+// encoding of the api request message (e.g., api.GetRequest) as the body. Field names are the
+// proto field names (e.g., "request_id"), and bytes fields are base-64 encoded. The response is
+// the JSON encoding of the api response message.
+//
+// Subscribe requests are POSTed to /v1/subscribe with the JSON api.SubscribeRequest body. The
+// response is a stream of server-sent events, each with the JSON api.SubscribeResponse as its
+// data. Browser clients must read this stream with fetch rather than EventSource, since
+// EventSource can only send GET requests and reconnects with the same (now replayed) request.
+// At most 32 Subscribe streams are served at once; requests beyond that get a ResourceExhausted
+// error.
+//
+// Requests are signed like gRPC requests signed with client.Signer, except that the signatures
+// are over the request body rather than the protobuf encoding of the request message. Each
+// signature is an ES256 JSON web token (JWT) with the claims in client.Claims:
+//
+//   - hash: the base-64-url encoding (with padding) of the SHA-256 hash of the exact request body
+//     bytes
+//   - iat: the epoch time (in seconds) the signature was issued
+//   - exp: the epoch time (in seconds) the signature expires, at most client.MaxSignatureTTL after
+//     iat
+//   - nonce: the base-64-url encoding (with padding) of 16 random bytes
+//
+// The requester signs with the private key of the request metadata "pub_key" and puts the JWT in
+// the Libri-Signature header. When the request metadata has an "org_pub_key", the organization
+// also signs with its private key and puts its JWT in the Libri-Org-Signature header. The request
+// metadata "request_id" must be 32 random bytes unique to each request, since replayed request
+// IDs are rejected. client.SignHash creates these signatures in Go.
+//
+// Errors have the HTTP status corresponding to the gRPC status code the librarian returns and a
+// JSON body with the "code" name and "message".
+func NewGateway(lib api.LibrarianServer, logger *zap.Logger) http.Handler {
+	g := newGateway(lib, logger, maxGatewaySubscriptions)
+	mux := http.NewServeMux()
+	mux.HandleFunc(GatewayGetPath, g.unary(
+		func() metadataRequest { return &api.GetRequest{} },
+		func(ctx context.Context, rq metadataRequest) (proto.Message, error) {
+			return lib.Get(ctx, rq.(*api.GetRequest))
+		},
+	))
+	mux.HandleFunc(GatewayPutPath, g.unary(
+		func() metadataRequest { return &api.PutRequest{} },
+		func(ctx context.Context, rq metadataRequest) (proto.Message, error) {
+			return lib.Put(ctx, rq.(*api.PutRequest))
+		},
+	))
+	mux.HandleFunc(GatewayFindPath, g.unary(
+		func() metadataRequest { return &api.FindRequest{} },
+		func(ctx context.Context, rq metadataRequest) (proto.Message, error) {
+			return lib.Find(ctx, rq.(*api.FindRequest))
+		},
+	))
+	mux.HandleFunc(GatewayVerifyPath, g.unary(
+		func() metadataRequest { return &api.VerifyRequest{} },
+		func(ctx context.Context, rq metadataRequest) (proto.Message, error) {
+			return lib.Verify(ctx, rq.(*api.VerifyRequest))
+		},
+	))
+	mux.HandleFunc(GatewaySubscribePath, g.serveSubscribe)
+	return mux
+}
+
+type gateway struct {
+	lib           api.LibrarianServer
+	logger        *zap.Logger
+	marshaler     *jsonpb.Marshaler
+	unmarshaler   *jsonpb.Unmarshaler
+	subscriptions chan struct{}
+}
+
+func newGateway(lib api.LibrarianServer, logger *zap.Logger, maxSubscriptions int) *gateway {
+	return &gateway{
+		lib:           lib,
+		logger:        logger,
+		marshaler:     &jsonpb.Marshaler{OrigName: true},
+		unmarshaler:   &jsonpb.Unmarshaler{},
+		subscriptions: make(chan struct{}, maxSubscriptions),
+	}
+}
+
+// unary returns a handler of POSTed requests created by newRq and handled by call.
+func (g *gateway) unary(
+	newRq func() metadataRequest,
+	call func(ctx context.Context, rq metadataRequest) (proto.Message, error),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			g.writeErr(w, status.Error(codes.Unimplemented, "method must be POST"))
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxGatewayBodySize))
+		if err != nil {
+			g.writeErr(w, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+		rq := newRq()
+		ctx, err := g.parseRequest(r.Context(), body, rq, r.Header.Get(GatewaySignatureHeader),
+			r.Header.Get(GatewayOrgSignatureHeader))
+		if err != nil {
+			g.writeErr(w, err)
+			return
+		}
+		rp, err := call(ctx, rq)
+		if err != nil {
+			g.writeErr(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", jsonContentType)
+		if err = g.marshaler.Marshal(w, rp); err != nil {
+			g.logger.Info("error writing gateway response", zap.Error(err))
+		}
+	}
+}
+
+func (g *gateway) serveSubscribe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		g.writeErr(w, status.Error(codes.Unimplemented, "method must be POST"))
+		return
+	}
+	select {
+	case g.subscriptions <- struct{}{}:
+		defer func() { <-g.subscriptions }()
+	default:
+		g.writeErr(w, status.Error(codes.ResourceExhausted, errGatewaySubscriptions.Error()))
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxGatewayBodySize))
+	if err != nil {
+		g.writeErr(w, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		g.writeErr(w, status.Error(codes.Internal, errGatewayStreaming.Error()))
+		return
+	}
+	rq := &api.SubscribeRequest{}
+	ctx, err := g.parseRequest(r.Context(), body, rq, r.Header.Get(GatewaySignatureHeader),
+		r.Header.Get(GatewayOrgSignatureHeader))
+	if err != nil {
+		g.writeErr(w, err)
+		return
+	}
+	from := &sseSubscribeServer{
+		ctx:       ctx,
+		w:         w,
+		flusher:   flusher,
+		marshaler: g.marshaler,
+	}
+	err = g.lib.Subscribe(rq, from)
+	if err != nil && !from.started {
+		g.writeErr(w, err)
+		return
+	}
+	if err != nil {
+		from.sendErr(err)
+		return
+	}
+	from.start()
+}
+
+// parseRequest unmarshals the JSON body into the request and returns the context with its
+// signatures, which are over the body.
+func (g *gateway) parseRequest(
+	ctx context.Context, body []byte, rq metadataRequest, sig, orgSig string,
+) (context.Context, error) {
+	if err := g.unmarshaler.Unmarshal(bytes.NewReader(body), rq); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if rq.GetMetadata() == nil {
+		return nil, status.Error(codes.InvalidArgument, errMissingGatewayMetadata.Error())
+	}
+	ctx = client.NewIncomingSignatureContext(ctx, sig, orgSig)
+	return newBodyHashContext(ctx, sha256.Sum256(body)), nil
+}
+
+func (g *gateway) writeErr(w http.ResponseWriter, err error) {
+	st, _ := status.FromError(err)
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(gatewayHTTPStatus(st.Code()))
+	err = json.NewEncoder(w).Encode(&gatewayError{Code: st.Code().String(), Message: st.Message()})
+	if err != nil {
+		g.logger.Info("error writing gateway error response", zap.Error(err))
+	}
+}
+
+// gatewayHTTPStatus returns the HTTP status corresponding to the gRPC status code.
+func gatewayHTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusMethodNotAllowed
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// sseSubscribeServer implements api.Librarian_SubscribeServer by writing each response as a
+// server-sent event. The response headers are written with the first event, so errors before
+// then can still be returned with an error status.
+type sseSubscribeServer struct {
+	ctx       context.Context
+	w         http.ResponseWriter
+	flusher   http.Flusher
+	marshaler *jsonpb.Marshaler
+	started   bool
+}
+
+func (s *sseSubscribeServer) Send(rp *api.SubscribeResponse) error {
+	if err := s.ctx.Err(); err != nil {
+		// requester has disconnected
+		return err
+	}
+	js, err := s.marshaler.MarshalToString(rp)
+	if err != nil {
+		return err
+	}
+	s.start()
+	if _, err = fmt.Fprintf(s.w, "data: %s\n\n", js); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// start writes the response headers if they haven't been written already.
+func (s *sseSubscribeServer) start() {
+	if s.started {
+		return
+	}
+	s.w.Header().Set("Content-Type", eventStreamContentType)
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.WriteHeader(http.StatusOK)
+	s.flusher.Flush()
+	s.started = true
+}
+
+// sendErr sends the error as a final "error" event.
+func (s *sseSubscribeServer) sendErr(err error) {
+	st, _ := status.FromError(err)
+	js, err := json.Marshal(&gatewayError{Code: st.Code().String(), Message: st.Message()})
+	if err != nil {
+		return
+	}
+	if _, err = fmt.Fprintf(s.w, "event: error\ndata: %s\n\n", js); err == nil {
+		s.flusher.Flush()
+	}
+}
+
+func (s *sseSubscribeServer) Context() context.Context {
+	return s.ctx
+}
+
+// the stubs below are just to satisfy the Librarian_SubscribeServer interface
+
+func (s *sseSubscribeServer) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func (s *sseSubscribeServer) SendHeader(metadata.MD) error {
+	return nil
+}
+
+func (s *sseSubscribeServer) SetTrailer(metadata.MD) {}
+
+func (s *sseSubscribeServer) SendMsg(m interface{}) error {
+	return nil
+}
+
+func (s *sseSubscribeServer) RecvMsg(m interface{}) error {
+	return nil
+}
@@ -0,0 +1,423 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGateway_unary_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	value, key := api.NewTestDocument(rng)
+	macKey := api.RandBytes(rng, 32)
+	lib := newFixedGatewayLibrarian(t, value)
+	g := httptest.NewServer(NewGateway(lib, zap.NewNop()))
+	defer g.Close()
+
+	cases := []struct {
+		path string
+		rq   proto.Message
+		rp   proto.Message
+	}{
+		{GatewayGetPath, client.NewGetRequest(peerID, orgID, key), &api.GetResponse{}},
+		{GatewayPutPath, client.NewPutRequest(peerID, orgID, key, value), &api.PutResponse{}},
+		{GatewayFindPath, client.NewFindRequest(peerID, orgID, key, 8), &api.FindResponse{}},
+		{GatewayVerifyPath, client.NewVerifyRequest(peerID, orgID, key, macKey, 8),
+			&api.VerifyResponse{}},
+	}
+	for _, c := range cases {
+		resp := postSignedGatewayRequest(t, g.URL+c.path, c.rq, peerID, orgID)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, c.path)
+		assert.Equal(t, jsonContentType, resp.Header.Get("Content-Type"))
+		assert.Nil(t, jsonpb.Unmarshal(resp.Body, c.rp), c.path)
+		assert.Nil(t, resp.Body.Close())
+	}
+	assert.True(t, proto.Equal(value, cases[0].rp.(*api.GetResponse).Value))
+	assert.Equal(t, api.PutOperation_STORED, cases[1].rp.(*api.PutResponse).Operation)
+	assert.True(t, proto.Equal(value, cases[2].rp.(*api.FindResponse).Value))
+	assert.Equal(t, macKey, cases[3].rp.(*api.VerifyResponse).Mac)
+
+	// without org
+	rq := client.NewGetRequest(peerID, nil, key)
+	resp := postSignedGatewayRequest(t, g.URL+GatewayGetPath, rq, peerID, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, resp.Body.Close())
+}
+
+func TestGateway_unary_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	value, key := api.NewTestDocument(rng)
+	lib := newFixedGatewayLibrarian(t, value)
+	g := httptest.NewServer(NewGateway(lib, zap.NewNop()))
+	defer g.Close()
+
+	// GET instead of POST
+	resp, err := http.Get(g.URL + GatewayGetPath)
+	assert.Nil(t, err)
+	checkGatewayErr(t, resp, http.StatusMethodNotAllowed, codes.Unimplemented)
+
+	// bad JSON
+	resp, err = http.Post(g.URL+GatewayGetPath, jsonContentType, strings.NewReader("{bad"))
+	assert.Nil(t, err)
+	checkGatewayErr(t, resp, http.StatusBadRequest, codes.InvalidArgument)
+
+	// missing metadata
+	resp, err = http.Post(g.URL+GatewayGetPath, jsonContentType, strings.NewReader("{}"))
+	assert.Nil(t, err)
+	checkGatewayErr(t, resp, http.StatusBadRequest, codes.InvalidArgument)
+
+	// signature over different body
+	rq := client.NewGetRequest(peerID, orgID, key)
+	body, err := (&jsonpb.Marshaler{}).MarshalToString(rq)
+	assert.Nil(t, err)
+	httpRq, err := http.NewRequest(http.MethodPost, g.URL+GatewayGetPath,
+		strings.NewReader(body))
+	assert.Nil(t, err)
+	sig, err := client.SignHash(peerID.Key(), sha256.Sum256([]byte(body+" ")))
+	assert.Nil(t, err)
+	httpRq.Header.Set(GatewaySignatureHeader, sig)
+	resp, err = http.DefaultClient.Do(httpRq)
+	assert.Nil(t, err)
+	checkGatewayErr(t, resp, http.StatusBadRequest, codes.InvalidArgument)
+
+	// missing org signature
+	rq = client.NewGetRequest(peerID, orgID, key)
+	resp = postSignedGatewayRequest(t, g.URL+GatewayGetPath, rq, peerID, nil)
+	checkGatewayErr(t, resp, http.StatusBadRequest, codes.InvalidArgument)
+
+	// replayed request
+	rq = client.NewGetRequest(peerID, orgID, key)
+	resp = postSignedGatewayRequest(t, g.URL+GatewayGetPath, rq, peerID, orgID)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, resp.Body.Close())
+	resp = postSignedGatewayRequest(t, g.URL+GatewayGetPath, rq, peerID, orgID)
+	checkGatewayErr(t, resp, http.StatusBadRequest, codes.InvalidArgument)
+
+	// librarian error
+	lib.err = status.Error(codes.Unavailable, codes.Unavailable.String())
+	rq = client.NewGetRequest(peerID, orgID, key)
+	resp = postSignedGatewayRequest(t, g.URL+GatewayGetPath, rq, peerID, orgID)
+	checkGatewayErr(t, resp, http.StatusServiceUnavailable, codes.Unavailable)
+}
+
+func TestGateway_signedBody(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID := ecid.NewPseudoRandom(rng)
+	_, key := api.NewTestDocument(rng)
+
+	// signatures are over the request bodies, not the protobuf encodings of the requests
+	rq := client.NewGetRequest(peerID, nil, key)
+	rqBytes, err := proto.Marshal(rq)
+	assert.Nil(t, err)
+	sig, err := client.SignHash(peerID.Key(), sha256.Sum256(rqBytes))
+	assert.Nil(t, err)
+
+	body := new(bytes.Buffer)
+	assert.Nil(t, (&jsonpb.Marshaler{}).Marshal(body, rq))
+	lib := newFixedGatewayLibrarian(t, nil)
+	g := httptest.NewServer(NewGateway(lib, zap.NewNop()))
+	defer g.Close()
+	httpRq, err := http.NewRequest(http.MethodPost, g.URL+GatewayGetPath, body)
+	assert.Nil(t, err)
+	httpRq.Header.Set(GatewaySignatureHeader, sig)
+	resp, err := http.DefaultClient.Do(httpRq)
+	assert.Nil(t, err)
+	checkGatewayErr(t, resp, http.StatusBadRequest, codes.InvalidArgument)
+}
+
+func TestGateway_subscribe_ok(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	value, _ := api.NewTestDocument(rng)
+	lib := newFixedGatewayLibrarian(t, value)
+	lib.pubs = []*api.Publication{api.NewTestPublication(rng), api.NewTestPublication(rng)}
+	g := httptest.NewServer(NewGateway(lib, zap.NewNop()))
+	defer g.Close()
+	sub := &api.Subscription{
+		AuthorPublicKeys: &api.BloomFilter{Encoded: api.RandBytes(rng, 32)},
+		ReaderPublicKeys: &api.BloomFilter{Encoded: api.RandBytes(rng, 32)},
+	}
+
+	// POST
+	rq := client.NewSubscribeRequest(peerID, orgID, sub)
+	resp := postSignedGatewayRequest(t, g.URL+GatewaySubscribePath, rq, peerID, orgID)
+	checkGatewaySubscribe(t, resp, lib.pubs)
+
+	// GET, as from browser EventSource, isn't supported
+	resp, err := http.Get(g.URL + GatewaySubscribePath)
+	assert.Nil(t, err)
+	checkGatewayErr(t, resp, http.StatusMethodNotAllowed, codes.Unimplemented)
+}
+
+func TestGateway_subscribe_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	value, _ := api.NewTestDocument(rng)
+	lib := newFixedGatewayLibrarian(t, value)
+	g := httptest.NewServer(NewGateway(lib, zap.NewNop()))
+	defer g.Close()
+	sub := &api.Subscription{
+		AuthorPublicKeys: &api.BloomFilter{Encoded: api.RandBytes(rng, 32)},
+		ReaderPublicKeys: &api.BloomFilter{Encoded: api.RandBytes(rng, 32)},
+	}
+
+	// bad method
+	httpRq, err := http.NewRequest(http.MethodPut, g.URL+GatewaySubscribePath, nil)
+	assert.Nil(t, err)
+	resp, err := http.DefaultClient.Do(httpRq)
+	assert.Nil(t, err)
+	checkGatewayErr(t, resp, http.StatusMethodNotAllowed, codes.Unimplemented)
+
+	// unsigned
+	resp, err = http.Post(g.URL+GatewaySubscribePath, jsonContentType,
+		strings.NewReader(`{"metadata": {}}`))
+	assert.Nil(t, err)
+	checkGatewayErr(t, resp, http.StatusBadRequest, codes.InvalidArgument)
+
+	// error before sending any publications
+	lib.err = status.Error(codes.ResourceExhausted, "too many subscriptions")
+	rq := client.NewSubscribeRequest(peerID, orgID, sub)
+	resp = postSignedGatewayRequest(t, g.URL+GatewaySubscribePath, rq, peerID, orgID)
+	checkGatewayErr(t, resp, http.StatusTooManyRequests, codes.ResourceExhausted)
+
+	// error after sending publications ends stream with error event
+	lib.pubs = []*api.Publication{api.NewTestPublication(rng)}
+	lib.err = status.Error(codes.Unavailable, codes.Unavailable.String())
+	rq = client.NewSubscribeRequest(peerID, orgID, sub)
+	resp = postSignedGatewayRequest(t, g.URL+GatewaySubscribePath, rq, peerID, orgID)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	events := readGatewayEvents(t, resp)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "error", events[1].event)
+	gErr := &gatewayError{}
+	assert.Nil(t, json.Unmarshal([]byte(events[1].data), gErr))
+	assert.Equal(t, codes.Unavailable.String(), gErr.Code)
+}
+
+func TestGateway_subscribe_tooMany(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	value, _ := api.NewTestDocument(rng)
+	lib := newFixedGatewayLibrarian(t, value)
+	lib.pubs = []*api.Publication{api.NewTestPublication(rng)}
+	g := newGateway(lib, zap.NewNop(), 1)
+	s := httptest.NewServer(http.HandlerFunc(g.serveSubscribe))
+	defer s.Close()
+	sub := &api.Subscription{
+		AuthorPublicKeys: &api.BloomFilter{Encoded: api.RandBytes(rng, 32)},
+		ReaderPublicKeys: &api.BloomFilter{Encoded: api.RandBytes(rng, 32)},
+	}
+
+	// another subscription is in progress
+	g.subscriptions <- struct{}{}
+	rq := client.NewSubscribeRequest(peerID, orgID, sub)
+	resp := postSignedGatewayRequest(t, s.URL, rq, peerID, orgID)
+	checkGatewayErr(t, resp, http.StatusTooManyRequests, codes.ResourceExhausted)
+
+	// once it ends, subscriptions are allowed again
+	<-g.subscriptions
+	rq = client.NewSubscribeRequest(peerID, orgID, sub)
+	resp = postSignedGatewayRequest(t, s.URL, rq, peerID, orgID)
+	checkGatewaySubscribe(t, resp, lib.pubs)
+	assert.Len(t, g.subscriptions, 0)
+}
+
+func TestGatewayHTTPStatus(t *testing.T) {
+	assert.Equal(t, http.StatusOK, gatewayHTTPStatus(codes.OK))
+	assert.Equal(t, http.StatusBadRequest, gatewayHTTPStatus(codes.InvalidArgument))
+	assert.Equal(t, http.StatusForbidden, gatewayHTTPStatus(codes.PermissionDenied))
+	assert.Equal(t, http.StatusTooManyRequests, gatewayHTTPStatus(codes.ResourceExhausted))
+	assert.Equal(t, http.StatusServiceUnavailable, gatewayHTTPStatus(codes.Unavailable))
+	assert.Equal(t, http.StatusInternalServerError, gatewayHTTPStatus(codes.Internal))
+	assert.Equal(t, http.StatusInternalServerError, gatewayHTTPStatus(codes.Unknown))
+}
+
+func postSignedGatewayRequest(
+	t *testing.T, url string, rq proto.Message, peerID, orgID ecid.ID,
+) *http.Response {
+	body, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(rq)
+	assert.Nil(t, err)
+	httpRq, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	assert.Nil(t, err)
+	hash := sha256.Sum256([]byte(body))
+	sig, err := client.SignHash(peerID.Key(), hash)
+	assert.Nil(t, err)
+	httpRq.Header.Set(GatewaySignatureHeader, sig)
+	if orgID != nil {
+		orgSig, err := client.SignHash(orgID.Key(), hash)
+		assert.Nil(t, err)
+		httpRq.Header.Set(GatewayOrgSignatureHeader, orgSig)
+	}
+	resp, err := http.DefaultClient.Do(httpRq)
+	assert.Nil(t, err)
+	return resp
+}
+
+func checkGatewayErr(t *testing.T, resp *http.Response, statusCode int, code codes.Code) {
+	assert.Equal(t, statusCode, resp.StatusCode)
+	gErr := &gatewayError{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(gErr))
+	assert.Equal(t, code.String(), gErr.Code)
+	assert.NotEmpty(t, gErr.Message)
+	assert.Nil(t, resp.Body.Close())
+}
+
+func checkGatewaySubscribe(t *testing.T, resp *http.Response, pubs []*api.Publication) {
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, eventStreamContentType, resp.Header.Get("Content-Type"))
+	events := readGatewayEvents(t, resp)
+	assert.Len(t, events, len(pubs))
+	for i, e := range events {
+		rp := &api.SubscribeResponse{}
+		assert.Nil(t, jsonpb.UnmarshalString(e.data, rp))
+		assert.True(t, proto.Equal(pubs[i], rp.Value))
+	}
+}
+
+type gatewayEvent struct {
+	event string
+	data  string
+}
+
+func readGatewayEvents(t *testing.T, resp *http.Response) []*gatewayEvent {
+	events := make([]*gatewayEvent, 0)
+	e := &gatewayEvent{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			events = append(events, e)
+			e = &gatewayEvent{}
+		}
+	}
+	assert.Nil(t, scanner.Err())
+	assert.Nil(t, resp.Body.Close())
+	return events
+}
+
+// fixedGatewayLibrarian implements api.LibrarianServer, verifying requests like the Librarian
+// does and then returning the fixed value or error.
+type fixedGatewayLibrarian struct {
+	rqv   RequestVerifier
+	value *api.Document
+	pubs  []*api.Publication
+	err   error
+}
+
+func newFixedGatewayLibrarian(t *testing.T, value *api.Document) *fixedGatewayLibrarian {
+	return &fixedGatewayLibrarian{
+		rqv:   newTestRequestVerifier(t),
+		value: value,
+	}
+}
+
+func (f *fixedGatewayLibrarian) check(ctx context.Context, rq proto.Message,
+	meta *api.RequestMetadata) error {
+	if err := f.rqv.Verify(ctx, rq, meta); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := f.rqv.CheckReplay(ctx, meta); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return f.err
+}
+
+func (f *fixedGatewayLibrarian) Introduce(ctx context.Context, rq *api.IntroduceRequest) (
+	*api.IntroduceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, codes.Unimplemented.String())
+}
+
+func (f *fixedGatewayLibrarian) Find(ctx context.Context, rq *api.FindRequest) (
+	*api.FindResponse, error) {
+	if err := f.check(ctx, rq, rq.Metadata); err != nil {
+		return nil, err
+	}
+	return &api.FindResponse{Metadata: newTestResponseMetadata(rq.Metadata), Value: f.value}, nil
+}
+
+func (f *fixedGatewayLibrarian) Verify(ctx context.Context, rq *api.VerifyRequest) (
+	*api.VerifyResponse, error) {
+	if err := f.check(ctx, rq, rq.Metadata); err != nil {
+		return nil, err
+	}
+	return &api.VerifyResponse{
+		Metadata: newTestResponseMetadata(rq.Metadata),
+		Mac:      rq.MacKey, // just echo MAC key
+	}, nil
+}
+
+func (f *fixedGatewayLibrarian) Store(ctx context.Context, rq *api.StoreRequest) (
+	*api.StoreResponse, error) {
+	return nil, status.Error(codes.Unimplemented, codes.Unimplemented.String())
+}
+
+func (f *fixedGatewayLibrarian) Get(ctx context.Context, rq *api.GetRequest) (
+	*api.GetResponse, error) {
+	if err := f.check(ctx, rq, rq.Metadata); err != nil {
+		return nil, err
+	}
+	return &api.GetResponse{Metadata: newTestResponseMetadata(rq.Metadata), Value: f.value}, nil
+}
+
+func (f *fixedGatewayLibrarian) Put(ctx context.Context, rq *api.PutRequest) (
+	*api.PutResponse, error) {
+	if err := f.check(ctx, rq, rq.Metadata); err != nil {
+		return nil, err
+	}
+	return &api.PutResponse{
+		Metadata:  newTestResponseMetadata(rq.Metadata),
+		Operation: api.PutOperation_STORED,
+		NReplicas: 3,
+	}, nil
+}
+
+func (f *fixedGatewayLibrarian) Subscribe(
+	rq *api.SubscribeRequest, from api.Librarian_SubscribeServer,
+) error {
+	if err := f.rqv.Verify(from.Context(), rq, rq.Metadata); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := f.rqv.CheckReplay(from.Context(), rq.Metadata); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	for _, pub := range f.pubs {
+		rp := &api.SubscribeResponse{
+			Metadata: newTestResponseMetadata(rq.Metadata),
+			Key:      id.NewPseudoRandom(rand.New(rand.NewSource(0))).Bytes(),
+			Value:    pub,
+		}
+		if err := from.Send(rp); err != nil {
+			return err
+		}
+	}
+	return f.err
+}
+
+func newTestResponseMetadata(rqMeta *api.RequestMetadata) *api.ResponseMetadata {
+	return &api.ResponseMetadata{RequestId: rqMeta.RequestId}
+}
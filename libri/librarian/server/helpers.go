@@ -3,8 +3,12 @@ package server
 import (
 	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/common/subscribe"
 	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/drausin/libri/libri/librarian/server/comm"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/routing"
 	"github.com/golang/protobuf/proto"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -14,8 +18,9 @@ import (
 )
 
 const (
-	invalidRequestMsg    = "invalid request"
-	requestNotAllowedMsg = "request not allowed"
+	invalidRequestMsg     = "invalid request"
+	requestNotAllowedMsg  = "request not allowed"
+	failedPreconditionMsg = "request conflicts with stored state"
 )
 
 // newStubPeerFromPublicKeyBytes creates a new stub peer with an ID coming from an ECDSA public key.
@@ -27,6 +32,48 @@ func newIDFromPublicKeyBytes(pubKeyBytes []byte) (id.ID, error) {
 	return id.FromPublicKey(pubKey), nil
 }
 
+// newKnower returns a Knower using the trust list in the given file and the organizations of peers
+// in orgs or one that knows all peers if the file is empty. The returned TrustKnower is nil when
+// not using a trust list.
+func newKnower(trustListFile string, orgs comm.PeerOrgs) (comm.Knower, comm.TrustKnower, error) {
+	if trustListFile == "" {
+		return comm.NewAlwaysKnower(), nil, nil
+	}
+	trust, err := comm.NewTrustKnower(trustListFile, orgs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return trust, trust, nil
+}
+
+// newAPISelf returns the api.PeerAddress the server advertises to other peers, including its
+// other addresses, its relay when it isn't directly reachable, and the certificate its signer
+// presents, if any.
+func newAPISelf(peerID id.ID, config *Config, signer client.Signer) *api.PeerAddress {
+	apiSelf := peer.FromAddress(peerID, config.PublicName, config.PublicAddr)
+	apiSelf.Addresses = config.AdvertisedAddrs
+	peer.SetRelayAddress(apiSelf, config.RelayAddr)
+	if cs, ok := signer.(client.CertifiedSigner); ok {
+		apiSelf.Cert = cs.Certificate()
+	}
+	return apiSelf
+}
+
+// newSubscribeToBalancer returns the client.SetBalancer selecting peers to subscribe to per the
+// given selection.
+func newSubscribeToBalancer(
+	selection subscribe.Selection, rt routing.Table, clients client.Pool,
+) client.SetBalancer {
+	switch selection {
+	case subscribe.SpreadSelection:
+		return routing.NewSpreadClientBalancer(rt, clients)
+	case subscribe.HealthySelection:
+		return routing.NewHealthiestClientBalancer(rt, clients)
+	default:
+		return routing.NewClientBalancer(rt, clients)
+	}
+}
+
 // NewResponseMetadata creates a new api.ResponseMatadata object with the same RequestID as that
 // in the api.RequestMetadata.
 func (l *Librarian) NewResponseMetadata(m *api.RequestMetadata) *api.ResponseMetadata {
@@ -50,6 +97,24 @@ func (l *Librarian) checkRequest(
 	if err := l.rqv.Verify(ctx, rq, meta); err != nil {
 		return requesterID, err
 	}
+	if l.certs != nil {
+		certOrgID, err := l.certs.Check(meta)
+		if err != nil {
+			return requesterID, err
+		}
+		if l.orgs != nil && certOrgID != nil {
+			// requester has proven its organization via the verified certificate
+			l.orgs.SetOrg(requesterID, certOrgID)
+		}
+	}
+	if l.orgs != nil && len(meta.OrgPubKey) > 0 {
+		// requester has proven its organization via the verified org signature
+		orgID, err := newIDFromPublicKeyBytes(meta.OrgPubKey)
+		if err != nil {
+			return requesterID, err
+		}
+		l.orgs.SetOrg(requesterID, orgID)
+	}
 	return requesterID, nil
 }
 
@@ -115,6 +180,13 @@ func logReturnInvalidRqErr(lg *zap.Logger, err error, fields ...zapcore.Field) e
 	return status.Error(codes.InvalidArgument, err.Error())
 }
 
+func logReturnFailedPreconditionErr(lg *zap.Logger, err error, fields ...zapcore.Field) error {
+	// info level b/c issue comes from request conflicting with what the peer already stores
+	fields = append(fields, zap.Error(err))
+	lg.Info(failedPreconditionMsg, fields...)
+	return status.Error(codes.FailedPrecondition, err.Error())
+}
+
 func logReturnInternalErr(lg *zap.Logger, msg string, err error, fields ...zapcore.Field) error {
 	fields = append(fields, zap.Error(err))
 	lg.Error(msg, fields...)
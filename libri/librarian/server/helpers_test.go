@@ -2,7 +2,11 @@ package server
 
 import (
 	"errors"
+	"io/ioutil"
 	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/drausin/libri/libri/common/ecid"
@@ -10,6 +14,7 @@ import (
 	"github.com/drausin/libri/libri/common/storage"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/comm"
 	"github.com/drausin/libri/libri/librarian/server/routing"
 	"github.com/golang/protobuf/proto"
 	"github.com/stretchr/testify/assert"
@@ -43,6 +48,145 @@ func TestCheckRequest_ok(t *testing.T) {
 	assert.Equal(t, peerID.ID(), requesterID)
 }
 
+func TestCheckRequest_orgs(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	trust := &fixedTrustKnower{orgs: make(map[string]id.ID)}
+	l := &Librarian{rqv: &alwaysRequestVerifier{}, orgs: trust}
+
+	// without org, nothing recorded
+	rq := client.NewGetRequest(peerID, nil, id.NewPseudoRandom(rng))
+	_, err := l.checkRequest(context.TODO(), rq, rq.Metadata)
+	assert.Nil(t, err)
+	assert.Len(t, trust.orgs, 0)
+
+	// with org, requester's org recorded
+	rq = client.NewGetRequest(peerID, orgID, id.NewPseudoRandom(rng))
+	_, err = l.checkRequest(context.TODO(), rq, rq.Metadata)
+	assert.Nil(t, err)
+	assert.Equal(t, orgID.ID(), trust.orgs[peerID.ID().String()])
+
+	// bad org pub key
+	rq.Metadata.OrgPubKey = []byte("bad org pub key")
+	_, err = l.checkRequest(context.TODO(), rq, rq.Metadata)
+	assert.NotNil(t, err)
+}
+
+func TestCheckRequest_certificate(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	trust := &fixedTrustKnower{
+		orgs:      make(map[string]id.ID),
+		knownOrgs: map[string]struct{}{orgID.ID().String(): {}},
+	}
+	certs, err := newCertChecker(client.NewCertificateVerifier(client.DefaultClockSkew), trust,
+		true)
+	assert.Nil(t, err)
+	l := &Librarian{rqv: &alwaysRequestVerifier{}, trust: trust, orgs: trust, certs: certs}
+
+	// without certificate, request refused
+	rq := client.NewGetRequest(peerID, nil, id.NewPseudoRandom(rng))
+	_, err = l.checkRequest(context.TODO(), rq, rq.Metadata)
+	assert.Equal(t, client.ErrMissingCertificate, err)
+	assert.Len(t, trust.orgs, 0)
+
+	// with certificate, requester's org recorded
+	rq.Metadata.Cert, err = client.NewCertificate(orgID, peerID.PublicKeyBytes(),
+		client.DefaultCertificateTTL)
+	assert.Nil(t, err)
+	_, err = l.checkRequest(context.TODO(), rq, rq.Metadata)
+	assert.Nil(t, err)
+	assert.Equal(t, orgID.ID(), trust.orgs[peerID.ID().String()])
+}
+
+func TestNewKnower(t *testing.T) {
+	orgs, err := comm.NewPeerOrgs(comm.DefaultPeerOrgsSize)
+	assert.Nil(t, err)
+	knower, trust, err := newKnower("", orgs)
+	assert.Nil(t, err)
+	assert.Nil(t, trust)
+	assert.True(t, knower.Know(id.FromInt64(1)))
+
+	dir, err := ioutil.TempDir("", "knower-test")
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+	assert.Nil(t, err)
+	fp := filepath.Join(dir, "trust.json")
+	assert.Nil(t, comm.WriteTrustList(fp, comm.NewTrustList()))
+	knower, trust, err = newKnower(fp, orgs)
+	assert.Nil(t, err)
+	assert.NotNil(t, trust)
+	assert.False(t, knower.Know(id.FromInt64(1)))
+
+	knower, trust, err = newKnower(filepath.Join(dir, "missing.json"), orgs)
+	assert.NotNil(t, err)
+	assert.Nil(t, knower)
+	assert.Nil(t, trust)
+}
+
+func TestNewAPISelf(t *testing.T) {
+	peerID := id.FromInt64(1)
+	config := NewDefaultConfig()
+	apiSelf := newAPISelf(peerID, config, client.NewEmptySigner())
+	assert.Equal(t, peerID.Bytes(), apiSelf.PeerId)
+	assert.Equal(t, config.PublicName, apiSelf.PeerName)
+	assert.Equal(t, uint32(config.PublicAddr.Port), apiSelf.Port)
+	assert.Empty(t, apiSelf.RelayIp)
+
+	config.WithRelayAddr(&net.TCPAddr{IP: net.ParseIP("10.11.12.13"), Port: 20400})
+	apiSelf = newAPISelf(peerID, config, client.NewEmptySigner())
+	assert.Equal(t, "10.11.12.13", apiSelf.RelayIp)
+	assert.Equal(t, uint32(20400), apiSelf.RelayPort)
+
+	addrs := []string{"[2001:db8::1]:20100", "librarian.example.com:20100"}
+	config.WithAdvertisedAddrs(addrs)
+	apiSelf = newAPISelf(peerID, config, client.NewEmptySigner())
+	assert.Equal(t, addrs, apiSelf.Addresses)
+	assert.Nil(t, apiSelf.Cert)
+
+	// check certificate presented by signer is advertised
+	cert := &api.PeerCertificate{Serial: id.FromInt64(2).Bytes()}
+	signer := client.NewCertifiedSigner(client.NewEmptySigner(), cert)
+	apiSelf = newAPISelf(peerID, config, signer)
+	assert.Equal(t, cert, apiSelf.Cert)
+}
+
+type fixedTrustKnower struct {
+	orgs      map[string]id.ID
+	knownOrgs map[string]struct{}
+	revoked   map[string]struct{}
+	reloadErr error
+	nReloads  int
+}
+
+func (k *fixedTrustKnower) Know(peerID id.ID) bool {
+	_, in := k.orgs[peerID.String()]
+	return in
+}
+
+func (k *fixedTrustKnower) KnowOrg(orgID id.ID) bool {
+	_, in := k.knownOrgs[orgID.String()]
+	return in
+}
+
+func (k *fixedTrustKnower) Revoked(serial id.ID) bool {
+	_, in := k.revoked[serial.String()]
+	return in
+}
+
+func (k *fixedTrustKnower) SetOrg(peerID, orgID id.ID) {
+	k.orgs[peerID.String()] = orgID
+}
+
+func (k *fixedTrustKnower) GetOrg(peerID id.ID) (id.ID, bool) {
+	orgID, in := k.orgs[peerID.String()]
+	return orgID, in
+}
+
+func (k *fixedTrustKnower) Reload() error {
+	k.nReloads++
+	return k.reloadErr
+}
+
 type neverRequestVerifier struct{}
 
 func (rv *neverRequestVerifier) Verify(ctx context.Context, msg proto.Message,
@@ -50,6 +194,11 @@ func (rv *neverRequestVerifier) Verify(ctx context.Context, msg proto.Message,
 	return errors.New("some verification error")
 }
 
+func (rv *neverRequestVerifier) CheckReplay(ctx context.Context,
+	meta *api.RequestMetadata) error {
+	return errors.New("some replay error")
+}
+
 func TestCheckRequest_newIDErr(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	peerID := ecid.NewPseudoRandom(rng)
@@ -3,6 +3,8 @@ package introduce
 import (
 	"bytes"
 	"fmt"
+	"math/rand"
+	"sort"
 	"sync"
 
 	"github.com/drausin/libri/libri/common/id"
@@ -45,16 +47,17 @@ func NewIntroducer(
 }
 
 // NewDefaultIntroducer creates a new Introducer with the given peerSigner and default querier and
-// response processor.
+// response processor, which ignores peers the admitter rejects.
 func NewDefaultIntroducer(
 	peerSigner client.Signer,
 	orgSigner client.Signer,
 	rec comm.QueryRecorder,
 	selfID id.ID,
 	clients client.Pool,
+	admitter Admitter,
 ) Introducer {
 	ic := client.NewIntroducerCreator(clients)
-	rp := NewResponseProcessor(peer.NewFromer(), selfID)
+	rp := NewResponseProcessor(peer.NewFromer(), selfID, admitter)
 	return NewIntroducer(peerSigner, orgSigner, rec, ic, rp)
 }
 
@@ -83,7 +86,7 @@ func (i *introducer) introduceWork(intro *Introduction, wg *sync.WaitGroup) {
 		// get next peer to query
 		var nextIDStr string
 		var next peer.Peer
-		intro.wrapLock(func() { nextIDStr, next = removeAny(intro.Result.Unqueried) })
+		intro.wrapLock(func() { nextIDStr, next = removeNext(intro.Result.Unqueried, intro.Rand) })
 		if next == nil {
 			// no more unqueried peers
 			continue
@@ -110,7 +113,7 @@ func (i *introducer) introduceWork(intro *Introduction, wg *sync.WaitGroup) {
 }
 
 func (i *introducer) query(next peer.Peer, intro *Introduction) (*api.IntroduceResponse, error) {
-	lc, err := i.introducerCreator.Create(next.Address().String())
+	lc, err := i.introducerCreator.Create(peer.DialAddress(next))
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +135,21 @@ func (i *introducer) query(next peer.Peer, intro *Introduction) (*api.IntroduceR
 	return rp, nil
 }
 
+func removeNext(m map[string]peer.Peer, rng *rand.Rand) (string, peer.Peer) {
+	if rng == nil || len(m) == 0 {
+		return removeAny(m)
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	k := keys[rng.Intn(len(keys))]
+	v := m[k]
+	delete(m, k)
+	return k, v
+}
+
 func removeAny(m map[string]peer.Peer) (string, peer.Peer) {
 	for k, v := range m {
 		delete(m, k)
@@ -140,6 +158,12 @@ func removeAny(m map[string]peer.Peer) (string, peer.Peer) {
 	return "empty", nil
 }
 
+// Admitter decides whether peers with a given ID may join the routing table.
+type Admitter interface {
+	// AdmitID returns an error if no peer with the given ID may join the routing table.
+	AdmitID(peerID id.ID) error
+}
+
 // ResponseProcessor handles an api.IntroduceResponse.
 type ResponseProcessor interface {
 	// Process handles an api.IntroduceResponse, adding the responder to the map of responded
@@ -148,32 +172,40 @@ type ResponseProcessor interface {
 }
 
 type responseProcessor struct {
-	fromer peer.Fromer
-	selfID id.ID
+	fromer   peer.Fromer
+	selfID   id.ID
+	admitter Admitter
 }
 
-// NewResponseProcessor creates a new ResponseProcessor with a given peer.Fromer.
-func NewResponseProcessor(f peer.Fromer, selfID id.ID) ResponseProcessor {
+// NewResponseProcessor creates a new ResponseProcessor with a given peer.Fromer, ignoring peers
+// whose IDs the admitter rejects.
+func NewResponseProcessor(
+	f peer.Fromer, selfID id.ID, admitter Admitter,
+) ResponseProcessor {
 	return &responseProcessor{
-		fromer: f,
-		selfID: selfID,
+		fromer:   f,
+		selfID:   selfID,
+		admitter: admitter,
 	}
 }
 
 func (irp *responseProcessor) Process(rp *api.IntroduceResponse, result *Result) {
 
-	// add newly introduced peer to responded map
-	idStr := id.FromBytes(rp.Self.PeerId).String()
-	newPeer := irp.fromer.FromAPI(rp.Self)
-	result.Responded[idStr] = newPeer
+	// add newly introduced peer to responded map if it may join the routing table
+	responderID := id.FromBytes(rp.Self.PeerId)
+	if irp.admitter.AdmitID(responderID) == nil {
+		result.Responded[responderID.String()] = irp.fromer.FromAPI(rp.Self)
+	}
 
 	// add newly discovered peers to list of peers to query if they're not already there
 	selfIDStr := irp.selfID.String()
 	for _, pa := range rp.Peers {
-		newIDStr := id.FromBytes(pa.PeerId).String()
+		newID := id.FromBytes(pa.PeerId)
+		newIDStr := newID.String()
 		_, inResponded := result.Responded[newIDStr]
 		_, inUnqueried := result.Unqueried[newIDStr]
-		if !inResponded && !inUnqueried && newIDStr != selfIDStr {
+		if !inResponded && !inUnqueried && newIDStr != selfIDStr &&
+			irp.admitter.AdmitID(newID) == nil {
 			newPeer := irp.fromer.FromAPI(pa)
 			result.Unqueried[newIDStr] = newPeer
 		}
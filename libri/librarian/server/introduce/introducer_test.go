@@ -14,6 +14,7 @@ import (
 	lclient "github.com/drausin/libri/libri/librarian/client"
 	"github.com/drausin/libri/libri/librarian/server/comm"
 	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/routing"
 	"github.com/drausin/libri/libri/librarian/server/search"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
@@ -30,6 +31,7 @@ func TestNewDefaultIntroducer(t *testing.T) {
 		&fixedRecorder{},
 		id.NewPseudoRandom(rng),
 		p,
+		routing.NewAdmitter(routing.NewDefaultParameters(), nil),
 	)
 	assert.NotNil(t, s.(*introducer).peerSigner)
 	assert.NotNil(t, s.(*introducer).introducerCreator)
@@ -170,7 +172,8 @@ func TestResponseProcessor_Process(t *testing.T) {
 	responder := peer.NewTestPeer(rng, nPeers)
 	peers := peer.NewTestPeers(rng, nPeers)
 	selfPeer := peer.NewTestPeer(rng, nPeers+1)
-	rp := NewResponseProcessor(peer.NewFromer(), selfPeer.ID())
+	rp := NewResponseProcessor(peer.NewFromer(), selfPeer.ID(),
+		routing.NewAdmitter(routing.NewDefaultParameters(), nil))
 	apiPeers := peer.ToAPIs(peers)
 	apiPeers = append(apiPeers, selfPeer.ToAPI())
 
@@ -212,6 +215,66 @@ func TestResponseProcessor_Process(t *testing.T) {
 	}
 }
 
+func TestResponseProcessor_Process_rejected(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	nPeers := 64
+	responder := peer.NewTestPeer(rng, nPeers)
+	peers := peer.NewTestPeers(rng, nPeers)
+	params := routing.NewDefaultParameters()
+	params.MinIDWork = 2
+	rp := NewResponseProcessor(peer.NewFromer(), id.NewPseudoRandom(rng),
+		routing.NewAdmitter(params, nil))
+	result := NewInitialResult()
+	rp.Process(&api.IntroduceResponse{Self: responder.ToAPI(), Peers: peer.ToAPIs(peers)},
+		result)
+
+	// only peers with enough ID work are added
+	_, in := result.Responded[responder.ID().String()]
+	assert.Equal(t, id.Work(responder.ID()) >= params.MinIDWork, in)
+	nAdmitted := 0
+	for _, p := range peers {
+		_, in = result.Unqueried[p.ID().String()]
+		assert.Equal(t, id.Work(p.ID()) >= params.MinIDWork, in)
+		if in {
+			nAdmitted++
+		}
+	}
+	assert.True(t, 0 < nAdmitted && nAdmitted < nPeers)
+}
+
+func TestRemoveNext(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peers := peer.NewTestPeers(rng, 8)
+	newUnqueried := func() map[string]peer.Peer {
+		m := make(map[string]peer.Peer)
+		for _, p := range peers {
+			m[p.ID().String()] = p
+		}
+		return m
+	}
+
+	// same rng seed should remove peers in same order
+	m1, m2 := newUnqueried(), newUnqueried()
+	rng1, rng2 := rand.New(rand.NewSource(1)), rand.New(rand.NewSource(1))
+	for range peers {
+		k1, p1 := removeNext(m1, rng1)
+		k2, p2 := removeNext(m2, rng2)
+		assert.Equal(t, k1, k2)
+		assert.Equal(t, p1, p2)
+		assert.Equal(t, k1, p1.ID().String())
+	}
+	assert.Len(t, m1, 0)
+	_, p := removeNext(m1, rng1)
+	assert.Nil(t, p)
+
+	// without rng, still removes a peer
+	m3 := newUnqueried()
+	k3, p3 := removeNext(m3, nil)
+	assert.NotNil(t, p3)
+	assert.Equal(t, k3, p3.ID().String())
+	assert.Len(t, m3, len(peers)-1)
+}
+
 func newQueryTestIntroduction() (*Introduction, map[string]api.Introducer) {
 	n, _ := 32, uint(8)
 	rng := rand.New(rand.NewSource(int64(n)))
@@ -250,8 +313,9 @@ func newTestIntroducer(
 		rec,
 		&fixedIntroducerCreator{introducers: addressIntroducers},
 		&responseProcessor{
-			fromer: &search.TestFromer{Peers: peersMap},
-			selfID: selfID,
+			fromer:   &search.TestFromer{Peers: peersMap},
+			selfID:   selfID,
+			admitter: routing.NewAdmitter(routing.NewDefaultParameters(), nil),
 		},
 	)
 }
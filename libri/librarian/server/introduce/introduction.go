@@ -1,6 +1,7 @@
 package introduce
 
 import (
+	"math/rand"
 	"sync"
 	"time"
 
@@ -100,6 +101,11 @@ type Introduction struct {
 	// parameters defining the search
 	Params *Parameters
 
+	// Rand, if not nil, chooses the next peer to query, which makes introductions reproducible
+	// when Params.Concurrency is 1 (e.g., in simulations); otherwise the next peer is whichever
+	// comes first in (randomized) map iteration order
+	Rand *rand.Rand
+
 	// mutex used to synchronizes reads and writes to this instance
 	mu sync.Mutex
 }
@@ -15,9 +15,11 @@ import (
 	cbackoff "github.com/cenkalti/backoff"
 	cerrors "github.com/drausin/libri/libri/common/errors"
 	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/relay"
 	"github.com/drausin/libri/libri/librarian/server/comm"
 	"github.com/drausin/libri/libri/librarian/server/introduce"
 	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/routing"
 	"github.com/grpc-ecosystem/go-grpc-prometheus"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -29,6 +31,7 @@ import (
 
 const (
 	postListenNotifyWait = 100 * time.Millisecond
+	replicatorStartWait  = 60 * time.Second
 	maxConcurrentStreams = 128
 )
 
@@ -163,15 +166,31 @@ func (l *Librarian) listenAndServe(up chan *Librarian, bootstrapped chan struct{
 		if rec, ok := l.rec.(comm.PromRecorder); ok {
 			rec.Register()
 		}
+		if a, ok := l.allower.(comm.ReloadableAllower); ok {
+			a.Register()
+		}
+		if a, ok := l.admitter.(routing.PromAdmitter); ok {
+			a.Register()
+		}
 	}
 	reflection.Register(s)
 
+	relayServer, err := l.maybeStartRelayServer()
+	if err != nil {
+		return err
+	}
+	gateway, err := l.maybeStartGateway()
+	if err != nil {
+		return err
+	}
+
 	// aux routines handle:
 	// - (maybe) start Prometheus metrics endpoint
 	// - (maybe) start pprof profiler endpoint
 	// - listening to SIGTERM (and friends) signals from outside world
 	// - sending publications to subscribed peers
 	// - document replication
+	// - routing table refreshes
 	l.startAuxRoutines(bootstrapped)
 
 	// handle stop signal
@@ -179,11 +198,25 @@ func (l *Librarian) listenAndServe(up chan *Librarian, bootstrapped chan struct{
 		<-l.stop
 		l.logger.Info("gracefully stopping server", zap.Int(LoggerPortKey, l.config.LocalPort))
 		s.GracefulStop()
+		if relayServer != nil {
+			relayServer.Stop()
+		}
+		if gateway != nil {
+			if err := gateway.Close(); err != nil {
+				l.logger.Error("error closing gateway", zap.Error(err))
+			}
+		}
 		if l.config.ReportMetrics {
 			l.storageMetrics.unregister()
 			if rec, ok := l.rec.(comm.PromRecorder); ok {
 				rec.Unregister()
 			}
+			if a, ok := l.allower.(comm.ReloadableAllower); ok {
+				a.Unregister()
+			}
+			if a, ok := l.admitter.(routing.PromAdmitter); ok {
+				a.Unregister()
+			}
 		}
 		close(l.stopped)
 	}()
@@ -204,6 +237,12 @@ func (l *Librarian) listenAndServe(up chan *Librarian, bootstrapped chan struct{
 		l.logger.Error("failed to listen", zap.Error(err))
 		return err
 	}
+	if l.config.WrapListener != nil {
+		lis = l.config.WrapListener(lis)
+	}
+	if l.config.RelayAddr != nil {
+		go l.serveRelayed(s)
+	}
 	if err := s.Serve(lis); err != nil {
 		if strings.Contains(err.Error(), "use of closed network connection") {
 			return nil
@@ -214,6 +253,65 @@ func (l *Librarian) listenAndServe(up chan *Librarian, bootstrapped chan struct{
 	return nil
 }
 
+// maybeStartRelayServer starts relaying connections for peers that aren't directly reachable if
+// the config has a local relay port.
+func (l *Librarian) maybeStartRelayServer() (relay.Server, error) {
+	if l.config.LocalRelayPort == 0 {
+		return nil, nil
+	}
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", l.config.LocalRelayPort))
+	if err != nil {
+		l.logger.Error("failed to listen for relay connections", zap.Error(err))
+		return nil, err
+	}
+	relayServer := relay.NewServer(l.peerID.ID(), l.config.Relay, l.logger)
+	go func() {
+		if err := relayServer.Serve(lis); err != nil &&
+			!strings.Contains(err.Error(), "use of closed network connection") {
+			l.logger.Error("failed to serve relay connections", zap.Error(err))
+		}
+	}()
+	l.logger.Info("relaying connections", zap.Int(LoggerPortKey, l.config.LocalRelayPort))
+	return relayServer, nil
+}
+
+// maybeStartGateway starts serving the HTTP/JSON gateway if the config has a local gateway port.
+func (l *Librarian) maybeStartGateway() (*http.Server, error) {
+	if l.config.LocalGatewayPort == 0 {
+		return nil, nil
+	}
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", l.config.LocalGatewayPort))
+	if err != nil {
+		l.logger.Error("failed to listen for gateway requests", zap.Error(err))
+		return nil, err
+	}
+	gateway := &http.Server{
+		Handler:           NewGateway(l, l.logger),
+		ReadHeaderTimeout: gatewayReadHeaderTimeout,
+		ReadTimeout:       gatewayReadTimeout,
+		IdleTimeout:       gatewayIdleTimeout,
+	}
+	go func() {
+		if err := gateway.Serve(lis); err != nil && err != http.ErrServerClosed {
+			l.logger.Error("failed to serve gateway requests", zap.Error(err))
+		}
+	}()
+	l.logger.Info("serving HTTP/JSON gateway", zap.Int(LoggerPortKey, l.config.LocalGatewayPort))
+	return gateway, nil
+}
+
+// serveRelayed serves requests from peers connecting through the configured relay.
+func (l *Librarian) serveRelayed(s *grpc.Server) {
+	lis := relay.NewListener(l.config.RelayAddr, l.config.RelayID, l.peerID, l.config.Relay,
+		l.logger)
+	l.logger.Info("listening for relayed requests",
+		zap.Stringer(logRelayAddr, l.config.RelayAddr))
+	if err := s.Serve(lis); err != nil && err != relay.ErrListenerClosed &&
+		err != grpc.ErrServerStopped {
+		l.logger.Error("failed to serve relayed requests", zap.Error(err))
+	}
+}
+
 func (l *Librarian) startAuxRoutines(bootstrapped chan struct{}) {
 	if l.config.ReportMetrics {
 		go func() {
@@ -242,6 +340,11 @@ func (l *Librarian) startAuxRoutines(bootstrapped chan struct{}) {
 		cerrors.MaybePanic(l.Close()) // don't try to recover from Close error
 	}()
 
+	// reload trust list and limits on SIGHUP from outside world
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+	go l.reloadOnSignal(reloadSignals)
+
 	// long-running goroutine managing subscriptions from other peers
 	go l.subscribeFrom.Fanout()
 
@@ -257,24 +360,92 @@ func (l *Librarian) startAuxRoutines(bootstrapped chan struct{}) {
 
 	// long-running goroutine replicating documents
 	go func() {
-		// wait until have bootstrapped peers
-		<-bootstrapped
-		time.Sleep(60 * time.Second)
+		// wait until have bootstrapped peers and let things settle a bit
+		select {
+		case <-bootstrapped:
+		case <-l.stop:
+			return
+		}
+		select {
+		case <-time.After(replicatorStartWait):
+		case <-l.stop:
+			return
+		}
 		if err := l.replicator.Start(); err != nil {
 			l.logger.Error("fatal replicator error", zap.Error(err))
 			cerrors.MaybePanic(l.Close()) // don't try to recover from Close error
 		}
 	}()
+
+	// long-running goroutine refreshing the routing table
+	go func() {
+		select {
+		case <-bootstrapped:
+		case <-l.stop:
+			return
+		}
+		l.refresher.Start()
+	}()
+}
+
+// reloadOnSignal reloads the trust list and limits files each time it receives a signal until the
+// librarian stops.
+func (l *Librarian) reloadOnSignal(signals chan os.Signal) {
+	for {
+		select {
+		case <-signals:
+			_ = l.reload()
+		case <-l.stop:
+			signal.Stop(signals)
+			return
+		}
+	}
+}
+
+// reload reloads the trust list and limits files, keeping the previous trust list or limits if
+// their file is invalid. It returns the first error encountered.
+func (l *Librarian) reload() error {
+	var firstErr error
+	if l.trust != nil {
+		if err := l.trust.Reload(); err != nil {
+			l.logger.Error("failed to reload trust list, keeping previous one",
+				zap.String(logTrustListFile, l.config.TrustListFile), zap.Error(err))
+			firstErr = err
+		} else {
+			l.logger.Info("reloaded trust list",
+				zap.String(logTrustListFile, l.config.TrustListFile))
+		}
+	}
+	if a, ok := l.allower.(comm.ReloadableAllower); ok {
+		if err := a.Reload(); err != nil {
+			l.logger.Error("failed to reload limits, keeping previous ones",
+				zap.String(logLimitsFile, l.config.LimitsFile), zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+		} else {
+			l.logger.Info("reloaded limits", zap.String(logLimitsFile, l.config.LimitsFile))
+		}
+	}
+	return firstErr
 }
 
-// StopAuxRoutines ends the replicator and subscriptions auxiliary routines.
+// StopAuxRoutines ends the replicator, refresher, and subscriptions auxiliary routines.
 func (l *Librarian) StopAuxRoutines() {
 	l.replicator.Stop()
+	l.refresher.Stop()
 	l.subscribeTo.End()
 }
 
-// Close handles cleanup involved in closing down the server.
+// Close handles cleanup involved in closing down the server. It is safe to call more than once,
+// e.g., from both a stop signal handler and the owner of the Librarian; later calls block until
+// the first has finished and then return its result.
 func (l *Librarian) Close() error {
+	l.closeOnce.Do(func() { l.closeErr = l.close() })
+	return l.closeErr
+}
+
+func (l *Librarian) close() error {
 	l.StopAuxRoutines()
 
 	// send stop signal to listener
@@ -17,6 +17,9 @@ import (
 	"github.com/drausin/libri/libri/common/id"
 	clogging "github.com/drausin/libri/libri/common/logging"
 	"github.com/drausin/libri/libri/common/parse"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/relay"
+	"github.com/drausin/libri/libri/librarian/server/comm"
 	"github.com/drausin/libri/libri/librarian/server/introduce"
 	"github.com/drausin/libri/libri/librarian/server/peer"
 	"github.com/drausin/libri/libri/librarian/server/routing"
@@ -32,6 +35,7 @@ func TestStart_ok(t *testing.T) {
 	// start a single librarian server
 	config := newTestConfig()
 	config.WithProfile(true).WithReportMetrics(true)
+	config.WithLocalGatewayPort(DefaultPort + 20)
 	config.WithLogLevel(zapcore.DebugLevel)
 	config.Introduce.MinNumIntroductions = 0 // since no other peers
 
@@ -73,6 +77,16 @@ func TestStart_ok(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "200 OK", resp.Status)
 
+	// confirm ok gateway Find
+	rng := rand.New(rand.NewSource(0))
+	clientID := ecid.NewPseudoRandom(rng)
+	gatewayAddr := fmt.Sprintf("http://localhost:%d%s", config.LocalGatewayPort, GatewayFindPath)
+	rq := client.NewFindRequest(clientID, nil, id.NewPseudoRandom(rng), 8)
+	resp = postSignedGatewayRequest(t, gatewayAddr, rq, clientID, nil)
+	assert.Equal(t, "200 OK", resp.Status)
+	assert.Equal(t, jsonContentType, resp.Header.Get("Content-Type"))
+	assert.Nil(t, resp.Body.Close())
+
 	// give time for bootstrap
 	time.Sleep(3 * time.Second)
 
@@ -80,6 +94,59 @@ func TestStart_ok(t *testing.T) {
 	wg1.Wait()
 }
 
+func TestStart_relayed(t *testing.T) {
+	// start a relaying librarian and one only reachable through it
+	relayingConfig := newTestConfig()
+	relayingConfig.WithLocalPort(DefaultPort + 10).WithDefaultPublicAddr()
+	relayingConfig.WithLocalRelayPort(DefaultPort + 11)
+	relayAddr, err := parse.Addr(DefaultIP, relayingConfig.LocalRelayPort)
+	assert.Nil(t, err)
+	relayedConfig := newTestConfig()
+	relayedConfig.WithLocalPort(DefaultPort + 12).WithDefaultPublicAddr()
+	relayedConfig.WithRelayAddr(relayAddr)
+	relayedConfig.Relay.RetryInterval = 10 * time.Millisecond
+
+	librarians := make([]*Librarian, 2)
+	wg1 := new(sync.WaitGroup)
+	for i, config := range []*Config{relayingConfig, relayedConfig} {
+		config.WithBootstrapAddrs([]*net.TCPAddr{config.PublicAddr})
+		config.Introduce.MinNumIntroductions = 0 // since no other peers
+		up := make(chan *Librarian, 1)
+		wg1.Add(1)
+		go func(wg2 *sync.WaitGroup, config *Config) {
+			defer wg2.Done()
+			err2 := Start(zap.NewNop(), config, up)
+			assert.Nil(t, err2)
+		}(wg1, config)
+		librarians[i] = <-up
+	}
+	assert.Equal(t, relayAddr.IP.String(), librarians[1].apiSelf.RelayIp)
+
+	// confirm ok health check through relay
+	target := relay.Target(librarians[1].peerID.ID(), relayAddr.String())
+	conn, err := grpc.Dial(target, grpc.WithInsecure(), grpc.WithDialer(relay.Dial))
+	assert.Nil(t, err)
+	clientHealth := healthpb.NewHealthClient(conn)
+	var rp *healthpb.HealthCheckResponse
+	for c := 0; c < 10; c++ {
+		// allow for relayed librarian to register its connections
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+		rp, err = clientHealth.Check(ctx, &healthpb.HealthCheckRequest{})
+		cancel()
+		if err == nil {
+			break
+		}
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, rp.Status)
+	assert.Nil(t, conn.Close())
+
+	for _, l := range librarians {
+		assert.Nil(t, l.CloseAndRemove())
+	}
+	wg1.Wait()
+}
+
 func TestStart_newLibrarianErr(t *testing.T) {
 	config := &Config{
 		DataDir: "some/nonexistant/path",
@@ -215,3 +282,93 @@ func (fi *fixedIntroducer) Introduce(intro *introduce.Introduction, seeds []peer
 	intro.Result = fi.result
 	return fi.err
 }
+
+func TestLibrarian_reloadOnSignal(t *testing.T) {
+	for _, reloadErr := range []error{nil, errors.New("some reload error")} {
+		trust := &fixedTrustKnower{orgs: make(map[string]id.ID), reloadErr: reloadErr}
+		allower := &fixedReloadableAllower{reloadErr: reloadErr}
+		l := &Librarian{
+			trust:   trust,
+			allower: allower,
+			config:  NewDefaultConfig(),
+			logger:  zap.NewNop(),
+			stop:    make(chan struct{}),
+		}
+		signals := make(chan os.Signal)
+		done := make(chan struct{})
+		go func() {
+			l.reloadOnSignal(signals)
+			close(done)
+		}()
+		signals <- os.Interrupt
+		signals <- os.Interrupt
+		close(l.stop)
+		<-done
+		assert.Equal(t, 2, trust.nReloads)
+		assert.Equal(t, 2, allower.nReloads)
+	}
+}
+
+func TestLibrarian_reload(t *testing.T) {
+	trustErr, limitsErr := errors.New("trust error"), errors.New("limits error")
+	cases := map[string]struct {
+		trust    *fixedTrustKnower
+		allower  comm.Allower
+		expected error
+	}{
+		"ok": {
+			trust:   &fixedTrustKnower{},
+			allower: &fixedReloadableAllower{},
+		},
+		"no trust list": {
+			allower: &fixedReloadableAllower{},
+		},
+		"not reloadable allower": {
+			trust:   &fixedTrustKnower{},
+			allower: &fixedAllower{},
+		},
+		"trust error": {
+			trust:    &fixedTrustKnower{reloadErr: trustErr},
+			allower:  &fixedReloadableAllower{reloadErr: limitsErr},
+			expected: trustErr,
+		},
+		"limits error": {
+			trust:    &fixedTrustKnower{},
+			allower:  &fixedReloadableAllower{reloadErr: limitsErr},
+			expected: limitsErr,
+		},
+	}
+	for desc, c := range cases {
+		l := &Librarian{
+			allower: c.allower,
+			config:  NewDefaultConfig(),
+			logger:  zap.NewNop(),
+		}
+		if c.trust != nil {
+			l.trust = c.trust
+		}
+		assert.Equal(t, c.expected, l.reload(), desc)
+		if a, ok := c.allower.(*fixedReloadableAllower); ok {
+			assert.Equal(t, 1, a.nReloads, desc)
+		}
+	}
+}
+
+type fixedReloadableAllower struct {
+	fixedAllower
+	reloadErr error
+	nReloads  int
+}
+
+func (a *fixedReloadableAllower) Reload() error {
+	a.nReloads++
+	return a.reloadErr
+}
+
+func (a *fixedReloadableAllower) Config() *comm.AllowConfig {
+	return comm.NewDefaultAllowConfig()
+}
+
+func (a *fixedReloadableAllower) Register() {}
+
+func (a *fixedReloadableAllower) Unregister() {}
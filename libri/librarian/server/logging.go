@@ -11,6 +11,7 @@ import (
 
 const (
 	logSelfIDShort     = "self_id_short"
+	logSelfPubKey      = "self_pub_key"
 	logRequestIDShort  = "request_id_short"
 	logFromPubKeyShort = "from_pub_key_short"
 	logNPeers          = "n_peers"
@@ -20,6 +21,11 @@ const (
 	logNReplicas       = "n_replicas"
 	logSearch          = "search"
 	logStore           = "store"
+	logTrustListFile   = "trust_list_file"
+	logLimitsFile      = "limits_file"
+	logIDWork          = "id_work"
+	logMinIDWork       = "min_id_work"
+	logRelayAddr       = "relay_addr"
 )
 
 func rqMetadataFields(md *api.RequestMetadata) []zapcore.Field {
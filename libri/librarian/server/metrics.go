@@ -15,6 +15,7 @@ const (
 	envelopeLabel = "envelope"
 	entryLabel    = "entry"
 	pageLabel     = "page"
+	pointerLabel  = "pointer"
 )
 
 var (
@@ -24,6 +25,8 @@ var (
 	entrySizeKey     = []byte("entry_stored_size")
 	pageCountKey     = []byte("page_stored_count")
 	pageSizeKey      = []byte("page_stored_size")
+	pointerCountKey  = []byte("pointer_stored_count")
+	pointerSizeKey   = []byte("pointer_stored_size")
 )
 
 type storageMetrics struct {
@@ -93,6 +96,15 @@ func (sm *storageMetrics) Add(doc *api.Document) error {
 		}
 		sm.count.WithLabelValues(pageLabel).Inc()
 		sm.size.WithLabelValues(pageLabel).Add(float64(len(bytes)))
+	case *api.Document_Pointer:
+		if err := sm.storedMetricAdd(pointerCountKey, 1); err != nil {
+			return err
+		}
+		if err := sm.storedMetricAdd(pointerSizeKey, uint64(len(bytes))); err != nil {
+			return err
+		}
+		sm.count.WithLabelValues(pointerLabel).Inc()
+		sm.size.WithLabelValues(pointerLabel).Add(float64(len(bytes)))
 	}
 	return nil
 }
@@ -122,6 +134,14 @@ func (sm *storageMetrics) initFromStorage() {
 	value, err = sm.getStored(pageSizeKey)
 	errors.MaybePanic(err)
 	sm.size.WithLabelValues(pageLabel).Add(float64(value))
+
+	// pointer
+	value, err = sm.getStored(pointerCountKey)
+	errors.MaybePanic(err)
+	sm.count.WithLabelValues(pointerLabel).Add(float64(value))
+	value, err = sm.getStored(pointerSizeKey)
+	errors.MaybePanic(err)
+	sm.size.WithLabelValues(pointerLabel).Add(float64(value))
 }
 
 func (sm *storageMetrics) storedMetricAdd(key []byte, amount uint64) error {
@@ -32,7 +32,13 @@ func TestStorageMetrics_initAdd(t *testing.T) {
 			Page: api.NewTestPage(rng),
 		},
 	}
-	for _, doc := range []*api.Document{envDoc, entryDoc, pageDoc} {
+	pointer, _ := api.NewTestPointer(rng)
+	pointerDoc := &api.Document{
+		Contents: &api.Document_Pointer{
+			Pointer: pointer,
+		},
+	}
+	for _, doc := range []*api.Document{envDoc, entryDoc, pageDoc, pointerDoc} {
 		err := sm1.Add(doc)
 		assert.Nil(t, err)
 	}
@@ -41,11 +47,12 @@ func TestStorageMetrics_initAdd(t *testing.T) {
 	sm2 := newStorageMetrics(serverSL)
 
 	// check we have a single count for each doc type
-	countMetrics := make(chan prom.Metric, 3)
+	countMetrics := make(chan prom.Metric, 4)
 	expectedLabelValues := map[string]struct{}{
 		"envelope": {},
 		"entry":    {},
 		"page":     {},
+		"pointer":  {},
 	}
 	sm2.count.Collect(countMetrics)
 	close(countMetrics)
@@ -60,10 +67,10 @@ func TestStorageMetrics_initAdd(t *testing.T) {
 		actualCountLabelValues[*written.Label[0].Value] = struct{}{}
 		nCountMetrics++
 	}
-	assert.Equal(t, 3, nCountMetrics)
+	assert.Equal(t, 4, nCountMetrics)
 	assert.Equal(t, expectedLabelValues, actualCountLabelValues)
 
-	sizeMetrics := make(chan prom.Metric, 3)
+	sizeMetrics := make(chan prom.Metric, 4)
 	sm2.size.Collect(sizeMetrics)
 	close(sizeMetrics)
 	nSizeMetrics := 0
@@ -77,7 +84,7 @@ func TestStorageMetrics_initAdd(t *testing.T) {
 		actualSizeLabelValues[*written.Label[0].Value] = struct{}{}
 		nSizeMetrics++
 	}
-	assert.Equal(t, 3, nSizeMetrics)
+	assert.Equal(t, 4, nSizeMetrics)
 }
 
 type fixedSL struct {
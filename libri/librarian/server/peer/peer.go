@@ -6,7 +6,10 @@ import (
 
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/relay"
 	"github.com/drausin/libri/libri/librarian/server/storage"
+	"github.com/golang/protobuf/proto"
 )
 
 const (
@@ -22,6 +25,19 @@ type Peer interface {
 	// Address returns the public address of the peer.
 	Address() *net.TCPAddr
 
+	// Addresses returns the host:port addresses the peer may be reached at, starting with its
+	// public address and followed by any others it advertises, e.g., IPv6 addresses or DNS
+	// names.
+	Addresses() []string
+
+	// RelayAddress returns the address of the relay the peer is reachable through, or nil if it
+	// is directly reachable.
+	RelayAddress() *net.TCPAddr
+
+	// Certificate returns the organization-issued certificate for the peer's public key, or nil
+	// if it doesn't have one.
+	Certificate() *api.PeerCertificate
+
 	// Merge merges another peer into the existing peer. If there is any conflicting information
 	// between the two, the merge returns an error.
 	Merge(other Peer) error
@@ -39,8 +55,17 @@ type peer struct {
 
 	address *net.TCPAddr
 
+	// other advertised host:port addresses
+	addresses []string
+
+	// relay address, if any
+	relayAddress *net.TCPAddr
+
 	// self-reported name
 	name string
+
+	// organization-issued certificate, if any
+	cert *api.PeerCertificate
 }
 
 // New creates a new Peer instance with empty response stats.
@@ -52,6 +77,32 @@ func New(id id.ID, name string, address *net.TCPAddr) Peer {
 	}
 }
 
+// NewRelayed creates a new Peer instance reachable through the relay at the given address.
+func NewRelayed(id id.ID, name string, address, relayAddress *net.TCPAddr) Peer {
+	return NewAdvertised(id, name, address, nil, relayAddress)
+}
+
+// NewAdvertised creates a new Peer instance that also advertises the given host:port addresses,
+// where hosts may be IPv4 or IPv6 addresses or DNS names, and is reachable through the relay at
+// the relay address when it isn't nil.
+func NewAdvertised(
+	id id.ID, name string, address *net.TCPAddr, addresses []string, relayAddress *net.TCPAddr,
+) Peer {
+	return &peer{
+		id:           id,
+		address:      address,
+		addresses:    addresses,
+		relayAddress: relayAddress,
+		name:         name,
+	}
+}
+
+// NewCertified returns the peer with the given organization-issued certificate for its public key.
+func NewCertified(p Peer, cert *api.PeerCertificate) Peer {
+	p.(*peer).cert = cert
+	return p
+}
+
 // NewStub creates a new peer without a name or connector.
 func NewStub(id id.ID, name string) Peer {
 	return New(id, name, nil)
@@ -65,6 +116,27 @@ func (p *peer) Address() *net.TCPAddr {
 	return p.address
 }
 
+func (p *peer) Addresses() []string {
+	addresses := make([]string, 0, len(p.addresses)+1)
+	if p.address != nil {
+		addresses = append(addresses, p.address.String())
+	}
+	for _, a := range p.addresses {
+		if !contains(addresses, a) {
+			addresses = append(addresses, a)
+		}
+	}
+	return addresses
+}
+
+func (p *peer) RelayAddress() *net.TCPAddr {
+	return p.relayAddress
+}
+
+func (p *peer) Certificate() *api.PeerCertificate {
+	return p.cert
+}
+
 func (p *peer) Merge(other Peer) error {
 	if p.id.Cmp(other.ID()) != 0 {
 		return fmt.Errorf("attempting to merge two different peers with IDs %v and %v",
@@ -76,24 +148,51 @@ func (p *peer) Merge(other Peer) error {
 	if p.Address().String() != other.Address().String() {
 		p.address = other.Address()
 	}
+	p.addresses = other.(*peer).addresses
+	p.relayAddress = other.RelayAddress()
+	if other.Certificate() != nil {
+		p.cert = other.Certificate()
+	}
 	return nil
 }
 
 func (p *peer) ToStored() *storage.Peer {
-	return &storage.Peer{
+	stored := &storage.Peer{
 		Id:            p.id.Bytes(),
 		Name:          p.name,
 		PublicAddress: toStoredAddress(p.Address()),
+		Addresses:     p.addresses,
+	}
+	if p.relayAddress != nil {
+		stored.RelayAddress = toStoredAddress(p.relayAddress)
+	}
+	if p.cert != nil {
+		// marshaling a message with only scalar fields never fails
+		stored.Cert, _ = proto.Marshal(p.cert)
 	}
+	return stored
 }
 
 func (p *peer) ToAPI() *api.PeerAddress {
-	return &api.PeerAddress{
-		PeerId:   p.id.Bytes(),
-		PeerName: p.name,
-		Ip:       p.Address().IP.String(),
-		Port:     uint32(p.Address().Port),
+	apiAddress := &api.PeerAddress{
+		PeerId:    p.id.Bytes(),
+		PeerName:  p.name,
+		Ip:        p.Address().IP.String(),
+		Port:      uint32(p.Address().Port),
+		Addresses: p.addresses,
+		Cert:      p.cert,
 	}
+	SetRelayAddress(apiAddress, p.relayAddress)
+	return apiAddress
+}
+
+// DialAddress returns the address a client.Pool dials to reach the peer, which goes through the
+// peer's relay when it has one.
+func DialAddress(p Peer) string {
+	if p.RelayAddress() == nil {
+		return client.JoinAddresses(p.Addresses())
+	}
+	return relay.Target(p.ID(), p.RelayAddress().String())
 }
 
 // ToAPIs converts a list of peers into a list of api.PeerAddress objects.
@@ -119,10 +218,15 @@ func NewFromer() Fromer {
 }
 
 func (f *fromer) FromAPI(apiAddress *api.PeerAddress) Peer {
-	return New(
-		id.FromBytes(apiAddress.PeerId),
-		apiAddress.PeerName,
-		ToAddress(apiAddress),
+	return NewCertified(
+		NewAdvertised(
+			id.FromBytes(apiAddress.PeerId),
+			apiAddress.PeerName,
+			ToAddress(apiAddress),
+			apiAddress.Addresses,
+			ToRelayAddress(apiAddress),
+		),
+		apiAddress.Cert,
 	)
 }
 
@@ -134,6 +238,27 @@ func ToAddress(addr *api.PeerAddress) *net.TCPAddr {
 	}
 }
 
+// ToRelayAddress creates a net.TCPAddr from the relay of an api.PeerAddress, or nil if it has no
+// relay.
+func ToRelayAddress(addr *api.PeerAddress) *net.TCPAddr {
+	if addr.RelayIp == "" {
+		return nil
+	}
+	return &net.TCPAddr{
+		IP:   net.ParseIP(addr.RelayIp),
+		Port: int(addr.RelayPort),
+	}
+}
+
+// SetRelayAddress sets the relay of an api.PeerAddress, leaving it empty for a nil relay address.
+func SetRelayAddress(addr *api.PeerAddress, relayAddr *net.TCPAddr) {
+	if relayAddr == nil {
+		return
+	}
+	addr.RelayIp = relayAddr.IP.String()
+	addr.RelayPort = uint32(relayAddr.Port)
+}
+
 // FromAddress creates an api.PeerAddress from a net.TCPAddr.
 func FromAddress(id id.ID, name string, addr *net.TCPAddr) *api.PeerAddress {
 	return &api.PeerAddress{
@@ -143,3 +268,12 @@ func FromAddress(id id.ID, name string, addr *net.TCPAddr) *api.PeerAddress {
 		Port:     uint32(addr.Port),
 	}
 }
+
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/relay"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,6 +21,34 @@ func TestNew(t *testing.T) {
 	assert.Equal(t, addr, addr)
 }
 
+func TestNewRelayed(t *testing.T) {
+	peerID, name := id.FromInt64(1), "test name"
+	addr := &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 1000}
+	relayAddr := &net.TCPAddr{IP: net.ParseIP("10.11.12.13"), Port: 1001}
+	p := NewRelayed(peerID, name, addr, relayAddr)
+	assert.Equal(t, addr, p.Address())
+	assert.Equal(t, relayAddr, p.RelayAddress())
+
+	assert.Nil(t, New(peerID, name, addr).RelayAddress())
+}
+
+func TestPeer_Addresses(t *testing.T) {
+	peerID, name := id.FromInt64(1), "test name"
+	addr := &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 1000}
+	p := New(peerID, name, addr)
+	assert.Equal(t, []string{"192.168.1.1:1000"}, p.Addresses())
+
+	p = NewAdvertised(peerID, name, addr, []string{
+		"[2001:db8::1]:1000",
+		"192.168.1.1:1000", // duplicate of public address
+		"librarian.example.com:1000",
+	}, nil)
+	expected := []string{"192.168.1.1:1000", "[2001:db8::1]:1000", "librarian.example.com:1000"}
+	assert.Equal(t, expected, p.Addresses())
+
+	assert.Empty(t, NewStub(peerID, name).Addresses())
+}
+
 func TestNewStub(t *testing.T) {
 	peerID := id.FromInt64(1)
 	name := "some name"
@@ -71,6 +101,31 @@ func TestPeer_Merge_ok(t *testing.T) {
 	err = p1.Merge(p2)
 	assert.Nil(t, err)
 	assert.Equal(t, p2Conn, p1.Address())
+
+	// p2's relay should replace p1's, including when p2 is now directly reachable
+	relayAddr := &net.TCPAddr{IP: net.ParseIP("10.11.12.13"), Port: 20100}
+	p2 = NewRelayed(p1ID, "p1", p2Conn, relayAddr)
+	err = p1.Merge(p2)
+	assert.Nil(t, err)
+	assert.Equal(t, relayAddr, p1.RelayAddress())
+	err = p1.Merge(New(p1ID, "p1", p2Conn))
+	assert.Nil(t, err)
+	assert.Nil(t, p1.RelayAddress())
+
+	// p2's advertised addresses should replace p1's
+	p2 = NewAdvertised(p1ID, "p1", p2Conn, []string{"librarian.example.com:11001"}, nil)
+	err = p1.Merge(p2)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{p2Conn.String(), "librarian.example.com:11001"}, p1.Addresses())
+
+	// p2's certificate should replace p1's, but a missing one should not
+	cert := &api.PeerCertificate{Serial: id.NewPseudoRandom(rng).Bytes()}
+	err = p1.Merge(NewCertified(New(p1ID, "p1", p2Conn), cert))
+	assert.Nil(t, err)
+	assert.Equal(t, cert, p1.Certificate())
+	err = p1.Merge(New(p1ID, "p1", p2Conn))
+	assert.Nil(t, err)
+	assert.Equal(t, cert, p1.Certificate())
 }
 
 func TestPeer_Merge_err(t *testing.T) {
@@ -91,6 +146,26 @@ func TestPeer_ToAPI(t *testing.T) {
 	assert.Equal(t, p.ID().Bytes(), apiP.PeerId)
 	assert.Equal(t, p.Address().IP.String(), apiP.Ip)
 	assert.Equal(t, uint32(p.Address().Port), apiP.Port)
+	assert.Empty(t, apiP.RelayIp)
+	assert.Zero(t, apiP.RelayPort)
+
+	relayAddr := &net.TCPAddr{IP: net.ParseIP("10.11.12.13"), Port: 20100}
+	apiP = NewRelayed(p.ID(), "", p.Address(), relayAddr).ToAPI()
+	assert.Equal(t, "10.11.12.13", apiP.RelayIp)
+	assert.Equal(t, uint32(20100), apiP.RelayPort)
+}
+
+func TestDialAddress(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	p := NewTestPeer(rng, 0)
+	assert.Equal(t, p.Address().String(), DialAddress(p))
+
+	relayAddr := &net.TCPAddr{IP: net.ParseIP("10.11.12.13"), Port: 20100}
+	p = NewRelayed(p.ID(), "", p.Address(), relayAddr)
+	assert.Equal(t, relay.Target(p.ID(), relayAddr.String()), DialAddress(p))
+
+	p = NewAdvertised(p.ID(), "", p.Address(), []string{"[2001:db8::1]:20100"}, nil)
+	assert.Equal(t, client.JoinAddresses(p.Addresses()), DialAddress(p))
 }
 
 func TestFromer_FromAPI(t *testing.T) {
@@ -102,6 +177,20 @@ func TestFromer_FromAPI(t *testing.T) {
 
 	assert.Equal(t, p1.ID(), p2.ID())
 	assert.Equal(t, p1.Address(), p2.Address())
+	assert.Nil(t, p2.RelayAddress())
+
+	relayAddr := &net.TCPAddr{IP: net.ParseIP("10.11.12.13"), Port: 20100}
+	p3 := f.FromAPI(NewRelayed(p1.ID(), "", p1.Address(), relayAddr).ToAPI())
+	assert.Equal(t, relayAddr.String(), p3.RelayAddress().String())
+
+	addresses := []string{"[2001:db8::1]:20100", "librarian.example.com:20100"}
+	p4 := f.FromAPI(NewAdvertised(p1.ID(), "", p1.Address(), addresses, nil).ToAPI())
+	assert.Equal(t, append([]string{p1.Address().String()}, addresses...), p4.Addresses())
+	assert.Nil(t, p4.Certificate())
+
+	cert := &api.PeerCertificate{Serial: id.NewPseudoRandom(rng).Bytes()}
+	p5 := f.FromAPI(NewCertified(p1, cert).ToAPI())
+	assert.Equal(t, cert, p5.Certificate())
 }
 
 func TestToAPIs(t *testing.T) {
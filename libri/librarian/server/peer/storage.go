@@ -4,16 +4,32 @@ import (
 	"net"
 
 	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/server/storage"
+	"github.com/golang/protobuf/proto"
 )
 
 // FromStored creates a new peer.Peer instance from a storage.Peer instance.
 func FromStored(stored *storage.Peer) Peer {
-	return New(
+	var relayAddress *net.TCPAddr
+	if stored.RelayAddress != nil {
+		relayAddress = fromStoredAddress(stored.RelayAddress)
+	}
+	p := NewAdvertised(
 		id.FromBytes(stored.Id),
 		stored.Name,
 		fromStoredAddress(stored.PublicAddress),
+		stored.Addresses,
+		relayAddress,
 	)
+	if len(stored.Cert) > 0 {
+		cert := &api.PeerCertificate{}
+		if err := proto.Unmarshal(stored.Cert, cert); err == nil {
+			// a corrupt certificate is dropped, leaving the peer to be rejected if one is required
+			p = NewCertified(p, cert)
+		}
+	}
+	return p
 }
 
 // fromStoredAddress creates a net.TCPAddr from a storage.Address.
@@ -5,6 +5,8 @@ import (
 	"net"
 	"testing"
 
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/server/storage"
 	"github.com/stretchr/testify/assert"
 )
@@ -21,6 +23,29 @@ func TestToStored(t *testing.T) {
 	AssertPeersEqual(t, sp, p)
 }
 
+func TestToStored_FromStored_relay(t *testing.T) {
+	p1 := NewTestPeer(rand.New(rand.NewSource(0)), 0)
+	relayAddr := &net.TCPAddr{IP: net.ParseIP("10.11.12.13"), Port: 20100}
+	p1 = NewRelayed(p1.ID(), "", p1.Address(), relayAddr)
+	sp := p1.ToStored()
+	assert.Equal(t, "10.11.12.13", sp.RelayAddress.Ip)
+	assert.Equal(t, uint32(20100), sp.RelayAddress.Port)
+
+	p2 := FromStored(sp)
+	assert.Equal(t, relayAddr.String(), p2.RelayAddress().String())
+}
+
+func TestToStored_FromStored_addresses(t *testing.T) {
+	p1 := NewTestPeer(rand.New(rand.NewSource(0)), 0)
+	addresses := []string{"[2001:db8::1]:20100", "librarian.example.com:20100"}
+	p1 = NewAdvertised(p1.ID(), "", p1.Address(), addresses, nil)
+	sp := p1.ToStored()
+	assert.Equal(t, addresses, sp.Addresses)
+
+	p2 := FromStored(sp)
+	assert.Equal(t, p1.Addresses(), p2.Addresses())
+}
+
 func TestFromStoredAddress(t *testing.T) {
 	ip, port := "192.168.1.1", uint32(1000)
 	sa := &storage.Address{Ip: ip, Port: port}
@@ -36,3 +61,26 @@ func TestToStoredAddress(t *testing.T) {
 	assert.Equal(t, ip, sa.Ip)
 	assert.Equal(t, uint32(port), sa.Port)
 }
+
+func TestToStored_FromStored_cert(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	p1 := NewTestPeer(rng, 0)
+	sp := p1.ToStored()
+	assert.Empty(t, sp.Cert)
+	assert.Nil(t, FromStored(sp).Certificate())
+
+	cert := &api.PeerCertificate{
+		PubKey:    []byte{1, 2, 3},
+		Serial:    id.NewPseudoRandom(rng).Bytes(),
+		IssuedAt:  1,
+		ExpiresAt: 2,
+	}
+	p1 = NewCertified(p1, cert)
+	sp = p1.ToStored()
+	assert.NotEmpty(t, sp.Cert)
+	assert.Equal(t, cert, FromStored(sp).Certificate())
+
+	// check corrupt stored certificate is dropped
+	sp.Cert = []byte{0xff}
+	assert.Nil(t, FromStored(sp).Certificate())
+}
@@ -0,0 +1,121 @@
+package refresh
+
+import (
+	"github.com/drausin/libri/libri/common/errors"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	promNamespace = "libri"
+	promSubsystem = "server_refresher"
+)
+
+type result int
+
+const (
+	succeeded result = iota
+	exhausted
+	errored
+)
+
+func (r result) String() string {
+	switch r {
+	case succeeded:
+		return "succeeded"
+	case exhausted:
+		return "exhausted"
+	case errored:
+		return "errored"
+	}
+	panic("should never get here")
+}
+
+type metrics struct {
+	refresh  *prom.CounterVec
+	added    prom.Counter
+	ping     *prom.CounterVec
+	eviction prom.Counter
+}
+
+func newMetrics() *metrics {
+	refreshes := prom.NewCounterVec(
+		prom.CounterOpts{
+			Namespace: promNamespace,
+			Subsystem: promSubsystem,
+			Name:      "bucket_refresh_count",
+			Help:      "Stale bucket refresh search result counts",
+		},
+		[]string{"result"},
+	)
+	added := prom.NewCounter(
+		prom.CounterOpts{
+			Namespace: promNamespace,
+			Subsystem: promSubsystem,
+			Name:      "peer_added_count",
+			Help:      "Number of peers bucket refreshes added to the routing table",
+		},
+	)
+	pings := prom.NewCounterVec(
+		prom.CounterOpts{
+			Namespace: promNamespace,
+			Subsystem: promSubsystem,
+			Name:      "peer_ping_count",
+			Help:      "Least-recently-seen peer ping result counts",
+		},
+		[]string{"result"},
+	)
+	evictions := prom.NewCounter(
+		prom.CounterOpts{
+			Namespace: promNamespace,
+			Subsystem: promSubsystem,
+			Name:      "peer_eviction_count",
+			Help:      "Number of unhealthy peers evicted from the routing table",
+		},
+	)
+	return &metrics{
+		refresh:  refreshes,
+		added:    added,
+		ping:     pings,
+		eviction: evictions,
+	}
+}
+
+func (m *metrics) incRefresh(result result) {
+	m.refresh.WithLabelValues(result.String()).Inc()
+}
+
+func (m *metrics) addPeers(n int) {
+	m.added.Add(float64(n))
+}
+
+func (m *metrics) incPing(result result) {
+	m.ping.WithLabelValues(result.String()).Inc()
+}
+
+func (m *metrics) incEviction() {
+	m.eviction.Inc()
+}
+
+func (m *metrics) register() {
+	prom.MustRegister(m.refresh)
+	prom.MustRegister(m.added)
+	prom.MustRegister(m.ping)
+	prom.MustRegister(m.eviction)
+
+	// populate zero counts
+	for _, r := range []result{succeeded, exhausted, errored} {
+		_, err := m.refresh.GetMetricWithLabelValues(r.String())
+		errors.MaybePanic(err) // should never happen
+	}
+	for _, r := range []result{succeeded, errored} {
+		_, err := m.ping.GetMetricWithLabelValues(r.String())
+		errors.MaybePanic(err) // should never happen
+	}
+}
+
+func (m *metrics) unregister() {
+	prom.Unregister(m.refresh)
+	prom.Unregister(m.added)
+	prom.Unregister(m.ping)
+	prom.Unregister(m.eviction)
+}
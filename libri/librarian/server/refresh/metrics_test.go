@@ -0,0 +1,75 @@
+package refresh
+
+import (
+	"testing"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_incRefresh(t *testing.T) {
+	m := newMetrics()
+	m.register()
+	defer m.unregister()
+
+	// do some incs
+	for _, r := range []result{succeeded, exhausted, errored} {
+		m.incRefresh(r)
+	}
+
+	// check we have a single count for each refresh result
+	refreshMetrics := make(chan prom.Metric, 3)
+	m.refresh.Collect(refreshMetrics)
+	close(refreshMetrics)
+	c := 0
+	for refreshMetric := range refreshMetrics {
+		written := dto.Metric{}
+		refreshMetric.Write(&written)
+		assert.Equal(t, float64(1.0), *written.Counter.Value)
+		assert.Equal(t, 1, len(written.Label))
+		c++
+	}
+	assert.Equal(t, 3, c)
+}
+
+func TestMetrics_incPing(t *testing.T) {
+	m := newMetrics()
+	m.register()
+	defer m.unregister()
+
+	// do some incs
+	for _, r := range []result{succeeded, errored} {
+		m.incPing(r)
+	}
+
+	// check we have a single count for each ping result
+	pingMetrics := make(chan prom.Metric, 2)
+	m.ping.Collect(pingMetrics)
+	close(pingMetrics)
+	c := 0
+	for pingMetric := range pingMetrics {
+		written := dto.Metric{}
+		pingMetric.Write(&written)
+		assert.Equal(t, float64(1.0), *written.Counter.Value)
+		assert.Equal(t, 1, len(written.Label))
+		c++
+	}
+	assert.Equal(t, 2, c)
+}
+
+func TestMetrics_addPeers_incEviction(t *testing.T) {
+	m := newMetrics()
+	m.register()
+	defer m.unregister()
+
+	m.addPeers(3)
+	m.addPeers(2)
+	m.incEviction()
+
+	written := dto.Metric{}
+	m.added.Write(&written)
+	assert.Equal(t, float64(5.0), *written.Counter.Value)
+	m.eviction.Write(&written)
+	assert.Equal(t, float64(1.0), *written.Counter.Value)
+}
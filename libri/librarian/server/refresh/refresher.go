@@ -0,0 +1,312 @@
+package refresh
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/comm"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/routing"
+	"github.com/drausin/libri/libri/librarian/server/search"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultInterval is the default amount of time between refreshes, which is also how long a
+	// bucket may go without new peers before a refresh searches for more.
+	DefaultInterval = 15 * time.Minute
+
+	// DefaultNPings is the default number of least-recently-seen peers pinged each refresh.
+	DefaultNPings = uint(8)
+
+	// DefaultPingTimeout is the default timeout for each ping.
+	DefaultPingTimeout = 5 * time.Second
+
+	// DefaultReportMetrics is the default setting for whether the refresher reports Prometheus
+	// metrics.
+	DefaultReportMetrics = true
+
+	// pings ask for only a single peer, since they just check the peer is responsive
+	pingNPeers = 1
+
+	// logger keys
+	logNStaleBuckets = "n_stale_buckets"
+	logNAdded        = "n_added"
+	logNPinged       = "n_pinged"
+	logNEvicted      = "n_evicted"
+)
+
+var errUnexpectedRequestID = errors.New("unexpected ping response request ID")
+
+// Parameters is the refresher parameters.
+type Parameters struct {
+	Interval      time.Duration
+	NPings        uint
+	PingTimeout   time.Duration
+	ReportMetrics bool
+}
+
+// NewDefaultParameters returns the default refresher parameters.
+func NewDefaultParameters() *Parameters {
+	return &Parameters{
+		Interval:      DefaultInterval,
+		NPings:        DefaultNPings,
+		PingTimeout:   DefaultPingTimeout,
+		ReportMetrics: DefaultReportMetrics,
+	}
+}
+
+// Refresher is a long-running routine that keeps the routing table from drifting. Each interval,
+// it searches for a random ID in each bucket no peers have been pushed into recently, adding the
+// peers it finds to the table, and pings the least-recently-seen peers in the table, evicting
+// those the Doctor then deems unhealthy.
+type Refresher interface {
+	// Start starts refreshing, returning once the refresher is stopped.
+	Start()
+
+	// Stop gracefully stops refreshing.
+	Stop()
+}
+
+type refresher struct {
+	peerID        ecid.ID
+	orgID         ecid.ID
+	peerSigner    client.Signer
+	orgSigner     client.Signer
+	rt            routing.Table
+	searcher      search.Searcher
+	finderCreator client.FinderCreator
+	rec           comm.QueryRecorder
+	getter        comm.QueryGetter
+	doc           comm.Doctor
+	params        *Parameters
+	searchParams  *search.Parameters
+	metrics       *metrics
+	stop          chan struct{}
+	started       chan struct{}
+	stopped       chan struct{}
+	rng           *rand.Rand
+	logger        *zap.Logger
+	mu            sync.Mutex
+}
+
+// NewRefresher returns a new Refresher. The QueryGetter determines when peers were last seen,
+// and the QueryRecorder records the outcomes of pings.
+func NewRefresher(
+	peerID ecid.ID,
+	orgID ecid.ID,
+	peerSigner client.Signer,
+	orgSigner client.Signer,
+	rt routing.Table,
+	searcher search.Searcher,
+	finderCreator client.FinderCreator,
+	rec comm.QueryRecorder,
+	getter comm.QueryGetter,
+	doc comm.Doctor,
+	params *Parameters,
+	searchParams *search.Parameters,
+	rng *rand.Rand,
+	logger *zap.Logger,
+) Refresher {
+	return &refresher{
+		peerID:        peerID,
+		orgID:         orgID,
+		peerSigner:    peerSigner,
+		orgSigner:     orgSigner,
+		rt:            rt,
+		searcher:      searcher,
+		finderCreator: finderCreator,
+		rec:           rec,
+		getter:        getter,
+		doc:           doc,
+		params:        params,
+		searchParams:  searchParams,
+		metrics:       newMetrics(),
+		stop:          make(chan struct{}),
+		started:       make(chan struct{}),
+		stopped:       make(chan struct{}),
+		rng:           rng,
+		logger:        logger,
+	}
+}
+
+func (r *refresher) Start() {
+	select {
+	case <-r.stop:
+		return // stopped before ever starting
+	default:
+		close(r.started)
+	}
+	defer close(r.stopped)
+	if r.params.ReportMetrics {
+		r.metrics.register()
+	}
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-time.After(r.params.Interval):
+			r.refresh()
+		}
+	}
+}
+
+func (r *refresher) Stop() {
+	r.logger.Info("ending refresher")
+	r.mu.Lock()
+	select {
+	case <-r.stop: // already stopped
+	default:
+		close(r.stop)
+	}
+	r.mu.Unlock()
+	select {
+	case <-r.started:
+		<-r.stopped
+		if r.params.ReportMetrics {
+			r.metrics.unregister()
+		}
+	default: // never started, so nothing to wait on
+	}
+	r.logger.Debug("ended refresher")
+}
+
+// refresh refreshes stale buckets and then pings the least-recently-seen peers.
+func (r *refresher) refresh() {
+	targets := r.rt.Stale(time.Now().Add(-r.params.Interval), r.rng)
+	nAdded := 0
+	for _, target := range targets {
+		nAdded += r.refreshBucket(target)
+	}
+	pinged := r.leastRecentlySeen(r.params.NPings)
+	nEvicted := r.pingAndEvict(pinged)
+	r.logger.Info("refreshed routing table",
+		zap.Int(logNStaleBuckets, len(targets)),
+		zap.Int(logNAdded, nAdded),
+		zap.Int(logNPinged, len(pinged)),
+		zap.Int(logNEvicted, nEvicted),
+	)
+}
+
+// refreshBucket searches for the target, pushing the peers that respond into the routing table,
+// and returns the number of peers added.
+func (r *refresher) refreshBucket(target id.ID) int {
+	s := search.NewSearch(r.peerID, r.orgID, target, r.searchParams)
+	seeds := r.rt.Find(target, s.Params.NClosestResponses)
+	if err := r.searcher.Search(s, seeds); err != nil {
+		r.logger.Debug("bucket refresh search errored", zap.Object("search", s),
+			zap.Error(err))
+		r.metrics.incRefresh(errored)
+		return 0
+	}
+	if len(s.Result.Responded) == 0 {
+		r.metrics.incRefresh(exhausted)
+		return 0
+	}
+	r.metrics.incRefresh(succeeded)
+	nAdded := 0
+	for _, p := range s.Result.Responded {
+		if r.rt.Push(p) == routing.Added {
+			nAdded++
+		}
+	}
+	r.metrics.addPeers(nAdded)
+	return nAdded
+}
+
+// leastRecentlySeen returns the k peers in the routing table with the oldest latest successful
+// request from or response to this peer.
+func (r *refresher) leastRecentlySeen(k uint) []peer.Peer {
+	ps := r.rt.Peers()
+	lastSeen := make(map[string]time.Time, len(ps))
+	for _, p := range ps {
+		lastSeen[p.ID().String()] = r.lastSeen(p.ID())
+	}
+	sort.SliceStable(ps, func(i, j int) bool {
+		return lastSeen[ps[i].ID().String()].Before(lastSeen[ps[j].ID().String()])
+	})
+	if len(ps) > int(k) {
+		ps = ps[:k]
+	}
+	return ps
+}
+
+func (r *refresher) lastSeen(peerID id.ID) time.Time {
+	outcomes := r.getter.Get(peerID, api.All)
+	lastSeen := outcomes[comm.Response][comm.Success].Latest
+	if latestRq := outcomes[comm.Request][comm.Success].Latest; latestRq.After(lastSeen) {
+		lastSeen = latestRq
+	}
+	return lastSeen
+}
+
+// pingAndEvict concurrently pings each of the peers, evicting from the routing table those
+// deemed unhealthy afterwards, and returns the number evicted.
+func (r *refresher) pingAndEvict(ps []peer.Peer) int {
+	evicted := make(chan struct{}, len(ps))
+	wg := new(sync.WaitGroup)
+	for _, p := range ps {
+		wg.Add(1)
+		go func(p peer.Peer) {
+			defer wg.Done()
+			if err := r.ping(p); err != nil {
+				r.logger.Debug("peer ping errored", zap.Stringer("peer_id", p.ID()),
+					zap.Error(err))
+				r.metrics.incPing(errored)
+			} else {
+				r.metrics.incPing(succeeded)
+			}
+			if !r.doc.Healthy(p.ID()) && r.rt.Evict(p.ID()) {
+				r.metrics.incEviction()
+				evicted <- struct{}{}
+			}
+		}(p)
+	}
+	wg.Wait()
+	close(evicted)
+	return len(evicted)
+}
+
+// ping sends the peer a Find request for its own ID, recording the outcome.
+func (r *refresher) ping(p peer.Peer) error {
+	err := r.find(p)
+	if err != nil {
+		comm.MaybeRecordRpErr(r.rec, p.ID(), api.Find, err)
+		return err
+	}
+	r.rec.Record(p.ID(), api.Find, comm.Response, comm.Success)
+
+	// re-heap with updated outcome
+	r.rt.Push(p)
+	return nil
+}
+
+func (r *refresher) find(p peer.Peer) error {
+	lc, err := r.finderCreator.Create(peer.DialAddress(p))
+	if err != nil {
+		return err
+	}
+	rq := client.NewFindRequest(r.peerID, r.orgID, p.ID(), pingNPeers)
+	ctx, cancel, err := client.NewSignedTimeoutContext(r.peerSigner, r.orgSigner, rq,
+		r.params.PingTimeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	rp, err := lc.Find(ctx, rq)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(rp.Metadata.RequestId, rq.Metadata.RequestId) {
+		return errUnexpectedRequestID
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package refresh
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/comm"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/routing"
+	"github.com/drausin/libri/libri/librarian/server/search"
+	prom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+func TestNewDefaultParameters(t *testing.T) {
+	p := NewDefaultParameters()
+	assert.NotZero(t, p.Interval)
+	assert.NotZero(t, p.NPings)
+	assert.NotZero(t, p.PingTimeout)
+	assert.True(t, p.ReportMetrics)
+}
+
+func TestRefresher_StartStop(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := &Parameters{Interval: 10 * time.Millisecond, NPings: 2, PingTimeout: time.Second}
+	r, _ := newTestRefresher(rng, 8, params, &fixedSearcher{}, &testFinderCreator{})
+
+	done := make(chan struct{})
+	go func() {
+		r.Start()
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	r.Stop()
+	<-done
+	assert.True(t, countMetric(r.(*refresher).metrics.ping) > 0)
+
+	// stopping again or starting after stopped does nothing
+	r.Stop()
+	r.Start()
+
+	// stopping before starting doesn't wait on anything
+	r2, _ := newTestRefresher(rng, 8, params, &fixedSearcher{}, &testFinderCreator{})
+	r2.Stop()
+	r2.Start()
+}
+
+func TestRefresher_refresh(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+
+	// negative interval makes all buckets stale
+	params := &Parameters{Interval: -time.Minute, NPings: 4, PingTimeout: time.Second}
+	newPeers := peer.NewTestPeers(rng, 4)
+	r, rt := newTestRefresher(rng, 64, params, &fixedSearcher{responded: newPeers},
+		&testFinderCreator{})
+	nBuckets, nPeers := rt.NumBuckets(), rt.NumPeers()
+
+	r.(*refresher).refresh()
+
+	m := r.(*refresher).metrics
+	assert.Equal(t, nBuckets, countMetric(m.refresh))
+	assert.Equal(t, int(params.NPings), countMetric(m.ping))
+	assert.True(t, rt.NumPeers() >= nPeers)
+}
+
+func TestRefresher_refreshBucket(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := NewDefaultParameters()
+	target := id.NewPseudoRandom(rng)
+
+	// search finds new peers
+	newPeers := peer.NewTestPeers(rng, 4)
+	r, rt := newTestRefresher(rng, 0, params, &fixedSearcher{responded: newPeers},
+		&testFinderCreator{})
+	nAdded := r.(*refresher).refreshBucket(target)
+	assert.Equal(t, len(newPeers), nAdded)
+	assert.Equal(t, len(newPeers), rt.NumPeers())
+	checkMetric(t, r.(*refresher).metrics.refresh, 1, succeeded)
+	assert.Equal(t, float64(nAdded), metricValue(r.(*refresher).metrics.added))
+
+	// search finds no peers
+	r, rt = newTestRefresher(rng, 0, params, &fixedSearcher{}, &testFinderCreator{})
+	nAdded = r.(*refresher).refreshBucket(target)
+	assert.Zero(t, nAdded)
+	assert.Zero(t, rt.NumPeers())
+	checkMetric(t, r.(*refresher).metrics.refresh, 1, exhausted)
+
+	// search errors
+	r, rt = newTestRefresher(rng, 0, params, &fixedSearcher{err: errors.New("some error")},
+		&testFinderCreator{})
+	nAdded = r.(*refresher).refreshBucket(target)
+	assert.Zero(t, nAdded)
+	assert.Zero(t, rt.NumPeers())
+	checkMetric(t, r.(*refresher).metrics.refresh, 1, errored)
+}
+
+func TestRefresher_leastRecentlySeen(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	r, rt := newTestRefresher(rng, 16, NewDefaultParameters(), &fixedSearcher{},
+		&testFinderCreator{})
+	ps := rt.Peers()
+	rec := r.(*refresher).rec
+
+	// see all but first two peers, in order
+	for i, p := range ps[2:] {
+		time.Sleep(time.Millisecond)
+		if i%2 == 0 {
+			rec.Record(p.ID(), api.Find, comm.Response, comm.Success)
+		} else {
+			rec.Record(p.ID(), api.Store, comm.Request, comm.Success)
+		}
+	}
+
+	// unseen peers are least recently seen, followed by seen peers in order
+	lrs := r.(*refresher).leastRecentlySeen(6)
+	assert.Equal(t, 6, len(lrs))
+	assert.ElementsMatch(t, ps[:2], lrs[:2])
+	assert.Equal(t, ps[2:6], lrs[2:])
+
+	// errors don't count as seen
+	rec.Record(ps[2].ID(), api.Find, comm.Response, comm.Error)
+	lrs = r.(*refresher).leastRecentlySeen(3)
+	assert.Equal(t, ps[2], lrs[2])
+
+	// k larger than table returns all peers
+	assert.Equal(t, len(ps), len(r.(*refresher).leastRecentlySeen(64)))
+}
+
+func TestRefresher_pingAndEvict(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	fc := &testFinderCreator{errAddresses: make(map[string]struct{})}
+	r, rt := newTestRefresher(rng, 16, NewDefaultParameters(), &fixedSearcher{}, fc)
+	ps := rt.Peers()
+	nPeers := len(ps)
+	failing := ps[:4]
+	for _, p := range failing {
+		fc.errAddresses[peer.DialAddress(p)] = struct{}{}
+	}
+
+	nEvicted := r.(*refresher).pingAndEvict(ps[:8])
+	assert.Equal(t, len(failing), nEvicted)
+	assert.Equal(t, nPeers-len(failing), rt.NumPeers())
+	for i, p := range ps {
+		_, in := rt.Get(p.ID())
+		assert.Equal(t, i >= len(failing), in)
+	}
+	m := r.(*refresher).metrics
+	checkMetric(t, m.ping, 4, succeeded)
+	checkMetric(t, m.ping, 4, errored)
+	assert.Equal(t, float64(len(failing)), metricValue(m.eviction))
+
+	// pinged peers are now seen
+	for _, p := range ps[4:8] {
+		assert.False(t, r.(*refresher).lastSeen(p.ID()).IsZero())
+	}
+}
+
+func TestRefresher_ping_err(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	p := peer.NewTestPeer(rng, 0)
+	cases := map[string]*refresher{
+		"create error": {finderCreator: &testFinderCreator{err: errors.New("some error")}},
+		"signer error": {
+			finderCreator: &testFinderCreator{},
+			peerSigner:    &client.TestErrSigner{},
+		},
+		"find error": {
+			finderCreator: &testFinderCreator{
+				errAddresses: map[string]struct{}{peer.DialAddress(p): {}},
+			},
+		},
+		"bad request ID": {finderCreator: &testFinderCreator{badRequestID: true}},
+	}
+	for desc, r := range cases {
+		rec := comm.NewQueryRecorderGetter(comm.NewAlwaysKnower())
+		r.peerID = ecid.NewPseudoRandom(rng)
+		if r.peerSigner == nil {
+			r.peerSigner = &client.TestNoOpSigner{}
+		}
+		r.orgSigner = &client.TestNoOpSigner{}
+		r.rec = rec
+		r.params = NewDefaultParameters()
+		assert.NotNil(t, r.ping(p), desc)
+		outcomes := rec.Get(p.ID(), api.Find)
+		assert.False(t, outcomes[comm.Response][comm.Error].Latest.IsZero(), desc)
+	}
+}
+
+func newTestRefresher(
+	rng *rand.Rand, nPeers int, params *Parameters, s search.Searcher, fc client.FinderCreator,
+) (Refresher, routing.Table) {
+	rec := comm.NewQueryRecorderGetter(comm.NewAlwaysKnower())
+	doc := comm.NewResponseTimeDoctor(rec)
+	peerID := ecid.NewPseudoRandom(rng)
+	rt, _ := routing.NewWithPeers(peerID.ID(), comm.NewRpPreferer(rec), doc,
+		routing.NewDefaultParameters(), peer.NewTestPeers(rng, nPeers))
+	r := NewRefresher(
+		peerID,
+		nil,
+		&client.TestNoOpSigner{},
+		&client.TestNoOpSigner{},
+		rt,
+		s,
+		fc,
+		rec,
+		rec,
+		doc,
+		params,
+		search.NewDefaultParameters(),
+		rng,
+		zap.NewNop(),
+	)
+	return r, rt
+}
+
+type fixedSearcher struct {
+	responded []peer.Peer
+	err       error
+}
+
+func (s *fixedSearcher) Search(search *search.Search, seeds []peer.Peer) error {
+	if s.err != nil {
+		return s.err
+	}
+	for _, p := range s.responded {
+		search.Result.Responded[p.ID().String()] = p
+	}
+	return nil
+}
+
+type testFinderCreator struct {
+	errAddresses map[string]struct{}
+	badRequestID bool
+	err          error
+}
+
+func (c *testFinderCreator) Create(address string) (api.Finder, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	_, isErr := c.errAddresses[address]
+	return &testFinder{err: isErr, badRequestID: c.badRequestID}, nil
+}
+
+type testFinder struct {
+	err          bool
+	badRequestID bool
+}
+
+func (f *testFinder) Find(ctx context.Context, rq *api.FindRequest, opts ...grpc.CallOption) (
+	*api.FindResponse, error) {
+	if f.err {
+		return nil, errors.New("some Find error")
+	}
+	requestID := rq.Metadata.RequestId
+	if f.badRequestID {
+		requestID = []byte{1, 2, 3}
+	}
+	return &api.FindResponse{Metadata: &api.ResponseMetadata{RequestId: requestID}}, nil
+}
+
+func checkMetric(t *testing.T, metrics *prom.CounterVec, expected int, label result) {
+	written := dto.Metric{}
+	err := metrics.WithLabelValues(label.String()).Write(&written)
+	assert.Nil(t, err)
+	assert.Equal(t, float64(expected), *written.Counter.Value)
+}
+
+func countMetric(metrics *prom.CounterVec) int {
+	collected := make(chan prom.Metric, 16)
+	metrics.Collect(collected)
+	close(collected)
+	total := 0.0
+	for m := range collected {
+		written := dto.Metric{}
+		_ = m.Write(&written)
+		total += *written.Counter.Value
+	}
+	return int(total)
+}
+
+func metricValue(metric prom.Counter) float64 {
+	written := dto.Metric{}
+	_ = metric.Write(&written)
+	return *written.Counter.Value
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"container/heap"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// replayEntrySize is the approximate memory in bytes used by each remembered request ID or
+	// nonce, including the map and heap overhead.
+	replayEntrySize = 256
+)
+
+var (
+	errReplayedRequest = errors.New("request ID or signature nonce already recently received " +
+		"from requester")
+
+	errInvalidReplayCacheSize = errors.New("replay cache memory must fit at least one entry")
+)
+
+// replayCache remembers the request IDs and signature nonces recently received from each
+// requester in order to reject replayed requests. Each is kept until the signature of the request
+// it came with expires, after which the request would be rejected anyway. When the cache is full,
+// the entries expiring soonest are evicted, so a flood of requests can't lock other requesters
+// out. Since the cache is only checked for allowed requests, the request limits bound how quickly
+// a requester can fill it.
+type replayCache struct {
+	maxEntries int
+	entries    map[string]time.Time
+	expiring   replayQueue
+	now        func() time.Time
+	mu         sync.Mutex
+}
+
+func newReplayCache(maxMemory uint) (*replayCache, error) {
+	maxEntries := int(maxMemory / replayEntrySize)
+	if maxEntries == 0 {
+		return nil, errInvalidReplayCacheSize
+	}
+	return &replayCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]time.Time),
+		expiring:   make(replayQueue, 0),
+		now:        time.Now,
+	}, nil
+}
+
+// Check records that a request with the given IDs (e.g., its request ID and signature nonce)
+// was received from the requester with the given public key and will be remembered until the
+// given expiration time, returning an error if any of its IDs were already received from the
+// requester.
+func (c *replayCache) Check(requesterPub []byte, expires time.Time, ids ...[]byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(c.now())
+
+	keys := make([]string, len(ids))
+	for i, rqID := range ids {
+		keys[i] = string(requesterPub) + string(rqID)
+		if _, in := c.entries[keys[i]]; in {
+			return errReplayedRequest
+		}
+	}
+	for _, key := range keys {
+		if len(c.entries) >= c.maxEntries {
+			c.evict()
+		}
+		c.entries[key] = expires
+		heap.Push(&c.expiring, &replayEntry{key: key, expires: expires})
+	}
+	return nil
+}
+
+// expire removes the entries whose requests' signatures have expired.
+func (c *replayCache) expire(now time.Time) {
+	for len(c.expiring) > 0 && !now.Before(c.expiring[0].expires) {
+		c.evict()
+	}
+}
+
+// evict removes the entry expiring soonest.
+func (c *replayCache) evict() {
+	e := heap.Pop(&c.expiring).(*replayEntry)
+	delete(c.entries, e.key)
+}
+
+type replayEntry struct {
+	key     string
+	expires time.Time
+}
+
+// replayQueue is a min-heap of entries by expiration time.
+type replayQueue []*replayEntry
+
+func (q replayQueue) Len() int { return len(q) }
+
+func (q replayQueue) Less(i, j int) bool { return q[i].expires.Before(q[j].expires) }
+
+func (q replayQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *replayQueue) Push(x interface{}) { *q = append(*q, x.(*replayEntry)) }
+
+func (q *replayQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return e
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReplayCache_err(t *testing.T) {
+	c, err := newReplayCache(replayEntrySize - 1)
+	assert.Equal(t, errInvalidReplayCacheSize, err)
+	assert.Nil(t, c)
+}
+
+func TestReplayCache_Check(t *testing.T) {
+	c := newTestReplayCache(t)
+	now := time.Unix(1500000000, 0)
+	c.now = func() time.Time { return now }
+	expires := now.Add(time.Minute)
+	requester1, requester2 := []byte{1}, []byte{2}
+	rqID1, rqID2 := []byte{1, 1}, []byte{2, 2}
+	nonce1, nonce2 := []byte{1, 1, 1}, []byte{2, 2, 2}
+
+	assert.Nil(t, c.Check(requester1, expires, rqID1, nonce1))
+	assert.Equal(t, errReplayedRequest, c.Check(requester1, expires, rqID1, nonce2))
+
+	// same nonce with different request ID is also a replay
+	assert.Equal(t, errReplayedRequest, c.Check(requester1, expires, rqID2, nonce1))
+
+	// same request ID from different requester is fine
+	assert.Nil(t, c.Check(requester2, expires, rqID1, nonce1))
+
+	// different request ID from same requester is fine
+	assert.Nil(t, c.Check(requester1, expires, rqID2, nonce2))
+
+	// each request ID is remembered until its own expiration
+	assert.Nil(t, c.Check(requester2, now.Add(2*time.Minute), rqID2, nonce2))
+	now = expires
+	assert.Nil(t, c.Check(requester1, now.Add(time.Minute), rqID1, nonce1))
+	assert.Equal(t, errReplayedRequest, c.Check(requester1, expires, rqID1, nonce1))
+	assert.Equal(t, errReplayedRequest, c.Check(requester2, expires, rqID2, nonce2))
+	assert.Len(t, c.entries, 4)
+	assert.Len(t, c.expiring, 4)
+}
+
+func TestReplayCache_Check_full(t *testing.T) {
+	c, err := newReplayCache(3 * replayEntrySize)
+	assert.Nil(t, err)
+	now := time.Unix(1500000000, 0)
+	c.now = func() time.Time { return now }
+	requester1, requester2 := []byte{1}, []byte{2}
+
+	assert.Nil(t, c.Check(requester1, now.Add(2*time.Minute), []byte{1}))
+	assert.Nil(t, c.Check(requester1, now.Add(time.Minute), []byte{2}))
+	assert.Nil(t, c.Check(requester1, now.Add(3*time.Minute), []byte{3}))
+
+	// when full, new requests evict the entries expiring soonest rather than being rejected
+	assert.Nil(t, c.Check(requester2, now.Add(time.Minute), []byte{1}))
+	assert.Len(t, c.entries, 3)
+	assert.Nil(t, c.Check(requester1, now.Add(time.Minute), []byte{2}))
+	assert.Equal(t, errReplayedRequest, c.Check(requester1, now, []byte{3}))
+
+	// expired entries are removed before any live ones are evicted
+	now = now.Add(time.Minute)
+	assert.Nil(t, c.Check(requester2, now.Add(time.Minute), []byte{2}))
+	assert.Equal(t, errReplayedRequest, c.Check(requester1, now, []byte{1}))
+	assert.Equal(t, errReplayedRequest, c.Check(requester1, now, []byte{3}))
+	assert.Len(t, c.entries, 3)
+	assert.Len(t, c.expiring, 3)
+}
+
+func newTestReplayCache(t *testing.T) *replayCache {
+	c, err := newReplayCache(DefaultReplayCacheMemory)
+	assert.Nil(t, err)
+	return c
+}
@@ -104,6 +104,7 @@ type replicator struct {
 	metrics          *metrics
 	underreplicated  chan *verify.Verify
 	stop             chan struct{}
+	started          chan struct{}
 	stopped          chan struct{}
 	errs             chan error
 	fatal            chan error
@@ -140,6 +141,7 @@ func NewReplicator(
 		underreplicated:  make(chan *verify.Verify, underreplicatedQueueSize),
 		errs:             make(chan error, errQueueSize),
 		stop:             make(chan struct{}),
+		started:          make(chan struct{}),
 		stopped:          make(chan struct{}),
 		fatal:            make(chan error, 1),
 		rng:              rng,
@@ -148,11 +150,26 @@ func NewReplicator(
 }
 
 func (r *replicator) Start() error {
+	// check stop and mark started under the lock so a concurrent Stop() either prevents the
+	// start or waits for it to finish
+	stoppedBeforeStart := false
+	r.wrapLock(func() {
+		select {
+		case <-r.stop:
+			stoppedBeforeStart = true
+		default:
+			close(r.started)
+		}
+	})
+	if stoppedBeforeStart {
+		return nil
+	}
 
 	// listen for fatal error
 	var err error
 	go func() {
-		err = <-r.fatal
+		err2 := <-r.fatal
+		r.wrapLock(func() { err = err2 })
 		r.Stop()
 	}()
 
@@ -175,20 +192,30 @@ func (r *replicator) Start() error {
 	}
 	wg.Wait()
 
-	return err
+	var fatalErr error
+	r.wrapLock(func() { fatalErr = err })
+	return fatalErr
 }
 
 func (r *replicator) Stop() {
 	r.logger.Info("ending replicator")
-	safeClose(r.stop)
+	started := false
 	r.wrapLock(func() {
+		safeClose(r.stop)
 		safeCloseErrChan(r.errs)
 		safeCloseVerifyChan(r.underreplicated)
+		select {
+		case <-r.started:
+			started = true
+		default: // never started, so nothing to wait on
+		}
 	})
 	if r.replicatorParams.ReportMetrics {
 		r.metrics.unregister()
 	}
-	<-r.stopped
+	if started {
+		<-r.stopped
+	}
 	r.logger.Debug("ended replicator")
 }
 
@@ -225,6 +252,12 @@ func (r *replicator) verify() {
 }
 
 func (r *replicator) verifyValue(key id.ID, value []byte) {
+	if isPointer(value) {
+		// replicas of a mutable pointer may hold older versions, whose MACs differ from this
+		// one's, so pointers aren't verified; each version is instead stored to the closest peers
+		// when published, and gets resolve the newest version among them
+		return
+	}
 	pause := make(chan struct{})
 	go func() {
 		time.Sleep(r.replicatorParams.VerifyInterval)
@@ -274,10 +307,20 @@ func (r *replicator) verifyValue(key id.ID, value []byte) {
 	}
 }
 
+// isPointer returns whether the stored value is a Pointer document.
+func isPointer(value []byte) bool {
+	doc := &api.Document{}
+	if err := proto.Unmarshal(value, doc); err != nil {
+		return false
+	}
+	_, ok := doc.Contents.(*api.Document_Pointer)
+	return ok
+}
+
 func (r *replicator) replicate(wg *sync.WaitGroup) {
 	defer wg.Done()
 	for v := range r.underreplicated {
-		s := newStore(r.peerID, r.orgID, v, *r.storeParams)
+		s := NewStore(r.peerID, r.orgID, v, *r.storeParams)
 		// empty seeds b/c verification has already, in effect, replaced the search component of
 		// the store operation
 		if err := r.storer.Store(s, []peer.Peer{}); err != nil {
@@ -305,7 +348,9 @@ func (r *replicator) wrapLock(operation func()) {
 	operation()
 }
 
-func newStore(peerID, orgID ecid.ID, v *verify.Verify, storeParams store.Parameters) *store.Store {
+// NewStore creates a store operation that tops up the replicas of a document a verification
+// found to be under-replicated, reusing the closest peers it found instead of searching again.
+func NewStore(peerID, orgID ecid.ID, v *verify.Verify, storeParams store.Parameters) *store.Store {
 	value := &api.Document{}
 	cerrors.MaybePanic(proto.Unmarshal(v.Value, value)) // should never happen
 	searchParams := &search.Parameters{
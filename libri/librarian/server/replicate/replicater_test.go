@@ -139,6 +139,36 @@ func TestReplicator_StartStop(t *testing.T) {
 	time.Sleep(1 * time.Second)
 }
 
+func TestReplicator_StopBeforeStart(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	assert.Nil(t, err)
+	defer cleanup()
+	defer kvdb.Close()
+
+	rt, peerID, _, _ := routing.NewTestWithPeers(rng, 10)
+	r := NewReplicator(
+		peerID,
+		ecid.NewPseudoRandom(rng),
+		rt,
+		storage.NewDocumentSLD(kvdb),
+		&fixedVerifier{},
+		&fixedStorer{},
+		NewDefaultParameters(),
+		verify.NewDefaultParameters(),
+		store.NewDefaultParameters(),
+		rng,
+		zap.NewNop(),
+	)
+
+	// stopping a replicator that hasn't started shouldn't block
+	r.Stop()
+
+	// and starting it afterwards should return immediately
+	err = r.Start()
+	assert.Nil(t, err)
+}
+
 func TestReplicator_verify(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	rt, selfID, _, _ := routing.NewTestWithPeers(rng, 10)
@@ -303,6 +333,20 @@ func TestReplicator_verifyValue(t *testing.T) {
 	default:
 	}
 	checkPromMetric(t, r.metrics.verification, 1, errored, unknown)
+
+	// check that pointers aren't verified
+	pointer, _ := api.NewTestPointer(rng)
+	pointerBytes, err := proto.Marshal(&api.Document{
+		Contents: &api.Document_Pointer{Pointer: pointer},
+	})
+	assert.Nil(t, err)
+	r.verifyValue(api.GetPointerKey(pointer.AuthorPublicKey, pointer.Name), pointerBytes)
+	select {
+	case <-r.errs:
+		assert.True(t, false) // shouldn't get an error or nil from verifying
+	default:
+	}
+	checkPromMetric(t, r.metrics.verification, 1, errored, unknown)
 }
 
 func checkPromMetric(t *testing.T, metrics *prom.CounterVec, expected int, labels ...fmt.Stringer) {
@@ -430,7 +474,7 @@ func TestNewStore(t *testing.T) {
 	assert.True(t, v.UnderReplicated())
 	assert.False(t, v.FullyReplicated())
 
-	s := newStore(peerID, orgID, v, *store.NewDefaultParameters())
+	s := NewStore(peerID, orgID, v, *store.NewDefaultParameters())
 	assert.Equal(t, verifyParams.NMaxErrors, s.Search.Params.NMaxErrors)
 	assert.Equal(t, uint(1), s.Params.NReplicas)
 	assert.Equal(t, uint(4), s.Search.Params.NClosestResponses)
@@ -1,8 +1,11 @@
 package server
 
 import (
+	"crypto/ecdsa"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/drausin/libri/libri/common/ecid"
 	"github.com/drausin/libri/libri/common/id"
@@ -12,20 +15,49 @@ import (
 	"golang.org/x/net/context"
 )
 
+var errMissingOrgSignature = errors.New("request with organization public key missing " +
+	"organization signature")
+
+// bodyHashKey is the context key of the hash of the body of an HTTP gateway request.
+type bodyHashKey struct{}
+
+// newBodyHashContext returns a context whose request signatures are over the given hash of the
+// body of an HTTP gateway request rather than over the protobuf encoding of the request.
+func newBodyHashContext(ctx context.Context, hash [sha256.Size]byte) context.Context {
+	return context.WithValue(ctx, bodyHashKey{}, hash)
+}
+
 // RequestVerifier verifies requests by checking the signature in the context.
 type RequestVerifier interface {
 	Verify(ctx context.Context, msg proto.Message, meta *api.RequestMetadata) error
+
+	// CheckReplay records that the request with the signature in the context was received,
+	// returning an error if it was already received. It should only be called once the request
+	// has been verified and allowed, so invalid or throttled requests don't fill the replay cache.
+	CheckReplay(ctx context.Context, meta *api.RequestMetadata) error
 }
 
 type verifier struct {
 	sigVerifier client.Verifier
+	clockSkew   time.Duration
+	replays     *replayCache
 }
 
-// NewRequestVerifier creates a new RequestVerifier instance.
-func NewRequestVerifier() RequestVerifier {
-	return &verifier{
-		sigVerifier: client.NewVerifier(),
+// NewRequestVerifier creates a new RequestVerifier instance tolerating the given clock skew
+// between requesters and this server. It rejects replayed requests, remembering recent request
+// IDs and signature nonces in up to replayCacheMemory bytes.
+func NewRequestVerifier(
+	clockSkew time.Duration, replayCacheMemory uint,
+) (RequestVerifier, error) {
+	replays, err := newReplayCache(replayCacheMemory)
+	if err != nil {
+		return nil, err
 	}
+	return &verifier{
+		sigVerifier: client.NewClockSkewVerifier(clockSkew),
+		clockSkew:   clockSkew,
+		replays:     replays,
+	}, nil
 }
 
 func (rv *verifier) Verify(
@@ -46,12 +78,46 @@ func (rv *verifier) Verify(
 		return fmt.Errorf("invalid RequestId length: %v; expected length %v",
 			len(meta.RequestId), id.Length)
 	}
-	if err = rv.sigVerifier.Verify(encToken, pubKey, msg); err != nil || encOrgToken == "" {
+	if err = rv.verifySig(ctx, encToken, pubKey, msg); err != nil {
 		return err
 	}
-	orgPubKey, err := ecid.FromPublicKeyBytes(meta.OrgPubKey)
+	if encOrgToken == "" && len(meta.OrgPubKey) > 0 {
+		return errMissingOrgSignature
+	}
+	if encOrgToken != "" {
+		orgPubKey, err := ecid.FromPublicKeyBytes(meta.OrgPubKey)
+		if err != nil {
+			return err
+		}
+		if err = rv.verifySig(ctx, encOrgToken, orgPubKey, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rv *verifier) CheckReplay(ctx context.Context, meta *api.RequestMetadata) error {
+	encToken, _, err := client.FromSignatureContext(ctx)
+	if err != nil {
+		return err
+	}
+	claims, err := client.ParseClaims(encToken)
 	if err != nil {
 		return err
 	}
-	return rv.sigVerifier.Verify(encOrgToken, orgPubKey, msg)
+	// a request is only accepted until its signature expires (give or take the clock skew), so
+	// its IDs only need to be remembered until then
+	expires := time.Unix(claims.ExpiresAt, 0).Add(rv.clockSkew)
+	return rv.replays.Check(meta.PubKey, expires, meta.RequestId, []byte(claims.Nonce))
+}
+
+// verifySig verifies the signature over the hash of the HTTP gateway request body in the context,
+// if there is one, or over the message otherwise.
+func (rv *verifier) verifySig(
+	ctx context.Context, encToken string, pubKey *ecdsa.PublicKey, msg proto.Message,
+) error {
+	if hash, ok := ctx.Value(bodyHashKey{}).([sha256.Size]byte); ok {
+		return rv.sigVerifier.VerifyHash(encToken, pubKey, hash)
+	}
+	return rv.sigVerifier.Verify(encToken, pubKey, msg)
 }
@@ -2,10 +2,14 @@ package server
 
 import (
 	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/client"
 	"github.com/golang/protobuf/proto"
@@ -22,9 +26,40 @@ func (asv *alwaysSigVerifier) Verify(encToken string, fromPubKey *ecdsa.PublicKe
 	return nil
 }
 
+func (asv *alwaysSigVerifier) VerifyHash(encToken string, fromPubKey *ecdsa.PublicKey,
+	hash [sha256.Size]byte) error {
+	return nil
+}
+
+// neverSigVerifier implements the signature.Verifier interface but never verifies signatures.
+type neverSigVerifier struct{}
+
+func (nsv *neverSigVerifier) Verify(encToken string, fromPubKey *ecdsa.PublicKey,
+	m proto.Message) error {
+	return errors.New("some verify error")
+}
+
+func (nsv *neverSigVerifier) VerifyHash(encToken string, fromPubKey *ecdsa.PublicKey,
+	hash [sha256.Size]byte) error {
+	return errors.New("some verify error")
+}
+
+func TestNewRequestVerifier(t *testing.T) {
+	rv, err := NewRequestVerifier(DefaultClockSkew, DefaultReplayCacheMemory)
+	assert.Nil(t, err)
+	assert.NotNil(t, rv.(*verifier).sigVerifier)
+	assert.Equal(t, DefaultClockSkew, rv.(*verifier).clockSkew)
+	assert.NotNil(t, rv.(*verifier).replays)
+
+	rv, err = NewRequestVerifier(DefaultClockSkew, 0)
+	assert.NotNil(t, err)
+	assert.Nil(t, rv)
+}
+
 func TestRequestVerifier_Verify_ok(t *testing.T) {
 	rv := &verifier{
 		sigVerifier: &alwaysSigVerifier{},
+		replays:     newTestReplayCache(t),
 	}
 
 	rng := rand.New(rand.NewSource(0))
@@ -33,11 +68,102 @@ func TestRequestVerifier_Verify_ok(t *testing.T) {
 	meta := client.NewRequestMetadata(ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng))
 
 	assert.Nil(t, rv.Verify(ctx, nil, meta))
+
+	// without org signature
+	ctx = client.NewIncomingSignatureContext(context.Background(), signedJWT, "")
+	meta = client.NewRequestMetadata(ecid.NewPseudoRandom(rng), nil)
+	assert.Nil(t, rv.Verify(ctx, nil, meta))
+}
+
+func TestRequestVerifier_CheckReplay(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	value, key := api.NewTestDocument(rng)
+	peerSigner, orgSigner := client.NewECDSASigner(peerID.Key()),
+		client.NewECDSASigner(orgID.Key())
+	rv := newTestRequestVerifier(t)
+
+	rq := client.NewStoreRequest(peerID, orgID, key, value)
+	signedJWT, err := peerSigner.Sign(rq)
+	assert.Nil(t, err)
+	orgSignedJWT, err := orgSigner.Sign(rq)
+	assert.Nil(t, err)
+	ctx := client.NewIncomingSignatureContext(context.Background(), signedJWT, orgSignedJWT)
+	assert.Nil(t, rv.Verify(ctx, rq, rq.Metadata))
+	assert.Nil(t, rv.CheckReplay(ctx, rq.Metadata))
+
+	// replaying the same request and signature fails
+	assert.Nil(t, rv.Verify(ctx, rq, rq.Metadata))
+	assert.Equal(t, errReplayedRequest, rv.CheckReplay(ctx, rq.Metadata))
+
+	// as does re-signing the same request
+	signedJWT, err = peerSigner.Sign(rq)
+	assert.Nil(t, err)
+	orgSignedJWT, err = orgSigner.Sign(rq)
+	assert.Nil(t, err)
+	ctx = client.NewIncomingSignatureContext(context.Background(), signedJWT, orgSignedJWT)
+	assert.Equal(t, errReplayedRequest, rv.CheckReplay(ctx, rq.Metadata))
+
+	// but a new request is fine
+	rq = client.NewStoreRequest(peerID, orgID, key, value)
+	signedJWT, err = peerSigner.Sign(rq)
+	assert.Nil(t, err)
+	orgSignedJWT, err = orgSigner.Sign(rq)
+	assert.Nil(t, err)
+	ctx = client.NewIncomingSignatureContext(context.Background(), signedJWT, orgSignedJWT)
+	assert.Nil(t, rv.CheckReplay(ctx, rq.Metadata))
+
+	// request IDs are remembered until their signatures expire
+	claims, err := client.ParseClaims(signedJWT)
+	assert.Nil(t, err)
+	expires := time.Unix(claims.ExpiresAt, 0).Add(DefaultClockSkew)
+	assert.Equal(t, expires, rv.(*verifier).replays.entries[string(rq.Metadata.PubKey)+
+		string(rq.Metadata.RequestId)])
+}
+
+func TestRequestVerifier_CheckReplay_err(t *testing.T) {
+	rv := newTestRequestVerifier(t)
+	rng := rand.New(rand.NewSource(0))
+	meta := client.NewRequestMetadata(ecid.NewPseudoRandom(rng), nil)
+
+	// no signature in context
+	assert.NotNil(t, rv.CheckReplay(context.Background(), meta))
+
+	// unparseable signature
+	ctx := client.NewIncomingSignatureContext(context.Background(), "dummy.signed.token", "")
+	assert.NotNil(t, rv.CheckReplay(ctx, meta))
+}
+
+func TestRequestVerifier_Verify_bodyHash(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	rv := newTestRequestVerifier(t)
+	rq := client.NewGetRequest(peerID, orgID, id.NewPseudoRandom(rng))
+	body := []byte("some HTTP request body")
+	hash := sha256.Sum256(body)
+
+	signedJWT, err := client.SignHash(peerID.Key(), hash)
+	assert.Nil(t, err)
+	orgSignedJWT, err := client.SignHash(orgID.Key(), hash)
+	assert.Nil(t, err)
+	ctx := client.NewIncomingSignatureContext(context.Background(), signedJWT, orgSignedJWT)
+
+	// signatures over body hash don't verify as signatures over the message
+	assert.NotNil(t, rv.Verify(ctx, rq, rq.Metadata))
+
+	// but do with the body hash in the context
+	assert.Nil(t, rv.Verify(newBodyHashContext(ctx, hash), rq, rq.Metadata))
+
+	// as long as it's the hash of the same body
+	rq = client.NewGetRequest(peerID, orgID, id.NewPseudoRandom(rng))
+	otherHash := sha256.Sum256([]byte("some other HTTP request body"))
+	assert.NotNil(t, rv.Verify(newBodyHashContext(ctx, otherHash), rq, rq.Metadata))
 }
 
 func TestRequestVerifier_Verify_err(t *testing.T) {
 	rv := &verifier{
 		sigVerifier: &alwaysSigVerifier{},
+		replays:     newTestReplayCache(t),
 	}
 
 	assert.NotNil(t, rv.Verify(context.Background(), nil, nil)) // no signature in context
@@ -59,4 +185,20 @@ func TestRequestVerifier_Verify_err(t *testing.T) {
 		PubKey:    ecid.NewPseudoRandom(rng).PublicKeyBytes(),
 		RequestId: []byte{1, 2, 3}, // not 32 bytes
 	}))
+
+	// org pub key without org signature
+	ctxNoOrg := client.NewIncomingSignatureContext(context.Background(), signedJWT, "")
+	assert.Equal(t, errMissingOrgSignature, rv.Verify(ctxNoOrg, nil,
+		client.NewRequestMetadata(ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng))))
+
+	// bad signature
+	rv.sigVerifier = &neverSigVerifier{}
+	meta := client.NewRequestMetadata(ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng))
+	assert.NotNil(t, rv.Verify(ctx, nil, meta))
+}
+
+func newTestRequestVerifier(t *testing.T) RequestVerifier {
+	rv, err := NewRequestVerifier(DefaultClockSkew, DefaultReplayCacheMemory)
+	assert.Nil(t, err)
+	return rv
 }
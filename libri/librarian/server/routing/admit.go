@@ -0,0 +1,165 @@
+package routing
+
+import (
+	"errors"
+	"net"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultMinIDWork is the default min amount of work (see id.Work) a peer ID must have to be
+	// admitted to the routing table, which admits all IDs.
+	DefaultMinIDWork = uint(0)
+
+	// DefaultMaxBucketOrgPeers is the default max number of peers from the same organization a
+	// bucket may hold, where zero means no limit.
+	DefaultMaxBucketOrgPeers = uint(0)
+
+	// DefaultMaxBucketSubnetPeers is the default max number of peers from the same subnet a
+	// bucket may hold, where zero means no limit.
+	DefaultMaxBucketSubnetPeers = uint(0)
+
+	// subnet prefix lengths within which peers are likely controlled by the same party
+	ipv4SubnetBits = 24
+	ipv6SubnetBits = 48
+
+	counterNamespace  = "libri"
+	routingSubsystem  = "routing"
+	rejectedPeersName = "rejected_peers_count"
+	reasonLabel       = "reason"
+)
+
+var (
+	// ErrInsufficientIDWork indicates when a peer ID has less than the min amount of work.
+	ErrInsufficientIDWork = errors.New("peer ID has insufficient work")
+
+	// ErrBucketOrgLimit indicates when a bucket already holds the max number of peers from the
+	// peer's organization.
+	ErrBucketOrgLimit = errors.New("bucket has max peers from organization")
+
+	// ErrBucketSubnetLimit indicates when a bucket already holds the max number of peers from the
+	// peer's subnet.
+	ErrBucketSubnetLimit = errors.New("bucket has max peers from subnet")
+
+	rejectedReasons = map[error]string{
+		ErrInsufficientIDWork: "insufficient_id_work",
+		ErrBucketOrgLimit:     "bucket_org_limit",
+		ErrBucketSubnetLimit:  "bucket_subnet_limit",
+	}
+)
+
+// OrgGetter gets the organizations peers belong to.
+type OrgGetter interface {
+	// GetOrg returns the organization a peer belongs to and whether it is known.
+	GetOrg(peerID id.ID) (id.ID, bool)
+}
+
+// Admitter decides whether new peers may join the routing table, making it harder for an
+// attacker to surround a key with peers it controls.
+type Admitter interface {
+	// AdmitID returns an error if no peer with the given ID may join the table.
+	AdmitID(peerID id.ID) error
+
+	// AdmitBucket returns an error if the new peer may not join a bucket already holding the
+	// given peers.
+	AdmitBucket(new peer.Peer, bucketPeers []peer.Peer) error
+}
+
+// PromAdmitter is an Admitter that counts rejected peers in Prometheus metrics.
+type PromAdmitter interface {
+	Admitter
+
+	// Register registers the Prometheus metric(s) with the default Prometheus registerer.
+	Register()
+
+	// Unregister unregisters the Prometheus metrics form the default Prometheus registerer.
+	Unregister()
+}
+
+type admitter struct {
+	params  *Parameters
+	orgs    OrgGetter
+	rejects *prom.CounterVec
+}
+
+// NewAdmitter returns a new PromAdmitter with the rules in the given parameters. Since peers only
+// prove their organization when making requests, the organization limit only applies to peers
+// whose organization orgs knows. A nil orgs disables the organization limit.
+func NewAdmitter(params *Parameters, orgs OrgGetter) PromAdmitter {
+	return &admitter{
+		params: params,
+		orgs:   orgs,
+		rejects: prom.NewCounterVec(
+			prom.CounterOpts{
+				Namespace: counterNamespace,
+				Subsystem: routingSubsystem,
+				Name:      rejectedPeersName,
+				Help:      "Number of peers rejected from the routing table.",
+			},
+			[]string{reasonLabel},
+		),
+	}
+}
+
+func (a *admitter) AdmitID(peerID id.ID) error {
+	if id.Work(peerID) < a.params.MinIDWork {
+		return a.reject(ErrInsufficientIDWork)
+	}
+	return nil
+}
+
+func (a *admitter) AdmitBucket(new peer.Peer, bucketPeers []peer.Peer) error {
+	if err := a.AdmitID(new.ID()); err != nil {
+		return err
+	}
+	if a.params.MaxBucketOrgPeers > 0 && a.orgs != nil {
+		if orgID, in := a.orgs.GetOrg(new.ID()); in {
+			n := uint(0)
+			for _, p := range bucketPeers {
+				if pOrgID, in := a.orgs.GetOrg(p.ID()); in && pOrgID.Cmp(orgID) == 0 {
+					n++
+				}
+			}
+			if n >= a.params.MaxBucketOrgPeers {
+				return a.reject(ErrBucketOrgLimit)
+			}
+		}
+	}
+	if a.params.MaxBucketSubnetPeers > 0 && new.Address() != nil {
+		subnet := toSubnet(new.Address().IP)
+		n := uint(0)
+		for _, p := range bucketPeers {
+			if p.Address() != nil && subnet.Equal(toSubnet(p.Address().IP)) {
+				n++
+			}
+		}
+		if n >= a.params.MaxBucketSubnetPeers {
+			return a.reject(ErrBucketSubnetLimit)
+		}
+	}
+	return nil
+}
+
+func (a *admitter) Register() {
+	prom.MustRegister(a.rejects)
+}
+
+func (a *admitter) Unregister() {
+	_ = prom.Unregister(a.rejects)
+}
+
+func (a *admitter) reject(err error) error {
+	a.rejects.With(prom.Labels{reasonLabel: rejectedReasons[err]}).Inc()
+	return err
+}
+
+// toSubnet returns the /24 IPv4 or /48 IPv6 subnet of the IP.
+func toSubnet(ip net.IP) net.IP {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(ipv4SubnetBits, net.IPv4len*8))
+	}
+	return ip.Mask(net.CIDRMask(ipv6SubnetBits, net.IPv6len*8))
+}
@@ -0,0 +1,108 @@
+package routing
+
+import (
+	"math/rand"
+	"net"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	prom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdmitter_AdmitID(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := NewDefaultParameters()
+	a := NewAdmitter(params, nil)
+	for c := 0; c < 16; c++ {
+		assert.Nil(t, a.AdmitID(id.NewPseudoRandom(rng)))
+	}
+
+	params.MinIDWork = 4
+	nRejected := 0
+	for c := 0; c < 64; c++ {
+		peerID := id.NewPseudoRandom(rng)
+		err := a.AdmitID(peerID)
+		if id.Work(peerID) >= params.MinIDWork {
+			assert.Nil(t, err)
+		} else {
+			assert.Equal(t, ErrInsufficientIDWork, err)
+			nRejected++
+		}
+	}
+	assert.True(t, nRejected > 0)
+	assert.Equal(t, float64(nRejected), rejectedCount(t, a, ErrInsufficientIDWork))
+}
+
+func TestAdmitter_AdmitBucket_org(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := NewDefaultParameters()
+	params.MaxBucketOrgPeers = 2
+	org1, org2 := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	ps := peer.NewTestPeers(rng, 5)
+	orgs := &fixedOrgGetter{orgs: map[string]id.ID{
+		ps[0].ID().String(): org1,
+		ps[1].ID().String(): org1,
+		ps[2].ID().String(): org1,
+		ps[3].ID().String(): org2,
+	}}
+	a := NewAdmitter(params, orgs)
+
+	assert.Nil(t, a.AdmitBucket(ps[1], ps[:1]))
+	assert.Equal(t, ErrBucketOrgLimit, a.AdmitBucket(ps[2], ps[:2]))
+	assert.Nil(t, a.AdmitBucket(ps[3], ps[:3])) // other org
+	assert.Nil(t, a.AdmitBucket(ps[4], ps[:4])) // unknown org
+	assert.Equal(t, float64(1), rejectedCount(t, a, ErrBucketOrgLimit))
+
+	// without org getter, no limit
+	a = NewAdmitter(params, nil)
+	assert.Nil(t, a.AdmitBucket(ps[2], ps[:2]))
+}
+
+func TestAdmitter_AdmitBucket_subnet(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := NewDefaultParameters()
+	params.MaxBucketSubnetPeers = 2
+	newPeer := func(ip string) peer.Peer {
+		return peer.New(id.NewPseudoRandom(rng), "", &net.TCPAddr{IP: net.ParseIP(ip)})
+	}
+	ps := []peer.Peer{
+		newPeer("1.2.3.4"),
+		newPeer("1.2.3.5"),
+		newPeer("1.2.4.4"),
+		newPeer("2001:db8:1:1::1"),
+		newPeer("2001:db8:1:2::1"),
+	}
+	a := NewAdmitter(params, nil)
+
+	assert.Nil(t, a.AdmitBucket(newPeer("1.2.3.6"), ps[:1]))
+	assert.Equal(t, ErrBucketSubnetLimit, a.AdmitBucket(newPeer("1.2.3.6"), ps))
+	assert.Nil(t, a.AdmitBucket(newPeer("1.2.5.6"), ps))
+	assert.Equal(t, ErrBucketSubnetLimit, a.AdmitBucket(newPeer("2001:db8:1:3::1"), ps))
+	assert.Nil(t, a.AdmitBucket(newPeer("2001:db8:2::1"), ps))
+	assert.Equal(t, float64(2), rejectedCount(t, a, ErrBucketSubnetLimit))
+}
+
+func TestAdmitter_RegisterUnregister(t *testing.T) {
+	a := NewAdmitter(NewDefaultParameters(), nil)
+	a.Register()
+	a.Unregister()
+}
+
+func rejectedCount(t *testing.T, a Admitter, err error) float64 {
+	metric := &dto.Metric{}
+	counter := a.(*admitter).rejects.With(prom.Labels{reasonLabel: rejectedReasons[err]})
+	assert.Nil(t, counter.Write(metric))
+	return metric.Counter.GetValue()
+}
+
+type fixedOrgGetter struct {
+	orgs map[string]id.ID
+}
+
+func (g *fixedOrgGetter) GetOrg(peerID id.ID) (id.ID, bool) {
+	orgID, in := g.orgs[peerID.String()]
+	return orgID, in
+}
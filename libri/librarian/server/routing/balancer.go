@@ -14,13 +14,36 @@ var (
 	tableSampleRetryWait = 15 * time.Second
 	numRetries           = 32
 	sampleBatchSize      = uint(8)
+	maxRecentlyRemoved   = 8
 )
 
 // NewClientBalancer returns a new client.Balancer that uses the routing tables's Sample()
 // method and returns a unique client on every Next() call.
 func NewClientBalancer(rt Table, clients client.Pool) client.SetBalancer {
+	return newTableSetBalancer(rt.Sample, clients)
+}
+
+// NewSpreadClientBalancer returns a new client.SetBalancer that uses the routing table's Spread()
+// method, so successive clients are spread across the table's buckets.
+func NewSpreadClientBalancer(rt Table, clients client.Pool) client.SetBalancer {
+	return newTableSetBalancer(rt.Spread, clients)
+}
+
+// NewHealthiestClientBalancer returns a new client.SetBalancer that uses the routing table's
+// Healthiest() method, so the most preferred healthy peers not already in the set are returned
+// first.
+func NewHealthiestClientBalancer(rt Table, clients client.Pool) client.SetBalancer {
+	healthiest := func(k uint, _ *rand.Rand) []peer.Peer {
+		return rt.Healthiest(k)
+	}
+	return newTableSetBalancer(healthiest, clients)
+}
+
+func newTableSetBalancer(
+	sample func(k uint, rng *rand.Rand) []peer.Peer, clients client.Pool,
+) *tableSetBalancer {
 	return &tableSetBalancer{
-		rt:      rt,
+		sample:  sample,
 		rng:     rand.New(rand.NewSource(0)),
 		set:     make(map[string]struct{}),
 		cache:   make([]peer.Peer, 0),
@@ -29,36 +52,42 @@ func NewClientBalancer(rt Table, clients client.Pool) client.SetBalancer {
 }
 
 type tableSetBalancer struct {
-	rt      Table
+	sample  func(k uint, rng *rand.Rand) []peer.Peer
 	rng     *rand.Rand
 	set     map[string]struct{}
 	cache   []peer.Peer
 	clients client.Pool
 	mu      sync.Mutex
+
+	// removed are the addresses most recently removed from the set, which AddNext() passes over
+	// when other peers are available so that rotating a subscription actually moves it to another
+	// peer even with deterministic samplers like Healthiest()
+	removed []string
 }
 
 func (b *tableSetBalancer) AddNext() (api.LibrarianClient, string, error) {
 	for c := 0; c < numRetries; c++ {
 		b.mu.Lock()
-		if len(b.cache) == 0 {
-			b.cache = b.rt.Sample(sampleBatchSize, b.rng)
-			if len(b.cache) == 0 {
-				b.mu.Unlock()
-				// wait for routing table to possibly fill up a bit
-				time.Sleep(tableSampleRetryWait)
-				continue
-			}
+		i, fallback := b.nextIndex()
+		if i < 0 || fallback {
+			// sample enough peers to get past those already in the set and those recently
+			// removed for deterministic samplers like Healthiest()
+			nSample := sampleBatchSize + uint(len(b.set)+len(b.removed))
+			b.cache = b.sample(nSample, b.rng)
+			i, _ = b.nextIndex()
 		}
-		nextPeer := b.cache[0]
-		b.cache = b.cache[1:]
-		nextAddress := nextPeer.Address().String()
-		if _, in := b.set[nextAddress]; !in {
+		if i >= 0 {
+			nextAddress := peer.DialAddress(b.cache[i])
+			b.cache = b.cache[i+1:]
+			b.unremove(nextAddress)
+
 			// update current state & return connection to new peer
 			b.set[nextAddress] = struct{}{}
 			b.mu.Unlock()
 			lc, err := b.clients.Get(nextAddress)
 			return lc, nextAddress, err
 		}
+		b.cache = b.cache[:0]
 		b.mu.Unlock()
 
 		// wait for routing table to possibly fill up a bit
@@ -67,6 +96,44 @@ func (b *tableSetBalancer) AddNext() (api.LibrarianClient, string, error) {
 	return nil, "", client.ErrNoNewClients
 }
 
+// nextIndex returns the index of the first cached peer not in the set and not recently removed,
+// falling back to the first recently removed one (indicated by the second return value), or -1
+// if all cached peers are in the set.
+func (b *tableSetBalancer) nextIndex() (int, bool) {
+	fallback := -1
+	for i, p := range b.cache {
+		address := peer.DialAddress(p)
+		if _, in := b.set[address]; in {
+			continue
+		}
+		if !b.recentlyRemoved(address) {
+			return i, false
+		}
+		if fallback == -1 {
+			fallback = i
+		}
+	}
+	return fallback, fallback >= 0
+}
+
+func (b *tableSetBalancer) recentlyRemoved(address string) bool {
+	for _, removed := range b.removed {
+		if removed == address {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *tableSetBalancer) unremove(address string) {
+	for i, removed := range b.removed {
+		if removed == address {
+			b.removed = append(b.removed[:i], b.removed[i+1:]...)
+			return
+		}
+	}
+}
+
 func (b *tableSetBalancer) Remove(address string) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -74,5 +141,10 @@ func (b *tableSetBalancer) Remove(address string) error {
 		return client.ErrClientMissingFromSet
 	}
 	delete(b.set, address)
+	b.unremove(address)
+	b.removed = append(b.removed, address)
+	if len(b.removed) > maxRecentlyRemoved {
+		b.removed = b.removed[1:]
+	}
 	return nil
 }
@@ -113,6 +113,81 @@ func TestTableSetBalancer_Remove(t *testing.T) {
 	assert.Equal(t, client.ErrClientMissingFromSet, err)
 }
 
+func TestSpreadClientBalancer_AddNext(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, nAdded, _ := NewTestWithPeers(rng, 64)
+	clients := &fixedPool{lc: api.NewLibrarianClient(nil), getAddresses: make(map[string]struct{})}
+	csb := NewSpreadClientBalancer(rt, clients)
+
+	// check each AddNext() returns a new peer until we've gotten all of them
+	addresses := make(map[string]struct{})
+	for c := 0; c < nAdded; c++ {
+		lc, address, err := csb.AddNext()
+		assert.Nil(t, err)
+		assert.NotNil(t, lc)
+		addresses[address] = struct{}{}
+	}
+	assert.Equal(t, nAdded, len(addresses))
+}
+
+func TestHealthiestClientBalancer_AddNext(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	ps := peer.NewTestPeers(rng, 64)
+	rt, _ := NewWithPeers(id.NewPseudoRandom(rng), &idPreferer{}, &fixedDoctor{healthy: true},
+		NewDefaultParameters(), ps)
+	clients := &fixedPool{lc: api.NewLibrarianClient(nil), getAddresses: make(map[string]struct{})}
+	csb := NewHealthiestClientBalancer(rt, clients)
+
+	// check AddNext() returns healthiest peers in order, skipping those already in the set
+	nAdd := uint(2 * sampleBatchSize)
+	healthiest := rt.Healthiest(nAdd)
+	for _, p := range healthiest {
+		lc, address, err := csb.AddNext()
+		assert.Nil(t, err)
+		assert.NotNil(t, lc)
+		assert.Equal(t, p.Address().String(), address)
+	}
+
+	// check rotating a peer out of the set moves to the next healthiest one instead of
+	// returning the same peer
+	err := csb.Remove(healthiest[0].Address().String())
+	assert.Nil(t, err)
+	_, address, err := csb.AddNext()
+	assert.Nil(t, err)
+	assert.Equal(t, rt.Healthiest(nAdd + 1)[nAdd].Address().String(), address)
+
+	// check recently removed peer is returned again once it's the only one available
+	rt2, _ := NewWithPeers(id.NewPseudoRandom(rng), &idPreferer{}, &fixedDoctor{healthy: true},
+		NewDefaultParameters(), ps[:1])
+	csb = NewHealthiestClientBalancer(rt2, clients)
+	_, address, err = csb.AddNext()
+	assert.Nil(t, err)
+	assert.Nil(t, csb.Remove(address))
+	_, address2, err := csb.AddNext()
+	assert.Nil(t, err)
+	assert.Equal(t, address, address2)
+	assert.Empty(t, csb.(*tableSetBalancer).removed)
+}
+
+func TestTableSetBalancer_Remove_rotation(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, nAdded, _ := NewTestWithPeers(rng, 16)
+	clients := &fixedPool{lc: api.NewLibrarianClient(nil), getAddresses: make(map[string]struct{})}
+	csb := NewClientBalancer(rt, clients)
+
+	// check each rotation moves to a peer other than the one just removed
+	_, address, err := csb.AddNext()
+	assert.Nil(t, err)
+	for c := 0; c < 2*nAdded; c++ {
+		assert.Nil(t, csb.Remove(address))
+		_, next, err := csb.AddNext()
+		assert.Nil(t, err)
+		assert.NotEqual(t, address, next)
+		address = next
+	}
+	assert.True(t, len(csb.(*tableSetBalancer).removed) <= maxRecentlyRemoved)
+}
+
 type fixedPool struct {
 	lc           api.LibrarianClient
 	getErr       error
@@ -142,6 +217,13 @@ func (f *fixedPreferer) Prefer(peerID1, peerID2 id.ID) bool {
 	return f.prefer
 }
 
+// idPreferer prefers peers with smaller IDs.
+type idPreferer struct{}
+
+func (f *idPreferer) Prefer(peerID1, peerID2 id.ID) bool {
+	return peerID1.Cmp(peerID2) < 0
+}
+
 type fixedDoctor struct {
 	healthy bool
 }
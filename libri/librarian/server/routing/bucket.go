@@ -2,6 +2,8 @@ package routing
 
 import (
 	"math/big"
+	"math/rand"
+	"time"
 
 	"container/heap"
 
@@ -44,6 +46,9 @@ type bucket struct {
 
 	// determines whether a peer is healthy
 	doctor comm.Doctor
+
+	// time a peer was last pushed into the bucket
+	touched time.Time
 }
 
 // newFirstBucket creates a new instance of the first bucket (spanning the entire ID range)
@@ -133,6 +138,13 @@ func (b *bucket) Contains(target id.ID) bool {
 	return target.Cmp(b.lowerBound) >= 0 && target.Cmp(b.upperBound) < 0
 }
 
+// Random returns a random ID in the bucket's ID range.
+func (b *bucket) Random(rng *rand.Rand) id.ID {
+	width := new(big.Int).Sub(b.upperBound.Int(), b.lowerBound.Int())
+	offset := new(big.Int).Rand(rng, width)
+	return id.FromInt(offset.Add(offset, b.lowerBound.Int()))
+}
+
 func (b *bucket) unhealthyRoot() bool {
 	if len(b.activePeers) == 0 {
 		// empty bucket cannot have unhealthy root
@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/api"
 	"github.com/drausin/libri/libri/librarian/server/comm"
 	"github.com/drausin/libri/libri/librarian/server/peer"
@@ -52,3 +53,16 @@ func TestBucket_Peak(t *testing.T) {
 	assert.Equal(t, 4, len(b.Peak(4)))
 	assert.Equal(t, 4, len(b.Peak(8)))
 }
+
+func TestBucket_Random(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rec := comm.NewQueryRecorderGetter(comm.NewAlwaysKnower())
+	preferer, doctor := comm.NewRpPreferer(rec), comm.NewNaiveDoctor()
+	b := newFirstBucket(DefaultMaxActivePeers, preferer, doctor)
+	b.lowerBound = id.FromInt64(1000)
+	b.upperBound = id.FromInt64(1010)
+
+	for c := 0; c < 64; c++ {
+		assert.True(t, b.Contains(b.Random(rng)))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/drausin/libri/libri/common/ecid"
 	errors2 "github.com/drausin/libri/libri/common/errors"
@@ -74,6 +75,23 @@ type Table interface {
 	// space the bucket covers.
 	Sample(k uint, rng *rand.Rand) []peer.Peer
 
+	// Spread returns k healthy peers spread as evenly as possible across the table's buckets,
+	// which visits buckets in random order and takes a random peer from each in turn.
+	Spread(k uint, rng *rand.Rand) []peer.Peer
+
+	// Healthiest returns the k healthy peers in the table most preferred by its Preferer.
+	Healthiest(k uint) []peer.Peer
+
+	// Stale returns a random ID in the range of each bucket no peer has been pushed into since
+	// the given time. Searching for these IDs refreshes the buckets with new peers.
+	Stale(since time.Time, rng *rand.Rand) []id.ID
+
+	// Peers returns all the peers in the table.
+	Peers() []peer.Peer
+
+	// Evict removes the peer with the given ID from the table, returning whether it existed.
+	Evict(peerID id.ID) bool
+
 	// NumPeers returns the number of total peers in the routing table.
 	NumPeers() int
 
@@ -88,12 +106,26 @@ type Table interface {
 type Parameters struct {
 	// MaxBucketPeers is the maximum number of peers in a bucket.
 	MaxBucketPeers uint
+
+	// MinIDWork is the min amount of work (see id.Work) a peer ID must have to join the table.
+	MinIDWork uint
+
+	// MaxBucketOrgPeers is the max number of peers from the same organization in a bucket, where
+	// zero means no limit.
+	MaxBucketOrgPeers uint
+
+	// MaxBucketSubnetPeers is the max number of peers from the same /24 IPv4 (or /48 IPv6)
+	// subnet in a bucket, where zero means no limit.
+	MaxBucketSubnetPeers uint
 }
 
 // NewDefaultParameters creates a new set of default parameters.
 func NewDefaultParameters() *Parameters {
 	return &Parameters{
-		MaxBucketPeers: DefaultMaxActivePeers,
+		MaxBucketPeers:       DefaultMaxActivePeers,
+		MinIDWork:            DefaultMinIDWork,
+		MaxBucketOrgPeers:    DefaultMaxBucketOrgPeers,
+		MaxBucketSubnetPeers: DefaultMaxBucketSubnetPeers,
 	}
 }
 
@@ -110,18 +142,35 @@ type table struct {
 	// defines some aspects of behavior
 	params *Parameters
 
+	// decides whether new peers may join
+	admitter Admitter
+
 	// manages pushes and pops
 	mu sync.Mutex
 }
 
-// NewEmpty creates a new routing table without peers.
+// NewEmpty creates a new routing table without peers, admitting peers per the rules in the
+// parameters that don't depend on their organizations.
 func NewEmpty(selfID id.ID, preferer comm.Preferer, doctor comm.Doctor, params *Parameters) Table {
+	return NewEmptyWithAdmitter(selfID, preferer, doctor, params, NewAdmitter(params, nil))
+}
+
+// NewEmptyWithAdmitter creates a new routing table without peers that uses the given Admitter to
+// decide whether new peers may join.
+func NewEmptyWithAdmitter(
+	selfID id.ID,
+	preferer comm.Preferer,
+	doctor comm.Doctor,
+	params *Parameters,
+	admitter Admitter,
+) Table {
 	firstBucket := newFirstBucket(params.MaxBucketPeers, preferer, doctor)
 	return &table{
-		selfID:  selfID,
-		peers:   make(map[string]peer.Peer),
-		buckets: []*bucket{firstBucket},
-		params:  params,
+		selfID:   selfID,
+		peers:    make(map[string]peer.Peer),
+		buckets:  []*bucket{firstBucket},
+		params:   params,
+		admitter: admitter,
 	}
 }
 
@@ -184,6 +233,7 @@ func (rt *table) Push(new peer.Peer) PushStatus {
 	insertBucket := rt.buckets[bucketIdx]
 
 	// take opportunity to remove an unhealthy root if necessary
+	insertBucket.touched = time.Now()
 	if insertBucket.unhealthyRoot() {
 		popped := heap.Pop(insertBucket).(peer.Peer)
 		delete(rt.peers, popped.ID().String())
@@ -207,6 +257,12 @@ func (rt *table) Push(new peer.Peer) PushStatus {
 		return Dropped
 	}
 
+	if err := rt.admitter.AdmitBucket(new, insertBucket.activePeers); err != nil {
+		// don't add if admission rules reject it
+		rt.mu.Unlock()
+		return Dropped
+	}
+
 	if !insertBucket.Vacancy() && insertBucket.containsSelf {
 		// no vacancy in the bucket and it contains the self ID, so split the bucket and
 		// insert via (single) recursive call
@@ -299,6 +355,94 @@ func (rt *table) Sample(k uint, rng *rand.Rand) []peer.Peer {
 	return sample
 }
 
+func (rt *table) Spread(k uint, rng *rand.Rand) []peer.Peer {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	// get (shuffled) healthy peers in each non-empty bucket
+	bucketPeers := make([][]peer.Peer, 0, len(rt.buckets))
+	for _, b := range rt.buckets {
+		ps := b.Peak(uint(b.Len()))
+		if len(ps) == 0 {
+			continue
+		}
+		rng.Shuffle(len(ps), func(i, j int) { ps[i], ps[j] = ps[j], ps[i] })
+		bucketPeers = append(bucketPeers, ps)
+	}
+
+	// take one peer from each bucket per pass until we have k or run out of peers
+	order := rng.Perm(len(bucketPeers))
+	sample := make([]peer.Peer, 0, k)
+	for added := true; added && len(sample) < int(k); {
+		added = false
+		for _, i := range order {
+			if len(sample) == int(k) {
+				break
+			}
+			if len(bucketPeers[i]) > 0 {
+				sample = append(sample, bucketPeers[i][0])
+				bucketPeers[i] = bucketPeers[i][1:]
+				added = true
+			}
+		}
+	}
+	return sample
+}
+
+func (rt *table) Healthiest(k uint) []peer.Peer {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	healthy := make([]peer.Peer, 0, len(rt.peers))
+	for _, b := range rt.buckets {
+		healthy = append(healthy, b.Peak(uint(b.Len()))...)
+	}
+
+	// all buckets share the same preferer
+	preferer := rt.buckets[0].preferer
+	sort.SliceStable(healthy, func(i, j int) bool {
+		return preferer.Prefer(healthy[i].ID(), healthy[j].ID())
+	})
+	if len(healthy) > int(k) {
+		healthy = healthy[:k]
+	}
+	return healthy
+}
+
+func (rt *table) Stale(since time.Time, rng *rand.Rand) []id.ID {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	targets := make([]id.ID, 0)
+	for _, b := range rt.buckets {
+		if b.touched.Before(since) {
+			targets = append(targets, b.Random(rng))
+		}
+	}
+	return targets
+}
+
+func (rt *table) Peers() []peer.Peer {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	ps := make([]peer.Peer, 0, len(rt.peers))
+	for _, b := range rt.buckets {
+		ps = append(ps, b.activePeers...)
+	}
+	return ps
+}
+
+func (rt *table) Evict(peerID id.ID) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if _, in := rt.peers[peerID.String()]; !in {
+		return false
+	}
+	b := rt.buckets[rt.bucketIndex(peerID)]
+	heap.Remove(b, b.positions[peerID.String()])
+	delete(rt.peers, peerID.String())
+	return true
+}
+
 // Len returns the current number of buckets in the routing table.
 func (rt *table) Len() int {
 	return len(rt.buckets)
@@ -390,6 +534,7 @@ func (rt *table) splitBucket(bucketIdx int) {
 		positions:      make(map[string]int),
 		preferer:       current.preferer,
 		doctor:         current.doctor,
+		touched:        current.touched,
 	}
 	left.containsSelf = left.Contains(rt.selfID)
 
@@ -404,6 +549,7 @@ func (rt *table) splitBucket(bucketIdx int) {
 		positions:      make(map[string]int),
 		preferer:       current.preferer,
 		doctor:         current.doctor,
+		touched:        current.touched,
 	}
 	right.containsSelf = right.Contains(rt.selfID)
 
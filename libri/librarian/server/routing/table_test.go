@@ -7,6 +7,7 @@ import (
 	"sort"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/librarian/server/peer"
@@ -136,6 +137,25 @@ func TestTable_Push_existing(t *testing.T) {
 	}
 }
 
+func TestTable_Push_admitter(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := NewDefaultParameters()
+	params.MaxBucketSubnetPeers = 4
+	p, d := &fixedPreferer{}, &fixedDoctor{healthy: true}
+	rt := NewEmptyWithAdmitter(id.NewPseudoRandom(rng), p, d, params,
+		NewAdmitter(params, nil))
+
+	// all test peers are on the same subnet
+	nAdded := 0
+	for _, p := range peer.NewTestPeers(rng, 8) {
+		if rt.Push(p) == Added {
+			nAdded++
+		}
+	}
+	assert.Equal(t, 4, nAdded)
+	assert.Equal(t, 4, rt.NumPeers())
+}
+
 func TestTable_Find(t *testing.T) {
 
 	// make sure we support popping 0 peers
@@ -219,6 +239,137 @@ func TestTable_Sample(t *testing.T) {
 	}
 }
 
+func TestTable_Spread(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for n := 2; n <= 256; n *= 2 {
+		// full buckets may drop some of the n peers
+		rt, _, nAdded, _ := NewTestWithPeers(rng, n)
+		for k := uint(2); k <= 32; k *= 2 {
+			info := fmt.Sprintf("n: %v, k: %v", n, k)
+			sample := rt.Spread(k, rng)
+			if k <= uint(nAdded) {
+				assert.Equal(t, int(k), len(sample), info)
+			} else {
+				assert.Equal(t, nAdded, len(sample), info)
+			}
+
+			// check sampled peers are unique & spread across buckets, i.e., no bucket has
+			// more than one peer more than any other bucket in the sample
+			bucketCounts := make(map[int]int)
+			sampled := make(map[string]struct{})
+			for _, p := range sample {
+				sampled[p.ID().String()] = struct{}{}
+				bucketCounts[rt.(*table).bucketIndex(p.ID())]++
+			}
+			assert.Equal(t, len(sample), len(sampled), info)
+			minCount, maxCount := len(sample), 0
+			for i, b := range rt.(*table).buckets {
+				if count := bucketCounts[i]; b.Len() > count && count < minCount {
+					minCount = count
+				}
+				if count := bucketCounts[i]; count > maxCount {
+					maxCount = count
+				}
+			}
+			assert.True(t, maxCount <= minCount+1, info)
+		}
+	}
+}
+
+func TestTable_Healthiest(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	n := 64
+	ps := peer.NewTestPeers(rng, n)
+	rt, nAdded := NewWithPeers(id.NewPseudoRandom(rng), &idPreferer{}, &fixedDoctor{healthy: true},
+		NewDefaultParameters(), ps)
+	assert.True(t, nAdded > 0)
+
+	// get all peers in table sorted by ID, which is how the idPreferer prefers them
+	all := make([]peer.Peer, 0, rt.NumPeers())
+	for _, p := range ps {
+		if _, in := rt.Get(p.ID()); in {
+			all = append(all, p)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID().Cmp(all[j].ID()) < 0 })
+
+	for k := uint(1); k <= 128; k *= 2 {
+		healthiest := rt.Healthiest(k)
+		if int(k) <= len(all) {
+			assert.Equal(t, all[:k], healthiest)
+		} else {
+			assert.Equal(t, all, healthiest)
+		}
+	}
+
+	// check no peers returned when none are healthy
+	rt2, _ := NewWithPeers(id.NewPseudoRandom(rng), &idPreferer{}, &fixedDoctor{healthy: false},
+		NewDefaultParameters(), ps)
+	assert.Empty(t, rt2.Healthiest(8))
+}
+
+func TestTable_Stale(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, nAdded, _ := NewTestWithPeers(rng, 128)
+	assert.True(t, nAdded > 0)
+	rt1 := rt.(*table)
+	assert.True(t, rt1.NumBuckets() > 1)
+
+	// no buckets stale when all recently touched
+	assert.Empty(t, rt.Stale(time.Now().Add(-time.Minute), rng))
+
+	// all buckets stale when touched before given time
+	stale := rt.Stale(time.Now().Add(time.Minute), rng)
+	assert.Equal(t, rt.NumBuckets(), len(stale))
+	for i, target := range stale {
+		assert.True(t, rt1.buckets[i].Contains(target))
+	}
+
+	// only buckets touched long ago are stale
+	rt1.buckets[0].touched = time.Now().Add(-time.Hour)
+	stale = rt.Stale(time.Now().Add(-time.Minute), rng)
+	assert.Equal(t, 1, len(stale))
+	assert.True(t, rt1.buckets[0].Contains(stale[0]))
+
+	// pushing a peer into the bucket touches it again
+	p := peer.NewTestPeer(rng, 0)
+	p = peer.New(rt1.buckets[0].Random(rng), "", p.Address())
+	rt.Push(p)
+	assert.Empty(t, rt.Stale(time.Now().Add(-time.Minute), rng))
+}
+
+func TestTable_Peers(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, nAdded, _ := NewTestWithPeers(rng, 64)
+	ps := rt.Peers()
+	assert.Equal(t, nAdded, len(ps))
+	for _, p := range ps {
+		q, in := rt.Get(p.ID())
+		assert.True(t, in)
+		assert.Equal(t, p, q)
+	}
+}
+
+func TestTable_Evict(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, _, nAdded, _ := NewTestWithPeers(rng, 64)
+	ps := rt.Peers()
+
+	for i, p := range ps {
+		assert.True(t, rt.Evict(p.ID()))
+		_, in := rt.Get(p.ID())
+		assert.False(t, in)
+		assert.Equal(t, nAdded-i-1, rt.NumPeers())
+
+		// can't evict what isn't there
+		assert.False(t, rt.Evict(p.ID()))
+	}
+	for _, b := range rt.(*table).buckets {
+		assert.Zero(t, b.Len())
+		assert.Empty(t, b.positions)
+	}
+}
+
 func TestTable_Less(t *testing.T) {
 	rt := newSimpleTable()
 	for i := 1; i < len(rt.buckets); i++ {
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"sync"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+)
+
+// PathClaims records which of a lookup's disjoint paths each peer is queried on, so that no peer
+// is queried on more than one path.
+type PathClaims struct {
+	paths map[string]int
+	mu    sync.Mutex
+}
+
+// NewPathClaims returns a new PathClaims with no claimed peers.
+func NewPathClaims() *PathClaims {
+	return &PathClaims{paths: make(map[string]int)}
+}
+
+// Claim claims the peer for the given path if no path has already claimed it, returning whether
+// it did. A claimed peer should be queried only by the path that claimed it and only once.
+func (c *PathClaims) Claim(peerID id.ID, path int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	idStr := peerID.String()
+	if _, in := c.paths[idStr]; in {
+		return false
+	}
+	c.paths[idStr] = path
+	return true
+}
+
+// SplitSeeds splits the seeds into at most nPaths non-empty groups, dealing them out in order of
+// their distance to the key so each path starts from similarly close peers.
+func SplitSeeds(key id.ID, seeds []peer.Peer, nPaths uint) [][]peer.Peer {
+	if uint(len(seeds)) < nPaths {
+		nPaths = uint(len(seeds))
+	}
+	ordered := NewClosestPeers(key, uint(len(seeds)))
+	ordered.SafePushMany(seeds)
+	split := make([][]peer.Peer, nPaths)
+	for i, p := range ordered.Peers() {
+		path := uint(i) % nPaths
+		split[path] = append(split[path], p)
+	}
+	return split
+}
@@ -0,0 +1,59 @@
+package search
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathClaims_Claim(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID1, peerID2 := id.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	c := NewPathClaims()
+
+	assert.True(t, c.Claim(peerID1, 0))
+	assert.False(t, c.Claim(peerID1, 0)) // only claimed once
+	assert.False(t, c.Claim(peerID1, 1))
+	assert.True(t, c.Claim(peerID2, 1))
+	assert.False(t, c.Claim(peerID2, 0))
+}
+
+func TestSplitSeeds(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	key := id.NewPseudoRandom(rng)
+	seeds := peer.NewTestPeers(rng, 7)
+
+	split := SplitSeeds(key, seeds, 3)
+	assert.Len(t, split, 3)
+	assert.Len(t, split[0], 3)
+	assert.Len(t, split[1], 2)
+	assert.Len(t, split[2], 2)
+
+	// closest seeds dealt out first, and each seed in exactly one path
+	ordered := NewClosestPeers(key, uint(len(seeds)))
+	ordered.SafePushMany(seeds)
+	closest := ordered.Peers()
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, closest[i], split[i][0])
+	}
+	inPath := make(map[string]int)
+	for i, pathSeeds := range split {
+		for _, p := range pathSeeds {
+			_, in := inPath[p.ID().String()]
+			assert.False(t, in)
+			inPath[p.ID().String()] = i
+		}
+	}
+	assert.Len(t, inPath, len(seeds))
+
+	// fewer seeds than paths
+	split = SplitSeeds(key, seeds[:2], 3)
+	assert.Len(t, split, 2)
+
+	// no seeds
+	split = SplitSeeds(key, nil, 3)
+	assert.Len(t, split, 0)
+}
@@ -24,6 +24,9 @@ const (
 	// DefaultConcurrency is the default number of parallel search workers.
 	DefaultConcurrency = uint(1)
 
+	// DefaultNDisjointPaths is the default number of disjoint paths a search follows.
+	DefaultNDisjointPaths = uint(1)
+
 	// DefaultQueryTimeout is the timeout for each query to a peer.
 	DefaultQueryTimeout = 3 * time.Second
 
@@ -32,6 +35,7 @@ const (
 	logNClosestResponses = "n_closest_responses"
 	logNMaxErrors        = "n_max_errors"
 	logConcurrency       = "concurrency"
+	logNDisjointPaths    = "n_disjoint_paths"
 	logTimeout           = "timeout"
 	logNClosest          = "n_closest"
 	logNUnqueried        = "n_unqueried"
@@ -59,6 +63,12 @@ type Parameters struct {
 	// Concurrency is the number of concurrent queries to use in search
 	Concurrency uint
 
+	// NDisjointPaths is the number of independent paths the search follows, each with
+	// Concurrency queries and NMaxErrors tolerated errors, without querying any peer on more than
+	// one path. Multiple paths make the search resilient to a few peers returning bad closest
+	// peers.
+	NDisjointPaths uint
+
 	// Timeout for queries to individual peers
 	Timeout time.Duration
 }
@@ -69,6 +79,7 @@ func NewDefaultParameters() *Parameters {
 		NClosestResponses: DefaultNClosestResponses,
 		NMaxErrors:        DefaultNMaxErrors,
 		Concurrency:       DefaultConcurrency,
+		NDisjointPaths:    DefaultNDisjointPaths,
 		Timeout:           DefaultQueryTimeout,
 	}
 }
@@ -78,6 +89,7 @@ func (p *Parameters) MarshalLogObject(oe zapcore.ObjectEncoder) error {
 	oe.AddUint(logNClosestResponses, p.NClosestResponses)
 	oe.AddUint(logNMaxErrors, p.NMaxErrors)
 	oe.AddUint(logConcurrency, p.Concurrency)
+	oe.AddUint(logNDisjointPaths, p.NDisjointPaths)
 	oe.AddDuration(logTimeout, p.Timeout)
 	return nil
 }
@@ -145,8 +157,22 @@ type Search struct {
 	// parameters defining the search
 	Params *Parameters
 
+	// PeersOnly indicates whether the search ignores any values found and only looks for the
+	// peers closest to the key, e.g., when storing a pointer that may overwrite stored ones
+	PeersOnly bool
+
 	// mutex used to synchronizes reads and writes to this instance
 	Mu sync.Mutex
+
+	// claims records the path each peer was queried on when this search is one of the paths of a
+	// disjoint-path search, otherwise nil
+	claims *PathClaims
+
+	// path is the index of this search among the paths of a disjoint-path search
+	path int
+
+	// nPaths is the number of disjoint paths whose results have been merged into this search
+	nPaths int
 }
 
 // NewSearch creates a new Search instance for a given target, search type, and search parameters.
@@ -199,10 +225,14 @@ func (s *Search) FoundValue() bool {
 	return s.Result.Value != nil
 }
 
-// Errored returns whether the search has encountered too many errors when querying the peers.
+// Errored returns whether the search has encountered too many errors when querying the peers. A
+// search merged from disjoint paths has only errored if all of its paths did.
 func (s *Search) Errored() bool {
 	s.Mu.Lock()
 	defer s.Mu.Unlock()
+	if s.nPaths > 1 {
+		return s.Result.FatalErr != nil
+	}
 	return uint(len(s.Result.Errored)) > s.Params.NMaxErrors || s.Result.FatalErr != nil
 }
 
@@ -217,7 +247,34 @@ func (s *Search) Exhausted() bool {
 // closest peers or errored or exhausted the list of peers to query. This operation is concurrency
 // safe.
 func (s *Search) Finished() bool {
-	return s.FoundValue() || s.FoundClosestPeers() || s.Errored()
+	return s.foundImmutableValue() || s.FoundClosestPeers() || s.Errored()
+}
+
+// foundImmutableValue returns whether the search has found a value other than a pointer. Since
+// peers that missed an update may hold older versions of a mutable pointer, a search that finds
+// one continues until it has found the closest peers, keeping the newest version among them.
+func (s *Search) foundImmutableValue() bool {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+	if s.Result.Value == nil {
+		return false
+	}
+	_, isPointer := s.Result.Value.Contents.(*api.Document_Pointer)
+	return !isPointer
+}
+
+// newestValue returns the found value if there is no current one or if both are pointers and the
+// found one has a higher sequence, otherwise the current value.
+func newestValue(current, found *api.Document) *api.Document {
+	if current == nil {
+		return found
+	}
+	currentPointer, foundPointer := current.GetPointer(), found.GetPointer()
+	if currentPointer != nil && foundPointer != nil &&
+		foundPointer.Sequence > currentPointer.Sequence {
+		return found
+	}
+	return current
 }
 
 // AddQueried adds a peer to the queried set.
@@ -227,6 +284,60 @@ func (s *Search) AddQueried(p peer.Peer) {
 	s.Result.Queried[p.ID().String()] = struct{}{}
 }
 
+// newPath creates a new search for one of the disjoint paths of this search.
+func (s *Search) newPath(path int, claims *PathClaims) *Search {
+	return &Search{
+		Key:       s.Key,
+		CreatRq:   s.CreatRq,
+		Result:    NewInitialResult(s.Key, s.Params),
+		Params:    s.Params,
+		PeersOnly: s.PeersOnly,
+		claims:    claims,
+		path:      path,
+	}
+}
+
+// mergePaths merges the results of the disjoint paths into this search's result. The search only
+// has a fatal error if all paths do.
+func (s *Search) mergePaths(paths []*Search) {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+	s.nPaths = len(paths)
+	nFatal := 0
+	for _, path := range paths {
+		path.Mu.Lock()
+		r := path.Result
+		if r.Value != nil {
+			s.Result.Value = newestValue(s.Result.Value, r.Value)
+		}
+		for idStr := range r.Queried {
+			s.Result.Queried[idStr] = struct{}{}
+		}
+		for idStr, p := range r.Responded {
+			s.Result.Responded[idStr] = p
+			s.Result.Closest.SafePush(p)
+		}
+		for idStr, err := range r.Errored {
+			s.Result.Errored[idStr] = err
+		}
+		if r.FatalErr != nil {
+			s.Result.FatalErr = r.FatalErr
+			nFatal++
+		}
+		path.Mu.Unlock()
+	}
+	if nFatal < len(paths) {
+		s.Result.FatalErr = nil
+	}
+	for _, path := range paths {
+		for _, p := range path.Result.Unqueried.Peers() {
+			if _, queried := s.Result.Queried[p.ID().String()]; !queried {
+				s.Result.Unqueried.SafePush(p)
+			}
+		}
+	}
+}
+
 func (s *Search) wrapLock(operation func()) {
 	s.Mu.Lock()
 	defer s.Mu.Unlock()
@@ -102,6 +102,22 @@ func TestSearch_FoundValue(t *testing.T) {
 	assert.True(t, search.FoundValue())
 }
 
+func TestNewestValue(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	pointer1, _ := api.NewTestPointer(rng)
+	pointer2 := *pointer1
+	pointer2.Sequence = 2
+	value1 := &api.Document{Contents: &api.Document_Pointer{Pointer: pointer1}}
+	value2 := &api.Document{Contents: &api.Document_Pointer{Pointer: &pointer2}}
+	doc, _ := api.NewTestDocument(rng)
+
+	assert.Equal(t, value1, newestValue(nil, value1))
+	assert.Equal(t, value2, newestValue(value1, value2))
+	assert.Equal(t, value2, newestValue(value2, value1))
+	assert.Equal(t, doc, newestValue(doc, value1))
+	assert.Equal(t, value1, newestValue(value1, doc))
+}
+
 func TestSearch_Errored(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	target, peerID := id.FromInt64(0), ecid.NewPseudoRandom(rng)
@@ -77,6 +77,30 @@ func NewDefaultSearcher(
 }
 
 func (s *searcher) Search(search *Search, seeds []peer.Peer) error {
+	if search.Params.NDisjointPaths <= 1 {
+		return s.searchPath(search, seeds)
+	}
+
+	// search each disjoint path concurrently and then merge their results
+	pathSeeds := SplitSeeds(search.Key, seeds, search.Params.NDisjointPaths)
+	claims := NewPathClaims()
+	paths := make([]*Search, len(pathSeeds))
+	var wg sync.WaitGroup
+	for i := range pathSeeds {
+		paths[i] = search.newPath(i, claims)
+		wg.Add(1)
+		go func(path *Search, seeds []peer.Peer) {
+			defer wg.Done()
+			_ = s.searchPath(path, seeds) // fatal errors merged below
+		}(paths[i], pathSeeds[i])
+	}
+	wg.Wait()
+	search.mergePaths(paths)
+	return search.Result.FatalErr
+}
+
+// searchPath searches along a single path from the seeds, with Concurrency parallel queries.
+func (s *searcher) searchPath(search *Search, seeds []peer.Peer) error {
 	toQuery := NewQueryQueue()
 	peerResponses := make(chan *peerResponse, 1)
 
@@ -84,11 +108,18 @@ func (s *searcher) Search(search *Search, seeds []peer.Peer) error {
 	search.Result.Unqueried.SafePushMany(seeds)
 
 	go func() {
+		nSent := 0
 		for c := uint(0); c < search.Params.Concurrency; c++ {
 			if next := getNextToQuery(search); next != nil {
 				toQuery.MaybeSend(next)
+				nSent++
 			}
 		}
+		if nSent == 0 {
+			// no responses will come to queue more peers, e.g., when another disjoint path
+			// has claimed all the seeds
+			toQuery.MaybeClose()
+		}
 	}()
 
 	// goroutine that processes responses and queues up next peer to query
@@ -127,7 +158,7 @@ func (s *searcher) Search(search *Search, seeds []peer.Peer) error {
 }
 
 func (s *searcher) query(next peer.Peer, search *Search) (*api.FindResponse, error) {
-	lc, err := s.finderCreator.Create(next.Address().String())
+	lc, err := s.finderCreator.Create(peer.DialAddress(next))
 	if err != nil {
 		return nil, err
 	}
@@ -196,14 +227,18 @@ func getNextToQuery(search *Search) peer.Peer {
 	}
 	search.Mu.Lock()
 	defer search.Mu.Unlock()
-	if search.Result.Unqueried.Len() == 0 {
-		return nil
-	}
-	next := heap.Pop(search.Result.Unqueried).(peer.Peer)
-	if _, alreadyQueried := search.Result.Queried[next.ID().String()]; alreadyQueried {
-		return nil
+	for search.Result.Unqueried.Len() > 0 {
+		next := heap.Pop(search.Result.Unqueried).(peer.Peer)
+		if _, alreadyQueried := search.Result.Queried[next.ID().String()]; alreadyQueried {
+			return nil
+		}
+		if search.claims != nil && !search.claims.Claim(next.ID(), search.path) {
+			// skip peers already claimed by this or another disjoint path
+			continue
+		}
+		return next
 	}
-	return next
+	return nil
 }
 
 func maybeSendNextToQuery(toQuery *QueryQueue, search *Search) {
@@ -273,7 +308,23 @@ func NewResponseProcessor(f peer.Fromer, doc comm.Doctor) ResponseProcessor {
 func (frp *responseProcessor) Process(rp *api.FindResponse, s *Search) error {
 	if rp.Value != nil {
 		// response has value we're searching for
-		s.Result.Value = rp.Value
+		if s.PeersOnly {
+			return nil
+		}
+		if pointer := rp.Value.GetPointer(); pointer != nil {
+			// only keep pointers actually signed for the key, since a forged one with a higher
+			// sequence would otherwise replace the genuine newest version
+			if err := api.ValidatePointer(pointer); err != nil {
+				return err
+			}
+			pointerKey := api.GetPointerKey(pointer.AuthorPublicKey, pointer.Name)
+			if !bytes.Equal(pointerKey.Bytes(), s.Key.Bytes()) {
+				return api.ErrUnexpectedKey
+			}
+		}
+		s.wrapLock(func() {
+			s.Result.Value = newestValue(s.Result.Value, rp.Value)
+		})
 		return nil
 	}
 
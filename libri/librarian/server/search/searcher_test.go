@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -93,6 +94,110 @@ func TestSearcher_Search_ok(t *testing.T) {
 	}
 }
 
+func TestSearcher_Search_disjoint(t *testing.T) {
+	n, nClosestResponses, nPaths := 64, uint(6), uint(3)
+	rng := rand.New(rand.NewSource(int64(n)))
+	peers, peersMap, addressFinders, selfPeerIdxs, peerID := NewTestPeers(rng, n)
+	orgID := ecid.NewPseudoRandom(rng)
+	key := id.NewPseudoRandom(rng)
+
+	rec := &fixedRecorder{}
+	searcherImpl := NewTestSearcher(peersMap, addressFinders, rec)
+	finders := &countingFinderCreator{
+		inner:  searcherImpl.(*searcher).finderCreator,
+		counts: make(map[string]int),
+	}
+	searcherImpl.(*searcher).finderCreator = finders
+	search := NewSearch(peerID, orgID, key, &Parameters{
+		NClosestResponses: nClosestResponses,
+		NMaxErrors:        DefaultNMaxErrors,
+		Concurrency:       2,
+		NDisjointPaths:    nPaths,
+		Timeout:           DefaultQueryTimeout,
+	})
+	seeds := NewTestSeeds(peers, selfPeerIdxs)
+
+	err := searcherImpl.Search(search, seeds)
+	assert.Nil(t, err)
+	assert.Equal(t, int(nPaths), search.nPaths)
+	assert.True(t, search.Finished())
+	assert.True(t, search.FoundClosestPeers())
+	assert.False(t, search.Errored())
+	assert.Equal(t, int(nClosestResponses), search.Result.Closest.Len())
+	assert.Equal(t, len(search.Result.Queried), len(search.Result.Responded))
+
+	// no peer queried on more than one path
+	for address, count := range finders.counts {
+		assert.Equal(t, 1, count, address)
+	}
+	assert.Equal(t, len(search.Result.Queried), len(finders.counts))
+}
+
+func TestSearcher_Search_disjointErr(t *testing.T) {
+	rec := &fixedRecorder{}
+	searcherImpl, search, selfPeerIdxs, peers := newTestSearch(rec)
+	search.Params.NDisjointPaths = 2
+	seeds := NewTestSeeds(peers, selfPeerIdxs)
+
+	// all queries return errors, so all paths error
+	searcherImpl.(*searcher).finderCreator = &TestFinderCreator{
+		err: errors.New("some Create error"),
+	}
+	err := searcherImpl.Search(search, seeds)
+	assert.Equal(t, ErrTooManyFindErrors, err)
+	assert.True(t, search.Errored())
+	assert.False(t, search.FoundClosestPeers())
+	assert.Equal(t, 2*int(search.Params.NMaxErrors+1), len(search.Result.Errored))
+}
+
+func TestSearch_mergePaths(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, key := ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	params := NewDefaultParameters()
+	params.NMaxErrors = 1
+	s := NewSearch(peerID, nil, key, params)
+	claims := NewPathClaims()
+	path1, path2 := s.newPath(0, claims), s.newPath(1, claims)
+	ps := peer.NewTestPeers(rng, 4)
+
+	// path 1 errored, path 2 responded
+	for _, p := range ps[:2] {
+		path1.Result.Queried[p.ID().String()] = struct{}{}
+		path1.Result.Errored[p.ID().String()] = errors.New("some Find error")
+	}
+	path1.Result.FatalErr = ErrTooManyFindErrors
+	path2.Result.Queried[ps[2].ID().String()] = struct{}{}
+	path2.Result.Responded[ps[2].ID().String()] = ps[2]
+	path2.Result.Unqueried.SafePushMany([]peer.Peer{ps[0], ps[3]})
+
+	s.mergePaths([]*Search{path1, path2})
+	assert.Nil(t, s.Result.FatalErr)
+	assert.False(t, s.Errored()) // even though more than NMaxErrors errors overall
+	assert.Len(t, s.Result.Errored, 2)
+	assert.Len(t, s.Result.Queried, 3)
+	assert.Equal(t, 1, s.Result.Closest.Len())
+	assert.Equal(t, []peer.Peer{ps[3]}, s.Result.Unqueried.Peers()) // ps[0] already queried
+
+	// all paths errored
+	s = NewSearch(peerID, nil, key, params)
+	s.mergePaths([]*Search{path1})
+	assert.Equal(t, ErrTooManyFindErrors, s.Result.FatalErr)
+	assert.True(t, s.Errored())
+}
+
+type countingFinderCreator struct {
+	inner  client.FinderCreator
+	counts map[string]int
+	mu     sync.Mutex
+}
+
+func (c *countingFinderCreator) Create(address string) (api.Finder, error) {
+	c.mu.Lock()
+	c.counts[address]++
+	c.mu.Unlock()
+	return c.inner.Create(address)
+}
+
 func TestSearcher_Search_queryErr(t *testing.T) {
 	rec := &fixedRecorder{}
 	searcherImpl, search, selfPeerIdxs, peers := newTestSearch(rec)
@@ -269,6 +374,72 @@ func TestResponseProcessor_Process_Value(t *testing.T) {
 	assert.Equal(t, value, s.Result.Value)
 }
 
+func TestResponseProcessor_Process_Pointer(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	rp := NewResponseProcessor(peer.NewFromer(), comm.NewNaiveDoctor())
+	pointer1, authorKey := api.NewTestPointer(rng)
+	key := api.GetPointerKey(pointer1.AuthorPublicKey, pointer1.Name)
+	s := &Search{
+		Key:    key,
+		Result: NewInitialResult(key, NewDefaultParameters()),
+		Params: NewDefaultParameters(),
+	}
+	pointer2 := *pointer1
+	pointer2.Sequence = 2
+	err := api.SignPointer(&pointer2, authorKey.Key())
+	assert.Nil(t, err)
+	value1 := &api.Document{Contents: &api.Document_Pointer{Pointer: pointer1}}
+	value2 := &api.Document{Contents: &api.Document_Pointer{Pointer: &pointer2}}
+
+	// newer pointer replaces older one, but search continues looking for newer versions
+	err = rp.Process(&api.FindResponse{Value: value1}, s)
+	assert.Nil(t, err)
+	err = rp.Process(&api.FindResponse{Value: value2}, s)
+	assert.Nil(t, err)
+	assert.Equal(t, value2, s.Result.Value)
+	assert.True(t, s.FoundValue())
+	assert.False(t, s.Finished())
+
+	// older pointer doesn't replace newer one
+	err = rp.Process(&api.FindResponse{Value: value1}, s)
+	assert.Nil(t, err)
+	assert.Equal(t, value2, s.Result.Value)
+
+	// pointer with a bad signature
+	forged := pointer2
+	forged.Sequence = 3
+	err = rp.Process(&api.FindResponse{
+		Value: &api.Document{Contents: &api.Document_Pointer{Pointer: &forged}},
+	}, s)
+	assert.NotNil(t, err)
+	assert.Equal(t, value2, s.Result.Value)
+
+	// pointer for a different key
+	other, _ := api.NewTestPointer(rng)
+	err = rp.Process(&api.FindResponse{
+		Value: &api.Document{Contents: &api.Document_Pointer{Pointer: other}},
+	}, s)
+	assert.Equal(t, api.ErrUnexpectedKey, err)
+	assert.Equal(t, value2, s.Result.Value)
+}
+
+func TestResponseProcessor_Process_PeersOnly(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	key := id.NewPseudoRandom(rng)
+	rp := NewResponseProcessor(peer.NewFromer(), comm.NewNaiveDoctor())
+	s := &Search{
+		Result:    NewInitialResult(key, NewDefaultParameters()),
+		PeersOnly: true,
+	}
+
+	// check that the result value isn't set
+	value, _ := api.NewTestDocument(rng)
+	err := rp.Process(&api.FindResponse{Value: value}, s)
+	assert.Nil(t, err)
+	assert.Nil(t, s.Result.Value)
+	assert.False(t, s.FoundValue())
+}
+
 func TestResponseProcessor_Process_Addresses(t *testing.T) {
 	rng := rand.New(rand.NewSource(int64(0)))
 
@@ -359,11 +530,14 @@ func newPeerAddresses(rng *rand.Rand, n int) []*api.PeerAddress {
 type fixedRecorder struct {
 	nSuccesses int
 	nErrors    int
+	mu         sync.Mutex
 }
 
 func (f *fixedRecorder) Record(
 	peerID id.ID, endpoint api.Endpoint, qt comm.QueryType, o comm.Outcome,
 ) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	if o == comm.Success {
 		f.nSuccesses++
 	} else {
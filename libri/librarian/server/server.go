@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
+	"sync"
 
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/drausin/libri/libri/librarian/server/comm"
 	"github.com/drausin/libri/libri/librarian/server/introduce"
 	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/refresh"
 	"github.com/drausin/libri/libri/librarian/server/replicate"
 	"github.com/drausin/libri/libri/librarian/server/routing"
 	"github.com/drausin/libri/libri/librarian/server/search"
@@ -69,6 +71,9 @@ type Librarian struct {
 	// replicates documents as needed
 	replicator replicate.Replicator
 
+	// refreshes stale routing table buckets and evicts unhealthy peers
+	refresher refresh.Refresher
+
 	// manages subscriptions from other peers
 	subscribeFrom subscribe.From
 
@@ -81,6 +86,18 @@ type Librarian struct {
 	// verifies requests from peers
 	rqv RequestVerifier
 
+	// trust determines which peers are known when using a trust list; nil otherwise
+	trust comm.TrustKnower
+
+	// orgs records the organizations requesters have proven they belong to
+	orgs comm.PeerOrgs
+
+	// decides whether new peers may join the routing table
+	admitter routing.Admitter
+
+	// certs checks the organization certificates presented with requests
+	certs *certChecker
+
 	// key-value store DB used for all external storage
 	db db.KVDB
 
@@ -131,6 +148,12 @@ type Librarian struct {
 
 	// closed when server is stopped
 	stopped chan struct{}
+
+	// ensures cleanup only happens once, even if Close is called multiple times
+	closeOnce sync.Once
+
+	// result of the first Close call
+	closeErr error
 }
 
 // NewLibrarian creates a new librarian instance.
@@ -144,13 +167,20 @@ func NewLibrarian(config *Config, logger *zap.Logger) (*Librarian, error) {
 	documentSL := storage.NewDocumentSLD(rdb)
 
 	// get peer ID and immediately save it so subsequent restarts have it
-	peerID, err := loadOrCreatePeerID(logger, serverSL)
+	peerID, err := loadOrCreatePeerID(logger, serverSL, config.Routing.MinIDWork)
 	if err != nil {
 		return nil, err
 	}
 	selfLogger := logger.With(zap.String(logSelfIDShort, id.ShortHex(peerID.Bytes())))
 
-	knower := comm.NewAlwaysKnower()
+	orgs, err := comm.NewPeerOrgs(comm.DefaultPeerOrgsSize)
+	if err != nil {
+		return nil, err
+	}
+	knower, trust, err := newKnower(config.TrustListFile, orgs)
+	if err != nil {
+		return nil, err
+	}
 
 	// TODO (drausin) load recorder from storage instead of initializing empty
 	windows := []time.Duration{comm.Second, comm.Day, comm.Week}
@@ -159,15 +189,30 @@ func NewLibrarian(config *Config, logger *zap.Logger) (*Librarian, error) {
 		recorder = comm.NewPromScalarRecorder(peerID.ID(), recorder)
 	}
 	prefer := comm.NewRpPreferer(getters[comm.Day])
-	allower := comm.NewDefaultAllower(knower, getters)
+	allower, err := comm.NewReloadableAllower(config.LimitsFile, knower, getters)
+	if err != nil {
+		logger.Error("unable to load limits", zap.String(logLimitsFile, config.LimitsFile),
+			zap.Error(err))
+		return nil, err
+	}
 	doctor := comm.NewResponseTimeDoctor(getters[comm.Day])
 
-	rt := routing.NewEmpty(peerID.ID(), prefer, doctor, config.Routing)
-	clients, err := client.NewDefaultLRUPool()
+	certVerifier := client.NewCertificateVerifier(config.ClockSkew)
+	certs, err := newCertChecker(certVerifier, trust, config.RequireCertificates)
+	if err != nil {
+		return nil, err
+	}
+	admitter := newCertAdmitter(routing.NewAdmitter(config.Routing, orgs), certs, orgs)
+	rt := routing.NewEmptyWithAdmitter(peerID.ID(), prefer, doctor, config.Routing, admitter)
+	clients, err := client.NewDefaultLRUPool(config.DialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	peerSigner, err := newPeerSigner(peerID, config.CertificateFile, certVerifier)
 	if err != nil {
+		logger.Error("unable to load peer certificate", zap.Error(err))
 		return nil, err
 	}
-	peerSigner := client.NewECDSASigner(peerID.Key())
 	orgSigner := client.NewEmptySigner()
 	if config.OrgID != nil {
 		orgSigner = client.NewECDSASigner(config.OrgID.Key())
@@ -177,7 +222,7 @@ func NewLibrarian(config *Config, logger *zap.Logger) (*Librarian, error) {
 	storer := store.NewStorer(peerSigner, orgSigner, recorder, doctor, searcher,
 		client.NewStorerCreator(clients))
 	introducer := introduce.NewDefaultIntroducer(peerSigner, orgSigner, recorder, peerID.ID(),
-		clients)
+		clients, admitter)
 	verifier := verify.NewDefaultVerifier(peerSigner, orgSigner, recorder, doctor, clients)
 
 	newPubs := make(chan *subscribe.KeyedPub, newPublicationsSlack)
@@ -185,7 +230,11 @@ func NewLibrarian(config *Config, logger *zap.Logger) (*Librarian, error) {
 	if err != nil {
 		return nil, err
 	}
-	clientBalancer := routing.NewClientBalancer(rt, clients)
+	rqv, err := NewRequestVerifier(config.ClockSkew, config.ReplayCacheMemory)
+	if err != nil {
+		return nil, err
+	}
+	clientBalancer := newSubscribeToBalancer(config.SubscribeTo.Selection, rt, clients)
 	subscribeTo := subscribe.NewTo(config.SubscribeTo, selfLogger, peerID, config.OrgID,
 		clientBalancer, peerSigner, orgSigner, recentPubs, newPubs)
 
@@ -193,6 +242,10 @@ func NewLibrarian(config *Config, logger *zap.Logger) (*Librarian, error) {
 	metricsSM.Handle("/metrics", promhttp.Handler())
 	metrics := &http.Server{Addr: fmt.Sprintf(":%d", config.LocalMetricsPort), Handler: metricsSM}
 
+	// verifications follow as many disjoint paths as searches
+	verifyParams := verify.NewDefaultParameters()
+	verifyParams.NDisjointPaths = config.Search.NDisjointPaths
+
 	rng := rand.New(rand.NewSource(peerID.Int().Int64()))
 	replicator := replicate.NewReplicator(
 		peerID,
@@ -202,30 +255,51 @@ func NewLibrarian(config *Config, logger *zap.Logger) (*Librarian, error) {
 		verifier,
 		storer,
 		config.Replicate,
-		verify.NewDefaultParameters(),
+		verifyParams,
 		config.Store,
 		rng,
 		selfLogger,
 	)
+	refresher := refresh.NewRefresher(
+		peerID,
+		config.OrgID,
+		peerSigner,
+		orgSigner,
+		rt,
+		searcher,
+		client.NewFinderCreator(clients),
+		recorder,
+		getters[comm.Day],
+		doctor,
+		config.Refresh,
+		config.Search,
+		rand.New(rand.NewSource(rng.Int63())),
+		selfLogger,
+	)
 	storageMetrics := newStorageMetrics(serverSL)
 
 	return &Librarian{
 		peerID:         peerID,
 		config:         config,
-		apiSelf:        peer.FromAddress(peerID.ID(), config.PublicName, config.PublicAddr),
+		apiSelf:        newAPISelf(peerID.ID(), config, peerSigner),
 		introducer:     introducer,
 		searcher:       searcher,
 		replicator:     replicator,
+		refresher:      refresher,
 		storer:         storer,
 		subscribeFrom:  subscribe.NewFrom(config.SubscribeFrom, logger, newPubs),
 		subscribeTo:    subscribeTo,
 		RecentPubs:     recentPubs,
-		rqv:            NewRequestVerifier(),
+		rqv:            rqv,
+		trust:          trust,
+		orgs:           orgs,
+		admitter:       admitter,
+		certs:          certs,
 		db:             rdb,
 		serverSL:       serverSL,
 		documentSL:     documentSL,
 		kc:             storage.NewExactLengthChecker(storage.EntriesKeyLength),
-		kvc:            storage.NewHashKeyValueChecker(),
+		kvc:            storage.NewDocumentKeyValueChecker(),
 		fromer:         peer.NewFromer(),
 		signer:         peerSigner,
 		clients:        clients,
@@ -257,6 +331,10 @@ func (l *Librarian) Introduce(ctx context.Context, rq *api.IntroduceRequest) (
 		l.record(requesterID, endpoint, comm.Request, comm.Error)
 		return nil, logReturnNotAllowedErr(lg, err)
 	}
+	if err := l.rqv.CheckReplay(ctx, rq.Metadata); err != nil {
+		l.record(requesterID, endpoint, comm.Request, comm.Error)
+		return nil, logReturnInvalidRqErr(lg, err)
+	}
 	requester := l.fromer.FromAPI(rq.Self)
 	if requester.ID().Cmp(requesterID) != 0 {
 		l.record(requesterID, endpoint, comm.Request, comm.Error)
@@ -295,6 +373,10 @@ func (l *Librarian) Find(ctx context.Context, rq *api.FindRequest) (*api.FindRes
 		l.record(requesterID, endpoint, comm.Request, comm.Error)
 		return nil, logReturnNotAllowedErr(lg, err)
 	}
+	if err := l.rqv.CheckReplay(ctx, rq.Metadata); err != nil {
+		l.record(requesterID, endpoint, comm.Request, comm.Error)
+		return nil, logReturnInvalidRqErr(lg, err)
+	}
 	l.record(requesterID, endpoint, comm.Request, comm.Success)
 
 	value, err := l.documentSL.Load(id.FromBytes(rq.Key))
@@ -342,6 +424,10 @@ func (l *Librarian) Verify(
 		l.record(requesterID, endpoint, comm.Request, comm.Error)
 		return nil, logReturnNotAllowedErr(lg, err)
 	}
+	if err := l.rqv.CheckReplay(ctx, rq.Metadata); err != nil {
+		l.record(requesterID, endpoint, comm.Request, comm.Error)
+		return nil, logReturnInvalidRqErr(lg, err)
+	}
 	l.record(requesterID, endpoint, comm.Request, comm.Success)
 
 	mac, err := l.documentSL.Mac(id.FromBytes(rq.Key), rq.MacKey)
@@ -387,14 +473,32 @@ func (l *Librarian) Store(ctx context.Context, rq *api.StoreRequest) (
 		l.record(requesterID, endpoint, comm.Request, comm.Error)
 		return nil, logReturnNotAllowedErr(lg, err)
 	}
+	if err := l.rqv.CheckReplay(ctx, rq.Metadata); err != nil {
+		l.record(requesterID, endpoint, comm.Request, comm.Error)
+		return nil, logReturnInvalidRqErr(lg, err)
+	}
 	l.record(requesterID, endpoint, comm.Request, comm.Success)
 
-	if err := l.documentSL.Store(id.FromBytes(rq.Key), rq.Value); err != nil {
+	key := id.FromBytes(rq.Key)
+	overwrites := false
+	if _, isPointer := rq.Value.Contents.(*api.Document_Pointer); isPointer {
+		// pointers overwrite any older version, which the metrics have already counted
+		stored, err := l.documentSL.Load(key)
+		if err != nil {
+			return nil, logReturnInternalErr(lg, "error loading stored pointer", err)
+		}
+		overwrites = stored != nil
+	}
+	if err := l.documentSL.Store(key, rq.Value); err == api.ErrStalePointer {
+		return nil, logReturnFailedPreconditionErr(lg, err)
+	} else if err != nil {
 		return nil, logReturnInternalErr(lg, "error storing document", err)
 	}
-	if err := l.storageMetrics.Add(rq.Value); err != nil {
-		// don't hard-fail on this since just internal book-keeping
-		lg.Error("error storing metric", zap.Error(err))
+	if !overwrites {
+		if err := l.storageMetrics.Add(rq.Value); err != nil {
+			// don't hard-fail on this since just internal book-keeping
+			lg.Error("error storing metric", zap.Error(err))
+		}
 	}
 	if err := l.subscribeTo.Send(api.GetPublication(rq.Key, rq.Value)); err != nil {
 		return nil, logReturnInternalErr(lg, "error sending publication", err)
@@ -422,6 +526,10 @@ func (l *Librarian) Get(ctx context.Context, rq *api.GetRequest) (*api.GetRespon
 		l.record(requesterID, endpoint, comm.Request, comm.Error)
 		return nil, logReturnNotAllowedErr(lg, err)
 	}
+	if err := l.rqv.CheckReplay(ctx, rq.Metadata); err != nil {
+		l.record(requesterID, endpoint, comm.Request, comm.Error)
+		return nil, logReturnInvalidRqErr(lg, err)
+	}
 	l.record(requesterID, endpoint, comm.Request, comm.Success)
 
 	key := id.FromBytes(rq.Key)
@@ -479,6 +587,10 @@ func (l *Librarian) Put(ctx context.Context, rq *api.PutRequest) (*api.PutRespon
 		l.record(requesterID, endpoint, comm.Request, comm.Error)
 		return nil, logReturnNotAllowedErr(lg, err)
 	}
+	if err := l.rqv.CheckReplay(ctx, rq.Metadata); err != nil {
+		l.record(requesterID, endpoint, comm.Request, comm.Error)
+		return nil, logReturnInvalidRqErr(lg, err)
+	}
 	l.record(requesterID, endpoint, comm.Request, comm.Success)
 
 	key := id.FromBytes(rq.Key)
@@ -544,6 +656,10 @@ func (l *Librarian) Subscribe(rq *api.SubscribeRequest, from api.Librarian_Subsc
 		l.record(requesterID, endpoint, comm.Request, comm.Error)
 		return logReturnNotAllowedErr(lg, err)
 	}
+	if err := l.rqv.CheckReplay(from.Context(), rq.Metadata); err != nil {
+		l.record(requesterID, endpoint, comm.Request, comm.Error)
+		return logReturnInvalidRqErr(lg, err)
+	}
 	authorFilter, err := subscribe.FromAPI(rq.Subscription.AuthorPublicKeys)
 	if err != nil {
 		l.record(requesterID, endpoint, comm.Request, comm.Error)
@@ -563,10 +679,20 @@ func (l *Librarian) Subscribe(rq *api.SubscribeRequest, from api.Librarian_Subsc
 	}
 
 	responseMetadata := l.NewResponseMetadata(rq.Metadata)
-	for pub := range pubs {
-		err = maybeSend(pub, authorFilter, readerFilter, from, responseMetadata, done)
-		if err != nil {
-			return logReturnUnavailErr(lg, "subscribe send error", err)
+subscription:
+	for {
+		select {
+		case <-l.stop:
+			// end subscription so server can stop gracefully
+			break subscription
+		case pub, open := <-pubs:
+			if !open {
+				break subscription
+			}
+			err = maybeSend(pub, authorFilter, readerFilter, from, responseMetadata, done)
+			if err != nil {
+				return logReturnUnavailErr(lg, "subscribe send error", err)
+			}
 		}
 	}
 
@@ -55,6 +55,10 @@ func TestNewLibrarian(t *testing.T) {
 	err := l1.Close()
 	assert.Nil(t, err)
 
+	// closing again should be a no-op
+	err = l1.Close()
+	assert.Nil(t, err)
+
 	l2, err := NewLibrarian(l1.config, zap.NewNop())
 	go func() {
 		err2 := l2.replicator.Start()
@@ -96,6 +100,11 @@ func (av *alwaysRequestVerifier) Verify(ctx context.Context, msg proto.Message,
 	return nil
 }
 
+func (av *alwaysRequestVerifier) CheckReplay(ctx context.Context,
+	meta *api.RequestMetadata) error {
+	return nil
+}
+
 func TestLibrarian_Introduce_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	peerName, serverPeerIdx := "server", 0
@@ -360,7 +369,7 @@ func TestLibrarian_Find_err(t *testing.T) {
 		"bad pub key": {
 			l: &Librarian{
 				logger: zap.NewNop(), // clogging.NewDevInfoLogger()
-				rqv:    NewRequestVerifier(),
+				rqv:    newTestRequestVerifier(t),
 				kc:     storage.NewExactLengthChecker(storage.EntriesKeyLength),
 				rec:    rec,
 			},
@@ -411,6 +420,42 @@ func TestLibrarian_Find_err(t *testing.T) {
 	}
 }
 
+func TestLibrarian_Find_replay(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, key := ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
+	rt, _, _, _ := routing.NewTestWithPeers(rng, 0)
+	allower := &fixedAllower{errNotAllowed}
+	l := &Librarian{
+		peerID:     ecid.NewPseudoRandom(rng),
+		logger:     zap.NewNop(), // clogging.NewDevInfoLogger()
+		rqv:        newTestRequestVerifier(t),
+		kc:         storage.NewExactLengthChecker(storage.EntriesKeyLength),
+		documentSL: &storage.TestDocSLD{},
+		rec:        comm.NewQueryRecorderGetter(comm.NewAlwaysKnower()),
+		allower:    allower,
+		rt:         rt,
+	}
+	rq := client.NewFindRequest(peerID, nil, key, uint(8))
+	signedJWT, err := client.NewECDSASigner(peerID.Key()).Sign(rq)
+	assert.Nil(t, err)
+	ctx := client.NewIncomingSignatureContext(context.Background(), signedJWT, "")
+
+	// requests that aren't allowed aren't remembered
+	rp, err := l.Find(ctx, rq)
+	assert.Nil(t, rp)
+	assert.Equal(t, codes.PermissionDenied, getErrCode(t, err))
+
+	allower.allow = nil
+	rp, err = l.Find(ctx, rq)
+	assert.Nil(t, err)
+	assert.NotNil(t, rp)
+
+	// but allowed ones are
+	rp, err = l.Find(ctx, rq)
+	assert.Nil(t, rp)
+	assert.Equal(t, codes.InvalidArgument, getErrCode(t, err))
+}
+
 func TestLibrarian_Verify_value(t *testing.T) {
 	rng := rand.New(rand.NewSource(int64(0)))
 	rt, peerID, _, _ := routing.NewTestWithPeers(rng, 64)
@@ -544,7 +589,7 @@ func TestLibrarian_Verify_err(t *testing.T) {
 		"bad pub key": {
 			l: &Librarian{
 				logger: zap.NewNop(), // clogging.NewDevInfoLogger(),
-				rqv:    NewRequestVerifier(),
+				rqv:    newTestRequestVerifier(t),
 				kc:     storage.NewExactLengthChecker(storage.EntriesKeyLength),
 				rec:    rec,
 			},
@@ -643,6 +688,54 @@ func TestLibrarian_Store_ok(t *testing.T) {
 	assert.Equal(t, 1, int(qo[comm.Request][comm.Success].Count))
 }
 
+func TestLibrarian_Store_pointerOverwrite(t *testing.T) {
+	rng := rand.New(rand.NewSource(int64(0)))
+	rt, peerID, _, _ := routing.NewTestWithPeers(rng, 64)
+	kvdb, cleanup, err := db.NewTempDirRocksDB()
+	defer cleanup()
+	defer kvdb.Close()
+	assert.Nil(t, err)
+
+	serverSL := storage.NewServerSL(kvdb)
+	l := &Librarian{
+		peerID:         peerID,
+		rt:             rt,
+		db:             kvdb,
+		serverSL:       serverSL,
+		documentSL:     storage.NewDocumentSLD(kvdb),
+		subscribeTo:    &fixedTo{},
+		kc:             storage.NewExactLengthChecker(storage.EntriesKeyLength),
+		kvc:            storage.NewDocumentKeyValueChecker(),
+		rqv:            &alwaysRequestVerifier{},
+		storageMetrics: newStorageMetrics(serverSL),
+		rec:            comm.NewQueryRecorderGetter(comm.NewAlwaysKnower()),
+		allower:        &fixedAllower{},
+		logger:         zap.NewNop(), // clogging.NewDevInfoLogger(),
+	}
+	defer l.storageMetrics.unregister()
+
+	// store two versions of the same pointer
+	pointer, authorKey := api.NewTestPointer(rng)
+	key := api.GetPointerKey(pointer.AuthorPublicKey, pointer.Name)
+	for seq := uint64(1); seq <= 2; seq++ {
+		pointer.Sequence = seq
+		assert.Nil(t, api.SignPointer(pointer, authorKey.Key()))
+		rq := &api.StoreRequest{
+			Metadata: newTestRequestMetadata(rng, l.peerID),
+			Key:      key.Bytes(),
+			Value:    &api.Document{Contents: &api.Document_Pointer{Pointer: pointer}},
+		}
+		rp, err := l.Store(context.Background(), rq)
+		assert.Nil(t, err)
+		assert.NotNil(t, rp)
+	}
+
+	// check the overwritten pointer is only counted once
+	count, err := l.storageMetrics.getStored(pointerCountKey)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), count)
+}
+
 func newTestRequestMetadata(rng *rand.Rand, peerID ecid.ID) *api.RequestMetadata {
 	return &api.RequestMetadata{
 		RequestId: id.NewPseudoRandom(rng).Bytes(),
@@ -724,6 +817,33 @@ func TestLibrarian_Store_storeError(t *testing.T) {
 	assert.Equal(t, 1, int(qo[comm.Request][comm.Success].Count))
 }
 
+func TestLibrarian_Store_stalePointerError(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	rt, peerID, _, _ := routing.NewTestWithPeers(rng, 64)
+	orgID := ecid.NewPseudoRandom(rng)
+	sld := storage.NewTestDocSLD()
+	sld.StoreErr = api.ErrStalePointer
+	l := &Librarian{
+		peerID:     peerID,
+		rt:         rt,
+		kc:         storage.NewExactLengthChecker(storage.EntriesKeyLength),
+		kvc:        storage.NewDocumentKeyValueChecker(),
+		rqv:        &alwaysRequestVerifier{},
+		documentSL: sld,
+		rec:        comm.NewQueryRecorderGetter(comm.NewAlwaysKnower()),
+		allower:    &fixedAllower{},
+		logger:     zap.NewNop(), // clogging.NewDevInfoLogger(),
+	}
+	pointer, _ := api.NewTestPointer(rng)
+	value := &api.Document{Contents: &api.Document_Pointer{Pointer: pointer}}
+	key := api.GetPointerKey(pointer.AuthorPublicKey, pointer.Name)
+	rq := client.NewStoreRequest(peerID, orgID, key, value)
+
+	rp, err := l.Store(context.Background(), rq)
+	assert.Nil(t, rp)
+	assert.Equal(t, codes.FailedPrecondition, getErrCode(t, err))
+}
+
 type fixedSearcher struct {
 	result *search.Result
 	err    error
@@ -1126,6 +1246,42 @@ func TestLibrarian_Subscribe_ok(t *testing.T) {
 	wg.Wait()
 }
 
+func TestLibrarian_Subscribe_stop(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	done := make(chan struct{})
+	rt, peerID, _, _ := routing.NewTestWithPeers(rng, 0)
+	orgID := ecid.NewPseudoRandom(rng)
+	l := &Librarian{
+		peerID: peerID,
+		subscribeFrom: &fixedFrom{
+			new:  make(chan *subscribe.KeyedPub), // never sends or closes
+			done: done,
+		},
+		rqv:     &alwaysRequestVerifier{},
+		rt:      rt,
+		rec:     comm.NewQueryRecorderGetter(comm.NewAlwaysKnower()),
+		allower: &fixedAllower{},
+		logger:  zap.NewNop(),
+		stop:    make(chan struct{}),
+	}
+	sub, err := subscribe.NewFPSubscription(1.0, rng)
+	assert.Nil(t, err)
+	rq := client.NewSubscribeRequest(peerID, orgID, sub)
+	from := &fixedLibrarianSubscribeServer{
+		sent: make(chan *api.SubscribeResponse),
+	}
+
+	// stopping the server should gracefully end the subscription
+	close(l.stop)
+	err = l.Subscribe(rq, from)
+	assert.Nil(t, err)
+	select {
+	case <-done:
+	default:
+		t.Error("subscription done channel should be closed")
+	}
+}
+
 func TestLibrarian_Subscribe_err(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	sub, err := subscribe.NewFPSubscription(1.0, rng) // get everything
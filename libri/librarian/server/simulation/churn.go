@@ -0,0 +1,110 @@
+package simulation
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// ChurnEvent is a peer leaving or (re)joining the network at a point in virtual time.
+type ChurnEvent struct {
+	// At is the virtual time of the event.
+	At time.Duration
+
+	// Peer is the index of the peer leaving or joining.
+	Peer int
+
+	// Up is whether the peer joins (true) or leaves (false).
+	Up bool
+}
+
+// ChurnModel decides when peers leave and rejoin the network.
+type ChurnModel interface {
+	// Events returns the churn events, ordered by time, for the given peers over the duration
+	// of a simulation.
+	Events(rng *rand.Rand, peers []int, duration time.Duration) []*ChurnEvent
+}
+
+// NewNoChurn returns a ChurnModel where peers never leave.
+func NewNoChurn() ChurnModel {
+	return &noChurn{}
+}
+
+type noChurn struct{}
+
+func (*noChurn) Events(rng *rand.Rand, peers []int, duration time.Duration) []*ChurnEvent {
+	return []*ChurnEvent{}
+}
+
+// NewExponentialChurn returns a ChurnModel where each peer alternates between being up and
+// down for exponentially-distributed amounts of time with the given means.
+func NewExponentialChurn(meanUptime, meanDowntime time.Duration) ChurnModel {
+	return &exponentialChurn{
+		meanUptime:   meanUptime,
+		meanDowntime: meanDowntime,
+	}
+}
+
+type exponentialChurn struct {
+	meanUptime   time.Duration
+	meanDowntime time.Duration
+}
+
+func (c *exponentialChurn) Events(
+	rng *rand.Rand, peers []int, duration time.Duration,
+) []*ChurnEvent {
+	events := make([]*ChurnEvent, 0)
+	for _, p := range peers {
+		at, up := time.Duration(0), true
+		for {
+			mean := c.meanUptime
+			if !up {
+				mean = c.meanDowntime
+			}
+			at += time.Duration(rng.ExpFloat64() * float64(mean))
+			if at >= duration {
+				break
+			}
+			up = !up
+			events = append(events, &ChurnEvent{At: at, Peer: p, Up: up})
+		}
+	}
+	sortChurnEvents(events)
+	return events
+}
+
+// NewCrashChurn returns a ChurnModel where a random fraction of peers leave for good at the
+// given time, e.g., to simulate a datacenter outage.
+func NewCrashChurn(fraction float64, at time.Duration) ChurnModel {
+	return &crashChurn{
+		fraction: fraction,
+		at:       at,
+	}
+}
+
+type crashChurn struct {
+	fraction float64
+	at       time.Duration
+}
+
+func (c *crashChurn) Events(rng *rand.Rand, peers []int, duration time.Duration) []*ChurnEvent {
+	if c.at >= duration {
+		return []*ChurnEvent{}
+	}
+	nCrashed := int(c.fraction * float64(len(peers)))
+	events := make([]*ChurnEvent, nCrashed)
+	for i, j := range rng.Perm(len(peers))[:nCrashed] {
+		events[i] = &ChurnEvent{At: c.at, Peer: peers[j], Up: false}
+	}
+	sortChurnEvents(events)
+	return events
+}
+
+func sortChurnEvents(events []*ChurnEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].At == events[j].At {
+			return events[i].Peer < events[j].Peer
+		}
+		return events[i].At < events[j].At
+	})
+}
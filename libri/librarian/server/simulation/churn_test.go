@@ -0,0 +1,61 @@
+package simulation
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoChurn_Events(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	events := NewNoChurn().Events(rng, []int{0, 1, 2}, time.Hour)
+	assert.Len(t, events, 0)
+}
+
+func TestExponentialChurn_Events(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peers := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	duration := time.Hour
+	events := NewExponentialChurn(10*time.Minute, 5*time.Minute).Events(rng, peers, duration)
+	assert.True(t, len(events) > len(peers))
+
+	up := make(map[int]bool)
+	for _, p := range peers {
+		up[p] = true
+	}
+	for i, e := range events {
+		assert.True(t, e.At < duration)
+		if i > 0 {
+			assert.True(t, events[i-1].At <= e.At)
+		}
+
+		// each peer alternates between leaving and rejoining
+		assert.NotEqual(t, up[e.Peer], e.Up)
+		up[e.Peer] = e.Up
+	}
+}
+
+func TestCrashChurn_Events(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peers := []int{3, 4, 5, 6, 7, 8, 9, 10}
+	at := 30 * time.Minute
+	events := NewCrashChurn(0.5, at).Events(rng, peers, time.Hour)
+	assert.Len(t, events, 4)
+	crashed := make(map[int]struct{})
+	for i, e := range events {
+		assert.Equal(t, at, e.At)
+		assert.False(t, e.Up)
+		assert.Contains(t, peers, e.Peer)
+		if i > 0 {
+			assert.True(t, events[i-1].Peer < e.Peer)
+		}
+		crashed[e.Peer] = struct{}{}
+	}
+	assert.Len(t, crashed, 4)
+
+	// crash after end of simulation
+	events = NewCrashChurn(0.5, 2*time.Hour).Events(rng, peers, time.Hour)
+	assert.Len(t, events, 0)
+}
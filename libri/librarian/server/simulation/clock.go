@@ -0,0 +1,90 @@
+package simulation
+
+import (
+	"container/heap"
+	"time"
+)
+
+// Clock is a virtual clock that jumps from one scheduled event to the next. Events run one at a
+// time in order of their time, and events scheduled for the same time run in the order they were
+// scheduled, so a run is the same every time given the same schedule.
+type Clock struct {
+	now    time.Duration
+	events *eventQueue
+	nextID uint64
+}
+
+// NewClock returns a new Clock at time zero without any scheduled events.
+func NewClock() *Clock {
+	events := make(eventQueue, 0)
+	return &Clock{events: &events}
+}
+
+// Now returns the current virtual time since the start of the simulation.
+func (c *Clock) Now() time.Duration {
+	return c.now
+}
+
+// Schedule schedules an event to run at the given virtual time. Events scheduled in the past run
+// at the current time.
+func (c *Clock) Schedule(at time.Duration, run func()) {
+	if at < c.now {
+		at = c.now
+	}
+	heap.Push(c.events, &event{at: at, id: c.nextID, run: run})
+	c.nextID++
+}
+
+// Len returns the number of scheduled events that have not yet run.
+func (c *Clock) Len() int {
+	return c.events.Len()
+}
+
+// RunUntil runs all events scheduled up to and including the given time, including those
+// scheduled by other events along the way, and then advances the clock to that time.
+func (c *Clock) RunUntil(end time.Duration) {
+	for c.events.Len() > 0 && (*c.events)[0].at <= end {
+		next := heap.Pop(c.events).(*event)
+		c.now = next.at
+		next.run()
+	}
+	if end > c.now {
+		c.now = end
+	}
+}
+
+type event struct {
+	at  time.Duration
+	id  uint64
+	run func()
+}
+
+// eventQueue is a min-heap of events ordered by time and then by scheduling order.
+type eventQueue []*event
+
+func (q eventQueue) Len() int {
+	return len(q)
+}
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at == q[j].at {
+		return q[i].id < q[j].id
+	}
+	return q[i].at < q[j].at
+}
+
+func (q eventQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *eventQueue) Push(x interface{}) {
+	*q = append(*q, x.(*event))
+}
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	*q = old[:n-1]
+	return e
+}
@@ -0,0 +1,40 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock_RunUntil(t *testing.T) {
+	c := NewClock()
+	assert.Zero(t, c.Now())
+	assert.Zero(t, c.Len())
+
+	order := make([]int, 0)
+	c.Schedule(2*time.Second, func() { order = append(order, 2) })
+	c.Schedule(1*time.Second, func() {
+		order = append(order, 1)
+
+		// scheduled in the past, so runs now, after the other event at this time
+		c.Schedule(0, func() { order = append(order, 4) })
+	})
+	c.Schedule(1*time.Second, func() { order = append(order, 3) })
+	c.Schedule(5*time.Second, func() { order = append(order, 5) })
+	assert.Equal(t, 4, c.Len())
+
+	c.RunUntil(3 * time.Second)
+	assert.Equal(t, []int{1, 3, 4, 2}, order)
+	assert.Equal(t, 3*time.Second, c.Now())
+	assert.Equal(t, 1, c.Len())
+
+	c.RunUntil(5 * time.Second)
+	assert.Equal(t, []int{1, 3, 4, 2, 5}, order)
+	assert.Equal(t, 5*time.Second, c.Now())
+	assert.Zero(t, c.Len())
+
+	// clock doesn't go backwards
+	c.RunUntil(time.Second)
+	assert.Equal(t, 5*time.Second, c.Now())
+}
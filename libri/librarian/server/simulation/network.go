@@ -0,0 +1,286 @@
+package simulation
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/drausin/libri/libri/common/ecid"
+	cerrors "github.com/drausin/libri/libri/common/errors"
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/drausin/libri/libri/librarian/server/comm"
+	"github.com/drausin/libri/libri/librarian/server/introduce"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/routing"
+	"github.com/drausin/libri/libri/librarian/server/search"
+	"github.com/drausin/libri/libri/librarian/server/store"
+	"github.com/drausin/libri/libri/librarian/server/verify"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const simPort = 20100
+
+var errPeerDown = status.Error(codes.Unavailable, "simulated peer is down")
+
+// network is an in-memory transport between virtual librarians. Requests are handled directly by
+// the destination node, and requests to nodes that are down fail as unavailable.
+type network struct {
+	nodes  []*node
+	byAddr map[string]*node
+}
+
+func newNetwork(rng *rand.Rand, params *Parameters) *network {
+	n := &network{
+		nodes:  make([]*node, params.NPeers),
+		byAddr: make(map[string]*node),
+	}
+	for i := range n.nodes {
+		n.nodes[i] = newNode(rng, i, n, params)
+		n.byAddr[n.nodes[i].self.Address().String()] = n.nodes[i]
+	}
+	return n
+}
+
+func (n *network) get(address string) (*node, error) {
+	if nd, in := n.byAddr[address]; in && nd.up {
+		return nd, nil
+	}
+	return nil, errPeerDown
+}
+
+// node is a virtual librarian with the real routing table and search, store, verify, and
+// introduce clients but an in-memory document store.
+type node struct {
+	idx        int
+	peerID     ecid.ID
+	self       peer.Peer
+	apiSelf    *api.PeerAddress
+	rt         routing.Table
+	docs       map[string][]byte
+	rng        *rand.Rand
+	up         bool
+	introducer introduce.Introducer
+	searcher   search.Searcher
+	storer     store.Storer
+	verifier   verify.Verifier
+	mu         sync.Mutex
+}
+
+func newNode(rng *rand.Rand, idx int, n *network, params *Parameters) *node {
+	peerID := newPseudoRandomID(rng)
+	name := fmt.Sprintf("sim-peer-%05d", idx)
+	self := peer.New(peerID.ID(), name, simAddress(idx))
+	rec := comm.NewQueryRecorderGetter(comm.NewAlwaysKnower())
+	doc := comm.NewNaiveDoctor()
+	signer := &client.TestNoOpSigner{}
+	fromer := peer.NewFromer()
+	searcher := search.NewSearcher(signer, signer, rec, doc, &finderCreator{n},
+		search.NewResponseProcessor(fromer, doc))
+	admitter := routing.NewAdmitter(params.Routing, nil)
+	return &node{
+		idx:     idx,
+		peerID:  peerID,
+		self:    self,
+		apiSelf: self.ToAPI(),
+		rt: routing.NewEmptyWithAdmitter(peerID.ID(), comm.NewRpPreferer(rec), doc,
+			params.Routing, admitter),
+		docs: make(map[string][]byte),
+		rng:  rand.New(rand.NewSource(rng.Int63())),
+		introducer: introduce.NewIntroducer(signer, signer, rec, &introducerCreator{n},
+			introduce.NewResponseProcessor(fromer, peerID.ID(), admitter)),
+		searcher: searcher,
+		storer:   store.NewStorer(signer, signer, rec, doc, searcher, &storerCreator{n}),
+		verifier: verify.NewVerifier(signer, signer, rec, doc, &verifierCreator{n},
+			verify.NewResponseProcessor(fromer, doc)),
+	}
+}
+
+// newPseudoRandomID returns a peer ID whose key is derived from the RNG. It's used instead of
+// ecid.NewPseudoRandom because ecdsa.GenerateKey deliberately consumes a random number of bytes
+// from the RNG, so the IDs (and everything after) would differ between runs.
+func newPseudoRandomID(rng *rand.Rand) ecid.ID {
+	keyBytes := make([]byte, 32)
+	_, err := rng.Read(keyBytes)
+	cerrors.MaybePanic(err) // should never happen
+
+	// private key must be in [1, N-1]
+	nMinus1 := new(big.Int).Sub(ecid.Curve.Params().N, big.NewInt(1))
+	d := new(big.Int).Mod(new(big.Int).SetBytes(keyBytes), nMinus1)
+	d.Add(d, big.NewInt(1))
+
+	priv := &ecdsa.PrivateKey{D: d}
+	priv.Curve = ecid.Curve
+	priv.X, priv.Y = ecid.Curve.ScalarBaseMult(d.Bytes())
+	peerID, err := ecid.FromPrivateKey(priv)
+	cerrors.MaybePanic(err) // should never happen
+	return peerID
+}
+
+// simAddress returns a unique (fake) address for the peer with the given index.
+func simAddress(idx int) *net.TCPAddr {
+	return &net.TCPAddr{
+		IP:   net.IPv4(10, byte(idx>>16), byte(idx>>8), byte(idx)),
+		Port: simPort,
+	}
+}
+
+// Introduce handles an introduction like a librarian would, adding the requester to the
+// routing table, but samples peers with the node's RNG rather than the request ID.
+func (nd *node) Introduce(
+	ctx context.Context, rq *api.IntroduceRequest, opts ...grpc.CallOption,
+) (*api.IntroduceResponse, error) {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+	nd.rt.Push(peer.NewFromer().FromAPI(rq.Self))
+	peers := nd.rt.Sample(uint(rq.NumPeers), nd.rng)
+	return &api.IntroduceResponse{
+		Metadata: nd.responseMetadata(rq.Metadata),
+		Self:     nd.apiSelf,
+		Peers:    peer.ToAPIs(peers),
+	}, nil
+}
+
+// Find handles a Find request like a librarian would.
+func (nd *node) Find(
+	ctx context.Context, rq *api.FindRequest, opts ...grpc.CallOption,
+) (*api.FindResponse, error) {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+	key := id.FromBytes(rq.Key)
+	if valueBytes, in := nd.docs[key.String()]; in {
+		value := &api.Document{}
+		cerrors.MaybePanic(proto.Unmarshal(valueBytes, value)) // should never happen
+		return &api.FindResponse{
+			Metadata: nd.responseMetadata(rq.Metadata),
+			Value:    value,
+		}, nil
+	}
+	return &api.FindResponse{
+		Metadata: nd.responseMetadata(rq.Metadata),
+		Peers:    peer.ToAPIs(nd.rt.Find(key, uint(rq.NumPeers))),
+	}, nil
+}
+
+// Verify handles a Verify request like a librarian would.
+func (nd *node) Verify(
+	ctx context.Context, rq *api.VerifyRequest, opts ...grpc.CallOption,
+) (*api.VerifyResponse, error) {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+	key := id.FromBytes(rq.Key)
+	if valueBytes, in := nd.docs[key.String()]; in {
+		macer := hmac.New(sha256.New, rq.MacKey)
+		_, err := macer.Write(valueBytes)
+		cerrors.MaybePanic(err) // should never happen b/c sha256.Write always returns nil error
+		return &api.VerifyResponse{
+			Metadata: nd.responseMetadata(rq.Metadata),
+			Mac:      macer.Sum(nil),
+		}, nil
+	}
+	return &api.VerifyResponse{
+		Metadata: nd.responseMetadata(rq.Metadata),
+		Peers:    peer.ToAPIs(nd.rt.Find(key, uint(rq.NumPeers))),
+	}, nil
+}
+
+// Store handles a Store request like a librarian would.
+func (nd *node) Store(
+	ctx context.Context, rq *api.StoreRequest, opts ...grpc.CallOption,
+) (*api.StoreResponse, error) {
+	nd.mu.Lock()
+	defer nd.mu.Unlock()
+	valueBytes, err := proto.Marshal(rq.Value)
+	if err != nil {
+		return nil, err
+	}
+	nd.docs[id.FromBytes(rq.Key).String()] = valueBytes
+	return &api.StoreResponse{Metadata: nd.responseMetadata(rq.Metadata)}, nil
+}
+
+func (nd *node) responseMetadata(rq *api.RequestMetadata) *api.ResponseMetadata {
+	return &api.ResponseMetadata{
+		RequestId: rq.RequestId,
+		PubKey:    nd.peerID.PublicKeyBytes(),
+	}
+}
+
+// pushAll adds the peers to the routing table in ID order, so the table is the same regardless of
+// map iteration order.
+func (nd *node) pushAll(peers map[string]peer.Peer) {
+	for _, p := range sortedPeers(peers) {
+		nd.rt.Push(p)
+	}
+}
+
+func sortedPeers(peers map[string]peer.Peer) []peer.Peer {
+	keys := make([]string, 0, len(peers))
+	for k := range peers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	sorted := make([]peer.Peer, len(keys))
+	for i, k := range keys {
+		sorted[i] = peers[k]
+	}
+	return sorted
+}
+
+type finderCreator struct {
+	n *network
+}
+
+func (c *finderCreator) Create(address string) (api.Finder, error) {
+	nd, err := c.n.get(address)
+	if err != nil {
+		return nil, err
+	}
+	return nd, nil
+}
+
+type storerCreator struct {
+	n *network
+}
+
+func (c *storerCreator) Create(address string) (api.Storer, error) {
+	nd, err := c.n.get(address)
+	if err != nil {
+		return nil, err
+	}
+	return nd, nil
+}
+
+type verifierCreator struct {
+	n *network
+}
+
+func (c *verifierCreator) Create(address string) (api.Verifier, error) {
+	nd, err := c.n.get(address)
+	if err != nil {
+		return nil, err
+	}
+	return nd, nil
+}
+
+type introducerCreator struct {
+	n *network
+}
+
+func (c *introducerCreator) Create(address string) (api.Introducer, error) {
+	nd, err := c.n.get(address)
+	if err != nil {
+		return nil, err
+	}
+	return nd, nil
+}
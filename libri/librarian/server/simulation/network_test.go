@@ -0,0 +1,65 @@
+package simulation
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/client"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewPseudoRandomID(t *testing.T) {
+	id1 := newPseudoRandomID(rand.New(rand.NewSource(0)))
+	id2 := newPseudoRandomID(rand.New(rand.NewSource(0)))
+	id3 := newPseudoRandomID(rand.New(rand.NewSource(1)))
+	assert.Equal(t, id1.ID(), id2.ID())
+	assert.NotEqual(t, id1.ID(), id3.ID())
+}
+
+func TestNetwork_get(t *testing.T) {
+	params := newTestParameters()
+	params.NPeers = 4
+	n := newNetwork(rand.New(rand.NewSource(0)), params)
+	nd := n.nodes[1]
+	addr := nd.self.Address().String()
+
+	// down by default
+	_, err := n.get(addr)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	nd.up = true
+	got, err := n.get(addr)
+	assert.Nil(t, err)
+	assert.Equal(t, nd, got)
+
+	_, err = n.get("10.255.255.255:20100")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestNode_StoreFind(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	params := newTestParameters()
+	params.NPeers = 4
+	n := newNetwork(rng, params)
+	nd := n.nodes[0]
+	doc, key := api.NewTestDocument(rng)
+
+	// not stored, so returns peers
+	rq := client.NewFindRequest(nd.peerID, nil, key, 3)
+	rp, err := nd.Find(context.Background(), rq)
+	assert.Nil(t, err)
+	assert.Nil(t, rp.Value)
+	assert.Equal(t, rq.Metadata.RequestId, rp.Metadata.RequestId)
+
+	storeRq := client.NewStoreRequest(nd.peerID, nil, key, doc)
+	_, err = nd.Store(context.Background(), storeRq)
+	assert.Nil(t, err)
+
+	rp, err = nd.Find(context.Background(), rq)
+	assert.Nil(t, err)
+	assert.Equal(t, doc, rp.Value)
+}
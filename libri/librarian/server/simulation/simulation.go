@@ -0,0 +1,534 @@
+// Package simulation runs deterministic, discrete-event simulations of a libri network in a single
+// process. Thousands of virtual librarians use the real routing table and introduce, search,
+// store, and verify clients but talk over an in-memory transport and share a virtual clock, so a
+// simulated hour of bootstrapping, storing, looking up, replicating, and churning peers takes
+// seconds. It is meant for evaluating parameter changes (e.g., search.Parameters
+// NClosestResponses) before rolling them out to real clusters.
+//
+// A simulation with a given seed and parameters always produces the same report, provided all
+// introduce, search, store, and verify Concurrency parameters are 1 (as in the default
+// parameters); with higher concurrency, goroutine scheduling can change the order of queries.
+package simulation
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/drausin/libri/libri/common/id"
+	"github.com/drausin/libri/libri/librarian/api"
+	"github.com/drausin/libri/libri/librarian/server/introduce"
+	"github.com/drausin/libri/libri/librarian/server/peer"
+	"github.com/drausin/libri/libri/librarian/server/replicate"
+	"github.com/drausin/libri/libri/librarian/server/routing"
+	"github.com/drausin/libri/libri/librarian/server/search"
+	"github.com/drausin/libri/libri/librarian/server/store"
+	"github.com/drausin/libri/libri/librarian/server/verify"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultNPeers is the default number of virtual librarians.
+	DefaultNPeers = uint(1000)
+
+	// DefaultNSeeds is the default number of librarians others bootstrap from.
+	DefaultNSeeds = uint(3)
+
+	// DefaultNDocuments is the default number of documents stored.
+	DefaultNDocuments = uint(100)
+
+	// DefaultNLookups is the default number of document lookups.
+	DefaultNLookups = uint(500)
+
+	// DefaultDuration is the default (virtual) duration of a simulation.
+	DefaultDuration = 1 * time.Hour
+
+	// DefaultReplicateInterval is the default (virtual) interval between replication passes.
+	DefaultReplicateInterval = 10 * time.Minute
+
+	macKeySize = 32
+)
+
+var (
+	// ErrTooFewPeers indicates when a simulation has fewer peers than seeds or no peers at all.
+	ErrTooFewPeers = errors.New("simulation must have at least one peer and no fewer peers " +
+		"than seeds")
+
+	// ErrInvalidDuration indicates when a simulation has a non-positive duration.
+	ErrInvalidDuration = errors.New("simulation duration must be positive")
+)
+
+// Parameters define a simulation.
+type Parameters struct {
+	// NPeers is the number of virtual librarians.
+	NPeers uint
+
+	// NSeeds is the number of librarians every other librarian bootstraps from. Seeds never
+	// churn, so (re)joining librarians always have someone to bootstrap from.
+	NSeeds uint
+
+	// NDocuments is the number of documents stored during the first half of the simulation.
+	NDocuments uint
+
+	// NLookups is the number of lookups of stored documents during the second half of the
+	// simulation.
+	NLookups uint
+
+	// Duration is the virtual duration of the simulation.
+	Duration time.Duration
+
+	// ReplicateInterval is the virtual interval between replication passes, in which each
+	// document is verified (and replicated if needed) by one of the librarians storing it. Zero
+	// disables replication.
+	ReplicateInterval time.Duration
+
+	// Seed seeds all the randomness in the simulation.
+	Seed int64
+
+	// Churn decides when librarians leave and rejoin the network.
+	Churn ChurnModel
+
+	// Routing are the routing table parameters of every librarian.
+	Routing *routing.Parameters
+
+	// Introduce are the parameters of the introductions librarians bootstrap with.
+	Introduce *introduce.Parameters
+
+	// Search are the parameters of lookups and the searches before stores.
+	Search *search.Parameters
+
+	// Store are the parameters of stores.
+	Store *store.Parameters
+
+	// Verify are the parameters of the verifications during replication.
+	Verify *verify.Parameters
+}
+
+// NewDefaultParameters returns default simulation parameters, which use the default librarian
+// parameters except with a Concurrency of 1 everywhere, so simulations are reproducible.
+func NewDefaultParameters() *Parameters {
+	introduceParams := introduce.NewDefaultParameters()
+	introduceParams.Concurrency = 1
+	searchParams := search.NewDefaultParameters()
+	searchParams.Concurrency = 1
+	storeParams := store.NewDefaultParameters()
+	storeParams.Concurrency = 1
+	verifyParams := verify.NewDefaultParameters()
+	verifyParams.Concurrency = 1
+	return &Parameters{
+		NPeers:            DefaultNPeers,
+		NSeeds:            DefaultNSeeds,
+		NDocuments:        DefaultNDocuments,
+		NLookups:          DefaultNLookups,
+		Duration:          DefaultDuration,
+		ReplicateInterval: DefaultReplicateInterval,
+		Churn:             NewNoChurn(),
+		Routing:           routing.NewDefaultParameters(),
+		Introduce:         introduceParams,
+		Search:            searchParams,
+		Store:             storeParams,
+		Verify:            verifyParams,
+	}
+}
+
+// MarshalLogObject converts the Parameters into an object (which will become json) for logging.
+func (p *Parameters) MarshalLogObject(oe zapcore.ObjectEncoder) error {
+	oe.AddUint("n_peers", p.NPeers)
+	oe.AddUint("n_seeds", p.NSeeds)
+	oe.AddUint("n_documents", p.NDocuments)
+	oe.AddUint("n_lookups", p.NLookups)
+	oe.AddDuration("duration", p.Duration)
+	oe.AddDuration("replicate_interval", p.ReplicateInterval)
+	oe.AddInt64("seed", p.Seed)
+	if err := oe.AddObject("search", p.Search); err != nil {
+		return err
+	}
+	return oe.AddObject("store", p.Store)
+}
+
+// OpStats count the attempts and successes of an operation.
+type OpStats struct {
+	NAttempted int `json:"n_attempted"`
+	NSucceeded int `json:"n_succeeded"`
+}
+
+// SuccessRate returns the fraction of attempts that succeeded (or zero without any attempts).
+func (s *OpStats) SuccessRate() float64 {
+	if s.NAttempted == 0 {
+		return 0
+	}
+	return float64(s.NSucceeded) / float64(s.NAttempted)
+}
+
+func (s *OpStats) record(succeeded bool) {
+	s.NAttempted++
+	if succeeded {
+		s.NSucceeded++
+	}
+}
+
+// HopStats summarize the number of peers queried (hops) by lookups.
+type HopStats struct {
+	Mean float64 `json:"mean"`
+	P50  int     `json:"p50"`
+	P95  int     `json:"p95"`
+	Max  int     `json:"max"`
+}
+
+// ReplicaStats summarize the number of replicas of each document on librarians that are up at
+// the end of the simulation.
+type ReplicaStats struct {
+	Mean             float64 `json:"mean"`
+	Min              int     `json:"min"`
+	Max              int     `json:"max"`
+	NUnderReplicated int     `json:"n_under_replicated"`
+	NLost            int     `json:"n_lost"`
+}
+
+// Report summarizes the results of a simulation.
+type Report struct {
+	// NPeersUp is the number of librarians up at the end of the simulation.
+	NPeersUp int `json:"n_peers_up"`
+
+	// MeanRoutingPeers is the mean number of peers in the routing tables of librarians up at
+	// the end of the simulation.
+	MeanRoutingPeers float64 `json:"mean_routing_peers"`
+
+	// Bootstraps are the (re)joining librarians' introductions.
+	Bootstraps *OpStats `json:"bootstraps"`
+
+	// Stores are the initial stores of documents.
+	Stores *OpStats `json:"stores"`
+
+	// Lookups are the searches for stored documents, which succeed when they find the document.
+	Lookups *OpStats `json:"lookups"`
+
+	// LookupHops are the number of peers queried by each lookup.
+	LookupHops *HopStats `json:"lookup_hops"`
+
+	// Verifications are the verifications during replication passes, which succeed when they
+	// find the document to be either fully or under replicated.
+	Verifications *OpStats `json:"verifications"`
+
+	// Replications are the stores of additional replicas of under-replicated documents.
+	Replications *OpStats `json:"replications"`
+
+	// Replicas summarize the replicas of each document at the end of the simulation.
+	Replicas *ReplicaStats `json:"replicas"`
+}
+
+// Run runs a simulation with the given parameters and returns its report.
+func Run(params *Parameters, logger *zap.Logger) (*Report, error) {
+	if params.NPeers == 0 || params.NSeeds > params.NPeers {
+		return nil, ErrTooFewPeers
+	}
+	if params.Duration <= 0 {
+		return nil, ErrInvalidDuration
+	}
+	s := newSimulation(params, logger)
+	logger.Info("starting simulation", zap.Object("params", params))
+	s.schedule()
+	s.clock.RunUntil(params.Duration)
+	report := s.report()
+	logger.Info("finished simulation",
+		zap.Int("n_peers_up", report.NPeersUp),
+		zap.Float64("lookup_success_rate", report.Lookups.SuccessRate()),
+		zap.Float64("mean_lookup_hops", report.LookupHops.Mean),
+		zap.Int("n_under_replicated", report.Replicas.NUnderReplicated),
+		zap.Int("n_lost", report.Replicas.NLost),
+	)
+	return report, nil
+}
+
+type simulation struct {
+	params  *Parameters
+	clock   *Clock
+	network *network
+	rng     *rand.Rand
+	logger  *zap.Logger
+
+	// keys of the documents stored (or attempted), in order
+	docKeys []id.ID
+
+	bootstraps    *OpStats
+	stores        *OpStats
+	lookups       *OpStats
+	lookupHops    []int
+	verifications *OpStats
+	replications  *OpStats
+}
+
+func newSimulation(params *Parameters, logger *zap.Logger) *simulation {
+	rng := rand.New(rand.NewSource(params.Seed))
+	return &simulation{
+		params:        params,
+		clock:         NewClock(),
+		network:       newNetwork(rng, params),
+		rng:           rng,
+		logger:        logger,
+		docKeys:       make([]id.ID, 0, params.NDocuments),
+		bootstraps:    &OpStats{},
+		stores:        &OpStats{},
+		lookups:       &OpStats{},
+		lookupHops:    make([]int, 0, params.NLookups),
+		verifications: &OpStats{},
+		replications:  &OpStats{},
+	}
+}
+
+// schedule schedules all the simulation's events up front, so their times don't depend on what
+// happens during the simulation.
+func (s *simulation) schedule() {
+	for _, nd := range s.network.nodes {
+		nd := nd
+		s.clock.Schedule(0, func() { s.join(nd) })
+	}
+
+	nonSeeds := make([]int, 0, s.params.NPeers-s.params.NSeeds)
+	for i := int(s.params.NSeeds); i < int(s.params.NPeers); i++ {
+		nonSeeds = append(nonSeeds, i)
+	}
+	for _, e := range s.params.Churn.Events(s.rng, nonSeeds, s.params.Duration) {
+		nd, up := s.network.nodes[e.Peer], e.Up
+		if up {
+			s.clock.Schedule(e.At, func() { s.join(nd) })
+		} else {
+			s.clock.Schedule(e.At, func() { nd.up = false })
+		}
+	}
+
+	half := s.params.Duration / 2
+	for _, at := range s.sampleTimes(s.params.NDocuments, 0, half) {
+		s.clock.Schedule(at, s.storeDocument)
+	}
+	for _, at := range s.sampleTimes(s.params.NLookups, half, s.params.Duration) {
+		s.clock.Schedule(at, s.lookupDocument)
+	}
+	if s.params.ReplicateInterval > 0 {
+		for at := s.params.ReplicateInterval; at < s.params.Duration; at +=
+			s.params.ReplicateInterval {
+			s.clock.Schedule(at, s.replicateDocuments)
+		}
+	}
+}
+
+// sampleTimes returns n sorted times sampled uniformly from [start, end).
+func (s *simulation) sampleTimes(n uint, start, end time.Duration) []time.Duration {
+	times := make([]time.Duration, n)
+	for i := range times {
+		times[i] = start + time.Duration(s.rng.Int63n(int64(end-start)))
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times
+}
+
+// join brings a librarian up and bootstraps it from the seeds that are up (other than itself).
+func (s *simulation) join(nd *node) {
+	nd.up = true
+	seeds := make([]peer.Peer, 0, s.params.NSeeds)
+	for _, seed := range s.network.nodes[:s.params.NSeeds] {
+		if seed != nd && seed.up {
+			seeds = append(seeds, seed.self)
+		}
+	}
+	if len(seeds) == 0 {
+		// first seed has no one to bootstrap from
+		return
+	}
+
+	// early on, there may be fewer peers up than the min number of introductions
+	params := *s.params.Introduce
+	if nUp := s.nUp() - 1; params.MinNumIntroductions > uint(nUp) {
+		params.MinNumIntroductions = uint(nUp)
+	}
+	intro := introduce.NewIntroduction(nd.peerID, nil, nd.apiSelf, &params)
+	intro.Rand = nd.rng
+	err := nd.introducer.Introduce(intro, seeds)
+	nd.pushAll(intro.Result.Responded)
+	s.bootstraps.record(err == nil && intro.ReachedMin())
+}
+
+// storeDocument stores a new random document from a random librarian, like a Put request.
+func (s *simulation) storeDocument() {
+	nd := s.randomUp()
+	if nd == nil {
+		return
+	}
+	doc, key := api.NewTestDocument(s.rng)
+	s.docKeys = append(s.docKeys, key)
+	storeParams := *s.params.Store // by value since Storer may change concurrency
+	st := store.NewStore(nd.peerID, nil, key, doc, s.params.Search, &storeParams)
+	seeds := nd.rt.Find(key, st.Search.Params.NClosestResponses)
+	err := nd.storer.Store(st, seeds)
+	for _, p := range st.Result.Responded {
+		nd.rt.Push(p)
+	}
+	s.stores.record(err == nil && st.Stored())
+}
+
+// lookupDocument searches for a random stored document from a random librarian, like a Get
+// request.
+func (s *simulation) lookupDocument() {
+	nd := s.randomUp()
+	if nd == nil || len(s.docKeys) == 0 {
+		return
+	}
+	key := s.docKeys[s.rng.Intn(len(s.docKeys))]
+	se := search.NewSearch(nd.peerID, nil, key, s.params.Search)
+	seeds := nd.rt.Find(key, se.Params.NClosestResponses)
+	err := nd.searcher.Search(se, seeds)
+	nd.pushAll(se.Result.Responded)
+	s.lookups.record(err == nil && se.FoundValue())
+	s.lookupHops = append(s.lookupHops, len(se.Result.Queried))
+}
+
+// replicateDocuments verifies each document from the closest librarian storing it that is up
+// and stores additional replicas of any under-replicated documents, like the replicator does.
+func (s *simulation) replicateDocuments() {
+	for _, key := range s.docKeys {
+		nd := s.closestHolder(key)
+		if nd == nil {
+			// document is lost
+			continue
+		}
+		macKey := make([]byte, macKeySize)
+		_, _ = s.rng.Read(macKey)
+		v := verify.NewVerify(nd.peerID, nil, key, nd.docs[key.String()], macKey,
+			s.params.Verify)
+		seeds := nd.rt.Find(key, s.params.Verify.NClosestResponses)
+		err := nd.verifier.Verify(v, seeds)
+		nd.pushAll(v.Result.Responded)
+		verified := err == nil && !v.Exhausted()
+		s.verifications.record(verified)
+		if !verified || !v.UnderReplicated() {
+			continue
+		}
+		st := replicate.NewStore(nd.peerID, nil, v, *s.params.Store)
+		err = nd.storer.Store(st, []peer.Peer{})
+		s.replications.record(err == nil && st.Stored())
+	}
+}
+
+func (s *simulation) closestHolder(key id.ID) *node {
+	var closest *node
+	for _, nd := range s.network.nodes {
+		if _, in := nd.docs[key.String()]; !in || !nd.up {
+			continue
+		}
+		if closest == nil || key.Distance(nd.peerID.ID()).Cmp(
+			key.Distance(closest.peerID.ID())) < 0 {
+			closest = nd
+		}
+	}
+	return closest
+}
+
+func (s *simulation) randomUp() *node {
+	up := make([]*node, 0, len(s.network.nodes))
+	for _, nd := range s.network.nodes {
+		if nd.up {
+			up = append(up, nd)
+		}
+	}
+	if len(up) == 0 {
+		return nil
+	}
+	return up[s.rng.Intn(len(up))]
+}
+
+func (s *simulation) nUp() int {
+	n := 0
+	for _, nd := range s.network.nodes {
+		if nd.up {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *simulation) report() *Report {
+	r := &Report{
+		Bootstraps:    s.bootstraps,
+		Stores:        s.stores,
+		Lookups:       s.lookups,
+		LookupHops:    newHopStats(s.lookupHops),
+		Verifications: s.verifications,
+		Replications:  s.replications,
+	}
+	nRoutingPeers := 0
+	for _, nd := range s.network.nodes {
+		if nd.up {
+			r.NPeersUp++
+			nRoutingPeers += nd.rt.NumPeers()
+		}
+	}
+	if r.NPeersUp > 0 {
+		r.MeanRoutingPeers = float64(nRoutingPeers) / float64(r.NPeersUp)
+	}
+
+	nReplicas := make([]int, len(s.docKeys))
+	for i, key := range s.docKeys {
+		for _, nd := range s.network.nodes {
+			if _, in := nd.docs[key.String()]; in && nd.up {
+				nReplicas[i]++
+			}
+		}
+	}
+	r.Replicas = newReplicaStats(nReplicas, int(s.params.Store.NReplicas))
+	return r
+}
+
+func newHopStats(hops []int) *HopStats {
+	if len(hops) == 0 {
+		return &HopStats{}
+	}
+	sorted := make([]int, len(hops))
+	copy(sorted, hops)
+	sort.Ints(sorted)
+	sum := 0
+	for _, h := range sorted {
+		sum += h
+	}
+	return &HopStats{
+		Mean: float64(sum) / float64(len(sorted)),
+		P50:  percentile(sorted, 0.5),
+		P95:  percentile(sorted, 0.95),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+func newReplicaStats(nReplicas []int, nTarget int) *ReplicaStats {
+	if len(nReplicas) == 0 {
+		return &ReplicaStats{}
+	}
+	rs := &ReplicaStats{Min: math.MaxInt32}
+	sum := 0
+	for _, n := range nReplicas {
+		sum += n
+		if n < rs.Min {
+			rs.Min = n
+		}
+		if n > rs.Max {
+			rs.Max = n
+		}
+		if n == 0 {
+			rs.NLost++
+		} else if n < nTarget {
+			rs.NUnderReplicated++
+		}
+	}
+	rs.Mean = float64(sum) / float64(len(nReplicas))
+	return rs
+}
+
+// percentile returns the q-quantile (nearest rank) of the sorted values.
+func percentile(sorted []int, q float64) int {
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
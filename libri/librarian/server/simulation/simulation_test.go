@@ -0,0 +1,130 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRun_ok(t *testing.T) {
+	params := newTestParameters()
+	report, err := Run(params, zap.NewNop())
+	assert.Nil(t, err)
+
+	assert.Equal(t, int(params.NPeers), report.NPeersUp)
+	assert.True(t, report.MeanRoutingPeers > 0)
+	assert.Equal(t, int(params.NPeers-1), report.Bootstraps.NAttempted)
+	assert.Equal(t, 1.0, report.Bootstraps.SuccessRate())
+	assert.Equal(t, int(params.NDocuments), report.Stores.NAttempted)
+	assert.Equal(t, 1.0, report.Stores.SuccessRate())
+	assert.Equal(t, int(params.NLookups), report.Lookups.NAttempted)
+	assert.Equal(t, 1.0, report.Lookups.SuccessRate())
+	assert.True(t, report.LookupHops.Mean > 0)
+	assert.True(t, report.LookupHops.P50 <= report.LookupHops.P95)
+	assert.True(t, report.LookupHops.P95 <= report.LookupHops.Max)
+
+	// each doc stored by then verified in each replication pass, and all docs are stored by the
+	// last pass
+	nPasses := int(params.Duration/params.ReplicateInterval) - 1
+	assert.True(t, report.Verifications.NAttempted >= int(params.NDocuments))
+	assert.True(t, report.Verifications.NAttempted <= nPasses*int(params.NDocuments))
+	assert.Equal(t, 1.0, report.Verifications.SuccessRate())
+
+	// without churn, nothing should be lost
+	assert.Zero(t, report.Replicas.NLost)
+	assert.Zero(t, report.Replicas.NUnderReplicated)
+	assert.True(t, report.Replicas.Min >= int(params.Store.NReplicas))
+}
+
+func TestRun_deterministic(t *testing.T) {
+	params := newTestParameters()
+	params.Churn = NewExponentialChurn(20*time.Minute, 5*time.Minute)
+	report1, err := Run(params, zap.NewNop())
+	assert.Nil(t, err)
+	report2, err := Run(params, zap.NewNop())
+	assert.Nil(t, err)
+	assert.Equal(t, report1, report2)
+
+	// some peers should have rejoined
+	assert.True(t, report1.Bootstraps.NAttempted > int(params.NPeers-1))
+
+	params.Seed = 1
+	report3, err := Run(params, zap.NewNop())
+	assert.Nil(t, err)
+	assert.NotEqual(t, report1, report3)
+}
+
+func TestRun_crash(t *testing.T) {
+	params := newTestParameters()
+	params.Churn = NewCrashChurn(0.5, params.Duration/2)
+	report, err := Run(params, zap.NewNop())
+	assert.Nil(t, err)
+
+	nNonSeeds := params.NPeers - params.NSeeds
+	assert.Equal(t, int(params.NPeers-nNonSeeds/2), report.NPeersUp)
+
+	// replication passes after the crash should have found and fixed under-replicated docs
+	assert.True(t, report.Replications.NAttempted > 0)
+	assert.True(t, report.Lookups.SuccessRate() > 0.9)
+}
+
+func TestRun_err(t *testing.T) {
+	params := newTestParameters()
+	params.NPeers = 0
+	report, err := Run(params, zap.NewNop())
+	assert.Equal(t, ErrTooFewPeers, err)
+	assert.Nil(t, report)
+
+	params = newTestParameters()
+	params.NSeeds = params.NPeers + 1
+	report, err = Run(params, zap.NewNop())
+	assert.Equal(t, ErrTooFewPeers, err)
+	assert.Nil(t, report)
+
+	params = newTestParameters()
+	params.Duration = 0
+	report, err = Run(params, zap.NewNop())
+	assert.Equal(t, ErrInvalidDuration, err)
+	assert.Nil(t, report)
+}
+
+func TestOpStats_SuccessRate(t *testing.T) {
+	s := &OpStats{}
+	assert.Zero(t, s.SuccessRate())
+	s.record(true)
+	s.record(false)
+	assert.Equal(t, 0.5, s.SuccessRate())
+}
+
+func TestNewHopStats(t *testing.T) {
+	assert.Equal(t, &HopStats{}, newHopStats(nil))
+	hops := make([]int, 100)
+	for i := range hops {
+		hops[i] = 100 - i
+	}
+	assert.Equal(t, &HopStats{Mean: 50.5, P50: 50, P95: 95, Max: 100}, newHopStats(hops))
+}
+
+func TestNewReplicaStats(t *testing.T) {
+	assert.Equal(t, &ReplicaStats{}, newReplicaStats(nil, 3))
+	rs := newReplicaStats([]int{0, 1, 3, 4}, 3)
+	assert.Equal(t, &ReplicaStats{
+		Mean:             2.0,
+		Min:              0,
+		Max:              4,
+		NUnderReplicated: 1,
+		NLost:            1,
+	}, rs)
+}
+
+func newTestParameters() *Parameters {
+	params := NewDefaultParameters()
+	params.NPeers = 64
+	params.NDocuments = 16
+	params.NLookups = 32
+	params.Duration = 30 * time.Minute
+	params.ReplicateInterval = 10 * time.Minute
+	return params
+}
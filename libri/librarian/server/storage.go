@@ -1,7 +1,10 @@
 package server
 
 import (
+	"encoding/hex"
+
 	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
 	"github.com/drausin/libri/libri/common/storage"
 	"github.com/golang/protobuf/proto"
 	"go.uber.org/zap"
@@ -23,7 +26,11 @@ var (
 	peerIDKey = []byte("PeerID")
 )
 
-func loadOrCreatePeerID(logger *zap.Logger, nsl storage.StorerLoader) (ecid.ID, error) {
+// loadOrCreatePeerID loads the saved peer ID or creates a new one with at least minIDWork (see
+// id.Work) so other peers requiring that much work admit it to their routing tables.
+func loadOrCreatePeerID(
+	logger *zap.Logger, nsl storage.StorerLoader, minIDWork uint,
+) (ecid.ID, error) {
 	bytes, err := nsl.Load(peerIDKey)
 	if err != nil {
 		logger.Error("error loading peer ID", zap.Error(err))
@@ -41,13 +48,19 @@ func loadOrCreatePeerID(logger *zap.Logger, nsl storage.StorerLoader) (ecid.ID,
 			logger.Error("error deserializing peer ID keys", zap.Error(err))
 			return nil, err
 		}
-		logger.Info("loaded exsting peer ID", zap.String(LoggerPeerID, peerID.String()))
+		logger.Info("loaded exsting peer ID", zap.String(LoggerPeerID, peerID.String()),
+			zap.String(logSelfPubKey, hex.EncodeToString(peerID.PublicKeyBytes())))
+		if work := id.Work(peerID.ID()); work < minIDWork {
+			logger.Warn("existing peer ID has less than min ID work",
+				zap.Uint(logIDWork, work), zap.Uint(logMinIDWork, minIDWork))
+		}
 		return peerID, nil
 	}
 
 	// return new PeerID
-	peerID := ecid.NewRandom()
-	logger.Info("created new peer ID", zap.String(LoggerPeerID, peerID.String()))
+	peerID := ecid.NewRandomWithWork(minIDWork)
+	logger.Info("created new peer ID", zap.String(LoggerPeerID, peerID.String()),
+		zap.String(logSelfPubKey, hex.EncodeToString(peerID.PublicKeyBytes())))
 	return peerID, savePeerID(nsl, peerID)
 }
 
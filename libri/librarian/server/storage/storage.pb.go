@@ -140,6 +140,12 @@ type Peer struct {
 	PublicAddress *Address `protobuf:"bytes,3,opt,name=public_address,json=publicAddress" json:"public_address,omitempty"`
 	// response history
 	QueryOutcomes *QueryOutcomes `protobuf:"bytes,4,opt,name=query_outcomes,json=queryOutcomes" json:"query_outcomes,omitempty"`
+	// address of the relay the peer is reachable through, if any
+	RelayAddress *Address `protobuf:"bytes,5,opt,name=relay_address,json=relayAddress" json:"relay_address,omitempty"`
+	// additional addresses (host:port) the peer may be reached at
+	Addresses []string `protobuf:"bytes,6,rep,name=addresses" json:"addresses,omitempty"`
+	// (optional) serialized organization-issued certificate for the peer ECDSA public key
+	Cert []byte `protobuf:"bytes,7,opt,name=cert,proto3" json:"cert,omitempty"`
 }
 
 func (m *Peer) Reset()                    { *m = Peer{} }
@@ -175,6 +181,27 @@ func (m *Peer) GetQueryOutcomes() *QueryOutcomes {
 	return nil
 }
 
+func (m *Peer) GetRelayAddress() *Address {
+	if m != nil {
+		return m.RelayAddress
+	}
+	return nil
+}
+
+func (m *Peer) GetAddresses() []string {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func (m *Peer) GetCert() []byte {
+	if m != nil {
+		return m.Cert
+	}
+	return nil
+}
+
 // StoredRoutingTable contains the essential information associated with a routing table.
 type RoutingTable struct {
 	// big-endian byte representation of 32-byte self ID
@@ -283,36 +310,39 @@ func init() {
 func init() { proto.RegisterFile("libri/common/storage/storage.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 494 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x53, 0xcf, 0x6f, 0xd3, 0x30,
-	0x14, 0x56, 0xda, 0xd0, 0x1f, 0x2f, 0x6d, 0xd9, 0x7c, 0x18, 0x65, 0x68, 0x5a, 0x09, 0x97, 0x4a,
-	0x88, 0x4d, 0x2a, 0x12, 0x70, 0xe1, 0x80, 0x04, 0x07, 0x24, 0x10, 0xab, 0x19, 0x5c, 0x2d, 0x37,
-	0x79, 0x9b, 0x2c, 0xa5, 0xb6, 0x6b, 0x3b, 0x48, 0xdd, 0x05, 0xf1, 0xaf, 0x20, 0xfe, 0x50, 0x14,
-	0xdb, 0xc9, 0x36, 0x21, 0x71, 0x8a, 0xbf, 0xf7, 0x7d, 0x7e, 0x3f, 0xbe, 0x17, 0x43, 0x5e, 0x89,
-	0x8d, 0x11, 0xe7, 0x85, 0xda, 0x6e, 0x95, 0x3c, 0xb7, 0x4e, 0x19, 0x7e, 0x8d, 0xed, 0xf7, 0x4c,
-	0x1b, 0xe5, 0x14, 0x19, 0x46, 0x98, 0xbf, 0x80, 0xe1, 0xbb, 0xb2, 0x34, 0x68, 0x2d, 0x99, 0x41,
-	0x4f, 0xe8, 0x79, 0x6f, 0x91, 0x2c, 0xc7, 0xb4, 0x27, 0x34, 0x21, 0x90, 0x6a, 0x65, 0xdc, 0xbc,
-	0xbf, 0x48, 0x96, 0x53, 0xea, 0xcf, 0xf9, 0xaf, 0x04, 0xa6, 0xeb, 0x1a, 0xcd, 0xfe, 0x4b, 0xed,
-	0x0a, 0xb5, 0x45, 0x4b, 0x5e, 0xc1, 0xc8, 0xe0, 0xae, 0x46, 0xeb, 0xec, 0x3c, 0x59, 0x24, 0xcb,
-	0x6c, 0x75, 0x7c, 0xd6, 0xd6, 0xf2, 0xca, 0xcb, 0xbd, 0xc6, 0x56, 0x4d, 0x3b, 0x2d, 0x79, 0x03,
-	0x63, 0x83, 0x56, 0x2b, 0x69, 0xd1, 0xfa, 0xa2, 0xff, 0xbf, 0x78, 0x2b, 0xce, 0x7f, 0xc2, 0xe1,
-	0x3f, 0x3c, 0x39, 0x86, 0x11, 0x72, 0x53, 0x09, 0xb4, 0xce, 0xb7, 0xd1, 0xa7, 0x1d, 0x26, 0x47,
-	0x30, 0xa8, 0xb8, 0x6b, 0x98, 0x9e, 0x67, 0x22, 0x22, 0x4f, 0x60, 0x2c, 0xd9, 0xae, 0x46, 0x23,
-	0xd0, 0xfa, 0x29, 0x53, 0x3a, 0x92, 0xeb, 0x80, 0xc9, 0x63, 0x18, 0x49, 0x86, 0xc6, 0x28, 0x63,
-	0xe7, 0xa9, 0xe7, 0x86, 0xf2, 0x83, 0x87, 0xf9, 0xef, 0x04, 0xd2, 0x0b, 0x44, 0xe3, 0x1d, 0x2b,
-	0x7d, 0xb9, 0x09, 0xed, 0x89, 0xb2, 0x71, 0x4c, 0xf2, 0x2d, 0x46, 0x0f, 0xfd, 0x99, 0xbc, 0x86,
-	0x99, 0xae, 0x37, 0x95, 0x28, 0x18, 0x0f, 0x3e, 0xfb, 0x4a, 0xd9, 0xea, 0xa0, 0x1b, 0x36, 0xfa,
-	0x4f, 0xa7, 0x41, 0xd7, 0xae, 0xe3, 0x2d, 0xcc, 0x9a, 0xde, 0xf6, 0x4c, 0xc5, 0x19, 0x7d, 0x1b,
-	0xd9, 0xea, 0xe8, 0xbe, 0x4b, 0x9d, 0x43, 0xd3, 0xdd, 0x5d, 0x98, 0x7f, 0x82, 0x09, 0x55, 0xb5,
-	0x13, 0xf2, 0xfa, 0x92, 0x6f, 0x2a, 0x24, 0x8f, 0x60, 0x68, 0xb1, 0xba, 0x62, 0x5d, 0xc3, 0x83,
-	0x06, 0x7e, 0x2c, 0xc9, 0x33, 0x78, 0xa0, 0x11, 0x4d, 0xb3, 0x84, 0xfe, 0x32, 0x5b, 0x4d, 0xbb,
-	0xf4, 0xcd, 0x88, 0x34, 0x70, 0xf9, 0x1a, 0x1e, 0xbe, 0x57, 0x45, 0xbd, 0x45, 0xe9, 0x3e, 0xa3,
-	0x33, 0xa2, 0xb0, 0xe4, 0x14, 0x32, 0xc9, 0xca, 0x18, 0x0c, 0xbb, 0x4f, 0x29, 0xc8, 0x56, 0x66,
-	0xc9, 0x09, 0x80, 0x53, 0x8e, 0x57, 0xcc, 0x8a, 0x9b, 0xe0, 0x49, 0x4a, 0xc7, 0x3e, 0xf2, 0x55,
-	0xdc, 0x60, 0xfe, 0x27, 0x01, 0x42, 0x51, 0x57, 0xa2, 0xe0, 0x4e, 0x28, 0xd9, 0xa6, 0x3d, 0x01,
-	0x90, 0xec, 0x07, 0x1a, 0x71, 0x25, 0xb0, 0x8c, 0x59, 0xc7, 0xf2, 0x7b, 0x0c, 0x90, 0xe7, 0x70,
-	0x28, 0x59, 0x2d, 0x4b, 0x34, 0x26, 0xde, 0xc5, 0x32, 0xe6, 0x3e, 0x90, 0xdf, 0xee, 0xc7, 0xc9,
-	0x53, 0x98, 0x48, 0x76, 0x47, 0x17, 0x76, 0x9c, 0x49, 0x7a, 0x2b, 0x39, 0x85, 0x2c, 0xfc, 0x0d,
-	0x4c, 0x73, 0x1b, 0x2c, 0xee, 0x53, 0x08, 0xa1, 0x0b, 0x6e, 0xed, 0x66, 0xe0, 0x1f, 0xcc, 0xcb,
-	0xbf, 0x01, 0x00, 0x00, 0xff, 0xff, 0x8b, 0x1b, 0x89, 0x21, 0x56, 0x03, 0x00, 0x00,
+	// 531 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x53, 0x4d, 0x6f, 0xd4, 0x30,
+	0x10, 0x55, 0xb2, 0xe9, 0xee, 0x66, 0xb2, 0x29, 0xad, 0x0f, 0x25, 0x14, 0xaa, 0x2e, 0xe1, 0xb2,
+	0x12, 0xa2, 0x95, 0x16, 0xf1, 0x71, 0xe1, 0x80, 0x04, 0x07, 0x24, 0x10, 0xad, 0x29, 0x5c, 0x2d,
+	0x6f, 0x32, 0xad, 0x2c, 0x65, 0xed, 0xd4, 0x76, 0x90, 0xb6, 0x17, 0xc4, 0x99, 0xbf, 0xc1, 0x0f,
+	0x45, 0x71, 0x9c, 0x6c, 0x2b, 0x84, 0x38, 0xc5, 0xef, 0xcd, 0xb3, 0xdf, 0xcc, 0xb3, 0x03, 0x79,
+	0x25, 0x56, 0x5a, 0x9c, 0x16, 0x6a, 0xbd, 0x56, 0xf2, 0xd4, 0x58, 0xa5, 0xf9, 0x15, 0xf6, 0xdf,
+	0x93, 0x5a, 0x2b, 0xab, 0xc8, 0xc4, 0xc3, 0xfc, 0x19, 0x4c, 0xde, 0x96, 0xa5, 0x46, 0x63, 0xc8,
+	0x2e, 0x84, 0xa2, 0xce, 0xc2, 0x79, 0xb0, 0x88, 0x69, 0x28, 0x6a, 0x42, 0x20, 0xaa, 0x95, 0xb6,
+	0xd9, 0x68, 0x1e, 0x2c, 0x52, 0xea, 0xd6, 0xf9, 0xcf, 0x00, 0xd2, 0xf3, 0x06, 0xf5, 0xe6, 0x73,
+	0x63, 0x0b, 0xb5, 0x46, 0x43, 0x5e, 0xc2, 0x54, 0xe3, 0x75, 0x83, 0xc6, 0x9a, 0x2c, 0x98, 0x07,
+	0x8b, 0x64, 0x79, 0x78, 0xd2, 0x7b, 0x39, 0xe5, 0xc5, 0xa6, 0xc6, 0x5e, 0x4d, 0x07, 0x2d, 0x79,
+	0x0d, 0xb1, 0x46, 0x53, 0x2b, 0x69, 0xd0, 0x64, 0xe1, 0x7f, 0x37, 0x6e, 0xc5, 0xf9, 0x0f, 0xd8,
+	0xff, 0xab, 0x4e, 0x0e, 0x61, 0x8a, 0x5c, 0x57, 0x02, 0x8d, 0x75, 0x6d, 0x8c, 0xe8, 0x80, 0xc9,
+	0x01, 0x8c, 0x2b, 0x6e, 0xdb, 0x4a, 0xe8, 0x2a, 0x1e, 0x91, 0x87, 0x10, 0x4b, 0x76, 0xdd, 0xa0,
+	0x16, 0x68, 0xdc, 0x94, 0x11, 0x9d, 0xca, 0xf3, 0x0e, 0x93, 0x07, 0x30, 0x95, 0x0c, 0xb5, 0x56,
+	0xda, 0x64, 0x91, 0xab, 0x4d, 0xe4, 0x7b, 0x07, 0xf3, 0x5f, 0x21, 0x44, 0x67, 0x88, 0xda, 0x25,
+	0x56, 0x3a, 0xbb, 0x19, 0x0d, 0x45, 0xd9, 0x26, 0x26, 0xf9, 0x1a, 0x7d, 0x86, 0x6e, 0x4d, 0x5e,
+	0xc1, 0x6e, 0xdd, 0xac, 0x2a, 0x51, 0x30, 0xde, 0xe5, 0xec, 0x9c, 0x92, 0xe5, 0xde, 0x30, 0xac,
+	0xcf, 0x9f, 0xa6, 0x9d, 0xce, 0x43, 0xf2, 0x06, 0x76, 0xdb, 0xde, 0x36, 0x4c, 0xf9, 0x19, 0x5d,
+	0x1b, 0xc9, 0xf2, 0xe0, 0x6e, 0x4a, 0x43, 0x42, 0xe9, 0xf5, 0x6d, 0x48, 0x5e, 0x40, 0xaa, 0xb1,
+	0xe2, 0x9b, 0xc1, 0x76, 0xe7, 0x1f, 0xb6, 0x33, 0x27, 0xeb, 0x5d, 0x1f, 0x41, 0xec, 0x37, 0xa0,
+	0xc9, 0xc6, 0xf3, 0xd1, 0x22, 0xa6, 0x5b, 0xa2, 0x1d, 0xb0, 0x40, 0x6d, 0xb3, 0x89, 0x1b, 0xd9,
+	0xad, 0xf3, 0x8f, 0x30, 0xa3, 0xaa, 0xb1, 0x42, 0x5e, 0x5d, 0xf0, 0x55, 0x85, 0xe4, 0x3e, 0x4c,
+	0x0c, 0x56, 0x97, 0x6c, 0x48, 0x66, 0xdc, 0xc2, 0x0f, 0x25, 0x79, 0x02, 0x3b, 0x35, 0xa2, 0x6e,
+	0x6f, 0x7b, 0xb4, 0x48, 0x96, 0xe9, 0xd0, 0x49, 0x9b, 0x25, 0xed, 0x6a, 0xf9, 0x39, 0xdc, 0x7b,
+	0xa7, 0x8a, 0x66, 0x8d, 0xd2, 0x7e, 0x42, 0xab, 0x45, 0x61, 0xc8, 0x31, 0x24, 0x92, 0x95, 0x9e,
+	0xec, 0x1e, 0x59, 0x44, 0x41, 0xf6, 0x32, 0x43, 0x8e, 0x00, 0xac, 0xb2, 0xbc, 0x62, 0x46, 0xdc,
+	0x74, 0xe1, 0x47, 0x34, 0x76, 0xcc, 0x17, 0x71, 0x83, 0xf9, 0xef, 0x00, 0x08, 0xc5, 0xba, 0x12,
+	0x05, 0xb7, 0x42, 0xc9, 0xfe, 0xd8, 0x23, 0x00, 0xc9, 0xbe, 0xa3, 0x16, 0x97, 0x02, 0x4b, 0x7f,
+	0x6a, 0x2c, 0xbf, 0x79, 0x82, 0x3c, 0x85, 0x7d, 0xc9, 0x1a, 0x59, 0xa2, 0xd6, 0x7e, 0x2f, 0x96,
+	0xfe, 0xec, 0x3d, 0xf9, 0xf5, 0x2e, 0x4f, 0x1e, 0xc3, 0x4c, 0xb2, 0x5b, 0xba, 0xee, 0x31, 0x25,
+	0x92, 0x6e, 0x25, 0xc7, 0x90, 0x74, 0xcf, 0x8e, 0xd5, 0xdc, 0x74, 0x77, 0x39, 0xa2, 0xd0, 0x51,
+	0x67, 0xdc, 0x98, 0xd5, 0xd8, 0xfd, 0x99, 0xcf, 0xff, 0x0c, 0x00, 0x89, 0x6d, 0x5c, 0x5b, 0xbf,
+	0x03, 0x00, 0x00,
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/drausin/libri/libri/common/ecid"
+	"github.com/drausin/libri/libri/common/id"
 	clogging "github.com/drausin/libri/libri/common/logging"
 	cstorage "github.com/drausin/libri/libri/common/storage"
 	"github.com/golang/protobuf/proto"
@@ -15,17 +16,23 @@ import (
 func TestLoadOrCreatePeerID_ok(t *testing.T) {
 
 	// create new peer ID
-	id1, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &cstorage.TestSLD{})
+	id1, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &cstorage.TestSLD{}, 0)
 	assert.NotNil(t, id1)
 	assert.Nil(t, err)
 
+	// create new peer ID with some work
+	id1, err = loadOrCreatePeerID(clogging.NewDevInfoLogger(), &cstorage.TestSLD{}, 4)
+	assert.Nil(t, err)
+	assert.True(t, id.Work(id1.ID()) >= 4)
+
 	// load existing
 	rng := rand.New(rand.NewSource(0))
 	peerID2 := ecid.NewPseudoRandom(rng)
 	bytes, err := proto.Marshal(ecid.ToStored(peerID2))
 	assert.Nil(t, err)
 
-	id2, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &cstorage.TestSLD{Bytes: bytes})
+	id2, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &cstorage.TestSLD{Bytes: bytes},
+		0)
 
 	assert.Equal(t, peerID2, id2)
 	assert.Nil(t, err)
@@ -34,13 +41,13 @@ func TestLoadOrCreatePeerID_ok(t *testing.T) {
 func TestLoadOrCreatePeerID_err(t *testing.T) {
 	id1, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &cstorage.TestSLD{
 		LoadErr: errors.New("some load error"),
-	})
+	}, 0)
 	assert.Nil(t, id1)
 	assert.NotNil(t, err)
 
 	id2, err := loadOrCreatePeerID(clogging.NewDevInfoLogger(), &cstorage.TestSLD{
 		Bytes: []byte("the wrong bytes"),
-	})
+	}, 0)
 	assert.Nil(t, id2)
 	assert.NotNil(t, err)
 }
@@ -175,9 +175,13 @@ func NewStore(
 	createRq := func() *api.StoreRequest {
 		return client.NewStoreRequest(peerID, orgID, key, value)
 	}
+	s := search.NewSearch(peerID, orgID, key, &updatedSearchParams)
+
+	// pointers overwrite older stored versions, so finding one shouldn't end the search
+	_, s.PeersOnly = value.Contents.(*api.Document_Pointer)
 	return &Store{
 		CreateRq: createRq,
-		Search:   search.NewSearch(peerID, orgID, key, &updatedSearchParams),
+		Search:   s,
 		Params:   storeParams,
 	}
 }
@@ -88,6 +88,23 @@ func TestStore_MarshalLogObject_ok(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestNewStore_pointer(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	peerID, orgID := ecid.NewPseudoRandom(rng), ecid.NewPseudoRandom(rng)
+	searchParams, storeParams := ssearch.NewDefaultParameters(), NewDefaultParameters()
+
+	doc, key := api.NewTestDocument(rng)
+	s1 := NewStore(peerID, orgID, key, doc, searchParams, storeParams)
+	assert.False(t, s1.Search.PeersOnly)
+
+	pointer, _ := api.NewTestPointer(rng)
+	pointerDoc := &api.Document{Contents: &api.Document_Pointer{Pointer: pointer}}
+	pointerKey, err := api.GetKey(pointerDoc)
+	assert.Nil(t, err)
+	s2 := NewStore(peerID, orgID, pointerKey, pointerDoc, searchParams, storeParams)
+	assert.True(t, s2.Search.PeersOnly)
+}
+
 func TestStore_Stored(t *testing.T) {
 	rng := rand.New(rand.NewSource(0))
 	peerID := ecid.NewPseudoRandom(rng)
@@ -138,7 +138,7 @@ func (s *storer) Store(store *Store, seeds []peer.Peer) error {
 }
 
 func (s *storer) query(next peer.Peer, store *Store) (*api.StoreResponse, error) {
-	lc, err := s.storerCreator.Create(next.Address().String())
+	lc, err := s.storerCreator.Create(peer.DialAddress(next))
 	if err != nil {
 		return nil, err
 	}
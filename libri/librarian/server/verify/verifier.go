@@ -77,6 +77,30 @@ type peerResponse struct {
 }
 
 func (v *verifier) Verify(verify *Verify, seeds []peer.Peer) error {
+	if verify.Params.NDisjointPaths <= 1 {
+		return v.verifyPath(verify, seeds)
+	}
+
+	// verify along each disjoint path concurrently and then merge their results
+	pathSeeds := search.SplitSeeds(verify.Key, seeds, verify.Params.NDisjointPaths)
+	claims := search.NewPathClaims()
+	paths := make([]*Verify, len(pathSeeds))
+	var wg sync.WaitGroup
+	for i := range pathSeeds {
+		paths[i] = verify.newPath(i, claims)
+		wg.Add(1)
+		go func(path *Verify, seeds []peer.Peer) {
+			defer wg.Done()
+			_ = v.verifyPath(path, seeds) // fatal errors merged below
+		}(paths[i], pathSeeds[i])
+	}
+	wg.Wait()
+	verify.mergePaths(paths)
+	return verify.Result.FatalErr
+}
+
+// verifyPath verifies along a single path from the seeds, with Concurrency parallel queries.
+func (v *verifier) verifyPath(verify *Verify, seeds []peer.Peer) error {
 	toQuery := search.NewQueryQueue()
 	peerResponses := make(chan *peerResponse, 1)
 
@@ -84,11 +108,18 @@ func (v *verifier) Verify(verify *Verify, seeds []peer.Peer) error {
 	verify.Result.Unqueried.SafePushMany(seeds)
 
 	go func() {
+		nSent := 0
 		for c := uint(0); c < verify.Params.Concurrency; c++ {
 			if next := getNextToQuery(verify); next != nil {
 				toQuery.MaybeSend(next)
+				nSent++
 			}
 		}
+		if nSent == 0 {
+			// no responses will come to queue more peers, e.g., when another disjoint path
+			// has claimed all the seeds
+			toQuery.MaybeClose()
+		}
 	}()
 
 	// goroutine that processes responses and queues up next peer to query
@@ -126,7 +157,7 @@ func (v *verifier) Verify(verify *Verify, seeds []peer.Peer) error {
 }
 
 func (v *verifier) query(next peer.Peer, verify *Verify) (*api.VerifyResponse, error) {
-	lc, err := v.verifierCreator.Create(next.Address().String())
+	lc, err := v.verifierCreator.Create(peer.DialAddress(next))
 	if err != nil {
 		return nil, err
 	}
@@ -184,14 +215,18 @@ func getNextToQuery(verify *Verify) peer.Peer {
 	}
 	verify.mu.Lock()
 	defer verify.mu.Unlock()
-	if verify.Result.Unqueried.Len() == 0 {
-		return nil
-	}
-	next := heap.Pop(verify.Result.Unqueried).(peer.Peer)
-	if _, alreadyQueried := verify.Result.Queried[next.ID().String()]; alreadyQueried {
-		return nil
+	for verify.Result.Unqueried.Len() > 0 {
+		next := heap.Pop(verify.Result.Unqueried).(peer.Peer)
+		if _, alreadyQueried := verify.Result.Queried[next.ID().String()]; alreadyQueried {
+			return nil
+		}
+		if verify.claims != nil && !verify.claims.Claim(next.ID(), verify.path) {
+			// skip peers already claimed by this or another disjoint path
+			continue
+		}
+		return next
 	}
-	return next
+	return nil
 }
 
 func maybeSendNextToQuery(toQuery *search.QueryQueue, verify *Verify) {
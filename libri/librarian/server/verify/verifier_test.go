@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"sync"
 	"testing"
 	"time"
 
@@ -149,6 +150,33 @@ func TestVerifier_Verify_rpErr(t *testing.T) {
 	assert.Equal(t, 0, len(verify.Result.Responded))
 }
 
+func TestVerifier_Verify_disjoint(t *testing.T) {
+	verifierImpl, verify, selfPeerIdxs, peers := newTestVerify()
+	verify.Params.NDisjointPaths = 2
+	seeds := search.NewTestSeeds(peers, selfPeerIdxs)
+
+	err := verifierImpl.Verify(verify, seeds)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, verify.nPaths)
+	assert.True(t, verify.Finished())
+	assert.True(t, verify.UnderReplicated())
+	assert.False(t, verify.Errored())
+	assert.Equal(t, int(verify.Params.NClosestResponses), verify.Result.Closest.Len())
+	assert.True(t, verify.Result.Closest.Len() <= len(verify.Result.Responded))
+
+	// all queries return errors, so all paths error
+	verifierImpl, verify, selfPeerIdxs, peers = newTestVerify()
+	verify.Params.NDisjointPaths = 2
+	seeds = search.NewTestSeeds(peers, selfPeerIdxs)
+	verifierImpl.(*verifier).verifierCreator = &testVerifierCreator{
+		err: errors.New("some Create error"),
+	}
+	err = verifierImpl.Verify(verify, seeds)
+	assert.Equal(t, errTooManyVerifyErrors, err)
+	assert.True(t, verify.Errored())
+	assert.Equal(t, 0, len(verify.Result.Responded))
+}
+
 func TestVerifier_query_ok(t *testing.T) {
 	rng := rand.New(rand.NewSource(int64(0)))
 	peerID, key := ecid.NewPseudoRandom(rng), id.NewPseudoRandom(rng)
@@ -384,11 +412,14 @@ func newPeerAddresses(rng *rand.Rand, n int) []*api.PeerAddress {
 type fixedRecorder struct {
 	nSuccesses int
 	nErrors    int
+	mu         sync.Mutex
 }
 
 func (f *fixedRecorder) Record(
 	peerID id.ID, endpoint api.Endpoint, qt comm.QueryType, o comm.Outcome,
 ) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	if o == comm.Success {
 		f.nSuccesses++
 	} else {
@@ -25,6 +25,7 @@ const (
 	logNClosestResponses = "n_closest_responses"
 	logNMaxErrors        = "n_max_errors"
 	logConcurrency       = "concurrency"
+	logNDisjointPaths    = "n_disjoint_paths"
 	logTimeout           = "timeout"
 	logNClosest          = "n_closest"
 	logNUnqueried        = "n_unqueried"
@@ -59,6 +60,11 @@ type Parameters struct {
 	// Concurrency is the number of concurrent queries to use in search
 	Concurrency uint
 
+	// NDisjointPaths is the number of independent paths the verify follows, each with
+	// Concurrency queries and NMaxErrors tolerated errors, without querying any peer on more than
+	// one path.
+	NDisjointPaths uint
+
 	// Timeout for queries to individual peers
 	Timeout time.Duration
 }
@@ -72,6 +78,7 @@ func NewDefaultParameters() *Parameters {
 		NClosestResponses: nReplicas + store.DefaultNMaxErrors,
 		NMaxErrors:        search.DefaultNMaxErrors,
 		Concurrency:       search.DefaultConcurrency,
+		NDisjointPaths:    search.DefaultNDisjointPaths,
 		Timeout:           search.DefaultQueryTimeout,
 	}
 }
@@ -83,6 +90,7 @@ func (p *Parameters) MarshalLogObject(oe zapcore.ObjectEncoder) error {
 	oe.AddUint(logNClosestResponses, p.NClosestResponses)
 	oe.AddUint(logNMaxErrors, p.NMaxErrors)
 	oe.AddUint(logConcurrency, p.Concurrency)
+	oe.AddUint(logNDisjointPaths, p.NDisjointPaths)
 	oe.AddDuration(logTimeout, p.Timeout)
 	return nil
 }
@@ -157,6 +165,16 @@ type Verify struct {
 
 	// mutex used to synchronizes reads and writes to this instance
 	mu sync.Mutex
+
+	// claims records the path each peer was queried on when this verify is one of the paths of a
+	// disjoint-path verify, otherwise nil
+	claims *search.PathClaims
+
+	// path is the index of this verify among the paths of a disjoint-path verify
+	path int
+
+	// nPaths is the number of disjoint paths whose results have been merged into this verify
+	nPaths int
 }
 
 // NewVerify creates a new Verify instance for the given key with the given macKey, expected mac
@@ -222,8 +240,12 @@ func (v *Verify) UnderReplicated() bool {
 	return v.Result.Closest.PeakDistance().Cmp(v.Result.Unqueried.PeakDistance()) <= 0
 }
 
-// Errored returns whether the verify has encountered too many errors when querying the peers.
+// Errored returns whether the verify has encountered too many errors when querying the peers. A
+// verify merged from disjoint paths has only errored if all of its paths did.
 func (v *Verify) Errored() bool {
+	if v.nPaths > 1 {
+		return v.Result.FatalErr != nil
+	}
 	return uint(len(v.Result.Errored)) > v.Params.NMaxErrors || v.Result.FatalErr != nil
 }
 
@@ -250,6 +272,63 @@ func (v *Verify) AddQueried(p peer.Peer) {
 	v.Result.Queried[p.ID().String()] = struct{}{}
 }
 
+// newPath creates a new verify for one of the disjoint paths of this verify.
+func (v *Verify) newPath(path int, claims *search.PathClaims) *Verify {
+	return &Verify{
+		Key:         v.Key,
+		Value:       v.Value,
+		ExpectedMAC: v.ExpectedMAC,
+		CreateRq:    v.CreateRq,
+		Result:      NewInitialResult(v.Key, v.Params),
+		Params:      v.Params,
+		claims:      claims,
+		path:        path,
+	}
+}
+
+// mergePaths merges the results of the disjoint paths into this verify's result. The verify only
+// has a fatal error if all paths do.
+func (v *Verify) mergePaths(paths []*Verify) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.nPaths = len(paths)
+	nFatal := 0
+	for _, path := range paths {
+		path.mu.Lock()
+		r := path.Result
+		for idStr, p := range r.Replicas {
+			v.Result.Replicas[idStr] = p
+		}
+		for _, p := range r.Closest.Peers() {
+			v.Result.Closest.SafePush(p)
+		}
+		for idStr := range r.Queried {
+			v.Result.Queried[idStr] = struct{}{}
+		}
+		for idStr, p := range r.Responded {
+			v.Result.Responded[idStr] = p
+		}
+		for idStr, err := range r.Errored {
+			v.Result.Errored[idStr] = err
+		}
+		if r.FatalErr != nil {
+			v.Result.FatalErr = r.FatalErr
+			nFatal++
+		}
+		path.mu.Unlock()
+	}
+	if nFatal < len(paths) {
+		v.Result.FatalErr = nil
+	}
+	for _, path := range paths {
+		for _, p := range path.Result.Unqueried.Peers() {
+			if _, queried := v.Result.Queried[p.ID().String()]; !queried {
+				v.Result.Unqueried.SafePush(p)
+			}
+		}
+	}
+}
+
 func (v *Verify) wrapLock(operation func()) {
 	v.mu.Lock()
 	defer v.mu.Unlock()